@@ -0,0 +1,130 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/version"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// APIVersion is the version of lifecycle API. It must be increased
+	// when an RPC is changed in a way that a daemon of the previous
+	// version can't decode.
+	APIVersion = 1
+
+	// MinCompatibleAPIVersion is the smallest API version of the peer
+	// that works with this version.
+	MinCompatibleAPIVersion = 1
+)
+
+// apiVersionExemptMethods are the RPC methods called without checking
+// the API version, so the user is able to find and stop a daemon of
+// an incompatible version.
+var apiVersionExemptMethods = map[string]bool{
+	"GetApiVersion": true,
+	"GetStatus":     true,
+	"Exit":          true,
+	"Stop":          true,
+}
+
+// currentAPIVersion returns the lifecycle API version of this program.
+func currentAPIVersion() *pb.ApiVersion {
+	return &pb.ApiVersion{
+		Version:              proto.Int32(APIVersion),
+		MinCompatibleVersion: proto.Int32(MinCompatibleAPIVersion),
+		AppVersion:           proto.String(version.AppVersion),
+	}
+}
+
+// checkAPIVersion returns an error if the API version of the daemon
+// is not compatible with this program.
+func checkAPIVersion(daemon *pb.ApiVersion) error {
+	if daemon.GetVersion() >= MinCompatibleAPIVersion && daemon.GetMinCompatibleVersion() <= APIVersion {
+		return nil
+	}
+	appVersion := daemon.GetAppVersion()
+	if appVersion == "" {
+		appVersion = "unknown"
+	}
+	return fmt.Errorf("the running daemon (version %s, API version %d) is not compatible with this command (version %s, API version %d); restart the daemon to run the same version as this command", appVersion, daemon.GetVersion(), version.AppVersion, APIVersion)
+}
+
+// apiVersionChecker checks the API version of the daemon before
+// the first RPC call of a connection.
+type apiVersionChecker struct {
+	// method is the full method name of GetApiVersion RPC.
+	method string
+
+	mu      sync.Mutex
+	checked bool
+	err     error
+}
+
+// apiVersionDialOptions returns the gRPC dial options that check the
+// API version of the daemon with the GetApiVersion RPC method.
+func apiVersionDialOptions(method string) []grpc.DialOption {
+	c := &apiVersionChecker{method: method}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if err := c.check(ctx, cc, method); err != nil {
+				return err
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			if err := c.check(ctx, cc, method); err != nil {
+				return nil, err
+			}
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	}
+}
+
+// check returns an error if the API version of the daemon is not
+// compatible. The result is reused by the following RPC calls.
+func (c *apiVersionChecker) check(ctx context.Context, cc *grpc.ClientConn, method string) error {
+	if apiVersionExemptMethods[method[strings.LastIndex(method, "/")+1:]] {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checked {
+		return c.err
+	}
+	daemon := &pb.ApiVersion{}
+	if err := cc.Invoke(ctx, c.method, &pb.Empty{}, daemon); err != nil {
+		if status.Code(err) != codes.Unimplemented {
+			// Let the RPC call report the error, and check again
+			// with the next RPC call.
+			return nil
+		}
+		// The daemon is older than the GetApiVersion RPC method.
+		daemon = &pb.ApiVersion{Version: proto.Int32(0)}
+	}
+	c.checked = true
+	c.err = checkAPIVersion(daemon)
+	return c.err
+}
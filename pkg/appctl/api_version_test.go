@@ -0,0 +1,96 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// versionedClientLifecycleService returns the given API version,
+// or doesn't implement GetApiVersion if the version is nil.
+type versionedClientLifecycleService struct {
+	pb.UnimplementedClientLifecycleServiceServer
+	version *pb.ApiVersion
+}
+
+func (s *versionedClientLifecycleService) GetStatus(ctx context.Context, req *pb.Empty) (*pb.AppStatusMsg, error) {
+	return &pb.AppStatusMsg{}, nil
+}
+
+func (s *versionedClientLifecycleService) GetMetrics(ctx context.Context, req *pb.Empty) (*pb.Metrics, error) {
+	return &pb.Metrics{}, nil
+}
+
+func (s *versionedClientLifecycleService) GetApiVersion(ctx context.Context, req *pb.Empty) (*pb.ApiVersion, error) {
+	if s.version == nil {
+		return s.UnimplementedClientLifecycleServiceServer.GetApiVersion(ctx, req)
+	}
+	return s.version, nil
+}
+
+func TestAPIVersionCheck(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version *pb.ApiVersion
+		wantErr bool
+	}{
+		{"same version", currentAPIVersion(), false},
+		{"newer compatible version", &pb.ApiVersion{Version: proto.Int32(APIVersion + 1), MinCompatibleVersion: proto.Int32(APIVersion)}, false},
+		{"newer incompatible version", &pb.ApiVersion{Version: proto.Int32(APIVersion + 1), MinCompatibleVersion: proto.Int32(APIVersion + 1)}, true},
+		{"older incompatible version", &pb.ApiVersion{Version: proto.Int32(MinCompatibleAPIVersion - 1)}, true},
+		{"no GetApiVersion method", nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() failed: %v", err)
+			}
+			grpcServer := grpc.NewServer()
+			pb.RegisterClientLifecycleServiceServer(grpcServer, &versionedClientLifecycleService{version: tc.version})
+			go grpcServer.Serve(l)
+			defer grpcServer.Stop()
+
+			opts := append([]grpc.DialOption{grpc.WithInsecure()}, apiVersionDialOptions(pb.ClientLifecycleService_GetApiVersion_FullMethodName)...)
+			conn, err := grpc.Dial(l.Addr().String(), opts...)
+			if err != nil {
+				t.Fatalf("grpc.Dial() failed: %v", err)
+			}
+			defer conn.Close()
+			client := pb.NewClientLifecycleServiceClient(conn)
+			ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+			defer cancel()
+			if _, err := client.GetStatus(ctx, &pb.Empty{}); err != nil {
+				t.Errorf("GetStatus() failed: %v", err)
+			}
+			_, err = client.GetMetrics(ctx, &pb.Empty{})
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "restart the daemon") {
+					t.Errorf("GetMetrics() returned error %v, want API version mismatch", err)
+				}
+			} else if err != nil {
+				t.Errorf("GetMetrics() failed: %v", err)
+			}
+		})
+	}
+}
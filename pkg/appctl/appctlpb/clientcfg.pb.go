@@ -35,6 +35,108 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type IPv6SourceAddressPreference int32
+
+const (
+	// Use the default source address selection of the operating system.
+	IPv6SourceAddressPreference_IPV6_SOURCE_DEFAULT IPv6SourceAddressPreference = 0
+	// Prefer temporary (privacy) IPv6 addresses.
+	IPv6SourceAddressPreference_IPV6_SOURCE_PREFER_TEMPORARY IPv6SourceAddressPreference = 1
+	// Prefer stable public IPv6 addresses.
+	IPv6SourceAddressPreference_IPV6_SOURCE_PREFER_PUBLIC IPv6SourceAddressPreference = 2
+)
+
+// Enum value maps for IPv6SourceAddressPreference.
+var (
+	IPv6SourceAddressPreference_name = map[int32]string{
+		0: "IPV6_SOURCE_DEFAULT",
+		1: "IPV6_SOURCE_PREFER_TEMPORARY",
+		2: "IPV6_SOURCE_PREFER_PUBLIC",
+	}
+	IPv6SourceAddressPreference_value = map[string]int32{
+		"IPV6_SOURCE_DEFAULT":          0,
+		"IPV6_SOURCE_PREFER_TEMPORARY": 1,
+		"IPV6_SOURCE_PREFER_PUBLIC":    2,
+	}
+)
+
+func (x IPv6SourceAddressPreference) Enum() *IPv6SourceAddressPreference {
+	p := new(IPv6SourceAddressPreference)
+	*p = x
+	return p
+}
+
+func (x IPv6SourceAddressPreference) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IPv6SourceAddressPreference) Descriptor() protoreflect.EnumDescriptor {
+	return file_clientcfg_proto_enumTypes[0].Descriptor()
+}
+
+func (IPv6SourceAddressPreference) Type() protoreflect.EnumType {
+	return &file_clientcfg_proto_enumTypes[0]
+}
+
+func (x IPv6SourceAddressPreference) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IPv6SourceAddressPreference.Descriptor instead.
+func (IPv6SourceAddressPreference) EnumDescriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{0}
+}
+
+type TransparentProxyMode int32
+
+const (
+	// Connections are diverted by iptables REDIRECT target.
+	// The original destination is read from SO_ORIGINAL_DST socket option.
+	TransparentProxyMode_TRANSPARENT_PROXY_REDIRECT TransparentProxyMode = 0
+	// Connections are diverted by iptables TPROXY target.
+	// The original destination is the local address of the connection.
+	TransparentProxyMode_TRANSPARENT_PROXY_TPROXY TransparentProxyMode = 1
+)
+
+// Enum value maps for TransparentProxyMode.
+var (
+	TransparentProxyMode_name = map[int32]string{
+		0: "TRANSPARENT_PROXY_REDIRECT",
+		1: "TRANSPARENT_PROXY_TPROXY",
+	}
+	TransparentProxyMode_value = map[string]int32{
+		"TRANSPARENT_PROXY_REDIRECT": 0,
+		"TRANSPARENT_PROXY_TPROXY":   1,
+	}
+)
+
+func (x TransparentProxyMode) Enum() *TransparentProxyMode {
+	p := new(TransparentProxyMode)
+	*p = x
+	return p
+}
+
+func (x TransparentProxyMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TransparentProxyMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_clientcfg_proto_enumTypes[1].Descriptor()
+}
+
+func (TransparentProxyMode) Type() protoreflect.EnumType {
+	return &file_clientcfg_proto_enumTypes[1]
+}
+
+func (x TransparentProxyMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TransparentProxyMode.Descriptor instead.
+func (TransparentProxyMode) EnumDescriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{1}
+}
+
 type ClientProfile struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -51,25 +153,562 @@ type ClientProfile struct {
 	Mtu *int32 `protobuf:"varint,4,opt,name=mtu,proto3,oneof" json:"mtu,omitempty"`
 	// Multiplexing behaviors.
 	Multiplexing *MultiplexingConfig `protobuf:"bytes,5,opt,name=multiplexing,proto3,oneof" json:"multiplexing,omitempty"`
+	// Preference of local IPv6 source address used to connect to proxy servers.
+	// This setting only takes effect on Linux and Android.
+	Ipv6SourceAddress *IPv6SourceAddressPreference `protobuf:"varint,6,opt,name=ipv6SourceAddress,proto3,enum=appctl.IPv6SourceAddressPreference,oneof" json:"ipv6SourceAddress,omitempty"`
+	// If set, the servers of this profile are downloaded from a subscription
+	// and replaced every time the subscription is refreshed.
+	Subscription *Subscription `protobuf:"bytes,7,opt,name=subscription,proto3,oneof" json:"subscription,omitempty"`
+}
+
+func (x *ClientProfile) Reset() {
+	*x = ClientProfile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientcfg_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientProfile) ProtoMessage() {}
+
+func (x *ClientProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientProfile.ProtoReflect.Descriptor instead.
+func (*ClientProfile) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ClientProfile) GetProfileName() string {
+	if x != nil && x.ProfileName != nil {
+		return *x.ProfileName
+	}
+	return ""
+}
+
+func (x *ClientProfile) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *ClientProfile) GetServers() []*ServerEndpoint {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+func (x *ClientProfile) GetMtu() int32 {
+	if x != nil && x.Mtu != nil {
+		return *x.Mtu
+	}
+	return 0
+}
+
+func (x *ClientProfile) GetMultiplexing() *MultiplexingConfig {
+	if x != nil {
+		return x.Multiplexing
+	}
+	return nil
+}
+
+func (x *ClientProfile) GetIpv6SourceAddress() IPv6SourceAddressPreference {
+	if x != nil && x.Ipv6SourceAddress != nil {
+		return *x.Ipv6SourceAddress
+	}
+	return IPv6SourceAddressPreference_IPV6_SOURCE_DEFAULT
+}
+
+func (x *ClientProfile) GetSubscription() *Subscription {
+	if x != nil {
+		return x.Subscription
+	}
+	return nil
+}
+
+type Subscription struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// HTTPS URL that returns the servers of the profile.
+	// The response body is a JSON object in the format of
+	// SubscriptionContent, for example {"servers": [...]}.
+	Url *string `protobuf:"bytes,1,opt,name=url,proto3,oneof" json:"url,omitempty"`
+	// Number of minutes between two refreshes.
+	// If not set, the subscription is refreshed every 1440 minutes (1 day).
+	RefreshIntervalMinutes *int32 `protobuf:"varint,2,opt,name=refreshIntervalMinutes,proto3,oneof" json:"refreshIntervalMinutes,omitempty"`
+}
+
+func (x *Subscription) Reset() {
+	*x = Subscription{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientcfg_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Subscription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subscription) ProtoMessage() {}
+
+func (x *Subscription) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subscription.ProtoReflect.Descriptor instead.
+func (*Subscription) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Subscription) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *Subscription) GetRefreshIntervalMinutes() int32 {
+	if x != nil && x.RefreshIntervalMinutes != nil {
+		return *x.RefreshIntervalMinutes
+	}
+	return 0
+}
+
+type SubscriptionContent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// A list of servers to connect.
+	Servers []*ServerEndpoint `protobuf:"bytes,1,rep,name=servers,proto3" json:"servers,omitempty"`
+}
+
+func (x *SubscriptionContent) Reset() {
+	*x = SubscriptionContent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientcfg_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscriptionContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriptionContent) ProtoMessage() {}
+
+func (x *SubscriptionContent) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriptionContent.ProtoReflect.Descriptor instead.
+func (*SubscriptionContent) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SubscriptionContent) GetServers() []*ServerEndpoint {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+type ClientAdvancedSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Retransmission parameters of UDP protocol.
+	Retransmission *RetransmissionSettings `protobuf:"bytes,1,opt,name=retransmission,proto3,oneof" json:"retransmission,omitempty"`
+	// Flow control parameters of UDP protocol.
+	FlowControl *FlowControlSettings `protobuf:"bytes,2,opt,name=flowControl,proto3,oneof" json:"flowControl,omitempty"`
+	// Rules to assign priority to proxy sessions by destination.
+	// The first matched rule is used. The priority only impacts data
+	// sent from the client.
+	PriorityRules []*PriorityRule `protobuf:"bytes,3,rep,name=priorityRules,proto3" json:"priorityRules,omitempty"`
+	// Number of seconds "mieru stop" waits for existing connections to
+	// finish. New connections are rejected during the wait. If not set,
+	// existing connections are closed immediately. Maximum value is 60.
+	DrainTimeoutSeconds *int32 `protobuf:"varint,4,opt,name=drainTimeoutSeconds,proto3,oneof" json:"drainTimeoutSeconds,omitempty"`
+	// Number of seconds without receiving anything from the server
+	// before a UDP session is closed because the server is considered
+	// dead. Idle sessions exchange heartbeats every 5 seconds.
+	// The default value is 20. It must be between 10 and 300.
+	DeadPeerTimeoutSeconds *int32 `protobuf:"varint,5,opt,name=deadPeerTimeoutSeconds,proto3,oneof" json:"deadPeerTimeoutSeconds,omitempty"`
+	// Maximum random delay in milliseconds before each segment is sent
+	// by the client. It breaks the precise inter-packet timing used by
+	// traffic classifiers, at the cost of some latency. If not set,
+	// segments are sent without delay. Maximum value is 50.
+	TimingJitterMillis *int32 `protobuf:"varint,6,opt,name=timingJitterMillis,proto3,oneof" json:"timingJitterMillis,omitempty"`
+	// Number of seconds a TCP connection waits for the next segment from
+	// the server before it is closed. If not set, there is no read deadline.
+	// Minimum value is 10.
+	UnderlayReadTimeoutSeconds *int32 `protobuf:"varint,7,opt,name=underlayReadTimeoutSeconds,proto3,oneof" json:"underlayReadTimeoutSeconds,omitempty"`
+	// Number of seconds without receiving anything from the server before
+	// a stuck TCP connection is closed. The default value is 600.
+	// Minimum value is 10.
+	StuckUnderlayTimeoutSeconds *int32 `protobuf:"varint,8,opt,name=stuckUnderlayTimeoutSeconds,proto3,oneof" json:"stuckUnderlayTimeoutSeconds,omitempty"`
+}
+
+func (x *ClientAdvancedSettings) Reset() {
+	*x = ClientAdvancedSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientcfg_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientAdvancedSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientAdvancedSettings) ProtoMessage() {}
+
+func (x *ClientAdvancedSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientAdvancedSettings.ProtoReflect.Descriptor instead.
+func (*ClientAdvancedSettings) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ClientAdvancedSettings) GetRetransmission() *RetransmissionSettings {
+	if x != nil {
+		return x.Retransmission
+	}
+	return nil
+}
+
+func (x *ClientAdvancedSettings) GetFlowControl() *FlowControlSettings {
+	if x != nil {
+		return x.FlowControl
+	}
+	return nil
+}
+
+func (x *ClientAdvancedSettings) GetPriorityRules() []*PriorityRule {
+	if x != nil {
+		return x.PriorityRules
+	}
+	return nil
+}
+
+func (x *ClientAdvancedSettings) GetDrainTimeoutSeconds() int32 {
+	if x != nil && x.DrainTimeoutSeconds != nil {
+		return *x.DrainTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ClientAdvancedSettings) GetDeadPeerTimeoutSeconds() int32 {
+	if x != nil && x.DeadPeerTimeoutSeconds != nil {
+		return *x.DeadPeerTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ClientAdvancedSettings) GetTimingJitterMillis() int32 {
+	if x != nil && x.TimingJitterMillis != nil {
+		return *x.TimingJitterMillis
+	}
+	return 0
+}
+
+func (x *ClientAdvancedSettings) GetUnderlayReadTimeoutSeconds() int32 {
+	if x != nil && x.UnderlayReadTimeoutSeconds != nil {
+		return *x.UnderlayReadTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ClientAdvancedSettings) GetStuckUnderlayTimeoutSeconds() int32 {
+	if x != nil && x.StuckUnderlayTimeoutSeconds != nil {
+		return *x.StuckUnderlayTimeoutSeconds
+	}
+	return 0
+}
+
+type DomainRuleList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Path of a local file that contains the domain rules.
+	// Each line of the file is one of the following:
+	//   "full:<DOMAIN>" matches exactly the domain name.
+	//   "domain:<DOMAIN>" or "<DOMAIN>" matches the domain name and all subdomains.
+	//   "keyword:<KEYWORD>" matches domain names that contain the keyword.
+	// Empty lines and lines started with "#" are ignored.
+	FilePath *string `protobuf:"bytes,1,opt,name=filePath,proto3,oneof" json:"filePath,omitempty"`
+	// The action to do when a domain name in the list is matched.
+	Action *EgressAction `protobuf:"varint,2,opt,name=action,proto3,enum=appctl.EgressAction,oneof" json:"action,omitempty"`
+}
+
+func (x *DomainRuleList) Reset() {
+	*x = DomainRuleList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientcfg_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DomainRuleList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DomainRuleList) ProtoMessage() {}
+
+func (x *DomainRuleList) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DomainRuleList.ProtoReflect.Descriptor instead.
+func (*DomainRuleList) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DomainRuleList) GetFilePath() string {
+	if x != nil && x.FilePath != nil {
+		return *x.FilePath
+	}
+	return ""
+}
+
+func (x *DomainRuleList) GetAction() EgressAction {
+	if x != nil && x.Action != nil {
+		return *x.Action
+	}
+	return EgressAction_PROXY
+}
+
+type HTTPProxyTLS struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// If set, the HTTP proxy port serves HTTPS rather than plaintext HTTP.
+	Enable *bool `protobuf:"varint,1,opt,name=enable,proto3,oneof" json:"enable,omitempty"`
+	// Path of a PEM encoded certificate file.
+	// If not set, a self-signed certificate is generated and stored
+	// in the client configuration directory.
+	CertificateFile *string `protobuf:"bytes,2,opt,name=certificateFile,proto3,oneof" json:"certificateFile,omitempty"`
+	// Path of a PEM encoded private key file of the certificate.
+	// It must be set together with certificateFile.
+	PrivateKeyFile *string `protobuf:"bytes,3,opt,name=privateKeyFile,proto3,oneof" json:"privateKeyFile,omitempty"`
+}
+
+func (x *HTTPProxyTLS) Reset() {
+	*x = HTTPProxyTLS{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientcfg_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HTTPProxyTLS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HTTPProxyTLS) ProtoMessage() {}
+
+func (x *HTTPProxyTLS) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HTTPProxyTLS.ProtoReflect.Descriptor instead.
+func (*HTTPProxyTLS) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HTTPProxyTLS) GetEnable() bool {
+	if x != nil && x.Enable != nil {
+		return *x.Enable
+	}
+	return false
+}
+
+func (x *HTTPProxyTLS) GetCertificateFile() string {
+	if x != nil && x.CertificateFile != nil {
+		return *x.CertificateFile
+	}
+	return ""
+}
+
+func (x *HTTPProxyTLS) GetPrivateKeyFile() string {
+	if x != nil && x.PrivateKeyFile != nil {
+		return *x.PrivateKeyFile
+	}
+	return ""
+}
+
+type TransparentProxy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The port mieru is listening to accept the diverted TCP connections.
+	// It listens to all network interfaces.
+	Port *int32 `protobuf:"varint,1,opt,name=port,proto3,oneof" json:"port,omitempty"`
+	// How the connections are diverted to the port.
+	Mode *TransparentProxyMode `protobuf:"varint,2,opt,name=mode,proto3,enum=appctl.TransparentProxyMode,oneof" json:"mode,omitempty"`
+	// Rules to decide the egress action of connections created by local
+	// processes. The first matched rule is used. Connections not matched
+	// by any rule, including connections from other devices, are handled
+	// by the egress rules. This setting only takes effect on Linux.
+	ApplicationRules []*ApplicationRule `protobuf:"bytes,3,rep,name=applicationRules,proto3" json:"applicationRules,omitempty"`
+}
+
+func (x *TransparentProxy) Reset() {
+	*x = TransparentProxy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientcfg_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransparentProxy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransparentProxy) ProtoMessage() {}
+
+func (x *TransparentProxy) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransparentProxy.ProtoReflect.Descriptor instead.
+func (*TransparentProxy) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TransparentProxy) GetPort() int32 {
+	if x != nil && x.Port != nil {
+		return *x.Port
+	}
+	return 0
+}
+
+func (x *TransparentProxy) GetMode() TransparentProxyMode {
+	if x != nil && x.Mode != nil {
+		return *x.Mode
+	}
+	return TransparentProxyMode_TRANSPARENT_PROXY_REDIRECT
+}
+
+func (x *TransparentProxy) GetApplicationRules() []*ApplicationRule {
+	if x != nil {
+		return x.ApplicationRules
+	}
+	return nil
+}
+
+type ApplicationRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// If set, the rule matches processes run by this user ID.
+	Uid *int32 `protobuf:"varint,1,opt,name=uid,proto3,oneof" json:"uid,omitempty"`
+	// If set, the rule matches processes in this cgroup and all its
+	// descendants, for example "/user.slice/user-1000.slice".
+	// It must be an absolute cgroup v2 path. If both uid and cgroupPath
+	// are set, both of them must match.
+	CgroupPath *string `protobuf:"bytes,2,opt,name=cgroupPath,proto3,oneof" json:"cgroupPath,omitempty"`
+	// The action to do when the rule is matched.
+	Action *EgressAction `protobuf:"varint,3,opt,name=action,proto3,enum=appctl.EgressAction,oneof" json:"action,omitempty"`
 }
 
-func (x *ClientProfile) Reset() {
-	*x = ClientProfile{}
+func (x *ApplicationRule) Reset() {
+	*x = ApplicationRule{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_clientcfg_proto_msgTypes[0]
+		mi := &file_clientcfg_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ClientProfile) String() string {
+func (x *ApplicationRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientProfile) ProtoMessage() {}
+func (*ApplicationRule) ProtoMessage() {}
 
-func (x *ClientProfile) ProtoReflect() protoreflect.Message {
-	mi := &file_clientcfg_proto_msgTypes[0]
+func (x *ApplicationRule) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -80,69 +719,124 @@ func (x *ClientProfile) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClientProfile.ProtoReflect.Descriptor instead.
-func (*ClientProfile) Descriptor() ([]byte, []int) {
-	return file_clientcfg_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use ApplicationRule.ProtoReflect.Descriptor instead.
+func (*ApplicationRule) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ClientProfile) GetProfileName() string {
-	if x != nil && x.ProfileName != nil {
-		return *x.ProfileName
+func (x *ApplicationRule) GetUid() int32 {
+	if x != nil && x.Uid != nil {
+		return *x.Uid
+	}
+	return 0
+}
+
+func (x *ApplicationRule) GetCgroupPath() string {
+	if x != nil && x.CgroupPath != nil {
+		return *x.CgroupPath
 	}
 	return ""
 }
 
-func (x *ClientProfile) GetUser() *User {
-	if x != nil {
-		return x.User
+func (x *ApplicationRule) GetAction() EgressAction {
+	if x != nil && x.Action != nil {
+		return *x.Action
 	}
-	return nil
+	return EgressAction_PROXY
 }
 
-func (x *ClientProfile) GetServers() []*ServerEndpoint {
-	if x != nil {
-		return x.Servers
+type DNSLeakProtection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The port mieru is listening in localhost to accept plain DNS queries
+	// over both UDP and TCP.
+	Port *int32 `protobuf:"varint,1,opt,name=port,proto3,oneof" json:"port,omitempty"`
+	// The DNS server that answers the queries, in "<HOST>:<PORT>" format.
+	// The queries are sent to it through the proxy with DNS over TCP.
+	// If not set, "1.1.1.1:53" is used.
+	Upstream *string `protobuf:"bytes,2,opt,name=upstream,proto3,oneof" json:"upstream,omitempty"`
+}
+
+func (x *DNSLeakProtection) Reset() {
+	*x = DNSLeakProtection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientcfg_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return nil
 }
 
-func (x *ClientProfile) GetMtu() int32 {
-	if x != nil && x.Mtu != nil {
-		return *x.Mtu
+func (x *DNSLeakProtection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSLeakProtection) ProtoMessage() {}
+
+func (x *DNSLeakProtection) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSLeakProtection.ProtoReflect.Descriptor instead.
+func (*DNSLeakProtection) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DNSLeakProtection) GetPort() int32 {
+	if x != nil && x.Port != nil {
+		return *x.Port
 	}
 	return 0
 }
 
-func (x *ClientProfile) GetMultiplexing() *MultiplexingConfig {
-	if x != nil {
-		return x.Multiplexing
+func (x *DNSLeakProtection) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
 	}
-	return nil
+	return ""
 }
 
-type ClientAdvancedSettings struct {
+type ProfileListener struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// Name of the profile used by this listener.
+	ProfileName *string `protobuf:"bytes,1,opt,name=profileName,proto3,oneof" json:"profileName,omitempty"`
+	// The socks5 port of this listener. The port listens to LAN
+	// if socks5ListenLAN is set.
+	Socks5Port *int32 `protobuf:"varint,2,opt,name=socks5Port,proto3,oneof" json:"socks5Port,omitempty"`
+	// If set, the port to provide HTTP / HTTPS proxy with this profile.
+	// The port listens to LAN if httpProxyListenLAN is set.
+	HttpProxyPort *int32 `protobuf:"varint,3,opt,name=httpProxyPort,proto3,oneof" json:"httpProxyPort,omitempty"`
 }
 
-func (x *ClientAdvancedSettings) Reset() {
-	*x = ClientAdvancedSettings{}
+func (x *ProfileListener) Reset() {
+	*x = ProfileListener{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_clientcfg_proto_msgTypes[1]
+		mi := &file_clientcfg_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ClientAdvancedSettings) String() string {
+func (x *ProfileListener) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientAdvancedSettings) ProtoMessage() {}
+func (*ProfileListener) ProtoMessage() {}
 
-func (x *ClientAdvancedSettings) ProtoReflect() protoreflect.Message {
-	mi := &file_clientcfg_proto_msgTypes[1]
+func (x *ProfileListener) ProtoReflect() protoreflect.Message {
+	mi := &file_clientcfg_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -153,9 +847,30 @@ func (x *ClientAdvancedSettings) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClientAdvancedSettings.ProtoReflect.Descriptor instead.
-func (*ClientAdvancedSettings) Descriptor() ([]byte, []int) {
-	return file_clientcfg_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ProfileListener.ProtoReflect.Descriptor instead.
+func (*ProfileListener) Descriptor() ([]byte, []int) {
+	return file_clientcfg_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ProfileListener) GetProfileName() string {
+	if x != nil && x.ProfileName != nil {
+		return *x.ProfileName
+	}
+	return ""
+}
+
+func (x *ProfileListener) GetSocks5Port() int32 {
+	if x != nil && x.Socks5Port != nil {
+		return *x.Socks5Port
+	}
+	return 0
+}
+
+func (x *ProfileListener) GetHttpProxyPort() int32 {
+	if x != nil && x.HttpProxyPort != nil {
+		return *x.HttpProxyPort
+	}
+	return 0
 }
 
 type ClientConfig struct {
@@ -181,12 +896,71 @@ type ClientConfig struct {
 	HttpProxyPort *int32 `protobuf:"varint,8,opt,name=httpProxyPort,proto3,oneof" json:"httpProxyPort,omitempty"`
 	// If set, the HTTP proxy port listens to LAN rather than localhost.
 	HttpProxyListenLAN *bool `protobuf:"varint,9,opt,name=httpProxyListenLAN,proto3,oneof" json:"httpProxyListenLAN,omitempty"`
+	// A list of domain rule lists that decide how to connect to a domain name.
+	// The lists are evaluated in order, and the first match wins.
+	// If no list is matched, the action is PROXY.
+	DomainRuleLists []*DomainRuleList `protobuf:"bytes,10,rep,name=domainRuleLists,proto3" json:"domainRuleLists,omitempty"`
+	// If set, domain names are always resolved by the mieru server.
+	// A destination specified by domain name never connects directly
+	// from the client, even if a domain rule list decides DIRECT.
+	// This prevents DNS leaks when the local resolver is untrustworthy.
+	RemoteDNSResolution *bool `protobuf:"varint,11,opt,name=remoteDNSResolution,proto3,oneof" json:"remoteDNSResolution,omitempty"`
+	// A list of encrypted DNS servers to resolve the domain name of proxy
+	// servers. The servers are tried in order. Supported formats are
+	//   "https://<HOST>[:<PORT>]/<PATH>" for DNS over HTTPS, and
+	//   "tls://<HOST>[:<PORT>]" for DNS over TLS.
+	// If not set, DNS servers of the operating system are used.
+	DnsUpstreams []string `protobuf:"bytes,12,rep,name=dnsUpstreams,proto3" json:"dnsUpstreams,omitempty"`
+	// TLS settings of the HTTP proxy port.
+	HttpProxyTLS *HTTPProxyTLS `protobuf:"bytes,13,opt,name=httpProxyTLS,proto3,oneof" json:"httpProxyTLS,omitempty"`
+	// Push metrics to a StatsD server.
+	Statsd *StatsdExport `protobuf:"bytes,14,opt,name=statsd,proto3,oneof" json:"statsd,omitempty"`
+	// Export traces of session lifecycle to an OpenTelemetry collector.
+	Tracing *TracingExport `protobuf:"bytes,15,opt,name=tracing,proto3,oneof" json:"tracing,omitempty"`
+	// If set, count the traffic of each destination domain name or
+	// IP address connected via proxy, which is shown by "mieru get top".
+	// The data is only kept in memory. It is disabled by default for privacy.
+	TopDestinations *bool `protobuf:"varint,16,opt,name=topDestinations,proto3,oneof" json:"topDestinations,omitempty"`
+	// Send events, such as endpoint failover, to a webhook.
+	Webhook *WebhookExport `protobuf:"bytes,17,opt,name=webhook,proto3,oneof" json:"webhook,omitempty"`
+	// Ship log entries to a remote collector.
+	LogShipping *LogShipping `protobuf:"bytes,18,opt,name=logShipping,proto3,oneof" json:"logShipping,omitempty"`
+	// Accept TCP connections diverted by iptables and forward them via
+	// proxy. It is only supported on Linux.
+	TransparentProxy *TransparentProxy `protobuf:"bytes,19,opt,name=transparentProxy,proto3,oneof" json:"transparentProxy,omitempty"`
+	// If set, the socks5 server also listens to the Unix domain socket
+	// at this absolute path, in addition to the socks5 port.
+	Socks5UnixSocketPath *string `protobuf:"bytes,20,opt,name=socks5UnixSocketPath,proto3,oneof" json:"socks5UnixSocketPath,omitempty"`
+	// Additional socks5 and HTTP proxy ports, each using a profile
+	// other than the active profile. They run in the same client daemon
+	// together with the active profile.
+	ProfileListeners []*ProfileListener `protobuf:"bytes,21,rep,name=profileListeners,proto3" json:"profileListeners,omitempty"`
+	// If set, the proxy settings of the operating system are changed to
+	// the socks5 port and HTTP proxy port when the client starts, and
+	// restored when the client stops.
+	SystemProxy *bool `protobuf:"varint,22,opt,name=systemProxy,proto3,oneof" json:"systemProxy,omitempty"`
+	// If set, plain DNS queries received from a local port are answered
+	// through the proxy, and domain names are always resolved by the
+	// mieru server, as if remoteDNSResolution is set.
+	DnsLeakProtection *DNSLeakProtection `protobuf:"bytes,23,opt,name=dnsLeakProtection,proto3,oneof" json:"dnsLeakProtection,omitempty"`
+	// If set, the RPC server listens to this Unix domain socket instead
+	// of rpcPort. It must be an absolute path. The socket file can only
+	// be accessed by the user who runs the client.
+	RpcSocketPath *string `protobuf:"bytes,24,opt,name=rpcSocketPath,proto3,oneof" json:"rpcSocketPath,omitempty"`
+	// If set, a web dashboard is served at this port of localhost.
+	// The dashboard reads data with the lifecycle RPC, so rpcPort or
+	// rpcSocketPath must be set as well.
+	WebDashboardPort *int32 `protobuf:"varint,25,opt,name=webDashboardPort,proto3,oneof" json:"webDashboardPort,omitempty"`
+	// If set, the lifecycle RPC methods are also served as a REST API
+	// with JSON encoding at this port of localhost. Requests must carry
+	// the RPC token. rpcPort or rpcSocketPath must be set as well.
+	RestApiPort *int32 `protobuf:"varint,26,opt,name=restApiPort,proto3,oneof" json:"restApiPort,omitempty"`
 }
 
 func (x *ClientConfig) Reset() {
 	*x = ClientConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_clientcfg_proto_msgTypes[2]
+		mi := &file_clientcfg_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -199,7 +973,7 @@ func (x *ClientConfig) String() string {
 func (*ClientConfig) ProtoMessage() {}
 
 func (x *ClientConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_clientcfg_proto_msgTypes[2]
+	mi := &file_clientcfg_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -212,7 +986,7 @@ func (x *ClientConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClientConfig.ProtoReflect.Descriptor instead.
 func (*ClientConfig) Descriptor() ([]byte, []int) {
-	return file_clientcfg_proto_rawDescGZIP(), []int{2}
+	return file_clientcfg_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ClientConfig) GetProfiles() []*ClientProfile {
@@ -278,76 +1052,416 @@ func (x *ClientConfig) GetHttpProxyListenLAN() bool {
 	return false
 }
 
+func (x *ClientConfig) GetDomainRuleLists() []*DomainRuleList {
+	if x != nil {
+		return x.DomainRuleLists
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetRemoteDNSResolution() bool {
+	if x != nil && x.RemoteDNSResolution != nil {
+		return *x.RemoteDNSResolution
+	}
+	return false
+}
+
+func (x *ClientConfig) GetDnsUpstreams() []string {
+	if x != nil {
+		return x.DnsUpstreams
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetHttpProxyTLS() *HTTPProxyTLS {
+	if x != nil {
+		return x.HttpProxyTLS
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetStatsd() *StatsdExport {
+	if x != nil {
+		return x.Statsd
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetTracing() *TracingExport {
+	if x != nil {
+		return x.Tracing
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetTopDestinations() bool {
+	if x != nil && x.TopDestinations != nil {
+		return *x.TopDestinations
+	}
+	return false
+}
+
+func (x *ClientConfig) GetWebhook() *WebhookExport {
+	if x != nil {
+		return x.Webhook
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetLogShipping() *LogShipping {
+	if x != nil {
+		return x.LogShipping
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetTransparentProxy() *TransparentProxy {
+	if x != nil {
+		return x.TransparentProxy
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetSocks5UnixSocketPath() string {
+	if x != nil && x.Socks5UnixSocketPath != nil {
+		return *x.Socks5UnixSocketPath
+	}
+	return ""
+}
+
+func (x *ClientConfig) GetProfileListeners() []*ProfileListener {
+	if x != nil {
+		return x.ProfileListeners
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetSystemProxy() bool {
+	if x != nil && x.SystemProxy != nil {
+		return *x.SystemProxy
+	}
+	return false
+}
+
+func (x *ClientConfig) GetDnsLeakProtection() *DNSLeakProtection {
+	if x != nil {
+		return x.DnsLeakProtection
+	}
+	return nil
+}
+
+func (x *ClientConfig) GetRpcSocketPath() string {
+	if x != nil && x.RpcSocketPath != nil {
+		return *x.RpcSocketPath
+	}
+	return ""
+}
+
+func (x *ClientConfig) GetWebDashboardPort() int32 {
+	if x != nil && x.WebDashboardPort != nil {
+		return *x.WebDashboardPort
+	}
+	return 0
+}
+
+func (x *ClientConfig) GetRestApiPort() int32 {
+	if x != nil && x.RestApiPort != nil {
+		return *x.RestApiPort
+	}
+	return 0
+}
+
 var File_clientcfg_proto protoreflect.FileDescriptor
 
 var file_clientcfg_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x63, 0x66, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x1a, 0x0e, 0x65, 0x6e, 0x64, 0x70, 0x6f,
-	0x69, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x6c, 0x6f, 0x67, 0x67, 0x69,
-	0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x12, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70,
-	0x6c, 0x65, 0x78, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0a, 0x75, 0x73,
-	0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9d, 0x02, 0x0a, 0x0d, 0x43, 0x6c, 0x69,
-	0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x25, 0x0a, 0x0b, 0x70, 0x72,
-	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48,
-	0x00, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01,
-	0x01, 0x12, 0x25, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0c, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x48, 0x01, 0x52,
-	0x04, 0x75, 0x73, 0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76,
-	0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x70, 0x70, 0x63,
+	0x6f, 0x12, 0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x1a, 0x0c, 0x65, 0x67, 0x72, 0x65, 0x73,
+	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0e, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x11, 0x66, 0x6c, 0x6f, 0x77, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f,
+	0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x12, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65, 0x78,
+	0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0e, 0x70, 0x72, 0x69, 0x6f, 0x72,
+	0x69, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x14, 0x72, 0x65, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x0a, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xdb, 0x03, 0x0a, 0x0d,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x25, 0x0a,
+	0x0b, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x55, 0x73, 0x65, 0x72,
+	0x48, 0x01, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a, 0x07, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61,
+	0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12, 0x15, 0x0a,
+	0x03, 0x6d, 0x74, 0x75, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x03, 0x6d, 0x74,
+	0x75, 0x88, 0x01, 0x01, 0x12, 0x43, 0x0a, 0x0c, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65,
+	0x78, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x70, 0x70,
+	0x63, 0x74, 0x6c, 0x2e, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65, 0x78, 0x69, 0x6e, 0x67,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x48, 0x03, 0x52, 0x0c, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70,
+	0x6c, 0x65, 0x78, 0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x12, 0x56, 0x0a, 0x11, 0x69, 0x70, 0x76,
+	0x36, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x49, 0x50,
+	0x76, 0x36, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x50,
+	0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x48, 0x04, 0x52, 0x11, 0x69, 0x70, 0x76,
+	0x36, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x88, 0x01,
+	0x01, 0x12, 0x3d, 0x0a, 0x0c, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c,
+	0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x05, 0x52,
+	0x0c, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01,
+	0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x42, 0x07, 0x0a, 0x05, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x6d, 0x74,
+	0x75, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65, 0x78, 0x69,
+	0x6e, 0x67, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x69, 0x70, 0x76, 0x36, 0x53, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x73, 0x75, 0x62,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x85, 0x01, 0x0a, 0x0c, 0x53, 0x75,
+	0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x0a, 0x03, 0x75, 0x72,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x88, 0x01,
+	0x01, 0x12, 0x3b, 0x0a, 0x16, 0x72, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x76, 0x61, 0x6c, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x01, 0x52, 0x16, 0x72, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x76, 0x61, 0x6c, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x88, 0x01, 0x01, 0x42, 0x06,
+	0x0a, 0x04, 0x5f, 0x75, 0x72, 0x6c, 0x42, 0x19, 0x0a, 0x17, 0x5f, 0x72, 0x65, 0x66, 0x72, 0x65,
+	0x73, 0x68, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65,
+	0x73, 0x22, 0x47, 0x0a, 0x13, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x30, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x70, 0x70, 0x63,
 	0x74, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
-	0x74, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12, 0x15, 0x0a, 0x03, 0x6d, 0x74,
-	0x75, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x03, 0x6d, 0x74, 0x75, 0x88, 0x01,
-	0x01, 0x12, 0x43, 0x0a, 0x0c, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65, 0x78, 0x69, 0x6e,
-	0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c,
-	0x2e, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65, 0x78, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0x48, 0x03, 0x52, 0x0c, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x65, 0x78,
-	0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69,
-	0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x42,
-	0x06, 0x0a, 0x04, 0x5f, 0x6d, 0x74, 0x75, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6d, 0x75, 0x6c, 0x74,
-	0x69, 0x70, 0x6c, 0x65, 0x78, 0x69, 0x6e, 0x67, 0x22, 0x18, 0x0a, 0x16, 0x43, 0x6c, 0x69, 0x65,
-	0x6e, 0x74, 0x41, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e,
-	0x67, 0x73, 0x22, 0xdf, 0x04, 0x0a, 0x0c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0x12, 0x31, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x43,
-	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x08, 0x70, 0x72,
-	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65,
-	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
-	0x0d, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x88, 0x01,
-	0x01, 0x12, 0x1d, 0x0a, 0x07, 0x72, 0x70, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x05, 0x48, 0x01, 0x52, 0x07, 0x72, 0x70, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x88, 0x01, 0x01,
-	0x12, 0x23, 0x0a, 0x0a, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x0a, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x50, 0x6f,
-	0x72, 0x74, 0x88, 0x01, 0x01, 0x12, 0x4f, 0x0a, 0x10, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
-	0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1e, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x41,
-	0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x48,
-	0x03, 0x52, 0x10, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74, 0x74, 0x69,
-	0x6e, 0x67, 0x73, 0x88, 0x01, 0x01, 0x12, 0x3d, 0x0a, 0x0c, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e,
-	0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61,
-	0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76,
-	0x65, 0x6c, 0x48, 0x04, 0x52, 0x0c, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76,
-	0x65, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x4c,
-	0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c, 0x41, 0x4e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x48, 0x05,
-	0x52, 0x0f, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c, 0x41,
-	0x4e, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78,
-	0x79, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x48, 0x06, 0x52, 0x0d, 0x68,
-	0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x6f, 0x72, 0x74, 0x88, 0x01, 0x01, 0x12,
-	0x33, 0x0a, 0x12, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x4c, 0x69, 0x73, 0x74,
-	0x65, 0x6e, 0x4c, 0x41, 0x4e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x48, 0x07, 0x52, 0x12, 0x68,
-	0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c, 0x41,
-	0x4e, 0x88, 0x01, 0x01, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x50,
-	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x72, 0x70, 0x63, 0x50, 0x6f,
-	0x72, 0x74, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x50, 0x6f, 0x72,
-	0x74, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65,
-	0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6c, 0x6f, 0x67, 0x67, 0x69,
-	0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x73, 0x6f, 0x63, 0x6b,
-	0x73, 0x35, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c, 0x41, 0x4e, 0x42, 0x10, 0x0a, 0x0e, 0x5f,
-	0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x6f, 0x72, 0x74, 0x42, 0x15, 0x0a,
-	0x13, 0x5f, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x65,
-	0x6e, 0x4c, 0x41, 0x4e, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
-	0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f,
-	0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74,
-	0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x22, 0xc6, 0x05, 0x0a, 0x16, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74,
+	0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x4b, 0x0a, 0x0e, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x48, 0x00, 0x52,
+	0x0e, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x88,
+	0x01, 0x01, 0x12, 0x42, 0x0a, 0x0b, 0x66, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c,
+	0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x53, 0x65, 0x74, 0x74,
+	0x69, 0x6e, 0x67, 0x73, 0x48, 0x01, 0x52, 0x0b, 0x66, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e, 0x74,
+	0x72, 0x6f, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x3a, 0x0a, 0x0d, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69,
+	0x74, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52,
+	0x75, 0x6c, 0x65, 0x52, 0x0d, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x75, 0x6c,
+	0x65, 0x73, 0x12, 0x35, 0x0a, 0x13, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x02, 0x52, 0x13, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x3b, 0x0a, 0x16, 0x64, 0x65, 0x61,
+	0x64, 0x50, 0x65, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52, 0x16, 0x64, 0x65, 0x61,
+	0x64, 0x50, 0x65, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x12, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67,
+	0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x04, 0x52, 0x12, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x4a, 0x69, 0x74, 0x74,
+	0x65, 0x72, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x43, 0x0a, 0x1a, 0x75,
+	0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x52, 0x65, 0x61, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x05, 0x52, 0x1a, 0x75, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x52, 0x65, 0x61, 0x64, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01,
+	0x12, 0x45, 0x0a, 0x1b, 0x73, 0x74, 0x75, 0x63, 0x6b, 0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61,
+	0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x05, 0x48, 0x06, 0x52, 0x1b, 0x73, 0x74, 0x75, 0x63, 0x6b, 0x55, 0x6e,
+	0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x72, 0x65, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x66,
+	0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x64,
+	0x72, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x42, 0x19, 0x0a, 0x17, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x50, 0x65, 0x65, 0x72, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x42, 0x15, 0x0a,
+	0x13, 0x5f, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x4d, 0x69,
+	0x6c, 0x6c, 0x69, 0x73, 0x42, 0x1d, 0x0a, 0x1b, 0x5f, 0x75, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61,
+	0x79, 0x52, 0x65, 0x61, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x42, 0x1e, 0x0a, 0x1c, 0x5f, 0x73, 0x74, 0x75, 0x63, 0x6b, 0x55, 0x6e, 0x64,
+	0x65, 0x72, 0x6c, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x22, 0x7c, 0x0a, 0x0e, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x52, 0x75, 0x6c,
+	0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x50, 0x61, 0x74,
+	0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x50,
+	0x61, 0x74, 0x68, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e,
+	0x45, 0x67, 0x72, 0x65, 0x73, 0x73, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x01, 0x52, 0x06,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x66, 0x69,
+	0x6c, 0x65, 0x50, 0x61, 0x74, 0x68, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0xb9, 0x01, 0x0a, 0x0c, 0x48, 0x54, 0x54, 0x50, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x54,
+	0x4c, 0x53, 0x12, 0x1b, 0x0a, 0x06, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x48, 0x00, 0x52, 0x06, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x12,
+	0x2d, 0x0a, 0x0f, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x46, 0x69,
+	0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0f, 0x63, 0x65, 0x72, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x12, 0x2b,
+	0x0a, 0x0e, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x46, 0x69, 0x6c, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x0e, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74,
+	0x65, 0x4b, 0x65, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f,
+	0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x70,
+	0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x22, 0xb9, 0x01,
+	0x0a, 0x10, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f,
+	0x78, 0x79, 0x12, 0x17, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x48, 0x00, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x88, 0x01, 0x01, 0x12, 0x35, 0x0a, 0x04, 0x6d,
+	0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x61, 0x70, 0x70, 0x63,
+	0x74, 0x6c, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x72,
+	0x6f, 0x78, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x48, 0x01, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x88,
+	0x01, 0x01, 0x12, 0x43, 0x0a, 0x10, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61,
+	0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x10, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x70, 0x6f, 0x72, 0x74,
+	0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0xa2, 0x01, 0x0a, 0x0f, 0x41, 0x70,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x15, 0x0a,
+	0x03, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x03, 0x75, 0x69,
+	0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x61,
+	0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0a, 0x63, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x50, 0x61, 0x74, 0x68, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x06, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63,
+	0x74, 0x6c, 0x2e, 0x45, 0x67, 0x72, 0x65, 0x73, 0x73, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x48,
+	0x02, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x06, 0x0a, 0x04,
+	0x5f, 0x75, 0x69, 0x64, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x50,
+	0x61, 0x74, 0x68, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x63,
+	0x0a, 0x11, 0x44, 0x4e, 0x53, 0x4c, 0x65, 0x61, 0x6b, 0x50, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x00, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08,
+	0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01,
+	0x52, 0x08, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a,
+	0x05, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x75, 0x70, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x22, 0xb9, 0x01, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4c,
+	0x69, 0x73, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b,
+	0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x23,
+	0x0a, 0x0a, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x01, 0x52, 0x0a, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x50, 0x6f, 0x72, 0x74,
+	0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79,
+	0x50, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x0d, 0x68, 0x74,
+	0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x6f, 0x72, 0x74, 0x88, 0x01, 0x01, 0x42, 0x0e,
+	0x0a, 0x0c, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x0d,
+	0x0a, 0x0b, 0x5f, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x50, 0x6f, 0x72, 0x74, 0x42, 0x10, 0x0a,
+	0x0e, 0x5f, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x6f, 0x72, 0x74, 0x22,
+	0x81, 0x0e, 0x0a, 0x0c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x12, 0x31, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x50, 0x72, 0x6f,
+	0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0d, 0x61, 0x63,
+	0x74, 0x69, 0x76, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1d,
+	0x0a, 0x07, 0x72, 0x70, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x01, 0x52, 0x07, 0x72, 0x70, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a,
+	0x0a, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x02, 0x52, 0x0a, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x50, 0x6f, 0x72, 0x74, 0x88,
+	0x01, 0x01, 0x12, 0x4f, 0x0a, 0x10, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65,
+	0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x61,
+	0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x76, 0x61,
+	0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x48, 0x03, 0x52, 0x10,
+	0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73,
+	0x88, 0x01, 0x01, 0x12, 0x3d, 0x0a, 0x0c, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63,
+	0x74, 0x6c, 0x2e, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x48,
+	0x04, 0x52, 0x0c, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x88,
+	0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x4c, 0x69, 0x73, 0x74,
+	0x65, 0x6e, 0x4c, 0x41, 0x4e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x48, 0x05, 0x52, 0x0f, 0x73,
+	0x6f, 0x63, 0x6b, 0x73, 0x35, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c, 0x41, 0x4e, 0x88, 0x01,
+	0x01, 0x12, 0x29, 0x0a, 0x0d, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x6f,
+	0x72, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x48, 0x06, 0x52, 0x0d, 0x68, 0x74, 0x74, 0x70,
+	0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x6f, 0x72, 0x74, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x12,
+	0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c,
+	0x41, 0x4e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x48, 0x07, 0x52, 0x12, 0x68, 0x74, 0x74, 0x70,
+	0x50, 0x72, 0x6f, 0x78, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c, 0x41, 0x4e, 0x88, 0x01,
+	0x01, 0x12, 0x40, 0x0a, 0x0f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x4c,
+	0x69, 0x73, 0x74, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x70, 0x70,
+	0x63, 0x74, 0x6c, 0x2e, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x4c, 0x69,
+	0x73, 0x74, 0x52, 0x0f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x4c, 0x69,
+	0x73, 0x74, 0x73, 0x12, 0x35, 0x0a, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x44, 0x4e, 0x53,
+	0x52, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08,
+	0x48, 0x08, 0x52, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x73,
+	0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x6e,
+	0x73, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0c, 0x64, 0x6e, 0x73, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x12, 0x3d,
+	0x0a, 0x0c, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x54, 0x4c, 0x53, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x48, 0x54,
+	0x54, 0x50, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x54, 0x4c, 0x53, 0x48, 0x09, 0x52, 0x0c, 0x68, 0x74,
+	0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x54, 0x4c, 0x53, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x73, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x64, 0x45, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x48, 0x0a, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x73, 0x64, 0x88, 0x01, 0x01,
+	0x12, 0x34, 0x0a, 0x07, 0x74, 0x72, 0x61, 0x63, 0x69, 0x6e, 0x67, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x69,
+	0x6e, 0x67, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x48, 0x0b, 0x52, 0x07, 0x74, 0x72, 0x61, 0x63,
+	0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x74, 0x6f, 0x70, 0x44, 0x65, 0x73,
+	0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x0c, 0x52, 0x0f, 0x74, 0x6f, 0x70, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a, 0x07, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e,
+	0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x48, 0x0d, 0x52,
+	0x07, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x3a, 0x0a, 0x0b, 0x6c,
+	0x6f, 0x67, 0x53, 0x68, 0x69, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4c, 0x6f, 0x67, 0x53, 0x68, 0x69,
+	0x70, 0x70, 0x69, 0x6e, 0x67, 0x48, 0x0e, 0x52, 0x0b, 0x6c, 0x6f, 0x67, 0x53, 0x68, 0x69, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x12, 0x49, 0x0a, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x18, 0x13, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x48, 0x0f, 0x52, 0x10, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x88,
+	0x01, 0x01, 0x12, 0x37, 0x0a, 0x14, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x55, 0x6e, 0x69, 0x78,
+	0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x50, 0x61, 0x74, 0x68, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x10, 0x52, 0x14, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x55, 0x6e, 0x69, 0x78, 0x53, 0x6f,
+	0x63, 0x6b, 0x65, 0x74, 0x50, 0x61, 0x74, 0x68, 0x88, 0x01, 0x01, 0x12, 0x43, 0x0a, 0x10, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x73, 0x18,
+	0x15, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x52, 0x10,
+	0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x73,
+	0x12, 0x25, 0x0a, 0x0b, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x18,
+	0x16, 0x20, 0x01, 0x28, 0x08, 0x48, 0x11, 0x52, 0x0b, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x50,
+	0x72, 0x6f, 0x78, 0x79, 0x88, 0x01, 0x01, 0x12, 0x4c, 0x0a, 0x11, 0x64, 0x6e, 0x73, 0x4c, 0x65,
+	0x61, 0x6b, 0x50, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x17, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x44, 0x4e, 0x53, 0x4c,
+	0x65, 0x61, 0x6b, 0x50, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x12, 0x52,
+	0x11, 0x64, 0x6e, 0x73, 0x4c, 0x65, 0x61, 0x6b, 0x50, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x72, 0x70, 0x63, 0x53, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x50, 0x61, 0x74, 0x68, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x48, 0x13, 0x52, 0x0d,
+	0x72, 0x70, 0x63, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x50, 0x61, 0x74, 0x68, 0x88, 0x01, 0x01,
+	0x12, 0x2f, 0x0a, 0x10, 0x77, 0x65, 0x62, 0x44, 0x61, 0x73, 0x68, 0x62, 0x6f, 0x61, 0x72, 0x64,
+	0x50, 0x6f, 0x72, 0x74, 0x18, 0x19, 0x20, 0x01, 0x28, 0x05, 0x48, 0x14, 0x52, 0x10, 0x77, 0x65,
+	0x62, 0x44, 0x61, 0x73, 0x68, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x50, 0x6f, 0x72, 0x74, 0x88, 0x01,
+	0x01, 0x12, 0x25, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x74, 0x41, 0x70, 0x69, 0x50, 0x6f, 0x72, 0x74,
+	0x18, 0x1a, 0x20, 0x01, 0x28, 0x05, 0x48, 0x15, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x74, 0x41, 0x70,
+	0x69, 0x50, 0x6f, 0x72, 0x74, 0x88, 0x01, 0x01, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x61, 0x63, 0x74,
+	0x69, 0x76, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x72,
+	0x70, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x73, 0x6f, 0x63, 0x6b, 0x73,
+	0x35, 0x50, 0x6f, 0x72, 0x74, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63,
+	0x65, 0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6c,
+	0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x12, 0x0a, 0x10, 0x5f,
+	0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c, 0x41, 0x4e, 0x42,
+	0x10, 0x0a, 0x0e, 0x5f, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x6f, 0x72,
+	0x74, 0x42, 0x15, 0x0a, 0x13, 0x5f, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x4c,
+	0x69, 0x73, 0x74, 0x65, 0x6e, 0x4c, 0x41, 0x4e, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x72, 0x65, 0x6d,
+	0x6f, 0x74, 0x65, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x54, 0x4c,
+	0x53, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x64, 0x42, 0x0a, 0x0a, 0x08,
+	0x5f, 0x74, 0x72, 0x61, 0x63, 0x69, 0x6e, 0x67, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x74, 0x6f, 0x70,
+	0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x42, 0x0a, 0x0a, 0x08,
+	0x5f, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6c, 0x6f, 0x67,
+	0x53, 0x68, 0x69, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x42, 0x17, 0x0a,
+	0x15, 0x5f, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x35, 0x55, 0x6e, 0x69, 0x78, 0x53, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x50, 0x61, 0x74, 0x68, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x64, 0x6e, 0x73, 0x4c, 0x65,
+	0x61, 0x6b, 0x50, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x10, 0x0a, 0x0e,
+	0x5f, 0x72, 0x70, 0x63, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x50, 0x61, 0x74, 0x68, 0x42, 0x13,
+	0x0a, 0x11, 0x5f, 0x77, 0x65, 0x62, 0x44, 0x61, 0x73, 0x68, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x50,
+	0x6f, 0x72, 0x74, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x72, 0x65, 0x73, 0x74, 0x41, 0x70, 0x69, 0x50,
+	0x6f, 0x72, 0x74, 0x2a, 0x77, 0x0a, 0x1b, 0x49, 0x50, 0x76, 0x36, 0x53, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x50, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x12, 0x17, 0x0a, 0x13, 0x49, 0x50, 0x56, 0x36, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43,
+	0x45, 0x5f, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x20, 0x0a, 0x1c, 0x49,
+	0x50, 0x56, 0x36, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x50, 0x52, 0x45, 0x46, 0x45,
+	0x52, 0x5f, 0x54, 0x45, 0x4d, 0x50, 0x4f, 0x52, 0x41, 0x52, 0x59, 0x10, 0x01, 0x12, 0x1d, 0x0a,
+	0x19, 0x49, 0x50, 0x56, 0x36, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x50, 0x52, 0x45,
+	0x46, 0x45, 0x52, 0x5f, 0x50, 0x55, 0x42, 0x4c, 0x49, 0x43, 0x10, 0x02, 0x2a, 0x54, 0x0a, 0x14,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x78, 0x79,
+	0x4d, 0x6f, 0x64, 0x65, 0x12, 0x1e, 0x0a, 0x1a, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x41, 0x52,
+	0x45, 0x4e, 0x54, 0x5f, 0x50, 0x52, 0x4f, 0x58, 0x59, 0x5f, 0x52, 0x45, 0x44, 0x49, 0x52, 0x45,
+	0x43, 0x54, 0x10, 0x00, 0x12, 0x1c, 0x0a, 0x18, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x41, 0x52,
+	0x45, 0x4e, 0x54, 0x5f, 0x50, 0x52, 0x4f, 0x58, 0x59, 0x5f, 0x54, 0x50, 0x52, 0x4f, 0x58, 0x59,
+	0x10, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b,
+	0x67, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -362,28 +1476,66 @@ func file_clientcfg_proto_rawDescGZIP() []byte {
 	return file_clientcfg_proto_rawDescData
 }
 
-var file_clientcfg_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_clientcfg_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_clientcfg_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_clientcfg_proto_goTypes = []interface{}{
-	(*ClientProfile)(nil),          // 0: appctl.ClientProfile
-	(*ClientAdvancedSettings)(nil), // 1: appctl.ClientAdvancedSettings
-	(*ClientConfig)(nil),           // 2: appctl.ClientConfig
-	(*User)(nil),                   // 3: appctl.User
-	(*ServerEndpoint)(nil),         // 4: appctl.ServerEndpoint
-	(*MultiplexingConfig)(nil),     // 5: appctl.MultiplexingConfig
-	(LoggingLevel)(0),              // 6: appctl.LoggingLevel
+	(IPv6SourceAddressPreference)(0), // 0: appctl.IPv6SourceAddressPreference
+	(TransparentProxyMode)(0),        // 1: appctl.TransparentProxyMode
+	(*ClientProfile)(nil),            // 2: appctl.ClientProfile
+	(*Subscription)(nil),             // 3: appctl.Subscription
+	(*SubscriptionContent)(nil),      // 4: appctl.SubscriptionContent
+	(*ClientAdvancedSettings)(nil),   // 5: appctl.ClientAdvancedSettings
+	(*DomainRuleList)(nil),           // 6: appctl.DomainRuleList
+	(*HTTPProxyTLS)(nil),             // 7: appctl.HTTPProxyTLS
+	(*TransparentProxy)(nil),         // 8: appctl.TransparentProxy
+	(*ApplicationRule)(nil),          // 9: appctl.ApplicationRule
+	(*DNSLeakProtection)(nil),        // 10: appctl.DNSLeakProtection
+	(*ProfileListener)(nil),          // 11: appctl.ProfileListener
+	(*ClientConfig)(nil),             // 12: appctl.ClientConfig
+	(*User)(nil),                     // 13: appctl.User
+	(*ServerEndpoint)(nil),           // 14: appctl.ServerEndpoint
+	(*MultiplexingConfig)(nil),       // 15: appctl.MultiplexingConfig
+	(*RetransmissionSettings)(nil),   // 16: appctl.RetransmissionSettings
+	(*FlowControlSettings)(nil),      // 17: appctl.FlowControlSettings
+	(*PriorityRule)(nil),             // 18: appctl.PriorityRule
+	(EgressAction)(0),                // 19: appctl.EgressAction
+	(LoggingLevel)(0),                // 20: appctl.LoggingLevel
+	(*StatsdExport)(nil),             // 21: appctl.StatsdExport
+	(*TracingExport)(nil),            // 22: appctl.TracingExport
+	(*WebhookExport)(nil),            // 23: appctl.WebhookExport
+	(*LogShipping)(nil),              // 24: appctl.LogShipping
 }
 var file_clientcfg_proto_depIdxs = []int32{
-	3, // 0: appctl.ClientProfile.user:type_name -> appctl.User
-	4, // 1: appctl.ClientProfile.servers:type_name -> appctl.ServerEndpoint
-	5, // 2: appctl.ClientProfile.multiplexing:type_name -> appctl.MultiplexingConfig
-	0, // 3: appctl.ClientConfig.profiles:type_name -> appctl.ClientProfile
-	1, // 4: appctl.ClientConfig.advancedSettings:type_name -> appctl.ClientAdvancedSettings
-	6, // 5: appctl.ClientConfig.loggingLevel:type_name -> appctl.LoggingLevel
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	13, // 0: appctl.ClientProfile.user:type_name -> appctl.User
+	14, // 1: appctl.ClientProfile.servers:type_name -> appctl.ServerEndpoint
+	15, // 2: appctl.ClientProfile.multiplexing:type_name -> appctl.MultiplexingConfig
+	0,  // 3: appctl.ClientProfile.ipv6SourceAddress:type_name -> appctl.IPv6SourceAddressPreference
+	3,  // 4: appctl.ClientProfile.subscription:type_name -> appctl.Subscription
+	14, // 5: appctl.SubscriptionContent.servers:type_name -> appctl.ServerEndpoint
+	16, // 6: appctl.ClientAdvancedSettings.retransmission:type_name -> appctl.RetransmissionSettings
+	17, // 7: appctl.ClientAdvancedSettings.flowControl:type_name -> appctl.FlowControlSettings
+	18, // 8: appctl.ClientAdvancedSettings.priorityRules:type_name -> appctl.PriorityRule
+	19, // 9: appctl.DomainRuleList.action:type_name -> appctl.EgressAction
+	1,  // 10: appctl.TransparentProxy.mode:type_name -> appctl.TransparentProxyMode
+	9,  // 11: appctl.TransparentProxy.applicationRules:type_name -> appctl.ApplicationRule
+	19, // 12: appctl.ApplicationRule.action:type_name -> appctl.EgressAction
+	2,  // 13: appctl.ClientConfig.profiles:type_name -> appctl.ClientProfile
+	5,  // 14: appctl.ClientConfig.advancedSettings:type_name -> appctl.ClientAdvancedSettings
+	20, // 15: appctl.ClientConfig.loggingLevel:type_name -> appctl.LoggingLevel
+	6,  // 16: appctl.ClientConfig.domainRuleLists:type_name -> appctl.DomainRuleList
+	7,  // 17: appctl.ClientConfig.httpProxyTLS:type_name -> appctl.HTTPProxyTLS
+	21, // 18: appctl.ClientConfig.statsd:type_name -> appctl.StatsdExport
+	22, // 19: appctl.ClientConfig.tracing:type_name -> appctl.TracingExport
+	23, // 20: appctl.ClientConfig.webhook:type_name -> appctl.WebhookExport
+	24, // 21: appctl.ClientConfig.logShipping:type_name -> appctl.LogShipping
+	8,  // 22: appctl.ClientConfig.transparentProxy:type_name -> appctl.TransparentProxy
+	11, // 23: appctl.ClientConfig.profileListeners:type_name -> appctl.ProfileListener
+	10, // 24: appctl.ClientConfig.dnsLeakProtection:type_name -> appctl.DNSLeakProtection
+	25, // [25:25] is the sub-list for method output_type
+	25, // [25:25] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_clientcfg_proto_init() }
@@ -391,9 +1543,15 @@ func file_clientcfg_proto_init() {
 	if File_clientcfg_proto != nil {
 		return
 	}
+	file_egress_proto_init()
 	file_endpoint_proto_init()
+	file_event_proto_init()
+	file_flowcontrol_proto_init()
 	file_logging_proto_init()
+	file_metrics_proto_init()
 	file_multiplexing_proto_init()
+	file_priority_proto_init()
+	file_retransmission_proto_init()
 	file_user_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_clientcfg_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
@@ -409,7 +1567,7 @@ func file_clientcfg_proto_init() {
 			}
 		}
 		file_clientcfg_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClientAdvancedSettings); i {
+			switch v := v.(*Subscription); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -421,6 +1579,102 @@ func file_clientcfg_proto_init() {
 			}
 		}
 		file_clientcfg_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscriptionContent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientcfg_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientAdvancedSettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientcfg_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DomainRuleList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientcfg_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HTTPProxyTLS); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientcfg_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransparentProxy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientcfg_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApplicationRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientcfg_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DNSLeakProtection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientcfg_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProfileListener); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientcfg_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ClientConfig); i {
 			case 0:
 				return &v.state
@@ -434,19 +1688,28 @@ func file_clientcfg_proto_init() {
 		}
 	}
 	file_clientcfg_proto_msgTypes[0].OneofWrappers = []interface{}{}
-	file_clientcfg_proto_msgTypes[2].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[6].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[7].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[8].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[9].OneofWrappers = []interface{}{}
+	file_clientcfg_proto_msgTypes[10].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_clientcfg_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      2,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_clientcfg_proto_goTypes,
 		DependencyIndexes: file_clientcfg_proto_depIdxs,
+		EnumInfos:         file_clientcfg_proto_enumTypes,
 		MessageInfos:      file_clientcfg_proto_msgTypes,
 	}.Build()
 	File_clientcfg_proto = out.File
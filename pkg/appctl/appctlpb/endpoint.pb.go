@@ -84,6 +84,66 @@ func (TransportProtocol) EnumDescriptor() ([]byte, []int) {
 	return file_endpoint_proto_rawDescGZIP(), []int{0}
 }
 
+type Mimicry int32
+
+const (
+	// Send mieru traffic without disguise.
+	Mimicry_MIMICRY_PLAIN Mimicry = 0
+	// Wrap the TCP connection with TLS.
+	// This is only supported by TCP protocol.
+	Mimicry_MIMICRY_TLS Mimicry = 1
+	// Start the TCP connection with a WebSocket handshake,
+	// and carry the traffic in WebSocket binary frames.
+	// This is only supported by TCP protocol.
+	Mimicry_MIMICRY_WEBSOCKET Mimicry = 2
+	// Prepend a DNS message header to each UDP packet.
+	// This is only supported by UDP protocol.
+	Mimicry_MIMICRY_DNS Mimicry = 3
+)
+
+// Enum value maps for Mimicry.
+var (
+	Mimicry_name = map[int32]string{
+		0: "MIMICRY_PLAIN",
+		1: "MIMICRY_TLS",
+		2: "MIMICRY_WEBSOCKET",
+		3: "MIMICRY_DNS",
+	}
+	Mimicry_value = map[string]int32{
+		"MIMICRY_PLAIN":     0,
+		"MIMICRY_TLS":       1,
+		"MIMICRY_WEBSOCKET": 2,
+		"MIMICRY_DNS":       3,
+	}
+)
+
+func (x Mimicry) Enum() *Mimicry {
+	p := new(Mimicry)
+	*p = x
+	return p
+}
+
+func (x Mimicry) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Mimicry) Descriptor() protoreflect.EnumDescriptor {
+	return file_endpoint_proto_enumTypes[1].Descriptor()
+}
+
+func (Mimicry) Type() protoreflect.EnumType {
+	return &file_endpoint_proto_enumTypes[1]
+}
+
+func (x Mimicry) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Mimicry.Descriptor instead.
+func (Mimicry) EnumDescriptor() ([]byte, []int) {
+	return file_endpoint_proto_rawDescGZIP(), []int{1}
+}
+
 type PortBinding struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -97,6 +157,10 @@ type PortBinding struct {
 	// For example, "8000-9000" contains 1001 ports from 8000 to 9000.
 	// This field can't be set with port at the same time.
 	PortRange *string `protobuf:"bytes,3,opt,name=portRange,proto3,oneof" json:"portRange,omitempty"`
+	// The disguise of the traffic sent to this port.
+	// The client and the server must use the same value.
+	// If not set, the default value is MIMICRY_PLAIN.
+	Mimicry *Mimicry `protobuf:"varint,4,opt,name=mimicry,proto3,enum=appctl.Mimicry,oneof" json:"mimicry,omitempty"`
 }
 
 func (x *PortBinding) Reset() {
@@ -152,6 +216,13 @@ func (x *PortBinding) GetPortRange() string {
 	return ""
 }
 
+func (x *PortBinding) GetMimicry() Mimicry {
+	if x != nil && x.Mimicry != nil {
+		return *x.Mimicry
+	}
+	return Mimicry_MIMICRY_PLAIN
+}
+
 type ServerEndpoint struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -165,6 +236,9 @@ type ServerEndpoint struct {
 	DomainName *string `protobuf:"bytes,2,opt,name=domainName,proto3,oneof" json:"domainName,omitempty"`
 	// Server's port-protocol bindings.
 	PortBindings []*PortBinding `protobuf:"bytes,3,rep,name=portBindings,proto3" json:"portBindings,omitempty"`
+	// If set, the client also connects to the active port of
+	// the scheduled port rotation of the server.
+	PortRotation *PortRotation `protobuf:"bytes,4,opt,name=portRotation,proto3,oneof" json:"portRotation,omitempty"`
 }
 
 func (x *ServerEndpoint) Reset() {
@@ -220,11 +294,94 @@ func (x *ServerEndpoint) GetPortBindings() []*PortBinding {
 	return nil
 }
 
+func (x *ServerEndpoint) GetPortRotation() *PortRotation {
+	if x != nil {
+		return x.PortRotation
+	}
+	return nil
+}
+
+type PortRotation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The range of ports to rotate, e.g. "20000-30000".
+	PortRange *string `protobuf:"bytes,1,opt,name=portRange,proto3,oneof" json:"portRange,omitempty"`
+	// Transport protocol of the rotated ports.
+	Protocol *TransportProtocol `protobuf:"varint,2,opt,name=protocol,proto3,enum=appctl.TransportProtocol,oneof" json:"protocol,omitempty"`
+	// The active port changes every interval.
+	// Intervals are aligned to the Unix epoch.
+	IntervalMinutes *int32 `protobuf:"varint,3,opt,name=intervalMinutes,proto3,oneof" json:"intervalMinutes,omitempty"`
+	// Secret shared by server and clients to compute the active port.
+	Secret *string `protobuf:"bytes,4,opt,name=secret,proto3,oneof" json:"secret,omitempty"`
+}
+
+func (x *PortRotation) Reset() {
+	*x = PortRotation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_endpoint_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PortRotation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortRotation) ProtoMessage() {}
+
+func (x *PortRotation) ProtoReflect() protoreflect.Message {
+	mi := &file_endpoint_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortRotation.ProtoReflect.Descriptor instead.
+func (*PortRotation) Descriptor() ([]byte, []int) {
+	return file_endpoint_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PortRotation) GetPortRange() string {
+	if x != nil && x.PortRange != nil {
+		return *x.PortRange
+	}
+	return ""
+}
+
+func (x *PortRotation) GetProtocol() TransportProtocol {
+	if x != nil && x.Protocol != nil {
+		return *x.Protocol
+	}
+	return TransportProtocol_UNKNOWN_TRANSPORT_PROTOCOL
+}
+
+func (x *PortRotation) GetIntervalMinutes() int32 {
+	if x != nil && x.IntervalMinutes != nil {
+		return *x.IntervalMinutes
+	}
+	return 0
+}
+
+func (x *PortRotation) GetSecret() string {
+	if x != nil && x.Secret != nil {
+		return *x.Secret
+	}
+	return ""
+}
+
 var File_endpoint_proto protoreflect.FileDescriptor
 
 var file_endpoint_proto_rawDesc = []byte{
 	0x0a, 0x0e, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x22, 0xa9, 0x01, 0x0a, 0x0b, 0x50, 0x6f, 0x72,
+	0x12, 0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x22, 0xe5, 0x01, 0x0a, 0x0b, 0x50, 0x6f, 0x72,
 	0x74, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x17, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x88, 0x01,
 	0x01, 0x12, 0x3a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x02, 0x20,
@@ -233,28 +390,57 @@ var file_endpoint_proto_rawDesc = []byte{
 	0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a,
 	0x09, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
 	0x48, 0x02, 0x52, 0x09, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x2e, 0x0a, 0x07, 0x6d, 0x69, 0x6d, 0x69, 0x63, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4d, 0x69, 0x6d, 0x69, 0x63,
+	0x72, 0x79, 0x48, 0x03, 0x52, 0x07, 0x6d, 0x69, 0x6d, 0x69, 0x63, 0x72, 0x79, 0x88, 0x01, 0x01,
 	0x42, 0x07, 0x0a, 0x05, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x52,
-	0x61, 0x6e, 0x67, 0x65, 0x22, 0xae, 0x01, 0x0a, 0x0e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45,
-	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64,
-	0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x69, 0x70,
-	0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x64, 0x6f,
-	0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01,
-	0x52, 0x0a, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12,
-	0x37, 0x0a, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x18,
-	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x50,
-	0x6f, 0x72, 0x74, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x0c, 0x70, 0x6f, 0x72, 0x74,
-	0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x69, 0x70, 0x41,
-	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69,
-	0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x2a, 0x45, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f,
-	0x72, 0x74, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x1e, 0x0a, 0x1a, 0x55, 0x4e,
-	0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x4f, 0x52, 0x54, 0x5f,
-	0x50, 0x52, 0x4f, 0x54, 0x4f, 0x43, 0x4f, 0x4c, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x55, 0x44,
-	0x50, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x02, 0x42, 0x2d, 0x5a, 0x2b,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69,
-	0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63,
-	0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x61, 0x6e, 0x67, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x69, 0x6d, 0x69, 0x63, 0x72, 0x79,
+	0x22, 0xfe, 0x01, 0x0a, 0x0e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x6e, 0x64, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e,
+	0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0a, 0x64, 0x6f,
+	0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x37, 0x0a, 0x0c, 0x70,
+	0x6f, 0x72, 0x74, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x50, 0x6f, 0x72, 0x74, 0x42,
+	0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x69, 0x6e, 0x64,
+	0x69, 0x6e, 0x67, 0x73, 0x12, 0x3d, 0x0a, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70,
+	0x63, 0x74, 0x6c, 0x2e, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x48, 0x02, 0x52, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65,
+	0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0xf3, 0x01, 0x0a, 0x0c, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x09, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x88, 0x01, 0x01, 0x12, 0x3a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c,
+	0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x48, 0x01, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x88, 0x01,
+	0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d, 0x69, 0x6e,
+	0x75, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x0f, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x88, 0x01, 0x01,
+	0x12, 0x1b, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x03, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a,
+	0x0a, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x42, 0x09, 0x0a, 0x07,
+	0x5f, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x2a, 0x45, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x70, 0x6f, 0x72, 0x74, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x1e, 0x0a, 0x1a,
+	0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x4f, 0x52,
+	0x54, 0x5f, 0x50, 0x52, 0x4f, 0x54, 0x4f, 0x43, 0x4f, 0x4c, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03,
+	0x55, 0x44, 0x50, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x02, 0x2a, 0x55,
+	0x0a, 0x07, 0x4d, 0x69, 0x6d, 0x69, 0x63, 0x72, 0x79, 0x12, 0x11, 0x0a, 0x0d, 0x4d, 0x49, 0x4d,
+	0x49, 0x43, 0x52, 0x59, 0x5f, 0x50, 0x4c, 0x41, 0x49, 0x4e, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b,
+	0x4d, 0x49, 0x4d, 0x49, 0x43, 0x52, 0x59, 0x5f, 0x54, 0x4c, 0x53, 0x10, 0x01, 0x12, 0x15, 0x0a,
+	0x11, 0x4d, 0x49, 0x4d, 0x49, 0x43, 0x52, 0x59, 0x5f, 0x57, 0x45, 0x42, 0x53, 0x4f, 0x43, 0x4b,
+	0x45, 0x54, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x49, 0x4d, 0x49, 0x43, 0x52, 0x59, 0x5f,
+	0x44, 0x4e, 0x53, 0x10, 0x03, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63,
+	0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -269,21 +455,26 @@ func file_endpoint_proto_rawDescGZIP() []byte {
 	return file_endpoint_proto_rawDescData
 }
 
-var file_endpoint_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_endpoint_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_endpoint_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_endpoint_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_endpoint_proto_goTypes = []interface{}{
 	(TransportProtocol)(0), // 0: appctl.TransportProtocol
-	(*PortBinding)(nil),    // 1: appctl.PortBinding
-	(*ServerEndpoint)(nil), // 2: appctl.ServerEndpoint
+	(Mimicry)(0),           // 1: appctl.Mimicry
+	(*PortBinding)(nil),    // 2: appctl.PortBinding
+	(*ServerEndpoint)(nil), // 3: appctl.ServerEndpoint
+	(*PortRotation)(nil),   // 4: appctl.PortRotation
 }
 var file_endpoint_proto_depIdxs = []int32{
 	0, // 0: appctl.PortBinding.protocol:type_name -> appctl.TransportProtocol
-	1, // 1: appctl.ServerEndpoint.portBindings:type_name -> appctl.PortBinding
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	1, // 1: appctl.PortBinding.mimicry:type_name -> appctl.Mimicry
+	2, // 2: appctl.ServerEndpoint.portBindings:type_name -> appctl.PortBinding
+	4, // 3: appctl.ServerEndpoint.portRotation:type_name -> appctl.PortRotation
+	0, // 4: appctl.PortRotation.protocol:type_name -> appctl.TransportProtocol
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_endpoint_proto_init() }
@@ -316,16 +507,29 @@ func file_endpoint_proto_init() {
 				return nil
 			}
 		}
+		file_endpoint_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PortRotation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_endpoint_proto_msgTypes[0].OneofWrappers = []interface{}{}
 	file_endpoint_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_endpoint_proto_msgTypes[2].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_endpoint_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   2,
+			NumEnums:      2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,195 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.22.3
+// source: flowcontrol.proto
+
+package appctlpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FlowControlSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of segments in flight that are sent but not
+	// acknowledged. The default value is 16384.
+	SendWindowSize *int32 `protobuf:"varint,1,opt,name=sendWindowSize,proto3,oneof" json:"sendWindowSize,omitempty"`
+	// Maximum number of out of order segments that can be buffered
+	// before delivered to the application. The default value is 16384.
+	ReceiveWindowSize *int32 `protobuf:"varint,2,opt,name=receiveWindowSize,proto3,oneof" json:"receiveWindowSize,omitempty"`
+	// If set, the receive window is fixed to receiveWindowSize.
+	// Otherwise, the receive window starts from 256 segments and grows
+	// to receiveWindowSize when the remote sends data fast enough.
+	DisableAutoTuning *bool `protobuf:"varint,3,opt,name=disableAutoTuning,proto3,oneof" json:"disableAutoTuning,omitempty"`
+}
+
+func (x *FlowControlSettings) Reset() {
+	*x = FlowControlSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flowcontrol_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlowControlSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowControlSettings) ProtoMessage() {}
+
+func (x *FlowControlSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_flowcontrol_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowControlSettings.ProtoReflect.Descriptor instead.
+func (*FlowControlSettings) Descriptor() ([]byte, []int) {
+	return file_flowcontrol_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FlowControlSettings) GetSendWindowSize() int32 {
+	if x != nil && x.SendWindowSize != nil {
+		return *x.SendWindowSize
+	}
+	return 0
+}
+
+func (x *FlowControlSettings) GetReceiveWindowSize() int32 {
+	if x != nil && x.ReceiveWindowSize != nil {
+		return *x.ReceiveWindowSize
+	}
+	return 0
+}
+
+func (x *FlowControlSettings) GetDisableAutoTuning() bool {
+	if x != nil && x.DisableAutoTuning != nil {
+		return *x.DisableAutoTuning
+	}
+	return false
+}
+
+var File_flowcontrol_proto protoreflect.FileDescriptor
+
+var file_flowcontrol_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x66, 0x6c, 0x6f, 0x77, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x22, 0xe7, 0x01, 0x0a, 0x13,
+	0x46, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x53, 0x65, 0x74, 0x74, 0x69,
+	0x6e, 0x67, 0x73, 0x12, 0x2b, 0x0a, 0x0e, 0x73, 0x65, 0x6e, 0x64, 0x57, 0x69, 0x6e, 0x64, 0x6f,
+	0x77, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x0e, 0x73,
+	0x65, 0x6e, 0x64, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x69, 0x7a, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x31, 0x0a, 0x11, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x57, 0x69, 0x6e, 0x64, 0x6f,
+	0x77, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x11, 0x72,
+	0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x69, 0x7a, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x11, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x41, 0x75,
+	0x74, 0x6f, 0x54, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x02,
+	0x52, 0x11, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x41, 0x75, 0x74, 0x6f, 0x54, 0x75, 0x6e,
+	0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x73, 0x65, 0x6e, 0x64, 0x57,
+	0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x72, 0x65,
+	0x63, 0x65, 0x69, 0x76, 0x65, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x69, 0x7a, 0x65, 0x42,
+	0x14, 0x0a, 0x12, 0x5f, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x41, 0x75, 0x74, 0x6f, 0x54,
+	0x75, 0x6e, 0x69, 0x6e, 0x67, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63,
+	0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_flowcontrol_proto_rawDescOnce sync.Once
+	file_flowcontrol_proto_rawDescData = file_flowcontrol_proto_rawDesc
+)
+
+func file_flowcontrol_proto_rawDescGZIP() []byte {
+	file_flowcontrol_proto_rawDescOnce.Do(func() {
+		file_flowcontrol_proto_rawDescData = protoimpl.X.CompressGZIP(file_flowcontrol_proto_rawDescData)
+	})
+	return file_flowcontrol_proto_rawDescData
+}
+
+var file_flowcontrol_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_flowcontrol_proto_goTypes = []interface{}{
+	(*FlowControlSettings)(nil), // 0: appctl.FlowControlSettings
+}
+var file_flowcontrol_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_flowcontrol_proto_init() }
+func file_flowcontrol_proto_init() {
+	if File_flowcontrol_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_flowcontrol_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlowControlSettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_flowcontrol_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_flowcontrol_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_flowcontrol_proto_goTypes,
+		DependencyIndexes: file_flowcontrol_proto_depIdxs,
+		MessageInfos:      file_flowcontrol_proto_msgTypes,
+	}.Build()
+	File_flowcontrol_proto = out.File
+	file_flowcontrol_proto_rawDesc = nil
+	file_flowcontrol_proto_goTypes = nil
+	file_flowcontrol_proto_depIdxs = nil
+}
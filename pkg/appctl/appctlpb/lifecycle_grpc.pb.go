@@ -34,14 +34,24 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ClientLifecycleService_GetStatus_FullMethodName       = "/appctl.ClientLifecycleService/GetStatus"
-	ClientLifecycleService_Exit_FullMethodName            = "/appctl.ClientLifecycleService/Exit"
-	ClientLifecycleService_GetMetrics_FullMethodName      = "/appctl.ClientLifecycleService/GetMetrics"
-	ClientLifecycleService_GetSessionInfo_FullMethodName  = "/appctl.ClientLifecycleService/GetSessionInfo"
-	ClientLifecycleService_GetThreadDump_FullMethodName   = "/appctl.ClientLifecycleService/GetThreadDump"
-	ClientLifecycleService_StartCPUProfile_FullMethodName = "/appctl.ClientLifecycleService/StartCPUProfile"
-	ClientLifecycleService_StopCPUProfile_FullMethodName  = "/appctl.ClientLifecycleService/StopCPUProfile"
-	ClientLifecycleService_GetHeapProfile_FullMethodName  = "/appctl.ClientLifecycleService/GetHeapProfile"
+	ClientLifecycleService_GetStatus_FullMethodName           = "/appctl.ClientLifecycleService/GetStatus"
+	ClientLifecycleService_Exit_FullMethodName                = "/appctl.ClientLifecycleService/Exit"
+	ClientLifecycleService_GetMetrics_FullMethodName          = "/appctl.ClientLifecycleService/GetMetrics"
+	ClientLifecycleService_GetMetricsHistory_FullMethodName   = "/appctl.ClientLifecycleService/GetMetricsHistory"
+	ClientLifecycleService_WatchMetrics_FullMethodName        = "/appctl.ClientLifecycleService/WatchMetrics"
+	ClientLifecycleService_GetTopDestinations_FullMethodName  = "/appctl.ClientLifecycleService/GetTopDestinations"
+	ClientLifecycleService_GetSessionInfo_FullMethodName      = "/appctl.ClientLifecycleService/GetSessionInfo"
+	ClientLifecycleService_WatchSessionInfo_FullMethodName    = "/appctl.ClientLifecycleService/WatchSessionInfo"
+	ClientLifecycleService_GetThreadDump_FullMethodName       = "/appctl.ClientLifecycleService/GetThreadDump"
+	ClientLifecycleService_StartCPUProfile_FullMethodName     = "/appctl.ClientLifecycleService/StartCPUProfile"
+	ClientLifecycleService_StopCPUProfile_FullMethodName      = "/appctl.ClientLifecycleService/StopCPUProfile"
+	ClientLifecycleService_GetHeapProfile_FullMethodName      = "/appctl.ClientLifecycleService/GetHeapProfile"
+	ClientLifecycleService_GetServerMessages_FullMethodName   = "/appctl.ClientLifecycleService/GetServerMessages"
+	ClientLifecycleService_SetSessionTap_FullMethodName       = "/appctl.ClientLifecycleService/SetSessionTap"
+	ClientLifecycleService_UpdateSubscriptions_FullMethodName = "/appctl.ClientLifecycleService/UpdateSubscriptions"
+	ClientLifecycleService_GetLogs_FullMethodName             = "/appctl.ClientLifecycleService/GetLogs"
+	ClientLifecycleService_SetLoggingLevel_FullMethodName     = "/appctl.ClientLifecycleService/SetLoggingLevel"
+	ClientLifecycleService_GetApiVersion_FullMethodName       = "/appctl.ClientLifecycleService/GetApiVersion"
 )
 
 // ClientLifecycleServiceClient is the client API for ClientLifecycleService service.
@@ -54,8 +64,16 @@ type ClientLifecycleServiceClient interface {
 	Exit(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	// Get client metrics.
 	GetMetrics(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Metrics, error)
+	// Get per-minute samples of client metrics.
+	GetMetricsHistory(ctx context.Context, in *GetMetricsHistoryRequest, opts ...grpc.CallOption) (*MetricsHistory, error)
+	// Stream the changes of client metrics until the caller cancels.
+	WatchMetrics(ctx context.Context, in *WatchMetricsRequest, opts ...grpc.CallOption) (ClientLifecycleService_WatchMetricsClient, error)
+	// Get the destinations that use the most traffic.
+	GetTopDestinations(ctx context.Context, in *GetTopDestinationsRequest, opts ...grpc.CallOption) (*TopDestinations, error)
 	// Get client session information.
 	GetSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionInfo, error)
+	// Stream client session information every second until the caller cancels.
+	WatchSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ClientLifecycleService_WatchSessionInfoClient, error)
 	// Generate a thread dump of client daemon.
 	GetThreadDump(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ThreadDump, error)
 	// Start CPU profiling.
@@ -64,6 +82,18 @@ type ClientLifecycleServiceClient interface {
 	StopCPUProfile(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	// Generate a heap profile.
 	GetHeapProfile(ctx context.Context, in *ProfileSavePath, opts ...grpc.CallOption) (*Empty, error)
+	// Get messages recently received from proxy servers.
+	GetServerMessages(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServerMessageList, error)
+	// Mirror byte counts and timing of a session to a local socket.
+	SetSessionTap(ctx context.Context, in *SessionTap, opts ...grpc.CallOption) (*Empty, error)
+	// Download the servers of all client profiles that have a subscription.
+	UpdateSubscriptions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UpdateSubscriptionsResult, error)
+	// Get recent log lines of mieru client.
+	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (ClientLifecycleService_GetLogsClient, error)
+	// Change the logging level of mieru client until it is restarted.
+	SetLoggingLevel(ctx context.Context, in *SetLoggingLevelRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Get the lifecycle API version of mieru client.
+	GetApiVersion(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ApiVersion, error)
 }
 
 type clientLifecycleServiceClient struct {
@@ -101,6 +131,56 @@ func (c *clientLifecycleServiceClient) GetMetrics(ctx context.Context, in *Empty
 	return out, nil
 }
 
+func (c *clientLifecycleServiceClient) GetMetricsHistory(ctx context.Context, in *GetMetricsHistoryRequest, opts ...grpc.CallOption) (*MetricsHistory, error) {
+	out := new(MetricsHistory)
+	err := c.cc.Invoke(ctx, ClientLifecycleService_GetMetricsHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clientLifecycleServiceClient) WatchMetrics(ctx context.Context, in *WatchMetricsRequest, opts ...grpc.CallOption) (ClientLifecycleService_WatchMetricsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ClientLifecycleService_ServiceDesc.Streams[0], ClientLifecycleService_WatchMetrics_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &clientLifecycleServiceWatchMetricsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ClientLifecycleService_WatchMetricsClient interface {
+	Recv() (*MetricsUpdate, error)
+	grpc.ClientStream
+}
+
+type clientLifecycleServiceWatchMetricsClient struct {
+	grpc.ClientStream
+}
+
+func (x *clientLifecycleServiceWatchMetricsClient) Recv() (*MetricsUpdate, error) {
+	m := new(MetricsUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *clientLifecycleServiceClient) GetTopDestinations(ctx context.Context, in *GetTopDestinationsRequest, opts ...grpc.CallOption) (*TopDestinations, error) {
+	out := new(TopDestinations)
+	err := c.cc.Invoke(ctx, ClientLifecycleService_GetTopDestinations_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *clientLifecycleServiceClient) GetSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionInfo, error) {
 	out := new(SessionInfo)
 	err := c.cc.Invoke(ctx, ClientLifecycleService_GetSessionInfo_FullMethodName, in, out, opts...)
@@ -110,6 +190,38 @@ func (c *clientLifecycleServiceClient) GetSessionInfo(ctx context.Context, in *E
 	return out, nil
 }
 
+func (c *clientLifecycleServiceClient) WatchSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ClientLifecycleService_WatchSessionInfoClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ClientLifecycleService_ServiceDesc.Streams[1], ClientLifecycleService_WatchSessionInfo_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &clientLifecycleServiceWatchSessionInfoClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ClientLifecycleService_WatchSessionInfoClient interface {
+	Recv() (*SessionInfo, error)
+	grpc.ClientStream
+}
+
+type clientLifecycleServiceWatchSessionInfoClient struct {
+	grpc.ClientStream
+}
+
+func (x *clientLifecycleServiceWatchSessionInfoClient) Recv() (*SessionInfo, error) {
+	m := new(SessionInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *clientLifecycleServiceClient) GetThreadDump(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ThreadDump, error) {
 	out := new(ThreadDump)
 	err := c.cc.Invoke(ctx, ClientLifecycleService_GetThreadDump_FullMethodName, in, out, opts...)
@@ -146,6 +258,83 @@ func (c *clientLifecycleServiceClient) GetHeapProfile(ctx context.Context, in *P
 	return out, nil
 }
 
+func (c *clientLifecycleServiceClient) GetServerMessages(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServerMessageList, error) {
+	out := new(ServerMessageList)
+	err := c.cc.Invoke(ctx, ClientLifecycleService_GetServerMessages_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clientLifecycleServiceClient) SetSessionTap(ctx context.Context, in *SessionTap, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ClientLifecycleService_SetSessionTap_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clientLifecycleServiceClient) UpdateSubscriptions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UpdateSubscriptionsResult, error) {
+	out := new(UpdateSubscriptionsResult)
+	err := c.cc.Invoke(ctx, ClientLifecycleService_UpdateSubscriptions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clientLifecycleServiceClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (ClientLifecycleService_GetLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ClientLifecycleService_ServiceDesc.Streams[2], ClientLifecycleService_GetLogs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &clientLifecycleServiceGetLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ClientLifecycleService_GetLogsClient interface {
+	Recv() (*LogLine, error)
+	grpc.ClientStream
+}
+
+type clientLifecycleServiceGetLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *clientLifecycleServiceGetLogsClient) Recv() (*LogLine, error) {
+	m := new(LogLine)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *clientLifecycleServiceClient) SetLoggingLevel(ctx context.Context, in *SetLoggingLevelRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ClientLifecycleService_SetLoggingLevel_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clientLifecycleServiceClient) GetApiVersion(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ApiVersion, error) {
+	out := new(ApiVersion)
+	err := c.cc.Invoke(ctx, ClientLifecycleService_GetApiVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ClientLifecycleServiceServer is the server API for ClientLifecycleService service.
 // All implementations must embed UnimplementedClientLifecycleServiceServer
 // for forward compatibility
@@ -156,8 +345,16 @@ type ClientLifecycleServiceServer interface {
 	Exit(context.Context, *Empty) (*Empty, error)
 	// Get client metrics.
 	GetMetrics(context.Context, *Empty) (*Metrics, error)
+	// Get per-minute samples of client metrics.
+	GetMetricsHistory(context.Context, *GetMetricsHistoryRequest) (*MetricsHistory, error)
+	// Stream the changes of client metrics until the caller cancels.
+	WatchMetrics(*WatchMetricsRequest, ClientLifecycleService_WatchMetricsServer) error
+	// Get the destinations that use the most traffic.
+	GetTopDestinations(context.Context, *GetTopDestinationsRequest) (*TopDestinations, error)
 	// Get client session information.
 	GetSessionInfo(context.Context, *Empty) (*SessionInfo, error)
+	// Stream client session information every second until the caller cancels.
+	WatchSessionInfo(*Empty, ClientLifecycleService_WatchSessionInfoServer) error
 	// Generate a thread dump of client daemon.
 	GetThreadDump(context.Context, *Empty) (*ThreadDump, error)
 	// Start CPU profiling.
@@ -166,6 +363,18 @@ type ClientLifecycleServiceServer interface {
 	StopCPUProfile(context.Context, *Empty) (*Empty, error)
 	// Generate a heap profile.
 	GetHeapProfile(context.Context, *ProfileSavePath) (*Empty, error)
+	// Get messages recently received from proxy servers.
+	GetServerMessages(context.Context, *Empty) (*ServerMessageList, error)
+	// Mirror byte counts and timing of a session to a local socket.
+	SetSessionTap(context.Context, *SessionTap) (*Empty, error)
+	// Download the servers of all client profiles that have a subscription.
+	UpdateSubscriptions(context.Context, *Empty) (*UpdateSubscriptionsResult, error)
+	// Get recent log lines of mieru client.
+	GetLogs(*GetLogsRequest, ClientLifecycleService_GetLogsServer) error
+	// Change the logging level of mieru client until it is restarted.
+	SetLoggingLevel(context.Context, *SetLoggingLevelRequest) (*Empty, error)
+	// Get the lifecycle API version of mieru client.
+	GetApiVersion(context.Context, *Empty) (*ApiVersion, error)
 	mustEmbedUnimplementedClientLifecycleServiceServer()
 }
 
@@ -182,9 +391,21 @@ func (UnimplementedClientLifecycleServiceServer) Exit(context.Context, *Empty) (
 func (UnimplementedClientLifecycleServiceServer) GetMetrics(context.Context, *Empty) (*Metrics, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMetrics not implemented")
 }
+func (UnimplementedClientLifecycleServiceServer) GetMetricsHistory(context.Context, *GetMetricsHistoryRequest) (*MetricsHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetricsHistory not implemented")
+}
+func (UnimplementedClientLifecycleServiceServer) WatchMetrics(*WatchMetricsRequest, ClientLifecycleService_WatchMetricsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMetrics not implemented")
+}
+func (UnimplementedClientLifecycleServiceServer) GetTopDestinations(context.Context, *GetTopDestinationsRequest) (*TopDestinations, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTopDestinations not implemented")
+}
 func (UnimplementedClientLifecycleServiceServer) GetSessionInfo(context.Context, *Empty) (*SessionInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSessionInfo not implemented")
 }
+func (UnimplementedClientLifecycleServiceServer) WatchSessionInfo(*Empty, ClientLifecycleService_WatchSessionInfoServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSessionInfo not implemented")
+}
 func (UnimplementedClientLifecycleServiceServer) GetThreadDump(context.Context, *Empty) (*ThreadDump, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetThreadDump not implemented")
 }
@@ -197,6 +418,24 @@ func (UnimplementedClientLifecycleServiceServer) StopCPUProfile(context.Context,
 func (UnimplementedClientLifecycleServiceServer) GetHeapProfile(context.Context, *ProfileSavePath) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHeapProfile not implemented")
 }
+func (UnimplementedClientLifecycleServiceServer) GetServerMessages(context.Context, *Empty) (*ServerMessageList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerMessages not implemented")
+}
+func (UnimplementedClientLifecycleServiceServer) SetSessionTap(context.Context, *SessionTap) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSessionTap not implemented")
+}
+func (UnimplementedClientLifecycleServiceServer) UpdateSubscriptions(context.Context, *Empty) (*UpdateSubscriptionsResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSubscriptions not implemented")
+}
+func (UnimplementedClientLifecycleServiceServer) GetLogs(*GetLogsRequest, ClientLifecycleService_GetLogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetLogs not implemented")
+}
+func (UnimplementedClientLifecycleServiceServer) SetLoggingLevel(context.Context, *SetLoggingLevelRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLoggingLevel not implemented")
+}
+func (UnimplementedClientLifecycleServiceServer) GetApiVersion(context.Context, *Empty) (*ApiVersion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApiVersion not implemented")
+}
 func (UnimplementedClientLifecycleServiceServer) mustEmbedUnimplementedClientLifecycleServiceServer() {
 }
 
@@ -265,6 +504,63 @@ func _ClientLifecycleService_GetMetrics_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ClientLifecycleService_GetMetricsHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetricsHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientLifecycleServiceServer).GetMetricsHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientLifecycleService_GetMetricsHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientLifecycleServiceServer).GetMetricsHistory(ctx, req.(*GetMetricsHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClientLifecycleService_WatchMetrics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMetricsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ClientLifecycleServiceServer).WatchMetrics(m, &clientLifecycleServiceWatchMetricsServer{stream})
+}
+
+type ClientLifecycleService_WatchMetricsServer interface {
+	Send(*MetricsUpdate) error
+	grpc.ServerStream
+}
+
+type clientLifecycleServiceWatchMetricsServer struct {
+	grpc.ServerStream
+}
+
+func (x *clientLifecycleServiceWatchMetricsServer) Send(m *MetricsUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ClientLifecycleService_GetTopDestinations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopDestinationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientLifecycleServiceServer).GetTopDestinations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientLifecycleService_GetTopDestinations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientLifecycleServiceServer).GetTopDestinations(ctx, req.(*GetTopDestinationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ClientLifecycleService_GetSessionInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -283,6 +579,27 @@ func _ClientLifecycleService_GetSessionInfo_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ClientLifecycleService_WatchSessionInfo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ClientLifecycleServiceServer).WatchSessionInfo(m, &clientLifecycleServiceWatchSessionInfoServer{stream})
+}
+
+type ClientLifecycleService_WatchSessionInfoServer interface {
+	Send(*SessionInfo) error
+	grpc.ServerStream
+}
+
+type clientLifecycleServiceWatchSessionInfoServer struct {
+	grpc.ServerStream
+}
+
+func (x *clientLifecycleServiceWatchSessionInfoServer) Send(m *SessionInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _ClientLifecycleService_GetThreadDump_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -355,6 +672,117 @@ func _ClientLifecycleService_GetHeapProfile_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ClientLifecycleService_GetServerMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientLifecycleServiceServer).GetServerMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientLifecycleService_GetServerMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientLifecycleServiceServer).GetServerMessages(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClientLifecycleService_SetSessionTap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionTap)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientLifecycleServiceServer).SetSessionTap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientLifecycleService_SetSessionTap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientLifecycleServiceServer).SetSessionTap(ctx, req.(*SessionTap))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClientLifecycleService_UpdateSubscriptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientLifecycleServiceServer).UpdateSubscriptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientLifecycleService_UpdateSubscriptions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientLifecycleServiceServer).UpdateSubscriptions(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClientLifecycleService_GetLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ClientLifecycleServiceServer).GetLogs(m, &clientLifecycleServiceGetLogsServer{stream})
+}
+
+type ClientLifecycleService_GetLogsServer interface {
+	Send(*LogLine) error
+	grpc.ServerStream
+}
+
+type clientLifecycleServiceGetLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *clientLifecycleServiceGetLogsServer) Send(m *LogLine) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ClientLifecycleService_SetLoggingLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLoggingLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientLifecycleServiceServer).SetLoggingLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientLifecycleService_SetLoggingLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientLifecycleServiceServer).SetLoggingLevel(ctx, req.(*SetLoggingLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClientLifecycleService_GetApiVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientLifecycleServiceServer).GetApiVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClientLifecycleService_GetApiVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientLifecycleServiceServer).GetApiVersion(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ClientLifecycleService_ServiceDesc is the grpc.ServiceDesc for ClientLifecycleService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -374,6 +802,14 @@ var ClientLifecycleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMetrics",
 			Handler:    _ClientLifecycleService_GetMetrics_Handler,
 		},
+		{
+			MethodName: "GetMetricsHistory",
+			Handler:    _ClientLifecycleService_GetMetricsHistory_Handler,
+		},
+		{
+			MethodName: "GetTopDestinations",
+			Handler:    _ClientLifecycleService_GetTopDestinations_Handler,
+		},
 		{
 			MethodName: "GetSessionInfo",
 			Handler:    _ClientLifecycleService_GetSessionInfo_Handler,
@@ -394,23 +830,73 @@ var ClientLifecycleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetHeapProfile",
 			Handler:    _ClientLifecycleService_GetHeapProfile_Handler,
 		},
+		{
+			MethodName: "GetServerMessages",
+			Handler:    _ClientLifecycleService_GetServerMessages_Handler,
+		},
+		{
+			MethodName: "SetSessionTap",
+			Handler:    _ClientLifecycleService_SetSessionTap_Handler,
+		},
+		{
+			MethodName: "UpdateSubscriptions",
+			Handler:    _ClientLifecycleService_UpdateSubscriptions_Handler,
+		},
+		{
+			MethodName: "SetLoggingLevel",
+			Handler:    _ClientLifecycleService_SetLoggingLevel_Handler,
+		},
+		{
+			MethodName: "GetApiVersion",
+			Handler:    _ClientLifecycleService_GetApiVersion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMetrics",
+			Handler:       _ClientLifecycleService_WatchMetrics_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchSessionInfo",
+			Handler:       _ClientLifecycleService_WatchSessionInfo_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetLogs",
+			Handler:       _ClientLifecycleService_GetLogs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "lifecycle.proto",
 }
 
 const (
-	ServerLifecycleService_GetStatus_FullMethodName       = "/appctl.ServerLifecycleService/GetStatus"
-	ServerLifecycleService_Start_FullMethodName           = "/appctl.ServerLifecycleService/Start"
-	ServerLifecycleService_Stop_FullMethodName            = "/appctl.ServerLifecycleService/Stop"
-	ServerLifecycleService_Reload_FullMethodName          = "/appctl.ServerLifecycleService/Reload"
-	ServerLifecycleService_Exit_FullMethodName            = "/appctl.ServerLifecycleService/Exit"
-	ServerLifecycleService_GetMetrics_FullMethodName      = "/appctl.ServerLifecycleService/GetMetrics"
-	ServerLifecycleService_GetSessionInfo_FullMethodName  = "/appctl.ServerLifecycleService/GetSessionInfo"
-	ServerLifecycleService_GetThreadDump_FullMethodName   = "/appctl.ServerLifecycleService/GetThreadDump"
-	ServerLifecycleService_StartCPUProfile_FullMethodName = "/appctl.ServerLifecycleService/StartCPUProfile"
-	ServerLifecycleService_StopCPUProfile_FullMethodName  = "/appctl.ServerLifecycleService/StopCPUProfile"
-	ServerLifecycleService_GetHeapProfile_FullMethodName  = "/appctl.ServerLifecycleService/GetHeapProfile"
+	ServerLifecycleService_GetStatus_FullMethodName         = "/appctl.ServerLifecycleService/GetStatus"
+	ServerLifecycleService_Start_FullMethodName             = "/appctl.ServerLifecycleService/Start"
+	ServerLifecycleService_Stop_FullMethodName              = "/appctl.ServerLifecycleService/Stop"
+	ServerLifecycleService_Reload_FullMethodName            = "/appctl.ServerLifecycleService/Reload"
+	ServerLifecycleService_Exit_FullMethodName              = "/appctl.ServerLifecycleService/Exit"
+	ServerLifecycleService_GetMetrics_FullMethodName        = "/appctl.ServerLifecycleService/GetMetrics"
+	ServerLifecycleService_GetMetricsHistory_FullMethodName = "/appctl.ServerLifecycleService/GetMetricsHistory"
+	ServerLifecycleService_WatchMetrics_FullMethodName      = "/appctl.ServerLifecycleService/WatchMetrics"
+	ServerLifecycleService_GetSessionInfo_FullMethodName    = "/appctl.ServerLifecycleService/GetSessionInfo"
+	ServerLifecycleService_WatchSessionInfo_FullMethodName  = "/appctl.ServerLifecycleService/WatchSessionInfo"
+	ServerLifecycleService_GetThreadDump_FullMethodName     = "/appctl.ServerLifecycleService/GetThreadDump"
+	ServerLifecycleService_StartCPUProfile_FullMethodName   = "/appctl.ServerLifecycleService/StartCPUProfile"
+	ServerLifecycleService_StopCPUProfile_FullMethodName    = "/appctl.ServerLifecycleService/StopCPUProfile"
+	ServerLifecycleService_GetHeapProfile_FullMethodName    = "/appctl.ServerLifecycleService/GetHeapProfile"
+	ServerLifecycleService_SendServerMessage_FullMethodName = "/appctl.ServerLifecycleService/SendServerMessage"
+	ServerLifecycleService_GetLogs_FullMethodName           = "/appctl.ServerLifecycleService/GetLogs"
+	ServerLifecycleService_SetLoggingLevel_FullMethodName   = "/appctl.ServerLifecycleService/SetLoggingLevel"
+	ServerLifecycleService_GetAuditLog_FullMethodName       = "/appctl.ServerLifecycleService/GetAuditLog"
+	ServerLifecycleService_GetBannedIPs_FullMethodName      = "/appctl.ServerLifecycleService/GetBannedIPs"
+	ServerLifecycleService_BanIP_FullMethodName             = "/appctl.ServerLifecycleService/BanIP"
+	ServerLifecycleService_UnbanIP_FullMethodName           = "/appctl.ServerLifecycleService/UnbanIP"
+	ServerLifecycleService_AddPortBinding_FullMethodName    = "/appctl.ServerLifecycleService/AddPortBinding"
+	ServerLifecycleService_DeletePortBinding_FullMethodName = "/appctl.ServerLifecycleService/DeletePortBinding"
+	ServerLifecycleService_GetUsers_FullMethodName          = "/appctl.ServerLifecycleService/GetUsers"
+	ServerLifecycleService_GetApiVersion_FullMethodName     = "/appctl.ServerLifecycleService/GetApiVersion"
 )
 
 // ServerLifecycleServiceClient is the client API for ServerLifecycleService service.
@@ -429,8 +915,14 @@ type ServerLifecycleServiceClient interface {
 	Exit(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	// Get server metrics.
 	GetMetrics(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Metrics, error)
+	// Get per-minute samples of server metrics.
+	GetMetricsHistory(ctx context.Context, in *GetMetricsHistoryRequest, opts ...grpc.CallOption) (*MetricsHistory, error)
+	// Stream the changes of server metrics until the caller cancels.
+	WatchMetrics(ctx context.Context, in *WatchMetricsRequest, opts ...grpc.CallOption) (ServerLifecycleService_WatchMetricsClient, error)
 	// Get server session information.
 	GetSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionInfo, error)
+	// Stream server session information every second until the caller cancels.
+	WatchSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ServerLifecycleService_WatchSessionInfoClient, error)
 	// Generate a thread dump of server daemon.
 	GetThreadDump(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ThreadDump, error)
 	// Start CPU profiling.
@@ -439,6 +931,32 @@ type ServerLifecycleServiceClient interface {
 	StopCPUProfile(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	// Generate a heap profile.
 	GetHeapProfile(ctx context.Context, in *ProfileSavePath, opts ...grpc.CallOption) (*Empty, error)
+	// Send a message to connected proxy clients.
+	SendServerMessage(ctx context.Context, in *ServerMessage, opts ...grpc.CallOption) (*SendServerMessageResult, error)
+	// Get recent log lines of mita server.
+	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (ServerLifecycleService_GetLogsClient, error)
+	// Change the logging level of mita server until it is restarted
+	// or the configuration is reloaded.
+	SetLoggingLevel(ctx context.Context, in *SetLoggingLevelRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Get the audit log of configuration and lifecycle changes.
+	GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*AuditLog, error)
+	// Get the source IP addresses that are banned.
+	GetBannedIPs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BannedIPList, error)
+	// Add a source IP range to the blocklist of server config.
+	BanIP(ctx context.Context, in *BanIPRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Remove the ban of a source IP address, or remove a source IP range
+	// from the blocklist of server config.
+	UnbanIP(ctx context.Context, in *UnbanIPRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Add a port binding to server config. If proxy is running,
+	// start to listen to the ports.
+	AddPortBinding(ctx context.Context, in *PortBinding, opts ...grpc.CallOption) (*Empty, error)
+	// Remove a port binding from server config. If proxy is running,
+	// stop listening to the ports.
+	DeletePortBinding(ctx context.Context, in *PortBinding, opts ...grpc.CallOption) (*Empty, error)
+	// Return the configured users with live usage.
+	GetUsers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UserUsageList, error)
+	// Get the lifecycle API version of mita server.
+	GetApiVersion(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ApiVersion, error)
 }
 
 type serverLifecycleServiceClient struct {
@@ -503,24 +1021,97 @@ func (c *serverLifecycleServiceClient) GetMetrics(ctx context.Context, in *Empty
 	return out, nil
 }
 
-func (c *serverLifecycleServiceClient) GetSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionInfo, error) {
-	out := new(SessionInfo)
-	err := c.cc.Invoke(ctx, ServerLifecycleService_GetSessionInfo_FullMethodName, in, out, opts...)
+func (c *serverLifecycleServiceClient) GetMetricsHistory(ctx context.Context, in *GetMetricsHistoryRequest, opts ...grpc.CallOption) (*MetricsHistory, error) {
+	out := new(MetricsHistory)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_GetMetricsHistory_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *serverLifecycleServiceClient) GetThreadDump(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ThreadDump, error) {
-	out := new(ThreadDump)
-	err := c.cc.Invoke(ctx, ServerLifecycleService_GetThreadDump_FullMethodName, in, out, opts...)
+func (c *serverLifecycleServiceClient) WatchMetrics(ctx context.Context, in *WatchMetricsRequest, opts ...grpc.CallOption) (ServerLifecycleService_WatchMetricsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServerLifecycleService_ServiceDesc.Streams[0], ServerLifecycleService_WatchMetrics_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
-}
-
+	x := &serverLifecycleServiceWatchMetricsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ServerLifecycleService_WatchMetricsClient interface {
+	Recv() (*MetricsUpdate, error)
+	grpc.ClientStream
+}
+
+type serverLifecycleServiceWatchMetricsClient struct {
+	grpc.ClientStream
+}
+
+func (x *serverLifecycleServiceWatchMetricsClient) Recv() (*MetricsUpdate, error) {
+	m := new(MetricsUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serverLifecycleServiceClient) GetSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionInfo, error) {
+	out := new(SessionInfo)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_GetSessionInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) WatchSessionInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ServerLifecycleService_WatchSessionInfoClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServerLifecycleService_ServiceDesc.Streams[1], ServerLifecycleService_WatchSessionInfo_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serverLifecycleServiceWatchSessionInfoClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ServerLifecycleService_WatchSessionInfoClient interface {
+	Recv() (*SessionInfo, error)
+	grpc.ClientStream
+}
+
+type serverLifecycleServiceWatchSessionInfoClient struct {
+	grpc.ClientStream
+}
+
+func (x *serverLifecycleServiceWatchSessionInfoClient) Recv() (*SessionInfo, error) {
+	m := new(SessionInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serverLifecycleServiceClient) GetThreadDump(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ThreadDump, error) {
+	out := new(ThreadDump)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_GetThreadDump_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serverLifecycleServiceClient) StartCPUProfile(ctx context.Context, in *ProfileSavePath, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, ServerLifecycleService_StartCPUProfile_FullMethodName, in, out, opts...)
@@ -548,6 +1139,128 @@ func (c *serverLifecycleServiceClient) GetHeapProfile(ctx context.Context, in *P
 	return out, nil
 }
 
+func (c *serverLifecycleServiceClient) SendServerMessage(ctx context.Context, in *ServerMessage, opts ...grpc.CallOption) (*SendServerMessageResult, error) {
+	out := new(SendServerMessageResult)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_SendServerMessage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (ServerLifecycleService_GetLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServerLifecycleService_ServiceDesc.Streams[2], ServerLifecycleService_GetLogs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serverLifecycleServiceGetLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ServerLifecycleService_GetLogsClient interface {
+	Recv() (*LogLine, error)
+	grpc.ClientStream
+}
+
+type serverLifecycleServiceGetLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *serverLifecycleServiceGetLogsClient) Recv() (*LogLine, error) {
+	m := new(LogLine)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serverLifecycleServiceClient) SetLoggingLevel(ctx context.Context, in *SetLoggingLevelRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_SetLoggingLevel_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*AuditLog, error) {
+	out := new(AuditLog)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_GetAuditLog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) GetBannedIPs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BannedIPList, error) {
+	out := new(BannedIPList)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_GetBannedIPs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) BanIP(ctx context.Context, in *BanIPRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_BanIP_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) UnbanIP(ctx context.Context, in *UnbanIPRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_UnbanIP_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) AddPortBinding(ctx context.Context, in *PortBinding, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_AddPortBinding_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) DeletePortBinding(ctx context.Context, in *PortBinding, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_DeletePortBinding_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) GetUsers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UserUsageList, error) {
+	out := new(UserUsageList)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_GetUsers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverLifecycleServiceClient) GetApiVersion(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ApiVersion, error) {
+	out := new(ApiVersion)
+	err := c.cc.Invoke(ctx, ServerLifecycleService_GetApiVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServerLifecycleServiceServer is the server API for ServerLifecycleService service.
 // All implementations must embed UnimplementedServerLifecycleServiceServer
 // for forward compatibility
@@ -564,8 +1277,14 @@ type ServerLifecycleServiceServer interface {
 	Exit(context.Context, *Empty) (*Empty, error)
 	// Get server metrics.
 	GetMetrics(context.Context, *Empty) (*Metrics, error)
+	// Get per-minute samples of server metrics.
+	GetMetricsHistory(context.Context, *GetMetricsHistoryRequest) (*MetricsHistory, error)
+	// Stream the changes of server metrics until the caller cancels.
+	WatchMetrics(*WatchMetricsRequest, ServerLifecycleService_WatchMetricsServer) error
 	// Get server session information.
 	GetSessionInfo(context.Context, *Empty) (*SessionInfo, error)
+	// Stream server session information every second until the caller cancels.
+	WatchSessionInfo(*Empty, ServerLifecycleService_WatchSessionInfoServer) error
 	// Generate a thread dump of server daemon.
 	GetThreadDump(context.Context, *Empty) (*ThreadDump, error)
 	// Start CPU profiling.
@@ -574,6 +1293,32 @@ type ServerLifecycleServiceServer interface {
 	StopCPUProfile(context.Context, *Empty) (*Empty, error)
 	// Generate a heap profile.
 	GetHeapProfile(context.Context, *ProfileSavePath) (*Empty, error)
+	// Send a message to connected proxy clients.
+	SendServerMessage(context.Context, *ServerMessage) (*SendServerMessageResult, error)
+	// Get recent log lines of mita server.
+	GetLogs(*GetLogsRequest, ServerLifecycleService_GetLogsServer) error
+	// Change the logging level of mita server until it is restarted
+	// or the configuration is reloaded.
+	SetLoggingLevel(context.Context, *SetLoggingLevelRequest) (*Empty, error)
+	// Get the audit log of configuration and lifecycle changes.
+	GetAuditLog(context.Context, *GetAuditLogRequest) (*AuditLog, error)
+	// Get the source IP addresses that are banned.
+	GetBannedIPs(context.Context, *Empty) (*BannedIPList, error)
+	// Add a source IP range to the blocklist of server config.
+	BanIP(context.Context, *BanIPRequest) (*Empty, error)
+	// Remove the ban of a source IP address, or remove a source IP range
+	// from the blocklist of server config.
+	UnbanIP(context.Context, *UnbanIPRequest) (*Empty, error)
+	// Add a port binding to server config. If proxy is running,
+	// start to listen to the ports.
+	AddPortBinding(context.Context, *PortBinding) (*Empty, error)
+	// Remove a port binding from server config. If proxy is running,
+	// stop listening to the ports.
+	DeletePortBinding(context.Context, *PortBinding) (*Empty, error)
+	// Return the configured users with live usage.
+	GetUsers(context.Context, *Empty) (*UserUsageList, error)
+	// Get the lifecycle API version of mita server.
+	GetApiVersion(context.Context, *Empty) (*ApiVersion, error)
 	mustEmbedUnimplementedServerLifecycleServiceServer()
 }
 
@@ -599,9 +1344,18 @@ func (UnimplementedServerLifecycleServiceServer) Exit(context.Context, *Empty) (
 func (UnimplementedServerLifecycleServiceServer) GetMetrics(context.Context, *Empty) (*Metrics, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMetrics not implemented")
 }
+func (UnimplementedServerLifecycleServiceServer) GetMetricsHistory(context.Context, *GetMetricsHistoryRequest) (*MetricsHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetricsHistory not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) WatchMetrics(*WatchMetricsRequest, ServerLifecycleService_WatchMetricsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMetrics not implemented")
+}
 func (UnimplementedServerLifecycleServiceServer) GetSessionInfo(context.Context, *Empty) (*SessionInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSessionInfo not implemented")
 }
+func (UnimplementedServerLifecycleServiceServer) WatchSessionInfo(*Empty, ServerLifecycleService_WatchSessionInfoServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSessionInfo not implemented")
+}
 func (UnimplementedServerLifecycleServiceServer) GetThreadDump(context.Context, *Empty) (*ThreadDump, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetThreadDump not implemented")
 }
@@ -614,6 +1368,39 @@ func (UnimplementedServerLifecycleServiceServer) StopCPUProfile(context.Context,
 func (UnimplementedServerLifecycleServiceServer) GetHeapProfile(context.Context, *ProfileSavePath) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHeapProfile not implemented")
 }
+func (UnimplementedServerLifecycleServiceServer) SendServerMessage(context.Context, *ServerMessage) (*SendServerMessageResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendServerMessage not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) GetLogs(*GetLogsRequest, ServerLifecycleService_GetLogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetLogs not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) SetLoggingLevel(context.Context, *SetLoggingLevelRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLoggingLevel not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) GetAuditLog(context.Context, *GetAuditLogRequest) (*AuditLog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) GetBannedIPs(context.Context, *Empty) (*BannedIPList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBannedIPs not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) BanIP(context.Context, *BanIPRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanIP not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) UnbanIP(context.Context, *UnbanIPRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnbanIP not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) AddPortBinding(context.Context, *PortBinding) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPortBinding not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) DeletePortBinding(context.Context, *PortBinding) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePortBinding not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) GetUsers(context.Context, *Empty) (*UserUsageList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsers not implemented")
+}
+func (UnimplementedServerLifecycleServiceServer) GetApiVersion(context.Context, *Empty) (*ApiVersion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApiVersion not implemented")
+}
 func (UnimplementedServerLifecycleServiceServer) mustEmbedUnimplementedServerLifecycleServiceServer() {
 }
 
@@ -736,6 +1523,45 @@ func _ServerLifecycleService_GetMetrics_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServerLifecycleService_GetMetricsHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetricsHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).GetMetricsHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_GetMetricsHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).GetMetricsHistory(ctx, req.(*GetMetricsHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_WatchMetrics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMetricsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServerLifecycleServiceServer).WatchMetrics(m, &serverLifecycleServiceWatchMetricsServer{stream})
+}
+
+type ServerLifecycleService_WatchMetricsServer interface {
+	Send(*MetricsUpdate) error
+	grpc.ServerStream
+}
+
+type serverLifecycleServiceWatchMetricsServer struct {
+	grpc.ServerStream
+}
+
+func (x *serverLifecycleServiceWatchMetricsServer) Send(m *MetricsUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _ServerLifecycleService_GetSessionInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -754,6 +1580,27 @@ func _ServerLifecycleService_GetSessionInfo_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServerLifecycleService_WatchSessionInfo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServerLifecycleServiceServer).WatchSessionInfo(m, &serverLifecycleServiceWatchSessionInfoServer{stream})
+}
+
+type ServerLifecycleService_WatchSessionInfoServer interface {
+	Send(*SessionInfo) error
+	grpc.ServerStream
+}
+
+type serverLifecycleServiceWatchSessionInfoServer struct {
+	grpc.ServerStream
+}
+
+func (x *serverLifecycleServiceWatchSessionInfoServer) Send(m *SessionInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _ServerLifecycleService_GetThreadDump_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -826,6 +1673,207 @@ func _ServerLifecycleService_GetHeapProfile_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServerLifecycleService_SendServerMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).SendServerMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_SendServerMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).SendServerMessage(ctx, req.(*ServerMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_GetLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServerLifecycleServiceServer).GetLogs(m, &serverLifecycleServiceGetLogsServer{stream})
+}
+
+type ServerLifecycleService_GetLogsServer interface {
+	Send(*LogLine) error
+	grpc.ServerStream
+}
+
+type serverLifecycleServiceGetLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *serverLifecycleServiceGetLogsServer) Send(m *LogLine) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ServerLifecycleService_SetLoggingLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLoggingLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).SetLoggingLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_SetLoggingLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).SetLoggingLevel(ctx, req.(*SetLoggingLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_GetAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).GetAuditLog(ctx, req.(*GetAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_GetBannedIPs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).GetBannedIPs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_GetBannedIPs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).GetBannedIPs(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_BanIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).BanIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_BanIP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).BanIP(ctx, req.(*BanIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_UnbanIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbanIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).UnbanIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_UnbanIP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).UnbanIP(ctx, req.(*UnbanIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_AddPortBinding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PortBinding)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).AddPortBinding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_AddPortBinding_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).AddPortBinding(ctx, req.(*PortBinding))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_DeletePortBinding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PortBinding)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).DeletePortBinding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_DeletePortBinding_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).DeletePortBinding(ctx, req.(*PortBinding))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_GetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).GetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_GetUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).GetUsers(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerLifecycleService_GetApiVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerLifecycleServiceServer).GetApiVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerLifecycleService_GetApiVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerLifecycleServiceServer).GetApiVersion(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ServerLifecycleService_ServiceDesc is the grpc.ServiceDesc for ServerLifecycleService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -857,6 +1905,10 @@ var ServerLifecycleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMetrics",
 			Handler:    _ServerLifecycleService_GetMetrics_Handler,
 		},
+		{
+			MethodName: "GetMetricsHistory",
+			Handler:    _ServerLifecycleService_GetMetricsHistory_Handler,
+		},
 		{
 			MethodName: "GetSessionInfo",
 			Handler:    _ServerLifecycleService_GetSessionInfo_Handler,
@@ -877,7 +1929,63 @@ var ServerLifecycleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetHeapProfile",
 			Handler:    _ServerLifecycleService_GetHeapProfile_Handler,
 		},
+		{
+			MethodName: "SendServerMessage",
+			Handler:    _ServerLifecycleService_SendServerMessage_Handler,
+		},
+		{
+			MethodName: "SetLoggingLevel",
+			Handler:    _ServerLifecycleService_SetLoggingLevel_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _ServerLifecycleService_GetAuditLog_Handler,
+		},
+		{
+			MethodName: "GetBannedIPs",
+			Handler:    _ServerLifecycleService_GetBannedIPs_Handler,
+		},
+		{
+			MethodName: "BanIP",
+			Handler:    _ServerLifecycleService_BanIP_Handler,
+		},
+		{
+			MethodName: "UnbanIP",
+			Handler:    _ServerLifecycleService_UnbanIP_Handler,
+		},
+		{
+			MethodName: "AddPortBinding",
+			Handler:    _ServerLifecycleService_AddPortBinding_Handler,
+		},
+		{
+			MethodName: "DeletePortBinding",
+			Handler:    _ServerLifecycleService_DeletePortBinding_Handler,
+		},
+		{
+			MethodName: "GetUsers",
+			Handler:    _ServerLifecycleService_GetUsers_Handler,
+		},
+		{
+			MethodName: "GetApiVersion",
+			Handler:    _ServerLifecycleService_GetApiVersion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMetrics",
+			Handler:       _ServerLifecycleService_WatchMetrics_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchSessionInfo",
+			Handler:       _ServerLifecycleService_WatchSessionInfo_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetLogs",
+			Handler:       _ServerLifecycleService_GetLogs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "lifecycle.proto",
 }
@@ -96,20 +96,399 @@ func (LoggingLevel) EnumDescriptor() ([]byte, []int) {
 	return file_logging_proto_rawDescGZIP(), []int{0}
 }
 
+type LogDropPolicy int32
+
+const (
+	// Drop new log entries when the queue is full.
+	LogDropPolicy_LOG_DROP_NEWEST LogDropPolicy = 0
+	// Drop the oldest log entries in the queue to make room for new ones.
+	LogDropPolicy_LOG_DROP_OLDEST LogDropPolicy = 1
+)
+
+// Enum value maps for LogDropPolicy.
+var (
+	LogDropPolicy_name = map[int32]string{
+		0: "LOG_DROP_NEWEST",
+		1: "LOG_DROP_OLDEST",
+	}
+	LogDropPolicy_value = map[string]int32{
+		"LOG_DROP_NEWEST": 0,
+		"LOG_DROP_OLDEST": 1,
+	}
+)
+
+func (x LogDropPolicy) Enum() *LogDropPolicy {
+	p := new(LogDropPolicy)
+	*p = x
+	return p
+}
+
+func (x LogDropPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogDropPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_logging_proto_enumTypes[1].Descriptor()
+}
+
+func (LogDropPolicy) Type() protoreflect.EnumType {
+	return &file_logging_proto_enumTypes[1]
+}
+
+func (x LogDropPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogDropPolicy.Descriptor instead.
+func (LogDropPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_logging_proto_rawDescGZIP(), []int{1}
+}
+
+type GetLogsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Keep sending new log lines until the caller cancels the request.
+	Follow *bool `protobuf:"varint,1,opt,name=follow,proto3,oneof" json:"follow,omitempty"`
+	// Only return log lines at this level or more severe.
+	// DEFAULT returns all the log lines.
+	Level *LoggingLevel `protobuf:"varint,2,opt,name=level,proto3,enum=appctl.LoggingLevel,oneof" json:"level,omitempty"`
+	// Maximum number of recent log lines to return.
+	// If not set, all the log lines kept in memory are returned.
+	Lines *int32 `protobuf:"varint,3,opt,name=lines,proto3,oneof" json:"lines,omitempty"`
+}
+
+func (x *GetLogsRequest) Reset() {
+	*x = GetLogsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_logging_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLogsRequest) ProtoMessage() {}
+
+func (x *GetLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_logging_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLogsRequest.ProtoReflect.Descriptor instead.
+func (*GetLogsRequest) Descriptor() ([]byte, []int) {
+	return file_logging_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetLogsRequest) GetFollow() bool {
+	if x != nil && x.Follow != nil {
+		return *x.Follow
+	}
+	return false
+}
+
+func (x *GetLogsRequest) GetLevel() LoggingLevel {
+	if x != nil && x.Level != nil {
+		return *x.Level
+	}
+	return LoggingLevel_DEFAULT
+}
+
+func (x *GetLogsRequest) GetLines() int32 {
+	if x != nil && x.Lines != nil {
+		return *x.Lines
+	}
+	return 0
+}
+
+type SetLoggingLevelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// New logging level of the running daemon.
+	// DEFAULT is the same as INFO.
+	Level *LoggingLevel `protobuf:"varint,1,opt,name=level,proto3,enum=appctl.LoggingLevel,oneof" json:"level,omitempty"`
+}
+
+func (x *SetLoggingLevelRequest) Reset() {
+	*x = SetLoggingLevelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_logging_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetLoggingLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLoggingLevelRequest) ProtoMessage() {}
+
+func (x *SetLoggingLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_logging_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLoggingLevelRequest.ProtoReflect.Descriptor instead.
+func (*SetLoggingLevelRequest) Descriptor() ([]byte, []int) {
+	return file_logging_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetLoggingLevelRequest) GetLevel() LoggingLevel {
+	if x != nil && x.Level != nil {
+		return *x.Level
+	}
+	return LoggingLevel_DEFAULT
+}
+
+type LogLine struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of milliseconds after UNIX epoch when the log is written.
+	TimeUnixMilli *int64        `protobuf:"varint,1,opt,name=timeUnixMilli,proto3,oneof" json:"timeUnixMilli,omitempty"`
+	Level         *LoggingLevel `protobuf:"varint,2,opt,name=level,proto3,enum=appctl.LoggingLevel,oneof" json:"level,omitempty"`
+	// Formatted log line.
+	Text *string `protobuf:"bytes,3,opt,name=text,proto3,oneof" json:"text,omitempty"`
+}
+
+func (x *LogLine) Reset() {
+	*x = LogLine{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_logging_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogLine) ProtoMessage() {}
+
+func (x *LogLine) ProtoReflect() protoreflect.Message {
+	mi := &file_logging_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return file_logging_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LogLine) GetTimeUnixMilli() int64 {
+	if x != nil && x.TimeUnixMilli != nil {
+		return *x.TimeUnixMilli
+	}
+	return 0
+}
+
+func (x *LogLine) GetLevel() LoggingLevel {
+	if x != nil && x.Level != nil {
+		return *x.Level
+	}
+	return LoggingLevel_DEFAULT
+}
+
+func (x *LogLine) GetText() string {
+	if x != nil && x.Text != nil {
+		return *x.Text
+	}
+	return ""
+}
+
+type LogShipping struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// TCP address of the log collector, e.g. "logs.example.com:5170".
+	// Log entries are sent over TLS, one JSON object per line.
+	Address *string `protobuf:"bytes,1,opt,name=address,proto3,oneof" json:"address,omitempty"`
+	// Server name to verify the certificate of the collector.
+	// If not set, the host of the address is used.
+	ServerName *string `protobuf:"bytes,2,opt,name=serverName,proto3,oneof" json:"serverName,omitempty"`
+	// Path of a PEM file with the CA certificates to verify the collector.
+	// If not set, the CA certificates of the operating system are used.
+	CaFile *string `protobuf:"bytes,3,opt,name=caFile,proto3,oneof" json:"caFile,omitempty"`
+	// Only ship log entries at this level or more severe.
+	// If not set, INFO is used.
+	Level *LoggingLevel `protobuf:"varint,4,opt,name=level,proto3,enum=appctl.LoggingLevel,oneof" json:"level,omitempty"`
+	// Maximum number of log entries waiting to be shipped.
+	// If not set, 4096 is used.
+	QueueSize *int32 `protobuf:"varint,5,opt,name=queueSize,proto3,oneof" json:"queueSize,omitempty"`
+	// What to do when the queue is full, for example if the collector
+	// is not reachable.
+	DropPolicy *LogDropPolicy `protobuf:"varint,6,opt,name=dropPolicy,proto3,enum=appctl.LogDropPolicy,oneof" json:"dropPolicy,omitempty"`
+}
+
+func (x *LogShipping) Reset() {
+	*x = LogShipping{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_logging_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogShipping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogShipping) ProtoMessage() {}
+
+func (x *LogShipping) ProtoReflect() protoreflect.Message {
+	mi := &file_logging_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogShipping.ProtoReflect.Descriptor instead.
+func (*LogShipping) Descriptor() ([]byte, []int) {
+	return file_logging_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LogShipping) GetAddress() string {
+	if x != nil && x.Address != nil {
+		return *x.Address
+	}
+	return ""
+}
+
+func (x *LogShipping) GetServerName() string {
+	if x != nil && x.ServerName != nil {
+		return *x.ServerName
+	}
+	return ""
+}
+
+func (x *LogShipping) GetCaFile() string {
+	if x != nil && x.CaFile != nil {
+		return *x.CaFile
+	}
+	return ""
+}
+
+func (x *LogShipping) GetLevel() LoggingLevel {
+	if x != nil && x.Level != nil {
+		return *x.Level
+	}
+	return LoggingLevel_DEFAULT
+}
+
+func (x *LogShipping) GetQueueSize() int32 {
+	if x != nil && x.QueueSize != nil {
+		return *x.QueueSize
+	}
+	return 0
+}
+
+func (x *LogShipping) GetDropPolicy() LogDropPolicy {
+	if x != nil && x.DropPolicy != nil {
+		return *x.DropPolicy
+	}
+	return LogDropPolicy_LOG_DROP_NEWEST
+}
+
 var File_logging_proto protoreflect.FileDescriptor
 
 var file_logging_proto_rawDesc = []byte{
 	0x0a, 0x0d, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2a, 0x5b, 0x0a, 0x0c, 0x4c, 0x6f, 0x67, 0x67, 0x69,
-	0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45, 0x46, 0x41, 0x55,
-	0x4c, 0x54, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x46, 0x41, 0x54, 0x41, 0x4c, 0x10, 0x01, 0x12,
-	0x09, 0x0a, 0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x57, 0x41,
-	0x52, 0x4e, 0x10, 0x03, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x46, 0x4f, 0x10, 0x04, 0x12, 0x09,
-	0x0a, 0x05, 0x44, 0x45, 0x42, 0x55, 0x47, 0x10, 0x05, 0x12, 0x09, 0x0a, 0x05, 0x54, 0x52, 0x41,
-	0x43, 0x45, 0x10, 0x06, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
-	0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f,
-	0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74,
-	0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x22, 0x98, 0x01, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4c,
+	0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x06, 0x66, 0x6f,
+	0x6c, 0x6c, 0x6f, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x06, 0x66, 0x6f,
+	0x6c, 0x6c, 0x6f, 0x77, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e,
+	0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x48, 0x01, 0x52, 0x05,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73,
+	0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x42, 0x08,
+	0x0a, 0x06, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x6c, 0x69, 0x6e,
+	0x65, 0x73, 0x22, 0x53, 0x0a, 0x16, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67,
+	0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x05,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x61, 0x70,
+	0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x48, 0x00, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x88, 0x01, 0x01, 0x42, 0x08, 0x0a,
+	0x06, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22, 0xa3, 0x01, 0x0a, 0x07, 0x4c, 0x6f, 0x67, 0x4c,
+	0x69, 0x6e, 0x65, 0x12, 0x29, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0d, 0x74, 0x69,
+	0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x88, 0x01, 0x01, 0x12, 0x2f,
+	0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x48, 0x01, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x88, 0x01, 0x01, 0x12,
+	0x17, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52,
+	0x04, 0x74, 0x65, 0x78, 0x74, 0x88, 0x01, 0x01, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x6c,
+	0x65, 0x76, 0x65, 0x6c, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x22, 0xcb, 0x02,
+	0x0a, 0x0b, 0x4c, 0x6f, 0x67, 0x53, 0x68, 0x69, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x1d, 0x0a,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x01, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x1b, 0x0a, 0x06, 0x63, 0x61, 0x46, 0x69, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x02, 0x52, 0x06, 0x63, 0x61, 0x46, 0x69, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x12, 0x2f,
+	0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x48, 0x03, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x88, 0x01, 0x01, 0x12,
+	0x21, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x04, 0x52, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x88,
+	0x01, 0x01, 0x12, 0x3a, 0x0a, 0x0a, 0x64, 0x72, 0x6f, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e,
+	0x4c, 0x6f, 0x67, 0x44, 0x72, 0x6f, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x48, 0x05, 0x52,
+	0x0a, 0x64, 0x72, 0x6f, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x88, 0x01, 0x01, 0x42, 0x0a,
+	0x0a, 0x08, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x63, 0x61,
+	0x46, 0x69, 0x6c, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x0c,
+	0x0a, 0x0a, 0x5f, 0x71, 0x75, 0x65, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x0d, 0x0a, 0x0b,
+	0x5f, 0x64, 0x72, 0x6f, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x2a, 0x5b, 0x0a, 0x0c, 0x4c,
+	0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x0b, 0x0a, 0x07, 0x44,
+	0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x46, 0x41, 0x54, 0x41,
+	0x4c, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x02, 0x12, 0x08,
+	0x0a, 0x04, 0x57, 0x41, 0x52, 0x4e, 0x10, 0x03, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x46, 0x4f,
+	0x10, 0x04, 0x12, 0x09, 0x0a, 0x05, 0x44, 0x45, 0x42, 0x55, 0x47, 0x10, 0x05, 0x12, 0x09, 0x0a,
+	0x05, 0x54, 0x52, 0x41, 0x43, 0x45, 0x10, 0x06, 0x2a, 0x39, 0x0a, 0x0d, 0x4c, 0x6f, 0x67, 0x44,
+	0x72, 0x6f, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x13, 0x0a, 0x0f, 0x4c, 0x4f, 0x47,
+	0x5f, 0x44, 0x52, 0x4f, 0x50, 0x5f, 0x4e, 0x45, 0x57, 0x45, 0x53, 0x54, 0x10, 0x00, 0x12, 0x13,
+	0x0a, 0x0f, 0x4c, 0x4f, 0x47, 0x5f, 0x44, 0x52, 0x4f, 0x50, 0x5f, 0x4f, 0x4c, 0x44, 0x45, 0x53,
+	0x54, 0x10, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70,
+	0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -124,16 +503,27 @@ func file_logging_proto_rawDescGZIP() []byte {
 	return file_logging_proto_rawDescData
 }
 
-var file_logging_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_logging_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_logging_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_logging_proto_goTypes = []interface{}{
-	(LoggingLevel)(0), // 0: appctl.LoggingLevel
+	(LoggingLevel)(0),              // 0: appctl.LoggingLevel
+	(LogDropPolicy)(0),             // 1: appctl.LogDropPolicy
+	(*GetLogsRequest)(nil),         // 2: appctl.GetLogsRequest
+	(*SetLoggingLevelRequest)(nil), // 3: appctl.SetLoggingLevelRequest
+	(*LogLine)(nil),                // 4: appctl.LogLine
+	(*LogShipping)(nil),            // 5: appctl.LogShipping
 }
 var file_logging_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: appctl.GetLogsRequest.level:type_name -> appctl.LoggingLevel
+	0, // 1: appctl.SetLoggingLevelRequest.level:type_name -> appctl.LoggingLevel
+	0, // 2: appctl.LogLine.level:type_name -> appctl.LoggingLevel
+	0, // 3: appctl.LogShipping.level:type_name -> appctl.LoggingLevel
+	1, // 4: appctl.LogShipping.dropPolicy:type_name -> appctl.LogDropPolicy
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_logging_proto_init() }
@@ -141,19 +531,74 @@ func file_logging_proto_init() {
 	if File_logging_proto != nil {
 		return
 	}
+	if !protoimpl.UnsafeEnabled {
+		file_logging_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLogsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_logging_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetLoggingLevelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_logging_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogLine); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_logging_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogShipping); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_logging_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_logging_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_logging_proto_msgTypes[2].OneofWrappers = []interface{}{}
+	file_logging_proto_msgTypes[3].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_logging_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   0,
+			NumEnums:      2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_logging_proto_goTypes,
 		DependencyIndexes: file_logging_proto_depIdxs,
 		EnumInfos:         file_logging_proto_enumTypes,
+		MessageInfos:      file_logging_proto_msgTypes,
 	}.Build()
 	File_logging_proto = out.File
 	file_logging_proto_rawDesc = nil
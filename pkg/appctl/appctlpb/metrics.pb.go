@@ -82,31 +82,1270 @@ func (x *Metrics) GetJson() string {
 	return ""
 }
 
+type GetMetricsHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Only return the samples taken within this number of seconds.
+	// If not set, all the samples are returned.
+	DurationSeconds *int64 `protobuf:"varint,1,opt,name=durationSeconds,proto3,oneof" json:"durationSeconds,omitempty"`
+}
+
+func (x *GetMetricsHistoryRequest) Reset() {
+	*x = GetMetricsHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetMetricsHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetricsHistoryRequest) ProtoMessage() {}
+
+func (x *GetMetricsHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetricsHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetMetricsHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetMetricsHistoryRequest) GetDurationSeconds() int64 {
+	if x != nil && x.DurationSeconds != nil {
+		return *x.DurationSeconds
+	}
+	return 0
+}
+
+type MetricsHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Samples from the oldest to the newest.
+	Samples []*MetricsSample `protobuf:"bytes,1,rep,name=samples,proto3" json:"samples,omitempty"`
+}
+
+func (x *MetricsHistory) Reset() {
+	*x = MetricsHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetricsHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsHistory) ProtoMessage() {}
+
+func (x *MetricsHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsHistory.ProtoReflect.Descriptor instead.
+func (*MetricsHistory) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MetricsHistory) GetSamples() []*MetricsSample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+type WatchMetricsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of seconds between two updates.
+	// If not set, an update is sent every 5 seconds.
+	IntervalSeconds *int32 `protobuf:"varint,1,opt,name=intervalSeconds,proto3,oneof" json:"intervalSeconds,omitempty"`
+}
+
+func (x *WatchMetricsRequest) Reset() {
+	*x = WatchMetricsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMetricsRequest) ProtoMessage() {}
+
+func (x *WatchMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMetricsRequest.ProtoReflect.Descriptor instead.
+func (*WatchMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WatchMetricsRequest) GetIntervalSeconds() int32 {
+	if x != nil && x.IntervalSeconds != nil {
+		return *x.IntervalSeconds
+	}
+	return 0
+}
+
+// MetricsUpdate contains the metrics changed since the previous update.
+// The first update of a stream contains all the metrics.
+type MetricsUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Time of the update in milliseconds since unix epoch.
+	TimeUnixMilli *int64         `protobuf:"varint,1,opt,name=timeUnixMilli,proto3,oneof" json:"timeUnixMilli,omitempty"`
+	Metrics       []*MetricDelta `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`
+}
+
+func (x *MetricsUpdate) Reset() {
+	*x = MetricsUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetricsUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsUpdate) ProtoMessage() {}
+
+func (x *MetricsUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsUpdate.ProtoReflect.Descriptor instead.
+func (*MetricsUpdate) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MetricsUpdate) GetTimeUnixMilli() int64 {
+	if x != nil && x.TimeUnixMilli != nil {
+		return *x.TimeUnixMilli
+	}
+	return 0
+}
+
+func (x *MetricsUpdate) GetMetrics() []*MetricDelta {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type MetricDelta struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of metric group, e.g. "traffic".
+	Group *string `protobuf:"bytes,1,opt,name=group,proto3,oneof" json:"group,omitempty"`
+	// Name of metric, e.g. "InBytes".
+	Name *string `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	// Current value of the metric.
+	Value *int64 `protobuf:"varint,3,opt,name=value,proto3,oneof" json:"value,omitempty"`
+	// Change of the value since the previous update.
+	// It is 0 in the first update.
+	Delta *int64 `protobuf:"varint,4,opt,name=delta,proto3,oneof" json:"delta,omitempty"`
+}
+
+func (x *MetricDelta) Reset() {
+	*x = MetricDelta{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetricDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricDelta) ProtoMessage() {}
+
+func (x *MetricDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricDelta.ProtoReflect.Descriptor instead.
+func (*MetricDelta) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MetricDelta) GetGroup() string {
+	if x != nil && x.Group != nil {
+		return *x.Group
+	}
+	return ""
+}
+
+func (x *MetricDelta) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *MetricDelta) GetValue() int64 {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return 0
+}
+
+func (x *MetricDelta) GetDelta() int64 {
+	if x != nil && x.Delta != nil {
+		return *x.Delta
+	}
+	return 0
+}
+
+type GetTopDestinationsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of destinations to return.
+	// If not set, all the destinations are returned.
+	Limit *int32 `protobuf:"varint,1,opt,name=limit,proto3,oneof" json:"limit,omitempty"`
+}
+
+func (x *GetTopDestinationsRequest) Reset() {
+	*x = GetTopDestinationsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTopDestinationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopDestinationsRequest) ProtoMessage() {}
+
+func (x *GetTopDestinationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopDestinationsRequest.ProtoReflect.Descriptor instead.
+func (*GetTopDestinationsRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetTopDestinationsRequest) GetLimit() int32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+type TopDestinations struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Destinations sorted by traffic, from the most to the least.
+	Destinations []*DestinationEntry `protobuf:"bytes,1,rep,name=destinations,proto3" json:"destinations,omitempty"`
+}
+
+func (x *TopDestinations) Reset() {
+	*x = TopDestinations{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopDestinations) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopDestinations) ProtoMessage() {}
+
+func (x *TopDestinations) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopDestinations.ProtoReflect.Descriptor instead.
+func (*TopDestinations) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TopDestinations) GetDestinations() []*DestinationEntry {
+	if x != nil {
+		return x.Destinations
+	}
+	return nil
+}
+
+type DestinationEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Domain name or IP address of the destination.
+	Destination *string `protobuf:"bytes,1,opt,name=destination,proto3,oneof" json:"destination,omitempty"`
+	// Number of proxy connections to the destination.
+	Connections *int64 `protobuf:"varint,2,opt,name=connections,proto3,oneof" json:"connections,omitempty"`
+	// Number of bytes sent to and received from the destination.
+	BytesSent     *int64 `protobuf:"varint,3,opt,name=bytesSent,proto3,oneof" json:"bytesSent,omitempty"`
+	BytesReceived *int64 `protobuf:"varint,4,opt,name=bytesReceived,proto3,oneof" json:"bytesReceived,omitempty"`
+	// Last time a connection to the destination is opened,
+	// in milliseconds since unix epoch.
+	LastSeenUnixMilli *int64 `protobuf:"varint,5,opt,name=lastSeenUnixMilli,proto3,oneof" json:"lastSeenUnixMilli,omitempty"`
+}
+
+func (x *DestinationEntry) Reset() {
+	*x = DestinationEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DestinationEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestinationEntry) ProtoMessage() {}
+
+func (x *DestinationEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestinationEntry.ProtoReflect.Descriptor instead.
+func (*DestinationEntry) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DestinationEntry) GetDestination() string {
+	if x != nil && x.Destination != nil {
+		return *x.Destination
+	}
+	return ""
+}
+
+func (x *DestinationEntry) GetConnections() int64 {
+	if x != nil && x.Connections != nil {
+		return *x.Connections
+	}
+	return 0
+}
+
+func (x *DestinationEntry) GetBytesSent() int64 {
+	if x != nil && x.BytesSent != nil {
+		return *x.BytesSent
+	}
+	return 0
+}
+
+func (x *DestinationEntry) GetBytesReceived() int64 {
+	if x != nil && x.BytesReceived != nil {
+		return *x.BytesReceived
+	}
+	return 0
+}
+
+func (x *DestinationEntry) GetLastSeenUnixMilli() int64 {
+	if x != nil && x.LastSeenUnixMilli != nil {
+		return *x.LastSeenUnixMilli
+	}
+	return 0
+}
+
+// MetricsSample is a summary of metrics over one minute.
+type MetricsSample struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// End of the sample interval in milliseconds since unix epoch.
+	TimeUnixMilli *int64 `protobuf:"varint,1,opt,name=timeUnixMilli,proto3,oneof" json:"timeUnixMilli,omitempty"`
+	// Number of bytes received and sent during the interval.
+	InBytes  *int64 `protobuf:"varint,2,opt,name=inBytes,proto3,oneof" json:"inBytes,omitempty"`
+	OutBytes *int64 `protobuf:"varint,3,opt,name=outBytes,proto3,oneof" json:"outBytes,omitempty"`
+	// Number of established connections at the end of the interval.
+	ActiveSessions *int64 `protobuf:"varint,4,opt,name=activeSessions,proto3,oneof" json:"activeSessions,omitempty"`
+	// Number of errors during the interval.
+	Errors *int64 `protobuf:"varint,5,opt,name=errors,proto3,oneof" json:"errors,omitempty"`
+}
+
+func (x *MetricsSample) Reset() {
+	*x = MetricsSample{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetricsSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsSample) ProtoMessage() {}
+
+func (x *MetricsSample) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsSample.ProtoReflect.Descriptor instead.
+func (*MetricsSample) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MetricsSample) GetTimeUnixMilli() int64 {
+	if x != nil && x.TimeUnixMilli != nil {
+		return *x.TimeUnixMilli
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetInBytes() int64 {
+	if x != nil && x.InBytes != nil {
+		return *x.InBytes
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetOutBytes() int64 {
+	if x != nil && x.OutBytes != nil {
+		return *x.OutBytes
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetActiveSessions() int64 {
+	if x != nil && x.ActiveSessions != nil {
+		return *x.ActiveSessions
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetErrors() int64 {
+	if x != nil && x.Errors != nil {
+		return *x.Errors
+	}
+	return 0
+}
+
+// MetricsDump is the persisted state of cumulative counters.
+type MetricsDump struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Time of the dump in milliseconds since unix epoch.
+	TimeUnixMilli *int64             `protobuf:"varint,1,opt,name=timeUnixMilli,proto3,oneof" json:"timeUnixMilli,omitempty"`
+	Groups        []*MetricGroupDump `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`
+}
+
+func (x *MetricsDump) Reset() {
+	*x = MetricsDump{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetricsDump) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsDump) ProtoMessage() {}
+
+func (x *MetricsDump) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsDump.ProtoReflect.Descriptor instead.
+func (*MetricsDump) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MetricsDump) GetTimeUnixMilli() int64 {
+	if x != nil && x.TimeUnixMilli != nil {
+		return *x.TimeUnixMilli
+	}
+	return 0
+}
+
+func (x *MetricsDump) GetGroups() []*MetricGroupDump {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type MetricGroupDump struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     *string        `protobuf:"bytes,1,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Counters []*CounterDump `protobuf:"bytes,2,rep,name=counters,proto3" json:"counters,omitempty"`
+}
+
+func (x *MetricGroupDump) Reset() {
+	*x = MetricGroupDump{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetricGroupDump) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricGroupDump) ProtoMessage() {}
+
+func (x *MetricGroupDump) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricGroupDump.ProtoReflect.Descriptor instead.
+func (*MetricGroupDump) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MetricGroupDump) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *MetricGroupDump) GetCounters() []*CounterDump {
+	if x != nil {
+		return x.Counters
+	}
+	return nil
+}
+
+type CounterDump struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name *string `protobuf:"bytes,1,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	// If true, the counter keeps a history of changes.
+	TimeSeries *bool            `protobuf:"varint,2,opt,name=timeSeries,proto3,oneof" json:"timeSeries,omitempty"`
+	Value      *int64           `protobuf:"varint,3,opt,name=value,proto3,oneof" json:"value,omitempty"`
+	History    []*CounterRecord `protobuf:"bytes,4,rep,name=history,proto3" json:"history,omitempty"`
+}
+
+func (x *CounterDump) Reset() {
+	*x = CounterDump{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CounterDump) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CounterDump) ProtoMessage() {}
+
+func (x *CounterDump) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CounterDump.ProtoReflect.Descriptor instead.
+func (*CounterDump) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CounterDump) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *CounterDump) GetTimeSeries() bool {
+	if x != nil && x.TimeSeries != nil {
+		return *x.TimeSeries
+	}
+	return false
+}
+
+func (x *CounterDump) GetValue() int64 {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return 0
+}
+
+func (x *CounterDump) GetHistory() []*CounterRecord {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+type CounterRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TimeUnixMilli *int64 `protobuf:"varint,1,opt,name=timeUnixMilli,proto3,oneof" json:"timeUnixMilli,omitempty"`
+	Delta         *int64 `protobuf:"varint,2,opt,name=delta,proto3,oneof" json:"delta,omitempty"`
+	// Precision of the record after roll up.
+	RollUp *int32 `protobuf:"varint,3,opt,name=rollUp,proto3,oneof" json:"rollUp,omitempty"`
+}
+
+func (x *CounterRecord) Reset() {
+	*x = CounterRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CounterRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CounterRecord) ProtoMessage() {}
+
+func (x *CounterRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CounterRecord.ProtoReflect.Descriptor instead.
+func (*CounterRecord) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CounterRecord) GetTimeUnixMilli() int64 {
+	if x != nil && x.TimeUnixMilli != nil {
+		return *x.TimeUnixMilli
+	}
+	return 0
+}
+
+func (x *CounterRecord) GetDelta() int64 {
+	if x != nil && x.Delta != nil {
+		return *x.Delta
+	}
+	return 0
+}
+
+func (x *CounterRecord) GetRollUp() int32 {
+	if x != nil && x.RollUp != nil {
+		return *x.RollUp
+	}
+	return 0
+}
+
+type StatsdExport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// UDP address of the StatsD or DogStatsD server, e.g. "127.0.0.1:8125".
+	Address *string `protobuf:"bytes,1,opt,name=address,proto3,oneof" json:"address,omitempty"`
+	// Number of seconds between two pushes.
+	// If not set, metrics are pushed every 10 seconds.
+	IntervalSeconds *int32 `protobuf:"varint,2,opt,name=intervalSeconds,proto3,oneof" json:"intervalSeconds,omitempty"`
+	// Prefix of metric names. If not set, "mieru" is used.
+	Prefix *string `protobuf:"bytes,3,opt,name=prefix,proto3,oneof" json:"prefix,omitempty"`
+	// Tags added to every metric in DogStatsD format, e.g. "env:prod".
+	// Leave it empty if the server doesn't support DogStatsD extensions.
+	Tags []string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+}
+
+func (x *StatsdExport) Reset() {
+	*x = StatsdExport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatsdExport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsdExport) ProtoMessage() {}
+
+func (x *StatsdExport) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsdExport.ProtoReflect.Descriptor instead.
+func (*StatsdExport) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StatsdExport) GetAddress() string {
+	if x != nil && x.Address != nil {
+		return *x.Address
+	}
+	return ""
+}
+
+func (x *StatsdExport) GetIntervalSeconds() int32 {
+	if x != nil && x.IntervalSeconds != nil {
+		return *x.IntervalSeconds
+	}
+	return 0
+}
+
+func (x *StatsdExport) GetPrefix() string {
+	if x != nil && x.Prefix != nil {
+		return *x.Prefix
+	}
+	return ""
+}
+
+func (x *StatsdExport) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type TracingExport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// URL of the OpenTelemetry OTLP HTTP receiver,
+	// e.g. "http://127.0.0.1:4318". Spans are sent in JSON encoding.
+	// If the path is not set, "/v1/traces" is used.
+	OtlpEndpoint *string `protobuf:"bytes,1,opt,name=otlpEndpoint,proto3,oneof" json:"otlpEndpoint,omitempty"`
+	// Fraction of sessions to trace, in the range of (0, 1].
+	// If not set, all the sessions are traced.
+	SampleRatio *float64 `protobuf:"fixed64,2,opt,name=sampleRatio,proto3,oneof" json:"sampleRatio,omitempty"`
+}
+
+func (x *TracingExport) Reset() {
+	*x = TracingExport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TracingExport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TracingExport) ProtoMessage() {}
+
+func (x *TracingExport) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TracingExport.ProtoReflect.Descriptor instead.
+func (*TracingExport) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TracingExport) GetOtlpEndpoint() string {
+	if x != nil && x.OtlpEndpoint != nil {
+		return *x.OtlpEndpoint
+	}
+	return ""
+}
+
+func (x *TracingExport) GetSampleRatio() float64 {
+	if x != nil && x.SampleRatio != nil {
+		return *x.SampleRatio
+	}
+	return 0
+}
+
 type SessionInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Table []string `protobuf:"bytes,1,rep,name=table,proto3" json:"table,omitempty"`
+	// Human readable table of sessions. The first line is the header.
+	Table []string `protobuf:"bytes,1,rep,name=table,proto3" json:"table,omitempty"`
+	// Sessions in machine readable format.
+	Sessions []*SessionEntry `protobuf:"bytes,2,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	// Underlay connections that carry the sessions.
+	Underlays []*UnderlayEntry `protobuf:"bytes,3,rep,name=underlays,proto3" json:"underlays,omitempty"`
+}
+
+func (x *SessionInfo) Reset() {
+	*x = SessionInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionInfo) ProtoMessage() {}
+
+func (x *SessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionInfo.ProtoReflect.Descriptor instead.
+func (*SessionInfo) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SessionInfo) GetTable() []string {
+	if x != nil {
+		return x.Table
+	}
+	return nil
+}
+
+func (x *SessionInfo) GetSessions() []*SessionEntry {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+func (x *SessionInfo) GetUnderlays() []*UnderlayEntry {
+	if x != nil {
+		return x.Underlays
+	}
+	return nil
+}
+
+type SessionEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id *uint32 `protobuf:"varint,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	// Transport protocol, "TCP" or "UDP".
+	Protocol   *string `protobuf:"bytes,2,opt,name=protocol,proto3,oneof" json:"protocol,omitempty"`
+	LocalAddr  *string `protobuf:"bytes,3,opt,name=localAddr,proto3,oneof" json:"localAddr,omitempty"`
+	RemoteAddr *string `protobuf:"bytes,4,opt,name=remoteAddr,proto3,oneof" json:"remoteAddr,omitempty"`
+	// Session state, e.g. "ESTABLISHED".
+	State *string `protobuf:"bytes,5,opt,name=state,proto3,oneof" json:"state,omitempty"`
+	// Number of segments in the receive queue and receive buffer.
+	RecvQueue *int32 `protobuf:"varint,6,opt,name=recvQueue,proto3,oneof" json:"recvQueue,omitempty"`
+	RecvBuf   *int32 `protobuf:"varint,7,opt,name=recvBuf,proto3,oneof" json:"recvBuf,omitempty"`
+	// Number of segments in the send queue and send buffer.
+	SendQueue *int32 `protobuf:"varint,8,opt,name=sendQueue,proto3,oneof" json:"sendQueue,omitempty"`
+	SendBuf   *int32 `protobuf:"varint,9,opt,name=sendBuf,proto3,oneof" json:"sendBuf,omitempty"`
+	// Milliseconds since the last segment was received.
+	LastRecvMillis *int64 `protobuf:"varint,10,opt,name=lastRecvMillis,proto3,oneof" json:"lastRecvMillis,omitempty"`
+	// Milliseconds since the last segment was sent.
+	LastSendMillis *int64 `protobuf:"varint,11,opt,name=lastSendMillis,proto3,oneof" json:"lastSendMillis,omitempty"`
+	// Number of bytes delivered to the application.
+	BytesRead *int64 `protobuf:"varint,12,opt,name=bytesRead,proto3,oneof" json:"bytesRead,omitempty"`
+	// Number of bytes sent from the application.
+	BytesWritten *int64 `protobuf:"varint,13,opt,name=bytesWritten,proto3,oneof" json:"bytesWritten,omitempty"`
+	// Smoothed round trip time in milliseconds.
+	// It is not set if no RTT sample is collected.
+	RttMillis *int64 `protobuf:"varint,14,opt,name=rttMillis,proto3,oneof" json:"rttMillis,omitempty"`
+	// Milliseconds since the session is created.
+	AgeMillis *int64 `protobuf:"varint,15,opt,name=ageMillis,proto3,oneof" json:"ageMillis,omitempty"`
+	// Number of UDP segments sent for the first time.
+	SegmentsSent *int64 `protobuf:"varint,16,opt,name=segmentsSent,proto3,oneof" json:"segmentsSent,omitempty"`
+	// Number of UDP segments sent again because they were not acknowledged.
+	Retransmits *int64 `protobuf:"varint,17,opt,name=retransmits,proto3,oneof" json:"retransmits,omitempty"`
+	// Number of segments that can be sent without acknowledgement.
+	CongestionWindow *int32 `protobuf:"varint,18,opt,name=congestionWindow,proto3,oneof" json:"congestionWindow,omitempty"`
+	// Receive window advertised by the peer, in segments.
+	PeerWindow *int32 `protobuf:"varint,19,opt,name=peerWindow,proto3,oneof" json:"peerWindow,omitempty"`
+	// Receive window of this session, in segments.
+	RecvWindow *int32 `protobuf:"varint,20,opt,name=recvWindow,proto3,oneof" json:"recvWindow,omitempty"`
+	// Protocol version negotiated with the peer.
+	// It is 0 if the peer doesn't negotiate the protocol version.
+	ProtocolVersion *int32 `protobuf:"varint,21,opt,name=protocolVersion,proto3,oneof" json:"protocolVersion,omitempty"`
+}
+
+func (x *SessionEntry) Reset() {
+	*x = SessionEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionEntry) ProtoMessage() {}
+
+func (x *SessionEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionEntry.ProtoReflect.Descriptor instead.
+func (*SessionEntry) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SessionEntry) GetId() uint32 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetProtocol() string {
+	if x != nil && x.Protocol != nil {
+		return *x.Protocol
+	}
+	return ""
+}
+
+func (x *SessionEntry) GetLocalAddr() string {
+	if x != nil && x.LocalAddr != nil {
+		return *x.LocalAddr
+	}
+	return ""
+}
+
+func (x *SessionEntry) GetRemoteAddr() string {
+	if x != nil && x.RemoteAddr != nil {
+		return *x.RemoteAddr
+	}
+	return ""
+}
+
+func (x *SessionEntry) GetState() string {
+	if x != nil && x.State != nil {
+		return *x.State
+	}
+	return ""
+}
+
+func (x *SessionEntry) GetRecvQueue() int32 {
+	if x != nil && x.RecvQueue != nil {
+		return *x.RecvQueue
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetRecvBuf() int32 {
+	if x != nil && x.RecvBuf != nil {
+		return *x.RecvBuf
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetSendQueue() int32 {
+	if x != nil && x.SendQueue != nil {
+		return *x.SendQueue
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetSendBuf() int32 {
+	if x != nil && x.SendBuf != nil {
+		return *x.SendBuf
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetLastRecvMillis() int64 {
+	if x != nil && x.LastRecvMillis != nil {
+		return *x.LastRecvMillis
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetLastSendMillis() int64 {
+	if x != nil && x.LastSendMillis != nil {
+		return *x.LastSendMillis
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetBytesRead() int64 {
+	if x != nil && x.BytesRead != nil {
+		return *x.BytesRead
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetBytesWritten() int64 {
+	if x != nil && x.BytesWritten != nil {
+		return *x.BytesWritten
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetRttMillis() int64 {
+	if x != nil && x.RttMillis != nil {
+		return *x.RttMillis
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetAgeMillis() int64 {
+	if x != nil && x.AgeMillis != nil {
+		return *x.AgeMillis
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetSegmentsSent() int64 {
+	if x != nil && x.SegmentsSent != nil {
+		return *x.SegmentsSent
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetRetransmits() int64 {
+	if x != nil && x.Retransmits != nil {
+		return *x.Retransmits
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetCongestionWindow() int32 {
+	if x != nil && x.CongestionWindow != nil {
+		return *x.CongestionWindow
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetPeerWindow() int32 {
+	if x != nil && x.PeerWindow != nil {
+		return *x.PeerWindow
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetRecvWindow() int32 {
+	if x != nil && x.RecvWindow != nil {
+		return *x.RecvWindow
+	}
+	return 0
+}
+
+func (x *SessionEntry) GetProtocolVersion() int32 {
+	if x != nil && x.ProtocolVersion != nil {
+		return *x.ProtocolVersion
+	}
+	return 0
+}
+
+type UnderlayEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Transport protocol, "TCP" or "UDP".
+	Protocol   *string `protobuf:"bytes,1,opt,name=protocol,proto3,oneof" json:"protocol,omitempty"`
+	LocalAddr  *string `protobuf:"bytes,2,opt,name=localAddr,proto3,oneof" json:"localAddr,omitempty"`
+	RemoteAddr *string `protobuf:"bytes,3,opt,name=remoteAddr,proto3,oneof" json:"remoteAddr,omitempty"`
+	// Number of sessions attached to the underlay.
+	SessionCount *int32 `protobuf:"varint,4,opt,name=sessionCount,proto3,oneof" json:"sessionCount,omitempty"`
+	// Milliseconds since anything was received from the server.
+	// It is only set for client TCP underlays.
+	LastRecvMillis *int64 `protobuf:"varint,5,opt,name=lastRecvMillis,proto3,oneof" json:"lastRecvMillis,omitempty"`
+	// Whether a keepalive request is waiting for the response.
+	// It is only set for client TCP underlays.
+	KeepalivePending *bool `protobuf:"varint,6,opt,name=keepalivePending,proto3,oneof" json:"keepalivePending,omitempty"`
+	// Number of bytes written to and read from the network,
+	// including padding.
+	BytesSent     *int64 `protobuf:"varint,7,opt,name=bytesSent,proto3,oneof" json:"bytesSent,omitempty"`
+	BytesReceived *int64 `protobuf:"varint,8,opt,name=bytesReceived,proto3,oneof" json:"bytesReceived,omitempty"`
+	// Number of segments written to and read from the network.
+	SegmentsSent     *int64 `protobuf:"varint,9,opt,name=segmentsSent,proto3,oneof" json:"segmentsSent,omitempty"`
+	SegmentsReceived *int64 `protobuf:"varint,10,opt,name=segmentsReceived,proto3,oneof" json:"segmentsReceived,omitempty"`
 }
 
-func (x *SessionInfo) Reset() {
-	*x = SessionInfo{}
+func (x *UnderlayEntry) Reset() {
+	*x = UnderlayEntry{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_metrics_proto_msgTypes[1]
+		mi := &file_metrics_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SessionInfo) String() string {
+func (x *UnderlayEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SessionInfo) ProtoMessage() {}
+func (*UnderlayEntry) ProtoMessage() {}
 
-func (x *SessionInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_metrics_proto_msgTypes[1]
+func (x *UnderlayEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -117,16 +1356,79 @@ func (x *SessionInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SessionInfo.ProtoReflect.Descriptor instead.
-func (*SessionInfo) Descriptor() ([]byte, []int) {
-	return file_metrics_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use UnderlayEntry.ProtoReflect.Descriptor instead.
+func (*UnderlayEntry) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *SessionInfo) GetTable() []string {
-	if x != nil {
-		return x.Table
+func (x *UnderlayEntry) GetProtocol() string {
+	if x != nil && x.Protocol != nil {
+		return *x.Protocol
 	}
-	return nil
+	return ""
+}
+
+func (x *UnderlayEntry) GetLocalAddr() string {
+	if x != nil && x.LocalAddr != nil {
+		return *x.LocalAddr
+	}
+	return ""
+}
+
+func (x *UnderlayEntry) GetRemoteAddr() string {
+	if x != nil && x.RemoteAddr != nil {
+		return *x.RemoteAddr
+	}
+	return ""
+}
+
+func (x *UnderlayEntry) GetSessionCount() int32 {
+	if x != nil && x.SessionCount != nil {
+		return *x.SessionCount
+	}
+	return 0
+}
+
+func (x *UnderlayEntry) GetLastRecvMillis() int64 {
+	if x != nil && x.LastRecvMillis != nil {
+		return *x.LastRecvMillis
+	}
+	return 0
+}
+
+func (x *UnderlayEntry) GetKeepalivePending() bool {
+	if x != nil && x.KeepalivePending != nil {
+		return *x.KeepalivePending
+	}
+	return false
+}
+
+func (x *UnderlayEntry) GetBytesSent() int64 {
+	if x != nil && x.BytesSent != nil {
+		return *x.BytesSent
+	}
+	return 0
+}
+
+func (x *UnderlayEntry) GetBytesReceived() int64 {
+	if x != nil && x.BytesReceived != nil {
+		return *x.BytesReceived
+	}
+	return 0
+}
+
+func (x *UnderlayEntry) GetSegmentsSent() int64 {
+	if x != nil && x.SegmentsSent != nil {
+		return *x.SegmentsSent
+	}
+	return 0
+}
+
+func (x *UnderlayEntry) GetSegmentsReceived() int64 {
+	if x != nil && x.SegmentsReceived != nil {
+		return *x.SegmentsReceived
+	}
+	return 0
 }
 
 var File_metrics_proto protoreflect.FileDescriptor
@@ -136,12 +1438,260 @@ var file_metrics_proto_rawDesc = []byte{
 	0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x22, 0x2b, 0x0a, 0x07, 0x4d, 0x65, 0x74, 0x72, 0x69,
 	0x63, 0x73, 0x12, 0x17, 0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
 	0x48, 0x00, 0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f,
-	0x6a, 0x73, 0x6f, 0x6e, 0x22, 0x23, 0x0a, 0x0b, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49,
-	0x6e, 0x66, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d,
-	0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f,
-	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6a, 0x73, 0x6f, 0x6e, 0x22, 0x5d, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x73, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x2d, 0x0a, 0x0f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0f, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x42,
+	0x12, 0x0a, 0x10, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x22, 0x41, 0x0a, 0x0e, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x48, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x2f, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e,
+	0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52, 0x07, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x22, 0x58, 0x0a, 0x13, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a,
+	0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76,
+	0x61, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x42, 0x12, 0x0a, 0x10,
+	0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73,
+	0x22, 0x7b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x12, 0x29, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0d, 0x74, 0x69, 0x6d, 0x65,
+	0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x07,
+	0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x44, 0x65, 0x6c,
+	0x74, 0x61, 0x52, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x42, 0x10, 0x0a, 0x0e, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x22, 0x9e, 0x01,
+	0x0a, 0x0b, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x12, 0x19, 0x0a,
+	0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x05,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x19, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x48, 0x02, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05,
+	0x64, 0x65, 0x6c, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x48, 0x03, 0x52, 0x05, 0x64,
+	0x65, 0x6c, 0x74, 0x61, 0x88, 0x01, 0x01, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x22, 0x40,
+	0x0a, 0x19, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x70, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x88, 0x01, 0x01, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x22, 0x4f, 0x0a, 0x0f, 0x54, 0x6f, 0x70, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x3c, 0x0a, 0x0c, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x70, 0x63,
+	0x74, 0x6c, 0x2e, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x0c, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x22, 0xb7, 0x02, 0x0a, 0x10, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x25, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x64,
+	0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a,
+	0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x48, 0x01, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x62, 0x79, 0x74, 0x65, 0x73, 0x53, 0x65, 0x6e,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x48, 0x02, 0x52, 0x09, 0x62, 0x79, 0x74, 0x65, 0x73,
+	0x53, 0x65, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x62, 0x79, 0x74, 0x65, 0x73,
+	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x48, 0x03,
+	0x52, 0x0d, 0x62, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x88,
+	0x01, 0x01, 0x12, 0x31, 0x0a, 0x11, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x55, 0x6e,
+	0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x48, 0x04, 0x52,
+	0x11, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x53,
+	0x65, 0x6e, 0x74, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x64, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65,
+	0x65, 0x6e, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x22, 0x8d, 0x02, 0x0a, 0x0d,
+	0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x29, 0x0a,
+	0x0d, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78,
+	0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x69, 0x6e, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x01, 0x52, 0x07, 0x69, 0x6e, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x6f, 0x75, 0x74, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x48, 0x02, 0x52, 0x08, 0x6f, 0x75, 0x74,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0e, 0x61, 0x63, 0x74, 0x69,
+	0x76, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x48, 0x03, 0x52, 0x0e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x48, 0x04, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x88,
+	0x01, 0x01, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x69, 0x6e, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x6f, 0x75, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x42, 0x11, 0x0a,
+	0x0f, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x42, 0x09, 0x0a, 0x07, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x7b, 0x0a, 0x0b, 0x4d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x44, 0x75, 0x6d, 0x70, 0x12, 0x29, 0x0a, 0x0d, 0x74, 0x69,
+	0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x48, 0x00, 0x52, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x75, 0x6d, 0x70, 0x52, 0x06,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x55,
+	0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x22, 0x64, 0x0a, 0x0f, 0x4d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x75, 0x6d, 0x70, 0x12, 0x17, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x08, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x44, 0x75, 0x6d, 0x70, 0x52, 0x08, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x65, 0x72, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xb9,
+	0x01, 0x0a, 0x0b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x44, 0x75, 0x6d, 0x70, 0x12, 0x17,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x53,
+	0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x48, 0x01, 0x52, 0x0a, 0x74,
+	0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x48, 0x02, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x79, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74,
+	0x6c, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52,
+	0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73,
+	0x42, 0x08, 0x0a, 0x06, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x99, 0x01, 0x0a, 0x0d, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x29, 0x0a, 0x0d,
+	0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x64, 0x65, 0x6c, 0x74, 0x61,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x01, 0x52, 0x05, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x88,
+	0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x72, 0x6f, 0x6c, 0x6c, 0x55, 0x70, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x02, 0x52, 0x06, 0x72, 0x6f, 0x6c, 0x6c, 0x55, 0x70, 0x88, 0x01, 0x01, 0x42,
+	0x10, 0x0a, 0x0e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c,
+	0x69, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x42, 0x09, 0x0a, 0x07, 0x5f,
+	0x72, 0x6f, 0x6c, 0x6c, 0x55, 0x70, 0x22, 0xb8, 0x01, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x64, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1d, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76,
+	0x61, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x01, 0x52, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x88,
+	0x01, 0x01, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x22, 0x80, 0x01, 0x0a, 0x0d, 0x54, 0x72, 0x61, 0x63, 0x69, 0x6e, 0x67, 0x45, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x12, 0x27, 0x0a, 0x0c, 0x6f, 0x74, 0x6c, 0x70, 0x45, 0x6e, 0x64, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0c, 0x6f, 0x74, 0x6c,
+	0x70, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0b,
+	0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x01, 0x52, 0x0b, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52, 0x61, 0x74, 0x69, 0x6f,
+	0x88, 0x01, 0x01, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6f, 0x74, 0x6c, 0x70, 0x45, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52,
+	0x61, 0x74, 0x69, 0x6f, 0x22, 0x8a, 0x01, 0x0a, 0x0b, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61,
+	0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x08, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x33, 0x0a, 0x09,
+	0x75, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61,
+	0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x75, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79,
+	0x73, 0x22, 0xc9, 0x08, 0x0a, 0x0c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x13, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00,
+	0x52, 0x02, 0x69, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x63, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x08, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x41, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x09, 0x6c,
+	0x6f, 0x63, 0x61, 0x6c, 0x41, 0x64, 0x64, 0x72, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x41, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x03, 0x52, 0x0a, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x41, 0x64, 0x64, 0x72, 0x88, 0x01, 0x01,
+	0x12, 0x19, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x04, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x72,
+	0x65, 0x63, 0x76, 0x51, 0x75, 0x65, 0x75, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x48, 0x05,
+	0x52, 0x09, 0x72, 0x65, 0x63, 0x76, 0x51, 0x75, 0x65, 0x75, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1d,
+	0x0a, 0x07, 0x72, 0x65, 0x63, 0x76, 0x42, 0x75, 0x66, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x06, 0x52, 0x07, 0x72, 0x65, 0x63, 0x76, 0x42, 0x75, 0x66, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a,
+	0x09, 0x73, 0x65, 0x6e, 0x64, 0x51, 0x75, 0x65, 0x75, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05,
+	0x48, 0x07, 0x52, 0x09, 0x73, 0x65, 0x6e, 0x64, 0x51, 0x75, 0x65, 0x75, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x1d, 0x0a, 0x07, 0x73, 0x65, 0x6e, 0x64, 0x42, 0x75, 0x66, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x08, 0x52, 0x07, 0x73, 0x65, 0x6e, 0x64, 0x42, 0x75, 0x66, 0x88, 0x01, 0x01, 0x12,
+	0x2b, 0x0a, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x52, 0x65, 0x63, 0x76, 0x4d, 0x69, 0x6c, 0x6c, 0x69,
+	0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x48, 0x09, 0x52, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x52,
+	0x65, 0x63, 0x76, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0e,
+	0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x6e, 0x64, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x03, 0x48, 0x0a, 0x52, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x6e, 0x64,
+	0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x52, 0x65, 0x61, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x48, 0x0b, 0x52, 0x09,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x61, 0x64, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0c,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x03, 0x48, 0x0c, 0x52, 0x0c, 0x62, 0x79, 0x74, 0x65, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74,
+	0x65, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x72, 0x74, 0x74, 0x4d, 0x69, 0x6c, 0x6c,
+	0x69, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x03, 0x48, 0x0d, 0x52, 0x09, 0x72, 0x74, 0x74, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x61, 0x67, 0x65, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x48, 0x0e, 0x52, 0x09, 0x61,
+	0x67, 0x65, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0c, 0x73,
+	0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28,
+	0x03, 0x48, 0x0f, 0x52, 0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53, 0x65, 0x6e,
+	0x74, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0b, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d,
+	0x69, 0x74, 0x73, 0x18, 0x11, 0x20, 0x01, 0x28, 0x03, 0x48, 0x10, 0x52, 0x0b, 0x72, 0x65, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x10, 0x63,
+	0x6f, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18,
+	0x12, 0x20, 0x01, 0x28, 0x05, 0x48, 0x11, 0x52, 0x10, 0x63, 0x6f, 0x6e, 0x67, 0x65, 0x73, 0x74,
+	0x69, 0x6f, 0x6e, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a,
+	0x70, 0x65, 0x65, 0x72, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x13, 0x20, 0x01, 0x28, 0x05,
+	0x48, 0x12, 0x52, 0x0a, 0x70, 0x65, 0x65, 0x72, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x88, 0x01,
+	0x01, 0x12, 0x23, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x76, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18,
+	0x14, 0x20, 0x01, 0x28, 0x05, 0x48, 0x13, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x76, 0x57, 0x69, 0x6e,
+	0x64, 0x6f, 0x77, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x15, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x14, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x69, 0x64, 0x42, 0x0b, 0x0a, 0x09,
+	0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6c, 0x6f,
+	0x63, 0x61, 0x6c, 0x41, 0x64, 0x64, 0x72, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x41, 0x64, 0x64, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x72, 0x65, 0x63, 0x76, 0x51, 0x75, 0x65, 0x75, 0x65, 0x42, 0x0a,
+	0x0a, 0x08, 0x5f, 0x72, 0x65, 0x63, 0x76, 0x42, 0x75, 0x66, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x73,
+	0x65, 0x6e, 0x64, 0x51, 0x75, 0x65, 0x75, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x73, 0x65, 0x6e,
+	0x64, 0x42, 0x75, 0x66, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x6c, 0x61, 0x73, 0x74, 0x52, 0x65, 0x63,
+	0x76, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x6c, 0x61, 0x73, 0x74,
+	0x53, 0x65, 0x6e, 0x64, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x61, 0x64, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x72, 0x74,
+	0x74, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x61, 0x67, 0x65, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e,
+	0x74, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x72, 0x65, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x6d, 0x69, 0x74, 0x73, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x63, 0x6f, 0x6e, 0x67, 0x65,
+	0x73, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x42, 0x0d, 0x0a, 0x0b, 0x5f,
+	0x70, 0x65, 0x65, 0x72, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x72,
+	0x65, 0x63, 0x76, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xd0, 0x04,
+	0x0a, 0x0d, 0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x1f, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x88, 0x01, 0x01,
+	0x12, 0x21, 0x0a, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x64, 0x64, 0x72,
+	0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x41, 0x64, 0x64,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x0a, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x41, 0x64, 0x64, 0x72, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0c, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03,
+	0x52, 0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x88, 0x01,
+	0x01, 0x12, 0x2b, 0x0a, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x52, 0x65, 0x63, 0x76, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x48, 0x04, 0x52, 0x0e, 0x6c, 0x61, 0x73,
+	0x74, 0x52, 0x65, 0x63, 0x76, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2f,
+	0x0a, 0x10, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x6c, 0x69, 0x76, 0x65, 0x50, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x48, 0x05, 0x52, 0x10, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x6c, 0x69, 0x76, 0x65, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x12,
+	0x21, 0x0a, 0x09, 0x62, 0x79, 0x74, 0x65, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x03, 0x48, 0x06, 0x52, 0x09, 0x62, 0x79, 0x74, 0x65, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x88,
+	0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x62, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69,
+	0x76, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x48, 0x07, 0x52, 0x0d, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a,
+	0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x08, 0x52, 0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53,
+	0x65, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x10, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03,
+	0x48, 0x09, 0x52, 0x10, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x63, 0x65,
+	0x69, 0x76, 0x65, 0x64, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x63, 0x6f, 0x6c, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x64,
+	0x64, 0x72, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x41, 0x64, 0x64,
+	0x72, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x6c, 0x61, 0x73, 0x74, 0x52, 0x65, 0x63, 0x76, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x6c,
+	0x69, 0x76, 0x65, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x73,
+	0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x42, 0x13, 0x0a, 0x11, 0x5f,
+	0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64,
+	0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65,
+	0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -156,17 +1706,42 @@ func file_metrics_proto_rawDescGZIP() []byte {
 	return file_metrics_proto_rawDescData
 }
 
-var file_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_metrics_proto_goTypes = []interface{}{
-	(*Metrics)(nil),     // 0: appctl.Metrics
-	(*SessionInfo)(nil), // 1: appctl.SessionInfo
+	(*Metrics)(nil),                   // 0: appctl.Metrics
+	(*GetMetricsHistoryRequest)(nil),  // 1: appctl.GetMetricsHistoryRequest
+	(*MetricsHistory)(nil),            // 2: appctl.MetricsHistory
+	(*WatchMetricsRequest)(nil),       // 3: appctl.WatchMetricsRequest
+	(*MetricsUpdate)(nil),             // 4: appctl.MetricsUpdate
+	(*MetricDelta)(nil),               // 5: appctl.MetricDelta
+	(*GetTopDestinationsRequest)(nil), // 6: appctl.GetTopDestinationsRequest
+	(*TopDestinations)(nil),           // 7: appctl.TopDestinations
+	(*DestinationEntry)(nil),          // 8: appctl.DestinationEntry
+	(*MetricsSample)(nil),             // 9: appctl.MetricsSample
+	(*MetricsDump)(nil),               // 10: appctl.MetricsDump
+	(*MetricGroupDump)(nil),           // 11: appctl.MetricGroupDump
+	(*CounterDump)(nil),               // 12: appctl.CounterDump
+	(*CounterRecord)(nil),             // 13: appctl.CounterRecord
+	(*StatsdExport)(nil),              // 14: appctl.StatsdExport
+	(*TracingExport)(nil),             // 15: appctl.TracingExport
+	(*SessionInfo)(nil),               // 16: appctl.SessionInfo
+	(*SessionEntry)(nil),              // 17: appctl.SessionEntry
+	(*UnderlayEntry)(nil),             // 18: appctl.UnderlayEntry
 }
 var file_metrics_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	9,  // 0: appctl.MetricsHistory.samples:type_name -> appctl.MetricsSample
+	5,  // 1: appctl.MetricsUpdate.metrics:type_name -> appctl.MetricDelta
+	8,  // 2: appctl.TopDestinations.destinations:type_name -> appctl.DestinationEntry
+	11, // 3: appctl.MetricsDump.groups:type_name -> appctl.MetricGroupDump
+	12, // 4: appctl.MetricGroupDump.counters:type_name -> appctl.CounterDump
+	13, // 5: appctl.CounterDump.history:type_name -> appctl.CounterRecord
+	17, // 6: appctl.SessionInfo.sessions:type_name -> appctl.SessionEntry
+	18, // 7: appctl.SessionInfo.underlays:type_name -> appctl.UnderlayEntry
+	8,  // [8:8] is the sub-list for method output_type
+	8,  // [8:8] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_metrics_proto_init() }
@@ -188,6 +1763,186 @@ func file_metrics_proto_init() {
 			}
 		}
 		file_metrics_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMetricsHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MetricsHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchMetricsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MetricsUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MetricDelta); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTopDestinationsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TopDestinations); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DestinationEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MetricsSample); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MetricsDump); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MetricGroupDump); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CounterDump); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CounterRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatsdExport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TracingExport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SessionInfo); i {
 			case 0:
 				return &v.state
@@ -199,15 +1954,54 @@ func file_metrics_proto_init() {
 				return nil
 			}
 		}
+		file_metrics_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnderlayEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_metrics_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[6].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[8].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[9].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[10].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[11].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[12].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[13].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[14].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[15].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[17].OneofWrappers = []interface{}{}
+	file_metrics_proto_msgTypes[18].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_metrics_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
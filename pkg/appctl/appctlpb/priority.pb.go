@@ -0,0 +1,251 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.22.3
+// source: priority.proto
+
+package appctlpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SessionPriority int32
+
+const (
+	// Sessions that don't match any priority rule.
+	SessionPriority_NORMAL_PRIORITY SessionPriority = 0
+	// Latency sensitive sessions, such as SSH and remote desktop.
+	// They are sent first when the underlay is saturated.
+	SessionPriority_INTERACTIVE_PRIORITY SessionPriority = 1
+	// Throughput oriented sessions, such as large downloads.
+	// They are sent after other sessions when the underlay is saturated.
+	SessionPriority_BULK_PRIORITY SessionPriority = 2
+)
+
+// Enum value maps for SessionPriority.
+var (
+	SessionPriority_name = map[int32]string{
+		0: "NORMAL_PRIORITY",
+		1: "INTERACTIVE_PRIORITY",
+		2: "BULK_PRIORITY",
+	}
+	SessionPriority_value = map[string]int32{
+		"NORMAL_PRIORITY":      0,
+		"INTERACTIVE_PRIORITY": 1,
+		"BULK_PRIORITY":        2,
+	}
+)
+
+func (x SessionPriority) Enum() *SessionPriority {
+	p := new(SessionPriority)
+	*p = x
+	return p
+}
+
+func (x SessionPriority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionPriority) Descriptor() protoreflect.EnumDescriptor {
+	return file_priority_proto_enumTypes[0].Descriptor()
+}
+
+func (SessionPriority) Type() protoreflect.EnumType {
+	return &file_priority_proto_enumTypes[0]
+}
+
+func (x SessionPriority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionPriority.Descriptor instead.
+func (SessionPriority) EnumDescriptor() ([]byte, []int) {
+	return file_priority_proto_rawDescGZIP(), []int{0}
+}
+
+type PriorityRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// A list of destination ports or port ranges to match the rule,
+	// for example "22" or "8000-8100".
+	Ports []string `protobuf:"bytes,1,rep,name=ports,proto3" json:"ports,omitempty"`
+	// A list of destination domain names to match the rule.
+	// Subdomains are also matched.
+	DomainNames []string `protobuf:"bytes,2,rep,name=domainNames,proto3" json:"domainNames,omitempty"`
+	// The priority of sessions matched by the rule.
+	Priority *SessionPriority `protobuf:"varint,3,opt,name=priority,proto3,enum=appctl.SessionPriority,oneof" json:"priority,omitempty"`
+}
+
+func (x *PriorityRule) Reset() {
+	*x = PriorityRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_priority_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PriorityRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriorityRule) ProtoMessage() {}
+
+func (x *PriorityRule) ProtoReflect() protoreflect.Message {
+	mi := &file_priority_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriorityRule.ProtoReflect.Descriptor instead.
+func (*PriorityRule) Descriptor() ([]byte, []int) {
+	return file_priority_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PriorityRule) GetPorts() []string {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *PriorityRule) GetDomainNames() []string {
+	if x != nil {
+		return x.DomainNames
+	}
+	return nil
+}
+
+func (x *PriorityRule) GetPriority() SessionPriority {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return SessionPriority_NORMAL_PRIORITY
+}
+
+var File_priority_proto protoreflect.FileDescriptor
+
+var file_priority_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x22, 0x8d, 0x01, 0x0a, 0x0c, 0x50, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x72,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x12,
+	0x20, 0x0a, 0x0b, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65,
+	0x73, 0x12, 0x38, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x48, 0x00, 0x52, 0x08,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x2a, 0x53, 0x0a, 0x0f, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x13, 0x0a, 0x0f, 0x4e,
+	0x4f, 0x52, 0x4d, 0x41, 0x4c, 0x5f, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x10, 0x00,
+	0x12, 0x18, 0x0a, 0x14, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x41, 0x43, 0x54, 0x49, 0x56, 0x45, 0x5f,
+	0x50, 0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x42, 0x55,
+	0x4c, 0x4b, 0x5f, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x10, 0x02, 0x42, 0x2d, 0x5a,
+	0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65,
+	0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70,
+	0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_priority_proto_rawDescOnce sync.Once
+	file_priority_proto_rawDescData = file_priority_proto_rawDesc
+)
+
+func file_priority_proto_rawDescGZIP() []byte {
+	file_priority_proto_rawDescOnce.Do(func() {
+		file_priority_proto_rawDescData = protoimpl.X.CompressGZIP(file_priority_proto_rawDescData)
+	})
+	return file_priority_proto_rawDescData
+}
+
+var file_priority_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_priority_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_priority_proto_goTypes = []interface{}{
+	(SessionPriority)(0), // 0: appctl.SessionPriority
+	(*PriorityRule)(nil), // 1: appctl.PriorityRule
+}
+var file_priority_proto_depIdxs = []int32{
+	0, // 0: appctl.PriorityRule.priority:type_name -> appctl.SessionPriority
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_priority_proto_init() }
+func file_priority_proto_init() {
+	if File_priority_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_priority_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PriorityRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_priority_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_priority_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_priority_proto_goTypes,
+		DependencyIndexes: file_priority_proto_depIdxs,
+		EnumInfos:         file_priority_proto_enumTypes,
+		MessageInfos:      file_priority_proto_msgTypes,
+	}.Build()
+	File_priority_proto = out.File
+	file_priority_proto_rawDesc = nil
+	file_priority_proto_goTypes = nil
+	file_priority_proto_depIdxs = nil
+}
@@ -0,0 +1,208 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.22.3
+// source: retransmission.proto
+
+package appctlpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RetransmissionSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Retransmission timeout in milliseconds before the round trip time
+	// is measured. The default value is 1000.
+	InitialRTOMillis *int32 `protobuf:"varint,1,opt,name=initialRTOMillis,proto3,oneof" json:"initialRTOMillis,omitempty"`
+	// Minimum retransmission timeout in milliseconds.
+	// If not set, the retransmission timeout is not limited.
+	MinRTOMillis *int32 `protobuf:"varint,2,opt,name=minRTOMillis,proto3,oneof" json:"minRTOMillis,omitempty"`
+	// Maximum retransmission timeout in milliseconds, including backoff.
+	// If not set, the retransmission timeout is not limited.
+	MaxRTOMillis *int32 `protobuf:"varint,3,opt,name=maxRTOMillis,proto3,oneof" json:"maxRTOMillis,omitempty"`
+	// The retransmission timeout is multiplied by this value each time
+	// a segment is retransmitted. The default value is 1.2.
+	BackoffMultiplier *float64 `protobuf:"fixed64,4,opt,name=backoffMultiplier,proto3,oneof" json:"backoffMultiplier,omitempty"`
+}
+
+func (x *RetransmissionSettings) Reset() {
+	*x = RetransmissionSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_retransmission_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetransmissionSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetransmissionSettings) ProtoMessage() {}
+
+func (x *RetransmissionSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_retransmission_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetransmissionSettings.ProtoReflect.Descriptor instead.
+func (*RetransmissionSettings) Descriptor() ([]byte, []int) {
+	return file_retransmission_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RetransmissionSettings) GetInitialRTOMillis() int32 {
+	if x != nil && x.InitialRTOMillis != nil {
+		return *x.InitialRTOMillis
+	}
+	return 0
+}
+
+func (x *RetransmissionSettings) GetMinRTOMillis() int32 {
+	if x != nil && x.MinRTOMillis != nil {
+		return *x.MinRTOMillis
+	}
+	return 0
+}
+
+func (x *RetransmissionSettings) GetMaxRTOMillis() int32 {
+	if x != nil && x.MaxRTOMillis != nil {
+		return *x.MaxRTOMillis
+	}
+	return 0
+}
+
+func (x *RetransmissionSettings) GetBackoffMultiplier() float64 {
+	if x != nil && x.BackoffMultiplier != nil {
+		return *x.BackoffMultiplier
+	}
+	return 0
+}
+
+var File_retransmission_proto protoreflect.FileDescriptor
+
+var file_retransmission_proto_rawDesc = []byte{
+	0x0a, 0x14, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x22, 0x9b,
+	0x02, 0x0a, 0x16, 0x52, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x2f, 0x0a, 0x10, 0x69, 0x6e, 0x69,
+	0x74, 0x69, 0x61, 0x6c, 0x52, 0x54, 0x4f, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x10, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x54,
+	0x4f, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0c, 0x6d, 0x69,
+	0x6e, 0x52, 0x54, 0x4f, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x48, 0x01, 0x52, 0x0c, 0x6d, 0x69, 0x6e, 0x52, 0x54, 0x4f, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73,
+	0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x52, 0x54, 0x4f, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x0c, 0x6d, 0x61, 0x78,
+	0x52, 0x54, 0x4f, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x11,
+	0x62, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x11, 0x62, 0x61, 0x63, 0x6b, 0x6f,
+	0x66, 0x66, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x88, 0x01, 0x01, 0x42,
+	0x13, 0x0a, 0x11, 0x5f, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x54, 0x4f, 0x4d, 0x69,
+	0x6c, 0x6c, 0x69, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6d, 0x69, 0x6e, 0x52, 0x54, 0x4f, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6d, 0x61, 0x78, 0x52, 0x54, 0x4f,
+	0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x6f,
+	0x66, 0x66, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x42, 0x2d, 0x5a, 0x2b,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69,
+	0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63,
+	0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_retransmission_proto_rawDescOnce sync.Once
+	file_retransmission_proto_rawDescData = file_retransmission_proto_rawDesc
+)
+
+func file_retransmission_proto_rawDescGZIP() []byte {
+	file_retransmission_proto_rawDescOnce.Do(func() {
+		file_retransmission_proto_rawDescData = protoimpl.X.CompressGZIP(file_retransmission_proto_rawDescData)
+	})
+	return file_retransmission_proto_rawDescData
+}
+
+var file_retransmission_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_retransmission_proto_goTypes = []interface{}{
+	(*RetransmissionSettings)(nil), // 0: appctl.RetransmissionSettings
+}
+var file_retransmission_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_retransmission_proto_init() }
+func file_retransmission_proto_init() {
+	if File_retransmission_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_retransmission_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RetransmissionSettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_retransmission_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_retransmission_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_retransmission_proto_goTypes,
+		DependencyIndexes: file_retransmission_proto_depIdxs,
+		MessageInfos:      file_retransmission_proto_msgTypes,
+	}.Build()
+	File_retransmission_proto = out.File
+	file_retransmission_proto_rawDesc = nil
+	file_retransmission_proto_goTypes = nil
+	file_retransmission_proto_depIdxs = nil
+}
@@ -43,6 +43,53 @@ type ServerAdvancedSettings struct {
 	// Allow using socks5 to access resources served in localhost.
 	// This option should be set to false unless required due to testing purpose.
 	AllowLocalDestination *bool `protobuf:"varint,1,opt,name=allowLocalDestination,proto3,oneof" json:"allowLocalDestination,omitempty"`
+	// Reject proxy requests to IP addresses of this server and IP addresses
+	// of connected proxy clients. This prevents a proxy client from probing
+	// the server or other clients of a shared proxy server.
+	ClientIsolation *bool `protobuf:"varint,2,opt,name=clientIsolation,proto3,oneof" json:"clientIsolation,omitempty"`
+	// Number of sessions this server is able to handle. If set, the server
+	// advertises its load factor to clients when a session is opened,
+	// and clients with multiple servers prefer the less busy ones.
+	// This doesn't limit the number of sessions.
+	SessionCapacity *int32 `protobuf:"varint,3,opt,name=sessionCapacity,proto3,oneof" json:"sessionCapacity,omitempty"`
+	// Parameters of the replay cache, which stores the signature of
+	// recent packets to reject replayed packets.
+	ReplayCache *ReplayCacheSettings `protobuf:"bytes,4,opt,name=replayCache,proto3,oneof" json:"replayCache,omitempty"`
+	// Retransmission parameters of UDP protocol.
+	Retransmission *RetransmissionSettings `protobuf:"bytes,5,opt,name=retransmission,proto3,oneof" json:"retransmission,omitempty"`
+	// Flow control parameters of UDP protocol.
+	FlowControl *FlowControlSettings `protobuf:"bytes,6,opt,name=flowControl,proto3,oneof" json:"flowControl,omitempty"`
+	// Rules to assign priority to proxy sessions by destination.
+	// The first matched rule is used. The priority only impacts data
+	// sent from the server.
+	PriorityRules []*PriorityRule `protobuf:"bytes,7,rep,name=priorityRules,proto3" json:"priorityRules,omitempty"`
+	// Number of seconds "mita stop" waits for existing sessions to
+	// finish. New sessions are rejected during the wait. If not set,
+	// existing sessions are closed immediately. Maximum value is 60.
+	DrainTimeoutSeconds *int32 `protobuf:"varint,8,opt,name=drainTimeoutSeconds,proto3,oneof" json:"drainTimeoutSeconds,omitempty"`
+	// Number of seconds without receiving anything from the client
+	// before a UDP session is closed because the client is considered
+	// dead. Idle sessions exchange heartbeats every 5 seconds.
+	// The default value is 20. It must be between 10 and 300.
+	DeadPeerTimeoutSeconds *int32 `protobuf:"varint,9,opt,name=deadPeerTimeoutSeconds,proto3,oneof" json:"deadPeerTimeoutSeconds,omitempty"`
+	// Maximum random delay in milliseconds before each segment is sent
+	// by the server. It breaks the precise inter-packet timing used by
+	// traffic classifiers, at the cost of some latency. If not set,
+	// segments are sent without delay. Maximum value is 50.
+	TimingJitterMillis *int32 `protobuf:"varint,10,opt,name=timingJitterMillis,proto3,oneof" json:"timingJitterMillis,omitempty"`
+	// Number of seconds a TCP connection waits for the next segment from
+	// the client before it is closed. If not set, there is no read deadline.
+	// Minimum value is 10.
+	UnderlayReadTimeoutSeconds *int32 `protobuf:"varint,11,opt,name=underlayReadTimeoutSeconds,proto3,oneof" json:"underlayReadTimeoutSeconds,omitempty"`
+	// Number of seconds without receiving anything from the client before
+	// a stuck TCP connection is closed. The default value is 600.
+	// Minimum value is 10.
+	StuckUnderlayTimeoutSeconds *int32 `protobuf:"varint,12,opt,name=stuckUnderlayTimeoutSeconds,proto3,oneof" json:"stuckUnderlayTimeoutSeconds,omitempty"`
+	// Automatic banning of source IP addresses that fail to decrypt
+	// or handshake with the server.
+	IpBan *IPBanSettings `protobuf:"bytes,13,opt,name=ipBan,proto3,oneof" json:"ipBan,omitempty"`
+	// Limits of concurrent sessions and underlays.
+	ConnectionLimits *ConnectionLimits `protobuf:"bytes,14,opt,name=connectionLimits,proto3,oneof" json:"connectionLimits,omitempty"`
 }
 
 func (x *ServerAdvancedSettings) Reset() {
@@ -84,6 +131,503 @@ func (x *ServerAdvancedSettings) GetAllowLocalDestination() bool {
 	return false
 }
 
+func (x *ServerAdvancedSettings) GetClientIsolation() bool {
+	if x != nil && x.ClientIsolation != nil {
+		return *x.ClientIsolation
+	}
+	return false
+}
+
+func (x *ServerAdvancedSettings) GetSessionCapacity() int32 {
+	if x != nil && x.SessionCapacity != nil {
+		return *x.SessionCapacity
+	}
+	return 0
+}
+
+func (x *ServerAdvancedSettings) GetReplayCache() *ReplayCacheSettings {
+	if x != nil {
+		return x.ReplayCache
+	}
+	return nil
+}
+
+func (x *ServerAdvancedSettings) GetRetransmission() *RetransmissionSettings {
+	if x != nil {
+		return x.Retransmission
+	}
+	return nil
+}
+
+func (x *ServerAdvancedSettings) GetFlowControl() *FlowControlSettings {
+	if x != nil {
+		return x.FlowControl
+	}
+	return nil
+}
+
+func (x *ServerAdvancedSettings) GetPriorityRules() []*PriorityRule {
+	if x != nil {
+		return x.PriorityRules
+	}
+	return nil
+}
+
+func (x *ServerAdvancedSettings) GetDrainTimeoutSeconds() int32 {
+	if x != nil && x.DrainTimeoutSeconds != nil {
+		return *x.DrainTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ServerAdvancedSettings) GetDeadPeerTimeoutSeconds() int32 {
+	if x != nil && x.DeadPeerTimeoutSeconds != nil {
+		return *x.DeadPeerTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ServerAdvancedSettings) GetTimingJitterMillis() int32 {
+	if x != nil && x.TimingJitterMillis != nil {
+		return *x.TimingJitterMillis
+	}
+	return 0
+}
+
+func (x *ServerAdvancedSettings) GetUnderlayReadTimeoutSeconds() int32 {
+	if x != nil && x.UnderlayReadTimeoutSeconds != nil {
+		return *x.UnderlayReadTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ServerAdvancedSettings) GetStuckUnderlayTimeoutSeconds() int32 {
+	if x != nil && x.StuckUnderlayTimeoutSeconds != nil {
+		return *x.StuckUnderlayTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ServerAdvancedSettings) GetIpBan() *IPBanSettings {
+	if x != nil {
+		return x.IpBan
+	}
+	return nil
+}
+
+func (x *ServerAdvancedSettings) GetConnectionLimits() *ConnectionLimits {
+	if x != nil {
+		return x.ConnectionLimits
+	}
+	return nil
+}
+
+type ConnectionLimits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of concurrent sessions of each user.
+	// If not set or 0, there is no limit.
+	MaxSessionsPerUser *int32 `protobuf:"varint,1,opt,name=maxSessionsPerUser,proto3,oneof" json:"maxSessionsPerUser,omitempty"`
+	// Maximum number of concurrent sessions from each source IP address.
+	// If not set or 0, there is no limit.
+	MaxSessionsPerSourceIP *int32 `protobuf:"varint,2,opt,name=maxSessionsPerSourceIP,proto3,oneof" json:"maxSessionsPerSourceIP,omitempty"`
+	// Maximum number of concurrent TCP underlays of each user.
+	// If not set or 0, there is no limit.
+	MaxUnderlaysPerUser *int32 `protobuf:"varint,3,opt,name=maxUnderlaysPerUser,proto3,oneof" json:"maxUnderlaysPerUser,omitempty"`
+	// Maximum number of concurrent TCP underlays from each source
+	// IP address. If not set or 0, there is no limit.
+	MaxUnderlaysPerSourceIP *int32 `protobuf:"varint,4,opt,name=maxUnderlaysPerSourceIP,proto3,oneof" json:"maxUnderlaysPerSourceIP,omitempty"`
+}
+
+func (x *ConnectionLimits) Reset() {
+	*x = ConnectionLimits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_servercfg_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConnectionLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectionLimits) ProtoMessage() {}
+
+func (x *ConnectionLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_servercfg_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectionLimits.ProtoReflect.Descriptor instead.
+func (*ConnectionLimits) Descriptor() ([]byte, []int) {
+	return file_servercfg_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ConnectionLimits) GetMaxSessionsPerUser() int32 {
+	if x != nil && x.MaxSessionsPerUser != nil {
+		return *x.MaxSessionsPerUser
+	}
+	return 0
+}
+
+func (x *ConnectionLimits) GetMaxSessionsPerSourceIP() int32 {
+	if x != nil && x.MaxSessionsPerSourceIP != nil {
+		return *x.MaxSessionsPerSourceIP
+	}
+	return 0
+}
+
+func (x *ConnectionLimits) GetMaxUnderlaysPerUser() int32 {
+	if x != nil && x.MaxUnderlaysPerUser != nil {
+		return *x.MaxUnderlaysPerUser
+	}
+	return 0
+}
+
+func (x *ConnectionLimits) GetMaxUnderlaysPerSourceIP() int32 {
+	if x != nil && x.MaxUnderlaysPerSourceIP != nil {
+		return *x.MaxUnderlaysPerSourceIP
+	}
+	return 0
+}
+
+type IPBanSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of failed attempts within findTimeSeconds that bans the
+	// source IP address. If not set or 0, source IP addresses are not banned.
+	MaxFailures *int32 `protobuf:"varint,1,opt,name=maxFailures,proto3,oneof" json:"maxFailures,omitempty"`
+	// Number of seconds to count the failed attempts.
+	// The default value is 600. It must be between 10 and 86400.
+	FindTimeSeconds *int32 `protobuf:"varint,2,opt,name=findTimeSeconds,proto3,oneof" json:"findTimeSeconds,omitempty"`
+	// Number of seconds the source IP address is banned.
+	// The default value is 3600. It must be between 10 and 2592000.
+	BanTimeSeconds *int32 `protobuf:"varint,3,opt,name=banTimeSeconds,proto3,oneof" json:"banTimeSeconds,omitempty"`
+}
+
+func (x *IPBanSettings) Reset() {
+	*x = IPBanSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_servercfg_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IPBanSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPBanSettings) ProtoMessage() {}
+
+func (x *IPBanSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_servercfg_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPBanSettings.ProtoReflect.Descriptor instead.
+func (*IPBanSettings) Descriptor() ([]byte, []int) {
+	return file_servercfg_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IPBanSettings) GetMaxFailures() int32 {
+	if x != nil && x.MaxFailures != nil {
+		return *x.MaxFailures
+	}
+	return 0
+}
+
+func (x *IPBanSettings) GetFindTimeSeconds() int32 {
+	if x != nil && x.FindTimeSeconds != nil {
+		return *x.FindTimeSeconds
+	}
+	return 0
+}
+
+func (x *IPBanSettings) GetBanTimeSeconds() int32 {
+	if x != nil && x.BanTimeSeconds != nil {
+		return *x.BanTimeSeconds
+	}
+	return 0
+}
+
+type ReplayCacheSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of signatures in the replay cache of each transport
+	// protocol. Each signature uses about 50 bytes of memory.
+	// The default value is 4194304. It must be at least 1024.
+	Capacity *int32 `protobuf:"varint,1,opt,name=capacity,proto3,oneof" json:"capacity,omitempty"`
+	// Number of seconds a signature is kept in the replay cache.
+	// The default value is 120. It must be between 60 and 3600.
+	ExpireSeconds *int32 `protobuf:"varint,2,opt,name=expireSeconds,proto3,oneof" json:"expireSeconds,omitempty"`
+}
+
+func (x *ReplayCacheSettings) Reset() {
+	*x = ReplayCacheSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_servercfg_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayCacheSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayCacheSettings) ProtoMessage() {}
+
+func (x *ReplayCacheSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_servercfg_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayCacheSettings.ProtoReflect.Descriptor instead.
+func (*ReplayCacheSettings) Descriptor() ([]byte, []int) {
+	return file_servercfg_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReplayCacheSettings) GetCapacity() int32 {
+	if x != nil && x.Capacity != nil {
+		return *x.Capacity
+	}
+	return 0
+}
+
+func (x *ReplayCacheSettings) GetExpireSeconds() int32 {
+	if x != nil && x.ExpireSeconds != nil {
+		return *x.ExpireSeconds
+	}
+	return 0
+}
+
+type ServerPrivilege struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Before the proxy is started, run the server as this user.
+	User *string `protobuf:"bytes,1,opt,name=user,proto3,oneof" json:"user,omitempty"`
+	// Before the proxy is started, run the server as this group.
+	// If not set, the primary group of the user is used.
+	Group *string `protobuf:"bytes,2,opt,name=group,proto3,oneof" json:"group,omitempty"`
+	// Before the proxy is started, change the root directory
+	// of the server to this absolute path.
+	Chroot *string `protobuf:"bytes,3,opt,name=chroot,proto3,oneof" json:"chroot,omitempty"`
+}
+
+func (x *ServerPrivilege) Reset() {
+	*x = ServerPrivilege{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_servercfg_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerPrivilege) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerPrivilege) ProtoMessage() {}
+
+func (x *ServerPrivilege) ProtoReflect() protoreflect.Message {
+	mi := &file_servercfg_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerPrivilege.ProtoReflect.Descriptor instead.
+func (*ServerPrivilege) Descriptor() ([]byte, []int) {
+	return file_servercfg_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ServerPrivilege) GetUser() string {
+	if x != nil && x.User != nil {
+		return *x.User
+	}
+	return ""
+}
+
+func (x *ServerPrivilege) GetGroup() string {
+	if x != nil && x.Group != nil {
+		return *x.Group
+	}
+	return ""
+}
+
+func (x *ServerPrivilege) GetChroot() string {
+	if x != nil && x.Chroot != nil {
+		return *x.Chroot
+	}
+	return ""
+}
+
+type ServerDNS struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// A list of encrypted DNS servers to resolve the domain name of
+	// destinations. The servers are tried in order. Supported formats are
+	//   "https://<HOST>[:<PORT>]/<PATH>" for DNS over HTTPS, and
+	//   "tls://<HOST>[:<PORT>]" for DNS over TLS.
+	// If not set, DNS servers of the operating system are used.
+	Upstreams []string `protobuf:"bytes,1,rep,name=upstreams,proto3" json:"upstreams,omitempty"`
+	// Overrides of specific domain names. The first matched override
+	// is used.
+	Overrides []*DNSOverride `protobuf:"bytes,2,rep,name=overrides,proto3" json:"overrides,omitempty"`
+}
+
+func (x *ServerDNS) Reset() {
+	*x = ServerDNS{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_servercfg_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerDNS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerDNS) ProtoMessage() {}
+
+func (x *ServerDNS) ProtoReflect() protoreflect.Message {
+	mi := &file_servercfg_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerDNS.ProtoReflect.Descriptor instead.
+func (*ServerDNS) Descriptor() ([]byte, []int) {
+	return file_servercfg_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ServerDNS) GetUpstreams() []string {
+	if x != nil {
+		return x.Upstreams
+	}
+	return nil
+}
+
+func (x *ServerDNS) GetOverrides() []*DNSOverride {
+	if x != nil {
+		return x.Overrides
+	}
+	return nil
+}
+
+type DNSOverride struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The domain name to override. Subdomains are also matched.
+	DomainName *string `protobuf:"bytes,1,opt,name=domainName,proto3,oneof" json:"domainName,omitempty"`
+	// If set, the domain name is resolved to this IP address.
+	IpAddress *string `protobuf:"bytes,2,opt,name=ipAddress,proto3,oneof" json:"ipAddress,omitempty"`
+	// If set, the domain name is resolved by these DNS servers,
+	// in the same format as ServerDNS upstreams.
+	// Exactly one of ipAddress and upstreams must be set.
+	Upstreams []string `protobuf:"bytes,3,rep,name=upstreams,proto3" json:"upstreams,omitempty"`
+}
+
+func (x *DNSOverride) Reset() {
+	*x = DNSOverride{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_servercfg_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DNSOverride) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSOverride) ProtoMessage() {}
+
+func (x *DNSOverride) ProtoReflect() protoreflect.Message {
+	mi := &file_servercfg_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSOverride.ProtoReflect.Descriptor instead.
+func (*DNSOverride) Descriptor() ([]byte, []int) {
+	return file_servercfg_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DNSOverride) GetDomainName() string {
+	if x != nil && x.DomainName != nil {
+		return *x.DomainName
+	}
+	return ""
+}
+
+func (x *DNSOverride) GetIpAddress() string {
+	if x != nil && x.IpAddress != nil {
+		return *x.IpAddress
+	}
+	return ""
+}
+
+func (x *DNSOverride) GetUpstreams() []string {
+	if x != nil {
+		return x.Upstreams
+	}
+	return nil
+}
+
 type ServerConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -102,12 +646,31 @@ type ServerConfig struct {
 	Mtu *int32 `protobuf:"varint,5,opt,name=mtu,proto3,oneof" json:"mtu,omitempty"`
 	// Egress proxies and rules.
 	Egress *Egress `protobuf:"bytes,6,opt,name=egress,proto3,oneof" json:"egress,omitempty"`
+	// Drop root privileges before the proxy is started.
+	Privilege *ServerPrivilege `protobuf:"bytes,7,opt,name=privilege,proto3,oneof" json:"privilege,omitempty"`
+	// Push metrics to a StatsD server.
+	Statsd *StatsdExport `protobuf:"bytes,8,opt,name=statsd,proto3,oneof" json:"statsd,omitempty"`
+	// Export traces of session lifecycle to an OpenTelemetry collector.
+	Tracing *TracingExport `protobuf:"bytes,9,opt,name=tracing,proto3,oneof" json:"tracing,omitempty"`
+	// Send events, such as quota exceeded, to a webhook.
+	Webhook *WebhookExport `protobuf:"bytes,10,opt,name=webhook,proto3,oneof" json:"webhook,omitempty"`
+	// Ship log entries to a remote collector.
+	LogShipping *LogShipping `protobuf:"bytes,11,opt,name=logShipping,proto3,oneof" json:"logShipping,omitempty"`
+	// Connections and packets from these source IP ranges are dropped
+	// silently. Each item is an IP range in CIDR format like
+	// "192.0.2.0/24" or a single IP address.
+	BlockedIPRanges []string `protobuf:"bytes,12,rep,name=blockedIPRanges,proto3" json:"blockedIPRanges,omitempty"`
+	// If set, the server listens to ports that change on a schedule,
+	// in addition to the port bindings.
+	PortRotation *PortRotation `protobuf:"bytes,13,opt,name=portRotation,proto3,oneof" json:"portRotation,omitempty"`
+	// DNS servers to resolve the domain name of destinations.
+	Dns *ServerDNS `protobuf:"bytes,14,opt,name=dns,proto3,oneof" json:"dns,omitempty"`
 }
 
 func (x *ServerConfig) Reset() {
 	*x = ServerConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_servercfg_proto_msgTypes[1]
+		mi := &file_servercfg_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -120,7 +683,7 @@ func (x *ServerConfig) String() string {
 func (*ServerConfig) ProtoMessage() {}
 
 func (x *ServerConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_servercfg_proto_msgTypes[1]
+	mi := &file_servercfg_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -133,7 +696,7 @@ func (x *ServerConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerConfig.ProtoReflect.Descriptor instead.
 func (*ServerConfig) Descriptor() ([]byte, []int) {
-	return file_servercfg_proto_rawDescGZIP(), []int{1}
+	return file_servercfg_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ServerConfig) GetPortBindings() []*PortBinding {
@@ -178,6 +741,62 @@ func (x *ServerConfig) GetEgress() *Egress {
 	return nil
 }
 
+func (x *ServerConfig) GetPrivilege() *ServerPrivilege {
+	if x != nil {
+		return x.Privilege
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetStatsd() *StatsdExport {
+	if x != nil {
+		return x.Statsd
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetTracing() *TracingExport {
+	if x != nil {
+		return x.Tracing
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetWebhook() *WebhookExport {
+	if x != nil {
+		return x.Webhook
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetLogShipping() *LogShipping {
+	if x != nil {
+		return x.LogShipping
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetBlockedIPRanges() []string {
+	if x != nil {
+		return x.BlockedIPRanges
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetPortRotation() *PortRotation {
+	if x != nil {
+		return x.PortRotation
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetDns() *ServerDNS {
+	if x != nil {
+		return x.Dns
+	}
+	return nil
+}
+
 var File_servercfg_proto protoreflect.FileDescriptor
 
 var file_servercfg_proto_rawDesc = []byte{
@@ -185,16 +804,154 @@ var file_servercfg_proto_rawDesc = []byte{
 	0x6f, 0x12, 0x06, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x1a, 0x0c, 0x65, 0x67, 0x72, 0x65, 0x73,
 	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0e, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x11, 0x66, 0x6c, 0x6f, 0x77, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x2e, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x1a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0x6d, 0x0a, 0x16, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
-	0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x39, 0x0a, 0x15, 0x61, 0x6c, 0x6c,
-	0x6f, 0x77, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x15, 0x61, 0x6c, 0x6c, 0x6f,
-	0x77, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x88, 0x01, 0x01, 0x42, 0x18, 0x0a, 0x16, 0x5f, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4c, 0x6f,
-	0x63, 0x61, 0x6c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xf8,
-	0x02, 0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
+	0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x0e, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x14, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x91, 0x09, 0x0a, 0x16, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41,
+	0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12,
+	0x39, 0x0a, 0x15, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x65, 0x73,
+	0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00,
+	0x52, 0x15, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x65, 0x73, 0x74,
+	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x49, 0x73, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x48, 0x01, 0x52, 0x0f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x73, 0x6f,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x02, 0x52, 0x0f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x70,
+	0x61, 0x63, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x42, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c,
+	0x61, 0x79, 0x43, 0x61, 0x63, 0x68, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x48, 0x03, 0x52, 0x0b, 0x72, 0x65,
+	0x70, 0x6c, 0x61, 0x79, 0x43, 0x61, 0x63, 0x68, 0x65, 0x88, 0x01, 0x01, 0x12, 0x4b, 0x0a, 0x0e,
+	0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x52, 0x65,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x74, 0x74,
+	0x69, 0x6e, 0x67, 0x73, 0x48, 0x04, 0x52, 0x0e, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d,
+	0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x42, 0x0a, 0x0b, 0x66, 0x6c, 0x6f,
+	0x77, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e, 0x74,
+	0x72, 0x6f, 0x6c, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x48, 0x05, 0x52, 0x0b, 0x66,
+	0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x3a, 0x0a,
+	0x0d, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x07,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x50, 0x72,
+	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x0d, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x35, 0x0a, 0x13, 0x64, 0x72, 0x61,
+	0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x48, 0x06, 0x52, 0x13, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01,
+	0x12, 0x3b, 0x0a, 0x16, 0x64, 0x65, 0x61, 0x64, 0x50, 0x65, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05,
+	0x48, 0x07, 0x52, 0x16, 0x64, 0x65, 0x61, 0x64, 0x50, 0x65, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a,
+	0x12, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x48, 0x08, 0x52, 0x12, 0x74, 0x69, 0x6d,
+	0x69, 0x6e, 0x67, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x88,
+	0x01, 0x01, 0x12, 0x43, 0x0a, 0x1a, 0x75, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x52, 0x65,
+	0x61, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x48, 0x09, 0x52, 0x1a, 0x75, 0x6e, 0x64, 0x65, 0x72, 0x6c,
+	0x61, 0x79, 0x52, 0x65, 0x61, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x45, 0x0a, 0x1b, 0x73, 0x74, 0x75, 0x63, 0x6b,
+	0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x48, 0x0a, 0x52, 0x1b,
+	0x73, 0x74, 0x75, 0x63, 0x6b, 0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x54, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x30,
+	0x0a, 0x05, 0x69, 0x70, 0x42, 0x61, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x49, 0x50, 0x42, 0x61, 0x6e, 0x53, 0x65, 0x74, 0x74,
+	0x69, 0x6e, 0x67, 0x73, 0x48, 0x0b, 0x52, 0x05, 0x69, 0x70, 0x42, 0x61, 0x6e, 0x88, 0x01, 0x01,
+	0x12, 0x49, 0x0a, 0x10, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x70,
+	0x63, 0x74, 0x6c, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x48, 0x0c, 0x52, 0x10, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x88, 0x01, 0x01, 0x42, 0x18, 0x0a, 0x16, 0x5f,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x65, 0x73, 0x74, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x49, 0x73, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x42, 0x0e, 0x0a,
+	0x0c, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x43, 0x61, 0x63, 0x68, 0x65, 0x42, 0x11, 0x0a,
+	0x0f, 0x5f, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x66, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c,
+	0x42, 0x16, 0x0a, 0x14, 0x5f, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x42, 0x19, 0x0a, 0x17, 0x5f, 0x64, 0x65, 0x61,
+	0x64, 0x50, 0x65, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x42, 0x15, 0x0a, 0x13, 0x5f, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x4a, 0x69,
+	0x74, 0x74, 0x65, 0x72, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x1d, 0x0a, 0x1b, 0x5f, 0x75,
+	0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x52, 0x65, 0x61, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x42, 0x1e, 0x0a, 0x1c, 0x5f, 0x73, 0x74,
+	0x75, 0x63, 0x6b, 0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x69, 0x70,
+	0x42, 0x61, 0x6e, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x22, 0xe0, 0x02, 0x0a, 0x10, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x33, 0x0a,
+	0x12, 0x6d, 0x61, 0x78, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x50, 0x65, 0x72, 0x55,
+	0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x12, 0x6d, 0x61, 0x78,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x50, 0x65, 0x72, 0x55, 0x73, 0x65, 0x72, 0x88,
+	0x01, 0x01, 0x12, 0x3b, 0x0a, 0x16, 0x6d, 0x61, 0x78, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x50, 0x65, 0x72, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x50, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x01, 0x52, 0x16, 0x6d, 0x61, 0x78, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x50, 0x65, 0x72, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x50, 0x88, 0x01, 0x01, 0x12,
+	0x35, 0x0a, 0x13, 0x6d, 0x61, 0x78, 0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x73, 0x50,
+	0x65, 0x72, 0x55, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x13,
+	0x6d, 0x61, 0x78, 0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x73, 0x50, 0x65, 0x72, 0x55,
+	0x73, 0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x3d, 0x0a, 0x17, 0x6d, 0x61, 0x78, 0x55, 0x6e, 0x64,
+	0x65, 0x72, 0x6c, 0x61, 0x79, 0x73, 0x50, 0x65, 0x72, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49,
+	0x50, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52, 0x17, 0x6d, 0x61, 0x78, 0x55, 0x6e,
+	0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x73, 0x50, 0x65, 0x72, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x49, 0x50, 0x88, 0x01, 0x01, 0x42, 0x15, 0x0a, 0x13, 0x5f, 0x6d, 0x61, 0x78, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x50, 0x65, 0x72, 0x55, 0x73, 0x65, 0x72, 0x42, 0x19, 0x0a, 0x17,
+	0x5f, 0x6d, 0x61, 0x78, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x50, 0x65, 0x72, 0x53,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x50, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x6d, 0x61, 0x78, 0x55,
+	0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x73, 0x50, 0x65, 0x72, 0x55, 0x73, 0x65, 0x72, 0x42,
+	0x1a, 0x0a, 0x18, 0x5f, 0x6d, 0x61, 0x78, 0x55, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x61, 0x79, 0x73,
+	0x50, 0x65, 0x72, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x50, 0x22, 0xc9, 0x01, 0x0a, 0x0d,
+	0x49, 0x50, 0x42, 0x61, 0x6e, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x25, 0x0a,
+	0x0b, 0x6d, 0x61, 0x78, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x00, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65,
+	0x73, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x66, 0x69, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52,
+	0x0f, 0x66, 0x69, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73,
+	0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0e, 0x62, 0x61, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x0e, 0x62,
+	0x61, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01,
+	0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6d, 0x61, 0x78, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73,
+	0x42, 0x12, 0x0a, 0x10, 0x5f, 0x66, 0x69, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x62, 0x61, 0x6e, 0x54, 0x69, 0x6d, 0x65,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x80, 0x01, 0x0a, 0x13, 0x52, 0x65, 0x70, 0x6c,
+	0x61, 0x79, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12,
+	0x1f, 0x0a, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x00, 0x52, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01,
+	0x12, 0x29, 0x0a, 0x0d, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x0d, 0x65, 0x78, 0x70, 0x69, 0x72,
+	0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f,
+	0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x80, 0x01, 0x0a, 0x0f, 0x53,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x12, 0x17,
+	0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04,
+	0x75, 0x73, 0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x88,
+	0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x63, 0x68, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x02, 0x52, 0x06, 0x63, 0x68, 0x72, 0x6f, 0x6f, 0x74, 0x88, 0x01, 0x01, 0x42,
+	0x07, 0x0a, 0x05, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x63, 0x68, 0x72, 0x6f, 0x6f, 0x74, 0x22, 0x5c, 0x0a,
+	0x09, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x44, 0x4e, 0x53, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x75,
+	0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x12, 0x31, 0x0a, 0x09, 0x6f, 0x76, 0x65, 0x72,
+	0x72, 0x69, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70,
+	0x70, 0x63, 0x74, 0x6c, 0x2e, 0x44, 0x4e, 0x53, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65,
+	0x52, 0x09, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x73, 0x22, 0x90, 0x01, 0x0a, 0x0b,
+	0x44, 0x4e, 0x53, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x23, 0x0a, 0x0a, 0x64,
+	0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x00, 0x52, 0x0a, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x21, 0x0a, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65,
+	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0xfc,
+	0x06, 0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
 	0x37, 0x0a, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x18,
 	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x50,
 	0x6f, 0x72, 0x74, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x0c, 0x70, 0x6f, 0x72, 0x74,
@@ -214,22 +971,54 @@ var file_servercfg_proto_rawDesc = []byte{
 	0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x06, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x06, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x45, 0x67, 0x72,
 	0x65, 0x73, 0x73, 0x48, 0x03, 0x52, 0x06, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x88, 0x01, 0x01,
-	0x42, 0x13, 0x0a, 0x11, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74,
-	0x74, 0x69, 0x6e, 0x67, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e,
-	0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x6d, 0x74, 0x75, 0x42, 0x09,
-	0x0a, 0x07, 0x5f, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x32, 0x80, 0x01, 0x0a, 0x13, 0x53, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x12, 0x30, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x0d,
-	0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x14, 0x2e,
-	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0x12, 0x37, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x12, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x1a, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e,
-	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x42, 0x2d, 0x5a, 0x2b,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69,
-	0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63,
-	0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x12, 0x3a, 0x0a, 0x09, 0x70, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x50, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x48, 0x04, 0x52, 0x09,
+	0x70, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x73, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61,
+	0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x64, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x48, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x73, 0x64, 0x88, 0x01, 0x01, 0x12,
+	0x34, 0x0a, 0x07, 0x74, 0x72, 0x61, 0x63, 0x69, 0x6e, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x69, 0x6e,
+	0x67, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x48, 0x06, 0x52, 0x07, 0x74, 0x72, 0x61, 0x63, 0x69,
+	0x6e, 0x67, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a, 0x07, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e,
+	0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x48, 0x07, 0x52,
+	0x07, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x3a, 0x0a, 0x0b, 0x6c,
+	0x6f, 0x67, 0x53, 0x68, 0x69, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x4c, 0x6f, 0x67, 0x53, 0x68, 0x69,
+	0x70, 0x70, 0x69, 0x6e, 0x67, 0x48, 0x08, 0x52, 0x0b, 0x6c, 0x6f, 0x67, 0x53, 0x68, 0x69, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x88, 0x01, 0x01, 0x12, 0x28, 0x0a, 0x0f, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x65, 0x64, 0x49, 0x50, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x49, 0x50, 0x52, 0x61, 0x6e, 0x67, 0x65,
+	0x73, 0x12, 0x3d, 0x0a, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c,
+	0x2e, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x09, 0x52,
+	0x0c, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01,
+	0x12, 0x28, 0x0a, 0x03, 0x64, 0x6e, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x44, 0x4e, 0x53,
+	0x48, 0x0a, 0x52, 0x03, 0x64, 0x6e, 0x73, 0x88, 0x01, 0x01, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x61,
+	0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x42,
+	0x0f, 0x0a, 0x0d, 0x5f, 0x6c, 0x6f, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x42, 0x06, 0x0a, 0x04, 0x5f, 0x6d, 0x74, 0x75, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x65, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x70, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67,
+	0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x64, 0x42, 0x0a, 0x0a, 0x08,
+	0x5f, 0x74, 0x72, 0x61, 0x63, 0x69, 0x6e, 0x67, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x77, 0x65, 0x62,
+	0x68, 0x6f, 0x6f, 0x6b, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6c, 0x6f, 0x67, 0x53, 0x68, 0x69, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x64, 0x6e, 0x73, 0x32, 0x80, 0x01,
+	0x0a, 0x13, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x30, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x0d, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x1a, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x37, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x1a, 0x14, 0x2e, 0x61, 0x70, 0x70,
+	0x63, 0x74, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65,
+	0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
+	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -244,31 +1033,59 @@ func file_servercfg_proto_rawDescGZIP() []byte {
 	return file_servercfg_proto_rawDescData
 }
 
-var file_servercfg_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_servercfg_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_servercfg_proto_goTypes = []interface{}{
 	(*ServerAdvancedSettings)(nil), // 0: appctl.ServerAdvancedSettings
-	(*ServerConfig)(nil),           // 1: appctl.ServerConfig
-	(*PortBinding)(nil),            // 2: appctl.PortBinding
-	(*User)(nil),                   // 3: appctl.User
-	(LoggingLevel)(0),              // 4: appctl.LoggingLevel
-	(*Egress)(nil),                 // 5: appctl.Egress
-	(*Empty)(nil),                  // 6: appctl.Empty
+	(*ConnectionLimits)(nil),       // 1: appctl.ConnectionLimits
+	(*IPBanSettings)(nil),          // 2: appctl.IPBanSettings
+	(*ReplayCacheSettings)(nil),    // 3: appctl.ReplayCacheSettings
+	(*ServerPrivilege)(nil),        // 4: appctl.ServerPrivilege
+	(*ServerDNS)(nil),              // 5: appctl.ServerDNS
+	(*DNSOverride)(nil),            // 6: appctl.DNSOverride
+	(*ServerConfig)(nil),           // 7: appctl.ServerConfig
+	(*RetransmissionSettings)(nil), // 8: appctl.RetransmissionSettings
+	(*FlowControlSettings)(nil),    // 9: appctl.FlowControlSettings
+	(*PriorityRule)(nil),           // 10: appctl.PriorityRule
+	(*PortBinding)(nil),            // 11: appctl.PortBinding
+	(*User)(nil),                   // 12: appctl.User
+	(LoggingLevel)(0),              // 13: appctl.LoggingLevel
+	(*Egress)(nil),                 // 14: appctl.Egress
+	(*StatsdExport)(nil),           // 15: appctl.StatsdExport
+	(*TracingExport)(nil),          // 16: appctl.TracingExport
+	(*WebhookExport)(nil),          // 17: appctl.WebhookExport
+	(*LogShipping)(nil),            // 18: appctl.LogShipping
+	(*PortRotation)(nil),           // 19: appctl.PortRotation
+	(*Empty)(nil),                  // 20: appctl.Empty
 }
 var file_servercfg_proto_depIdxs = []int32{
-	2, // 0: appctl.ServerConfig.portBindings:type_name -> appctl.PortBinding
-	3, // 1: appctl.ServerConfig.users:type_name -> appctl.User
-	0, // 2: appctl.ServerConfig.advancedSettings:type_name -> appctl.ServerAdvancedSettings
-	4, // 3: appctl.ServerConfig.loggingLevel:type_name -> appctl.LoggingLevel
-	5, // 4: appctl.ServerConfig.egress:type_name -> appctl.Egress
-	6, // 5: appctl.ServerConfigService.GetConfig:input_type -> appctl.Empty
-	1, // 6: appctl.ServerConfigService.SetConfig:input_type -> appctl.ServerConfig
-	1, // 7: appctl.ServerConfigService.GetConfig:output_type -> appctl.ServerConfig
-	1, // 8: appctl.ServerConfigService.SetConfig:output_type -> appctl.ServerConfig
-	7, // [7:9] is the sub-list for method output_type
-	5, // [5:7] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	3,  // 0: appctl.ServerAdvancedSettings.replayCache:type_name -> appctl.ReplayCacheSettings
+	8,  // 1: appctl.ServerAdvancedSettings.retransmission:type_name -> appctl.RetransmissionSettings
+	9,  // 2: appctl.ServerAdvancedSettings.flowControl:type_name -> appctl.FlowControlSettings
+	10, // 3: appctl.ServerAdvancedSettings.priorityRules:type_name -> appctl.PriorityRule
+	2,  // 4: appctl.ServerAdvancedSettings.ipBan:type_name -> appctl.IPBanSettings
+	1,  // 5: appctl.ServerAdvancedSettings.connectionLimits:type_name -> appctl.ConnectionLimits
+	6,  // 6: appctl.ServerDNS.overrides:type_name -> appctl.DNSOverride
+	11, // 7: appctl.ServerConfig.portBindings:type_name -> appctl.PortBinding
+	12, // 8: appctl.ServerConfig.users:type_name -> appctl.User
+	0,  // 9: appctl.ServerConfig.advancedSettings:type_name -> appctl.ServerAdvancedSettings
+	13, // 10: appctl.ServerConfig.loggingLevel:type_name -> appctl.LoggingLevel
+	14, // 11: appctl.ServerConfig.egress:type_name -> appctl.Egress
+	4,  // 12: appctl.ServerConfig.privilege:type_name -> appctl.ServerPrivilege
+	15, // 13: appctl.ServerConfig.statsd:type_name -> appctl.StatsdExport
+	16, // 14: appctl.ServerConfig.tracing:type_name -> appctl.TracingExport
+	17, // 15: appctl.ServerConfig.webhook:type_name -> appctl.WebhookExport
+	18, // 16: appctl.ServerConfig.logShipping:type_name -> appctl.LogShipping
+	19, // 17: appctl.ServerConfig.portRotation:type_name -> appctl.PortRotation
+	5,  // 18: appctl.ServerConfig.dns:type_name -> appctl.ServerDNS
+	20, // 19: appctl.ServerConfigService.GetConfig:input_type -> appctl.Empty
+	7,  // 20: appctl.ServerConfigService.SetConfig:input_type -> appctl.ServerConfig
+	7,  // 21: appctl.ServerConfigService.GetConfig:output_type -> appctl.ServerConfig
+	7,  // 22: appctl.ServerConfigService.SetConfig:output_type -> appctl.ServerConfig
+	21, // [21:23] is the sub-list for method output_type
+	19, // [19:21] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_servercfg_proto_init() }
@@ -279,7 +1096,12 @@ func file_servercfg_proto_init() {
 	file_egress_proto_init()
 	file_empty_proto_init()
 	file_endpoint_proto_init()
+	file_event_proto_init()
+	file_flowcontrol_proto_init()
 	file_logging_proto_init()
+	file_metrics_proto_init()
+	file_priority_proto_init()
+	file_retransmission_proto_init()
 	file_user_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_servercfg_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
@@ -295,6 +1117,78 @@ func file_servercfg_proto_init() {
 			}
 		}
 		file_servercfg_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConnectionLimits); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_servercfg_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IPBanSettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_servercfg_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplayCacheSettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_servercfg_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerPrivilege); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_servercfg_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerDNS); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_servercfg_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DNSOverride); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_servercfg_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ServerConfig); i {
 			case 0:
 				return &v.state
@@ -309,13 +1203,18 @@ func file_servercfg_proto_init() {
 	}
 	file_servercfg_proto_msgTypes[0].OneofWrappers = []interface{}{}
 	file_servercfg_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_servercfg_proto_msgTypes[2].OneofWrappers = []interface{}{}
+	file_servercfg_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_servercfg_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_servercfg_proto_msgTypes[6].OneofWrappers = []interface{}{}
+	file_servercfg_proto_msgTypes[7].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_servercfg_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -35,6 +35,107 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type QuotaPeriod int32
+
+const (
+	// The quota is renewed on a rolling basis of a number of days.
+	QuotaPeriod_QUOTA_PERIOD_ROLLING_DAYS QuotaPeriod = 0
+	// The quota is renewed at 00:00 of the first day of every month,
+	// in the local time zone of the server.
+	QuotaPeriod_QUOTA_PERIOD_CALENDAR_MONTH QuotaPeriod = 1
+	// The quota is never renewed.
+	QuotaPeriod_QUOTA_PERIOD_TOTAL QuotaPeriod = 2
+)
+
+// Enum value maps for QuotaPeriod.
+var (
+	QuotaPeriod_name = map[int32]string{
+		0: "QUOTA_PERIOD_ROLLING_DAYS",
+		1: "QUOTA_PERIOD_CALENDAR_MONTH",
+		2: "QUOTA_PERIOD_TOTAL",
+	}
+	QuotaPeriod_value = map[string]int32{
+		"QUOTA_PERIOD_ROLLING_DAYS":   0,
+		"QUOTA_PERIOD_CALENDAR_MONTH": 1,
+		"QUOTA_PERIOD_TOTAL":          2,
+	}
+)
+
+func (x QuotaPeriod) Enum() *QuotaPeriod {
+	p := new(QuotaPeriod)
+	*p = x
+	return p
+}
+
+func (x QuotaPeriod) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QuotaPeriod) Descriptor() protoreflect.EnumDescriptor {
+	return file_user_proto_enumTypes[0].Descriptor()
+}
+
+func (QuotaPeriod) Type() protoreflect.EnumType {
+	return &file_user_proto_enumTypes[0]
+}
+
+func (x QuotaPeriod) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QuotaPeriod.Descriptor instead.
+func (QuotaPeriod) EnumDescriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{0}
+}
+
+type QuotaAction int32
+
+const (
+	// Reject new sessions of the user.
+	QuotaAction_QUOTA_ACTION_REJECT QuotaAction = 0
+	// Accept new sessions of the user, but limit the speed.
+	QuotaAction_QUOTA_ACTION_THROTTLE QuotaAction = 1
+)
+
+// Enum value maps for QuotaAction.
+var (
+	QuotaAction_name = map[int32]string{
+		0: "QUOTA_ACTION_REJECT",
+		1: "QUOTA_ACTION_THROTTLE",
+	}
+	QuotaAction_value = map[string]int32{
+		"QUOTA_ACTION_REJECT":   0,
+		"QUOTA_ACTION_THROTTLE": 1,
+	}
+)
+
+func (x QuotaAction) Enum() *QuotaAction {
+	p := new(QuotaAction)
+	*p = x
+	return p
+}
+
+func (x QuotaAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QuotaAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_user_proto_enumTypes[1].Descriptor()
+}
+
+func (QuotaAction) Type() protoreflect.EnumType {
+	return &file_user_proto_enumTypes[1]
+}
+
+func (x QuotaAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QuotaAction.Descriptor instead.
+func (QuotaAction) EnumDescriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{1}
+}
+
 type User struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -52,6 +153,28 @@ type User struct {
 	// User quotas.
 	// This has no effect at the client side.
 	Quotas []*Quota `protobuf:"bytes,4,rep,name=quotas,proto3" json:"quotas,omitempty"`
+	// Time windows when the user is allowed to open new sessions.
+	// If not set, the user can access the server at any time.
+	// This has no effect at the client side.
+	AccessWindows []*AccessWindow `protobuf:"bytes,5,rep,name=accessWindows,proto3" json:"accessWindows,omitempty"`
+	// Speed limits of the user.
+	// This has no effect at the client side.
+	RateLimit *RateLimit `protobuf:"bytes,6,opt,name=rateLimit,proto3,oneof" json:"rateLimit,omitempty"`
+	// Ports like "443" or port ranges like "8000-8100" the user is
+	// allowed to connect. If not set, all ports are allowed.
+	// This has no effect at the client side.
+	AllowedPorts []string `protobuf:"bytes,7,rep,name=allowedPorts,proto3" json:"allowedPorts,omitempty"`
+	// Ports or port ranges the user is not allowed to connect.
+	// This has no effect at the client side.
+	DeniedPorts []string `protobuf:"bytes,8,rep,name=deniedPorts,proto3" json:"deniedPorts,omitempty"`
+	// Domain names the user is allowed to connect, including subdomains.
+	// If neither allowedDomainNames nor allowedIPRanges is set,
+	// all destinations are allowed.
+	// This has no effect at the client side.
+	AllowedDomainNames []string `protobuf:"bytes,9,rep,name=allowedDomainNames,proto3" json:"allowedDomainNames,omitempty"`
+	// IP ranges in CIDR format the user is allowed to connect.
+	// This has no effect at the client side.
+	AllowedIPRanges []string `protobuf:"bytes,10,rep,name=allowedIPRanges,proto3" json:"allowedIPRanges,omitempty"`
 }
 
 func (x *User) Reset() {
@@ -114,6 +237,48 @@ func (x *User) GetQuotas() []*Quota {
 	return nil
 }
 
+func (x *User) GetAccessWindows() []*AccessWindow {
+	if x != nil {
+		return x.AccessWindows
+	}
+	return nil
+}
+
+func (x *User) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *User) GetAllowedPorts() []string {
+	if x != nil {
+		return x.AllowedPorts
+	}
+	return nil
+}
+
+func (x *User) GetDeniedPorts() []string {
+	if x != nil {
+		return x.DeniedPorts
+	}
+	return nil
+}
+
+func (x *User) GetAllowedDomainNames() []string {
+	if x != nil {
+		return x.AllowedDomainNames
+	}
+	return nil
+}
+
+func (x *User) GetAllowedIPRanges() []string {
+	if x != nil {
+		return x.AllowedIPRanges
+	}
+	return nil
+}
+
 type Quota struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -121,9 +286,18 @@ type Quota struct {
 
 	// Number of days to renew the quota.
 	// The renew is rolling based.
+	// This is only used by QUOTA_PERIOD_ROLLING_DAYS.
 	Days *int32 `protobuf:"varint,1,opt,name=days,proto3,oneof" json:"days,omitempty"`
 	// Number of megabytes the user allowed to send and receive.
 	Megabytes *int32 `protobuf:"varint,2,opt,name=megabytes,proto3,oneof" json:"megabytes,omitempty"`
+	// How the quota is renewed.
+	Period *QuotaPeriod `protobuf:"varint,3,opt,name=period,proto3,enum=appctl.QuotaPeriod,oneof" json:"period,omitempty"`
+	// What to do after the quota is exhausted.
+	Action *QuotaAction `protobuf:"varint,4,opt,name=action,proto3,enum=appctl.QuotaAction,oneof" json:"action,omitempty"`
+	// Speed limit in kilobytes per second shared by all the sessions
+	// of the user, after the quota is exhausted.
+	// This is only used by QUOTA_ACTION_THROTTLE.
+	ThrottleKilobytesPerSecond *int32 `protobuf:"varint,5,opt,name=throttleKilobytesPerSecond,proto3,oneof" json:"throttleKilobytesPerSecond,omitempty"`
 }
 
 func (x *Quota) Reset() {
@@ -172,11 +346,173 @@ func (x *Quota) GetMegabytes() int32 {
 	return 0
 }
 
+func (x *Quota) GetPeriod() QuotaPeriod {
+	if x != nil && x.Period != nil {
+		return *x.Period
+	}
+	return QuotaPeriod_QUOTA_PERIOD_ROLLING_DAYS
+}
+
+func (x *Quota) GetAction() QuotaAction {
+	if x != nil && x.Action != nil {
+		return *x.Action
+	}
+	return QuotaAction_QUOTA_ACTION_REJECT
+}
+
+func (x *Quota) GetThrottleKilobytesPerSecond() int32 {
+	if x != nil && x.ThrottleKilobytesPerSecond != nil {
+		return *x.ThrottleKilobytesPerSecond
+	}
+	return 0
+}
+
+type RateLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Speed limit in kilobytes per second shared by all the sessions
+	// of the user. If not set, the speed is not limited.
+	KilobytesPerSecond *int32 `protobuf:"varint,1,opt,name=kilobytesPerSecond,proto3,oneof" json:"kilobytesPerSecond,omitempty"`
+	// Speed limit in kilobytes per second shared by the sessions of the
+	// user from the same source IP address.
+	// If not set, the speed is not limited.
+	PerSourceIPKilobytesPerSecond *int32 `protobuf:"varint,2,opt,name=perSourceIPKilobytesPerSecond,proto3,oneof" json:"perSourceIPKilobytesPerSecond,omitempty"`
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_user_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RateLimit) GetKilobytesPerSecond() int32 {
+	if x != nil && x.KilobytesPerSecond != nil {
+		return *x.KilobytesPerSecond
+	}
+	return 0
+}
+
+func (x *RateLimit) GetPerSourceIPKilobytesPerSecond() int32 {
+	if x != nil && x.PerSourceIPKilobytesPerSecond != nil {
+		return *x.PerSourceIPKilobytesPerSecond
+	}
+	return 0
+}
+
+type AccessWindow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Days of the week when the window starts.
+	// 0 is Sunday, 1 is Monday, and so on until 6 is Saturday.
+	// If not set, the window starts every day.
+	Weekdays []int32 `protobuf:"varint,1,rep,packed,name=weekdays,proto3" json:"weekdays,omitempty"`
+	// Start time of the window in "HH:MM" 24-hour format.
+	StartTime *string `protobuf:"bytes,2,opt,name=startTime,proto3,oneof" json:"startTime,omitempty"`
+	// End time of the window in "HH:MM" 24-hour format. The end time is
+	// not included. If the end time is earlier than the start time, the
+	// window ends at the next day. If the end time is the same as the
+	// start time, the window lasts 24 hours.
+	EndTime *string `protobuf:"bytes,3,opt,name=endTime,proto3,oneof" json:"endTime,omitempty"`
+	// IANA time zone name of start time and end time, e.g. "Asia/Shanghai".
+	// If not set, the local time zone of the server is used.
+	TimeZone *string `protobuf:"bytes,4,opt,name=timeZone,proto3,oneof" json:"timeZone,omitempty"`
+}
+
+func (x *AccessWindow) Reset() {
+	*x = AccessWindow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_user_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AccessWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessWindow) ProtoMessage() {}
+
+func (x *AccessWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessWindow.ProtoReflect.Descriptor instead.
+func (*AccessWindow) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AccessWindow) GetWeekdays() []int32 {
+	if x != nil {
+		return x.Weekdays
+	}
+	return nil
+}
+
+func (x *AccessWindow) GetStartTime() string {
+	if x != nil && x.StartTime != nil {
+		return *x.StartTime
+	}
+	return ""
+}
+
+func (x *AccessWindow) GetEndTime() string {
+	if x != nil && x.EndTime != nil {
+		return *x.EndTime
+	}
+	return ""
+}
+
+func (x *AccessWindow) GetTimeZone() string {
+	if x != nil && x.TimeZone != nil {
+		return *x.TimeZone
+	}
+	return ""
+}
+
 var File_user_proto protoreflect.FileDescriptor
 
 var file_user_proto_rawDesc = []byte{
 	0x0a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x61, 0x70,
-	0x70, 0x63, 0x74, 0x6c, 0x22, 0xbd, 0x01, 0x0a, 0x04, 0x55, 0x73, 0x65, 0x72, 0x12, 0x17, 0x0a,
+	0x70, 0x63, 0x74, 0x6c, 0x22, 0xdd, 0x03, 0x0a, 0x04, 0x55, 0x73, 0x65, 0x72, 0x12, 0x17, 0x0a,
 	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e,
 	0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f,
 	0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73,
@@ -185,19 +521,85 @@ var file_user_proto_rawDesc = []byte{
 	0x02, 0x52, 0x0e, 0x68, 0x61, 0x73, 0x68, 0x65, 0x64, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72,
 	0x64, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x73, 0x18, 0x04,
 	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x51, 0x75,
-	0x6f, 0x74, 0x61, 0x52, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f,
-	0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72,
-	0x64, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x65, 0x64, 0x50, 0x61, 0x73, 0x73,
-	0x77, 0x6f, 0x72, 0x64, 0x22, 0x5a, 0x0a, 0x05, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x17, 0x0a,
-	0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x04, 0x64,
-	0x61, 0x79, 0x73, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x6d, 0x65, 0x67, 0x61, 0x62, 0x79,
-	0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x09, 0x6d, 0x65, 0x67,
-	0x61, 0x62, 0x79, 0x74, 0x65, 0x73, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x64, 0x61,
-	0x79, 0x73, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6d, 0x65, 0x67, 0x61, 0x62, 0x79, 0x74, 0x65, 0x73,
-	0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65,
-	0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65, 0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
-	0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x74, 0x61, 0x52, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x73, 0x12, 0x3a, 0x0a, 0x0d, 0x61,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x41, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x52, 0x0d, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x73, 0x12, 0x34, 0x0a, 0x09, 0x72, 0x61, 0x74, 0x65, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x70,
+	0x63, 0x74, 0x6c, 0x2e, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x48, 0x03, 0x52,
+	0x09, 0x72, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a,
+	0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x50, 0x6f, 0x72, 0x74,
+	0x73, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x50, 0x6f, 0x72, 0x74, 0x73,
+	0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x50, 0x6f,
+	0x72, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x12, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x44, 0x6f,
+	0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x12, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x4e, 0x61,
+	0x6d, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x49, 0x50,
+	0x52, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x61, 0x6c,
+	0x6c, 0x6f, 0x77, 0x65, 0x64, 0x49, 0x50, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x42, 0x07, 0x0a,
+	0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x65, 0x64, 0x50, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x22, 0xb8, 0x02, 0x0a, 0x05, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x17,
+	0x0a, 0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x04,
+	0x64, 0x61, 0x79, 0x73, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x6d, 0x65, 0x67, 0x61, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x09, 0x6d, 0x65,
+	0x67, 0x61, 0x62, 0x79, 0x74, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a, 0x06, 0x70, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x70,
+	0x63, 0x74, 0x6c, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x48,
+	0x02, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a, 0x06,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x61,
+	0x70, 0x70, 0x63, 0x74, 0x6c, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x48, 0x03, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x43,
+	0x0a, 0x1a, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x4b, 0x69, 0x6c, 0x6f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x04, 0x52, 0x1a, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x4b, 0x69,
+	0x6c, 0x6f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x42, 0x0c, 0x0a, 0x0a,
+	0x5f, 0x6d, 0x65, 0x67, 0x61, 0x62, 0x79, 0x74, 0x65, 0x73, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x70,
+	0x65, 0x72, 0x69, 0x6f, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x42, 0x1d, 0x0a, 0x1b, 0x5f, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x4b, 0x69, 0x6c,
+	0x6f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x22,
+	0xc4, 0x01, 0x0a, 0x09, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x33, 0x0a,
+	0x12, 0x6b, 0x69, 0x6c, 0x6f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x12, 0x6b, 0x69, 0x6c,
+	0x6f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x88,
+	0x01, 0x01, 0x12, 0x49, 0x0a, 0x1d, 0x70, 0x65, 0x72, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49,
+	0x50, 0x4b, 0x69, 0x6c, 0x6f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x1d, 0x70, 0x65, 0x72,
+	0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x50, 0x4b, 0x69, 0x6c, 0x6f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x88, 0x01, 0x01, 0x42, 0x15, 0x0a,
+	0x13, 0x5f, 0x6b, 0x69, 0x6c, 0x6f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x42, 0x20, 0x0a, 0x1e, 0x5f, 0x70, 0x65, 0x72, 0x53, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x49, 0x50, 0x4b, 0x69, 0x6c, 0x6f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x22, 0xb4, 0x01, 0x0a, 0x0c, 0x41, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x65, 0x65, 0x6b, 0x64,
+	0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x05, 0x52, 0x08, 0x77, 0x65, 0x65, 0x6b, 0x64,
+	0x61, 0x79, 0x73, 0x12, 0x21, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54,
+	0x69, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69,
+	0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x5a, 0x6f, 0x6e,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x5a,
+	0x6f, 0x6e, 0x65, 0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x54, 0x69, 0x6d, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65,
+	0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5a, 0x6f, 0x6e, 0x65, 0x2a, 0x65, 0x0a,
+	0x0b, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x1d, 0x0a, 0x19,
+	0x51, 0x55, 0x4f, 0x54, 0x41, 0x5f, 0x50, 0x45, 0x52, 0x49, 0x4f, 0x44, 0x5f, 0x52, 0x4f, 0x4c,
+	0x4c, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x41, 0x59, 0x53, 0x10, 0x00, 0x12, 0x1f, 0x0a, 0x1b, 0x51,
+	0x55, 0x4f, 0x54, 0x41, 0x5f, 0x50, 0x45, 0x52, 0x49, 0x4f, 0x44, 0x5f, 0x43, 0x41, 0x4c, 0x45,
+	0x4e, 0x44, 0x41, 0x52, 0x5f, 0x4d, 0x4f, 0x4e, 0x54, 0x48, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12,
+	0x51, 0x55, 0x4f, 0x54, 0x41, 0x5f, 0x50, 0x45, 0x52, 0x49, 0x4f, 0x44, 0x5f, 0x54, 0x4f, 0x54,
+	0x41, 0x4c, 0x10, 0x02, 0x2a, 0x41, 0x0a, 0x0b, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x13, 0x51, 0x55, 0x4f, 0x54, 0x41, 0x5f, 0x41, 0x43, 0x54,
+	0x49, 0x4f, 0x4e, 0x5f, 0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15,
+	0x51, 0x55, 0x4f, 0x54, 0x41, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54, 0x48, 0x52,
+	0x4f, 0x54, 0x54, 0x4c, 0x45, 0x10, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x65, 0x6e, 0x66, 0x65, 0x69, 0x6e, 0x2f, 0x6d, 0x69, 0x65,
+	0x72, 0x75, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x70, 0x63, 0x74, 0x6c, 0x2f, 0x61, 0x70,
+	0x70, 0x63, 0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -212,18 +614,27 @@ func file_user_proto_rawDescGZIP() []byte {
 	return file_user_proto_rawDescData
 }
 
-var file_user_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_user_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_user_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_user_proto_goTypes = []interface{}{
-	(*User)(nil),  // 0: appctl.User
-	(*Quota)(nil), // 1: appctl.Quota
+	(QuotaPeriod)(0),     // 0: appctl.QuotaPeriod
+	(QuotaAction)(0),     // 1: appctl.QuotaAction
+	(*User)(nil),         // 2: appctl.User
+	(*Quota)(nil),        // 3: appctl.Quota
+	(*RateLimit)(nil),    // 4: appctl.RateLimit
+	(*AccessWindow)(nil), // 5: appctl.AccessWindow
 }
 var file_user_proto_depIdxs = []int32{
-	1, // 0: appctl.User.quotas:type_name -> appctl.Quota
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 0: appctl.User.quotas:type_name -> appctl.Quota
+	5, // 1: appctl.User.accessWindows:type_name -> appctl.AccessWindow
+	4, // 2: appctl.User.rateLimit:type_name -> appctl.RateLimit
+	0, // 3: appctl.Quota.period:type_name -> appctl.QuotaPeriod
+	1, // 4: appctl.Quota.action:type_name -> appctl.QuotaAction
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_user_proto_init() }
@@ -256,21 +667,48 @@ func file_user_proto_init() {
 				return nil
 			}
 		}
+		file_user_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RateLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_user_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AccessWindow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_user_proto_msgTypes[0].OneofWrappers = []interface{}{}
 	file_user_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_user_proto_msgTypes[2].OneofWrappers = []interface{}{}
+	file_user_proto_msgTypes[3].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_user_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_user_proto_goTypes,
 		DependencyIndexes: file_user_proto_depIdxs,
+		EnumInfos:         file_user_proto_enumTypes,
 		MessageInfos:      file_user_proto_msgTypes,
 	}.Build()
 	File_user_proto = out.File
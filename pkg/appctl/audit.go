@@ -0,0 +1,269 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// auditLogFileName is the name of the append-only audit log file,
+// in the same directory as the config file.
+const auditLogFileName = "audit.log"
+
+const (
+	// AuditSourceCLI means the action is done by a command line.
+	AuditSourceCLI = "CLI"
+
+	// AuditSourceRPC means the action is done by a RPC to the daemon.
+	AuditSourceRPC = "RPC"
+)
+
+// auditLock serializes the writes to audit log files in this process.
+var auditLock sync.Mutex
+
+// RecordClientAudit appends a record to the client audit log.
+// A failure is logged and doesn't affect the action itself.
+func RecordClientAudit(source, action, summary string) {
+	path, err := clientAuditLogPath()
+	if err == nil {
+		err = appendAuditRecord(path, 0600, newAuditRecord(source, action, summary))
+	}
+	if err != nil {
+		log.Warnf("unable to write audit log: %v", err)
+	}
+}
+
+// ReadClientAuditLog returns the latest records of the client audit log.
+// If limit is not positive, all records are returned.
+func ReadClientAuditLog(limit int) (*pb.AuditLog, error) {
+	path, err := clientAuditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	return readAuditLog(path, limit)
+}
+
+// recordServerAudit appends a record of an action received from RPC
+// to the server audit log.
+func recordServerAudit(action, summary string) {
+	path := filepath.Join(cachedServerConfigDir, auditLogFileName)
+	if err := appendAuditRecord(path, 0660, newAuditRecord(AuditSourceRPC, action, summary)); err != nil {
+		log.Warnf("unable to write audit log: %v", err)
+	}
+}
+
+// clientAuditLogPath returns the path of client audit log file.
+func clientAuditLogPath() (string, error) {
+	if _, _, err := clientConfigFilePath(); err != nil {
+		return "", fmt.Errorf("clientConfigFilePath() failed: %w", err)
+	}
+	if err := prepareClientConfigDir(); err != nil {
+		return "", fmt.Errorf("prepareClientConfigDir() failed: %w", err)
+	}
+	return filepath.Join(cachedClientConfigDir, auditLogFileName), nil
+}
+
+func newAuditRecord(source, action, summary string) *pb.AuditRecord {
+	record := &pb.AuditRecord{
+		TimeUnixMilli: proto.Int64(time.Now().UnixMilli()),
+		Action:        proto.String(action),
+		Source:        proto.String(source),
+	}
+	if summary != "" {
+		record.Summary = proto.String(summary)
+	}
+	if source == AuditSourceCLI {
+		if u, err := user.Current(); err == nil {
+			record.User = proto.String(u.Username)
+		}
+	}
+	return record
+}
+
+// appendAuditRecord writes the record as a single JSON line
+// to the end of the audit log file.
+func appendAuditRecord(path string, perm os.FileMode, record *pb.AuditRecord) error {
+	b, err := protojson.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("protojson.Marshal() failed: %w", err)
+	}
+	b = append(b, '\n')
+
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, perm)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile(%q) failed: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(b); err != nil {
+		return fmt.Errorf("write to %q failed: %w", path, err)
+	}
+	return nil
+}
+
+// readAuditLog returns the latest records of the audit log file.
+// Lines that can't be parsed are skipped. If the file doesn't exist,
+// an empty audit log is returned.
+func readAuditLog(path string, limit int) (*pb.AuditLog, error) {
+	res := &pb.AuditLog{}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, nil
+		}
+		return nil, fmt.Errorf("os.Open(%q) failed: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		record := &pb.AuditRecord{}
+		if err := protojson.Unmarshal(line, record); err != nil {
+			continue
+		}
+		res.Records = append(res.Records, record)
+		if limit > 0 && len(res.Records) > limit {
+			res.Records = res.Records[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %q failed: %w", path, err)
+	}
+	return res, nil
+}
+
+// ConfigDiffSummary describes the differences between two configs
+// of the same type. Only the names of changed top level fields are
+// included. For a list of items that have a name, such as profiles
+// and users, the names of added, removed and changed items are included.
+// The values are never included, so secrets are not exposed.
+func ConfigDiffSummary(oldConfig, newConfig proto.Message) string {
+	if newConfig == nil {
+		return ""
+	}
+	n := newConfig.ProtoReflect()
+	o := n.Type().Zero()
+	if oldConfig != nil && oldConfig.ProtoReflect().IsValid() {
+		o = oldConfig.ProtoReflect()
+	}
+
+	var changes []string
+	fields := n.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if auditFieldEqual(o, n, fd) {
+			continue
+		}
+		if fd.IsList() && fd.Message() != nil {
+			if key := auditKeyField(fd.Message()); key != nil {
+				changes = append(changes, fmt.Sprintf("%s %s", fd.JSONName(), auditListDiff(o.Get(fd).List(), n.Get(fd).List(), key)))
+				continue
+			}
+		}
+		changes = append(changes, fmt.Sprintf("%s changed", fd.JSONName()))
+	}
+	if len(changes) == 0 {
+		return "no change"
+	}
+	return strings.Join(changes, "; ")
+}
+
+// auditFieldEqual returns true if the field has the same value in both messages.
+func auditFieldEqual(o, n protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+	a := n.Type().New()
+	if o.Has(fd) {
+		a.Set(fd, o.Get(fd))
+	}
+	b := n.Type().New()
+	if n.Has(fd) {
+		b.Set(fd, n.Get(fd))
+	}
+	return proto.Equal(a.Interface(), b.Interface())
+}
+
+// auditKeyField returns the field that identifies an item in a list.
+// It returns nil if the message doesn't have such a field.
+func auditKeyField(md protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	for _, name := range []protoreflect.Name{"name", "profileName"} {
+		fd := md.Fields().ByName(name)
+		if fd != nil && fd.Kind() == protoreflect.StringKind && !fd.IsList() {
+			return fd
+		}
+	}
+	return nil
+}
+
+// auditListDiff describes the added, removed and changed items of a list.
+func auditListDiff(o, n protoreflect.List, key protoreflect.FieldDescriptor) string {
+	toMap := func(l protoreflect.List) map[string]proto.Message {
+		m := make(map[string]proto.Message)
+		for i := 0; i < l.Len(); i++ {
+			item := l.Get(i).Message()
+			m[item.Get(key).String()] = item.Interface()
+		}
+		return m
+	}
+	oldItems := toMap(o)
+	newItems := toMap(n)
+	var added, removed, changed []string
+	for name, item := range newItems {
+		if oldItem, ok := oldItems[name]; !ok {
+			added = append(added, name)
+		} else if !proto.Equal(oldItem, item) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range oldItems {
+		if _, ok := newItems[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	var parts []string
+	for _, p := range []struct {
+		verb  string
+		names []string
+	}{{"added", added}, {"removed", removed}, {"changed", changed}} {
+		if len(p.names) > 0 {
+			sort.Strings(p.names)
+			parts = append(parts, fmt.Sprintf("%s [%s]", p.verb, strings.Join(p.names, ", ")))
+		}
+	}
+	if len(parts) == 0 {
+		return "reordered"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConfigDiffSummary(t *testing.T) {
+	oldConfig := &pb.ClientConfig{
+		Profiles: []*pb.ClientProfile{
+			{ProfileName: proto.String("a"), User: &pb.User{Name: proto.String("u"), Password: proto.String("p1")}},
+			{ProfileName: proto.String("b")},
+		},
+		ActiveProfile: proto.String("a"),
+		Socks5Port:    proto.Int32(1080),
+	}
+	newConfig := &pb.ClientConfig{
+		Profiles: []*pb.ClientProfile{
+			{ProfileName: proto.String("a"), User: &pb.User{Name: proto.String("u"), Password: proto.String("p2")}},
+			{ProfileName: proto.String("c")},
+		},
+		ActiveProfile: proto.String("a"),
+		Socks5Port:    proto.Int32(1081),
+	}
+	want := "profiles added [c], removed [b], changed [a]; socks5Port changed"
+	if got := ConfigDiffSummary(oldConfig, newConfig); got != want {
+		t.Errorf("ConfigDiffSummary() = %q, want %q", got, want)
+	}
+	if got := ConfigDiffSummary(newConfig, proto.Clone(newConfig)); got != "no change" {
+		t.Errorf("ConfigDiffSummary() of the same config = %q, want %q", got, "no change")
+	}
+
+	var missing *pb.ClientConfig
+	want = "profiles added [a, c]; activeProfile changed; socks5Port changed"
+	if got := ConfigDiffSummary(missing, newConfig); got != want {
+		t.Errorf("ConfigDiffSummary() from nil config = %q, want %q", got, want)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), auditLogFileName)
+	auditLog, err := readAuditLog(path, 0)
+	if err != nil {
+		t.Fatalf("readAuditLog() failed: %v", err)
+	}
+	if len(auditLog.GetRecords()) != 0 {
+		t.Errorf("got %d records from missing file, want 0", len(auditLog.GetRecords()))
+	}
+
+	for _, action := range []string{"apply config", "start", "stop"} {
+		if err := appendAuditRecord(path, 0600, newAuditRecord(AuditSourceRPC, action, "")); err != nil {
+			t.Fatalf("appendAuditRecord() failed: %v", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("os.OpenFile() failed: %v", err)
+	}
+	f.WriteString("not a record\n")
+	f.Close()
+
+	auditLog, err = readAuditLog(path, 0)
+	if err != nil {
+		t.Fatalf("readAuditLog() failed: %v", err)
+	}
+	if len(auditLog.GetRecords()) != 3 {
+		t.Fatalf("got %d records, want 3", len(auditLog.GetRecords()))
+	}
+	auditLog, err = readAuditLog(path, 2)
+	if err != nil {
+		t.Fatalf("readAuditLog() failed: %v", err)
+	}
+	if len(auditLog.GetRecords()) != 2 {
+		t.Fatalf("got %d records, want 2", len(auditLog.GetRecords()))
+	}
+	if got := auditLog.GetRecords()[0].GetAction(); got != "start" {
+		t.Errorf("action of first record = %q, want %q", got, "start")
+	}
+	if got := auditLog.GetRecords()[1].GetSource(); got != AuditSourceRPC {
+		t.Errorf("source of last record = %q, want %q", got, AuditSourceRPC)
+	}
+}
@@ -17,8 +17,11 @@ package appctl
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net"
 	"os"
 	"path/filepath"
@@ -28,15 +31,29 @@ import (
 	"sync/atomic"
 
 	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/event"
+	"github.com/enfein/mieru/pkg/http2socks"
 	"github.com/enfein/mieru/pkg/log"
 	"github.com/enfein/mieru/pkg/metrics"
 	"github.com/enfein/mieru/pkg/protocolv2"
 	"github.com/enfein/mieru/pkg/socks5"
 	"github.com/enfein/mieru/pkg/stderror"
+	"github.com/enfein/mieru/pkg/util"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
 )
 
+const (
+	// httpProxyCertificateFileName is the file name of the self-signed
+	// HTTP proxy certificate in the client config directory.
+	httpProxyCertificateFileName = "http_proxy.crt"
+
+	// httpProxyPrivateKeyFileName is the file name of the private key of
+	// the self-signed HTTP proxy certificate in the client config directory.
+	httpProxyPrivateKeyFileName = "http_proxy.key"
+)
+
 var (
 	// ClientRPCServerStarted is closed when client RPC server is started.
 	ClientRPCServerStarted chan struct{} = make(chan struct{})
@@ -65,6 +82,16 @@ var (
 	// clientSocks5ServerRef holds a pointer to client socks5 server.
 	clientSocks5ServerRef atomic.Pointer[socks5.Server]
 
+	// clientProfileSocks5Servers holds the socks5 servers of profile listeners.
+	clientProfileSocks5Servers struct {
+		mu      sync.Mutex
+		servers []*socks5.Server
+	}
+
+	// clientDestinationStatsRef holds a pointer to the traffic of
+	// destinations. It is nil if top destinations is disabled.
+	clientDestinationStatsRef atomic.Pointer[socks5.DestinationStats]
+
 	// clientMuxRef holds a pointer to client multiplexier.
 	clientMuxRef atomic.Pointer[protocolv2.Mux]
 )
@@ -77,6 +104,18 @@ func SetClientSocks5ServerRef(server *socks5.Server) {
 	clientSocks5ServerRef.Store(server)
 }
 
+// AddClientProfileSocks5Server adds the socks5 server of a profile listener,
+// which is stopped together with the client socks5 server.
+func AddClientProfileSocks5Server(server *socks5.Server) {
+	clientProfileSocks5Servers.mu.Lock()
+	defer clientProfileSocks5Servers.mu.Unlock()
+	clientProfileSocks5Servers.servers = append(clientProfileSocks5Servers.servers, server)
+}
+
+func SetClientDestinationStatsRef(stats *socks5.DestinationStats) {
+	clientDestinationStatsRef.Store(stats)
+}
+
 func SetClientMuxRef(mux *protocolv2.Mux) {
 	clientMuxRef.Store(mux)
 }
@@ -89,7 +128,15 @@ type clientLifecycleService struct {
 func (c *clientLifecycleService) GetStatus(ctx context.Context, req *pb.Empty) (*pb.AppStatusMsg, error) {
 	status := GetAppStatus()
 	log.Infof("return app status %s back to RPC caller", status.String())
-	return &pb.AppStatusMsg{Status: &status}, nil
+	res := &pb.AppStatusMsg{Status: &status}
+	if mux := clientMuxRef.Load(); mux != nil && mux.UDPFallbackActive() {
+		res.Notes = append(res.Notes, "UDP traffic to proxy server is blocked, UDP endpoints fall back to TCP")
+	}
+	if offset := cipher.ClockOffset(); offset != 0 {
+		res.Notes = append(res.Notes, fmt.Sprintf("local clock differs from proxy server by %v, time of proxy server is used", offset))
+	}
+	res.ServerMessages = recentServerMessages()
+	return res, nil
 }
 
 func (c *clientLifecycleService) Exit(ctx context.Context, req *pb.Empty) (*pb.Empty, error) {
@@ -97,6 +144,7 @@ func (c *clientLifecycleService) Exit(ctx context.Context, req *pb.Empty) (*pb.E
 	log.Infof("received exit request from RPC caller")
 	socks5Server := clientSocks5ServerRef.Load()
 	if socks5Server != nil {
+		drainSocks5Server(socks5Server, clientDrainTimeout())
 		log.Infof("stopping socks5 server")
 		if err := socks5Server.Close(); err != nil {
 			log.Infof("socks5 server Close() failed: %v", err)
@@ -104,6 +152,18 @@ func (c *clientLifecycleService) Exit(ctx context.Context, req *pb.Empty) (*pb.E
 	} else {
 		log.Infof("socks5 server reference not found")
 	}
+	clientProfileSocks5Servers.mu.Lock()
+	for _, server := range clientProfileSocks5Servers.servers {
+		drainSocks5Server(server, clientDrainTimeout())
+		if err := server.Close(); err != nil {
+			log.Infof("socks5 server of profile listener Close() failed: %v", err)
+		}
+	}
+	clientProfileSocks5Servers.servers = nil
+	clientProfileSocks5Servers.mu.Unlock()
+	metrics.StopPersistence()
+	event.Emit(event.DaemonStop, "", nil, "mieru client daemon is exiting")
+	RecordClientAudit(AuditSourceRPC, "stop", "")
 	grpcServer := clientRPCServerRef.Load()
 	if grpcServer != nil {
 		log.Infof("stopping RPC server")
@@ -123,12 +183,56 @@ func (c *clientLifecycleService) GetMetrics(ctx context.Context, req *pb.Empty)
 	return &pb.Metrics{Json: proto.String(string(b))}, nil
 }
 
+func (c *clientLifecycleService) GetMetricsHistory(ctx context.Context, req *pb.GetMetricsHistoryRequest) (*pb.MetricsHistory, error) {
+	return metricsHistory(req), nil
+}
+
+func (c *clientLifecycleService) WatchMetrics(req *pb.WatchMetricsRequest, stream pb.ClientLifecycleService_WatchMetricsServer) error {
+	return watchMetrics(stream.Context(), req, stream.Send)
+}
+
+func (c *clientLifecycleService) GetTopDestinations(ctx context.Context, req *pb.GetTopDestinationsRequest) (*pb.TopDestinations, error) {
+	stats := clientDestinationStatsRef.Load()
+	if stats == nil {
+		return &pb.TopDestinations{}, fmt.Errorf("top destinations is disabled, set topDestinations to true in client config to enable it")
+	}
+	res := &pb.TopDestinations{}
+	for _, d := range stats.Top(int(req.GetLimit())) {
+		res.Destinations = append(res.Destinations, &pb.DestinationEntry{
+			Destination:       proto.String(d.Destination),
+			Connections:       proto.Int64(d.Connections),
+			BytesSent:         proto.Int64(d.BytesSent),
+			BytesReceived:     proto.Int64(d.BytesReceived),
+			LastSeenUnixMilli: proto.Int64(d.LastSeen.UnixMilli()),
+		})
+	}
+	return res, nil
+}
+
 func (c *clientLifecycleService) GetSessionInfo(context.Context, *pb.Empty) (*pb.SessionInfo, error) {
 	mux := clientMuxRef.Load()
 	if mux == nil {
 		return &pb.SessionInfo{}, fmt.Errorf("client multiplexier is unavailable")
 	}
-	return &pb.SessionInfo{Table: mux.ExportSessionInfoTable()}, nil
+	return sessionInfo(mux), nil
+}
+
+func (c *clientLifecycleService) WatchSessionInfo(req *pb.Empty, stream pb.ClientLifecycleService_WatchSessionInfoServer) error {
+	return watchSessionInfo(stream.Context(), clientMuxRef.Load(), stream.Send)
+}
+
+func (c *clientLifecycleService) GetLogs(req *pb.GetLogsRequest, stream pb.ClientLifecycleService_GetLogsServer) error {
+	return streamLogs(stream.Context(), req, stream.Send)
+}
+
+func (c *clientLifecycleService) SetLoggingLevel(ctx context.Context, req *pb.SetLoggingLevelRequest) (*pb.Empty, error) {
+	level := setLoggingLevel(req)
+	RecordClientAudit(AuditSourceRPC, "set log-level", fmt.Sprintf("loggingLevel changed to %s", level.String()))
+	return &pb.Empty{}, nil
+}
+
+func (c *clientLifecycleService) GetApiVersion(ctx context.Context, req *pb.Empty) (*pb.ApiVersion, error) {
+	return currentAPIVersion(), nil
 }
 
 func (c *clientLifecycleService) GetThreadDump(ctx context.Context, req *pb.Empty) (*pb.ThreadDump, error) {
@@ -150,6 +254,44 @@ func (c *clientLifecycleService) GetHeapProfile(ctx context.Context, req *pb.Pro
 	return &pb.Empty{}, err
 }
 
+func (c *clientLifecycleService) GetServerMessages(ctx context.Context, req *pb.Empty) (*pb.ServerMessageList, error) {
+	return &pb.ServerMessageList{Messages: recentServerMessages()}, nil
+}
+
+func (c *clientLifecycleService) SetSessionTap(ctx context.Context, req *pb.SessionTap) (*pb.Empty, error) {
+	mux := clientMuxRef.Load()
+	if mux == nil {
+		return &pb.Empty{}, fmt.Errorf("client multiplexier is unavailable")
+	}
+	return &pb.Empty{}, mux.SetSessionTap(req.GetSessionID(), req.GetAddress())
+}
+
+func (c *clientLifecycleService) UpdateSubscriptions(ctx context.Context, req *pb.Empty) (*pb.UpdateSubscriptionsResult, error) {
+	oldConfig, _ := LoadClientConfig()
+	updated, err := UpdateSubscriptions(ctx)
+	if err != nil {
+		return &pb.UpdateSubscriptionsResult{}, err
+	}
+	if len(updated) > 0 {
+		if newConfig, err := LoadClientConfig(); err == nil {
+			RecordClientAudit(AuditSourceRPC, "update subscriptions", ConfigDiffSummary(oldConfig, newConfig))
+		}
+	}
+	return &pb.UpdateSubscriptionsResult{UpdatedProfiles: updated}, nil
+}
+
+// recentServerMessages returns the messages recently received from proxy servers.
+func recentServerMessages() []*pb.ServerMessage {
+	var res []*pb.ServerMessage
+	for _, msg := range protocolv2.RecentServerMessages() {
+		res = append(res, &pb.ServerMessage{
+			Text:                 proto.String(msg.Text),
+			ReceiveTimeUnixMilli: proto.Int64(msg.ReceiveTime.UnixMilli()),
+		})
+	}
+	return res
+}
+
 // NewClientLifecycleService creates a new ClientLifecycleService RPC server.
 func NewClientLifecycleService() *clientLifecycleService {
 	return &clientLifecycleService{}
@@ -158,23 +300,41 @@ func NewClientLifecycleService() *clientLifecycleService {
 // NewClientLifecycleRPCClient creates a new ClientLifecycleService RPC client.
 // It loads client config to find the server address.
 func NewClientLifecycleRPCClient(ctx context.Context) (pb.ClientLifecycleServiceClient, error) {
-	config, err := LoadClientConfig()
+	conn, err := NewClientLifecycleRPCConn(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("LoadClientConfig() failed: %w", err)
+		return nil, err
+	}
+	return pb.NewClientLifecycleServiceClient(conn), nil
+}
+
+// NewClientLifecycleRPCConn creates a new connection to the
+// ClientLifecycleService RPC server. It loads client config to find
+// the server address.
+func NewClientLifecycleRPCConn(ctx context.Context) (*grpc.ClientConn, error) {
+	config, err := LoadActiveClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("LoadActiveClientConfig() failed: %w", err)
 	}
 	if proto.Equal(config, &pb.ClientConfig{}) {
 		return nil, fmt.Errorf(stderror.ClientConfigIsEmpty)
 	}
+	if socketPath := config.GetRpcSocketPath(); socketPath != "" {
+		dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		}
+		return newClientLifecycleRPCConn(ctx, "passthrough:///localhost", grpc.WithContextDialer(dialer))
+	}
 	if config.GetRpcPort() < 1 || config.GetRpcPort() > 65535 {
 		return nil, fmt.Errorf("RPC port number %d is invalid", config.GetRpcPort())
 	}
 	rpcAddr := "localhost:" + strconv.Itoa(int(config.GetRpcPort()))
-	return newClientLifecycleRPCClient(ctx, rpcAddr)
+	return newClientLifecycleRPCConn(ctx, rpcAddr)
 }
 
 // IsClientDaemonRunning detects if client daemon is running by using ClientLifecycleService.GetStatus() RPC.
 func IsClientDaemonRunning(ctx context.Context) error {
-	timedctx, cancelFunc := context.WithTimeout(ctx, RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(ctx, GetRPCTimeout(StatusRPCTimeout))
 	defer cancelFunc()
 	client, err := NewClientLifecycleRPCClient(timedctx)
 	if err != nil {
@@ -212,55 +372,109 @@ func GetURLClientConfig() (string, error) {
 	return u, nil
 }
 
+// GetScopedURLClientConfig returns a URL that only contains the selected
+// profiles and servers of the client config, so it can be shared without
+// leaking the other profiles.
+//
+// A profile is selected if its name is in profileNames, or profileNames
+// is empty. A server is selected if its domain name or IP address is in
+// serverNames, or serverNames is empty. Profiles without a selected server
+// are removed.
+func GetScopedURLClientConfig(profileNames, serverNames []string) (string, error) {
+	config, err := LoadClientConfig()
+	if err != nil {
+		return "", fmt.Errorf("LoadClientConfig() failed: %w", err)
+	}
+	scoped, err := scopeClientConfig(config, profileNames, serverNames)
+	if err != nil {
+		return "", err
+	}
+	u, err := ClientConfigToURL(scoped)
+	if err != nil {
+		return "", fmt.Errorf("ClientConfigToURL() failed: %w", err)
+	}
+	return u, nil
+}
+
 // LoadClientConfig reads client config from disk.
 func LoadClientConfig() (*pb.ClientConfig, error) {
+	return loadClientConfig(false)
+}
+
+// LoadActiveClientConfig reads client config from disk.
+// The returned config only contains the active profile and the profiles
+// used by profile listeners. It is faster than LoadClientConfig when
+// there are many profiles.
+func LoadActiveClientConfig() (*pb.ClientConfig, error) {
+	return loadClientConfig(true)
+}
+
+// StoreClientConfig writes client config to disk.
+// If the stored client config is encrypted, it stays encrypted.
+func StoreClientConfig(config *pb.ClientConfig) error {
 	clientIOLock.Lock()
 	defer clientIOLock.Unlock()
 
-	fileName, fileType, err := clientConfigFilePath()
+	fileName, _, err := clientConfigFilePath()
 	if err != nil {
-		return nil, fmt.Errorf("clientConfigFilePath() failed: %w", err)
-	}
-	if err := prepareClientConfigDir(); err != nil {
-		return nil, fmt.Errorf("prepareClientConfigDir() failed: %w", err)
+		return fmt.Errorf("clientConfigFilePath() failed: %w", err)
 	}
-
-	log.Debugf("loading client config from %q", fileName)
-	f, err := os.Open(fileName)
-	if err != nil && os.IsNotExist(err) {
-		return nil, stderror.ErrFileNotExist
-	} else if err != nil {
-		return nil, fmt.Errorf("os.Open() failed: %w", err)
+	encrypt, err := isConfigFileEncrypted(fileName)
+	if err != nil {
+		return fmt.Errorf("isConfigFileEncrypted() failed: %w", err)
 	}
-	defer f.Close()
+	return storeClientConfig(config, encrypt)
+}
 
-	b, err := io.ReadAll(f)
+// SetClientConfigEncryption encrypts or decrypts the stored client config.
+// The encryption key is kept in the credential store of the operating system.
+func SetClientConfigEncryption(encrypt bool) error {
+	config, err := LoadClientConfig()
 	if err != nil {
-		return nil, fmt.Errorf("io.ReadAll() failed: %w", err)
+		return fmt.Errorf("LoadClientConfig() failed: %w", err)
 	}
 
-	c := &pb.ClientConfig{}
-	switch fileType {
-	case PROTOBUF_CONFIG_FILE_TYPE:
-		if err := proto.Unmarshal(b, c); err != nil {
-			return nil, fmt.Errorf("proto.Unmarshal() failed: %w", err)
-		}
-	case JSON_CONFIG_FILE_TYPE:
-		if err := Unmarshal(b, c); err != nil {
-			return nil, fmt.Errorf("Unmarshal() failed: %w", err)
-		}
-	default:
-		return nil, fmt.Errorf("config file type is invalid")
+	clientIOLock.Lock()
+	defer clientIOLock.Unlock()
+	if _, fileType, err := clientConfigFilePath(); err != nil {
+		return fmt.Errorf("clientConfigFilePath() failed: %w", err)
+	} else if fileType != PROTOBUF_CONFIG_FILE_TYPE {
+		return fmt.Errorf("encryption is only supported by client config in protobuf format")
 	}
-
-	return c, nil
+	return storeClientConfig(config, encrypt)
 }
 
-// StoreClientConfig writes client config to disk.
-func StoreClientConfig(config *pb.ClientConfig) error {
+// updateClientConfig loads client config from disk, calls update to modify
+// it, and stores it, without releasing clientIOLock in between. The config
+// is not stored if update returns false. It returns the stored config, or
+// nil if the config is not stored.
+func updateClientConfig(update func(config *pb.ClientConfig) bool) (*pb.ClientConfig, error) {
 	clientIOLock.Lock()
 	defer clientIOLock.Unlock()
 
+	config, err := readClientConfig(false)
+	if err != nil {
+		return nil, fmt.Errorf("readClientConfig() failed: %w", err)
+	}
+	if !update(config) {
+		return nil, nil
+	}
+	fileName, _, err := clientConfigFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("clientConfigFilePath() failed: %w", err)
+	}
+	encrypt, err := isConfigFileEncrypted(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("isConfigFileEncrypted() failed: %w", err)
+	}
+	if err := storeClientConfig(config, encrypt); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// storeClientConfig writes client config to disk. Caller must hold clientIOLock.
+func storeClientConfig(config *pb.ClientConfig, encrypt bool) error {
 	fileName, fileType, err := clientConfigFilePath()
 	if err != nil {
 		return fmt.Errorf("clientConfigFilePath() failed: %w", err)
@@ -276,30 +490,46 @@ func StoreClientConfig(config *pb.ClientConfig) error {
 	var b []byte
 	switch fileType {
 	case PROTOBUF_CONFIG_FILE_TYPE:
-		if b, err = proto.Marshal(config); err != nil {
+		// Store new profiles first, so the config file never misses a profile.
+		profileDir := clientProfileDir(fileName)
+		if err := storeClientProfiles(profileDir, config.GetProfiles(), encrypt); err != nil {
+			return fmt.Errorf("storeClientProfiles() failed: %w", err)
+		}
+		withoutProfiles := proto.Clone(config).(*pb.ClientConfig)
+		withoutProfiles.Profiles = nil
+		if b, err = proto.Marshal(withoutProfiles); err != nil {
 			return fmt.Errorf("proto.Marshal() failed: %w", err)
 		}
+		if encrypt {
+			if b, err = encryptConfig(b); err != nil {
+				return fmt.Errorf("encryptConfig() failed: %w", err)
+			}
+		}
+		if err = writeFileAtomic(fileName, b, 0660); err != nil {
+			return fmt.Errorf("writeFileAtomic() failed: %w", err)
+		}
+		if err := deleteStaleClientProfiles(profileDir, config.GetProfiles()); err != nil {
+			return fmt.Errorf("deleteStaleClientProfiles() failed: %w", err)
+		}
 	case JSON_CONFIG_FILE_TYPE:
 		if b, err = Marshal(config); err != nil {
 			return fmt.Errorf("Marshal() failed: %w", err)
 		}
+		if err = writeFileAtomic(fileName, b, 0660); err != nil {
+			return fmt.Errorf("writeFileAtomic() failed: %w", err)
+		}
 	default:
 		return fmt.Errorf("config file type is invalid")
 	}
 
-	err = os.WriteFile(fileName, b, 0660)
-	if err != nil {
-		return fmt.Errorf("os.WriteFile(%q) failed: %w", fileName, err)
-	}
-
 	return nil
 }
 
-// ApplyJSONClientConfig applies user provided JSON client config from the given file.
+// ApplyJSONClientConfig applies user provided JSON or YAML client config from the given file.
 func ApplyJSONClientConfig(path string) error {
-	b, err := os.ReadFile(path)
+	b, err := ReadConfigFileAsJSON(path)
 	if err != nil {
-		return fmt.Errorf("os.ReadFile(%q) failed: %w", path, err)
+		return err
 	}
 	c := &pb.ClientConfig{}
 	if err = jsonUnmarshalOption.Unmarshal(b, c); err != nil {
@@ -327,6 +557,11 @@ func DeleteClientConfigProfile(profileName string) error {
 	if config.GetActiveProfile() == profileName {
 		return fmt.Errorf("activeProfile %q can't be deleted", profileName)
 	}
+	for _, listener := range config.GetProfileListeners() {
+		if listener.GetProfileName() == profileName {
+			return fmt.Errorf("profile %q used by profile listener can't be deleted", profileName)
+		}
+	}
 	profiles := config.GetProfiles()
 	updated := make([]*pb.ClientProfile, 0)
 	for _, profile := range profiles {
@@ -349,13 +584,29 @@ func DeleteClientConfigProfile(profileName string) error {
 // 2. for each profile
 // 2.1. profile name is not empty
 // 2.2. user name is not empty
-// 2.3. user has either a password or a hashed password
-// 2.4. user has no quota
-// 2.5. it has at least 1 server, and for each server
+// 2.3. user has either a password or a hashed password, which is not redacted
+// 2.4. user has no quota and no access window
+// 2.5. it has at least 1 server unless subscription is set, and for each server
 // 2.5.1. the server has either IP address or domain name
 // 2.5.2. if set, server's IP address is parsable
-// 2.5.3. the server has at least 1 port binding, and all port bindings are valid
+// 2.5.3. the server has at least 1 port binding or port rotation, and all port bindings and port rotation are valid
 // 2.6. if set, MTU is valid
+// 2.7. if set, subscription URL is a HTTPS URL and refresh interval is not negative
+// 2.8. IPv6 source address preference is valid
+// 3. for each domain rule list, file path is set and action is valid
+// 4. each DNS upstream is a valid URL
+// 5. HTTP proxy certificate file and private key file are set together
+// 6. if set, retransmission parameters are valid
+// 7. if set, flow control window sizes are valid
+// 8. priority rules are valid
+// 9. drain timeout is valid
+// 10. if set, dead peer timeout is valid
+// 11. timing jitter is valid
+// 12. if set, underlay timeouts are valid
+// 13. if set, socks5 Unix socket path is an absolute path
+// 14. if set, DNS leak protection upstream is a valid address
+// 15. application rules of transparent proxy are valid
+// 16. if set, RPC socket path is an absolute path
 func ValidateClientConfigPatch(patch *pb.ClientConfig) error {
 	for _, profile := range patch.GetProfiles() {
 		name := profile.GetProfileName()
@@ -369,11 +620,20 @@ func ValidateClientConfigPatch(patch *pb.ClientConfig) error {
 		if user.GetPassword() == "" && user.GetHashedPassword() == "" {
 			return fmt.Errorf("user password is not set")
 		}
+		if isRedactedSecret(user.GetPassword()) || isRedactedSecret(user.GetHashedPassword()) {
+			return fmt.Errorf("password of user %q is redacted, use describe config --show-secrets to get the password", user.GetName())
+		}
 		if len(user.GetQuotas()) != 0 {
 			return fmt.Errorf("user quota is not supported by proxy client")
 		}
+		if len(user.GetAccessWindows()) != 0 {
+			return fmt.Errorf("user access window is not supported by proxy client")
+		}
+		if user.RateLimit != nil {
+			return fmt.Errorf("user rate limit is not supported by proxy client")
+		}
 		servers := profile.GetServers()
-		if len(servers) == 0 {
+		if len(servers) == 0 && profile.Subscription == nil {
 			return fmt.Errorf("servers are not set")
 		}
 		for _, server := range servers {
@@ -384,16 +644,97 @@ func ValidateClientConfigPatch(patch *pb.ClientConfig) error {
 				return fmt.Errorf("failed to parse IP address %q", server.GetIpAddress())
 			}
 			portBindings := server.GetPortBindings()
-			if len(portBindings) == 0 {
+			if len(portBindings) == 0 && server.PortRotation == nil {
 				return fmt.Errorf("server port binding is not set")
 			}
 			if _, err := FlatPortBindings(portBindings); err != nil {
 				return err
 			}
+			if err := validatePortRotation(server.GetPortRotation()); err != nil {
+				return err
+			}
 		}
 		if profile.GetMtu() != 0 && (profile.GetMtu() < 1280 || profile.GetMtu() > 1500) {
 			return fmt.Errorf("MTU value %d is out of range, valid range is [1280, 1500]", profile.GetMtu())
 		}
+		if profile.Subscription != nil {
+			if err := validateSubscription(profile.GetSubscription()); err != nil {
+				return err
+			}
+		}
+		if err := validateIPv6SourceAddress(profile.GetIpv6SourceAddress()); err != nil {
+			return err
+		}
+	}
+	for _, list := range patch.GetDomainRuleLists() {
+		if list.GetFilePath() == "" {
+			return fmt.Errorf("domain rule list file path is not set")
+		}
+		if _, ok := pb.EgressAction_name[int32(list.GetAction())]; !ok {
+			return fmt.Errorf("domain rule list %q has invalid action %d", list.GetFilePath(), list.GetAction())
+		}
+	}
+	for _, upstream := range patch.GetDnsUpstreams() {
+		if _, err := util.NewDNSUpstream(upstream); err != nil {
+			return fmt.Errorf("invalid DNS upstream: %w", err)
+		}
+	}
+	if patch.HttpProxyTLS != nil {
+		tlsConfig := patch.GetHttpProxyTLS()
+		if (tlsConfig.GetCertificateFile() == "") != (tlsConfig.GetPrivateKeyFile() == "") {
+			return fmt.Errorf("HTTP proxy certificate file and private key file must be set together")
+		}
+	}
+	if err := validateRetransmissionSettings(patch.GetAdvancedSettings().GetRetransmission()); err != nil {
+		return err
+	}
+	if err := validateFlowControlSettings(patch.GetAdvancedSettings().GetFlowControl()); err != nil {
+		return err
+	}
+	if _, err := socks5.NewPriorityRules(patch.GetAdvancedSettings().GetPriorityRules()); err != nil {
+		return err
+	}
+	if err := validateDrainTimeout(patch.GetAdvancedSettings().GetDrainTimeoutSeconds()); err != nil {
+		return err
+	}
+	if err := validateDeadPeerTimeout(patch.GetAdvancedSettings().GetDeadPeerTimeoutSeconds()); err != nil {
+		return err
+	}
+	if err := validateTimingJitter(patch.GetAdvancedSettings().GetTimingJitterMillis()); err != nil {
+		return err
+	}
+	if err := validateUnderlayTimeouts(patch.GetAdvancedSettings().GetUnderlayReadTimeoutSeconds(), patch.GetAdvancedSettings().GetStuckUnderlayTimeoutSeconds()); err != nil {
+		return err
+	}
+	if patch.Socks5UnixSocketPath != nil && !filepath.IsAbs(patch.GetSocks5UnixSocketPath()) {
+		return fmt.Errorf("socks5 Unix socket path %q is not an absolute path", patch.GetSocks5UnixSocketPath())
+	}
+	if patch.RpcSocketPath != nil && !filepath.IsAbs(patch.GetRpcSocketPath()) {
+		return fmt.Errorf("RPC socket path %q is not an absolute path", patch.GetRpcSocketPath())
+	}
+	if _, err := socks5.NewApplicationRules(patch.GetTransparentProxy().GetApplicationRules()); err != nil {
+		return err
+	}
+	if upstream := patch.GetDnsLeakProtection().GetUpstream(); upstream != "" {
+		host, port, err := net.SplitHostPort(upstream)
+		if err != nil {
+			return fmt.Errorf("DNS leak protection upstream %q is invalid: %w", upstream, err)
+		}
+		if portNum, err := strconv.Atoi(port); err != nil || host == "" || portNum < 1 || portNum > 65535 {
+			return fmt.Errorf("DNS leak protection upstream %q is invalid", upstream)
+		}
+	}
+	if err := validateStatsdExport(patch.GetStatsd()); err != nil {
+		return err
+	}
+	if err := validateTracingExport(patch.GetTracing()); err != nil {
+		return err
+	}
+	if err := validateWebhookExport(patch.GetWebhook()); err != nil {
+		return err
+	}
+	if err := validateLogShipping(patch.GetLogShipping()); err != nil {
+		return err
 	}
 	return nil
 }
@@ -406,6 +747,13 @@ func ValidateClientConfigPatch(patch *pb.ClientConfig) error {
 // 3. RPC port is valid
 // 4. socks5 port is valid
 // 5. RPC port, socks5 port, http proxy port are different
+// 6. if HTTP proxy TLS is enabled, http proxy port is set
+// 7. if set, transparent proxy port is valid and different from other ports
+// 8. profile listeners use existing profiles, and their ports are valid and different from other ports
+// 9. if set, DNS leak protection port is valid and different from other ports, and remote DNS resolution is not disabled
+// 10. if set, RPC socket path is different from socks5 Unix socket path
+// 11. if set, web dashboard port is valid and different from other ports, and RPC is enabled
+// 12. if set, REST API port is valid and different from other ports, and RPC is enabled
 func ValidateFullClientConfig(config *pb.ClientConfig) error {
 	if err := ValidateClientConfigPatch(config); err != nil {
 		return err
@@ -447,6 +795,158 @@ func ValidateFullClientConfig(config *pb.ClientConfig) error {
 			return fmt.Errorf("HTTP proxy port number %d is the same as socks5 port number", config.GetHttpProxyPort())
 		}
 	}
+	if config.GetHttpProxyTLS().GetEnable() && config.HttpProxyPort == nil {
+		return fmt.Errorf("HTTP proxy TLS is enabled but HTTP proxy port is not set")
+	}
+	if config.GetTransparentProxy() != nil {
+		port := config.GetTransparentProxy().GetPort()
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("transparent proxy port number %d is invalid", port)
+		}
+		if port == config.GetRpcPort() {
+			return fmt.Errorf("transparent proxy port number %d is the same as RPC port number", port)
+		}
+		if port == config.GetSocks5Port() {
+			return fmt.Errorf("transparent proxy port number %d is the same as socks5 port number", port)
+		}
+		if port == config.GetHttpProxyPort() {
+			return fmt.Errorf("transparent proxy port number %d is the same as HTTP proxy port number", port)
+		}
+	}
+	if config.GetDnsLeakProtection() != nil {
+		port := config.GetDnsLeakProtection().GetPort()
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("DNS leak protection port number %d is invalid", port)
+		}
+		if port == config.GetRpcPort() {
+			return fmt.Errorf("DNS leak protection port number %d is the same as RPC port number", port)
+		}
+		if port == config.GetSocks5Port() {
+			return fmt.Errorf("DNS leak protection port number %d is the same as socks5 port number", port)
+		}
+		if port == config.GetHttpProxyPort() {
+			return fmt.Errorf("DNS leak protection port number %d is the same as HTTP proxy port number", port)
+		}
+		if port == config.GetTransparentProxy().GetPort() {
+			return fmt.Errorf("DNS leak protection port number %d is the same as transparent proxy port number", port)
+		}
+		if config.RemoteDNSResolution != nil && !config.GetRemoteDNSResolution() {
+			return fmt.Errorf("remote DNS resolution can't be disabled when DNS leak protection is set")
+		}
+	}
+	if config.GetRpcSocketPath() != "" && config.GetRpcSocketPath() == config.GetSocks5UnixSocketPath() {
+		return fmt.Errorf("RPC socket path %q is the same as socks5 Unix socket path", config.GetRpcSocketPath())
+	}
+	if config.WebDashboardPort != nil {
+		port := config.GetWebDashboardPort()
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("web dashboard port number %d is invalid", port)
+		}
+		if config.GetRpcPort() == 0 && config.GetRpcSocketPath() == "" {
+			return fmt.Errorf("web dashboard requires RPC port or RPC socket path")
+		}
+		if port == config.GetRpcPort() {
+			return fmt.Errorf("web dashboard port number %d is the same as RPC port number", port)
+		}
+		if port == config.GetSocks5Port() {
+			return fmt.Errorf("web dashboard port number %d is the same as socks5 port number", port)
+		}
+		if port == config.GetHttpProxyPort() {
+			return fmt.Errorf("web dashboard port number %d is the same as HTTP proxy port number", port)
+		}
+		if port == config.GetTransparentProxy().GetPort() {
+			return fmt.Errorf("web dashboard port number %d is the same as transparent proxy port number", port)
+		}
+		if port == config.GetDnsLeakProtection().GetPort() {
+			return fmt.Errorf("web dashboard port number %d is the same as DNS leak protection port number", port)
+		}
+	}
+	if config.RestApiPort != nil {
+		port := config.GetRestApiPort()
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("REST API port number %d is invalid", port)
+		}
+		if config.GetRpcPort() == 0 && config.GetRpcSocketPath() == "" {
+			return fmt.Errorf("REST API requires RPC port or RPC socket path")
+		}
+		if port == config.GetRpcPort() {
+			return fmt.Errorf("REST API port number %d is the same as RPC port number", port)
+		}
+		if port == config.GetSocks5Port() {
+			return fmt.Errorf("REST API port number %d is the same as socks5 port number", port)
+		}
+		if port == config.GetHttpProxyPort() {
+			return fmt.Errorf("REST API port number %d is the same as HTTP proxy port number", port)
+		}
+		if port == config.GetTransparentProxy().GetPort() {
+			return fmt.Errorf("REST API port number %d is the same as transparent proxy port number", port)
+		}
+		if port == config.GetDnsLeakProtection().GetPort() {
+			return fmt.Errorf("REST API port number %d is the same as DNS leak protection port number", port)
+		}
+		if port == config.GetWebDashboardPort() {
+			return fmt.Errorf("REST API port number %d is the same as web dashboard port number", port)
+		}
+	}
+	if err := validateProfileListeners(config); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateProfileListeners checks the profile listeners of the full client config.
+func validateProfileListeners(config *pb.ClientConfig) error {
+	if len(config.GetProfileListeners()) == 0 {
+		return nil
+	}
+	usedPorts := map[int32]string{
+		config.GetSocks5Port(): "socks5 port",
+	}
+	if config.GetRpcPort() != 0 {
+		usedPorts[config.GetRpcPort()] = "RPC port"
+	}
+	if config.HttpProxyPort != nil {
+		usedPorts[config.GetHttpProxyPort()] = "HTTP proxy port"
+	}
+	if config.GetTransparentProxy() != nil {
+		usedPorts[config.GetTransparentProxy().GetPort()] = "transparent proxy port"
+	}
+	if config.GetDnsLeakProtection() != nil {
+		usedPorts[config.GetDnsLeakProtection().GetPort()] = "DNS leak protection port"
+	}
+	if config.WebDashboardPort != nil {
+		usedPorts[config.GetWebDashboardPort()] = "web dashboard port"
+	}
+	if config.RestApiPort != nil {
+		usedPorts[config.GetRestApiPort()] = "REST API port"
+	}
+	checkPort := func(port int32, name string) error {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("%s number %d is invalid", name, port)
+		}
+		if used, ok := usedPorts[port]; ok {
+			return fmt.Errorf("%s number %d is the same as %s number", name, port, used)
+		}
+		usedPorts[port] = name
+		return nil
+	}
+	for _, listener := range config.GetProfileListeners() {
+		name := listener.GetProfileName()
+		if name == "" {
+			return fmt.Errorf("profile listener: profile name is not set")
+		}
+		if _, err := GetActiveProfileFromConfig(config, name); err != nil {
+			return fmt.Errorf("profile listener: %w", err)
+		}
+		if err := checkPort(listener.GetSocks5Port(), fmt.Sprintf("profile listener %q socks5 port", name)); err != nil {
+			return err
+		}
+		if listener.HttpProxyPort != nil {
+			if err := checkPort(listener.GetHttpProxyPort(), fmt.Sprintf("profile listener %q HTTP proxy port", name)); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -463,14 +963,70 @@ func GetActiveProfileFromConfig(config *pb.ClientConfig, name string) (*pb.Clien
 	return nil, fmt.Errorf("profile %q is not found", name)
 }
 
-// newClientLifecycleRPCClient creates a new ClientLifecycleService RPC client
-// and connects to the given server address.
-func newClientLifecycleRPCClient(ctx context.Context, serverAddr string) (pb.ClientLifecycleServiceClient, error) {
-	conn, err := grpc.DialContext(ctx, serverAddr, grpc.WithInsecure())
+// LoadHTTPProxyCertificate returns the TLS certificate of HTTP proxy.
+// If the certificate file is not set in the client config, a self-signed
+// certificate is loaded from the client config directory. The self-signed
+// certificate is generated if it doesn't exist.
+func LoadHTTPProxyCertificate(config *pb.ClientConfig) (tls.Certificate, error) {
+	tlsConfig := config.GetHttpProxyTLS()
+	if tlsConfig.GetCertificateFile() != "" {
+		return tls.LoadX509KeyPair(tlsConfig.GetCertificateFile(), tlsConfig.GetPrivateKeyFile())
+	}
+
+	if err := prepareClientConfigDir(); err != nil {
+		return tls.Certificate{}, fmt.Errorf("prepareClientConfigDir() failed: %w", err)
+	}
+	certFile := filepath.Join(cachedClientConfigDir, httpProxyCertificateFileName)
+	keyFile := filepath.Join(cachedClientConfigDir, httpProxyPrivateKeyFileName)
+	if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+		return cert, nil
+	}
+
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if hostname, err := os.Hostname(); err == nil {
+		hosts = append(hosts, hostname)
+	}
+	if config.GetHttpProxyListenLAN() {
+		if addrs, err := net.InterfaceAddrs(); err == nil {
+			for _, addr := range addrs {
+				if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsGlobalUnicast() {
+					hosts = append(hosts, ipNet.IP.String())
+				}
+			}
+		}
+	}
+	certPEM, keyPEM, err := http2socks.GenerateCertificate(hosts)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("GenerateCertificate() failed: %w", err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, fmt.Errorf("os.WriteFile(%q) failed: %w", keyFile, err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return tls.Certificate{}, fmt.Errorf("os.WriteFile(%q) failed: %w", certFile, err)
+	}
+	log.Infof("generated self-signed HTTP proxy certificate %q", certFile)
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// newClientLifecycleRPCConn connects to the ClientLifecycleService RPC server
+// at the given address.
+func newClientLifecycleRPCConn(ctx context.Context, serverAddr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	tokenPath, err := ClientRPCTokenPath()
+	if err != nil {
+		return nil, err
+	}
+	tokenOption, err := rpcTokenDialOption(tokenPath)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithInsecure(), tokenOption)
+	opts = append(opts, apiVersionDialOptions(pb.ClientLifecycleService_GetApiVersion_FullMethodName)...)
+	conn, err := grpc.DialContext(ctx, serverAddr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("grpc.DialContext() failed: %w", err)
 	}
-	return pb.NewClientLifecycleServiceClient(conn), nil
+	return conn, nil
 }
 
 // prepareClientConfigDir creates the client config directory if needed.
@@ -512,6 +1068,116 @@ func clientConfigFilePath() (string, ConfigFileType, error) {
 	return cachedClientConfigFilePath, FindConfigFileType(cachedClientConfigFilePath), nil
 }
 
+// loadClientConfig reads client config from disk.
+// If activeOnly is true, only the active profile and the profiles
+// used by profile listeners are loaded.
+func loadClientConfig(activeOnly bool) (*pb.ClientConfig, error) {
+	clientIOLock.Lock()
+	defer clientIOLock.Unlock()
+	return readClientConfig(activeOnly)
+}
+
+// readClientConfig reads client config from disk. Caller must hold clientIOLock.
+func readClientConfig(activeOnly bool) (*pb.ClientConfig, error) {
+	fileName, fileType, err := clientConfigFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("clientConfigFilePath() failed: %w", err)
+	}
+	if err := prepareClientConfigDir(); err != nil {
+		return nil, fmt.Errorf("prepareClientConfigDir() failed: %w", err)
+	}
+
+	log.Debugf("loading client config from %q", fileName)
+	f, err := os.Open(fileName)
+	if err != nil && os.IsNotExist(err) {
+		return nil, stderror.ErrFileNotExist
+	} else if err != nil {
+		return nil, fmt.Errorf("os.Open() failed: %w", err)
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll() failed: %w", err)
+	}
+	if b, err = decryptConfig(b); err != nil {
+		return nil, fmt.Errorf("decryptConfig() failed: %w", err)
+	}
+
+	c := &pb.ClientConfig{}
+	switch fileType {
+	case PROTOBUF_CONFIG_FILE_TYPE:
+		if err := proto.Unmarshal(b, c); err != nil {
+			return nil, fmt.Errorf("proto.Unmarshal() failed: %w", err)
+		}
+	case JSON_CONFIG_FILE_TYPE:
+		if err := Unmarshal(b, c); err != nil {
+			return nil, fmt.Errorf("Unmarshal() failed: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("config file type is invalid")
+	}
+
+	// Profiles are stored in separate files, unless the config
+	// file is in JSON format or written by an old version.
+	if fileType == PROTOBUF_CONFIG_FILE_TYPE && len(c.GetProfiles()) == 0 {
+		profileDir := clientProfileDir(fileName)
+		if activeOnly {
+			for _, name := range usedProfileNames(c) {
+				profile, err := loadClientProfile(profileDir, name)
+				if err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return nil, fmt.Errorf("loadClientProfile() failed: %w", err)
+				}
+				if profile != nil {
+					c.Profiles = append(c.Profiles, profile)
+				}
+			}
+		} else {
+			c.Profiles, err = loadClientProfiles(profileDir)
+			if err != nil {
+				return nil, fmt.Errorf("loadClientProfiles() failed: %w", err)
+			}
+		}
+	} else if activeOnly {
+		used := make(map[string]bool)
+		for _, name := range usedProfileNames(c) {
+			used[name] = true
+		}
+		profiles := make([]*pb.ClientProfile, 0, len(used))
+		for _, profile := range c.GetProfiles() {
+			if used[profile.GetProfileName()] {
+				profiles = append(profiles, profile)
+			}
+		}
+		c.Profiles = profiles
+	}
+
+	if activeOnly {
+		registerSecrets("active client profile", c)
+	} else {
+		registerSecrets("client config", c)
+	}
+	return c, nil
+}
+
+// usedProfileNames returns the names of the active profile and the
+// profiles used by profile listeners, without duplicates.
+func usedProfileNames(config *pb.ClientConfig) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	add(config.GetActiveProfile())
+	for _, listener := range config.GetProfileListeners() {
+		add(listener.GetProfileName())
+	}
+	return names
+}
+
 func applyClientConfig(c *pb.ClientConfig) error {
 	if err := ValidateClientConfigPatch(c); err != nil {
 		return fmt.Errorf("ValidateClientConfigPatch() failed: %w", err)
@@ -601,6 +1267,74 @@ func mergeClientConfigByProfile(dst, src *pb.ClientConfig) {
 	if src.HttpProxyListenLAN != nil {
 		httpProxyListenLAN = src.HttpProxyListenLAN
 	}
+	domainRuleLists := dst.DomainRuleLists
+	if len(src.DomainRuleLists) != 0 {
+		domainRuleLists = src.DomainRuleLists
+	}
+	var remoteDNSResolution *bool = dst.RemoteDNSResolution
+	if src.RemoteDNSResolution != nil {
+		remoteDNSResolution = src.RemoteDNSResolution
+	}
+	dnsUpstreams := dst.DnsUpstreams
+	if len(src.DnsUpstreams) != 0 {
+		dnsUpstreams = src.DnsUpstreams
+	}
+	var httpProxyTLS *pb.HTTPProxyTLS = dst.HttpProxyTLS
+	if src.HttpProxyTLS != nil {
+		httpProxyTLS = src.HttpProxyTLS
+	}
+	var statsd *pb.StatsdExport = dst.Statsd
+	if src.Statsd != nil {
+		statsd = src.Statsd
+	}
+	var tracing *pb.TracingExport = dst.Tracing
+	if src.Tracing != nil {
+		tracing = src.Tracing
+	}
+	var topDestinations *bool = dst.TopDestinations
+	if src.TopDestinations != nil {
+		topDestinations = src.TopDestinations
+	}
+	var webhook *pb.WebhookExport = dst.Webhook
+	if src.Webhook != nil {
+		webhook = src.Webhook
+	}
+	var logShipping *pb.LogShipping = dst.LogShipping
+	if src.LogShipping != nil {
+		logShipping = src.LogShipping
+	}
+	var transparentProxy *pb.TransparentProxy = dst.TransparentProxy
+	if src.TransparentProxy != nil {
+		transparentProxy = src.TransparentProxy
+	}
+	var socks5UnixSocketPath *string = dst.Socks5UnixSocketPath
+	if src.Socks5UnixSocketPath != nil {
+		socks5UnixSocketPath = src.Socks5UnixSocketPath
+	}
+	profileListeners := dst.ProfileListeners
+	if len(src.ProfileListeners) != 0 {
+		profileListeners = src.ProfileListeners
+	}
+	var systemProxy *bool = dst.SystemProxy
+	if src.SystemProxy != nil {
+		systemProxy = src.SystemProxy
+	}
+	var dnsLeakProtection *pb.DNSLeakProtection = dst.DnsLeakProtection
+	if src.DnsLeakProtection != nil {
+		dnsLeakProtection = src.DnsLeakProtection
+	}
+	var rpcSocketPath *string = dst.RpcSocketPath
+	if src.RpcSocketPath != nil {
+		rpcSocketPath = src.RpcSocketPath
+	}
+	var webDashboardPort *int32 = dst.WebDashboardPort
+	if src.WebDashboardPort != nil {
+		webDashboardPort = src.WebDashboardPort
+	}
+	var restApiPort *int32 = dst.RestApiPort
+	if src.RestApiPort != nil {
+		restApiPort = src.RestApiPort
+	}
 
 	proto.Reset(dst)
 
@@ -614,6 +1348,81 @@ func mergeClientConfigByProfile(dst, src *pb.ClientConfig) {
 	dst.Socks5ListenLAN = socks5ListenLAN
 	dst.HttpProxyPort = httpProxyPort
 	dst.HttpProxyListenLAN = httpProxyListenLAN
+	dst.DomainRuleLists = domainRuleLists
+	dst.RemoteDNSResolution = remoteDNSResolution
+	dst.DnsUpstreams = dnsUpstreams
+	dst.HttpProxyTLS = httpProxyTLS
+	dst.Statsd = statsd
+	dst.Tracing = tracing
+	dst.TopDestinations = topDestinations
+	dst.Webhook = webhook
+	dst.LogShipping = logShipping
+	dst.TransparentProxy = transparentProxy
+	dst.Socks5UnixSocketPath = socks5UnixSocketPath
+	dst.ProfileListeners = profileListeners
+	dst.SystemProxy = systemProxy
+	dst.DnsLeakProtection = dnsLeakProtection
+	dst.RpcSocketPath = rpcSocketPath
+	dst.WebDashboardPort = webDashboardPort
+	dst.RestApiPort = restApiPort
+}
+
+// scopeClientConfig returns a copy of client config that only contains
+// the selected profiles and servers.
+func scopeClientConfig(config *pb.ClientConfig, profileNames, serverNames []string) (*pb.ClientConfig, error) {
+	selected := func(names []string, values ...string) bool {
+		if len(names) == 0 {
+			return true
+		}
+		for _, name := range names {
+			for _, value := range values {
+				if value != "" && name == value {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	scoped := proto.Clone(config).(*pb.ClientConfig)
+	scoped.Profiles = nil
+	for _, profile := range config.GetProfiles() {
+		if !selected(profileNames, profile.GetProfileName()) {
+			continue
+		}
+		p := proto.Clone(profile).(*pb.ClientProfile)
+		p.Servers = nil
+		for _, server := range profile.GetServers() {
+			if selected(serverNames, server.GetDomainName(), server.GetIpAddress()) {
+				p.Servers = append(p.Servers, server)
+			}
+		}
+		if len(p.GetServers()) != 0 {
+			scoped.Profiles = append(scoped.Profiles, p)
+		}
+	}
+	if len(scoped.GetProfiles()) == 0 {
+		return nil, fmt.Errorf("no profile or server matches the selection")
+	}
+	activeFound := false
+	for _, profile := range scoped.GetProfiles() {
+		if profile.GetProfileName() == scoped.GetActiveProfile() {
+			activeFound = true
+			break
+		}
+	}
+	if !activeFound {
+		scoped.ActiveProfile = proto.String(scoped.GetProfiles()[0].GetProfileName())
+	}
+	// Profile listeners of the profiles not selected are removed.
+	scoped.ProfileListeners = nil
+	for _, listener := range config.GetProfileListeners() {
+		if _, err := GetActiveProfileFromConfig(scoped, listener.GetProfileName()); err == nil {
+			scoped.ProfileListeners = append(scoped.ProfileListeners, listener)
+		}
+	}
+	// Domain rule lists refer to local files.
+	scoped.DomainRuleLists = nil
+	return scoped, nil
 }
 
 // deleteClientConfigFile deletes the client config file.
@@ -622,6 +1431,9 @@ func deleteClientConfigFile() error {
 	if err != nil {
 		return fmt.Errorf("clientConfigFilePath() failed: %w", err)
 	}
+	if err = os.RemoveAll(clientProfileDir(path)); err != nil {
+		return err
+	}
 	err = os.Remove(path)
 	if err != nil && os.IsNotExist(err) {
 		return nil
@@ -0,0 +1,192 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Client profiles are stored in a directory next to the protobuf client
+// config file, one file per profile. The client config file itself doesn't
+// store profiles. This way, updating one profile doesn't rewrite the others,
+// and the active profile can be loaded without reading all the profiles.
+//
+// Client config files in JSON format still store profiles in the same file.
+
+const profileFileSuffix = ".pb"
+
+// clientProfileDir returns the directory to store client profiles.
+func clientProfileDir(configFilePath string) string {
+	return configFilePath + ".d"
+}
+
+// clientProfileFileName returns the file name to store a client profile.
+// The profile name is hex encoded so any profile name is a valid file name.
+func clientProfileFileName(profileName string) string {
+	return hex.EncodeToString([]byte(profileName)) + profileFileSuffix
+}
+
+// loadClientProfile reads one client profile from the profile directory.
+func loadClientProfile(dir, profileName string) (*pb.ClientProfile, error) {
+	fileName := filepath.Join(dir, clientProfileFileName(profileName))
+	b, err := readConfigFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("readConfigFile(%q) failed: %w", fileName, err)
+	}
+	profile := &pb.ClientProfile{}
+	if err := proto.Unmarshal(b, profile); err != nil {
+		return nil, fmt.Errorf("proto.Unmarshal() failed: %w", err)
+	}
+	if profile.GetProfileName() != profileName {
+		return nil, fmt.Errorf("profile file %q contains profile %q", fileName, profile.GetProfileName())
+	}
+	return profile, nil
+}
+
+// loadClientProfiles reads all the client profiles from the profile directory.
+// The returned profiles are sorted by profile name.
+func loadClientProfiles(dir string) ([]*pb.ClientProfile, error) {
+	names, err := listClientProfiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]*pb.ClientProfile, 0, len(names))
+	for _, name := range names {
+		profile, err := loadClientProfile(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// listClientProfiles returns the sorted names of client profiles
+// stored in the profile directory.
+func listClientProfiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("os.ReadDir(%q) failed: %w", dir, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), profileFileSuffix) {
+			continue
+		}
+		name, err := hex.DecodeString(strings.TrimSuffix(entry.Name(), profileFileSuffix))
+		if err != nil {
+			// Not a profile file.
+			continue
+		}
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// storeClientProfiles writes client profiles to the profile directory.
+// Profile files are encrypted if encrypt is true.
+// Profile files that are not changed are not rewritten.
+func storeClientProfiles(dir string, profiles []*pb.ClientProfile, encrypt bool) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("os.MkdirAll(%q) failed: %w", dir, err)
+	}
+	for _, profile := range profiles {
+		fileName := filepath.Join(dir, clientProfileFileName(profile.GetProfileName()))
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(profile)
+		if err != nil {
+			return fmt.Errorf("proto.Marshal() failed: %w", err)
+		}
+		if old, err := os.ReadFile(fileName); err == nil && isEncryptedConfig(old) == encrypt {
+			if plaintext, err := decryptConfig(old); err == nil && bytes.Equal(plaintext, b) {
+				continue
+			}
+		}
+		if encrypt {
+			if b, err = encryptConfig(b); err != nil {
+				return fmt.Errorf("encryptConfig() failed: %w", err)
+			}
+		}
+		if err := writeFileAtomic(fileName, b, 0660); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteStaleClientProfiles deletes profile files that don't belong
+// to the given profiles.
+func deleteStaleClientProfiles(dir string, profiles []*pb.ClientProfile) error {
+	names, err := listClientProfiles(dir)
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]struct{})
+	for _, profile := range profiles {
+		keep[profile.GetProfileName()] = struct{}{}
+	}
+	for _, name := range names {
+		if _, found := keep[name]; found {
+			continue
+		}
+		fileName := filepath.Join(dir, clientProfileFileName(name))
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("os.Remove(%q) failed: %w", fileName, err)
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to
+// the named file, so a reader never sees a partially written file.
+func writeFileAtomic(fileName string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp() failed: %w", err)
+	}
+	tmpName := f.Name()
+	defer os.Remove(tmpName)
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("Write() to %q failed: %w", tmpName, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("Sync() %q failed: %w", tmpName, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Close() %q failed: %w", tmpName, err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return fmt.Errorf("os.Chmod(%q) failed: %w", tmpName, err)
+	}
+	if err := os.Rename(tmpName, fileName); err != nil {
+		return fmt.Errorf("os.Rename(%q, %q) failed: %w", tmpName, fileName, err)
+	}
+	return nil
+}
@@ -16,10 +16,15 @@
 package appctl
 
 import (
+	"context"
+	"net"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/util/sockopts"
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -63,9 +68,50 @@ func TestApply2ClientConfig(t *testing.T) {
 	afterClientTest(t)
 }
 
+func TestClientApplyYAMLConfig(t *testing.T) {
+	beforeClientTest(t)
+
+	jsonFile := "testdata/client_apply_config_2.json"
+	if err := ApplyJSONClientConfig(jsonFile); err != nil {
+		t.Fatalf("ApplyJSONClientConfig(%q) failed: %v", jsonFile, err)
+	}
+	want, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+
+	if err := deleteClientConfigFile(); err != nil {
+		t.Fatalf("failed to delete client config file")
+	}
+	if err := StoreClientConfig(&appctlpb.ClientConfig{}); err != nil {
+		t.Fatalf("failed to create empty client config file")
+	}
+	yamlFile := "testdata/client_apply_config_2.yaml"
+	if err := ApplyJSONClientConfig(yamlFile); err != nil {
+		t.Fatalf("ApplyJSONClientConfig(%q) failed: %v", yamlFile, err)
+	}
+	got, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("client config applied from YAML doesn't equal the one from JSON")
+	}
+
+	afterClientTest(t)
+}
+
 func TestClientApplyReject(t *testing.T) {
 	cases := []string{
 		"testdata/client_reject_active_profile_mismatch.json",
+		"testdata/client_reject_dns_leak_protection_no_remote_dns.json",
+		"testdata/client_reject_http_proxy_tls_no_key.json",
+		"testdata/client_reject_http_proxy_tls_no_port.json",
+		"testdata/client_reject_invalid_dns_leak_protection_upstream.json",
+		"testdata/client_reject_invalid_dns_upstream.json",
+		"testdata/client_reject_invalid_ipv6_source_address.json",
+		"testdata/client_reject_invalid_priority_port.json",
+		"testdata/client_reject_invalid_retransmission_backoff.json",
 		"testdata/client_reject_invalid_rpc_port.json",
 		"testdata/client_reject_mtu_too_big.json",
 		"testdata/client_reject_mtu_too_small.json",
@@ -78,10 +124,22 @@ func TestClientApplyReject(t *testing.T) {
 		"testdata/client_reject_no_server_addr.json",
 		"testdata/client_reject_no_socks5_port.json",
 		"testdata/client_reject_no_user_name.json",
+		"testdata/client_reject_profile_listener_unknown_profile.json",
+		"testdata/client_reject_relative_application_rule_cgroup_path.json",
+		"testdata/client_reject_relative_rpc_socket_path.json",
+		"testdata/client_reject_relative_socks5_unix_socket_path.json",
+		"testdata/client_reject_rest_api_no_rpc.json",
+		"testdata/client_reject_same_port_dns_socks5.json",
 		"testdata/client_reject_same_port_http_rpc.json",
 		"testdata/client_reject_same_port_http_socks5.json",
+		"testdata/client_reject_same_port_profile_listener_socks5.json",
+		"testdata/client_reject_same_port_rest_api_web_dashboard.json",
 		"testdata/client_reject_same_port_rpc_socks5.json",
+		"testdata/client_reject_same_port_transparent_socks5.json",
+		"testdata/client_reject_same_port_web_dashboard_socks5.json",
+		"testdata/client_reject_subscription_not_https.json",
 		"testdata/client_reject_user_has_quota.json",
+		"testdata/client_reject_web_dashboard_no_rpc.json",
 		"testdata/client_reject_wrong_ipv4_address.json",
 		"testdata/client_reject_wrong_ipv6_address.json",
 	}
@@ -205,6 +263,154 @@ func TestClientDeleteProfileRejectActiveProfile(t *testing.T) {
 	afterClientTest(t)
 }
 
+func TestClientProfileStorage(t *testing.T) {
+	// Use the default protobuf config file.
+	cachedClientConfigFilePath = ""
+	beforeClientTest(t)
+
+	configFile := "testdata/client_before_delete_profile.json"
+	if err := ApplyJSONClientConfig(configFile); err != nil {
+		t.Fatalf("ApplyJSONClientConfig(%q) failed: %v", configFile, err)
+	}
+	profileDir := clientProfileDir(cachedClientConfigFilePath)
+	names, err := listClientProfiles(profileDir)
+	if err != nil {
+		t.Fatalf("listClientProfiles() failed: %v", err)
+	}
+	if len(names) != 2 || names[0] != "default" || names[1] != "new" {
+		t.Errorf("got profile files %v, want [default new]", names)
+	}
+
+	// A broken profile doesn't affect loading the active profile.
+	brokenFile := filepath.Join(profileDir, clientProfileFileName("default"))
+	if err := os.WriteFile(brokenFile, []byte("broken"), 0660); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	config, err := LoadActiveClientConfig()
+	if err != nil {
+		t.Fatalf("LoadActiveClientConfig() failed: %v", err)
+	}
+	if len(config.GetProfiles()) != 1 || config.GetProfiles()[0].GetProfileName() != "new" {
+		t.Errorf("LoadActiveClientConfig() didn't return only the active profile")
+	}
+	if _, err := LoadClientConfig(); err == nil {
+		t.Errorf("want error in LoadClientConfig() with a broken profile, got no error")
+	}
+
+	// Deleting a profile deletes the profile file.
+	config.ActiveProfile = proto.String("new")
+	if err := StoreClientConfig(config); err != nil {
+		t.Fatalf("StoreClientConfig() failed: %v", err)
+	}
+	if _, err := os.Stat(brokenFile); !os.IsNotExist(err) {
+		t.Errorf("profile file %q is not deleted", brokenFile)
+	}
+	got, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	if !proto.Equal(got, config) {
+		t.Errorf("client config doesn't equal after store and load")
+	}
+
+	afterClientTest(t)
+}
+
+func TestLoadActiveClientConfigWithProfileListeners(t *testing.T) {
+	testCases := []struct {
+		name     string
+		jsonFile bool
+	}{
+		{"protobuf", false},
+		{"json", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The protobuf config file stores profiles in separate files,
+			// while the JSON config file stores profiles inline.
+			cachedClientConfigFilePath = ""
+			defer func() {
+				cachedClientConfigFilePath = ""
+			}()
+			if tc.jsonFile {
+				t.Setenv("MIERU_CONFIG_JSON_FILE", filepath.Join(t.TempDir(), "client.conf.json"))
+			}
+			beforeClientTest(t)
+			defer afterClientTest(t)
+
+			configFile := "testdata/client_before_delete_profile.json"
+			if err := ApplyJSONClientConfig(configFile); err != nil {
+				t.Fatalf("ApplyJSONClientConfig(%q) failed: %v", configFile, err)
+			}
+			config, err := LoadClientConfig()
+			if err != nil {
+				t.Fatalf("LoadClientConfig() failed: %v", err)
+			}
+			config.ProfileListeners = []*appctlpb.ProfileListener{
+				{
+					ProfileName: proto.String("default"),
+					Socks5Port:  proto.Int32(1081),
+				},
+			}
+			if err := StoreClientConfig(config); err != nil {
+				t.Fatalf("StoreClientConfig() failed: %v", err)
+			}
+
+			active, err := LoadActiveClientConfig()
+			if err != nil {
+				t.Fatalf("LoadActiveClientConfig() failed: %v", err)
+			}
+			if len(active.GetProfiles()) != 2 {
+				t.Errorf("LoadActiveClientConfig() returned %d profiles, want 2", len(active.GetProfiles()))
+			}
+			if _, err := GetActiveProfileFromConfig(active, "default"); err != nil {
+				t.Errorf("profile used by profile listener is not loaded: %v", err)
+			}
+			if err := ValidateFullClientConfig(active); err != nil {
+				t.Errorf("ValidateFullClientConfig() failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientRPCOverUnixSocket(t *testing.T) {
+	beforeClientTest(t)
+	defer afterClientTest(t)
+
+	configFile := "testdata/client_apply_config_1.json"
+	if err := ApplyJSONClientConfig(configFile); err != nil {
+		t.Fatalf("ApplyJSONClientConfig(%q) failed: %v", configFile, err)
+	}
+	config, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	socketPath := filepath.Join(t.TempDir(), "rpc.sock")
+	config.RpcSocketPath = proto.String(socketPath)
+	if err := StoreClientConfig(config); err != nil {
+		t.Fatalf("StoreClientConfig() failed: %v", err)
+	}
+
+	token, err := PrepareClientRPCToken()
+	if err != nil {
+		t.Fatalf("PrepareClientRPCToken() failed: %v", err)
+	}
+	tokenPath, _ := ClientRPCTokenPath()
+	defer os.Remove(tokenPath)
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	grpcServer := grpc.NewServer(RPCTokenServerOptions(token)...)
+	appctlpb.RegisterClientLifecycleServiceServer(grpcServer, NewClientLifecycleService())
+	go grpcServer.Serve(l)
+	defer grpcServer.Stop()
+
+	if err := IsClientDaemonRunning(context.Background()); err != nil {
+		t.Errorf("IsClientDaemonRunning() failed: %v", err)
+	}
+}
+
 func beforeClientTest(t *testing.T) {
 	dir := os.TempDir()
 	if dir == "" {
@@ -224,3 +430,181 @@ func afterClientTest(t *testing.T) {
 		t.Fatalf("failed to clean client config file after the test")
 	}
 }
+
+func TestScopeClientConfig(t *testing.T) {
+	server := func(domain string) *appctlpb.ServerEndpoint {
+		return &appctlpb.ServerEndpoint{DomainName: proto.String(domain)}
+	}
+	config := &appctlpb.ClientConfig{
+		Profiles: []*appctlpb.ClientProfile{
+			{
+				ProfileName: proto.String("home"),
+				Servers:     []*appctlpb.ServerEndpoint{server("a.example.com"), server("b.example.com")},
+			},
+			{
+				ProfileName: proto.String("work"),
+				Servers:     []*appctlpb.ServerEndpoint{server("c.example.com")},
+			},
+		},
+		ActiveProfile: proto.String("work"),
+		RpcPort:       proto.Int32(8964),
+		Socks5Port:    proto.Int32(1080),
+	}
+
+	scoped, err := scopeClientConfig(config, nil, []string{"b.example.com"})
+	if err != nil {
+		t.Fatalf("scopeClientConfig() failed: %v", err)
+	}
+	if len(scoped.GetProfiles()) != 1 || scoped.GetProfiles()[0].GetProfileName() != "home" {
+		t.Fatalf("scoped profiles = %v, want only profile \"home\"", scoped.GetProfiles())
+	}
+	if servers := scoped.GetProfiles()[0].GetServers(); len(servers) != 1 || servers[0].GetDomainName() != "b.example.com" {
+		t.Errorf("scoped servers = %v, want only b.example.com", servers)
+	}
+	if scoped.GetActiveProfile() != "home" {
+		t.Errorf("scoped active profile = %q, want %q", scoped.GetActiveProfile(), "home")
+	}
+	if len(config.GetProfiles()[0].GetServers()) != 2 {
+		t.Errorf("original config is modified")
+	}
+
+	scoped, err = scopeClientConfig(config, []string{"work"}, nil)
+	if err != nil {
+		t.Fatalf("scopeClientConfig() failed: %v", err)
+	}
+	if len(scoped.GetProfiles()) != 1 || scoped.GetActiveProfile() != "work" {
+		t.Errorf("scoped config = %v, want only profile \"work\"", scoped)
+	}
+
+	if _, err := scopeClientConfig(config, []string{"work"}, []string{"a.example.com"}); err == nil {
+		t.Errorf("scopeClientConfig() succeeded without any matched server, want error")
+	}
+}
+
+func TestClientRemoteDNSResolution(t *testing.T) {
+	beforeClientTest(t)
+
+	configFile := "testdata/client_apply_config_2.json"
+	if err := ApplyJSONClientConfig(configFile); err != nil {
+		t.Fatalf("ApplyJSONClientConfig(%q) failed: %v", configFile, err)
+	}
+	config, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	config.RemoteDNSResolution = proto.Bool(true)
+	config.DnsLeakProtection = &appctlpb.DNSLeakProtection{Port: proto.Int32(5353)}
+	if err := ValidateFullClientConfig(config); err != nil {
+		t.Fatalf("ValidateFullClientConfig() failed: %v", err)
+	}
+	if err := StoreClientConfig(config); err != nil {
+		t.Fatalf("StoreClientConfig() failed: %v", err)
+	}
+
+	// Remote DNS resolution is kept if the patch doesn't set it.
+	if err := ApplyJSONClientConfig(configFile); err != nil {
+		t.Fatalf("ApplyJSONClientConfig(%q) failed: %v", configFile, err)
+	}
+	config, err = LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	if !config.GetRemoteDNSResolution() {
+		t.Errorf("remote DNS resolution is not kept after applying a patch")
+	}
+
+	// Remote DNS resolution can't be disabled with DNS leak protection.
+	config.RemoteDNSResolution = proto.Bool(false)
+	if err := ValidateFullClientConfig(config); err == nil {
+		t.Errorf("ValidateFullClientConfig() succeeded with remote DNS resolution disabled and DNS leak protection, want error")
+	}
+	config.DnsLeakProtection = nil
+	if err := ValidateFullClientConfig(config); err != nil {
+		t.Errorf("ValidateFullClientConfig() failed: %v", err)
+	}
+
+	afterClientTest(t)
+}
+
+func TestIPv6SourcePreference(t *testing.T) {
+	testCases := []struct {
+		pref    appctlpb.IPv6SourceAddressPreference
+		want    sockopts.IPv6SourcePreference
+		wantErr bool
+	}{
+		{appctlpb.IPv6SourceAddressPreference_IPV6_SOURCE_DEFAULT, sockopts.IPv6SourceDefault, false},
+		{appctlpb.IPv6SourceAddressPreference_IPV6_SOURCE_PREFER_TEMPORARY, sockopts.IPv6SourceTemporary, false},
+		{appctlpb.IPv6SourceAddressPreference_IPV6_SOURCE_PREFER_PUBLIC, sockopts.IPv6SourcePublic, false},
+		{appctlpb.IPv6SourceAddressPreference(7), sockopts.IPv6SourceDefault, true},
+	}
+	for _, tc := range testCases {
+		patch := &appctlpb.ClientConfig{
+			Profiles: []*appctlpb.ClientProfile{
+				{
+					ProfileName: proto.String("default"),
+					User: &appctlpb.User{
+						Name:     proto.String("user1"),
+						Password: proto.String("fa7206ed2a94"),
+					},
+					Servers: []*appctlpb.ServerEndpoint{
+						{
+							IpAddress: proto.String("2001:db8::1"),
+							PortBindings: []*appctlpb.PortBinding{
+								{Port: proto.Int32(4000), Protocol: appctlpb.TransportProtocol_TCP.Enum()},
+							},
+						},
+					},
+					Ipv6SourceAddress: tc.pref.Enum(),
+				},
+			},
+		}
+		err := ValidateClientConfigPatch(patch)
+		if tc.wantErr && err == nil {
+			t.Errorf("ValidateClientConfigPatch() with IPv6 source address %d succeeded, want error", tc.pref)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("ValidateClientConfigPatch() with IPv6 source address %v failed: %v", tc.pref, err)
+		}
+		if got := IPv6SourcePreference(tc.pref); got != tc.want {
+			t.Errorf("IPv6SourcePreference(%d) = %v, want %v", tc.pref, got, tc.want)
+		}
+	}
+}
+
+func TestLoadHTTPProxyCertificate(t *testing.T) {
+	cachedClientConfigDir = t.TempDir()
+	defer func() {
+		cachedClientConfigDir = ""
+	}()
+	config := &appctlpb.ClientConfig{
+		HttpProxyPort: proto.Int32(8080),
+		HttpProxyTLS: &appctlpb.HTTPProxyTLS{
+			Enable: proto.Bool(true),
+		},
+	}
+
+	cert, err := LoadHTTPProxyCertificate(config)
+	if err != nil {
+		t.Fatalf("LoadHTTPProxyCertificate() failed: %v", err)
+	}
+	certFile := filepath.Join(cachedClientConfigDir, httpProxyCertificateFileName)
+	if _, err := os.Stat(certFile); err != nil {
+		t.Fatalf("self-signed certificate is not stored: %v", err)
+	}
+
+	// The stored certificate is reused.
+	cert2, err := LoadHTTPProxyCertificate(config)
+	if err != nil {
+		t.Fatalf("LoadHTTPProxyCertificate() failed: %v", err)
+	}
+	if string(cert.Certificate[0]) != string(cert2.Certificate[0]) {
+		t.Errorf("self-signed certificate is regenerated")
+	}
+
+	// User supplied certificate takes precedence.
+	config.HttpProxyTLS.CertificateFile = proto.String(certFile)
+	config.HttpProxyTLS.PrivateKeyFile = proto.String(filepath.Join(cachedClientConfigDir, "not_exist.key"))
+	if _, err := LoadHTTPProxyCertificate(config); err == nil {
+		t.Errorf("LoadHTTPProxyCertificate() succeeded with a missing private key file, want error")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/log"
+)
+
+// clockOffsetFileName is the file in the client config directory that
+// stores the clock offset learned from the proxy server. Without it, the
+// client starts with no clock offset after a restart, and can't connect
+// if the local clock is too far away from the proxy server.
+const clockOffsetFileName = "clock_offset"
+
+// LoadClientClockOffset applies the clock offset saved before the client
+// daemon was restarted, and saves the clock offset when it is changed.
+func LoadClientClockOffset() error {
+	path, err := clientClockOffsetPath()
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("os.ReadFile(%q) failed: %w", path, err)
+	}
+	offset := time.Duration(0)
+	if len(b) > 0 {
+		if offset, err = time.ParseDuration(strings.TrimSpace(string(b))); err != nil {
+			return fmt.Errorf("invalid clock offset in %q: %w", path, err)
+		}
+	}
+	cipher.SetClockOffsetHook(nil)
+	cipher.SetClockOffset(offset)
+	if offset != 0 {
+		log.Infof("using clock offset %v learned from proxy server before restart", offset)
+	}
+	cipher.SetClockOffsetHook(func(offset time.Duration) {
+		if err := os.WriteFile(path, []byte(offset.String()+"\n"), 0600); err != nil {
+			log.Warnf("save clock offset to %q failed: %v", path, err)
+		}
+	})
+	return nil
+}
+
+// clientClockOffsetPath returns the path of the clock offset file.
+func clientClockOffsetPath() (string, error) {
+	fileName, _, err := clientConfigFilePath()
+	if err != nil {
+		return "", fmt.Errorf("clientConfigFilePath() failed: %w", err)
+	}
+	return filepath.Join(filepath.Dir(fileName), clockOffsetFileName), nil
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/cipher"
+)
+
+func TestClientClockOffsetAfterRestart(t *testing.T) {
+	beforeClientTest(t)
+	defer afterClientTest(t)
+	path, err := clientClockOffsetPath()
+	if err != nil {
+		t.Fatalf("clientClockOffsetPath() failed: %v", err)
+	}
+	os.Remove(path)
+	defer func() {
+		cipher.SetClockOffsetHook(nil)
+		cipher.SetClockOffset(0)
+		os.Remove(path)
+	}()
+
+	password := []byte{0x08, 0x09, 0x06, 0x04}
+	plaintext := []byte("clock offset")
+	skew := 10 * time.Minute
+
+	// The proxy server clock is 10 minutes ahead of the local clock.
+	cipher.SetClockOffset(skew)
+	block, err := cipher.BlockCipherFromPassword(password, true)
+	if err != nil {
+		t.Fatalf("BlockCipherFromPassword() failed: %v", err)
+	}
+	ciphertext, err := block.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() failed: %v", err)
+	}
+	cipher.SetClockOffset(0)
+
+	// The client daemon starts and learns the clock offset from the proxy server.
+	if err := LoadClientClockOffset(); err != nil {
+		t.Fatalf("LoadClientClockOffset() failed: %v", err)
+	}
+	if offset := cipher.ClockOffset(); offset != 0 {
+		t.Errorf("ClockOffset() = %v without saved clock offset, want 0", offset)
+	}
+	cipher.SetClockOffset(skew)
+
+	// The client daemon is restarted. Without the saved clock offset,
+	// the first connection fails.
+	cipher.SetClockOffsetHook(nil)
+	cipher.SetClockOffset(0)
+	if _, _, err := cipher.TryDecrypt(ciphertext, password, true); err == nil {
+		t.Fatalf("TryDecrypt() succeeded without clock offset")
+	}
+
+	// The saved clock offset is used by the first connection.
+	if err := LoadClientClockOffset(); err != nil {
+		t.Fatalf("LoadClientClockOffset() failed: %v", err)
+	}
+	if offset := cipher.ClockOffset(); offset != skew {
+		t.Errorf("ClockOffset() = %v after restart, want %v", offset, skew)
+	}
+	if _, _, err := cipher.TryDecrypt(ciphertext, password, true); err != nil {
+		t.Errorf("TryDecrypt() failed after restart: %v", err)
+	}
+
+	// A broken clock offset file is reported.
+	if err := os.WriteFile(path, []byte("broken"), 0600); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	if err := LoadClientClockOffset(); err == nil {
+		t.Errorf("LoadClientClockOffset() returned no error with a broken file")
+	}
+}
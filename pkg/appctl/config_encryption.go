@@ -0,0 +1,170 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/enfein/mieru/pkg/util/keychain"
+)
+
+// The protobuf client config file and the profile files can be encrypted
+// with AES-256-GCM. The key is generated for each device and saved in the
+// credential store of the operating system, so the passwords saved on disk
+// can't be used on another device.
+//
+// An encrypted file starts with encryptedConfigMagic, followed by
+// the nonce and the ciphertext. Files without the magic are not encrypted.
+
+const (
+	configKeychainService = "mieru"
+	configKeychainAccount = "client-config-key"
+	configKeyLen          = 32
+)
+
+var encryptedConfigMagic = []byte("mieru-encrypted-config-v1\n")
+
+var (
+	// getConfigKey returns the key to encrypt client config.
+	// If create is true, a new key is generated if it doesn't exist.
+	// It can be replaced in tests.
+	getConfigKey = getConfigKeyFromKeychain
+
+	// cachedConfigKey avoids querying the keychain repeatedly.
+	cachedConfigKey []byte
+
+	// configKeyLock guards cachedConfigKey. It is held while the key
+	// is loaded or created, so concurrent callers don't create
+	// different keys.
+	configKeyLock sync.Mutex
+)
+
+// getConfigKeyFromKeychain loads the client config key from the keychain.
+func getConfigKeyFromKeychain(create bool) ([]byte, error) {
+	configKeyLock.Lock()
+	defer configKeyLock.Unlock()
+	if cachedConfigKey != nil {
+		return cachedConfigKey, nil
+	}
+	key, err := keychain.Get(configKeychainService, configKeychainAccount)
+	if errors.Is(err, keychain.ErrNotFound) && create {
+		key = make([]byte, configKeyLen)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("rand.Read() failed: %w", err)
+		}
+		if err := keychain.Set(configKeychainService, configKeychainAccount, key); err != nil {
+			return nil, fmt.Errorf("keychain.Set() failed: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("keychain.Get() failed: %w", err)
+	}
+	if len(key) != configKeyLen {
+		return nil, fmt.Errorf("client config key in keychain has %d bytes, want %d", len(key), configKeyLen)
+	}
+	cachedConfigKey = key
+	return key, nil
+}
+
+// isEncryptedConfig returns true if the content of a config file is encrypted.
+func isEncryptedConfig(b []byte) bool {
+	return bytes.HasPrefix(b, encryptedConfigMagic)
+}
+
+func newConfigAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher() failed: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptConfig encrypts the content of a config file.
+func encryptConfig(plaintext []byte) ([]byte, error) {
+	key, err := getConfigKey(true)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newConfigAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("rand.Read() failed: %w", err)
+	}
+	res := make([]byte, 0, len(encryptedConfigMagic)+len(nonce)+len(plaintext)+aead.Overhead())
+	res = append(res, encryptedConfigMagic...)
+	res = append(res, nonce...)
+	return aead.Seal(res, nonce, plaintext, encryptedConfigMagic), nil
+}
+
+// decryptConfig returns the plaintext of a config file.
+// It returns the input if the content is not encrypted.
+func decryptConfig(b []byte) ([]byte, error) {
+	if !isEncryptedConfig(b) {
+		return b, nil
+	}
+	key, err := getConfigKey(false)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newConfigAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	b = b[len(encryptedConfigMagic):]
+	if len(b) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted config is truncated")
+	}
+	plaintext, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], encryptedConfigMagic)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt config failed, the key in keychain may not match: %w", err)
+	}
+	return plaintext, nil
+}
+
+// readConfigFile reads a config file and decrypts the content if needed.
+func readConfigFile(fileName string) ([]byte, error) {
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return decryptConfig(b)
+}
+
+// isConfigFileEncrypted returns true if the config file exists and
+// is encrypted.
+func isConfigFileEncrypted(fileName string) (bool, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+	header := make([]byte, len(encryptedConfigMagic))
+	n, _ := io.ReadFull(f, header)
+	return isEncryptedConfig(header[:n]), nil
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestClientConfigEncryption(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, configKeyLen)
+	getConfigKey = func(create bool) ([]byte, error) {
+		return key, nil
+	}
+	defer func() {
+		getConfigKey = getConfigKeyFromKeychain
+	}()
+
+	// Use the default protobuf config file.
+	cachedClientConfigFilePath = ""
+	beforeClientTest(t)
+	defer afterClientTest(t)
+
+	configFile := "testdata/client_before_delete_profile.json"
+	if err := ApplyJSONClientConfig(configFile); err != nil {
+		t.Fatalf("ApplyJSONClientConfig(%q) failed: %v", configFile, err)
+	}
+	want, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+
+	if err := SetClientConfigEncryption(true); err != nil {
+		t.Fatalf("SetClientConfigEncryption(true) failed: %v", err)
+	}
+	profileFile := filepath.Join(clientProfileDir(cachedClientConfigFilePath), clientProfileFileName("default"))
+	for _, f := range []string{cachedClientConfigFilePath, profileFile} {
+		if encrypted, err := isConfigFileEncrypted(f); err != nil || !encrypted {
+			t.Errorf("file %q is not encrypted", f)
+		}
+	}
+	b, err := os.ReadFile(profileFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	if bytes.Contains(b, []byte(want.GetProfiles()[0].GetUser().GetName())) {
+		t.Errorf("encrypted profile file contains the user name")
+	}
+	got, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("client config doesn't equal after encryption")
+	}
+
+	// Encryption is kept when the config is changed.
+	got.ActiveProfile = proto.String("new")
+	if err := StoreClientConfig(got); err != nil {
+		t.Fatalf("StoreClientConfig() failed: %v", err)
+	}
+	if encrypted, err := isConfigFileEncrypted(cachedClientConfigFilePath); err != nil || !encrypted {
+		t.Errorf("client config is not encrypted after StoreClientConfig()")
+	}
+
+	// A wrong key can't decrypt the config.
+	key = bytes.Repeat([]byte{0x24}, configKeyLen)
+	if _, err := LoadClientConfig(); err == nil {
+		t.Errorf("want error in LoadClientConfig() with a wrong key, got no error")
+	}
+	key = bytes.Repeat([]byte{0x42}, configKeyLen)
+
+	if err := SetClientConfigEncryption(false); err != nil {
+		t.Fatalf("SetClientConfigEncryption(false) failed: %v", err)
+	}
+	for _, f := range []string{cachedClientConfigFilePath, profileFile} {
+		if encrypted, err := isConfigFileEncrypted(f); err != nil || encrypted {
+			t.Errorf("file %q is still encrypted", f)
+		}
+	}
+	if _, err := LoadClientConfig(); err != nil {
+		t.Errorf("LoadClientConfig() failed: %v", err)
+	}
+}
@@ -15,7 +15,13 @@
 
 package appctl
 
-import "strings"
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/enfein/mieru/pkg/util/yaml"
+)
 
 type ConfigFileType int
 
@@ -33,3 +39,31 @@ func FindConfigFileType(fileName string) ConfigFileType {
 	}
 	return PROTOBUF_CONFIG_FILE_TYPE
 }
+
+// IsYAMLFile returns true if the file name has a YAML extension name.
+func IsYAMLFile(fileName string) bool {
+	return strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, ".yml")
+}
+
+// ReadConfigFileAsJSON reads a user provided config file. YAML files are
+// converted to JSON, so both formats share the same protobuf field names.
+// The config fragments listed by the "include" key are merged into the
+// returned config.
+func ReadConfigFileAsJSON(path string) ([]byte, error) {
+	return readConfigFileWithIncludes(path, nil)
+}
+
+// readConfigFileWithIncludes reads the config file as JSON and resolves its includes.
+// stack holds the files that include this file.
+func readConfigFileWithIncludes(path string, stack []string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile(%q) failed: %w", path, err)
+	}
+	if IsYAMLFile(path) {
+		if b, err = yaml.ToJSON(b); err != nil {
+			return nil, fmt.Errorf("parse YAML file %q failed: %w", path, err)
+		}
+	}
+	return resolveConfigIncludes(path, b, stack)
+}
@@ -0,0 +1,212 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	// configIncludeKey is the top level key of a config file that lists
+	// the config fragments to include.
+	configIncludeKey = "include"
+
+	// maxConfigIncludeDepth is the maximum depth of nested includes.
+	maxConfigIncludeDepth = 8
+)
+
+// configMergeKeys identify the objects in a list. If every object of
+// two lists has the same key, the lists are merged object by object,
+// e.g. two profiles with the same profileName are merged into one.
+// Otherwise, the list is replaced.
+var configMergeKeys = []string{"profileName", "name"}
+
+// resolveConfigIncludes merges the config fragments listed by the
+// "include" key of the config file at path. The fragments are merged
+// in order, and the config file itself is merged last. A value from
+// a later file overrides the value from an earlier file. stack holds
+// the files that include this file, to find include cycles.
+//
+// If the config file has no "include" key, b is returned unchanged.
+func resolveConfigIncludes(path string, b []byte, stack []string) ([]byte, error) {
+	top, err := decodeConfigObject(b)
+	if err != nil {
+		// Let protojson report the syntax error.
+		return b, nil
+	}
+	raw, ok := top[configIncludeKey]
+	if !ok {
+		return b, nil
+	}
+	delete(top, configIncludeKey)
+
+	var includes []string
+	switch v := raw.(type) {
+	case string:
+		includes = []string{v}
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("config file %q: %s must be a list of file paths", path, configIncludeKey)
+			}
+			includes = append(includes, s)
+		}
+	default:
+		return nil, fmt.Errorf("config file %q: %s must be a list of file paths", path, configIncludeKey)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("filepath.Abs(%q) failed: %w", path, err)
+	}
+	for _, p := range stack {
+		if p == absPath {
+			return nil, fmt.Errorf("config file %q includes itself", path)
+		}
+	}
+	if len(stack) >= maxConfigIncludeDepth {
+		return nil, fmt.Errorf("config file %q: more than %d levels of %s", path, maxConfigIncludeDepth, configIncludeKey)
+	}
+	stack = append(stack, absPath)
+
+	merged := map[string]any{}
+	for _, include := range includes {
+		if include == "" {
+			return nil, fmt.Errorf("config file %q: %s has an empty file path", path, configIncludeKey)
+		}
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		fb, err := readConfigFileWithIncludes(include, stack)
+		if err != nil {
+			return nil, fmt.Errorf("config file %q: %w", path, err)
+		}
+		fragment, err := decodeConfigObject(fb)
+		if err != nil {
+			return nil, fmt.Errorf("parse included config file %q failed: %w", include, err)
+		}
+		merged = mergeConfigValues(merged, fragment).(map[string]any)
+	}
+	merged = mergeConfigValues(merged, top).(map[string]any)
+	return json.Marshal(merged)
+}
+
+// decodeConfigObject decodes the JSON object. Numbers are kept as
+// json.Number so 64 bit integers are not rounded.
+func decodeConfigObject(b []byte) (map[string]any, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var obj map[string]any
+	if err := d.Decode(&obj); err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("config is not a JSON object")
+	}
+	return obj, nil
+}
+
+// mergeConfigValues returns the value of base overridden by override.
+// Objects are merged key by key, and lists of objects are merged by
+// configMergeKeys. Other values are replaced by override.
+func mergeConfigValues(base, override any) any {
+	switch o := override.(type) {
+	case map[string]any:
+		b, ok := base.(map[string]any)
+		if !ok {
+			return o
+		}
+		for k, v := range o {
+			if bv, ok := b[k]; ok {
+				b[k] = mergeConfigValues(bv, v)
+			} else {
+				b[k] = v
+			}
+		}
+		return b
+	case []any:
+		b, ok := base.([]any)
+		if !ok {
+			return o
+		}
+		if merged, ok := mergeConfigLists(b, o); ok {
+			return merged
+		}
+		return o
+	default:
+		return override
+	}
+}
+
+// mergeConfigLists merges two lists of objects with the same merge key.
+// Objects of override are merged into the object of base with the same
+// key, or appended to the end. It returns false if the lists can't be
+// merged by key.
+func mergeConfigLists(base, override []any) ([]any, bool) {
+	if len(base) == 0 || len(override) == 0 {
+		return nil, false
+	}
+	for _, key := range configMergeKeys {
+		baseKeys, ok := configListKeys(base, key)
+		if !ok {
+			continue
+		}
+		overrideKeys, ok := configListKeys(override, key)
+		if !ok {
+			continue
+		}
+		merged := append([]any{}, base...)
+		index := make(map[string]int)
+		for i, k := range baseKeys {
+			index[k] = i
+		}
+		for i, k := range overrideKeys {
+			if j, ok := index[k]; ok {
+				merged[j] = mergeConfigValues(merged[j], override[i])
+			} else {
+				index[k] = len(merged)
+				merged = append(merged, override[i])
+			}
+		}
+		return merged, true
+	}
+	return nil, false
+}
+
+// configListKeys returns the value of key of each object in the list.
+// It returns false if an item is not an object, the key is missing,
+// or the values are not unique.
+func configListKeys(list []any, key string) ([]string, bool) {
+	keys := make([]string, 0, len(list))
+	seen := make(map[string]bool)
+	for _, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		k, ok := obj[key].(string)
+		if !ok || seen[k] {
+			return nil, false
+		}
+		seen[k] = true
+		keys = append(keys, k)
+	}
+	return keys, true
+}
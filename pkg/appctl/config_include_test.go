@@ -0,0 +1,192 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("os.MkdirAll() failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("os.WriteFile() failed: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestReadConfigFileWithIncludes(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"shared/servers.json": `{
+			"profiles": [{
+				"profileName": "family",
+				"user": {"name": "shared", "password": "shared-password"},
+				"servers": [{"ipAddress": "1.1.1.1", "portBindings": [{"port": 4000, "protocol": "TCP"}]}]
+			}],
+			"rpcPort": 8964,
+			"socks5Port": 1080
+		}`,
+		"shared/defaults.yaml": "loggingLevel: DEBUG\nsocks5Port: 1081\n",
+		"machine.json": `{
+			"include": ["shared/servers.json", "shared/defaults.yaml"],
+			"profiles": [
+				{"profileName": "family", "user": {"name": "alice", "password": "alice-password"}},
+				{"profileName": "work", "user": {"name": "bob", "password": "bob-password"}}
+			],
+			"activeProfile": "family",
+			"socks5Port": 1082
+		}`,
+	})
+
+	b, err := ReadConfigFileAsJSON(filepath.Join(dir, "machine.json"))
+	if err != nil {
+		t.Fatalf("ReadConfigFileAsJSON() failed: %v", err)
+	}
+	c := &pb.ClientConfig{}
+	if err := jsonUnmarshalOption.Unmarshal(b, c); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if c.GetSocks5Port() != 1082 {
+		t.Errorf("socks5Port is %d, want the value of the including file", c.GetSocks5Port())
+	}
+	if c.GetRpcPort() != 8964 {
+		t.Errorf("rpcPort is %d, want the value of the included file", c.GetRpcPort())
+	}
+	if c.GetLoggingLevel() != pb.LoggingLevel_DEBUG {
+		t.Errorf("loggingLevel is %v, want the value of the YAML file", c.GetLoggingLevel())
+	}
+	if len(c.GetProfiles()) != 2 {
+		t.Fatalf("got %d profiles, want 2", len(c.GetProfiles()))
+	}
+	family := c.GetProfiles()[0]
+	if family.GetProfileName() != "family" || family.GetUser().GetName() != "alice" {
+		t.Errorf("profile %q has user %q, want the user of the including file", family.GetProfileName(), family.GetUser().GetName())
+	}
+	if len(family.GetServers()) != 1 || family.GetServers()[0].GetIpAddress() != "1.1.1.1" {
+		t.Errorf("servers of the included file are not merged: %v", family.GetServers())
+	}
+	if c.GetProfiles()[1].GetProfileName() != "work" {
+		t.Errorf("second profile is %q, want %q", c.GetProfiles()[1].GetProfileName(), "work")
+	}
+}
+
+func TestReadConfigFileWithoutIncludes(t *testing.T) {
+	content := `{"socks5Port": 1080}`
+	dir := writeConfigFiles(t, map[string]string{"config.json": content})
+	b, err := ReadConfigFileAsJSON(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatalf("ReadConfigFileAsJSON() failed: %v", err)
+	}
+	if string(b) != content {
+		t.Errorf("config file without include is changed to %s", string(b))
+	}
+}
+
+func TestReadConfigFileIncludeErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			"cycle",
+			map[string]string{
+				"config.json": `{"include": ["a.json"]}`,
+				"a.json":      `{"include": ["config.json"]}`,
+			},
+			"includes itself",
+		},
+		{
+			"missing file",
+			map[string]string{
+				"config.json": `{"include": ["missing.json"]}`,
+			},
+			"missing.json",
+		},
+		{
+			"not a list of paths",
+			map[string]string{
+				"config.json": `{"include": [1]}`,
+			},
+			"must be a list of file paths",
+		},
+		{
+			"fragment is not an object",
+			map[string]string{
+				"config.json": `{"include": ["a.json"]}`,
+				"a.json":      `[]`,
+			},
+			"parse included config file",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tc.files)
+			_, err := ReadConfigFileAsJSON(filepath.Join(dir, "config.json"))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ReadConfigFileAsJSON() returned error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeConfigValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		base     string
+		override string
+		want     string
+	}{
+		{"override scalar", `{"a": 1, "b": 2}`, `{"b": 3}`, `{"a":1,"b":3}`},
+		{"merge nested object", `{"a": {"x": 1, "y": 2}}`, `{"a": {"y": 3}}`, `{"a":{"x":1,"y":3}}`},
+		{"replace list of scalars", `{"a": [1, 2]}`, `{"a": [3]}`, `{"a":[3]}`},
+		{"replace list without key", `{"a": [{"x": 1}]}`, `{"a": [{"x": 2}]}`, `{"a":[{"x":2}]}`},
+		{"merge list by name", `{"a": [{"name": "u1", "x": 1}, {"name": "u2"}]}`, `{"a": [{"name": "u1", "y": 2}, {"name": "u3"}]}`, `{"a":[{"name":"u1","x":1,"y":2},{"name":"u2"},{"name":"u3"}]}`},
+		{"clear list", `{"a": [{"name": "u1"}]}`, `{"a": []}`, `{"a":[]}`},
+		{"null removes value", `{"a": {"x": 1}}`, `{"a": null}`, `{"a":null}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base, err := decodeConfigObject([]byte(tc.base))
+			if err != nil {
+				t.Fatalf("decodeConfigObject() failed: %v", err)
+			}
+			override, err := decodeConfigObject([]byte(tc.override))
+			if err != nil {
+				t.Fatalf("decodeConfigObject() failed: %v", err)
+			}
+			got, err := json.Marshal(mergeConfigValues(base, override))
+			if err != nil {
+				t.Fatalf("json.Marshal() failed: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got %s, want %s", string(got), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/protocolv2"
+)
+
+// validateConnectionLimits checks the connection limits, if they are set.
+func validateConnectionLimits(limits *pb.ConnectionLimits) error {
+	if limits.GetMaxSessionsPerUser() < 0 || limits.GetMaxSessionsPerSourceIP() < 0 {
+		return fmt.Errorf("connection limits: maximum number of sessions can't be negative")
+	}
+	if limits.GetMaxUnderlaysPerUser() < 0 || limits.GetMaxUnderlaysPerSourceIP() < 0 {
+		return fmt.Errorf("connection limits: maximum number of underlays can't be negative")
+	}
+	return nil
+}
+
+// ConnectionLimitConfig converts the connection limits in config to the
+// parameters used by the server underlays.
+func ConnectionLimitConfig(limits *pb.ConnectionLimits) protocolv2.ConnectionLimitConfig {
+	return protocolv2.ConnectionLimitConfig{
+		MaxSessionsPerUser:      int(limits.GetMaxSessionsPerUser()),
+		MaxSessionsPerSourceIP:  int(limits.GetMaxSessionsPerSourceIP()),
+		MaxUnderlaysPerUser:     int(limits.GetMaxUnderlaysPerUser()),
+		MaxUnderlaysPerSourceIP: int(limits.GetMaxUnderlaysPerSourceIP()),
+	}
+}
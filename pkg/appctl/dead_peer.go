@@ -0,0 +1,44 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// minDeadPeerTimeoutSeconds is the minimum dead peer timeout
+	// allowed in config.
+	minDeadPeerTimeoutSeconds = 10
+
+	// maxDeadPeerTimeoutSeconds is the maximum dead peer timeout
+	// allowed in config.
+	maxDeadPeerTimeoutSeconds = 300
+)
+
+// validateDeadPeerTimeout checks the dead peer timeout, if it is set.
+func validateDeadPeerTimeout(seconds int32) error {
+	if seconds != 0 && (seconds < minDeadPeerTimeoutSeconds || seconds > maxDeadPeerTimeoutSeconds) {
+		return fmt.Errorf("dead peer timeout %d seconds is not between %d and %d", seconds, minDeadPeerTimeoutSeconds, maxDeadPeerTimeoutSeconds)
+	}
+	return nil
+}
+
+// DeadPeerTimeout converts the dead peer timeout in config to a duration.
+func DeadPeerTimeout(seconds int32) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/socks5"
+)
+
+// maxDrainTimeoutSeconds is the maximum drain timeout allowed in config.
+const maxDrainTimeoutSeconds = 60
+
+// validateDrainTimeout checks the drain timeout.
+func validateDrainTimeout(seconds int32) error {
+	if seconds < 0 || seconds > maxDrainTimeoutSeconds {
+		return fmt.Errorf("drain timeout %d seconds is not between 0 and %d", seconds, maxDrainTimeoutSeconds)
+	}
+	return nil
+}
+
+// drainSocks5Server waits for the connections of the socks5 server to
+// finish before the server is closed. New connections are rejected.
+func drainSocks5Server(server *socks5.Server, timeout time.Duration) {
+	if timeout <= 0 || server.ActiveConnections() == 0 {
+		return
+	}
+	log.Infof("draining socks5 server with %d active connections, timeout is %v", server.ActiveConnections(), timeout)
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+	if err := server.Drain(ctx); err != nil {
+		log.Infof("socks5 server Drain() failed: %v", err)
+		return
+	}
+	log.Infof("all connections of socks5 server are finished")
+}
+
+// serverDrainTimeout returns the drain timeout of the server.
+// It returns 0 if the server config can't be loaded.
+func serverDrainTimeout() time.Duration {
+	config, err := LoadServerConfig()
+	if err != nil {
+		return 0
+	}
+	return time.Duration(config.GetAdvancedSettings().GetDrainTimeoutSeconds()) * time.Second
+}
+
+// clientDrainTimeout returns the drain timeout of the client.
+// It returns 0 if the client config can't be loaded.
+func clientDrainTimeout() time.Duration {
+	config, err := LoadActiveClientConfig()
+	if err != nil {
+		return 0
+	}
+	return time.Duration(config.GetAdvancedSettings().GetDrainTimeoutSeconds()) * time.Second
+}
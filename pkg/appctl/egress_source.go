@@ -0,0 +1,54 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"net"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/socks5"
+)
+
+// validateEgressSource checks the egress source, if it is set.
+func validateEgressSource(source *pb.EgressSource) error {
+	if source.GetIpv4Address() != "" {
+		ip := net.ParseIP(source.GetIpv4Address())
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("egress source: %q is not an IPv4 address", source.GetIpv4Address())
+		}
+	}
+	if source.GetIpv6Address() != "" {
+		ip := net.ParseIP(source.GetIpv6Address())
+		if ip == nil || ip.To4() != nil {
+			return fmt.Errorf("egress source: %q is not an IPv6 address", source.GetIpv6Address())
+		}
+	}
+	return nil
+}
+
+// OutboundConfig converts the egress source in config to the outbound
+// config of socks5 server. It returns nil if the egress source is not set.
+func OutboundConfig(source *pb.EgressSource) *socks5.OutboundConfig {
+	if source.GetInterfaceName() == "" && source.GetIpv4Address() == "" && source.GetIpv6Address() == "" {
+		return nil
+	}
+	return &socks5.OutboundConfig{
+		Interface: source.GetInterfaceName(),
+		IPv4:      net.ParseIP(source.GetIpv4Address()),
+		IPv6:      net.ParseIP(source.GetIpv6Address()),
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/protocolv2"
+)
+
+const (
+	// minFlowControlWindow is the minimum window size allowed in config.
+	minFlowControlWindow = 16
+
+	// maxFlowControlWindow is the maximum window size allowed in config.
+	maxFlowControlWindow = 16 * 1024
+)
+
+// validateFlowControlSettings checks the flow control parameters,
+// if they are set.
+func validateFlowControlSettings(settings *pb.FlowControlSettings) error {
+	if size := settings.GetSendWindowSize(); size != 0 && (size < minFlowControlWindow || size > maxFlowControlWindow) {
+		return fmt.Errorf("flow control send window size %d is not between %d and %d", size, minFlowControlWindow, maxFlowControlWindow)
+	}
+	if size := settings.GetReceiveWindowSize(); size != 0 && (size < minFlowControlWindow || size > maxFlowControlWindow) {
+		return fmt.Errorf("flow control receive window size %d is not between %d and %d", size, minFlowControlWindow, maxFlowControlWindow)
+	}
+	return nil
+}
+
+// FlowControlConfig converts the flow control settings
+// to the parameters used by UDP sessions.
+func FlowControlConfig(settings *pb.FlowControlSettings) protocolv2.FlowControlConfig {
+	return protocolv2.FlowControlConfig{
+		SendWindowSize:    int(settings.GetSendWindowSize()),
+		ReceiveWindowSize: int(settings.GetReceiveWindowSize()),
+		DisableAutoTuning: settings.GetDisableAutoTuning(),
+	}
+}
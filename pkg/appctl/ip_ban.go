@@ -0,0 +1,108 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+const (
+	defaultIPBanFindTimeSeconds = 600
+	minIPBanFindTimeSeconds     = 10
+	maxIPBanFindTimeSeconds     = 86400
+
+	defaultIPBanTimeSeconds = 3600
+	minIPBanTimeSeconds     = 10
+	maxIPBanTimeSeconds     = 30 * 86400
+)
+
+// ipBlocklistLock serializes the changes to the IP blocklist.
+var ipBlocklistLock sync.Mutex
+
+// validateIPBanSettings checks the IP ban settings, if they are set.
+func validateIPBanSettings(settings *pb.IPBanSettings) error {
+	if settings.GetMaxFailures() < 0 {
+		return fmt.Errorf("IP ban max failures %d is negative", settings.GetMaxFailures())
+	}
+	if settings.GetFindTimeSeconds() != 0 && (settings.GetFindTimeSeconds() < minIPBanFindTimeSeconds || settings.GetFindTimeSeconds() > maxIPBanFindTimeSeconds) {
+		return fmt.Errorf("IP ban find time %d seconds is not between %d and %d", settings.GetFindTimeSeconds(), minIPBanFindTimeSeconds, maxIPBanFindTimeSeconds)
+	}
+	if settings.GetBanTimeSeconds() != 0 && (settings.GetBanTimeSeconds() < minIPBanTimeSeconds || settings.GetBanTimeSeconds() > maxIPBanTimeSeconds) {
+		return fmt.Errorf("IP ban time %d seconds is not between %d and %d", settings.GetBanTimeSeconds(), minIPBanTimeSeconds, maxIPBanTimeSeconds)
+	}
+	return nil
+}
+
+// IPBanConfig converts the IP ban settings in config to the parameters
+// used by the server underlays, filling in the default values.
+func IPBanConfig(settings *pb.IPBanSettings) protocolv2.IPBanConfig {
+	findTime := settings.GetFindTimeSeconds()
+	if findTime == 0 {
+		findTime = defaultIPBanFindTimeSeconds
+	}
+	banTime := settings.GetBanTimeSeconds()
+	if banTime == 0 {
+		banTime = defaultIPBanTimeSeconds
+	}
+	return protocolv2.IPBanConfig{
+		MaxFailures: int(settings.GetMaxFailures()),
+		FindTime:    time.Duration(findTime) * time.Second,
+		BanTime:     time.Duration(banTime) * time.Second,
+	}
+}
+
+// updateIPBlocklist adds the IP range to, or removes it from, the blocklist
+// in server config. The new blocklist takes effect immediately.
+// It returns false if the blocklist is not changed.
+func updateIPBlocklist(ipNet *net.IPNet, add bool) (bool, error) {
+	ipBlocklistLock.Lock()
+	defer ipBlocklistLock.Unlock()
+
+	config, err := LoadServerConfig()
+	if err != nil {
+		return false, fmt.Errorf("LoadServerConfig() failed: %w", err)
+	}
+	found := false
+	remaining := make([]string, 0, len(config.GetBlockedIPRanges()))
+	for _, ipRange := range config.GetBlockedIPRanges() {
+		if existing, err := util.ParseIPRange(ipRange); err == nil && existing.String() == ipNet.String() {
+			found = true
+			continue
+		}
+		remaining = append(remaining, ipRange)
+	}
+	if add == found {
+		return false, nil
+	}
+	if add {
+		remaining = append(remaining, ipNet.String())
+	}
+	config.BlockedIPRanges = remaining
+	if err := StoreServerConfig(config); err != nil {
+		return false, fmt.Errorf("StoreServerConfig() failed: %w", err)
+	}
+	if err := protocolv2.SetIPBlocklist(config.GetBlockedIPRanges()); err != nil {
+		return false, fmt.Errorf("SetIPBlocklist() failed: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/util/sockopts"
+)
+
+// validateIPv6SourceAddress checks the IPv6 source address preference.
+func validateIPv6SourceAddress(pref pb.IPv6SourceAddressPreference) error {
+	if _, ok := pb.IPv6SourceAddressPreference_name[int32(pref)]; !ok {
+		return fmt.Errorf("IPv6 source address preference %d is invalid", pref)
+	}
+	return nil
+}
+
+// IPv6SourcePreference converts the IPv6 source address preference
+// in config to the socket option.
+func IPv6SourcePreference(pref pb.IPv6SourceAddressPreference) sockopts.IPv6SourcePreference {
+	switch pref {
+	case pb.IPv6SourceAddressPreference_IPV6_SOURCE_PREFER_TEMPORARY:
+		return sockopts.IPv6SourceTemporary
+	case pb.IPv6SourceAddressPreference_IPV6_SOURCE_PREFER_PUBLIC:
+		return sockopts.IPv6SourcePublic
+	default:
+		return sockopts.IPv6SourceDefault
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// LaunchdLabel is the label of the mieru client launchd service.
+const LaunchdLabel = "io.github.enfein.mieru"
+
+// launchdPlistTemplate runs the client daemon as a launch agent.
+// The daemon is started again by launchd if it exits with an error,
+// but not after it is stopped by "mieru stop" command.
+const launchdPlistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+		<string>run</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ThrottleInterval</key>
+	<integer>10</integer>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`
+
+// launchdPaths returns the path of the plist file and the directory of
+// the standard output and standard error logs.
+func launchdPaths() (plistPath, logDir string, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	plistPath = filepath.Join(home, "Library", "LaunchAgents", LaunchdLabel+".plist")
+	logDir = filepath.Join(home, "Library", "Logs", "mieru")
+	return plistPath, logDir, nil
+}
+
+// launchdDomain returns the launchd domain of the current user.
+func launchdDomain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+// newLaunchdPlist returns the content of the launchd plist file that runs
+// the executable.
+func newLaunchdPlist(executable, logDir string) ([]byte, error) {
+	escape := func(s string) (string, error) {
+		var b bytes.Buffer
+		if err := xml.EscapeText(&b, []byte(s)); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	values := []string{LaunchdLabel, executable, filepath.Join(logDir, "stdout.log"), filepath.Join(logDir, "stderr.log")}
+	args := make([]any, 0, len(values))
+	for _, v := range values {
+		escaped, err := escape(v)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, escaped)
+	}
+	return []byte(fmt.Sprintf(launchdPlistTemplate, args...)), nil
+}
+
+// checkLaunchdSupport returns an error if launchd is not available.
+func checkLaunchdSupport() error {
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf("launchd service is only supported on macOS")
+	}
+	return nil
+}
+
+// runLaunchctl runs the launchctl command with the arguments.
+func runLaunchctl(args ...string) error {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// IsLaunchdServiceInstalled returns true if the mieru client launchd
+// service is installed.
+func IsLaunchdServiceInstalled() bool {
+	if checkLaunchdSupport() != nil {
+		return false
+	}
+	plistPath, _, err := launchdPaths()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(plistPath)
+	return err == nil
+}
+
+// InstallLaunchdService generates the launchd plist file that runs the
+// executable as the mieru client daemon, and loads it. The daemon is started
+// immediately and at every login.
+func InstallLaunchdService(executable string) error {
+	if err := checkLaunchdSupport(); err != nil {
+		return err
+	}
+	executable, err := filepath.Abs(executable)
+	if err != nil {
+		return fmt.Errorf("filepath.Abs() failed: %w", err)
+	}
+	plistPath, logDir, err := launchdPaths()
+	if err != nil {
+		return fmt.Errorf("launchdPaths() failed: %w", err)
+	}
+	content, err := newLaunchdPlist(executable, logDir)
+	if err != nil {
+		return fmt.Errorf("newLaunchdPlist() failed: %w", err)
+	}
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return fmt.Errorf("os.MkdirAll() failed: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(plistPath), 0755); err != nil {
+		return fmt.Errorf("os.MkdirAll() failed: %w", err)
+	}
+	if IsLaunchdServiceInstalled() {
+		// Replace the existing service. It is fine if it is not loaded.
+		runLaunchctl("bootout", launchdDomain()+"/"+LaunchdLabel)
+	}
+	if err := os.WriteFile(plistPath, content, 0644); err != nil {
+		return fmt.Errorf("os.WriteFile() failed: %w", err)
+	}
+	return runLaunchctl("bootstrap", launchdDomain(), plistPath)
+}
+
+// UninstallLaunchdService stops the mieru client launchd service and
+// removes the plist file.
+func UninstallLaunchdService() error {
+	if err := checkLaunchdSupport(); err != nil {
+		return err
+	}
+	if !IsLaunchdServiceInstalled() {
+		return fmt.Errorf("launchd service %s is not installed", LaunchdLabel)
+	}
+	plistPath, _, err := launchdPaths()
+	if err != nil {
+		return fmt.Errorf("launchdPaths() failed: %w", err)
+	}
+	// The service may be already unloaded.
+	runLaunchctl("bootout", launchdDomain()+"/"+LaunchdLabel)
+	if err := os.Remove(plistPath); err != nil {
+		return fmt.Errorf("os.Remove() failed: %w", err)
+	}
+	return nil
+}
+
+// StartLaunchdService asks launchd to start the mieru client daemon.
+func StartLaunchdService() error {
+	if err := checkLaunchdSupport(); err != nil {
+		return err
+	}
+	return runLaunchctl("kickstart", launchdDomain()+"/"+LaunchdLabel)
+}
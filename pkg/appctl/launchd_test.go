@@ -0,0 +1,54 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewLaunchdPlist(t *testing.T) {
+	content, err := newLaunchdPlist("/Applications/mieru & co/mieru", "/Users/a/Library/Logs/mieru")
+	if err != nil {
+		t.Fatalf("newLaunchdPlist() failed: %v", err)
+	}
+	plist := string(content)
+	for _, want := range []string{
+		"<string>" + LaunchdLabel + "</string>",
+		"<string>/Applications/mieru &amp; co/mieru</string>",
+		"<string>run</string>",
+		"<key>KeepAlive</key>",
+		"<string>/Users/a/Library/Logs/mieru/stdout.log</string>",
+		"<string>/Users/a/Library/Logs/mieru/stderr.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist doesn't contain %q", want)
+		}
+	}
+
+	// The plist must be a well formed XML document.
+	decoder := xml.NewDecoder(strings.NewReader(plist))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("plist is not valid XML: %v", err)
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/logship"
+)
+
+// validateLogShipping checks the log shipping settings.
+func validateLogShipping(l *pb.LogShipping) error {
+	if l == nil {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(l.GetAddress())
+	if err != nil {
+		return fmt.Errorf("log shipping: invalid address %q: %w", l.GetAddress(), err)
+	}
+	if host == "" {
+		return fmt.Errorf("log shipping: host of address %q is empty", l.GetAddress())
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("log shipping: port number of address %q is invalid", l.GetAddress())
+	}
+	if l.QueueSize != nil && l.GetQueueSize() <= 0 {
+		return fmt.Errorf("log shipping: queue size %d is invalid", l.GetQueueSize())
+	}
+	return nil
+}
+
+// StartLogShipping starts shipping log entries to the collector.
+// It does nothing if log shipping is not configured.
+func StartLogShipping(l *pb.LogShipping, source string) error {
+	if l.GetAddress() == "" {
+		return nil
+	}
+	if err := validateLogShipping(l); err != nil {
+		return err
+	}
+	tlsConfig, err := logship.NewTLSConfig(l.GetServerName(), l.GetCaFile())
+	if err != nil {
+		return fmt.Errorf("log shipping: %w", err)
+	}
+	level := log.InfoLevel
+	if l.GetLevel() != pb.LoggingLevel_DEFAULT {
+		// Values of the two enums are aligned.
+		level = log.Level(l.GetLevel())
+	}
+	return logship.Enable(logship.Config{
+		Address:    l.GetAddress(),
+		TLSConfig:  tlsConfig,
+		Level:      level,
+		QueueSize:  int(l.GetQueueSize()),
+		DropOldest: l.GetDropPolicy() == pb.LogDropPolicy_LOG_DROP_OLDEST,
+		Source:     source,
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxLogLevel returns the least severe log level requested.
+func maxLogLevel(level pb.LoggingLevel) log.Level {
+	if level == pb.LoggingLevel_DEFAULT {
+		return log.TraceLevel
+	}
+	// Values of the two enums are aligned.
+	return log.Level(level)
+}
+
+func toLogLine(line log.RecentLine) *pb.LogLine {
+	return &pb.LogLine{
+		TimeUnixMilli: proto.Int64(line.Time.UnixMilli()),
+		Level:         pb.LoggingLevel(line.Level).Enum(),
+		Text:          proto.String(line.Text),
+	}
+}
+
+// filterLogLines returns up to n most recent log lines at or more severe
+// than the max level. If n is not positive, all the matched lines are returned.
+func filterLogLines(lines []log.RecentLine, maxLevel log.Level, n int) []log.RecentLine {
+	res := make([]log.RecentLine, 0, len(lines))
+	for _, line := range lines {
+		if line.Level <= maxLevel {
+			res = append(res, line)
+		}
+	}
+	if n > 0 && len(res) > n {
+		res = res[len(res)-n:]
+	}
+	return res
+}
+
+// streamLogs sends recent log lines of this process.
+// If follow is requested, new log lines are sent until the context is done.
+func streamLogs(ctx context.Context, req *pb.GetLogsRequest, send func(*pb.LogLine) error) error {
+	maxLevel := maxLogLevel(req.GetLevel())
+	var lines []log.RecentLine
+	var ch <-chan log.RecentLine
+	if req.GetFollow() {
+		var cancel func()
+		lines, ch, cancel = log.SubscribeRecentLines()
+		defer cancel()
+	} else {
+		lines = log.RecentLines()
+	}
+	for _, line := range filterLogLines(lines, maxLevel, int(req.GetLines())) {
+		if err := send(toLogLine(line)); err != nil {
+			return err
+		}
+	}
+	if ch == nil {
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line := <-ch:
+			if line.Level > maxLevel {
+				continue
+			}
+			if err := send(toLogLine(line)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// setLoggingLevel changes the logging level of this process.
+// It returns the new logging level.
+func setLoggingLevel(req *pb.SetLoggingLevelRequest) pb.LoggingLevel {
+	level := req.GetLevel()
+	if level == pb.LoggingLevel_DEFAULT {
+		level = pb.LoggingLevel_INFO
+	}
+	log.SetLevel(level.String())
+	log.Infof("logging level is changed to %s by RPC caller", level.String())
+	return level
+}
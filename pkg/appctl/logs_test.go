@@ -0,0 +1,36 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+)
+
+func TestSetLoggingLevel(t *testing.T) {
+	defer log.SetLevel("INFO")
+
+	setLoggingLevel(&pb.SetLoggingLevelRequest{Level: pb.LoggingLevel_DEBUG.Enum()})
+	if got := log.GetLevel(); got != log.DebugLevel {
+		t.Errorf("log.GetLevel() = %v, want %v", got, log.DebugLevel)
+	}
+	setLoggingLevel(&pb.SetLoggingLevelRequest{})
+	if got := log.GetLevel(); got != log.InfoLevel {
+		t.Errorf("log.GetLevel() = %v, want %v", got, log.InfoLevel)
+	}
+}
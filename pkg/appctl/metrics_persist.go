@@ -0,0 +1,42 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/enfein/mieru/pkg/metrics"
+)
+
+// metricsFileName is the name of file that stores the counters,
+// in the same directory as the config file.
+const metricsFileName = "metrics.pb"
+
+// StartServerMetricsPersistence restores server counters from disk,
+// and saves them periodically.
+func StartServerMetricsPersistence() error {
+	return metrics.StartPersistence(filepath.Join(cachedServerConfigDir, metricsFileName), 0)
+}
+
+// StartClientMetricsPersistence restores client counters from disk,
+// and saves them periodically.
+func StartClientMetricsPersistence() error {
+	if err := prepareClientConfigDir(); err != nil {
+		return fmt.Errorf("prepareClientConfigDir() failed: %w", err)
+	}
+	return metrics.StartPersistence(filepath.Join(cachedClientConfigDir, metricsFileName), 0)
+}
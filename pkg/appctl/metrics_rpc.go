@@ -0,0 +1,100 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/metrics"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultMetricsWatchInterval is the default interval to send metrics updates.
+const defaultMetricsWatchInterval = 5 * time.Second
+
+// metricsHistory returns the metrics history requested by the RPC caller.
+func metricsHistory(req *pb.GetMetricsHistoryRequest) *pb.MetricsHistory {
+	samples := metrics.History(time.Duration(req.GetDurationSeconds()) * time.Second)
+	res := &pb.MetricsHistory{}
+	for _, s := range samples {
+		res.Samples = append(res.Samples, &pb.MetricsSample{
+			TimeUnixMilli:  proto.Int64(s.Time.UnixMilli()),
+			InBytes:        proto.Int64(s.InBytes),
+			OutBytes:       proto.Int64(s.OutBytes),
+			ActiveSessions: proto.Int64(s.ActiveSessions),
+			Errors:         proto.Int64(s.Errors),
+		})
+	}
+	return res
+}
+
+// watchMetrics sends the changes of metrics at every interval
+// until ctx is done or send fails.
+func watchMetrics(ctx context.Context, req *pb.WatchMetricsRequest, send func(*pb.MetricsUpdate) error) error {
+	interval := defaultMetricsWatchInterval
+	if req.GetIntervalSeconds() > 0 {
+		interval = time.Duration(req.GetIntervalSeconds()) * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last map[string]int64
+	for {
+		first := last == nil
+		var update *pb.MetricsUpdate
+		update, last = metricsUpdate(metrics.Snapshot(), last)
+		if first || len(update.GetMetrics()) > 0 {
+			if err := send(update); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// metricsUpdate returns the metrics changed since the previous values,
+// and the current values. If previous values are nil, all the metrics
+// are returned.
+func metricsUpdate(snapshot []metrics.MetricValue, last map[string]int64) (*pb.MetricsUpdate, map[string]int64) {
+	update := &pb.MetricsUpdate{
+		TimeUnixMilli: proto.Int64(time.Now().UnixMilli()),
+	}
+	current := make(map[string]int64, len(snapshot))
+	for _, m := range snapshot {
+		key := m.Group + "/" + m.Name
+		current[key] = m.Value
+		var delta int64
+		if last != nil {
+			prev, found := last[key]
+			if found && prev == m.Value {
+				continue
+			}
+			delta = m.Value - prev
+		}
+		update.Metrics = append(update.Metrics, &pb.MetricDelta{
+			Group: proto.String(m.Group),
+			Name:  proto.String(m.Name),
+			Value: proto.Int64(m.Value),
+			Delta: proto.Int64(delta),
+		})
+	}
+	return update, current
+}
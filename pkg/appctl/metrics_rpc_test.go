@@ -0,0 +1,51 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"testing"
+
+	"github.com/enfein/mieru/pkg/metrics"
+)
+
+func TestMetricsUpdate(t *testing.T) {
+	snapshot := []metrics.MetricValue{
+		{Group: "traffic", Name: "InBytes", Type: metrics.COUNTER, Value: 100},
+		{Group: "traffic", Name: "OutBytes", Type: metrics.COUNTER, Value: 200},
+	}
+	update, last := metricsUpdate(snapshot, nil)
+	if len(update.GetMetrics()) != 2 {
+		t.Fatalf("first update has %d metrics, want 2", len(update.GetMetrics()))
+	}
+	for _, m := range update.GetMetrics() {
+		if m.GetDelta() != 0 {
+			t.Errorf("metric %s/%s has delta %d in the first update, want 0", m.GetGroup(), m.GetName(), m.GetDelta())
+		}
+	}
+
+	snapshot[1].Value = 250
+	snapshot = append(snapshot, metrics.MetricValue{Group: "user - a", Name: "ReadBytes", Type: metrics.COUNTER_TIME_SERIES, Value: 7})
+	update, _ = metricsUpdate(snapshot, last)
+	if len(update.GetMetrics()) != 2 {
+		t.Fatalf("second update has %d metrics, want 2", len(update.GetMetrics()))
+	}
+	if m := update.GetMetrics()[0]; m.GetName() != "OutBytes" || m.GetValue() != 250 || m.GetDelta() != 50 {
+		t.Errorf("got %v, want OutBytes with value 250 and delta 50", m)
+	}
+	if m := update.GetMetrics()[1]; m.GetName() != "ReadBytes" || m.GetValue() != 7 || m.GetDelta() != 7 {
+		t.Errorf("got %v, want ReadBytes with value 7 and delta 7", m)
+	}
+}
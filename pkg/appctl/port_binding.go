@@ -20,39 +20,63 @@ import (
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 
 	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/util"
 	"google.golang.org/protobuf/proto"
 )
 
 var (
 	validPortRange = regexp.MustCompile(`^(\d+)-(\d+)$`)
+
+	// portBindingLock serializes the changes to the port bindings.
+	portBindingLock sync.Mutex
 )
 
 // FlatPortBindings checks port bindings and convert port range to a list of ports.
+// The mimicry of each port is preserved. It is an error to use different
+// mimicry on the same port.
 func FlatPortBindings(bindings []*pb.PortBinding) ([]*pb.PortBinding, error) {
 	res := make([]*pb.PortBinding, 0)
 	if len(bindings) == 0 {
 		return res, nil
 	}
-	tcp := make(map[int32]struct{})
-	udp := make(map[int32]struct{})
+	tcp := make(map[int32]pb.Mimicry)
+	udp := make(map[int32]pb.Mimicry)
+	addPort := func(ports map[int32]pb.Mimicry, port int32, mimicry pb.Mimicry) error {
+		if existing, ok := ports[port]; ok && existing != mimicry {
+			return fmt.Errorf("port %d has conflicting mimicry %s and %s", port, existing.String(), mimicry.String())
+		}
+		ports[port] = mimicry
+		return nil
+	}
 	for _, binding := range bindings {
 		if binding.GetProtocol() == pb.TransportProtocol_UNKNOWN_TRANSPORT_PROTOCOL {
 			return res, fmt.Errorf("protocol is not set")
 		}
+		if err := validateMimicry(binding.GetProtocol(), binding.GetMimicry()); err != nil {
+			return res, err
+		}
 		if binding.GetPort() != 0 {
 			if binding.GetPort() < 1 || binding.GetPort() > 65535 {
 				return res, fmt.Errorf("port number %d is invalid", binding.GetPort())
 			}
+			var err error
 			switch binding.GetProtocol() {
 			case pb.TransportProtocol_TCP:
-				tcp[binding.GetPort()] = struct{}{}
+				err = addPort(tcp, binding.GetPort(), binding.GetMimicry())
 			case pb.TransportProtocol_UDP:
-				udp[binding.GetPort()] = struct{}{}
+				err = addPort(udp, binding.GetPort(), binding.GetMimicry())
 			default:
 				return res, fmt.Errorf("unknown protocol %s", binding.GetProtocol().String())
 			}
+			if err != nil {
+				return res, err
+			}
 		} else {
 			matches := validPortRange.FindStringSubmatch(binding.GetPortRange())
 			if len(matches) != 3 {
@@ -75,18 +99,20 @@ func FlatPortBindings(bindings []*pb.PortBinding) ([]*pb.PortBinding, error) {
 			if small > big {
 				return res, fmt.Errorf("begin of port range %d is bigger than end of port range %d", small, big)
 			}
+			var ports map[int32]pb.Mimicry
 			switch binding.GetProtocol() {
 			case pb.TransportProtocol_TCP:
-				for i := small; i <= big; i++ {
-					tcp[int32(i)] = struct{}{}
-				}
+				ports = tcp
 			case pb.TransportProtocol_UDP:
-				for i := small; i <= big; i++ {
-					udp[int32(i)] = struct{}{}
-				}
+				ports = udp
 			default:
 				return res, fmt.Errorf("unknown protocol %s", binding.GetProtocol().String())
 			}
+			for i := small; i <= big; i++ {
+				if err := addPort(ports, int32(i), binding.GetMimicry()); err != nil {
+					return res, err
+				}
+			}
 		}
 	}
 	tcpList := make([]int32, 0)
@@ -100,16 +126,228 @@ func FlatPortBindings(bindings []*pb.PortBinding) ([]*pb.PortBinding, error) {
 	sort.Slice(tcpList, func(i, j int) bool { return tcpList[i] < tcpList[j] })
 	sort.Slice(udpList, func(i, j int) bool { return udpList[i] < udpList[j] })
 	for _, port := range tcpList {
-		res = append(res, &pb.PortBinding{
+		binding := &pb.PortBinding{
 			Port:     proto.Int32(port),
 			Protocol: pb.TransportProtocol_TCP.Enum(),
-		})
+		}
+		if tcp[port] != pb.Mimicry_MIMICRY_PLAIN {
+			binding.Mimicry = tcp[port].Enum()
+		}
+		res = append(res, binding)
 	}
 	for _, port := range udpList {
-		res = append(res, &pb.PortBinding{
+		binding := &pb.PortBinding{
 			Port:     proto.Int32(port),
 			Protocol: pb.TransportProtocol_UDP.Enum(),
-		})
+		}
+		if udp[port] != pb.Mimicry_MIMICRY_PLAIN {
+			binding.Mimicry = udp[port].Enum()
+		}
+		res = append(res, binding)
 	}
 	return res, nil
 }
+
+// validateMimicry returns an error if the mimicry can't be used with
+// the transport protocol.
+func validateMimicry(protocol pb.TransportProtocol, mimicry pb.Mimicry) error {
+	switch mimicry {
+	case pb.Mimicry_MIMICRY_PLAIN:
+		return nil
+	case pb.Mimicry_MIMICRY_TLS, pb.Mimicry_MIMICRY_WEBSOCKET:
+		if protocol != pb.TransportProtocol_TCP {
+			return fmt.Errorf("mimicry %s requires TCP protocol", mimicry.String())
+		}
+		return nil
+	case pb.Mimicry_MIMICRY_DNS:
+		if protocol != pb.TransportProtocol_UDP {
+			return fmt.Errorf("mimicry %s requires UDP protocol", mimicry.String())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown mimicry %d", mimicry)
+	}
+}
+
+// UnderlayMimicry converts the mimicry in the config to the one used by underlay.
+func UnderlayMimicry(mimicry pb.Mimicry) protocolv2.Mimicry {
+	switch mimicry {
+	case pb.Mimicry_MIMICRY_TLS:
+		return protocolv2.MimicryTLS
+	case pb.Mimicry_MIMICRY_WEBSOCKET:
+		return protocolv2.MimicryWebSocket
+	case pb.Mimicry_MIMICRY_DNS:
+		return protocolv2.MimicryDNS
+	default:
+		return protocolv2.MimicryPlain
+	}
+}
+
+// ParsePortBinding parses a port binding like "443/tcp" or "2012-2022/udp".
+func ParsePortBinding(s string) (*pb.PortBinding, error) {
+	ports, protocol, found := strings.Cut(s, "/")
+	if !found {
+		return nil, fmt.Errorf("port binding %q is not in <PORT>/<PROTOCOL> format", s)
+	}
+	binding := &pb.PortBinding{}
+	switch strings.ToUpper(protocol) {
+	case "TCP":
+		binding.Protocol = pb.TransportProtocol_TCP.Enum()
+	case "UDP":
+		binding.Protocol = pb.TransportProtocol_UDP.Enum()
+	default:
+		return nil, fmt.Errorf("protocol %q is not TCP or UDP", protocol)
+	}
+	if strings.Contains(ports, "-") {
+		binding.PortRange = proto.String(ports)
+	} else {
+		port, err := strconv.Atoi(ports)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse port %q", ports)
+		}
+		binding.Port = proto.Int32(int32(port))
+	}
+	if _, err := FlatPortBindings([]*pb.PortBinding{binding}); err != nil {
+		return nil, err
+	}
+	return binding, nil
+}
+
+// updatePortBinding adds the port binding to server config, or removes
+// it from server config. If proxy is running, the server starts or stops
+// listening to the ports before the config is changed.
+func updatePortBinding(binding *pb.PortBinding, add bool) error {
+	portBindingLock.Lock()
+	defer portBindingLock.Unlock()
+
+	ports, err := FlatPortBindings([]*pb.PortBinding{binding})
+	if err != nil {
+		return err
+	}
+	if len(ports) == 0 {
+		return fmt.Errorf("port binding is empty")
+	}
+	config, err := LoadServerConfig()
+	if err != nil {
+		return fmt.Errorf("LoadServerConfig() failed: %w", err)
+	}
+	existing, err := FlatPortBindings(config.GetPortBindings())
+	if err != nil {
+		return err
+	}
+	bound := make(map[string]bool)
+	for _, b := range existing {
+		bound[portBindingKey(b)] = true
+	}
+
+	var bindings []*pb.PortBinding
+	if add {
+		for _, p := range ports {
+			if bound[portBindingKey(p)] {
+				return fmt.Errorf("port %s is already bound", portBindingKey(p))
+			}
+		}
+		bindings = append(config.GetPortBindings(), binding)
+		if err := checkPortRotationOverlap(bindings, config.GetPortRotation()); err != nil {
+			return err
+		}
+	} else {
+		removed := make(map[string]bool)
+		for _, p := range ports {
+			if !bound[portBindingKey(p)] {
+				return fmt.Errorf("port %s is not bound", portBindingKey(p))
+			}
+			removed[portBindingKey(p)] = true
+		}
+		bindings = removePorts(config.GetPortBindings(), removed)
+		if len(bindings) == 0 && config.GetPortRotation() == nil {
+			return fmt.Errorf("unable to delete the last port binding")
+		}
+	}
+
+	if mux := serverMuxRef.Load(); mux != nil && GetAppStatus() == pb.AppStatus_RUNNING {
+		mtu := util.DefaultMTU
+		if config.GetMtu() != 0 {
+			mtu = int(config.GetMtu())
+		}
+		endpoints, err := PortBindingsToUnderlayProperties(ports, mtu)
+		if err != nil {
+			return err
+		}
+		for i, endpoint := range endpoints {
+			if add {
+				if err := mux.AddEndpoint(endpoint); err != nil {
+					for _, added := range endpoints[:i] {
+						mux.RemoveEndpoint(added)
+					}
+					return fmt.Errorf("AddEndpoint() failed: %w", err)
+				}
+			} else if err := mux.RemoveEndpoint(endpoint); err != nil {
+				// The port may not be listened if config is not reloaded.
+				log.Debugf("RemoveEndpoint() failed: %v", err)
+			}
+		}
+	}
+
+	config.PortBindings = bindings
+	if err := StoreServerConfig(config); err != nil {
+		return fmt.Errorf("StoreServerConfig() failed: %w", err)
+	}
+	return nil
+}
+
+// portBindingKey returns the port and protocol of a single port binding,
+// like "443/TCP".
+func portBindingKey(binding *pb.PortBinding) string {
+	return fmt.Sprintf("%d/%s", binding.GetPort(), binding.GetProtocol().String())
+}
+
+// portBindingString returns the port or port range and protocol of
+// a port binding, like "2012-2022/TCP".
+func portBindingString(binding *pb.PortBinding) string {
+	if binding.GetPort() != 0 {
+		return portBindingKey(binding)
+	}
+	return binding.GetPortRange() + "/" + binding.GetProtocol().String()
+}
+
+// removePorts returns the port bindings without the removed ports.
+// A port range is split if some ports in the range are removed.
+func removePorts(bindings []*pb.PortBinding, removed map[string]bool) []*pb.PortBinding {
+	res := make([]*pb.PortBinding, 0, len(bindings))
+	for _, binding := range bindings {
+		flat, err := FlatPortBindings([]*pb.PortBinding{binding})
+		if err != nil {
+			res = append(res, binding)
+			continue
+		}
+		kept := make([]*pb.PortBinding, 0, len(flat))
+		for _, p := range flat {
+			if !removed[portBindingKey(p)] {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == len(flat) {
+			res = append(res, binding)
+			continue
+		}
+		// Merge the remaining consecutive ports into port ranges.
+		for i := 0; i < len(kept); {
+			j := i
+			for j+1 < len(kept) && kept[j+1].GetPort() == kept[j].GetPort()+1 {
+				j++
+			}
+			b := proto.Clone(binding).(*pb.PortBinding)
+			if i == j {
+				b.Port = proto.Int32(kept[i].GetPort())
+				b.PortRange = nil
+			} else {
+				b.Port = nil
+				b.PortRange = proto.String(fmt.Sprintf("%d-%d", kept[i].GetPort(), kept[j].GetPort()))
+			}
+			res = append(res, b)
+			i = j + 1
+		}
+	}
+	return res
+}
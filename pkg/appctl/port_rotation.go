@@ -0,0 +1,276 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	minPortRotationIntervalMinutes = 1
+	maxPortRotationIntervalMinutes = 7 * 24 * 60
+
+	// PortRotationCheckInterval is the interval to check if the
+	// active port of port rotation is changed.
+	PortRotationCheckInterval = 30 * time.Second
+)
+
+// portRotation holds the rotated ports the server mux is listening to.
+var portRotation struct {
+	mu        sync.Mutex
+	stop      chan struct{}
+	listening map[string]*pb.PortBinding
+}
+
+// validatePortRotation checks the port rotation, if it is set.
+func validatePortRotation(rotation *pb.PortRotation) error {
+	if rotation == nil {
+		return nil
+	}
+	if rotation.GetProtocol() != pb.TransportProtocol_TCP && rotation.GetProtocol() != pb.TransportProtocol_UDP {
+		return fmt.Errorf("port rotation: protocol must be TCP or UDP")
+	}
+	if _, err := FlatPortBindings([]*pb.PortBinding{{PortRange: rotation.PortRange, Protocol: rotation.Protocol}}); err != nil {
+		return fmt.Errorf("port rotation: %w", err)
+	}
+	if rotation.GetIntervalMinutes() < minPortRotationIntervalMinutes || rotation.GetIntervalMinutes() > maxPortRotationIntervalMinutes {
+		return fmt.Errorf("port rotation: interval %d minutes is not between %d and %d", rotation.GetIntervalMinutes(), minPortRotationIntervalMinutes, maxPortRotationIntervalMinutes)
+	}
+	if rotation.GetSecret() == "" {
+		return fmt.Errorf("port rotation: secret is not set")
+	}
+	if isRedactedSecret(rotation.GetSecret()) {
+		return fmt.Errorf("port rotation: secret is redacted")
+	}
+	return nil
+}
+
+// portRotationRange returns the smallest and the biggest port
+// of the port rotation.
+func portRotationRange(rotation *pb.PortRotation) (small, big int, ok bool) {
+	matches := validPortRange.FindStringSubmatch(rotation.GetPortRange())
+	if len(matches) != 3 {
+		return 0, 0, false
+	}
+	small, _ = strconv.Atoi(matches[1])
+	big, _ = strconv.Atoi(matches[2])
+	if small > big {
+		return 0, 0, false
+	}
+	return small, big, true
+}
+
+// checkPortRotationOverlap returns an error if any of the port bindings
+// is in the port range of port rotation.
+func checkPortRotationOverlap(bindings []*pb.PortBinding, rotation *pb.PortRotation) error {
+	if rotation == nil {
+		return nil
+	}
+	small, big, ok := portRotationRange(rotation)
+	if !ok {
+		return nil
+	}
+	flat, err := FlatPortBindings(bindings)
+	if err != nil {
+		return err
+	}
+	for _, binding := range flat {
+		if binding.GetProtocol() == rotation.GetProtocol() && int(binding.GetPort()) >= small && int(binding.GetPort()) <= big {
+			return fmt.Errorf("port %d is in the port range of port rotation", binding.GetPort())
+		}
+	}
+	return nil
+}
+
+// RotatedPort returns the active port of the port rotation at time t.
+// The port is computed from the secret and the number of intervals
+// since the Unix epoch, so server and clients get the same port
+// without any communication.
+func RotatedPort(rotation *pb.PortRotation, t time.Time) int32 {
+	interval := int64(rotation.GetIntervalMinutes()) * 60
+	if interval <= 0 {
+		return 0
+	}
+	return rotatedPortAt(rotation, t.Unix()/interval)
+}
+
+func rotatedPortAt(rotation *pb.PortRotation, epoch int64) int32 {
+	small, big, ok := portRotationRange(rotation)
+	if !ok {
+		return 0
+	}
+	mac := hmac.New(sha256.New, []byte(rotation.GetSecret()))
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(epoch))
+	mac.Write(b[:])
+	sum := mac.Sum(nil)
+	n := binary.BigEndian.Uint64(sum[:8]) % uint64(big-small+1)
+	return int32(small) + int32(n)
+}
+
+// RotatedPortBindings returns the port bindings the server listens to
+// at time t. Besides the active port, the ports of the previous and
+// the next interval are also listened, so clients with a small clock
+// skew can connect.
+func RotatedPortBindings(rotation *pb.PortRotation, t time.Time) []*pb.PortBinding {
+	interval := int64(rotation.GetIntervalMinutes()) * 60
+	if interval <= 0 {
+		return nil
+	}
+	epoch := t.Unix() / interval
+	res := make([]*pb.PortBinding, 0, 3)
+	seen := make(map[int32]bool)
+	for _, e := range []int64{epoch - 1, epoch, epoch + 1} {
+		port := rotatedPortAt(rotation, e)
+		if port == 0 || seen[port] {
+			continue
+		}
+		seen[port] = true
+		res = append(res, &pb.PortBinding{
+			Port:     proto.Int32(port),
+			Protocol: rotation.GetProtocol().Enum(),
+		})
+	}
+	return res
+}
+
+// ServerEndpointPortBindings returns the port bindings of the server
+// endpoint, including the active port of port rotation at time t.
+func ServerEndpointPortBindings(server *pb.ServerEndpoint, t time.Time) []*pb.PortBinding {
+	rotation := server.GetPortRotation()
+	if rotation == nil {
+		return server.GetPortBindings()
+	}
+	bindings := append([]*pb.PortBinding{}, server.GetPortBindings()...)
+	if port := RotatedPort(rotation, t); port != 0 {
+		bindings = append(bindings, &pb.PortBinding{
+			Port:     proto.Int32(port),
+			Protocol: rotation.GetProtocol().Enum(),
+		})
+	}
+	return bindings
+}
+
+// ServerPortBindings returns the port bindings the server listens to
+// at time t, including the rotated ports.
+func ServerPortBindings(config *pb.ServerConfig, t time.Time) []*pb.PortBinding {
+	if config.GetPortRotation() == nil {
+		return config.GetPortBindings()
+	}
+	bindings := append([]*pb.PortBinding{}, config.GetPortBindings()...)
+	return append(bindings, RotatedPortBindings(config.GetPortRotation(), t)...)
+}
+
+// StartPortRotation changes the rotated ports listened by the server mux
+// in the background. listening are the rotated ports the mux is already
+// listening to. The previous port rotation is stopped, and the ports
+// it listened to are closed unless they are still in listening.
+func StartPortRotation(mux *protocolv2.Mux, rotation *pb.PortRotation, listening []*pb.PortBinding, mtu int) {
+	portRotation.mu.Lock()
+	defer portRotation.mu.Unlock()
+	if portRotation.stop != nil {
+		close(portRotation.stop)
+		portRotation.stop = nil
+	}
+	previous := portRotation.listening
+	portRotation.listening = make(map[string]*pb.PortBinding)
+	for _, binding := range listening {
+		portRotation.listening[portBindingKey(binding)] = binding
+	}
+	for key, binding := range previous {
+		if _, ok := portRotation.listening[key]; !ok {
+			stopListening(mux, key, binding, mtu)
+		}
+	}
+	if rotation == nil {
+		return
+	}
+	stop := make(chan struct{})
+	portRotation.stop = stop
+	go func() {
+		ticker := time.NewTicker(PortRotationCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				rotatePorts(mux, rotation, mtu, stop)
+			}
+		}
+	}()
+}
+
+// rotatePorts starts listening to the rotated ports of the current time,
+// and stops listening to the rotated ports that are expired.
+func rotatePorts(mux *protocolv2.Mux, rotation *pb.PortRotation, mtu int, stop chan struct{}) {
+	portRotation.mu.Lock()
+	defer portRotation.mu.Unlock()
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	wanted := make(map[string]*pb.PortBinding)
+	for _, binding := range RotatedPortBindings(rotation, time.Now()) {
+		wanted[portBindingKey(binding)] = binding
+	}
+	for key, binding := range wanted {
+		if _, ok := portRotation.listening[key]; ok {
+			continue
+		}
+		endpoints, err := PortBindingsToUnderlayProperties([]*pb.PortBinding{binding}, mtu)
+		if err != nil || len(endpoints) != 1 {
+			log.Errorf("port rotation: unable to listen to port %s: %v", key, err)
+			continue
+		}
+		if err := mux.AddEndpoint(endpoints[0]); err != nil {
+			log.Errorf("port rotation: unable to listen to port %s: %v", key, err)
+			continue
+		}
+		log.Infof("port rotation: started listening to port %s", key)
+		portRotation.listening[key] = binding
+	}
+	for key, binding := range portRotation.listening {
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+		stopListening(mux, key, binding, mtu)
+		delete(portRotation.listening, key)
+	}
+}
+
+// stopListening closes the rotated port listened by the server mux.
+func stopListening(mux *protocolv2.Mux, key string, binding *pb.PortBinding, mtu int) {
+	endpoints, err := PortBindingsToUnderlayProperties([]*pb.PortBinding{binding}, mtu)
+	if err == nil && len(endpoints) == 1 {
+		if err := mux.RemoveEndpoint(endpoints[0]); err != nil {
+			log.Debugf("port rotation: RemoveEndpoint() failed: %v", err)
+		}
+	}
+	log.Infof("port rotation: stopped listening to port %s", key)
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRotatedPort(t *testing.T) {
+	rotation := &pb.PortRotation{
+		PortRange:       proto.String("20000-20099"),
+		Protocol:        pb.TransportProtocol_TCP.Enum(),
+		IntervalMinutes: proto.Int32(10),
+		Secret:          proto.String("62c5a0bb09c8"),
+	}
+	if err := validatePortRotation(rotation); err != nil {
+		t.Fatalf("validatePortRotation() failed: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	port := RotatedPort(rotation, now)
+	if port < 20000 || port > 20099 {
+		t.Errorf("rotated port %d is out of range", port)
+	}
+	// Server and client get the same port within the same interval.
+	start := now.Truncate(10 * time.Minute)
+	if got := RotatedPort(rotation, start); got != port {
+		t.Errorf("RotatedPort() at %v = %d, want %d", start, got, port)
+	}
+	if got := RotatedPort(rotation, start.Add(10*time.Minute-time.Second)); got != port {
+		t.Errorf("RotatedPort() at the end of interval = %d, want %d", got, port)
+	}
+
+	other := proto.Clone(rotation).(*pb.PortRotation)
+	other.Secret = proto.String("0ba8f3a93a36")
+	different := false
+	for i := 0; i < 10; i++ {
+		tm := now.Add(time.Duration(i) * 10 * time.Minute)
+		if RotatedPort(rotation, tm) != RotatedPort(other, tm) {
+			different = true
+		}
+	}
+	if !different {
+		t.Errorf("rotated ports are the same with different secrets")
+	}
+
+	bindings := RotatedPortBindings(rotation, now)
+	if len(bindings) == 0 || len(bindings) > 3 {
+		t.Fatalf("got %d rotated port bindings, want 1 to 3", len(bindings))
+	}
+	found := false
+	for _, binding := range bindings {
+		if binding.GetPort() == port && binding.GetProtocol() == pb.TransportProtocol_TCP {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("active port %d is not in rotated port bindings %v", port, bindings)
+	}
+}
+
+func TestCheckPortRotationOverlap(t *testing.T) {
+	rotation := &pb.PortRotation{
+		PortRange: proto.String("20000-20099"),
+		Protocol:  pb.TransportProtocol_TCP.Enum(),
+	}
+	udp := []*pb.PortBinding{{Port: proto.Int32(20050), Protocol: pb.TransportProtocol_UDP.Enum()}}
+	if err := checkPortRotationOverlap(udp, rotation); err != nil {
+		t.Errorf("checkPortRotationOverlap() failed: %v", err)
+	}
+	tcp := []*pb.PortBinding{{PortRange: proto.String("19990-20000"), Protocol: pb.TransportProtocol_TCP.Enum()}}
+	if err := checkPortRotationOverlap(tcp, rotation); err == nil {
+		t.Errorf("checkPortRotationOverlap() returned nil, want error")
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// droppedPrivilege is the privilege settings applied to the process.
+	// It is nil if privileges are not dropped. It is not possible to
+	// get the privileges back.
+	droppedPrivilege *pb.ServerPrivilege
+
+	privilegeMu sync.Mutex
+)
+
+// validateServerPrivilege checks the privilege settings without
+// looking up the user and group.
+func validateServerPrivilege(privilege *pb.ServerPrivilege) error {
+	if privilege == nil {
+		return nil
+	}
+	if privilege.GetGroup() != "" && privilege.GetUser() == "" {
+		return fmt.Errorf("privilege: group is set but user is not set")
+	}
+	if privilege.Chroot != nil && !filepath.IsAbs(privilege.GetChroot()) {
+		return fmt.Errorf("privilege: chroot directory %q is not an absolute path", privilege.GetChroot())
+	}
+	return nil
+}
+
+// lookupServerPrivilege returns the user ID and group ID to run the server.
+func lookupServerPrivilege(privilege *pb.ServerPrivilege) (uid, gid int, err error) {
+	u, err := user.Lookup(privilege.GetUser())
+	if err != nil {
+		return 0, 0, fmt.Errorf("look up user %q failed: %w", privilege.GetUser(), err)
+	}
+	if uid, err = strconv.Atoi(u.Uid); err != nil {
+		return 0, 0, fmt.Errorf("user %q has invalid user ID %q", u.Username, u.Uid)
+	}
+	gidStr := u.Gid
+	if privilege.GetGroup() != "" {
+		g, err := user.LookupGroup(privilege.GetGroup())
+		if err != nil {
+			return 0, 0, fmt.Errorf("look up group %q failed: %w", privilege.GetGroup(), err)
+		}
+		gidStr = g.Gid
+	}
+	if gid, err = strconv.Atoi(gidStr); err != nil {
+		return 0, 0, fmt.Errorf("invalid group ID %q", gidStr)
+	}
+	return uid, gid, nil
+}
+
+// DropServerPrivileges switches the server process to the user and group,
+// and changes the root directory, as specified in the privilege settings.
+// It should be called by the server daemon once, before the proxy is
+// started. The server config directory and file are given to the new user,
+// so the config can still be loaded and stored. On Linux, the capability to
+// bind ports below 1024 is kept, so the proxy can be restarted.
+// Privileges are only dropped once; later calls do nothing.
+func DropServerPrivileges(privilege *pb.ServerPrivilege) error {
+	if privilege.GetUser() == "" && privilege.GetChroot() == "" {
+		return nil
+	}
+	if err := validateServerPrivilege(privilege); err != nil {
+		return err
+	}
+
+	privilegeMu.Lock()
+	defer privilegeMu.Unlock()
+	if droppedPrivilege != nil {
+		return nil
+	}
+
+	uid, gid := -1, -1
+	if privilege.GetUser() != "" {
+		var err error
+		if uid, gid, err = lookupServerPrivilege(privilege); err != nil {
+			return err
+		}
+	}
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("dropping privileges requires running as root")
+	}
+
+	fileName, _, err := serverConfigFilePath()
+	if err != nil {
+		return fmt.Errorf("serverConfigFilePath() failed: %w", err)
+	}
+	newFileName := fileName
+	if privilege.GetChroot() != "" {
+		if newFileName, err = pathInChroot(privilege.GetChroot(), fileName); err != nil {
+			return err
+		}
+	}
+	if uid >= 0 {
+		for _, path := range []string{filepath.Dir(fileName), fileName} {
+			if err := os.Chown(path, uid, gid); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("os.Chown(%q) failed: %w", path, err)
+			}
+		}
+	}
+
+	if err := dropPrivileges(uid, gid, privilege.GetChroot()); err != nil {
+		return err
+	}
+	if newFileName != fileName {
+		setServerConfigFilePath(newFileName)
+	}
+	droppedPrivilege = proto.Clone(privilege).(*pb.ServerPrivilege)
+	log.Infof("server privileges are dropped: user ID %d, group ID %d, root directory %q", os.Getuid(), os.Getgid(), privilege.GetChroot())
+	return nil
+}
+
+// serverPrivilegeApplied returns true if the privilege settings
+// are the same as the settings applied to the process.
+func serverPrivilegeApplied(privilege *pb.ServerPrivilege) bool {
+	privilegeMu.Lock()
+	defer privilegeMu.Unlock()
+	if privilege.GetUser() == "" && privilege.GetChroot() == "" {
+		return droppedPrivilege == nil
+	}
+	return proto.Equal(privilege, droppedPrivilege)
+}
+
+// pathInChroot returns the path of a file after the root directory
+// is changed to chroot. It returns an error if the file is not inside
+// the chroot directory.
+func pathInChroot(chroot, path string) (string, error) {
+	rel, err := filepath.Rel(filepath.Clean(chroot), filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("privilege: server config file %q is not inside chroot directory %q", path, chroot)
+	}
+	return string(filepath.Separator) + rel, nil
+}
+
+// setServerConfigFilePath changes the server config file path.
+// The environment variables are updated as well, because they
+// take precedence over the cached path.
+func setServerConfigFilePath(path string) {
+	if _, found := os.LookupEnv("MITA_CONFIG_FILE"); found {
+		os.Setenv("MITA_CONFIG_FILE", path)
+	} else if _, found := os.LookupEnv("MITA_CONFIG_JSON_FILE"); found {
+		os.Setenv("MITA_CONFIG_JSON_FILE", path)
+	}
+	cachedServerConfigFilePath = path
+	cachedServerConfigDir = filepath.Dir(path)
+}
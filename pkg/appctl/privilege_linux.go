@@ -0,0 +1,114 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// privilegeSyscalls are the system calls used to drop privileges.
+// They can be replaced in tests.
+var privilegeSyscalls = struct {
+	chroot    func(path string) error
+	chdir     func(path string) error
+	setgroups func(gids []int) error
+	setgid    func(gid int) error
+	setuid    func(uid int) error
+	keepCaps  func(keep bool) error
+	setCaps   func(caps uint32) error
+}{
+	chroot:    syscall.Chroot,
+	chdir:     syscall.Chdir,
+	setgroups: syscall.Setgroups,
+	setgid:    syscall.Setgid,
+	setuid:    syscall.Setuid,
+	keepCaps:  keepCapsAllThreads,
+	setCaps:   setCapsAllThreads,
+}
+
+// keepCapsAllThreads sets whether the permitted capabilities of all the
+// threads are kept when the user ID is changed from root to non-root.
+func keepCapsAllThreads(keep bool) error {
+	var v uintptr
+	if keep {
+		v = 1
+	}
+	if _, _, errno := syscall.AllThreadsSyscall(syscall.SYS_PRCTL, unix.PR_SET_KEEPCAPS, v, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// setCapsAllThreads sets the effective and permitted capabilities of all
+// the threads. Capabilities not in caps are removed.
+func setCapsAllThreads(caps uint32) error {
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	data := [2]unix.CapUserData{{Effective: caps, Permitted: caps}}
+	if _, _, errno := syscall.AllThreadsSyscall(syscall.SYS_CAPSET, uintptr(unsafe.Pointer(&hdr)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// dropPrivileges changes the root directory if chroot is not empty,
+// then switches to the group and user if they are not negative.
+// The capability to bind ports below 1024 is kept after switching
+// to the user. The change applies to all the threads of the process.
+//
+// The order matters: chroot requires root privileges, and the group
+// can't be changed after the user is changed.
+func dropPrivileges(uid, gid int, chroot string) error {
+	sys := privilegeSyscalls
+	if chroot != "" {
+		if err := sys.chroot(chroot); err != nil {
+			return fmt.Errorf("chroot to %q failed: %w", chroot, err)
+		}
+		if err := sys.chdir("/"); err != nil {
+			return fmt.Errorf("change directory to new root failed: %w", err)
+		}
+	}
+	if gid >= 0 {
+		if err := sys.setgroups([]int{gid}); err != nil {
+			return fmt.Errorf("set supplementary groups failed: %w", err)
+		}
+		if err := sys.setgid(gid); err != nil {
+			return fmt.Errorf("set group ID to %d failed: %w", gid, err)
+		}
+	}
+	if uid >= 0 {
+		if err := sys.keepCaps(true); err != nil {
+			return fmt.Errorf("keep capabilities failed: %w", err)
+		}
+		if err := sys.setuid(uid); err != nil {
+			return fmt.Errorf("set user ID to %d failed: %w", uid, err)
+		}
+		if err := sys.setCaps(1 << unix.CAP_NET_BIND_SERVICE); err != nil {
+			return fmt.Errorf("set capabilities failed: %w", err)
+		}
+		if err := sys.keepCaps(false); err != nil {
+			return fmt.Errorf("clear keep capabilities failed: %w", err)
+		}
+		// Make sure root privileges can't be restored.
+		if uid != 0 && sys.setuid(0) == nil {
+			return fmt.Errorf("root privileges are restored after dropping")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+// recordPrivilegeSyscalls replaces the privilege system calls with fakes
+// that record the calls. The system call named by fail returns an error.
+func recordPrivilegeSyscalls(t *testing.T, fail string) *[]string {
+	saved := privilegeSyscalls
+	t.Cleanup(func() {
+		privilegeSyscalls = saved
+	})
+
+	var calls []string
+	uid := 0
+	record := func(call string) error {
+		calls = append(calls, call)
+		if call == fail {
+			return syscall.EPERM
+		}
+		return nil
+	}
+	privilegeSyscalls.chroot = func(path string) error {
+		return record(fmt.Sprintf("chroot(%s)", path))
+	}
+	privilegeSyscalls.chdir = func(path string) error {
+		return record(fmt.Sprintf("chdir(%s)", path))
+	}
+	privilegeSyscalls.setgroups = func(gids []int) error {
+		return record(fmt.Sprintf("setgroups(%v)", gids))
+	}
+	privilegeSyscalls.setgid = func(gid int) error {
+		return record(fmt.Sprintf("setgid(%d)", gid))
+	}
+	privilegeSyscalls.setuid = func(id int) error {
+		if err := record(fmt.Sprintf("setuid(%d)", id)); err != nil {
+			return err
+		}
+		// A non-root user can't switch back to root.
+		if uid != 0 && id == 0 {
+			return syscall.EPERM
+		}
+		uid = id
+		return nil
+	}
+	privilegeSyscalls.keepCaps = func(keep bool) error {
+		return record(fmt.Sprintf("keepCaps(%v)", keep))
+	}
+	privilegeSyscalls.setCaps = func(caps uint32) error {
+		return record(fmt.Sprintf("setCaps(%#x)", caps))
+	}
+	return &calls
+}
+
+func TestDropPrivilegesOrder(t *testing.T) {
+	testCases := []struct {
+		name   string
+		uid    int
+		gid    int
+		chroot string
+		want   []string
+	}{
+		{
+			name:   "user, group and chroot",
+			uid:    1000,
+			gid:    2000,
+			chroot: "/var/empty",
+			want: []string{
+				"chroot(/var/empty)",
+				"chdir(/)",
+				"setgroups([2000])",
+				"setgid(2000)",
+				"keepCaps(true)",
+				"setuid(1000)",
+				"setCaps(0x400)",
+				"keepCaps(false)",
+				"setuid(0)",
+			},
+		},
+		{
+			name: "user and group",
+			uid:  1000,
+			gid:  2000,
+			want: []string{
+				"setgroups([2000])",
+				"setgid(2000)",
+				"keepCaps(true)",
+				"setuid(1000)",
+				"setCaps(0x400)",
+				"keepCaps(false)",
+				"setuid(0)",
+			},
+		},
+		{
+			name:   "chroot only",
+			uid:    -1,
+			gid:    -1,
+			chroot: "/var/empty",
+			want: []string{
+				"chroot(/var/empty)",
+				"chdir(/)",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := recordPrivilegeSyscalls(t, "")
+			if err := dropPrivileges(tc.uid, tc.gid, tc.chroot); err != nil {
+				t.Fatalf("dropPrivileges() failed: %v", err)
+			}
+			if !reflect.DeepEqual(*calls, tc.want) {
+				t.Errorf("got system calls %v, want %v", *calls, tc.want)
+			}
+		})
+	}
+}
+
+func TestDropPrivilegesStopOnError(t *testing.T) {
+	testCases := []struct {
+		fail string
+		want []string
+	}{
+		{
+			fail: "chroot(/var/empty)",
+			want: []string{"chroot(/var/empty)"},
+		},
+		{
+			fail: "setgid(2000)",
+			want: []string{"chroot(/var/empty)", "chdir(/)", "setgroups([2000])", "setgid(2000)"},
+		},
+		{
+			fail: "setuid(1000)",
+			want: []string{"chroot(/var/empty)", "chdir(/)", "setgroups([2000])", "setgid(2000)", "keepCaps(true)", "setuid(1000)"},
+		},
+		{
+			fail: "setCaps(0x400)",
+			want: []string{"chroot(/var/empty)", "chdir(/)", "setgroups([2000])", "setgid(2000)", "keepCaps(true)", "setuid(1000)", "setCaps(0x400)"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.fail, func(t *testing.T) {
+			calls := recordPrivilegeSyscalls(t, tc.fail)
+			err := dropPrivileges(1000, 2000, "/var/empty")
+			if !errors.Is(err, syscall.EPERM) {
+				t.Errorf("dropPrivileges() returned %v, want %v", err, syscall.EPERM)
+			}
+			if !reflect.DeepEqual(*calls, tc.want) {
+				t.Errorf("got system calls %v, want %v", *calls, tc.want)
+			}
+		})
+	}
+}
+
+func TestDropPrivilegesDetectRestoredRoot(t *testing.T) {
+	calls := recordPrivilegeSyscalls(t, "")
+	// The fake setuid always succeeds, as if root privileges were kept.
+	privilegeSyscalls.setuid = func(id int) error {
+		*calls = append(*calls, fmt.Sprintf("setuid(%d)", id))
+		return nil
+	}
+	if err := dropPrivileges(1000, 2000, ""); err == nil {
+		t.Errorf("want error when root privileges can be restored, got no error")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestValidateServerPrivilege(t *testing.T) {
+	testCases := []struct {
+		name      string
+		privilege *pb.ServerPrivilege
+		wantErr   bool
+	}{
+		{"nil", nil, false},
+		{"empty", &pb.ServerPrivilege{}, false},
+		{"user", &pb.ServerPrivilege{User: proto.String("mita")}, false},
+		{"user and group", &pb.ServerPrivilege{User: proto.String("mita"), Group: proto.String("mita")}, false},
+		{"chroot only", &pb.ServerPrivilege{Chroot: proto.String("/var/empty")}, false},
+		{"group without user", &pb.ServerPrivilege{Group: proto.String("mita")}, true},
+		{"relative chroot", &pb.ServerPrivilege{User: proto.String("mita"), Chroot: proto.String("var/empty")}, true},
+		{"empty chroot", &pb.ServerPrivilege{User: proto.String("mita"), Chroot: proto.String("")}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateServerPrivilege(tc.privilege)
+			if tc.wantErr && err == nil {
+				t.Errorf("want error, got no error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("validateServerPrivilege() failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestPathInChroot(t *testing.T) {
+	testCases := []struct {
+		chroot  string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"/var/lib/mita", "/var/lib/mita/etc/server.conf.pb", "/etc/server.conf.pb", false},
+		{"/var/lib/mita/", "/var/lib/mita/server.conf.pb", "/server.conf.pb", false},
+		{"/", "/etc/mita/server.conf.pb", "/etc/mita/server.conf.pb", false},
+		{"/var/empty", "/etc/mita/server.conf.pb", "", true},
+		{"/var/lib/mita", "/var/lib/mita2/server.conf.pb", "", true},
+		{"/var/lib/mita", "/var/lib/mita/../server.conf.pb", "", true},
+	}
+	for _, tc := range testCases {
+		got, err := pathInChroot(tc.chroot, tc.path)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("pathInChroot(%q, %q) = %q, want error", tc.chroot, tc.path, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("pathInChroot(%q, %q) failed: %v", tc.chroot, tc.path, err)
+		} else if got != tc.want {
+			t.Errorf("pathInChroot(%q, %q) = %q, want %q", tc.chroot, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestServerPrivilegeApplied(t *testing.T) {
+	defer func() {
+		droppedPrivilege = nil
+	}()
+
+	droppedPrivilege = nil
+	if !serverPrivilegeApplied(nil) {
+		t.Errorf("serverPrivilegeApplied(nil) = false, want true")
+	}
+	privilege := &pb.ServerPrivilege{User: proto.String("mita")}
+	if serverPrivilegeApplied(privilege) {
+		t.Errorf("serverPrivilegeApplied() = true before privileges are dropped, want false")
+	}
+
+	droppedPrivilege = proto.Clone(privilege).(*pb.ServerPrivilege)
+	if !serverPrivilegeApplied(privilege) {
+		t.Errorf("serverPrivilegeApplied() = false with the same settings, want true")
+	}
+	if serverPrivilegeApplied(&pb.ServerPrivilege{User: proto.String("nobody")}) {
+		t.Errorf("serverPrivilegeApplied() = true with a different user, want false")
+	}
+	if serverPrivilegeApplied(nil) {
+		t.Errorf("serverPrivilegeApplied(nil) = true after privileges are dropped, want false")
+	}
+}
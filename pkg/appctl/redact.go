@@ -0,0 +1,90 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"github.com/enfein/mieru/pkg/log"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// secretFieldNames are the names of config fields that store secrets.
+var secretFieldNames = map[protoreflect.Name]bool{
+	"password":       true,
+	"hashedPassword": true,
+	"secret":         true,
+}
+
+// RedactSecrets returns a copy of the config, where the values of
+// passwords and hashed passwords are replaced by log.RedactedSecret.
+func RedactSecrets[T proto.Message](config T) T {
+	c := proto.Clone(config).(T)
+	for _, f := range findSecretFields(c.ProtoReflect()) {
+		f.msg.Set(f.fd, protoreflect.ValueOfString(log.RedactedSecret))
+	}
+	return c
+}
+
+// registerSecrets prevents the secrets in the config from
+// appearing in log messages.
+func registerSecrets(group string, config proto.Message) {
+	var secrets []string
+	for _, f := range findSecretFields(config.ProtoReflect()) {
+		secrets = append(secrets, f.msg.Get(f.fd).String())
+	}
+	log.SetSecrets(group, secrets)
+}
+
+// isRedactedSecret returns true if the value is copied from
+// a redacted config.
+func isRedactedSecret(v string) bool {
+	return v == log.RedactedSecret
+}
+
+type secretField struct {
+	msg protoreflect.Message
+	fd  protoreflect.FieldDescriptor
+}
+
+// findSecretFields returns all the non-empty secret fields in the message
+// and the nested messages.
+func findSecretFields(m protoreflect.Message) []secretField {
+	var res []secretField
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			if fd.Message() != nil {
+				l := v.List()
+				for i := 0; i < l.Len(); i++ {
+					res = append(res, findSecretFields(l.Get(i).Message())...)
+				}
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					res = append(res, findSecretFields(mv.Message())...)
+					return true
+				})
+			}
+		case fd.Message() != nil:
+			res = append(res, findSecretFields(v.Message())...)
+		case fd.Kind() == protoreflect.StringKind && secretFieldNames[fd.Name()] && v.String() != "":
+			res = append(res, secretField{msg: m, fd: fd})
+		}
+		return true
+	})
+	return res
+}
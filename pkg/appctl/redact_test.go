@@ -0,0 +1,57 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	config := &pb.ServerConfig{
+		Users: []*pb.User{
+			{Name: proto.String("u1"), Password: proto.String("p1")},
+			{Name: proto.String("u2"), HashedPassword: proto.String("abcd")},
+		},
+	}
+	redacted := RedactSecrets(config)
+	for _, user := range redacted.GetUsers() {
+		if user.GetPassword() != "" && user.GetPassword() != log.RedactedSecret {
+			t.Errorf("password of user %q is not redacted", user.GetName())
+		}
+		if user.GetHashedPassword() != "" && user.GetHashedPassword() != log.RedactedSecret {
+			t.Errorf("hashed password of user %q is not redacted", user.GetName())
+		}
+	}
+	if redacted.GetUsers()[0].GetName() != "u1" {
+		t.Errorf("user name is changed to %q", redacted.GetUsers()[0].GetName())
+	}
+	if config.GetUsers()[0].GetPassword() != "p1" || config.GetUsers()[1].GetHashedPassword() != "abcd" {
+		t.Errorf("original config is modified")
+	}
+
+	fields := findSecretFields(config.ProtoReflect())
+	if len(fields) != 2 {
+		t.Errorf("found %d secret fields, want 2", len(fields))
+	}
+
+	if err := ValidateServerConfigPatch(redacted); err == nil {
+		t.Errorf("ValidateServerConfigPatch() accepted a redacted config")
+	}
+}
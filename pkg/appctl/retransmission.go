@@ -0,0 +1,68 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/protocolv2"
+)
+
+const (
+	// minRTOMillis is the minimum retransmission timeout allowed in config.
+	minRTOMillis = 10
+
+	// maxRTOMillis is the maximum retransmission timeout allowed in config.
+	maxRTOMillis = 60000
+
+	// maxBackoffMultiplier is the maximum retransmission backoff multiplier
+	// allowed in config.
+	maxBackoffMultiplier = 4.0
+)
+
+// validateRetransmissionSettings checks the retransmission parameters,
+// if they are set.
+func validateRetransmissionSettings(settings *pb.RetransmissionSettings) error {
+	for name, millis := range map[string]int32{
+		"initial RTO": settings.GetInitialRTOMillis(),
+		"minimum RTO": settings.GetMinRTOMillis(),
+		"maximum RTO": settings.GetMaxRTOMillis(),
+	} {
+		if millis != 0 && (millis < minRTOMillis || millis > maxRTOMillis) {
+			return fmt.Errorf("retransmission %s %d milliseconds is not between %d and %d", name, millis, minRTOMillis, maxRTOMillis)
+		}
+	}
+	if settings.GetMinRTOMillis() != 0 && settings.GetMaxRTOMillis() != 0 && settings.GetMinRTOMillis() > settings.GetMaxRTOMillis() {
+		return fmt.Errorf("retransmission minimum RTO %d milliseconds is bigger than maximum RTO %d milliseconds", settings.GetMinRTOMillis(), settings.GetMaxRTOMillis())
+	}
+	if settings.GetBackoffMultiplier() != 0 && (settings.GetBackoffMultiplier() < 1 || settings.GetBackoffMultiplier() > maxBackoffMultiplier) {
+		return fmt.Errorf("retransmission backoff multiplier %v is not between 1 and %v", settings.GetBackoffMultiplier(), maxBackoffMultiplier)
+	}
+	return nil
+}
+
+// RetransmissionConfig converts the retransmission settings
+// to the parameters used by UDP sessions.
+func RetransmissionConfig(settings *pb.RetransmissionSettings) protocolv2.RetransmissionConfig {
+	return protocolv2.RetransmissionConfig{
+		InitialRTO:        time.Duration(settings.GetInitialRTOMillis()) * time.Millisecond,
+		MinRTO:            time.Duration(settings.GetMinRTOMillis()) * time.Millisecond,
+		MaxRTO:            time.Duration(settings.GetMaxRTOMillis()) * time.Millisecond,
+		BackoffMultiplier: settings.GetBackoffMultiplier(),
+	}
+}
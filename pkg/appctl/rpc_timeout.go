@@ -16,6 +16,8 @@
 package appctl
 
 import (
+	"fmt"
+	"sync"
 	"time"
 )
 
@@ -24,4 +26,51 @@ const (
 	// We use a very large timeout that works for embedded computer
 	// and anti-virus sandbox environment.
 	RPCTimeout = time.Second * 10
+
+	// StatusRPCTimeout is the timeout to check if a daemon is alive.
+	// It is still generous, because a status check is also used to
+	// wait for a daemon to start up.
+	StatusRPCTimeout = time.Second * 5
+
+	// ProfileRPCTimeout is the timeout to complete a RPC call that
+	// collects debug information, e.g. a thread dump or a heap profile.
+	ProfileRPCTimeout = time.Minute * 5
+
+	// SubscriptionRPCTimeout is the timeout to complete a RPC call that
+	// downloads subscriptions from the network.
+	SubscriptionRPCTimeout = time.Minute * 2
+
+	// StopRPCTimeout is the timeout to complete a RPC call that stops
+	// the proxy, which may wait for existing connections to finish.
+	StopRPCTimeout = RPCTimeout + maxDrainTimeoutSeconds*time.Second
 )
+
+var (
+	// rpcTimeoutOverride, if positive, replaces the default timeout
+	// of every RPC call.
+	rpcTimeoutOverride time.Duration
+	rpcTimeoutMu       sync.Mutex
+)
+
+// SetRPCTimeoutOverride replaces the default timeout of all RPC calls
+// made by this process. A zero value restores the default timeouts.
+func SetRPCTimeoutOverride(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("RPC timeout %v is negative", timeout)
+	}
+	rpcTimeoutMu.Lock()
+	defer rpcTimeoutMu.Unlock()
+	rpcTimeoutOverride = timeout
+	return nil
+}
+
+// GetRPCTimeout returns the timeout of a RPC call. If an override is set,
+// it is returned. Otherwise the default timeout of the call is returned.
+func GetRPCTimeout(defaultTimeout time.Duration) time.Duration {
+	rpcTimeoutMu.Lock()
+	defer rpcTimeoutMu.Unlock()
+	if rpcTimeoutOverride > 0 {
+		return rpcTimeoutOverride
+	}
+	return defaultTimeout
+}
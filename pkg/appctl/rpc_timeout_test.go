@@ -0,0 +1,44 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRPCTimeoutOverride(t *testing.T) {
+	defer SetRPCTimeoutOverride(0)
+
+	if got := GetRPCTimeout(ProfileRPCTimeout); got != ProfileRPCTimeout {
+		t.Errorf("GetRPCTimeout() = %v, want %v", got, ProfileRPCTimeout)
+	}
+	if err := SetRPCTimeoutOverride(-time.Second); err == nil {
+		t.Errorf("want error in SetRPCTimeoutOverride() with negative timeout, got no error")
+	}
+	if err := SetRPCTimeoutOverride(time.Minute); err != nil {
+		t.Fatalf("SetRPCTimeoutOverride() failed: %v", err)
+	}
+	if got := GetRPCTimeout(StatusRPCTimeout); got != time.Minute {
+		t.Errorf("GetRPCTimeout() = %v, want %v", got, time.Minute)
+	}
+	if err := SetRPCTimeoutOverride(0); err != nil {
+		t.Fatalf("SetRPCTimeoutOverride() failed: %v", err)
+	}
+	if got := GetRPCTimeout(RPCTimeout); got != RPCTimeout {
+		t.Errorf("GetRPCTimeout() = %v, want %v", got, RPCTimeout)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// rpcTokenFileName stores the token required by every RPC call.
+	// The file is in the same directory as the config file.
+	rpcTokenFileName = "rpc.token"
+
+	// rpcTokenMetadataKey is the gRPC metadata key that carries the token.
+	rpcTokenMetadataKey = "mieru-rpc-token"
+
+	// rpcTokenSize is the number of random bytes in a token.
+	rpcTokenSize = 32
+)
+
+// ClientRPCTokenPath returns the path of client RPC token file.
+func ClientRPCTokenPath() (string, error) {
+	if _, _, err := clientConfigFilePath(); err != nil {
+		return "", fmt.Errorf("clientConfigFilePath() failed: %w", err)
+	}
+	if err := prepareClientConfigDir(); err != nil {
+		return "", fmt.Errorf("prepareClientConfigDir() failed: %w", err)
+	}
+	return filepath.Join(cachedClientConfigDir, rpcTokenFileName), nil
+}
+
+// ServerRPCTokenPath returns the path of server RPC token file.
+func ServerRPCTokenPath() string {
+	// Apply the config file path from environment variables, if any.
+	serverConfigFilePath()
+	return filepath.Join(cachedServerConfigDir, rpcTokenFileName)
+}
+
+// PrepareClientRPCToken returns the RPC token of mieru client.
+// The token is created if it doesn't exist. It is called by the client
+// daemon before the RPC server is started.
+func PrepareClientRPCToken() (string, error) {
+	path, err := ClientRPCTokenPath()
+	if err != nil {
+		return "", err
+	}
+	return prepareRPCToken(path, 0600)
+}
+
+// ReadClientRPCToken returns the RPC token of mieru client.
+// It doesn't create the token.
+func ReadClientRPCToken() (string, error) {
+	path, err := ClientRPCTokenPath()
+	if err != nil {
+		return "", err
+	}
+	return readRPCToken(path)
+}
+
+// PrepareServerRPCToken returns the RPC token of mita server.
+// The token is created if it doesn't exist. It is called by the server
+// daemon before the RPC server is started.
+func PrepareServerRPCToken() (string, error) {
+	return prepareRPCToken(ServerRPCTokenPath(), 0640)
+}
+
+// RPCTokenServerOptions returns the gRPC server options that reject
+// RPC calls without the token.
+func RPCTokenServerOptions(token string) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := checkRPCToken(ctx, token); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkRPCToken(ss.Context(), token); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// rpcTokenCredentials attaches the token to every RPC call.
+type rpcTokenCredentials string
+
+var _ credentials.PerRPCCredentials = rpcTokenCredentials("")
+
+func (c rpcTokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{rpcTokenMetadataKey: string(c)}, nil
+}
+
+func (c rpcTokenCredentials) RequireTransportSecurity() bool {
+	// RPC servers only listen to localhost or a Unix domain socket.
+	return false
+}
+
+// rpcTokenDialOption returns the gRPC dial option that sends the token
+// read from the file.
+func rpcTokenDialOption(path string) (grpc.DialOption, error) {
+	token, err := readRPCToken(path)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithPerRPCCredentials(rpcTokenCredentials(token)), nil
+}
+
+// checkRPCToken returns an error if the token in the metadata of
+// the incoming RPC call is missing or wrong.
+func checkRPCToken(ctx context.Context, token string) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "RPC token is missing")
+	}
+	values := md.Get(rpcTokenMetadataKey)
+	if len(values) != 1 {
+		return status.Error(codes.Unauthenticated, "RPC token is missing")
+	}
+	if subtle.ConstantTimeCompare([]byte(values[0]), []byte(token)) != 1 {
+		return status.Error(codes.Unauthenticated, "RPC token is invalid")
+	}
+	return nil
+}
+
+// prepareRPCToken reads the token from the file. If the file doesn't
+// exist, a random token is created and stored in the file.
+func prepareRPCToken(path string, perm fs.FileMode) (string, error) {
+	token, err := readRPCToken(path)
+	if err == nil {
+		return token, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	b := make([]byte, rpcTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read() failed: %w", err)
+	}
+	token = hex.EncodeToString(b)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			// The token is created by another process.
+			return readRPCToken(path)
+		}
+		return "", fmt.Errorf("os.OpenFile(%q) failed: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(token + "\n"); err != nil {
+		return "", fmt.Errorf("write RPC token to %q failed: %w", path, err)
+	}
+	return token, nil
+}
+
+// readRPCToken reads the token from the file.
+func readRPCToken(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read RPC token failed: %w", err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("RPC token file %q is empty", path)
+	}
+	return token, nil
+}
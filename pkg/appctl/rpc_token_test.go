@@ -0,0 +1,111 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPrepareRPCToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), rpcTokenFileName)
+	if _, err := readRPCToken(path); err == nil {
+		t.Fatalf("readRPCToken() succeeded before the token is created")
+	}
+	token, err := prepareRPCToken(path, 0600)
+	if err != nil {
+		t.Fatalf("prepareRPCToken() failed: %v", err)
+	}
+	if len(token) != 2*rpcTokenSize {
+		t.Errorf("token length is %d, want %d", len(token), 2*rpcTokenSize)
+	}
+	again, err := prepareRPCToken(path, 0600)
+	if err != nil {
+		t.Fatalf("prepareRPCToken() failed: %v", err)
+	}
+	if again != token {
+		t.Errorf("token is changed from %q to %q", token, again)
+	}
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	if _, err := prepareRPCToken(path, 0600); err == nil {
+		t.Errorf("prepareRPCToken() succeeded with an empty token file")
+	}
+}
+
+func TestRPCTokenRequired(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, rpcTokenFileName)
+	token, err := prepareRPCToken(tokenPath, 0600)
+	if err != nil {
+		t.Fatalf("prepareRPCToken() failed: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	grpcServer := grpc.NewServer(RPCTokenServerOptions(token)...)
+	pb.RegisterClientLifecycleServiceServer(grpcServer, NewClientLifecycleService())
+	go grpcServer.Serve(l)
+	defer grpcServer.Stop()
+
+	wrongPath := filepath.Join(dir, "wrong.token")
+	if _, err := prepareRPCToken(wrongPath, 0600); err != nil {
+		t.Fatalf("prepareRPCToken() failed: %v", err)
+	}
+	testCases := []struct {
+		name     string
+		path     string
+		wantCode codes.Code
+	}{
+		{"correct token", tokenPath, codes.OK},
+		{"wrong token", wrongPath, codes.Unauthenticated},
+		{"no token", "", codes.Unauthenticated},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []grpc.DialOption{grpc.WithInsecure()}
+			if tc.path != "" {
+				tokenOption, err := rpcTokenDialOption(tc.path)
+				if err != nil {
+					t.Fatalf("rpcTokenDialOption() failed: %v", err)
+				}
+				opts = append(opts, tokenOption)
+			}
+			conn, err := grpc.Dial(l.Addr().String(), opts...)
+			if err != nil {
+				t.Fatalf("grpc.Dial() failed: %v", err)
+			}
+			defer conn.Close()
+			client := pb.NewClientLifecycleServiceClient(conn)
+			ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+			defer cancel()
+			_, err = client.GetStatus(ctx, &pb.Empty{})
+			if got := status.Code(err); got != tc.wantCode {
+				t.Errorf("GetStatus() returned code %v, want %v", got, tc.wantCode)
+			}
+		})
+	}
+}
@@ -29,9 +29,11 @@ import (
 
 	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
 	"github.com/enfein/mieru/pkg/egress"
+	"github.com/enfein/mieru/pkg/event"
 	"github.com/enfein/mieru/pkg/log"
 	"github.com/enfein/mieru/pkg/metrics"
 	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/replay"
 	"github.com/enfein/mieru/pkg/socks5"
 	"github.com/enfein/mieru/pkg/stderror"
 	"github.com/enfein/mieru/pkg/util"
@@ -39,6 +41,20 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+const (
+	// minReplayCacheCapacity is the minimum replay cache capacity
+	// allowed in the server config.
+	minReplayCacheCapacity = 1024
+
+	// minReplayCacheExpireSeconds is the minimum replay cache expire interval
+	// allowed in the server config. It must cover the key refresh interval.
+	minReplayCacheExpireSeconds = 60
+
+	// maxReplayCacheExpireSeconds is the maximum replay cache expire interval
+	// allowed in the server config.
+	maxReplayCacheExpireSeconds = 3600
+)
+
 var (
 	// ServerRPCServerStarted is closed when server RPC server is started.
 	ServerRPCServerStarted chan struct{} = make(chan struct{})
@@ -113,12 +129,41 @@ func (s *serverLifecycleService) Start(ctx context.Context, req *pb.Empty) (*pb.
 	SetAppStatus(pb.AppStatus_STARTING)
 
 	mux := protocolv2.NewMux(false).SetServerUsers(UserListToMap(config.GetUsers()))
+	mux.SetServerSessionCapacity(int(config.GetAdvancedSettings().GetSessionCapacity()))
+	if err := mux.SetReplayCache(ReplayCacheParameters(config)); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetReplayCache() failed: %w", err)
+	}
+	if err := protocolv2.SetRetransmissionConfig(RetransmissionConfig(config.GetAdvancedSettings().GetRetransmission())); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetRetransmissionConfig() failed: %w", err)
+	}
+	if err := protocolv2.SetFlowControlConfig(FlowControlConfig(config.GetAdvancedSettings().GetFlowControl())); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetFlowControlConfig() failed: %w", err)
+	}
+	if err := protocolv2.SetDeadPeerTimeout(DeadPeerTimeout(config.GetAdvancedSettings().GetDeadPeerTimeoutSeconds())); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetDeadPeerTimeout() failed: %w", err)
+	}
+	if err := protocolv2.SetTimingJitter(TimingJitter(config.GetAdvancedSettings().GetTimingJitterMillis())); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetTimingJitter() failed: %w", err)
+	}
+	if err := protocolv2.SetUnderlayTimeoutConfig(UnderlayTimeoutConfig(config.GetAdvancedSettings().GetUnderlayReadTimeoutSeconds(), config.GetAdvancedSettings().GetStuckUnderlayTimeoutSeconds())); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetUnderlayTimeoutConfig() failed: %w", err)
+	}
+	if err := protocolv2.SetIPBanConfig(IPBanConfig(config.GetAdvancedSettings().GetIpBan())); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetIPBanConfig() failed: %w", err)
+	}
+	if err := protocolv2.SetConnectionLimitConfig(ConnectionLimitConfig(config.GetAdvancedSettings().GetConnectionLimits())); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetConnectionLimitConfig() failed: %w", err)
+	}
+	if err := protocolv2.SetIPBlocklist(config.GetBlockedIPRanges()); err != nil {
+		return &pb.Empty{}, fmt.Errorf("SetIPBlocklist() failed: %w", err)
+	}
 	SetServerMuxRef(mux)
 	mtu := util.DefaultMTU
 	if config.GetMtu() != 0 {
 		mtu = int(config.GetMtu())
 	}
-	endpoints, err := PortBindingsToUnderlayProperties(config.GetPortBindings(), mtu)
+	rotatedPortBindings := RotatedPortBindings(config.GetPortRotation(), time.Now())
+	endpoints, err := PortBindingsToUnderlayProperties(append(config.GetPortBindings(), rotatedPortBindings...), mtu)
 	if err != nil {
 		return &pb.Empty{}, err
 	}
@@ -130,6 +175,21 @@ func (s *serverLifecycleService) Start(ctx context.Context, req *pb.Empty) (*pb.
 		ClientSideAuthentication: true,
 		EgressController:         egress.NewSocks5Controller(config.GetEgress()),
 		HandshakeTimeout:         10 * time.Second,
+		Outbound:                 OutboundConfig(config.GetEgress().GetSource()),
+	}
+	socks5Config.Resolver, err = ServerDNSResolver(config.GetDns())
+	if err != nil {
+		return &pb.Empty{}, err
+	}
+	if len(config.GetAdvancedSettings().GetPriorityRules()) != 0 {
+		socks5Config.PriorityRules, err = socks5.NewPriorityRules(config.GetAdvancedSettings().GetPriorityRules())
+		if err != nil {
+			return &pb.Empty{}, fmt.Errorf("NewPriorityRules() failed: %w", err)
+		}
+	}
+	socks5Config.EgressPolicy, err = egress.NewPolicy(config.GetEgress().GetPolicy(), config.GetUsers(), config.GetAdvancedSettings().GetAllowLocalDestination())
+	if err != nil {
+		return &pb.Empty{}, fmt.Errorf("NewPolicy() failed: %w", err)
 	}
 	socks5Server, err := socks5.New(socks5Config)
 	if err != nil {
@@ -144,6 +204,7 @@ func (s *serverLifecycleService) Start(ctx context.Context, req *pb.Empty) (*pb.
 		if err = mux.Start(); err != nil {
 			log.Fatalf("socks5 server listening failed: %v", err)
 		}
+		StartPortRotation(mux, config.GetPortRotation(), rotatedPortBindings, mtu)
 		initProxyTasks.Done()
 
 		log.Infof("mita server daemon socks5 server is running")
@@ -154,8 +215,28 @@ func (s *serverLifecycleService) Start(ctx context.Context, req *pb.Empty) (*pb.
 	}()
 
 	initProxyTasks.Wait()
+	if !serverPrivilegeApplied(config.GetPrivilege()) {
+		log.Warnf("privilege settings are changed; restart mita to apply them")
+	}
 	metrics.EnableLogging()
+	if err := StartStatsdExport(config.GetStatsd()); err != nil {
+		log.Errorf("start StatsD export failed: %v", err)
+	}
+	if err := StartTracingExport(config.GetTracing(), "mita"); err != nil {
+		log.Errorf("start tracing export failed: %v", err)
+	}
+	if err := StartWebhookExport(config.GetWebhook(), "mita"); err != nil {
+		log.Errorf("start webhook export failed: %v", err)
+	}
+	if err := StartLogShipping(config.GetLogShipping(), "mita"); err != nil {
+		log.Errorf("start log shipping failed: %v", err)
+	}
+	if err := StartServerMetricsPersistence(); err != nil {
+		log.Errorf("start metrics persistence failed: %v", err)
+	}
 	SetAppStatus(pb.AppStatus_RUNNING)
+	event.Emit(event.DaemonStart, "", nil, "mita server daemon is started")
+	recordServerAudit("start", "")
 	log.Infof("completed start request from RPC caller")
 	return &pb.Empty{}, nil
 }
@@ -164,6 +245,7 @@ func (s *serverLifecycleService) Stop(ctx context.Context, req *pb.Empty) (*pb.E
 	SetAppStatus(pb.AppStatus_STOPPING)
 	log.Infof("received stop request from RPC caller")
 	if socks5ServerRef.Load() != nil {
+		drainSocks5Server(socks5ServerRef.Load(), serverDrainTimeout())
 		log.Infof("stopping socks5 server")
 		if err := socks5ServerRef.Load().Close(); err != nil {
 			log.Infof("socks5 server Close() failed: %v", err)
@@ -172,6 +254,9 @@ func (s *serverLifecycleService) Stop(ctx context.Context, req *pb.Empty) (*pb.E
 	} else {
 		log.Infof("active socks5 servers not found")
 	}
+	metrics.StopPersistence()
+	event.Emit(event.DaemonStop, "", nil, "mita server daemon is stopped")
+	recordServerAudit("stop", "")
 	SetAppStatus(pb.AppStatus_IDLE)
 	log.Infof("completed stop request from RPC caller")
 	return &pb.Empty{}, nil
@@ -201,15 +286,52 @@ func (s *serverLifecycleService) Reload(ctx context.Context, req *pb.Empty) (*pb
 		if config.GetMtu() != 0 {
 			mtu = int(config.GetMtu())
 		}
-		endpoints, err := PortBindingsToUnderlayProperties(config.GetPortBindings(), mtu)
+		rotatedPortBindings := RotatedPortBindings(config.GetPortRotation(), time.Now())
+		endpoints, err := PortBindingsToUnderlayProperties(append(config.GetPortBindings(), rotatedPortBindings...), mtu)
 		if err != nil {
 			return &pb.Empty{}, err
 		}
 		mux.SetEndpoints(endpoints)
+		StartPortRotation(mux, config.GetPortRotation(), rotatedPortBindings, mtu)
 
 		// Adjust users.
 		mux.SetServerUsers(UserListToMap(config.GetUsers()))
+
+		// Adjust session capacity.
+		mux.SetServerSessionCapacity(int(config.GetAdvancedSettings().GetSessionCapacity()))
+
+		// Adjust replay cache.
+		if err := mux.SetReplayCache(ReplayCacheParameters(config)); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetReplayCache() failed: %w", err)
+		}
+
+		// Adjust UDP transport parameters.
+		if err := protocolv2.SetRetransmissionConfig(RetransmissionConfig(config.GetAdvancedSettings().GetRetransmission())); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetRetransmissionConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetFlowControlConfig(FlowControlConfig(config.GetAdvancedSettings().GetFlowControl())); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetFlowControlConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetDeadPeerTimeout(DeadPeerTimeout(config.GetAdvancedSettings().GetDeadPeerTimeoutSeconds())); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetDeadPeerTimeout() failed: %w", err)
+		}
+		if err := protocolv2.SetTimingJitter(TimingJitter(config.GetAdvancedSettings().GetTimingJitterMillis())); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetTimingJitter() failed: %w", err)
+		}
+		if err := protocolv2.SetUnderlayTimeoutConfig(UnderlayTimeoutConfig(config.GetAdvancedSettings().GetUnderlayReadTimeoutSeconds(), config.GetAdvancedSettings().GetStuckUnderlayTimeoutSeconds())); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetUnderlayTimeoutConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetIPBanConfig(IPBanConfig(config.GetAdvancedSettings().GetIpBan())); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetIPBanConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetConnectionLimitConfig(ConnectionLimitConfig(config.GetAdvancedSettings().GetConnectionLimits())); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetConnectionLimitConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetIPBlocklist(config.GetBlockedIPRanges()); err != nil {
+			return &pb.Empty{}, fmt.Errorf("SetIPBlocklist() failed: %w", err)
+		}
 	}
+	recordServerAudit("reload", "")
 	return &pb.Empty{}, nil
 }
 
@@ -217,6 +339,7 @@ func (s *serverLifecycleService) Exit(ctx context.Context, req *pb.Empty) (*pb.E
 	SetAppStatus(pb.AppStatus_STOPPING)
 	log.Infof("received exit request from RPC caller")
 	if socks5ServerRef.Load() != nil {
+		drainSocks5Server(socks5ServerRef.Load(), serverDrainTimeout())
 		log.Infof("stopping socks5 server")
 		if err := socks5ServerRef.Load().Close(); err != nil {
 			log.Infof("socks5 server Close() failed: %v", err)
@@ -225,6 +348,9 @@ func (s *serverLifecycleService) Exit(ctx context.Context, req *pb.Empty) (*pb.E
 	} else {
 		log.Infof("active socks5 servers not found")
 	}
+	metrics.StopPersistence()
+	event.Emit(event.DaemonStop, "", nil, "mita server daemon is exiting")
+	recordServerAudit("exit", "")
 	SetAppStatus(pb.AppStatus_IDLE)
 
 	grpcServer := serverRPCServerRef.Load()
@@ -246,12 +372,123 @@ func (s *serverLifecycleService) GetMetrics(ctx context.Context, req *pb.Empty)
 	return &pb.Metrics{Json: proto.String(string(b))}, nil
 }
 
+func (s *serverLifecycleService) GetMetricsHistory(ctx context.Context, req *pb.GetMetricsHistoryRequest) (*pb.MetricsHistory, error) {
+	return metricsHistory(req), nil
+}
+
+func (s *serverLifecycleService) WatchMetrics(req *pb.WatchMetricsRequest, stream pb.ServerLifecycleService_WatchMetricsServer) error {
+	return watchMetrics(stream.Context(), req, stream.Send)
+}
+
 func (s *serverLifecycleService) GetSessionInfo(context.Context, *pb.Empty) (*pb.SessionInfo, error) {
 	mux := serverMuxRef.Load()
 	if mux == nil {
 		return &pb.SessionInfo{}, fmt.Errorf("server multiplexier is unavailable")
 	}
-	return &pb.SessionInfo{Table: mux.ExportSessionInfoTable()}, nil
+	return sessionInfo(mux), nil
+}
+
+func (s *serverLifecycleService) WatchSessionInfo(req *pb.Empty, stream pb.ServerLifecycleService_WatchSessionInfoServer) error {
+	return watchSessionInfo(stream.Context(), serverMuxRef.Load(), stream.Send)
+}
+
+func (s *serverLifecycleService) GetLogs(req *pb.GetLogsRequest, stream pb.ServerLifecycleService_GetLogsServer) error {
+	return streamLogs(stream.Context(), req, stream.Send)
+}
+
+func (s *serverLifecycleService) SetLoggingLevel(ctx context.Context, req *pb.SetLoggingLevelRequest) (*pb.Empty, error) {
+	level := setLoggingLevel(req)
+	recordServerAudit("set log-level", fmt.Sprintf("loggingLevel changed to %s", level.String()))
+	return &pb.Empty{}, nil
+}
+
+func (s *serverLifecycleService) GetAuditLog(ctx context.Context, req *pb.GetAuditLogRequest) (*pb.AuditLog, error) {
+	return readAuditLog(filepath.Join(cachedServerConfigDir, auditLogFileName), int(req.GetLimit()))
+}
+
+func (s *serverLifecycleService) GetBannedIPs(ctx context.Context, req *pb.Empty) (*pb.BannedIPList, error) {
+	res := &pb.BannedIPList{}
+	config, err := LoadServerConfig()
+	if err != nil {
+		return res, fmt.Errorf("LoadServerConfig() failed: %w", err)
+	}
+	for _, ipRange := range config.GetBlockedIPRanges() {
+		res.BannedIPs = append(res.BannedIPs, &pb.BannedIP{
+			Ip:          proto.String(ipRange),
+			Blocklisted: proto.Bool(true),
+		})
+	}
+	for _, banned := range protocolv2.BannedIPs() {
+		res.BannedIPs = append(res.BannedIPs, &pb.BannedIP{
+			Ip:             proto.String(banned.IP),
+			Failures:       proto.Int32(int32(banned.Failures)),
+			UntilUnixMilli: proto.Int64(banned.Until.UnixMilli()),
+		})
+	}
+	return res, nil
+}
+
+func (s *serverLifecycleService) GetUsers(ctx context.Context, req *pb.Empty) (*pb.UserUsageList, error) {
+	config, err := LoadServerConfig()
+	if err != nil {
+		return &pb.UserUsageList{}, fmt.Errorf("LoadServerConfig() failed: %w", err)
+	}
+	return &pb.UserUsageList{Users: userUsages(config.GetUsers(), time.Now())}, nil
+}
+
+func (s *serverLifecycleService) GetApiVersion(ctx context.Context, req *pb.Empty) (*pb.ApiVersion, error) {
+	return currentAPIVersion(), nil
+}
+
+func (s *serverLifecycleService) BanIP(ctx context.Context, req *pb.BanIPRequest) (*pb.Empty, error) {
+	ipNet, err := util.ParseIPRange(req.GetIpRange())
+	if err != nil {
+		return &pb.Empty{}, err
+	}
+	added, err := updateIPBlocklist(ipNet, true)
+	if err != nil {
+		return &pb.Empty{}, err
+	}
+	if !added {
+		return &pb.Empty{}, fmt.Errorf("IP range %s is already blocked", ipNet)
+	}
+	recordServerAudit("ban ip", fmt.Sprintf("blocked %s", ipNet))
+	return &pb.Empty{}, nil
+}
+
+func (s *serverLifecycleService) UnbanIP(ctx context.Context, req *pb.UnbanIPRequest) (*pb.Empty, error) {
+	ipNet, err := util.ParseIPRange(req.GetIp())
+	if err != nil {
+		return &pb.Empty{}, err
+	}
+	removed, err := updateIPBlocklist(ipNet, false)
+	if err != nil {
+		return &pb.Empty{}, err
+	}
+	if ip := net.ParseIP(req.GetIp()); ip != nil && protocolv2.UnbanIP(ip) {
+		removed = true
+	}
+	if !removed {
+		return &pb.Empty{}, fmt.Errorf("%s is not banned", ipNet)
+	}
+	recordServerAudit("unban ip", fmt.Sprintf("unbanned %s", ipNet))
+	return &pb.Empty{}, nil
+}
+
+func (s *serverLifecycleService) AddPortBinding(ctx context.Context, req *pb.PortBinding) (*pb.Empty, error) {
+	if err := updatePortBinding(req, true); err != nil {
+		return &pb.Empty{}, err
+	}
+	recordServerAudit("add binding", portBindingString(req))
+	return &pb.Empty{}, nil
+}
+
+func (s *serverLifecycleService) DeletePortBinding(ctx context.Context, req *pb.PortBinding) (*pb.Empty, error) {
+	if err := updatePortBinding(req, false); err != nil {
+		return &pb.Empty{}, err
+	}
+	recordServerAudit("delete binding", portBindingString(req))
+	return &pb.Empty{}, nil
 }
 
 func (s *serverLifecycleService) GetThreadDump(ctx context.Context, req *pb.Empty) (*pb.ThreadDump, error) {
@@ -273,6 +510,19 @@ func (s *serverLifecycleService) GetHeapProfile(ctx context.Context, req *pb.Pro
 	return &pb.Empty{}, err
 }
 
+func (s *serverLifecycleService) SendServerMessage(ctx context.Context, req *pb.ServerMessage) (*pb.SendServerMessageResult, error) {
+	mux := serverMuxRef.Load()
+	if mux == nil {
+		return &pb.SendServerMessageResult{}, fmt.Errorf("server multiplexier is unavailable")
+	}
+	n, err := mux.SendServerMessage(req.GetUserName(), req.GetText())
+	if err != nil {
+		return &pb.SendServerMessageResult{}, err
+	}
+	log.Infof("sent server message to %d sessions", n)
+	return &pb.SendServerMessageResult{SessionCount: proto.Int32(int32(n))}, nil
+}
+
 // NewServerLifecycleService creates a new ServerLifecycleService RPC server.
 func NewServerLifecycleService() *serverLifecycleService {
 	return &serverLifecycleService{}
@@ -281,9 +531,14 @@ func NewServerLifecycleService() *serverLifecycleService {
 // NewServerLifecycleRPCClient creates a new ServerLifecycleService RPC client.
 func NewServerLifecycleRPCClient() (pb.ServerLifecycleServiceClient, error) {
 	rpcAddr := "unix://" + ServerUDS()
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), RPCTimeout)
+	tokenOption, err := rpcTokenDialOption(ServerRPCTokenPath())
+	if err != nil {
+		return nil, err
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), GetRPCTimeout(RPCTimeout))
 	defer cancelFunc()
-	conn, err := grpc.DialContext(timedctx, rpcAddr, grpc.WithInsecure())
+	opts := append([]grpc.DialOption{grpc.WithInsecure(), tokenOption}, apiVersionDialOptions(pb.ServerLifecycleService_GetApiVersion_FullMethodName)...)
+	conn, err := grpc.DialContext(timedctx, rpcAddr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("grpc.DialContext() failed: %w", err)
 	}
@@ -304,6 +559,7 @@ func (s *serverConfigService) GetConfig(ctx context.Context, req *pb.Empty) (*pb
 }
 
 func (s *serverConfigService) SetConfig(ctx context.Context, req *pb.ServerConfig) (*pb.ServerConfig, error) {
+	oldConfig, _ := LoadServerConfig()
 	if err := StoreServerConfig(req); err != nil {
 		return &pb.ServerConfig{}, fmt.Errorf("StoreServerConfig() failed: %w", err)
 	}
@@ -311,6 +567,11 @@ func (s *serverConfigService) SetConfig(ctx context.Context, req *pb.ServerConfi
 	if err != nil {
 		return &pb.ServerConfig{}, fmt.Errorf("LoadServerConfig() failed: %w", err)
 	}
+	recordServerAudit("set config", ConfigDiffSummary(oldConfig, config))
+	// The blocklist takes effect without reloading the server.
+	if err := protocolv2.SetIPBlocklist(config.GetBlockedIPRanges()); err != nil {
+		log.Warnf("SetIPBlocklist() failed: %v", err)
+	}
 	return config, nil
 }
 
@@ -322,9 +583,14 @@ func NewServerConfigService() *serverConfigService {
 // NewServerConfigRPCClient creates a new ServerConfigService RPC client.
 func NewServerConfigRPCClient() (pb.ServerConfigServiceClient, error) {
 	rpcAddr := "unix://" + ServerUDS()
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), RPCTimeout)
+	tokenOption, err := rpcTokenDialOption(ServerRPCTokenPath())
+	if err != nil {
+		return nil, err
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), GetRPCTimeout(RPCTimeout))
 	defer cancelFunc()
-	conn, err := grpc.DialContext(timedctx, rpcAddr, grpc.WithInsecure())
+	opts := append([]grpc.DialOption{grpc.WithInsecure(), tokenOption}, apiVersionDialOptions(pb.ServerLifecycleService_GetApiVersion_FullMethodName)...)
+	conn, err := grpc.DialContext(timedctx, rpcAddr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("grpc.DialContext() failed: %w", err)
 	}
@@ -337,7 +603,7 @@ func GetServerStatusWithRPC(ctx context.Context) (*pb.AppStatusMsg, error) {
 	if err != nil {
 		return nil, fmt.Errorf("NewServerLifecycleRPCClient() failed: %w", err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(ctx, RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(ctx, GetRPCTimeout(StatusRPCTimeout))
 	defer cancelFunc()
 	status, err := client.GetStatus(timedctx, &pb.Empty{})
 	if err != nil {
@@ -422,6 +688,7 @@ func LoadServerConfig() (*pb.ServerConfig, error) {
 		return nil, fmt.Errorf("config file type is invalid")
 	}
 
+	registerSecrets("server config", s)
 	return s, nil
 }
 
@@ -464,11 +731,11 @@ func StoreServerConfig(config *pb.ServerConfig) error {
 	return nil
 }
 
-// ApplyJSONServerConfig applies user provided JSON server config from path.
+// ApplyJSONServerConfig applies user provided JSON or YAML server config from path.
 func ApplyJSONServerConfig(path string) error {
-	b, err := os.ReadFile(path)
+	b, err := ReadConfigFileAsJSON(path)
 	if err != nil {
-		return fmt.Errorf("os.ReadFile(%q) failed: %w", path, err)
+		return err
 	}
 	s := &pb.ServerConfig{}
 	if err = jsonUnmarshalOption.Unmarshal(b, s); err != nil {
@@ -529,10 +796,11 @@ func DeleteServerUsers(names []string) error {
 // 1. port bindings are valid
 // 2. for each user
 // 2.1. user name is not empty
-// 2.2. user has either a password or a hashed password
+// 2.2. user has either a password or a hashed password, which is not redacted
 // 2.3. for each quota
 // 2.3.1. number of days is valid
 // 2.3.2. traffic volume in megabyte is valid
+// 2.4. each access window is valid
 // 3. if set, MTU is valid
 // 4. for each egress proxy
 // 4.1. name is not empty
@@ -540,11 +808,30 @@ func DeleteServerUsers(names []string) error {
 // 4.3. protocol is valid
 // 4.4. host is not empty
 // 4.5. port is valid
-// 5. there is maximum 1 egress rule
+// 4.6. a mieru proxy has user name and password
+// 5. for each egress rule
 // 5.1. the IP ranges must be "*"
 // 5.2. the domain names must be "*"
-// 5.3. the action must be "PROXY"
-// 5.4. the proxy name is defined
+// 5.3. the action must be "PROXY", or "DIRECT" if user names are set
+// 5.4. the proxy name is defined if the action is "PROXY"
+// 5.5. there is maximum 1 rule without user names
+// 5.6. each user is in maximum 1 rule
+// 6. if set, session capacity is not negative
+// 7. if set, replay cache capacity and expire interval are valid
+// 8. if set, retransmission parameters are valid
+// 9. if set, flow control window sizes are valid
+// 10. priority rules are valid
+// 11. drain timeout is valid
+// 12. if set, dead peer timeout is valid
+// 13. timing jitter is valid
+// 14. if set, underlay timeouts are valid
+// 15. egress policy and per-user destination rules are valid
+// 16. if set, IP ban settings are valid
+// 17. blocked IP ranges are valid
+// 18. if set, egress source IP addresses are valid
+// 19. if set, port rotation is valid and doesn't overlap with port bindings
+// 20. if set, DNS upstreams and overrides are valid
+// 21. if set, connection limits are not negative
 func ValidateServerConfigPatch(patch *pb.ServerConfig) error {
 	if _, err := FlatPortBindings(patch.GetPortBindings()); err != nil {
 		return err
@@ -556,13 +843,30 @@ func ValidateServerConfigPatch(patch *pb.ServerConfig) error {
 		if user.GetPassword() == "" && user.GetHashedPassword() == "" {
 			return fmt.Errorf("user password is not set")
 		}
+		if isRedactedSecret(user.GetPassword()) || isRedactedSecret(user.GetHashedPassword()) {
+			return fmt.Errorf("password of user %q is redacted, use describe config --show-secrets to get the password", user.GetName())
+		}
 		for _, quota := range user.GetQuotas() {
-			if quota.GetDays() <= 0 {
+			if quota.GetPeriod() == pb.QuotaPeriod_QUOTA_PERIOD_ROLLING_DAYS && quota.GetDays() <= 0 {
 				return fmt.Errorf("quota: number of days %d is invalid", quota.GetDays())
 			}
 			if quota.GetMegabytes() <= 0 {
 				return fmt.Errorf("quota: traffic volume in megabyte %d is invalid", quota.GetMegabytes())
 			}
+			if quota.GetAction() == pb.QuotaAction_QUOTA_ACTION_THROTTLE && quota.GetThrottleKilobytesPerSecond() <= 0 {
+				return fmt.Errorf("quota: throttle speed in kilobytes per second %d is invalid", quota.GetThrottleKilobytesPerSecond())
+			}
+		}
+		for _, window := range user.GetAccessWindows() {
+			if err := protocolv2.ValidateAccessWindow(window); err != nil {
+				return err
+			}
+		}
+		if user.GetRateLimit().GetKilobytesPerSecond() < 0 {
+			return fmt.Errorf("rate limit: speed in kilobytes per second %d is invalid", user.GetRateLimit().GetKilobytesPerSecond())
+		}
+		if user.GetRateLimit().GetPerSourceIPKilobytesPerSecond() < 0 {
+			return fmt.Errorf("rate limit: speed per source IP in kilobytes per second %d is invalid", user.GetRateLimit().GetPerSourceIPKilobytesPerSecond())
 		}
 	}
 	if patch.GetMtu() != 0 && (patch.GetMtu() < 1280 || patch.GetMtu() > 1500) {
@@ -586,42 +890,146 @@ func ValidateServerConfigPatch(patch *pb.ServerConfig) error {
 		if proxy.GetPort() < 1 || proxy.GetPort() > 65535 {
 			return fmt.Errorf("egress proxy port number %d is invalid", proxy.GetPort())
 		}
+		if proxy.GetProtocol() == pb.ProxyProtocol_MIERU_PROXY_PROTOCOL && (proxy.GetUser() == "" || proxy.GetPassword() == "") {
+			return fmt.Errorf("egress proxy %q: user name and password are required by mieru proxy", proxy.GetName())
+		}
 	}
-	if len(patch.GetEgress().GetRules()) > 1 {
-		return fmt.Errorf("found %d egress rules, maximum number of supported rules is 1", len(patch.GetEgress().GetRules()))
-	}
-	if len(patch.GetEgress().GetRules()) == 1 {
-		rule := patch.GetEgress().GetRules()[0]
+	hasDefaultRule := false
+	ruleUsers := map[string]bool{}
+	for _, rule := range patch.GetEgress().GetRules() {
 		if len(rule.GetIpRanges()) != 1 || rule.GetIpRanges()[0] != "*" {
 			return fmt.Errorf("egress rule: the only supported IP range value is %q", "*")
 		}
 		if len(rule.GetDomainNames()) != 1 || rule.GetDomainNames()[0] != "*" {
 			return fmt.Errorf("egress rule: the only supported domain name value is %q", "*")
 		}
+		if len(rule.GetUserNames()) == 0 {
+			if hasDefaultRule {
+				return fmt.Errorf("found more than 1 egress rule without user names")
+			}
+			hasDefaultRule = true
+		}
+		for _, name := range rule.GetUserNames() {
+			if ruleUsers[name] {
+				return fmt.Errorf("egress rule: user %q is in more than 1 rule", name)
+			}
+			ruleUsers[name] = true
+		}
+		if rule.GetAction() == pb.EgressAction_DIRECT && len(rule.GetUserNames()) > 0 {
+			continue
+		}
 		if rule.GetAction() != pb.EgressAction_PROXY {
-			return fmt.Errorf("egress rule: the only supported action is %q", pb.EgressAction_PROXY.String())
+			return fmt.Errorf("egress rule: the only supported action is %q, or %q for rules with user names", pb.EgressAction_PROXY.String(), pb.EgressAction_DIRECT.String())
 		}
 		if rule.GetProxyName() == "" {
 			return fmt.Errorf("egress rule: proxy name is not set")
 		}
-		foundProxy := false
-		for _, proxy := range patch.GetEgress().GetProxies() {
-			if proxy.GetName() == rule.GetProxyName() {
-				foundProxy = true
-				break
-			}
-		}
-		if !foundProxy {
+		if !usedProxyNames[rule.GetProxyName()] {
 			return fmt.Errorf("egress rule: proxy %q is not defined", rule.GetProxyName())
 		}
 	}
+	if patch.GetAdvancedSettings().GetSessionCapacity() < 0 {
+		return fmt.Errorf("session capacity %d is invalid", patch.GetAdvancedSettings().GetSessionCapacity())
+	}
+	if err := validateReplayCacheSettings(patch.GetAdvancedSettings().GetReplayCache()); err != nil {
+		return err
+	}
+	if err := validateRetransmissionSettings(patch.GetAdvancedSettings().GetRetransmission()); err != nil {
+		return err
+	}
+	if err := validateFlowControlSettings(patch.GetAdvancedSettings().GetFlowControl()); err != nil {
+		return err
+	}
+	if _, err := socks5.NewPriorityRules(patch.GetAdvancedSettings().GetPriorityRules()); err != nil {
+		return err
+	}
+	if err := validateDrainTimeout(patch.GetAdvancedSettings().GetDrainTimeoutSeconds()); err != nil {
+		return err
+	}
+	if err := validateDeadPeerTimeout(patch.GetAdvancedSettings().GetDeadPeerTimeoutSeconds()); err != nil {
+		return err
+	}
+	if err := validateTimingJitter(patch.GetAdvancedSettings().GetTimingJitterMillis()); err != nil {
+		return err
+	}
+	if err := validateUnderlayTimeouts(patch.GetAdvancedSettings().GetUnderlayReadTimeoutSeconds(), patch.GetAdvancedSettings().GetStuckUnderlayTimeoutSeconds()); err != nil {
+		return err
+	}
+	if _, err := egress.NewPolicy(patch.GetEgress().GetPolicy(), patch.GetUsers(), false); err != nil {
+		return err
+	}
+	if err := validateIPBanSettings(patch.GetAdvancedSettings().GetIpBan()); err != nil {
+		return err
+	}
+	if err := validateConnectionLimits(patch.GetAdvancedSettings().GetConnectionLimits()); err != nil {
+		return err
+	}
+	for _, ipRange := range patch.GetBlockedIPRanges() {
+		if _, err := util.ParseIPRange(ipRange); err != nil {
+			return fmt.Errorf("blocked IP range: %w", err)
+		}
+	}
+	if err := validateEgressSource(patch.GetEgress().GetSource()); err != nil {
+		return err
+	}
+	if err := validatePortRotation(patch.GetPortRotation()); err != nil {
+		return err
+	}
+	if err := checkPortRotationOverlap(patch.GetPortBindings(), patch.GetPortRotation()); err != nil {
+		return err
+	}
+	if err := validateServerDNS(patch.GetDns()); err != nil {
+		return err
+	}
+	if err := validateServerPrivilege(patch.GetPrivilege()); err != nil {
+		return err
+	}
+	if err := validateStatsdExport(patch.GetStatsd()); err != nil {
+		return err
+	}
+	if err := validateTracingExport(patch.GetTracing()); err != nil {
+		return err
+	}
+	if err := validateWebhookExport(patch.GetWebhook()); err != nil {
+		return err
+	}
+	if err := validateLogShipping(patch.GetLogShipping()); err != nil {
+		return err
+	}
 	return nil
 }
 
+// validateReplayCacheSettings checks the replay cache capacity and
+// expire interval, if they are set.
+func validateReplayCacheSettings(settings *pb.ReplayCacheSettings) error {
+	if settings.GetCapacity() != 0 && settings.GetCapacity() < minReplayCacheCapacity {
+		return fmt.Errorf("replay cache capacity %d is smaller than %d", settings.GetCapacity(), minReplayCacheCapacity)
+	}
+	if settings.GetExpireSeconds() != 0 && (settings.GetExpireSeconds() < minReplayCacheExpireSeconds || settings.GetExpireSeconds() > maxReplayCacheExpireSeconds) {
+		return fmt.Errorf("replay cache expire seconds %d is not between %d and %d", settings.GetExpireSeconds(), minReplayCacheExpireSeconds, maxReplayCacheExpireSeconds)
+	}
+	return nil
+}
+
+// ReplayCacheParameters returns the replay cache capacity and expire interval
+// from the server config, or the default values if they are not set.
+func ReplayCacheParameters(config *pb.ServerConfig) (int, time.Duration) {
+	capacity := replay.DefaultCapacity
+	expireInterval := replay.DefaultExpireInterval
+	settings := config.GetAdvancedSettings().GetReplayCache()
+	if settings.GetCapacity() != 0 {
+		capacity = int(settings.GetCapacity())
+	}
+	if settings.GetExpireSeconds() != 0 {
+		expireInterval = time.Duration(settings.GetExpireSeconds()) * time.Second
+	}
+	return capacity, expireInterval
+}
+
 // ValidateFullServerConfig validates the full server config.
 //
 // In addition to ValidateServerConfigPatch, it also validates:
-// 1. there is at least 1 port binding
+// 1. there is at least 1 port binding or port rotation
 //
 // It is not an error if no user is configured. However mita won't be functional.
 func ValidateFullServerConfig(config *pb.ServerConfig) error {
@@ -631,7 +1039,7 @@ func ValidateFullServerConfig(config *pb.ServerConfig) error {
 	if proto.Equal(config, &pb.ServerConfig{}) {
 		return fmt.Errorf("server config is empty")
 	}
-	if len(config.GetPortBindings()) == 0 {
+	if len(config.GetPortBindings()) == 0 && config.GetPortRotation() == nil {
 		return fmt.Errorf("server port binding is not set")
 	}
 	return nil
@@ -653,12 +1061,13 @@ func PortBindingsToUnderlayProperties(portBindings []*pb.PortBinding, mtu int) (
 	for i := 0; i < n; i++ {
 		protocol := portBindings[i].GetProtocol()
 		port := portBindings[i].GetPort()
+		mimicry := UnderlayMimicry(portBindings[i].GetMimicry())
 		switch protocol {
 		case pb.TransportProtocol_TCP:
-			endpoint := protocolv2.NewUnderlayProperties(mtu, ipVersion, util.TCPTransport, &net.TCPAddr{IP: listenIP, Port: int(port)}, nil)
+			endpoint := protocolv2.NewUnderlayPropertiesWithMimicry(mtu, ipVersion, util.TCPTransport, mimicry, &net.TCPAddr{IP: listenIP, Port: int(port)}, nil)
 			endpoints = append(endpoints, endpoint)
 		case pb.TransportProtocol_UDP:
-			endpoint := protocolv2.NewUnderlayProperties(mtu, ipVersion, util.UDPTransport, &net.UDPAddr{IP: listenIP, Port: int(port)}, nil)
+			endpoint := protocolv2.NewUnderlayPropertiesWithMimicry(mtu, ipVersion, util.UDPTransport, mimicry, &net.UDPAddr{IP: listenIP, Port: int(port)}, nil)
 			endpoints = append(endpoints, endpoint)
 		default:
 			return []protocolv2.UnderlayProperties{}, fmt.Errorf(stderror.InvalidTransportProtocol)
@@ -746,6 +1155,54 @@ func mergeServerConfig(dst, src *pb.ServerConfig) error {
 	} else {
 		egress = dst.GetEgress()
 	}
+	var privilege *pb.ServerPrivilege
+	if src.Privilege != nil {
+		privilege = src.GetPrivilege()
+	} else {
+		privilege = dst.GetPrivilege()
+	}
+	var statsd *pb.StatsdExport
+	if src.Statsd != nil {
+		statsd = src.GetStatsd()
+	} else {
+		statsd = dst.GetStatsd()
+	}
+	var tracing *pb.TracingExport
+	if src.Tracing != nil {
+		tracing = src.GetTracing()
+	} else {
+		tracing = dst.GetTracing()
+	}
+	var webhook *pb.WebhookExport
+	if src.Webhook != nil {
+		webhook = src.GetWebhook()
+	} else {
+		webhook = dst.GetWebhook()
+	}
+	var logShipping *pb.LogShipping
+	if src.LogShipping != nil {
+		logShipping = src.GetLogShipping()
+	} else {
+		logShipping = dst.GetLogShipping()
+	}
+	var blockedIPRanges []string
+	if src.BlockedIPRanges != nil {
+		blockedIPRanges = src.GetBlockedIPRanges()
+	} else {
+		blockedIPRanges = dst.GetBlockedIPRanges()
+	}
+	var portRotation *pb.PortRotation
+	if src.PortRotation != nil {
+		portRotation = src.GetPortRotation()
+	} else {
+		portRotation = dst.GetPortRotation()
+	}
+	var dns *pb.ServerDNS
+	if src.Dns != nil {
+		dns = src.GetDns()
+	} else {
+		dns = dst.GetDns()
+	}
 
 	proto.Reset(dst)
 	dst.PortBindings = portBindings
@@ -754,6 +1211,14 @@ func mergeServerConfig(dst, src *pb.ServerConfig) error {
 	dst.LoggingLevel = &loggingLevel
 	dst.Mtu = proto.Int32(mtu)
 	dst.Egress = egress
+	dst.Privilege = privilege
+	dst.Statsd = statsd
+	dst.Tracing = tracing
+	dst.Webhook = webhook
+	dst.LogShipping = logShipping
+	dst.BlockedIPRanges = blockedIPRanges
+	dst.PortRotation = portRotation
+	dst.Dns = dns
 	return nil
 }
 
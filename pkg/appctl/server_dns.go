@@ -0,0 +1,71 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"net"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+// validateServerDNS checks the server DNS settings, if they are set.
+func validateServerDNS(dns *pb.ServerDNS) error {
+	_, err := ServerDNSResolver(dns)
+	return err
+}
+
+// ServerDNSResolver creates the DNS resolver used by proxy server to
+// resolve the domain name of destinations. The lookup results are cached.
+func ServerDNSResolver(dns *pb.ServerDNS) (*util.DNSResolver, error) {
+	resolver := &util.DNSResolver{Cache: util.NewDNSCache()}
+	var err error
+	if resolver.Upstreams, err = newDNSUpstreams(dns.GetUpstreams()); err != nil {
+		return nil, fmt.Errorf("server DNS: %w", err)
+	}
+	for _, o := range dns.GetOverrides() {
+		if o.GetDomainName() == "" {
+			return nil, fmt.Errorf("server DNS: domain name of override is not set")
+		}
+		if (o.GetIpAddress() == "") == (len(o.GetUpstreams()) == 0) {
+			return nil, fmt.Errorf("server DNS: exactly one of IP address and upstreams must be set for domain name %q", o.GetDomainName())
+		}
+		override := util.DNSOverride{Domain: o.GetDomainName()}
+		if o.GetIpAddress() != "" {
+			if override.IP = net.ParseIP(o.GetIpAddress()); override.IP == nil {
+				return nil, fmt.Errorf("server DNS: failed to parse IP address %q of domain name %q", o.GetIpAddress(), o.GetDomainName())
+			}
+		}
+		if override.Upstreams, err = newDNSUpstreams(o.GetUpstreams()); err != nil {
+			return nil, fmt.Errorf("server DNS: domain name %q: %w", o.GetDomainName(), err)
+		}
+		resolver.Overrides = append(resolver.Overrides, override)
+	}
+	return resolver, nil
+}
+
+func newDNSUpstreams(urls []string) ([]util.DNSUpstream, error) {
+	var upstreams []util.DNSUpstream
+	for _, u := range urls {
+		upstream, err := util.NewDNSUpstream(u)
+		if err != nil {
+			return nil, err
+		}
+		upstreams = append(upstreams, upstream)
+	}
+	return upstreams, nil
+}
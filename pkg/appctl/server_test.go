@@ -16,10 +16,13 @@
 package appctl
 
 import (
+	"context"
 	"os"
+	"reflect"
 	"testing"
 
 	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/protocolv2"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -65,17 +68,50 @@ func TestApply2ServerConfig(t *testing.T) {
 
 func TestServerApplyReject(t *testing.T) {
 	cases := []string{
+		"testdata/server_reject_conflicting_mimicry.json",
+		"testdata/server_reject_dns_invalid_upstream.json",
+		"testdata/server_reject_dns_override_no_target.json",
+		"testdata/server_reject_drain_timeout_too_long.json",
+		"testdata/server_reject_egress_mieru_proxy_no_password.json",
+		"testdata/server_reject_egress_policy_invalid_ip_range.json",
+		"testdata/server_reject_egress_policy_invalid_user_ip_range.json",
+		"testdata/server_reject_egress_policy_invalid_user_port.json",
+		"testdata/server_reject_egress_source_invalid_ipv4.json",
+		"testdata/server_reject_egress_user_in_two_rules.json",
+		"testdata/server_reject_flow_control_window_too_big.json",
+		"testdata/server_reject_invalid_access_window.json",
+		"testdata/server_reject_invalid_blocked_ip_range.json",
 		"testdata/server_reject_invalid_port_range_1.json",
 		"testdata/server_reject_invalid_port_range_2.json",
 		"testdata/server_reject_invalid_port_range_3.json",
 		"testdata/server_reject_invalid_quota_days.json",
 		"testdata/server_reject_invalid_quota_megabytes.json",
+		"testdata/server_reject_invalid_quota_throttle.json",
+		"testdata/server_reject_invalid_rate_limit.json",
+		"testdata/server_reject_ip_ban_time_too_short.json",
+		"testdata/server_reject_log_shipping_invalid_address.json",
+		"testdata/server_reject_mimicry_protocol_mismatch.json",
 		"testdata/server_reject_mtu_too_big.json",
 		"testdata/server_reject_mtu_too_small.json",
+		"testdata/server_reject_negative_connection_limit.json",
+		"testdata/server_reject_negative_session_capacity.json",
 		"testdata/server_reject_no_password.json",
 		"testdata/server_reject_no_port.json",
 		"testdata/server_reject_no_protocol.json",
 		"testdata/server_reject_no_user_name.json",
+		"testdata/server_reject_port_rotation_no_secret.json",
+		"testdata/server_reject_port_rotation_overlap.json",
+		"testdata/server_reject_privilege_no_user.json",
+		"testdata/server_reject_privilege_relative_chroot.json",
+		"testdata/server_reject_redacted_password.json",
+		"testdata/server_reject_replay_cache_expire_too_long.json",
+		"testdata/server_reject_replay_cache_too_small.json",
+		"testdata/server_reject_retransmission_min_bigger_than_max.json",
+		"testdata/server_reject_statsd_invalid_address.json",
+		"testdata/server_reject_timing_jitter_too_long.json",
+		"testdata/server_reject_underlay_read_timeout_too_short.json",
+		"testdata/server_reject_tracing_invalid_sample_ratio.json",
+		"testdata/server_reject_webhook_unknown_event.json",
 	}
 
 	for _, c := range cases {
@@ -144,6 +180,103 @@ func TestServerHashUserPassword(t *testing.T) {
 	afterServerTest(t)
 }
 
+func TestServerIPBlocklist(t *testing.T) {
+	beforeServerTest(t)
+	defer protocolv2.SetIPBlocklist(nil)
+
+	configFile := "testdata/server_apply_config_2.json"
+	if err := ApplyJSONServerConfig(configFile); err != nil {
+		t.Fatalf("ApplyJSONServerConfig() failed: %v", err)
+	}
+	s := NewServerLifecycleService()
+	ctx := context.Background()
+	if _, err := s.BanIP(ctx, &pb.BanIPRequest{IpRange: proto.String("192.0.2.77/24")}); err != nil {
+		t.Fatalf("BanIP() failed: %v", err)
+	}
+	if _, err := s.BanIP(ctx, &pb.BanIPRequest{IpRange: proto.String("192.0.2.0/24")}); err == nil {
+		t.Errorf("BanIP() with IP range already blocked succeeded")
+	}
+	if _, err := s.BanIP(ctx, &pb.BanIPRequest{IpRange: proto.String("scanner")}); err == nil {
+		t.Errorf("BanIP() with invalid IP range succeeded")
+	}
+
+	// The blocklist is kept after another config is applied.
+	if err := ApplyJSONServerConfig(configFile); err != nil {
+		t.Fatalf("ApplyJSONServerConfig() failed: %v", err)
+	}
+	bans, err := s.GetBannedIPs(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatalf("GetBannedIPs() failed: %v", err)
+	}
+	if len(bans.GetBannedIPs()) != 1 || bans.GetBannedIPs()[0].GetIp() != "192.0.2.0/24" || !bans.GetBannedIPs()[0].GetBlocklisted() {
+		t.Errorf("GetBannedIPs() = %v, want blocklisted 192.0.2.0/24", bans.GetBannedIPs())
+	}
+
+	if _, err := s.UnbanIP(ctx, &pb.UnbanIPRequest{Ip: proto.String("192.0.2.0/24")}); err != nil {
+		t.Errorf("UnbanIP() failed: %v", err)
+	}
+	if _, err := s.UnbanIP(ctx, &pb.UnbanIPRequest{Ip: proto.String("192.0.2.0/24")}); err == nil {
+		t.Errorf("UnbanIP() with IP range not blocked succeeded")
+	}
+	config, err := LoadServerConfig()
+	if err != nil {
+		t.Fatalf("LoadServerConfig() failed: %v", err)
+	}
+	if len(config.GetBlockedIPRanges()) != 0 {
+		t.Errorf("got blocked IP ranges %v, want none", config.GetBlockedIPRanges())
+	}
+
+	afterServerTest(t)
+}
+
+func TestServerPortBinding(t *testing.T) {
+	beforeServerTest(t)
+
+	configFile := "testdata/server_apply_config_2.json"
+	if err := ApplyJSONServerConfig(configFile); err != nil {
+		t.Fatalf("ApplyJSONServerConfig() failed: %v", err)
+	}
+	s := NewServerLifecycleService()
+	ctx := context.Background()
+	binding, err := ParsePortBinding("443/tcp")
+	if err != nil {
+		t.Fatalf("ParsePortBinding() failed: %v", err)
+	}
+	if _, err := s.AddPortBinding(ctx, binding); err != nil {
+		t.Fatalf("AddPortBinding() failed: %v", err)
+	}
+	if _, err := s.AddPortBinding(ctx, binding); err == nil {
+		t.Errorf("AddPortBinding() with port already bound succeeded")
+	}
+
+	// Delete ports in the middle of a port range.
+	binding, err = ParsePortBinding("12001-12998/tcp")
+	if err != nil {
+		t.Fatalf("ParsePortBinding() failed: %v", err)
+	}
+	if _, err := s.DeletePortBinding(ctx, binding); err != nil {
+		t.Fatalf("DeletePortBinding() failed: %v", err)
+	}
+	if _, err := s.DeletePortBinding(ctx, binding); err == nil {
+		t.Errorf("DeletePortBinding() with port not bound succeeded")
+	}
+
+	config, err := LoadServerConfig()
+	if err != nil {
+		t.Fatalf("LoadServerConfig() failed: %v", err)
+	}
+	var got []string
+	for _, b := range config.GetPortBindings() {
+		got = append(got, portBindingString(b))
+	}
+	want := []string{"9000/TCP", "10000-11000/UDP", "12000/TCP", "12999-13000/TCP", "443/TCP"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got port bindings %v, want %v", got, want)
+	}
+
+	afterServerTest(t)
+}
+
 func beforeServerTest(t *testing.T) {
 	dir := os.TempDir()
 	if dir == "" {
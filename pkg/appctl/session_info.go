@@ -0,0 +1,106 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"google.golang.org/protobuf/proto"
+)
+
+// sessionInfoWatchInterval is the interval to send session info to watchers.
+const sessionInfoWatchInterval = time.Second
+
+// sessionInfo returns the sessions of the multiplexer in both human readable
+// and machine readable formats.
+func sessionInfo(mux *protocolv2.Mux) *pb.SessionInfo {
+	stats := mux.Stats()
+	res := &pb.SessionInfo{
+		Table: protocolv2.FormatSessionInfoTable(stats.Sessions),
+	}
+	for _, si := range stats.Sessions {
+		entry := &pb.SessionEntry{
+			Id:              proto.Uint32(si.ID),
+			Protocol:        proto.String(si.Protocol),
+			LocalAddr:       proto.String(si.LocalAddr),
+			RemoteAddr:      proto.String(si.RemoteAddr),
+			State:           proto.String(si.State),
+			RecvQueue:       proto.Int32(int32(si.RecvQueue)),
+			RecvBuf:         proto.Int32(int32(si.RecvBuf)),
+			SendQueue:       proto.Int32(int32(si.SendQueue)),
+			SendBuf:         proto.Int32(int32(si.SendBuf)),
+			LastRecvMillis:  proto.Int64(si.LastRecv.Milliseconds()),
+			LastSendMillis:  proto.Int64(si.LastSend.Milliseconds()),
+			BytesRead:       proto.Int64(si.BytesRead),
+			BytesWritten:    proto.Int64(si.BytesWritten),
+			AgeMillis:       proto.Int64(si.Age.Milliseconds()),
+			SegmentsSent:    proto.Int64(si.SegmentsSent),
+			Retransmits:     proto.Int64(si.Retransmits),
+			PeerWindow:      proto.Int32(int32(si.PeerWindow)),
+			RecvWindow:      proto.Int32(int32(si.RecvWindow)),
+			ProtocolVersion: proto.Int32(int32(si.ProtocolVersion)),
+		}
+		if si.CongestionWindow > 0 {
+			entry.CongestionWindow = proto.Int32(int32(si.CongestionWindow))
+		}
+		if si.SmoothedRTT > 0 {
+			entry.RttMillis = proto.Int64(si.SmoothedRTT.Milliseconds())
+		}
+		res.Sessions = append(res.Sessions, entry)
+	}
+	for _, ui := range stats.Underlays {
+		entry := &pb.UnderlayEntry{
+			Protocol:         proto.String(ui.Protocol),
+			LocalAddr:        proto.String(ui.LocalAddr),
+			RemoteAddr:       proto.String(ui.RemoteAddr),
+			SessionCount:     proto.Int32(int32(ui.Sessions)),
+			BytesSent:        proto.Int64(ui.BytesSent),
+			BytesReceived:    proto.Int64(ui.BytesReceived),
+			SegmentsSent:     proto.Int64(ui.SegmentsSent),
+			SegmentsReceived: proto.Int64(ui.SegmentsReceived),
+		}
+		if ui.LastRecv > 0 {
+			entry.LastRecvMillis = proto.Int64(ui.LastRecv.Milliseconds())
+			entry.KeepalivePending = proto.Bool(ui.KeepalivePending)
+		}
+		res.Underlays = append(res.Underlays, entry)
+	}
+	return res
+}
+
+// watchSessionInfo sends the session info of the multiplexer at every
+// interval until ctx is done or send fails.
+func watchSessionInfo(ctx context.Context, mux *protocolv2.Mux, send func(*pb.SessionInfo) error) error {
+	if mux == nil {
+		return fmt.Errorf("multiplexier is unavailable")
+	}
+	ticker := time.NewTicker(sessionInfoWatchInterval)
+	defer ticker.Stop()
+	for {
+		if err := send(sessionInfo(mux)); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/protocolv2"
+)
+
+func TestWatchSessionInfo(t *testing.T) {
+	mux := protocolv2.NewMux(true)
+	defer mux.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := 0
+	send := func(info *pb.SessionInfo) error {
+		if len(info.GetTable()) != 1 {
+			t.Errorf("got %d lines in session table, want only the header", len(info.GetTable()))
+		}
+		updates++
+		if updates == 2 {
+			cancel()
+		}
+		return nil
+	}
+	if err := watchSessionInfo(ctx, mux, send); err != nil {
+		t.Fatalf("watchSessionInfo() failed: %v", err)
+	}
+	if updates != 2 {
+		t.Errorf("got %d updates, want 2", updates)
+	}
+
+	if err := watchSessionInfo(context.Background(), nil, send); err == nil {
+		t.Errorf("watchSessionInfo() returned no error without multiplexier")
+	}
+}
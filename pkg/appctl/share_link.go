@@ -0,0 +1,343 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/util/yaml"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// Default ports used when a foreign configuration is imported into
+	// an empty client configuration.
+	defaultImportSocks5Port = 1080
+	defaultImportRPCPort    = 8964
+)
+
+// IsMieruURL returns true if the string is a URL exported by mieru.
+func IsMieruURL(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "mieru://")
+}
+
+// ForeignConfigToClientProfiles converts the configuration of other proxy
+// software to mieru client profile skeletons. The input can be one or more
+// share links (ss://, vmess://, vless://, trojan://), a base64 encoded list
+// of share links, or a Clash YAML configuration.
+//
+// Only the server address, the port and the password can be mapped from
+// foreign configurations. The fields that can't be mapped, typically the
+// user name, are left empty.
+func ForeignConfigToClientProfiles(input string) ([]*pb.ClientProfile, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("input is empty")
+	}
+	if !strings.Contains(input, "://") {
+		if b, err := decodeBase64(input); err == nil && strings.Contains(string(b), "://") {
+			input = strings.TrimSpace(string(b))
+		}
+	}
+
+	var profiles []*pb.ClientProfile
+	if strings.Contains(input, "://") && !strings.Contains(input, "proxies:") {
+		for _, link := range strings.Fields(input) {
+			profile, err := shareLinkToClientProfile(link)
+			if err != nil {
+				return nil, err
+			}
+			profiles = append(profiles, profile)
+		}
+	} else {
+		var err error
+		profiles, err = clashConfigToClientProfiles([]byte(input))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no proxy server is found")
+	}
+	uniqueProfileNames(profiles)
+	return profiles, nil
+}
+
+// ImportForeignClientConfig imports the configuration of other proxy software
+// to client config. The prompt function is called to collect the value of
+// each required field that can't be mapped.
+func ImportForeignClientConfig(input string, prompt func(profileName, field string) (string, error)) error {
+	profiles, err := ForeignConfigToClientProfiles(input)
+	if err != nil {
+		return fmt.Errorf("ForeignConfigToClientProfiles() failed: %w", err)
+	}
+	for _, profile := range profiles {
+		if profile.GetUser().GetName() == "" {
+			v, err := prompt(profile.GetProfileName(), "user name")
+			if err != nil {
+				return err
+			}
+			profile.User.Name = proto.String(v)
+		}
+		if profile.GetUser().GetPassword() == "" {
+			v, err := prompt(profile.GetProfileName(), "password")
+			if err != nil {
+				return err
+			}
+			profile.User.Password = proto.String(v)
+		}
+	}
+
+	config, err := LoadClientConfig()
+	if err != nil {
+		return fmt.Errorf("LoadClientConfig() failed: %w", err)
+	}
+	c := &pb.ClientConfig{Profiles: profiles}
+	if config.GetActiveProfile() == "" {
+		c.ActiveProfile = proto.String(profiles[0].GetProfileName())
+	}
+	if config.GetSocks5Port() == 0 {
+		c.Socks5Port = proto.Int32(defaultImportSocks5Port)
+	}
+	if config.GetRpcPort() == 0 {
+		c.RpcPort = proto.Int32(defaultImportRPCPort)
+	}
+	return applyClientConfig(c)
+}
+
+// shareLinkToClientProfile converts a single share link to client profile.
+func shareLinkToClientProfile(link string) (*pb.ClientProfile, error) {
+	scheme, _, found := strings.Cut(link, "://")
+	if !found {
+		return nil, fmt.Errorf("%q is not a share link", link)
+	}
+	switch strings.ToLower(scheme) {
+	case "ss":
+		return shadowsocksLinkToClientProfile(link)
+	case "vmess":
+		return vmessLinkToClientProfile(link)
+	case "vless", "trojan":
+		u, err := url.Parse(link)
+		if err != nil {
+			return nil, fmt.Errorf("url.Parse() failed: %w", err)
+		}
+		return newImportedTCPProfile(u.Fragment, u.Hostname(), u.Port(), u.User.Username())
+	default:
+		return nil, fmt.Errorf("unsupported share link scheme %q", scheme)
+	}
+}
+
+// shadowsocksLinkToClientProfile supports both SIP002 format
+// "ss://userinfo@host:port#tag" and legacy format
+// "ss://base64(method:password@host:port)#tag".
+func shadowsocksLinkToClientProfile(link string) (*pb.ClientProfile, error) {
+	body := strings.TrimPrefix(link[len("ss"):], "://")
+	body, tag, _ := strings.Cut(body, "#")
+	if tag != "" {
+		if t, err := url.PathUnescape(tag); err == nil {
+			tag = t
+		}
+	}
+	if !strings.Contains(body, "@") {
+		b, err := decodeBase64(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shadowsocks link: %w", err)
+		}
+		body = string(b)
+	}
+	i := strings.LastIndex(body, "@")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid shadowsocks link: server address is not found")
+	}
+	userInfo := body[:i]
+	hostPort, _, _ := strings.Cut(body[i+1:], "/")
+	hostPort, _, _ = strings.Cut(hostPort, "?")
+	if u, err := url.PathUnescape(userInfo); err == nil {
+		userInfo = u
+	}
+	if !strings.Contains(userInfo, ":") {
+		b, err := decodeBase64(userInfo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shadowsocks link: %w", err)
+		}
+		userInfo = string(b)
+	}
+	_, password, found := strings.Cut(userInfo, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid shadowsocks link: password is not found")
+	}
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shadowsocks link: %w", err)
+	}
+	return newImportedTCPProfile(tag, host, port, password)
+}
+
+// vmessLinkToClientProfile supports "vmess://base64(JSON)" format.
+func vmessLinkToClientProfile(link string) (*pb.ClientProfile, error) {
+	b, err := decodeBase64(link[len("vmess://"):])
+	if err != nil {
+		return nil, fmt.Errorf("invalid vmess link: %w", err)
+	}
+	var v struct {
+		PS   string          `json:"ps"`
+		Add  string          `json:"add"`
+		Port json.RawMessage `json:"port"`
+		ID   string          `json:"id"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("invalid vmess link: %w", err)
+	}
+	// Port can be either a number or a string.
+	port := strings.Trim(string(v.Port), "\"")
+	return newImportedTCPProfile(v.PS, v.Add, port, v.ID)
+}
+
+// clashConfigToClientProfiles converts the proxies in a Clash YAML
+// configuration to client profiles.
+func clashConfigToClientProfiles(data []byte) ([]*pb.ClientProfile, error) {
+	doc, err := yaml.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Unmarshal() failed: %w", err)
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("input is neither a share link nor a Clash configuration")
+	}
+	proxies, ok := root["proxies"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("proxies is not found in Clash configuration")
+	}
+	profiles := make([]*pb.ClientProfile, 0, len(proxies))
+	for i, item := range proxies {
+		proxy, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("proxy %d in Clash configuration is not a mapping", i)
+		}
+		name := yamlString(proxy["name"])
+		password := yamlString(proxy["password"])
+		if password == "" {
+			password = yamlString(proxy["uuid"])
+		}
+		binding := &pb.PortBinding{Protocol: pb.TransportProtocol_TCP.Enum()}
+		isMieru := yamlString(proxy["type"]) == "mieru"
+		if portRange := yamlString(proxy["port-range"]); isMieru && proxy["port"] == nil && portRange != "" {
+			binding.PortRange = proto.String(portRange)
+		} else if binding.Port, err = parsePort(yamlString(proxy["port"])); err != nil {
+			return nil, fmt.Errorf("proxy %d in Clash configuration: %w", i, err)
+		}
+		if isMieru && strings.EqualFold(yamlString(proxy["transport"]), "UDP") {
+			binding.Protocol = pb.TransportProtocol_UDP.Enum()
+		}
+		profile, err := newImportedProfile(name, yamlString(proxy["server"]), password, binding)
+		if err != nil {
+			return nil, fmt.Errorf("proxy %d in Clash configuration: %w", i, err)
+		}
+		if isMieru {
+			// Clash configuration of mieru can be mapped completely.
+			profile.User.Name = proto.String(yamlString(proxy["username"]))
+			if level, ok := pb.MultiplexingLevel_value[yamlString(proxy["multiplexing"])]; ok {
+				profile.Multiplexing = &pb.MultiplexingConfig{
+					Level: pb.MultiplexingLevel(level).Enum(),
+				}
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// newImportedProfile creates a client profile skeleton with a single server.
+func newImportedProfile(name, host, password string, binding *pb.PortBinding) (*pb.ClientProfile, error) {
+	host = strings.Trim(host, "[]")
+	if host == "" {
+		return nil, fmt.Errorf("server address is not found")
+	}
+	if name == "" {
+		name = "imported-" + host
+	}
+	server := &pb.ServerEndpoint{
+		PortBindings: []*pb.PortBinding{binding},
+	}
+	if net.ParseIP(host) != nil {
+		server.IpAddress = proto.String(host)
+	} else {
+		server.DomainName = proto.String(host)
+	}
+	return &pb.ClientProfile{
+		ProfileName: proto.String(name),
+		User: &pb.User{
+			Password: proto.String(password),
+		},
+		Servers: []*pb.ServerEndpoint{server},
+	}, nil
+}
+
+// newImportedTCPProfile creates a client profile skeleton with a single
+// server that listens on a TCP port.
+func newImportedTCPProfile(name, host, port, password string) (*pb.ClientProfile, error) {
+	portNum, err := parsePort(port)
+	if err != nil {
+		return nil, err
+	}
+	return newImportedProfile(name, host, password, &pb.PortBinding{
+		Port:     portNum,
+		Protocol: pb.TransportProtocol_TCP.Enum(),
+	})
+}
+
+func parsePort(port string) (*int32, error) {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return nil, fmt.Errorf("invalid server port %q", port)
+	}
+	return proto.Int32(int32(n)), nil
+}
+
+// uniqueProfileNames appends a suffix to duplicated profile names.
+func uniqueProfileNames(profiles []*pb.ClientProfile) {
+	seen := make(map[string]int)
+	for _, profile := range profiles {
+		name := profile.GetProfileName()
+		seen[name]++
+		if seen[name] > 1 {
+			profile.ProfileName = proto.String(fmt.Sprintf("%s-%d", name, seen[name]))
+		}
+	}
+}
+
+// decodeBase64 decodes standard or URL base64 encoding, with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.Join(strings.Fields(s), ""), "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+func yamlString(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
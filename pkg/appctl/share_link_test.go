@@ -0,0 +1,186 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"encoding/base64"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func importedTCPProfile(name, ip, domain string, port int32, password string) *pb.ClientProfile {
+	server := &pb.ServerEndpoint{
+		PortBindings: []*pb.PortBinding{
+			{
+				Port:     proto.Int32(port),
+				Protocol: pb.TransportProtocol_TCP.Enum(),
+			},
+		},
+	}
+	if ip != "" {
+		server.IpAddress = proto.String(ip)
+	}
+	if domain != "" {
+		server.DomainName = proto.String(domain)
+	}
+	return &pb.ClientProfile{
+		ProfileName: proto.String(name),
+		User: &pb.User{
+			Password: proto.String(password),
+		},
+		Servers: []*pb.ServerEndpoint{server},
+	}
+}
+
+func TestForeignConfigToClientProfiles(t *testing.T) {
+	vmess := "vmess://" + base64.StdEncoding.EncodeToString([]byte(`{"v":"2","ps":"vm","add":"example.com","port":"443","id":"b831381d-6324-4d53-ad4f-8cda48b30811","net":"ws"}`))
+	testcases := []struct {
+		name  string
+		input string
+		want  []*pb.ClientProfile
+	}{
+		{
+			name:  "shadowsocks SIP002",
+			input: "ss://YWVzLTI1Ni1nY206cGFzc3dvcmQ@1.2.3.4:8388/?plugin=obfs#my%20server",
+			want:  []*pb.ClientProfile{importedTCPProfile("my server", "1.2.3.4", "", 8388, "password")},
+		},
+		{
+			name:  "shadowsocks SIP002 without encoding",
+			input: "ss://2022-blake3-aes-128-gcm:c2VjcmV0@[2001:db8::1]:443",
+			want:  []*pb.ClientProfile{importedTCPProfile("imported-2001:db8::1", "2001:db8::1", "", 443, "c2VjcmV0")},
+		},
+		{
+			name:  "shadowsocks legacy",
+			input: "ss://" + base64.StdEncoding.EncodeToString([]byte("aes-128-gcm:pass:word@example.com:8388")) + "#legacy",
+			want:  []*pb.ClientProfile{importedTCPProfile("legacy", "", "example.com", 8388, "pass:word")},
+		},
+		{
+			name:  "vmess",
+			input: vmess,
+			want:  []*pb.ClientProfile{importedTCPProfile("vm", "", "example.com", 443, "b831381d-6324-4d53-ad4f-8cda48b30811")},
+		},
+		{
+			name:  "vless and trojan",
+			input: "vless://uuid@1.2.3.4:443?security=tls#a\ntrojan://secret@example.com:443#a",
+			want: []*pb.ClientProfile{
+				importedTCPProfile("a", "1.2.3.4", "", 443, "uuid"),
+				importedTCPProfile("a-2", "", "example.com", 443, "secret"),
+			},
+		},
+		{
+			name:  "base64 subscription",
+			input: base64.StdEncoding.EncodeToString([]byte("trojan://secret@example.com:443#t\n")),
+			want:  []*pb.ClientProfile{importedTCPProfile("t", "", "example.com", 443, "secret")},
+		},
+		{
+			name: "clash",
+			input: `
+proxies:
+  - name: ss1
+    type: ss
+    server: 1.2.3.4
+    port: 8388
+    cipher: aes-256-gcm
+    password: "p#ss"
+  - {name: vm, type: vmess, server: example.com, port: 443, uuid: id}
+rules:
+  - MATCH,DIRECT
+`,
+			want: []*pb.ClientProfile{
+				importedTCPProfile("ss1", "1.2.3.4", "", 8388, "p#ss"),
+				importedTCPProfile("vm", "", "example.com", 443, "id"),
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ForeignConfigToClientProfiles(tc.input)
+			if err != nil {
+				t.Fatalf("ForeignConfigToClientProfiles() failed: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d profiles, want %d", len(got), len(tc.want))
+			}
+			for i := range got {
+				if !proto.Equal(got[i], tc.want[i]) {
+					t.Errorf("profile %d: got %v, want %v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClashMieruConfigToClientProfiles(t *testing.T) {
+	input := `
+proxies:
+  - name: mieru
+    type: mieru
+    server: 1.2.3.4
+    port-range: 2090-2099
+    transport: UDP
+    username: user
+    password: password
+    multiplexing: MULTIPLEXING_LOW
+`
+	got, err := ForeignConfigToClientProfiles(input)
+	if err != nil {
+		t.Fatalf("ForeignConfigToClientProfiles() failed: %v", err)
+	}
+	want := &pb.ClientProfile{
+		ProfileName: proto.String("mieru"),
+		User: &pb.User{
+			Name:     proto.String("user"),
+			Password: proto.String("password"),
+		},
+		Servers: []*pb.ServerEndpoint{
+			{
+				IpAddress: proto.String("1.2.3.4"),
+				PortBindings: []*pb.PortBinding{
+					{
+						PortRange: proto.String("2090-2099"),
+						Protocol:  pb.TransportProtocol_UDP.Enum(),
+					},
+				},
+			},
+		},
+		Multiplexing: &pb.MultiplexingConfig{
+			Level: pb.MultiplexingLevel_MULTIPLEXING_LOW.Enum(),
+		},
+	}
+	if len(got) != 1 || !proto.Equal(got[0], want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestForeignConfigToClientProfilesReject(t *testing.T) {
+	testcases := []string{
+		"",
+		"http://example.com",
+		"ss://YWVzLTI1Ni1nY206cGFzc3dvcmQ@1.2.3.4",
+		"trojan://secret@example.com:70000",
+		"proxies: []",
+		"mixed-port: 7890",
+	}
+	for _, input := range testcases {
+		if _, err := ForeignConfigToClientProfiles(input); err == nil {
+			t.Errorf("ForeignConfigToClientProfiles(%q) returned no error", input)
+		}
+	}
+}
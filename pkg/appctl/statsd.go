@@ -0,0 +1,64 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/metrics"
+)
+
+// validateStatsdExport checks the StatsD export settings.
+func validateStatsdExport(statsd *pb.StatsdExport) error {
+	if statsd == nil {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(statsd.GetAddress())
+	if err != nil {
+		return fmt.Errorf("statsd: invalid address %q: %w", statsd.GetAddress(), err)
+	}
+	if host == "" {
+		return fmt.Errorf("statsd: host of address %q is empty", statsd.GetAddress())
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("statsd: port number of address %q is invalid", statsd.GetAddress())
+	}
+	if statsd.IntervalSeconds != nil && statsd.GetIntervalSeconds() <= 0 {
+		return fmt.Errorf("statsd: interval %d seconds is invalid", statsd.GetIntervalSeconds())
+	}
+	return nil
+}
+
+// StartStatsdExport starts pushing metrics to the StatsD server.
+// It does nothing if StatsD export is not configured.
+func StartStatsdExport(statsd *pb.StatsdExport) error {
+	if statsd.GetAddress() == "" {
+		return nil
+	}
+	if err := validateStatsdExport(statsd); err != nil {
+		return err
+	}
+	return metrics.StartStatsd(metrics.StatsdConfig{
+		Address:  statsd.GetAddress(),
+		Interval: time.Duration(statsd.GetIntervalSeconds()) * time.Second,
+		Prefix:   statsd.GetPrefix(),
+		Tags:     statsd.GetTags(),
+	})
+}
@@ -0,0 +1,269 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// defaultSubscriptionRefreshInterval is used when the refresh interval
+	// of a subscription is not set.
+	defaultSubscriptionRefreshInterval = 24 * time.Hour
+
+	// subscriptionCheckInterval is how often the client daemon checks
+	// if a subscription should be refreshed.
+	subscriptionCheckInterval = time.Minute
+
+	// subscriptionFetchTimeout is the timeout to download a subscription.
+	subscriptionFetchTimeout = 30 * time.Second
+
+	// maxSubscriptionSize is the maximum number of bytes of a subscription.
+	maxSubscriptionSize = 1024 * 1024
+)
+
+var (
+	// subscriptionMu serializes subscription updates.
+	subscriptionMu sync.Mutex
+
+	// subscriptionLastRefresh maps profile name to the last time
+	// its subscription was downloaded by this process.
+	subscriptionLastRefresh = map[string]time.Time{}
+
+	// subscriptionHTTPClient downloads subscriptions.
+	subscriptionHTTPClient = &http.Client{Timeout: subscriptionFetchTimeout}
+
+	// clientSubscriptionHook is called after the servers of the
+	// active profile are updated by a subscription.
+	clientSubscriptionHook atomic.Pointer[func(*pb.ClientConfig)]
+)
+
+// SetClientSubscriptionHook sets the function that is called after the
+// servers of the active profile are updated by a subscription. The client
+// daemon uses it to connect to the new servers without a restart.
+func SetClientSubscriptionHook(f func(config *pb.ClientConfig)) {
+	clientSubscriptionHook.Store(&f)
+}
+
+// UpdateSubscriptions downloads the servers of all client profiles that
+// have a subscription, and stores the client config. It returns the names
+// of profiles whose servers are changed. The servers of a profile are
+// replaced only if the subscription is downloaded and validated successfully.
+func UpdateSubscriptions(ctx context.Context) ([]string, error) {
+	return updateSubscriptions(ctx, func(*pb.ClientProfile) bool { return true })
+}
+
+// StartSubscriptionRefresh refreshes the subscriptions in the background
+// until ctx is done. Each subscription is downloaded when the daemon starts,
+// and then at the refresh interval of the subscription.
+func StartSubscriptionRefresh(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(subscriptionCheckInterval)
+		defer ticker.Stop()
+		for {
+			updated, err := updateSubscriptions(ctx, subscriptionDue)
+			if err != nil {
+				log.Warnf("refresh subscription failed: %v", err)
+			}
+			for _, name := range updated {
+				log.Infof("servers of profile %q are updated by subscription", name)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// updateSubscriptions downloads the subscriptions of profiles selected by
+// the due function.
+//
+// Subscriptions are downloaded without holding the client config lock.
+// Before the servers are stored, the client config is loaded again, and
+// only the servers of profiles that still use the same subscription are
+// replaced, so changes made to the client config during the download
+// are not lost.
+func updateSubscriptions(ctx context.Context, due func(*pb.ClientProfile) bool) ([]string, error) {
+	subscriptionMu.Lock()
+	defer subscriptionMu.Unlock()
+
+	config, err := LoadClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("LoadClientConfig() failed: %w", err)
+	}
+	pruneSubscriptionLastRefresh(config)
+
+	// Map profile name to the downloaded servers of the subscription URL.
+	type download struct {
+		url     string
+		servers []*pb.ServerEndpoint
+	}
+	downloads := map[string]download{}
+	var errs []error
+	for _, profile := range config.GetProfiles() {
+		if profile.GetSubscription().GetUrl() == "" || !due(profile) {
+			continue
+		}
+		name := profile.GetProfileName()
+		servers, err := fetchSubscription(ctx, profile)
+		subscriptionLastRefresh[name] = time.Now()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("profile %q: %w", name, err))
+			continue
+		}
+		if serversEqual(profile.GetServers(), servers) {
+			continue
+		}
+		downloads[name] = download{url: profile.GetSubscription().GetUrl(), servers: servers}
+	}
+	if len(downloads) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	var updated []string
+	config, err = updateClientConfig(func(config *pb.ClientConfig) bool {
+		for _, profile := range config.GetProfiles() {
+			name := profile.GetProfileName()
+			d, ok := downloads[name]
+			if !ok || profile.GetSubscription().GetUrl() != d.url || serversEqual(profile.GetServers(), d.servers) {
+				continue
+			}
+			profile.Servers = d.servers
+			updated = append(updated, name)
+		}
+		return len(updated) > 0
+	})
+	if err != nil {
+		return nil, fmt.Errorf("updateClientConfig() failed: %w", err)
+	}
+	for _, name := range updated {
+		if name == config.GetActiveProfile() {
+			if hook := clientSubscriptionHook.Load(); hook != nil {
+				(*hook)(config)
+			}
+		}
+	}
+	return updated, errors.Join(errs...)
+}
+
+// pruneSubscriptionLastRefresh deletes the last refresh time of profiles
+// that are deleted or no longer have a subscription. It must be called
+// when holding subscriptionMu.
+func pruneSubscriptionLastRefresh(config *pb.ClientConfig) {
+	subscribed := map[string]bool{}
+	for _, profile := range config.GetProfiles() {
+		if profile.GetSubscription().GetUrl() != "" {
+			subscribed[profile.GetProfileName()] = true
+		}
+	}
+	for name := range subscriptionLastRefresh {
+		if !subscribed[name] {
+			delete(subscriptionLastRefresh, name)
+		}
+	}
+}
+
+// fetchSubscription downloads and validates the servers of a profile.
+func fetchSubscription(ctx context.Context, profile *pb.ClientProfile) ([]*pb.ServerEndpoint, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, profile.GetSubscription().GetUrl(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext() failed: %w", err)
+	}
+	resp, err := subscriptionHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download subscription failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download subscription failed: HTTP status %s", resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxSubscriptionSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("download subscription failed: %w", err)
+	}
+	if len(b) > maxSubscriptionSize {
+		return nil, fmt.Errorf("subscription is larger than %d bytes", maxSubscriptionSize)
+	}
+	content := &pb.SubscriptionContent{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, content); err != nil {
+		return nil, fmt.Errorf("protojson.Unmarshal() failed: %w", err)
+	}
+	if len(content.GetServers()) == 0 {
+		return nil, fmt.Errorf("subscription has no server")
+	}
+	check := proto.Clone(profile).(*pb.ClientProfile)
+	check.Servers = content.GetServers()
+	if err := ValidateClientConfigPatch(&pb.ClientConfig{Profiles: []*pb.ClientProfile{check}}); err != nil {
+		return nil, fmt.Errorf("invalid subscription: %w", err)
+	}
+	return content.GetServers(), nil
+}
+
+// subscriptionDue returns true if the subscription of the profile should
+// be refreshed. It must be called when holding subscriptionMu.
+func subscriptionDue(profile *pb.ClientProfile) bool {
+	last, ok := subscriptionLastRefresh[profile.GetProfileName()]
+	if !ok {
+		return true
+	}
+	interval := defaultSubscriptionRefreshInterval
+	if minutes := profile.GetSubscription().GetRefreshIntervalMinutes(); minutes > 0 {
+		interval = time.Duration(minutes) * time.Minute
+	}
+	return time.Since(last) >= interval
+}
+
+// validateSubscription returns an error if the subscription is invalid.
+func validateSubscription(subscription *pb.Subscription) error {
+	u, err := url.Parse(subscription.GetUrl())
+	if err != nil {
+		return fmt.Errorf("invalid subscription URL: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("subscription URL %q is not a HTTPS URL", subscription.GetUrl())
+	}
+	if subscription.GetRefreshIntervalMinutes() < 0 {
+		return fmt.Errorf("subscription refresh interval %d is negative", subscription.GetRefreshIntervalMinutes())
+	}
+	return nil
+}
+
+func serversEqual(a, b []*pb.ServerEndpoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,231 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUpdateSubscriptions(t *testing.T) {
+	var content atomic.Value
+	content.Store(`{"servers": [{"ipAddress": "1.2.3.4", "portBindings": [{"port": 8964, "protocol": "TCP"}]}], "comment": "unknown field"}`)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, content.Load().(string))
+	}))
+	defer server.Close()
+	subscriptionHTTPClient = server.Client()
+
+	beforeClientTest(t)
+	defer afterClientTest(t)
+	config := &pb.ClientConfig{
+		Profiles: []*pb.ClientProfile{
+			{
+				ProfileName: proto.String("default"),
+				User: &pb.User{
+					Name:     proto.String("user"),
+					Password: proto.String("password"),
+				},
+				Subscription: &pb.Subscription{
+					Url: proto.String(server.URL),
+				},
+			},
+		},
+		ActiveProfile: proto.String("default"),
+		RpcPort:       proto.Int32(8964),
+		Socks5Port:    proto.Int32(1080),
+	}
+	if err := applyClientConfig(config); err != nil {
+		t.Fatalf("applyClientConfig() failed: %v", err)
+	}
+	var hookCalled atomic.Bool
+	SetClientSubscriptionHook(func(*pb.ClientConfig) { hookCalled.Store(true) })
+	defer clientSubscriptionHook.Store(nil)
+
+	updated, err := UpdateSubscriptions(context.Background())
+	if err != nil {
+		t.Fatalf("UpdateSubscriptions() failed: %v", err)
+	}
+	if len(updated) != 1 || updated[0] != "default" {
+		t.Errorf("got updated profiles %v, want [default]", updated)
+	}
+	if !hookCalled.Load() {
+		t.Errorf("subscription hook is not called")
+	}
+	got, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	if servers := got.GetProfiles()[0].GetServers(); len(servers) != 1 || servers[0].GetIpAddress() != "1.2.3.4" {
+		t.Errorf("servers are not updated: %v", servers)
+	}
+
+	// Nothing is updated if the servers are not changed.
+	updated, err = UpdateSubscriptions(context.Background())
+	if err != nil {
+		t.Fatalf("UpdateSubscriptions() failed: %v", err)
+	}
+	if len(updated) != 0 {
+		t.Errorf("got updated profiles %v, want none", updated)
+	}
+
+	// Invalid servers are not used.
+	content.Store(`{"servers": [{"ipAddress": "1.2.3.4"}]}`)
+	if _, err = UpdateSubscriptions(context.Background()); err == nil {
+		t.Errorf("UpdateSubscriptions() returned no error with invalid servers")
+	}
+	got, err = LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	if servers := got.GetProfiles()[0].GetServers(); len(servers) != 1 || len(servers[0].GetPortBindings()) != 1 {
+		t.Errorf("servers are replaced by invalid subscription: %v", servers)
+	}
+}
+
+func TestUpdateSubscriptionsKeepConcurrentChange(t *testing.T) {
+	// The handler changes the client config while the subscription
+	// is downloaded, like a concurrent "mieru apply config".
+	var change atomic.Pointer[func(*pb.ClientConfig)]
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f := change.Load(); f != nil {
+			config, err := LoadClientConfig()
+			if err == nil {
+				(*f)(config)
+				err = StoreClientConfig(config)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		fmt.Fprint(w, `{"servers": [{"ipAddress": "1.2.3.4", "portBindings": [{"port": 8964, "protocol": "TCP"}]}]}`)
+	}))
+	defer server.Close()
+	subscriptionHTTPClient = server.Client()
+
+	beforeClientTest(t)
+	defer afterClientTest(t)
+	config := &pb.ClientConfig{
+		Profiles: []*pb.ClientProfile{
+			{
+				ProfileName: proto.String("default"),
+				User: &pb.User{
+					Name:     proto.String("user"),
+					Password: proto.String("password"),
+				},
+				Subscription: &pb.Subscription{
+					Url: proto.String(server.URL),
+				},
+			},
+		},
+		ActiveProfile: proto.String("default"),
+		RpcPort:       proto.Int32(8964),
+		Socks5Port:    proto.Int32(1080),
+	}
+	if err := applyClientConfig(config); err != nil {
+		t.Fatalf("applyClientConfig() failed: %v", err)
+	}
+
+	// A change to other settings is kept.
+	changeSocks5Port := func(c *pb.ClientConfig) { c.Socks5Port = proto.Int32(1081) }
+	change.Store(&changeSocks5Port)
+	updated, err := UpdateSubscriptions(context.Background())
+	if err != nil {
+		t.Fatalf("UpdateSubscriptions() failed: %v", err)
+	}
+	if len(updated) != 1 || updated[0] != "default" {
+		t.Errorf("got updated profiles %v, want [default]", updated)
+	}
+	got, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	if got.GetSocks5Port() != 1081 {
+		t.Errorf("concurrent change is lost: got socks5 port %d, want 1081", got.GetSocks5Port())
+	}
+	if servers := got.GetProfiles()[0].GetServers(); len(servers) != 1 || servers[0].GetIpAddress() != "1.2.3.4" {
+		t.Errorf("servers are not updated: %v", servers)
+	}
+
+	// Servers are not replaced if the subscription is changed.
+	got.GetProfiles()[0].Servers = nil
+	if err := StoreClientConfig(got); err != nil {
+		t.Fatalf("StoreClientConfig() failed: %v", err)
+	}
+	newURL := server.URL + "/new"
+	changeURL := func(c *pb.ClientConfig) { c.GetProfiles()[0].GetSubscription().Url = proto.String(newURL) }
+	change.Store(&changeURL)
+	updated, err = UpdateSubscriptions(context.Background())
+	if err != nil {
+		t.Fatalf("UpdateSubscriptions() failed: %v", err)
+	}
+	if len(updated) != 0 {
+		t.Errorf("got updated profiles %v, want none", updated)
+	}
+	got, err = LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() failed: %v", err)
+	}
+	if url := got.GetProfiles()[0].GetSubscription().GetUrl(); url != newURL {
+		t.Errorf("got subscription URL %q, want %q", url, newURL)
+	}
+	if servers := got.GetProfiles()[0].GetServers(); len(servers) != 0 {
+		t.Errorf("servers of old subscription are stored: %v", servers)
+	}
+}
+
+func TestPruneSubscriptionLastRefresh(t *testing.T) {
+	subscriptionMu.Lock()
+	defer subscriptionMu.Unlock()
+	saved := subscriptionLastRefresh
+	defer func() {
+		subscriptionLastRefresh = saved
+	}()
+
+	now := time.Now()
+	subscriptionLastRefresh = map[string]time.Time{
+		"kept":         now,
+		"deleted":      now,
+		"unsubscribed": now,
+	}
+	config := &pb.ClientConfig{
+		Profiles: []*pb.ClientProfile{
+			{
+				ProfileName:  proto.String("kept"),
+				Subscription: &pb.Subscription{Url: proto.String("https://example.com")},
+			},
+			{
+				ProfileName: proto.String("unsubscribed"),
+			},
+		},
+	}
+	pruneSubscriptionLastRefresh(config)
+	if len(subscriptionLastRefresh) != 1 {
+		t.Errorf("got %d entries, want 1", len(subscriptionLastRefresh))
+	}
+	if _, ok := subscriptionLastRefresh["kept"]; !ok {
+		t.Errorf("last refresh time of subscribed profile is deleted")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/util"
+	"github.com/enfein/mieru/pkg/util/sysproxy"
+)
+
+// systemProxyBackupFileName stores the proxy settings of the operating
+// system before they are changed by mieru.
+const systemProxyBackupFileName = "system_proxy_backup.json"
+
+// systemProxyBackupPath returns the path of system proxy backup file.
+func systemProxyBackupPath() (string, error) {
+	if _, _, err := clientConfigFilePath(); err != nil {
+		return "", fmt.Errorf("clientConfigFilePath() failed: %w", err)
+	}
+	if err := prepareClientConfigDir(); err != nil {
+		return "", fmt.Errorf("prepareClientConfigDir() failed: %w", err)
+	}
+	return filepath.Join(cachedClientConfigDir, systemProxyBackupFileName), nil
+}
+
+// SystemProxySettings returns the local listeners of the client that are
+// set to the operating system. The HTTP proxy is not used if TLS is enabled,
+// because the operating system expects a plain HTTP proxy.
+func SystemProxySettings(config *pb.ClientConfig) sysproxy.Settings {
+	localAddr := util.MaybeDecorateIPv6(util.LocalIPAddr())
+	settings := sysproxy.Settings{
+		SOCKS5Addr: localAddr + ":" + strconv.Itoa(int(config.GetSocks5Port())),
+	}
+	if config.GetHttpProxyPort() != 0 && !config.GetHttpProxyTLS().GetEnable() {
+		settings.HTTPAddr = localAddr + ":" + strconv.Itoa(int(config.GetHttpProxyPort()))
+	}
+	return settings
+}
+
+// EnableSystemProxy sets the proxy settings of the operating system
+// to the local listeners of the client. The previous settings are saved,
+// unless they are already saved by an earlier call.
+func EnableSystemProxy(config *pb.ClientConfig) error {
+	path, err := systemProxyBackupPath()
+	if err != nil {
+		return err
+	}
+	backup, err := sysproxy.Set(SystemProxySettings(config))
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		// Keep the settings before mieru changed them for the first time.
+		return nil
+	}
+	b, err := json.Marshal(backup)
+	if err != nil {
+		return fmt.Errorf("json.Marshal() failed: %w", err)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("os.WriteFile() failed: %w", err)
+	}
+	return nil
+}
+
+// DisableSystemProxy restores the proxy settings of the operating system
+// saved by EnableSystemProxy.
+func DisableSystemProxy() error {
+	path, err := systemProxyBackupPath()
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("system proxy is not changed by mieru")
+		}
+		return fmt.Errorf("os.ReadFile() failed: %w", err)
+	}
+	var backup sysproxy.Backup
+	if err := json.Unmarshal(b, &backup); err != nil {
+		return fmt.Errorf("json.Unmarshal() failed: %w", err)
+	}
+	if err := sysproxy.Restore(backup); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("os.Remove() failed: %w", err)
+	}
+	return nil
+}
+
+// IsSystemProxyEnabled returns true if the proxy settings of the operating
+// system are changed by mieru and not restored yet.
+func IsSystemProxyEnabled() bool {
+	path, err := systemProxyBackupPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
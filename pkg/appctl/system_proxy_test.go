@@ -0,0 +1,44 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSystemProxySettings(t *testing.T) {
+	config := &pb.ClientConfig{
+		Socks5Port:    proto.Int32(1080),
+		HttpProxyPort: proto.Int32(8080),
+	}
+	settings := SystemProxySettings(config)
+	if !strings.HasSuffix(settings.SOCKS5Addr, ":1080") {
+		t.Errorf("socks5 address is %q, want port 1080", settings.SOCKS5Addr)
+	}
+	if !strings.HasSuffix(settings.HTTPAddr, ":8080") {
+		t.Errorf("HTTP address is %q, want port 8080", settings.HTTPAddr)
+	}
+
+	// The operating system doesn't support HTTPS proxy.
+	config.HttpProxyTLS = &pb.HTTPProxyTLS{Enable: proto.Bool(true)}
+	if settings := SystemProxySettings(config); settings.HTTPAddr != "" {
+		t.Errorf("HTTP address is %q with TLS enabled, want empty", settings.HTTPAddr)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/enfein/mieru/pkg/protocolv2"
+)
+
+// validateTimingJitter checks the timing jitter, if it is set.
+func validateTimingJitter(millis int32) error {
+	if millis < 0 || TimingJitter(millis) > protocolv2.MaxTimingJitter {
+		return fmt.Errorf("timing jitter %d milliseconds is not between 0 and %d", millis, protocolv2.MaxTimingJitter.Milliseconds())
+	}
+	return nil
+}
+
+// TimingJitter converts the timing jitter in config to a duration.
+func TimingJitter(millis int32) time.Duration {
+	return time.Duration(millis) * time.Millisecond
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"net/url"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/tracing"
+)
+
+// validateTracingExport checks the tracing export settings.
+func validateTracingExport(t *pb.TracingExport) error {
+	if t == nil {
+		return nil
+	}
+	u, err := url.Parse(t.GetOtlpEndpoint())
+	if err != nil {
+		return fmt.Errorf("tracing: invalid OTLP endpoint %q: %w", t.GetOtlpEndpoint(), err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("tracing: OTLP endpoint %q is not a HTTP or HTTPS URL", t.GetOtlpEndpoint())
+	}
+	if t.SampleRatio != nil && (t.GetSampleRatio() <= 0 || t.GetSampleRatio() > 1) {
+		return fmt.Errorf("tracing: sample ratio %v is out of range (0, 1]", t.GetSampleRatio())
+	}
+	return nil
+}
+
+// StartTracingExport starts exporting traces to the OTLP receiver.
+// It does nothing if tracing export is not configured.
+func StartTracingExport(t *pb.TracingExport, serviceName string) error {
+	if t.GetOtlpEndpoint() == "" {
+		return nil
+	}
+	if err := validateTracingExport(t); err != nil {
+		return err
+	}
+	return tracing.Enable(tracing.Config{
+		Endpoint:    t.GetOtlpEndpoint(),
+		ServiceName: serviceName,
+		SampleRatio: t.GetSampleRatio(),
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/enfein/mieru/pkg/protocolv2"
+)
+
+const (
+	// minUnderlayTimeoutSeconds is the minimum underlay read timeout and
+	// stuck timeout allowed in config.
+	minUnderlayTimeoutSeconds = 10
+
+	// maxUnderlayTimeoutSeconds is the maximum underlay read timeout and
+	// stuck timeout allowed in config.
+	maxUnderlayTimeoutSeconds = 86400
+)
+
+// validateUnderlayTimeouts checks the underlay read timeout and
+// stuck timeout, if they are set.
+func validateUnderlayTimeouts(readTimeoutSeconds, stuckTimeoutSeconds int32) error {
+	for name, seconds := range map[string]int32{
+		"read timeout":  readTimeoutSeconds,
+		"stuck timeout": stuckTimeoutSeconds,
+	} {
+		if seconds != 0 && (seconds < minUnderlayTimeoutSeconds || seconds > maxUnderlayTimeoutSeconds) {
+			return fmt.Errorf("underlay %s %d seconds is not between %d and %d", name, seconds, minUnderlayTimeoutSeconds, maxUnderlayTimeoutSeconds)
+		}
+	}
+	return nil
+}
+
+// UnderlayTimeoutConfig converts the underlay timeouts in config
+// to the parameters used by TCP underlays.
+func UnderlayTimeoutConfig(readTimeoutSeconds, stuckTimeoutSeconds int32) protocolv2.UnderlayTimeoutConfig {
+	return protocolv2.UnderlayTimeoutConfig{
+		ReadTimeout:  time.Duration(readTimeoutSeconds) * time.Second,
+		StuckTimeout: time.Duration(stuckTimeoutSeconds) * time.Second,
+	}
+}
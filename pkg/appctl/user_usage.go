@@ -0,0 +1,71 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/metrics"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"google.golang.org/protobuf/proto"
+)
+
+// userThroughputWindow is the time window to compute the current
+// throughput of a user.
+const userThroughputWindow = 10 * time.Second
+
+// userUsages returns the live usage of each configured user at time now.
+func userUsages(users []*pb.User, now time.Time) []*pb.UserUsage {
+	activeSessions := protocolv2.ActiveSessionsByUser()
+	res := make([]*pb.UserUsage, 0, len(users))
+	for _, user := range users {
+		usage := &pb.UserUsage{
+			Name:                proto.String(user.GetName()),
+			ActiveSessions:      proto.Int32(int32(activeSessions[user.GetName()])),
+			ReadBytesPerSecond:  proto.Int64(0),
+			WriteBytesPerSecond: proto.Int64(0),
+			ReadBytes:           proto.Int64(0),
+			WriteBytes:          proto.Int64(0),
+		}
+		metricGroup := metrics.GetMetricGroupByName(fmt.Sprintf(metrics.UserMetricGroupFormat, user.GetName()))
+		if metricGroup != nil {
+			if m, ok := metricGroup.GetMetric(metrics.UserMetricReadBytes); ok {
+				counter := m.(*metrics.Counter)
+				usage.ReadBytes = proto.Int64(counter.Load())
+				usage.ReadBytesPerSecond = proto.Int64(counter.DeltaBetween(now.Add(-userThroughputWindow), now) / int64(userThroughputWindow.Seconds()))
+			}
+			if m, ok := metricGroup.GetMetric(metrics.UserMetricWriteBytes); ok {
+				counter := m.(*metrics.Counter)
+				usage.WriteBytes = proto.Int64(counter.Load())
+				usage.WriteBytesPerSecond = proto.Int64(counter.DeltaBetween(now.Add(-userThroughputWindow), now) / int64(userThroughputWindow.Seconds()))
+			}
+		}
+		for _, quota := range protocolv2.UserQuotaUsage(user, now) {
+			usage.Quotas = append(usage.Quotas, &pb.QuotaUsage{
+				Quota:     quota.Quota,
+				UsedBytes: proto.Int64(quota.UsedBytes),
+				Exhausted: proto.Bool(quota.Exhausted),
+			})
+		}
+		if t, ok := protocolv2.UserLastHandshake(user.GetName()); ok {
+			usage.LastHandshakeUnixMilli = proto.Int64(t.UnixMilli())
+		}
+		res = append(res, usage)
+	}
+	return res
+}
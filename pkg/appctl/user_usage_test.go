@@ -0,0 +1,52 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUserUsagesWithoutTraffic(t *testing.T) {
+	users := []*pb.User{
+		{
+			Name: proto.String("idle"),
+			Quotas: []*pb.Quota{
+				{Days: proto.Int32(1), Megabytes: proto.Int32(1024)},
+			},
+		},
+	}
+	usages := userUsages(users, time.Now())
+	if len(usages) != 1 {
+		t.Fatalf("got %d user usages, want 1", len(usages))
+	}
+	usage := usages[0]
+	if usage.GetName() != "idle" {
+		t.Errorf("got user %q, want %q", usage.GetName(), "idle")
+	}
+	if usage.GetActiveSessions() != 0 || usage.GetReadBytes() != 0 || usage.GetWriteBytes() != 0 {
+		t.Errorf("got usage %v, want no sessions and no traffic", usage)
+	}
+	if usage.LastHandshakeUnixMilli != nil {
+		t.Errorf("got last handshake time %d, want unset", usage.GetLastHandshakeUnixMilli())
+	}
+	if len(usage.GetQuotas()) != 1 || usage.GetQuotas()[0].GetUsedBytes() != 0 || usage.GetQuotas()[0].GetExhausted() {
+		t.Errorf("got quota usage %v, want 1 quota with nothing used", usage.GetQuotas())
+	}
+}
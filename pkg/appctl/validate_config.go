@@ -0,0 +1,67 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+)
+
+// ValidateJSONClientConfigFile checks the client config in the JSON or YAML file
+// with the full validation rules. The stored client config is not changed.
+// The returned error includes the location of the invalid field if possible.
+func ValidateJSONClientConfigFile(path string) error {
+	b, err := ReadConfigFileAsJSON(path)
+	if err != nil {
+		return err
+	}
+	c := &pb.ClientConfig{}
+	if err = jsonUnmarshalOption.Unmarshal(b, c); err != nil {
+		return fmt.Errorf("protojson.Unmarshal() failed: %w", err)
+	}
+	for i, profile := range c.GetProfiles() {
+		if err := ValidateClientConfigPatch(&pb.ClientConfig{Profiles: []*pb.ClientProfile{profile}}); err != nil {
+			return fmt.Errorf("profiles[%d] %q: %w", i, profile.GetProfileName(), err)
+		}
+	}
+	return ValidateFullClientConfig(c)
+}
+
+// ValidateJSONServerConfigFile checks the server config in the JSON or YAML file
+// with the full validation rules. The stored server config is not changed.
+// The returned error includes the location of the invalid field if possible.
+func ValidateJSONServerConfigFile(path string) error {
+	b, err := ReadConfigFileAsJSON(path)
+	if err != nil {
+		return err
+	}
+	s := &pb.ServerConfig{}
+	if err = jsonUnmarshalOption.Unmarshal(b, s); err != nil {
+		return fmt.Errorf("protojson.Unmarshal() failed: %w", err)
+	}
+	for i, binding := range s.GetPortBindings() {
+		if _, err := FlatPortBindings([]*pb.PortBinding{binding}); err != nil {
+			return fmt.Errorf("portBindings[%d]: %w", i, err)
+		}
+	}
+	for i, user := range s.GetUsers() {
+		if err := ValidateServerConfigPatch(&pb.ServerConfig{Users: []*pb.User{user}}); err != nil {
+			return fmt.Errorf("users[%d] %q: %w", i, user.GetName(), err)
+		}
+	}
+	return ValidateFullServerConfig(s)
+}
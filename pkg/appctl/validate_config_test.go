@@ -0,0 +1,64 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateJSONClientConfigFile(t *testing.T) {
+	if err := ValidateJSONClientConfigFile("testdata/client_apply_config_1.json"); err != nil {
+		t.Errorf("ValidateJSONClientConfigFile() failed: %v", err)
+	}
+
+	rejects, err := filepath.Glob("testdata/client_reject_*.json")
+	if err != nil {
+		t.Fatalf("filepath.Glob() failed: %v", err)
+	}
+	for _, c := range rejects {
+		if err := ValidateJSONClientConfigFile(c); err == nil {
+			t.Errorf("want error in ValidateJSONClientConfigFile(%q), got no error", c)
+		}
+	}
+
+	err = ValidateJSONClientConfigFile("testdata/client_reject_no_user_name.json")
+	if err == nil || !strings.Contains(err.Error(), `profiles[0] "default": user name is not set`) {
+		t.Errorf("got error %v, want the location of the invalid field", err)
+	}
+}
+
+func TestValidateJSONServerConfigFile(t *testing.T) {
+	if err := ValidateJSONServerConfigFile("testdata/server_apply_config_1.json"); err != nil {
+		t.Errorf("ValidateJSONServerConfigFile() failed: %v", err)
+	}
+
+	rejects, err := filepath.Glob("testdata/server_reject_*.json")
+	if err != nil {
+		t.Fatalf("filepath.Glob() failed: %v", err)
+	}
+	for _, c := range rejects {
+		if err := ValidateJSONServerConfigFile(c); err == nil {
+			t.Errorf("want error in ValidateJSONServerConfigFile(%q), got no error", c)
+		}
+	}
+
+	err = ValidateJSONServerConfigFile("testdata/server_reject_no_user_name.json")
+	if err == nil || !strings.Contains(err.Error(), "users[0]") {
+		t.Errorf("got error %v, want the location of the invalid field", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package appctl
+
+import (
+	"fmt"
+	"net/url"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/event"
+)
+
+// validateWebhookExport checks the webhook settings.
+func validateWebhookExport(w *pb.WebhookExport) error {
+	if w == nil {
+		return nil
+	}
+	u, err := url.Parse(w.GetUrl())
+	if err != nil {
+		return fmt.Errorf("webhook: invalid URL %q: %w", w.GetUrl(), err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook: URL %q is not a HTTP or HTTPS URL", w.GetUrl())
+	}
+	for _, e := range w.GetEvents() {
+		if !event.IsValidType(e) {
+			return fmt.Errorf("webhook: event type %q is unknown", e)
+		}
+	}
+	return nil
+}
+
+// StartWebhookExport starts sending events to the webhook.
+// It does nothing if webhook is not configured.
+func StartWebhookExport(w *pb.WebhookExport, source string) error {
+	if w.GetUrl() == "" {
+		return nil
+	}
+	if err := validateWebhookExport(w); err != nil {
+		return err
+	}
+	types := make([]event.Type, 0, len(w.GetEvents()))
+	for _, e := range w.GetEvents() {
+		types = append(types, event.Type(e))
+	}
+	return event.EnableWebhook(event.WebhookConfig{
+		URL:    w.GetUrl(),
+		Source: source,
+		Types:  types,
+	})
+}
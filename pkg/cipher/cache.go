@@ -40,7 +40,7 @@ func getBlockCipherList(password []byte, stateless bool) ([]BlockCipher, error)
 		c, ok := blockCipherCache.Load(pw)
 		if ok {
 			// Check if the cached entry is expired.
-			if c.(cachedCiphers).createTime.Add(cacheValidInterval).Before(time.Now()) {
+			if c.(cachedCiphers).createTime.Add(cacheValidInterval).Before(Now()) {
 				ok = false
 			}
 		}
@@ -67,7 +67,7 @@ func getBlockCipherList(password []byte, stateless bool) ([]BlockCipher, error)
 }
 
 func newBlockCipherList(password []byte, stateless bool) ([]BlockCipher, time.Time, error) {
-	t := time.Now()
+	t := Now()
 	salts := saltFromTime(t)
 	blockCiphers := make([]BlockCipher, 0, 3)
 	for i := 0; i < 3; i++ {
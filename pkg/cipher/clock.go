@@ -0,0 +1,69 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cipher
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// clockOffset is added to the local clock to get the time used by
+	// mieru protocol, in nanoseconds.
+	clockOffset atomic.Int64
+
+	// clockOffsetHook is called after the clock offset is changed.
+	clockOffsetHook atomic.Pointer[func(time.Duration)]
+)
+
+// Now returns the time used to generate keys and metadata timestamps.
+// It is the local time adjusted by the clock offset.
+func Now() time.Time {
+	return time.Now().Add(ClockOffset())
+}
+
+// ClockOffset returns the difference between the time used by mieru protocol
+// and the local time.
+func ClockOffset() time.Duration {
+	return time.Duration(clockOffset.Load())
+}
+
+// SetClockOffset changes the difference between the time used by mieru
+// protocol and the local time. Cached block ciphers are discarded
+// because they were generated from the old time.
+func SetClockOffset(offset time.Duration) {
+	if clockOffset.Swap(int64(offset)) == int64(offset) {
+		return
+	}
+	blockCipherCache.Range(func(key, _ any) bool {
+		blockCipherCache.Delete(key)
+		return true
+	})
+	if hook := clockOffsetHook.Load(); hook != nil {
+		(*hook)(offset)
+	}
+}
+
+// SetClockOffsetHook sets the function that is called after the clock
+// offset is changed. The client daemon uses it to save the clock offset,
+// so it can be used after a restart. A nil function removes the hook.
+func SetClockOffsetHook(f func(offset time.Duration)) {
+	if f == nil {
+		clockOffsetHook.Store(nil)
+		return
+	}
+	clockOffsetHook.Store(&f)
+}
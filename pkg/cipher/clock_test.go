@@ -0,0 +1,74 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cipher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetClockOffset(t *testing.T) {
+	defer SetClockOffset(0)
+	password := []byte{0x08, 0x09, 0x06, 0x04}
+	plaintext := []byte("clock offset")
+
+	block, err := BlockCipherFromPassword(password, true)
+	if err != nil {
+		t.Fatalf("BlockCipherFromPassword() failed: %v", err)
+	}
+	ciphertext, err := block.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() failed: %v", err)
+	}
+
+	// A peer with a clock that is 10 minutes ahead can't decrypt it.
+	SetClockOffset(10 * time.Minute)
+	if d := time.Until(Now()); d < 9*time.Minute || d > 11*time.Minute {
+		t.Errorf("Now() is %v after local time, want about 10 minutes", d)
+	}
+	if _, _, err := TryDecrypt(ciphertext, password, true); err == nil {
+		t.Errorf("TryDecrypt() succeeded with a clock offset of 10 minutes")
+	}
+
+	// After the clock is adjusted back, it can decrypt again.
+	SetClockOffset(0)
+	if _, _, err := TryDecrypt(ciphertext, password, true); err != nil {
+		t.Errorf("TryDecrypt() failed: %v", err)
+	}
+}
+
+func TestClockOffsetHook(t *testing.T) {
+	defer SetClockOffset(0)
+	defer SetClockOffsetHook(nil)
+	var got []time.Duration
+	SetClockOffsetHook(func(offset time.Duration) {
+		got = append(got, offset)
+	})
+
+	SetClockOffset(time.Minute)
+	SetClockOffset(time.Minute)
+	SetClockOffset(0)
+	if len(got) != 2 || got[0] != time.Minute || got[1] != 0 {
+		t.Errorf("hook is called with %v, want [1m0s 0s]", got)
+	}
+
+	// The hook is not called after it is removed.
+	SetClockOffsetHook(nil)
+	SetClockOffset(time.Minute)
+	if len(got) != 2 {
+		t.Errorf("hook is called after it is removed")
+	}
+}
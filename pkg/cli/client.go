@@ -16,29 +16,42 @@
 package cli
 
 import (
+	"bufio"
 	"context"
-	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime/pprof"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/enfein/mieru/pkg/appctl"
 	"github.com/enfein/mieru/pkg/appctl/appctlpb"
 	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/event"
 	"github.com/enfein/mieru/pkg/http2socks"
 	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/logship"
 	"github.com/enfein/mieru/pkg/metrics"
+	"github.com/enfein/mieru/pkg/mieruclient"
+	"github.com/enfein/mieru/pkg/netmon"
 	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/restapi"
 	"github.com/enfein/mieru/pkg/socks5"
+	"github.com/enfein/mieru/pkg/speedtest"
 	"github.com/enfein/mieru/pkg/stderror"
 	"github.com/enfein/mieru/pkg/util"
 	"github.com/enfein/mieru/pkg/util/sockopts"
+	"github.com/enfein/mieru/pkg/webui"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
 )
@@ -66,6 +79,34 @@ func RegisterClientCommands() {
 		},
 		clientRunFunc,
 	)
+	RegisterCallback(
+		[]string{"", "service", "install"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		clientServiceInstallFunc,
+	)
+	RegisterCallback(
+		[]string{"", "service", "uninstall"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		clientServiceUninstallFunc,
+	)
+	RegisterCallback(
+		[]string{"", "system-proxy", "on"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		clientSystemProxyOnFunc,
+	)
+	RegisterCallback(
+		[]string{"", "system-proxy", "off"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		clientSystemProxyOffFunc,
+	)
 	RegisterCallback(
 		[]string{"", "stop"},
 		func(s []string) error {
@@ -73,13 +114,35 @@ func RegisterClientCommands() {
 		},
 		clientStopFunc,
 	)
-	RegisterCallback(
+	RegisterJSONCallback(
 		[]string{"", "status"},
 		func(s []string) error {
 			return unexpectedArgsError(s, 2)
 		},
 		clientStatusFunc,
 	)
+	RegisterCallback(
+		[]string{"", "test"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		clientTestFunc,
+	)
+	RegisterCallback(
+		[]string{"", "speedtest"},
+		func(s []string) error {
+			if err := unexpectedArgsError(s, 3); err != nil {
+				return err
+			}
+			if len(s) == 3 {
+				if _, err := parseSpeedTestSize(s[2]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		clientSpeedTestFunc,
+	)
 	RegisterCallback(
 		[]string{"", "apply", "config"},
 		func(s []string) error {
@@ -93,19 +156,43 @@ func RegisterClientCommands() {
 		clientApplyConfigFunc,
 	)
 	RegisterCallback(
-		[]string{"", "describe", "config"},
+		[]string{"", "validate", "config"},
+		func(s []string) error {
+			if len(s) < 4 {
+				return fmt.Errorf("usage: mieru validate config <FILE>. No config file is provided")
+			} else if len(s) > 4 {
+				return fmt.Errorf("usage: mieru validate config <FILE>. More than 1 config file is provided")
+			}
+			return nil
+		},
+		clientValidateConfigFunc,
+	)
+	RegisterCallback(
+		[]string{"", "encrypt", "config"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		clientEncryptConfigFunc,
+	)
+	RegisterCallback(
+		[]string{"", "decrypt", "config"},
 		func(s []string) error {
 			return unexpectedArgsError(s, 3)
 		},
+		clientDecryptConfigFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "describe", "config"},
+		describeConfigArgsValidator,
 		clientDescribeConfigFunc,
 	)
 	RegisterCallback(
 		[]string{"", "import", "config"},
 		func(s []string) error {
 			if len(s) < 4 {
-				return fmt.Errorf("usage: mieru import config <URL>. No URL is provided")
+				return fmt.Errorf("usage: mieru import config <URL|FILE>. No URL is provided")
 			} else if len(s) > 4 {
-				return fmt.Errorf("usage: mieru import config <URL>. More than 1 URL is provided")
+				return fmt.Errorf("usage: mieru import config <URL|FILE>. More than 1 URL is provided")
 			}
 			return nil
 		},
@@ -118,6 +205,33 @@ func RegisterClientCommands() {
 		},
 		clientExportConfigFunc,
 	)
+	RegisterCallback(
+		[]string{"", "logs", "decrypt"},
+		func(s []string) error {
+			if len(s) < 4 {
+				return fmt.Errorf("usage: mieru logs decrypt <FILE>. No file is provided")
+			} else if len(s) > 4 {
+				return fmt.Errorf("usage: mieru logs decrypt <FILE>. More than 1 file is provided")
+			}
+			return nil
+		},
+		clientDecryptLogsFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "get", "config-url"},
+		func(s []string) error {
+			_, _, err := parseConfigURLSelectors(s[3:])
+			return err
+		},
+		clientGetConfigURLFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "get", "dashboard-url"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		clientGetDashboardURLFunc,
+	)
 	RegisterCallback(
 		[]string{"", "delete", "profile"},
 		func(s []string) error {
@@ -130,6 +244,13 @@ func RegisterClientCommands() {
 		},
 		clientDeleteProfileFunc,
 	)
+	RegisterCallback(
+		[]string{"", "update", "subscriptions"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		clientUpdateSubscriptionsFunc,
+	)
 	RegisterCallback(
 		[]string{"", "version"},
 		func(s []string) error {
@@ -140,32 +261,75 @@ func RegisterClientCommands() {
 	RegisterCallback(
 		[]string{"", "check", "update"},
 		func(s []string) error {
-			return unexpectedArgsError(s, 3)
+			return unexpectedArgsError(s, 4)
 		},
 		checkUpdateFunc,
 	)
-	RegisterCallback(
+	RegisterJSONCallback(
 		[]string{"", "get", "metrics"},
 		func(s []string) error {
-			return unexpectedArgsError(s, 3)
+			_, err := parseMetricsArgs(s)
+			return err
 		},
 		clientGetMetricsFunc,
 	)
-	RegisterCallback(
+	RegisterJSONCallback(
+		[]string{"", "get", "top"},
+		func(s []string) error {
+			_, err := parseTopDestinationsArgs(s)
+			return err
+		},
+		clientGetTopFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "get", "audit"},
+		func(s []string) error {
+			_, err := parseAuditArgs(s)
+			return err
+		},
+		clientGetAuditFunc,
+	)
+	RegisterJSONCallback(
 		[]string{"", "get", "connections"},
 		func(s []string) error {
+			if len(s) > 3 && s[3] == "--watch" {
+				return unexpectedArgsError(s, 4)
+			}
 			return unexpectedArgsError(s, 3)
 		},
 		clientGetConnectionsFunc,
 	)
 	RegisterCallback(
+		[]string{"", "top"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 2)
+		},
+		clientTopFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "logs"},
+		func(s []string) error {
+			_, err := parseLogsArgs(s)
+			return err
+		},
+		clientLogsFunc,
+	)
+	RegisterCallback(
+		[]string{"", "set", "log-level"},
+		func(s []string) error {
+			_, err := parseSetLogLevelArgs(s)
+			return err
+		},
+		clientSetLogLevelFunc,
+	)
+	RegisterJSONCallback(
 		[]string{"", "get", "thread-dump"},
 		func(s []string) error {
 			return unexpectedArgsError(s, 3)
 		},
 		clientGetThreadDumpFunc,
 	)
-	RegisterCallback(
+	RegisterJSONCallback(
 		[]string{"", "get", "heap-profile"},
 		func(s []string) error {
 			if len(s) < 4 {
@@ -196,6 +360,32 @@ func RegisterClientCommands() {
 		},
 		clientStopCPUProfileFunc,
 	)
+	RegisterCallback(
+		[]string{"", "tap", "session"},
+		func(s []string) error {
+			if len(s) != 5 {
+				return fmt.Errorf("usage: mieru tap session <SESSION_ID> <ADDRESS>")
+			}
+			if _, err := strconv.ParseUint(s[3], 10, 32); err != nil {
+				return fmt.Errorf("session ID %q is invalid", s[3])
+			}
+			return nil
+		},
+		clientTapSessionFunc,
+	)
+	RegisterCallback(
+		[]string{"", "untap", "session"},
+		func(s []string) error {
+			if len(s) != 4 {
+				return fmt.Errorf("usage: mieru untap session <SESSION_ID>")
+			}
+			if _, err := strconv.ParseUint(s[3], 10, 32); err != nil {
+				return fmt.Errorf("session ID %q is invalid", s[3])
+			}
+			return nil
+		},
+		clientTapSessionFunc,
+	)
 }
 
 var clientHelpFunc = func(s []string) error {
@@ -214,45 +404,113 @@ var clientHelpFunc = func(s []string) error {
 				cmd:  "stop",
 				help: "Stop mieru client.",
 			},
+			{
+				cmd:  "service install",
+				help: "macOS only. Run mieru client as a launchd service that starts at login and restarts after a crash.",
+			},
+			{
+				cmd:  "service uninstall",
+				help: "macOS only. Stop and remove mieru client launchd service.",
+			},
+			{
+				cmd:  "system-proxy on",
+				help: "Set the proxy settings of the operating system to mieru client socks5 port and HTTP proxy port.",
+			},
+			{
+				cmd:  "system-proxy off",
+				help: "Restore the proxy settings of the operating system changed by mieru.",
+			},
 			{
 				cmd:  "status",
 				help: "Check mieru client status.",
 			},
+			{
+				cmd:  "test [<URL>]",
+				help: "Test the connection to the proxy server and fetch the URL through it. Report latency of each step.",
+			},
+			{
+				cmd:  "speedtest [<MEGABYTES>]",
+				help: "Measure round trip time, upload and download throughput to the proxy server for each transport protocol. Transfer 16 megabytes in each direction by default.",
+			},
 			{
 				cmd:  "apply config <FILE>",
-				help: "Apply client configuration from JSON file.",
+				help: "Apply client configuration from JSON or YAML file.",
+			},
+			{
+				cmd:  "validate config <FILE>",
+				help: "Check client configuration in JSON or YAML file without applying it.",
+			},
+			{
+				cmd:  "encrypt config",
+				help: "Encrypt stored client configuration with a key saved in the keychain of the operating system.",
+			},
+			{
+				cmd:  "decrypt config",
+				help: "Store client configuration without encryption.",
 			},
 			{
-				cmd:  "describe config",
-				help: "Show current client configuration.",
+				cmd:  "describe config [--format json|yaml] [--show-secrets]",
+				help: "Show current client configuration. The default format is JSON. Passwords are hidden unless --show-secrets is used.",
 			},
 			{
-				cmd:  "import config <URL>",
-				help: "Import client configuration from URL.",
+				cmd:  "import config <URL|FILE>",
+				help: "Import client configuration from URL. Shadowsocks, VMess, VLESS and Trojan share links, as well as Clash configuration file, are also accepted.",
 			},
 			{
 				cmd:  "export config",
 				help: "Export client configuration as URL.",
 			},
+			{
+				cmd:  "get config-url [--profile <PROFILE_NAME>] [--server <SERVER>]",
+				help: "Export selected client profiles or servers as URL. Each option can be repeated.",
+			},
+			{
+				cmd:  "get dashboard-url",
+				help: "Get the URL to open the web dashboard of mieru client. This requires webDashboardPort in client config.",
+			},
 			{
 				cmd:  "delete profile <PROFILE_NAME>",
 				help: "Delete an inactive client configuration profile.",
 			},
 			{
-				cmd:  "get metrics",
-				help: "Get mieru client metrics.",
+				cmd:  "update subscriptions",
+				help: "Download the servers of client profiles that have a subscription.",
+			},
+			{
+				cmd:  "get metrics [--history <DURATION>] [--group <GROUP>] [--name <NAME>] [--list-groups]",
+				help: "Get mieru client metrics. With --history, show per-minute samples of the given duration, e.g. 1h. With --group and --name, only show metrics whose group and name contain the given text. With --list-groups, only show the names of metric groups.",
+			},
+			{
+				cmd:  "get top [--limit <N>]",
+				help: "Get the destinations that use the most traffic. This requires topDestinations in client config.",
+			},
+			{
+				cmd:  "get connections [--watch]",
+				help: "Get mieru client connections. With --watch, refresh the connections every second.",
+			},
+			{
+				cmd:  "get audit [--limit <N>]",
+				help: "Get the latest records of client configuration and lifecycle changes.",
+			},
+			{
+				cmd:  "top",
+				help: "Show a live dashboard of mieru client sessions, underlays and metrics.",
+			},
+			{
+				cmd:  "logs [-f] [--level <LEVEL>] [--lines <N>]",
+				help: "Show recent log lines of mieru client. With -f, keep printing new log lines.",
 			},
 			{
-				cmd:  "get connections",
-				help: "Get mieru client connections.",
+				cmd:  "set log-level <LEVEL>",
+				help: "Change the logging level of running mieru client until it is restarted, e.g. debug.",
 			},
 			{
 				cmd:  "version",
 				help: "Show mieru client version.",
 			},
 			{
-				cmd:  "check update",
-				help: "Check mieru client update.",
+				cmd:  "check update [<URL>]",
+				help: "Check mieru client update. Optionally query a mirror URL of GitHub latest release API.",
 			},
 		},
 		advanced: []helpCmdEntry{
@@ -268,6 +526,10 @@ var clientHelpFunc = func(s []string) error {
 				cmd:  "get heap-profile <GZ_FILE>",
 				help: "Get mieru client heap profile and save results to the file.",
 			},
+			{
+				cmd:  "logs decrypt <FILE>",
+				help: "Decrypt an encrypted client log file and print it. The key is read from MIERU_LOG_ENCRYPTION_KEY environment variable.",
+			},
 			{
 				cmd:  "profile cpu start <GZ_FILE>",
 				help: "Start mieru client CPU profile and save results to the file.",
@@ -276,7 +538,16 @@ var clientHelpFunc = func(s []string) error {
 				cmd:  "profile cpu stop",
 				help: "Stop mieru client CPU profile.",
 			},
+			{
+				cmd:  "tap session <SESSION_ID> <ADDRESS>",
+				help: "Mirror byte counts and timing of a session to a loopback UDP address.",
+			},
+			{
+				cmd:  "untap session <SESSION_ID>",
+				help: "Stop mirroring a session.",
+			},
 		},
+		flags: globalFlagEntries(),
 	}
 	helpFmt.print()
 	return nil
@@ -284,7 +555,7 @@ var clientHelpFunc = func(s []string) error {
 
 var clientStartFunc = func(s []string) error {
 	// Load and verify client config.
-	config, err := appctl.LoadClientConfig()
+	config, err := appctl.LoadActiveClientConfig()
 	if err != nil {
 		if err == stderror.ErrFileNotExist {
 			return fmt.Errorf(stderror.ClientConfigNotExist)
@@ -305,12 +576,19 @@ var clientStartFunc = func(s []string) error {
 		return nil
 	}
 
-	cmd := exec.Command(s[0], "run")
-	if errors.Is(cmd.Err, exec.ErrDot) {
-		cmd.Err = nil
-	}
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf(stderror.StartClientFailedErr, err)
+	if appctl.IsLaunchdServiceInstalled() {
+		// Let launchd manage the client daemon.
+		if err := appctl.StartLaunchdService(); err != nil {
+			return fmt.Errorf(stderror.StartClientFailedErr, err)
+		}
+	} else {
+		cmd := exec.Command(s[0], "run")
+		if errors.Is(cmd.Err, exec.ErrDot) {
+			cmd.Err = nil
+		}
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf(stderror.StartClientFailedErr, err)
+		}
 	}
 
 	// Wait until client daemon is running.
@@ -319,6 +597,7 @@ var clientStartFunc = func(s []string) error {
 	for i := 0; i < 100; i++ {
 		lastErr = appctl.IsClientDaemonRunning(context.Background())
 		if lastErr == nil {
+			appctl.RecordClientAudit(appctl.AuditSourceCLI, "start", "")
 			if config.GetSocks5ListenLAN() {
 				log.Infof("mieru client is started, listening to 0.0.0.0:%d", config.GetSocks5Port())
 			} else {
@@ -331,6 +610,149 @@ var clientStartFunc = func(s []string) error {
 	return fmt.Errorf(stderror.ClientNotRunningErr, lastErr)
 }
 
+// listenUnixSocket listens to the Unix domain socket. The socket file
+// left by the previous run is removed.
+func listenUnixSocket(socketPath string) (net.Listener, error) {
+	if info, err := os.Lstat(socketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(socketPath)
+	}
+	return net.Listen("unix", socketPath)
+}
+
+// listenPrivateUnixSocket listens to the Unix domain socket that only the
+// current user can connect to. The socket is created in a new directory
+// that only the current user can access, and moved to socketPath after its
+// permission is restricted, so other users can't connect to it in between.
+// The socket file left by the previous run is replaced.
+func listenPrivateUnixSocket(socketPath string) (net.Listener, error) {
+	if info, err := os.Lstat(socketPath); err == nil && info.Mode()&os.ModeSocket == 0 {
+		return nil, fmt.Errorf("%q exists and is not a socket", socketPath)
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(socketPath), ".mieru")
+	if err != nil {
+		return nil, fmt.Errorf("os.MkdirTemp() failed: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	tmpPath := filepath.Join(dir, "s")
+	l, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("os.Chmod(%q) failed: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, socketPath); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("os.Rename() failed: %w", err)
+	}
+	// The socket file is no longer at the path used to listen.
+	if ul, ok := l.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	return l, nil
+}
+
+// startProfileListener runs the socks5 server and the HTTP proxy of
+// the profile listener in the background. It returns a function to
+// connect to the servers of the profile updated by subscription.
+func startProfileListener(config *appctlpb.ClientConfig, listener *appctlpb.ProfileListener, resolver *util.DNSResolver, netMonitor *netmon.Monitor) (func(*appctlpb.ClientConfig), error) {
+	profileConfig := func(config *appctlpb.ClientConfig) *appctlpb.ClientConfig {
+		c := proto.Clone(config).(*appctlpb.ClientConfig)
+		c.ActiveProfile = proto.String(listener.GetProfileName())
+		return c
+	}
+	listenerConfig := profileConfig(config)
+	endpoints, err := mieruclient.Endpoints(context.Background(), listenerConfig, resolver)
+	if err != nil {
+		return nil, err
+	}
+	mux, err := mieruclient.NewMux(listenerConfig, endpoints)
+	if err != nil {
+		return nil, err
+	}
+	netMonitor.OnChange(func(reason string) {
+		mux.ResetUnderlays(reason)
+	})
+	var currentConfig atomic.Pointer[appctlpb.ClientConfig]
+	currentConfig.Store(listenerConfig)
+	if hasSubscription(config) || mieruclient.HasPortRotation(listenerConfig) {
+		go mieruclient.RefreshRotatedPorts(mux, resolver, &currentConfig, nil)
+	}
+	socks5Config, err := mieruclient.Socks5Config(listenerConfig, mux, resolver)
+	if err != nil {
+		return nil, err
+	}
+	socks5Server, err := socks5.New(socks5Config)
+	if err != nil {
+		return nil, fmt.Errorf(stderror.CreateSocks5ServerFailedErr, err)
+	}
+	appctl.AddClientProfileSocks5Server(socks5Server)
+
+	var socks5Addr string
+	if config.GetSocks5ListenLAN() {
+		socks5Addr = util.MaybeDecorateIPv6(util.AllIPAddr()) + ":" + strconv.Itoa(int(listener.GetSocks5Port()))
+	} else {
+		socks5Addr = util.MaybeDecorateIPv6(util.LocalIPAddr()) + ":" + strconv.Itoa(int(listener.GetSocks5Port()))
+	}
+	listenConfig := sockopts.ListenConfigWithControls()
+	l, err := listenConfig.Listen(context.Background(), "tcp", socks5Addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on socks5 address tcp %q failed: %w", socks5Addr, err)
+	}
+	go func() {
+		if err := socks5Server.Serve(l); err != nil {
+			log.Errorf("run socks5 server of profile %q failed: %v", listener.GetProfileName(), err)
+		}
+	}()
+	log.Infof("mieru client socks5 server of profile %q is running on port %d", listener.GetProfileName(), listener.GetSocks5Port())
+
+	if listener.GetHttpProxyPort() != 0 {
+		var httpServerAddr string
+		if config.GetHttpProxyListenLAN() {
+			httpServerAddr = util.MaybeDecorateIPv6(util.AllIPAddr()) + ":" + strconv.Itoa(int(listener.GetHttpProxyPort()))
+		} else {
+			httpServerAddr = util.MaybeDecorateIPv6(util.LocalIPAddr()) + ":" + strconv.Itoa(int(listener.GetHttpProxyPort()))
+		}
+		proxy := &http2socks.Proxy{
+			DialContext: socks5Server.DialContext,
+		}
+		var httpServer *http.Server
+		if config.GetHttpProxyTLS().GetEnable() {
+			cert, err := appctl.LoadHTTPProxyCertificate(config)
+			if err != nil {
+				return nil, fmt.Errorf("load HTTP proxy certificate failed: %w", err)
+			}
+			httpServer = http2socks.NewHTTPSServer(httpServerAddr, proxy, cert)
+		} else {
+			httpServer = http2socks.NewHTTPServer(httpServerAddr, proxy)
+		}
+		go func() {
+			var err error
+			if httpServer.TLSConfig != nil {
+				err = httpServer.ListenAndServeTLS("", "")
+			} else {
+				err = httpServer.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("run HTTP proxy server of profile %q failed: %v", listener.GetProfileName(), err)
+			}
+		}()
+		log.Infof("mieru client HTTP proxy server of profile %q is running on port %d", listener.GetProfileName(), listener.GetHttpProxyPort())
+	}
+
+	return func(config *appctlpb.ClientConfig) {
+		listenerConfig := profileConfig(config)
+		endpoints, err := mieruclient.Endpoints(context.Background(), listenerConfig, resolver)
+		if err != nil {
+			log.Errorf("use servers of profile %q updated by subscription failed: %v", listener.GetProfileName(), err)
+			return
+		}
+		currentConfig.Store(listenerConfig)
+		mux.SetEndpoints(endpoints)
+	}, nil
+}
+
 var clientRunFunc = func(s []string) error {
 	log.SetFormatter(&log.DaemonFormatter{})
 	appctl.SetAppStatus(appctlpb.AppStatus_STARTING)
@@ -346,7 +768,7 @@ var clientRunFunc = func(s []string) error {
 	}
 
 	// Load and verify client config.
-	config, err := appctl.LoadClientConfig()
+	config, err := appctl.LoadActiveClientConfig()
 	if err != nil {
 		if err == stderror.ErrFileNotExist {
 			return fmt.Errorf(stderror.ClientConfigNotExist)
@@ -374,19 +796,33 @@ var clientRunFunc = func(s []string) error {
 
 	var wg sync.WaitGroup
 
-	// RPC port is allowed to set to 0. In that case, don't run RPC server.
+	// RPC port is allowed to set to 0. In that case, don't run RPC server unless RPC socket path is set.
 	// When RPC server is not running, mieru commands can't be used to control the proxy client.
 	// This mode is typically used by a mobile app, where the app controls the lifecycle of the proxy client.
-	if config.GetRpcPort() != 0 {
+	if config.GetRpcPort() != 0 || config.GetRpcSocketPath() != "" {
 		wg.Add(1)
 		go func() {
-			rpcAddr := "localhost:" + strconv.Itoa(int(config.GetRpcPort()))
-			listenConfig := sockopts.ListenConfigWithControls()
-			rpcListener, err := listenConfig.Listen(context.Background(), "tcp", rpcAddr)
+			var rpcListener net.Listener
+			var err error
+			if socketPath := config.GetRpcSocketPath(); socketPath != "" {
+				// Only the user who runs the client can connect to the socket.
+				rpcListener, err = listenPrivateUnixSocket(socketPath)
+				if err != nil {
+					log.Fatalf("listen on RPC unix socket %q failed: %v", socketPath, err)
+				}
+			} else {
+				rpcAddr := "localhost:" + strconv.Itoa(int(config.GetRpcPort()))
+				listenConfig := sockopts.ListenConfigWithControls()
+				rpcListener, err = listenConfig.Listen(context.Background(), "tcp", rpcAddr)
+				if err != nil {
+					log.Fatalf("listen on RPC address tcp %q failed: %v", rpcAddr, err)
+				}
+			}
+			rpcToken, err := appctl.PrepareClientRPCToken()
 			if err != nil {
-				log.Fatalf("listen on RPC address tcp %q failed: %v", rpcAddr, err)
+				log.Fatalf("prepare client RPC token failed: %v", err)
 			}
-			grpcServer := grpc.NewServer()
+			grpcServer := grpc.NewServer(appctl.RPCTokenServerOptions(rpcToken)...)
 			appctl.SetClientRPCServerRef(grpcServer)
 			appctlpb.RegisterClientLifecycleServiceServer(grpcServer, appctl.NewClientLifecycleService())
 			close(appctl.ClientRPCServerStarted)
@@ -400,85 +836,78 @@ var clientRunFunc = func(s []string) error {
 		<-appctl.ClientRPCServerStarted
 	}
 
+	// Use the clock offset learned from the proxy server before a restart.
+	if err := appctl.LoadClientClockOffset(); err != nil {
+		log.Warnf("load clock offset failed: %v", err)
+	}
+
 	// Collect remote proxy addresses and password.
-	mux := protocolv2.NewMux(true)
-	appctl.SetClientMuxRef(mux)
-	var hashedPassword []byte
-	activeProfile, err := appctl.GetActiveProfileFromConfig(config, config.GetActiveProfile())
+	resolver, err := mieruclient.NewResolver(config)
+	if err != nil {
+		return err
+	}
+	endpoints, err := mieruclient.Endpoints(context.Background(), config, resolver)
+	if err != nil {
+		return err
+	}
+	mux, err := mieruclient.NewMux(config, endpoints)
 	if err != nil {
-		return fmt.Errorf(stderror.ClientGetActiveProfileFailedErr, err)
+		return err
 	}
-	user := activeProfile.GetUser()
-	if user.GetHashedPassword() != "" {
-		hashedPassword, err = hex.DecodeString(user.GetHashedPassword())
+	// Only the client daemon follows the time of the proxy server.
+	mux.SetClientClockSync(true)
+	appctl.SetClientMuxRef(mux)
+
+	// Re-establish the underlays when the network of the host is changed.
+	netMonitor := netmon.New()
+	netMonitor.OnChange(func(reason string) {
+		mux.ResetUnderlays(reason)
+	})
+	netMonitor.Start()
+	defer netMonitor.Close()
+
+	// Run the profile listeners, each using a profile other than the active profile.
+	profileListenerUpdates := make([]func(*appctlpb.ClientConfig), 0, len(config.GetProfileListeners()))
+	for _, listener := range config.GetProfileListeners() {
+		update, err := startProfileListener(config, listener, resolver, netMonitor)
 		if err != nil {
-			return fmt.Errorf(stderror.DecodeHashedPasswordFailedErr, err)
+			return fmt.Errorf("start listener of profile %q failed: %w", listener.GetProfileName(), err)
 		}
-	} else {
-		hashedPassword = cipher.HashPassword([]byte(user.GetPassword()), []byte(user.GetName()))
-	}
-	mux = mux.SetClientPassword(hashedPassword)
-	mtu := util.DefaultMTU
-	if activeProfile.GetMtu() != 0 {
-		mtu = int(activeProfile.GetMtu())
-	}
-	multiplexFactor := 1
-	switch activeProfile.GetMultiplexing().GetLevel() {
-	case appctlpb.MultiplexingLevel_MULTIPLEXING_OFF:
-		multiplexFactor = 0
-	case appctlpb.MultiplexingLevel_MULTIPLEXING_LOW:
-		multiplexFactor = 1
-	case appctlpb.MultiplexingLevel_MULTIPLEXING_MIDDLE:
-		multiplexFactor = 2
-	case appctlpb.MultiplexingLevel_MULTIPLEXING_HIGH:
-		multiplexFactor = 3
-	}
-	mux = mux.SetClientMultiplexFactor(multiplexFactor)
-	endpoints := make([]protocolv2.UnderlayProperties, 0)
-	resolver := &util.DNSResolver{}
-	for _, serverInfo := range activeProfile.GetServers() {
-		var proxyHost string
-		var proxyIP net.IP
-		if serverInfo.GetDomainName() != "" {
-			proxyHost = serverInfo.GetDomainName()
-			proxyIP, err = resolver.LookupIP(context.Background(), proxyHost)
-			if err != nil {
-				return fmt.Errorf(stderror.LookupIPFailedErr, err)
-			}
-		} else {
-			proxyHost = serverInfo.GetIpAddress()
-			proxyIP = net.ParseIP(proxyHost)
-			if proxyIP == nil {
-				return fmt.Errorf(stderror.ParseIPFailed)
+		profileListenerUpdates = append(profileListenerUpdates, update)
+	}
+
+	// Connect to the new servers when the active profile is updated by subscription.
+	var currentConfig atomic.Pointer[appctlpb.ClientConfig]
+	currentConfig.Store(config)
+	if hasSubscription(config) {
+		appctl.SetClientSubscriptionHook(func(config *appctlpb.ClientConfig) {
+			for _, update := range profileListenerUpdates {
+				update(config)
 			}
-		}
-		ipVersion := util.GetIPVersion(proxyIP.String())
-		portBindings, err := appctl.FlatPortBindings(serverInfo.GetPortBindings())
-		if err != nil {
-			return fmt.Errorf(stderror.InvalidPortBindingsErr, err)
-		}
-		for _, bindingInfo := range portBindings {
-			proxyPort := bindingInfo.GetPort()
-			switch bindingInfo.GetProtocol() {
-			case appctlpb.TransportProtocol_TCP:
-				endpoint := protocolv2.NewUnderlayProperties(mtu, ipVersion, util.TCPTransport, nil, &net.TCPAddr{IP: proxyIP, Port: int(proxyPort)})
-				endpoints = append(endpoints, endpoint)
-			case appctlpb.TransportProtocol_UDP:
-				endpoint := protocolv2.NewUnderlayProperties(mtu, ipVersion, util.UDPTransport, nil, &net.UDPAddr{IP: proxyIP, Port: int(proxyPort)})
-				endpoints = append(endpoints, endpoint)
-			default:
-				return fmt.Errorf(stderror.InvalidTransportProtocol)
+			endpoints, err := mieruclient.Endpoints(context.Background(), config, resolver)
+			if err != nil {
+				log.Errorf("use servers updated by subscription failed: %v", err)
+				return
 			}
-		}
-		mux.SetEndpoints(endpoints)
+			currentConfig.Store(config)
+			mux.SetEndpoints(endpoints)
+		})
+		appctl.StartSubscriptionRefresh(context.Background())
+	}
+
+	// Follow the active ports of servers with port rotation.
+	if hasSubscription(config) || mieruclient.HasPortRotation(config) {
+		go mieruclient.RefreshRotatedPorts(mux, resolver, &currentConfig, nil)
 	}
 
 	// Create the local socks5 server.
-	socks5Config := &socks5.Config{
-		UseProxy:                 true,
-		ClientSideAuthentication: true,
-		ProxyMux:                 mux,
-		HandshakeTimeout:         10 * time.Second,
+	socks5Config, err := mieruclient.Socks5Config(config, mux, resolver)
+	if err != nil {
+		return err
+	}
+	if config.GetTopDestinations() {
+		socks5Config.Destinations = socks5.NewDestinationStats()
+		appctl.SetClientDestinationStatsRef(socks5Config.Destinations)
 	}
 	socks5Server, err := socks5.New(socks5Config)
 	if err != nil {
@@ -512,29 +941,179 @@ var clientRunFunc = func(s []string) error {
 	// If HTTP proxy is enabled, run the local HTTP server in the background.
 	if config.GetHttpProxyPort() != 0 {
 		wg.Add(1)
-		go func(socks5Addr string) {
+		go func() {
 			var httpServerAddr string
 			if config.GetHttpProxyListenLAN() {
 				httpServerAddr = util.MaybeDecorateIPv6(util.AllIPAddr()) + ":" + strconv.Itoa(int(config.GetHttpProxyPort()))
 			} else {
 				httpServerAddr = util.MaybeDecorateIPv6(util.LocalIPAddr()) + ":" + strconv.Itoa(int(config.GetHttpProxyPort()))
 			}
-			httpServer := http2socks.NewHTTPServer(httpServerAddr, &http2socks.Proxy{
-				ProxyURI: "socks5://" + socks5Addr + "?timeout=10s",
-			})
+			proxy := &http2socks.Proxy{
+				DialContext: socks5Server.DialContext,
+			}
+			if config.GetHttpProxyTLS().GetEnable() {
+				cert, err := appctl.LoadHTTPProxyCertificate(config)
+				if err != nil {
+					log.Fatalf("load HTTP proxy certificate failed: %v", err)
+				}
+				httpServer := http2socks.NewHTTPSServer(httpServerAddr, proxy, cert)
+				log.Infof("mieru client HTTPS proxy server is running")
+				wg.Done()
+				if err := httpServer.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Fatalf("run HTTPS proxy server failed: %v", err)
+				}
+				return
+			}
+			httpServer := http2socks.NewHTTPServer(httpServerAddr, proxy)
 			log.Infof("mieru client HTTP proxy server is running")
 			wg.Done()
 			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 				log.Fatalf("run HTTP proxy server failed: %v", err)
 			}
-		}(socks5Addr)
+		}()
 	}
 
-	<-appctl.ClientSocks5ServerStarted
-	metrics.EnableLogging()
+	// If transparent proxy is enabled, accept the diverted connections in the background.
+	if config.GetTransparentProxy() != nil {
+		wg.Add(1)
+		go func() {
+			transparentAddr := util.MaybeDecorateIPv6(util.AllIPAddr()) + ":" + strconv.Itoa(int(config.GetTransparentProxy().GetPort()))
+			mode := config.GetTransparentProxy().GetMode()
+			listenConfig := sockopts.ListenConfigWithControls()
+			if mode == appctlpb.TransparentProxyMode_TRANSPARENT_PROXY_TPROXY {
+				listenConfig.Control = sockopts.Append(listenConfig.Control, sockopts.Transparent())
+			}
+			l, err := listenConfig.Listen(context.Background(), "tcp", transparentAddr)
+			if err != nil {
+				log.Fatalf("listen on transparent proxy address tcp %q failed: %v", transparentAddr, err)
+			}
+			log.Infof("mieru client transparent proxy server is running")
+			wg.Done()
+			if err := socks5Server.ServeTransparent(l, mode); err != nil {
+				log.Fatalf("run transparent proxy server failed: %v", err)
+			}
+		}()
+	}
 
-	appctl.SetAppStatus(appctlpb.AppStatus_RUNNING)
-	wg.Wait()
+	// If DNS leak protection is enabled, answer DNS queries through the proxy in the background.
+	if config.GetDnsLeakProtection() != nil {
+		wg.Add(1)
+		go func() {
+			dnsAddr := util.MaybeDecorateIPv6(util.LocalIPAddr()) + ":" + strconv.Itoa(int(config.GetDnsLeakProtection().GetPort()))
+			listenConfig := sockopts.ListenConfigWithControls()
+			pc, err := listenConfig.ListenPacket(context.Background(), "udp", dnsAddr)
+			if err != nil {
+				log.Fatalf("listen on DNS address udp %q failed: %v", dnsAddr, err)
+			}
+			l, err := listenConfig.Listen(context.Background(), "tcp", dnsAddr)
+			if err != nil {
+				log.Fatalf("listen on DNS address tcp %q failed: %v", dnsAddr, err)
+			}
+			log.Infof("mieru client DNS leak protection server is running")
+			wg.Done()
+			if err := socks5Server.ServeDNS(pc, l, mieruclient.DNSLeakProtectionUpstream(config)); err != nil {
+				log.Fatalf("run DNS leak protection server failed: %v", err)
+			}
+		}()
+	}
+
+	// If socks5 Unix socket path is set, accept socks5 connections from it in the background.
+	if config.GetSocks5UnixSocketPath() != "" {
+		wg.Add(1)
+		go func() {
+			socketPath := config.GetSocks5UnixSocketPath()
+			l, err := listenUnixSocket(socketPath)
+			if err != nil {
+				log.Fatalf("listen on socks5 unix socket %q failed: %v", socketPath, err)
+			}
+			log.Infof("mieru client socks5 unix socket server is running")
+			wg.Done()
+			if err := socks5Server.ServeUnix(l); err != nil {
+				log.Fatalf("run socks5 unix socket server failed: %v", err)
+			}
+		}()
+	}
+
+	// If web dashboard is enabled, run the dashboard HTTP server in the background.
+	if config.WebDashboardPort != nil {
+		wg.Add(1)
+		go func() {
+			dashboardAddr := "localhost:" + strconv.Itoa(int(config.GetWebDashboardPort()))
+			rpcToken, err := appctl.PrepareClientRPCToken()
+			if err != nil {
+				log.Fatalf("prepare client RPC token failed: %v", err)
+			}
+			rpcClient, err := appctl.NewClientLifecycleRPCClient(context.Background())
+			if err != nil {
+				log.Fatalf("create web dashboard RPC client failed: %v", err)
+			}
+			dashboardServer := webui.NewServer(dashboardAddr, webui.NewHandler(rpcClient, rpcToken))
+			log.Infof("mieru client web dashboard is running")
+			wg.Done()
+			if err := dashboardServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("run web dashboard server failed: %v", err)
+			}
+		}()
+	}
+
+	// If REST API is enabled, run the REST API HTTP server in the background.
+	if config.RestApiPort != nil {
+		wg.Add(1)
+		go func() {
+			restAddr := "localhost:" + strconv.Itoa(int(config.GetRestApiPort()))
+			rpcToken, err := appctl.PrepareClientRPCToken()
+			if err != nil {
+				log.Fatalf("prepare client RPC token failed: %v", err)
+			}
+			rpcConn, err := appctl.NewClientLifecycleRPCConn(context.Background())
+			if err != nil {
+				log.Fatalf("create REST API RPC connection failed: %v", err)
+			}
+			service := appctlpb.File_lifecycle_proto.Services().ByName("ClientLifecycleService")
+			restServer := restapi.NewServer(restAddr, restapi.NewGateway(rpcConn, service, rpcToken))
+			log.Infof("mieru client REST API server is running")
+			wg.Done()
+			if err := restServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("run REST API server failed: %v", err)
+			}
+		}()
+	}
+
+	<-appctl.ClientSocks5ServerStarted
+	metrics.EnableLogging()
+	if err := appctl.StartStatsdExport(config.GetStatsd()); err != nil {
+		log.Errorf("start StatsD export failed: %v", err)
+	}
+	if err := appctl.StartTracingExport(config.GetTracing(), "mieru"); err != nil {
+		log.Errorf("start tracing export failed: %v", err)
+	}
+	if err := appctl.StartWebhookExport(config.GetWebhook(), "mieru"); err != nil {
+		log.Errorf("start webhook export failed: %v", err)
+	}
+	if err := appctl.StartLogShipping(config.GetLogShipping(), "mieru"); err != nil {
+		log.Errorf("start log shipping failed: %v", err)
+	}
+	if err := appctl.StartClientMetricsPersistence(); err != nil {
+		log.Errorf("start metrics persistence failed: %v", err)
+	}
+
+	if config.GetSystemProxy() {
+		if err := appctl.EnableSystemProxy(config); err != nil {
+			log.Errorf("set system proxy failed: %v", err)
+		}
+	}
+
+	appctl.SetAppStatus(appctlpb.AppStatus_RUNNING)
+	event.Emit(event.DaemonStart, "", nil, "mieru client daemon is started")
+	wg.Wait()
+	if config.GetSystemProxy() && appctl.IsSystemProxyEnabled() {
+		if err := appctl.DisableSystemProxy(); err != nil {
+			log.Errorf("restore system proxy failed: %v", err)
+		}
+	}
+	metrics.StopPersistence()
+	event.DisableWebhook()
+	logship.Disable()
 
 	// Stop CPU profiling, if previously started.
 	pprof.StopCPUProfile()
@@ -543,13 +1122,87 @@ var clientRunFunc = func(s []string) error {
 	return nil
 }
 
+var clientServiceInstallFunc = func(s []string) error {
+	// Load and verify client config.
+	config, err := appctl.LoadActiveClientConfig()
+	if err != nil {
+		if err == stderror.ErrFileNotExist {
+			return fmt.Errorf(stderror.ClientConfigNotExist)
+		} else {
+			return fmt.Errorf(stderror.LoadClientConfigFailedErr, err)
+		}
+	}
+	if err = appctl.ValidateFullClientConfig(config); err != nil {
+		return fmt.Errorf(stderror.ValidateFullClientConfigFailedErr, err)
+	}
+
+	// The daemon started in background is replaced by the service.
+	if err := appctl.IsClientDaemonRunning(context.Background()); err == nil {
+		if err := clientStopFunc(s); err != nil {
+			return err
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf(stderror.InstallServiceFailedErr, err)
+	}
+	if err := appctl.InstallLaunchdService(executable); err != nil {
+		return fmt.Errorf(stderror.InstallServiceFailedErr, err)
+	}
+	log.Infof("mieru client launchd service %s is installed", appctl.LaunchdLabel)
+	return nil
+}
+
+var clientServiceUninstallFunc = func(s []string) error {
+	if err := appctl.UninstallLaunchdService(); err != nil {
+		return fmt.Errorf(stderror.UninstallServiceFailedErr, err)
+	}
+	log.Infof("mieru client launchd service %s is uninstalled", appctl.LaunchdLabel)
+	return nil
+}
+
+var clientSystemProxyOnFunc = func(s []string) error {
+	// Load and verify client config.
+	config, err := appctl.LoadActiveClientConfig()
+	if err != nil {
+		if err == stderror.ErrFileNotExist {
+			return fmt.Errorf(stderror.ClientConfigNotExist)
+		} else {
+			return fmt.Errorf(stderror.LoadClientConfigFailedErr, err)
+		}
+	}
+	if err = appctl.ValidateFullClientConfig(config); err != nil {
+		return fmt.Errorf(stderror.ValidateFullClientConfigFailedErr, err)
+	}
+
+	if err := appctl.EnableSystemProxy(config); err != nil {
+		return fmt.Errorf(stderror.SetSystemProxyFailedErr, err)
+	}
+	settings := appctl.SystemProxySettings(config)
+	if settings.HTTPAddr != "" {
+		log.Infof("system proxy is set to socks5 %s and HTTP %s", settings.SOCKS5Addr, settings.HTTPAddr)
+	} else {
+		log.Infof("system proxy is set to socks5 %s", settings.SOCKS5Addr)
+	}
+	return nil
+}
+
+var clientSystemProxyOffFunc = func(s []string) error {
+	if err := appctl.DisableSystemProxy(); err != nil {
+		return fmt.Errorf(stderror.RestoreSystemProxyFailedErr, err)
+	}
+	log.Infof("system proxy is restored")
+	return nil
+}
+
 var clientStopFunc = func(s []string) error {
 	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
 		log.Infof(stderror.ClientNotRunning)
 		return nil
 	}
 
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.StopRPCTimeout))
 	defer cancelFunc()
 	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
 	if err != nil {
@@ -574,18 +1227,262 @@ var clientStatusFunc = func(s []string) error {
 			return fmt.Errorf(stderror.ClientNotRunningErr, err)
 		}
 	}
-	log.Infof("mieru client is running")
+	if !jsonOutput {
+		log.Infof("mieru client is running")
+	}
+
+	// Show status notes and recent messages from proxy servers.
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
+	if err != nil {
+		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
+	}
+	if jsonOutput {
+		status, err := client.GetStatus(timedctx, &appctlpb.Empty{})
+		if err != nil {
+			return fmt.Errorf(stderror.ClientNotRunningErr, err)
+		}
+		return printJSON(status)
+	}
+	if status, err := client.GetStatus(timedctx, &appctlpb.Empty{}); err == nil {
+		for _, note := range status.GetNotes() {
+			log.Infof("%s", note)
+		}
+	}
+
+	msgs, err := client.GetServerMessages(timedctx, &appctlpb.Empty{})
+	if err != nil {
+		log.Debugf("GetServerMessages() failed: %v", err)
+		return nil
+	}
+	for _, msg := range msgs.GetMessages() {
+		log.Infof("[%s] message from proxy server: %s", time.UnixMilli(msg.GetReceiveTimeUnixMilli()).Format(time.RFC3339), msg.GetText())
+	}
+	return nil
+}
+
+var clientTestFunc = func(s []string) error {
+	testURL := defaultConnectivityTestURL
+	if len(s) > 2 {
+		testURL = s[2]
+	}
+	u, err := url.Parse(testURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid test URL %q", testURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	destination := net.JoinHostPort(u.Hostname(), port)
+
+	var config *appctlpb.ClientConfig
+	var mux *protocolv2.Mux
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+		if mux != nil {
+			mux.Close()
+		}
+	}()
+	steps := []connectivityTestStep{
+		{
+			name: "load client config",
+			hint: "create or fix the client config with \"mieru apply config <FILE>\" command",
+			run: func(ctx context.Context) (string, error) {
+				var err error
+				config, err = appctl.LoadActiveClientConfig()
+				if err != nil {
+					return "", err
+				}
+				if err := appctl.ValidateFullClientConfig(config); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("profile %q", config.GetActiveProfile()), nil
+			},
+		},
+		{
+			name: "resolve proxy servers",
+			hint: "check the DNS settings and the proxy server addresses of the active profile",
+			run: func(ctx context.Context) (string, error) {
+				resolver, err := mieruclient.NewResolver(config)
+				if err != nil {
+					return "", err
+				}
+				endpoints, err := mieruclient.Endpoints(ctx, config, resolver)
+				if err != nil {
+					return "", err
+				}
+				mux, err = mieruclient.NewMux(config, endpoints)
+				return "", err
+			},
+		},
+		{
+			name: "connect to proxy server",
+			hint: "check the port bindings of the active profile, and make sure the proxy server ports are not blocked by firewall",
+			run: func(ctx context.Context) (string, error) {
+				var err error
+				conn, err = mux.DialContext(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%s %s", conn.RemoteAddr().Network(), conn.RemoteAddr().String()), nil
+			},
+		},
+		{
+			name: "open session to " + destination,
+			hint: "if the proxy server didn't respond, check the user name and password, and make sure the system time of client and server are accurate; otherwise check the network of the proxy server",
+			run: func(ctx context.Context) (string, error) {
+				socks5Server, err := socks5.New(&socks5.Config{
+					UseProxy:                 true,
+					ClientSideAuthentication: true,
+					ProxyMux:                 mux,
+					HandshakeTimeout:         connectivityTestStepTimeout,
+				})
+				if err != nil {
+					return "", err
+				}
+				return "", socks5Server.ConnectProxyConn(conn, destination)
+			},
+		},
+		{
+			name: "fetch " + testURL,
+			hint: "the proxy server is working, but the URL can't be fetched; try another URL",
+			run: func(ctx context.Context) (string, error) {
+				var once sync.Once
+				transport := &http.Transport{
+					DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+						var c net.Conn
+						once.Do(func() {
+							c = conn
+						})
+						if c == nil {
+							return nil, fmt.Errorf("connection to %s is already used", addr)
+						}
+						return c, nil
+					},
+					DisableKeepAlives: true,
+				}
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, testURL, nil)
+				if err != nil {
+					return "", err
+				}
+				resp, err := transport.RoundTrip(req)
+				if err != nil {
+					return "", err
+				}
+				defer resp.Body.Close()
+				return fmt.Sprintf("HTTP status %s", resp.Status), nil
+			},
+		},
+	}
+	return runConnectivityTest(steps)
+}
+
+var clientSpeedTestFunc = func(s []string) error {
+	size := int64(defaultSpeedTestMegabytes) << 20
+	if len(s) > 2 {
+		megabytes, err := parseSpeedTestSize(s[2])
+		if err != nil {
+			return err
+		}
+		size = int64(megabytes) << 20
+	}
+
+	config, err := appctl.LoadActiveClientConfig()
+	if err != nil {
+		if err == stderror.ErrFileNotExist {
+			return fmt.Errorf(stderror.ClientConfigNotExist)
+		}
+		return fmt.Errorf(stderror.LoadClientConfigFailedErr, err)
+	}
+	if err = appctl.ValidateFullClientConfig(config); err != nil {
+		return fmt.Errorf(stderror.ValidateFullClientConfigFailedErr, err)
+	}
+	resolver, err := mieruclient.NewResolver(config)
+	if err != nil {
+		return err
+	}
+	endpoints, err := mieruclient.Endpoints(context.Background(), config, resolver)
+	if err != nil {
+		return err
+	}
+
+	var failed bool
+	for _, transport := range []util.TransportProtocol{util.TCPTransport, util.UDPTransport} {
+		var group []protocolv2.UnderlayProperties
+		for _, endpoint := range endpoints {
+			if endpoint.TransportProtocol() == transport {
+				group = append(group, endpoint)
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+		name := "TCP"
+		if transport == util.UDPTransport {
+			name = "UDP"
+		}
+		log.Infof("%s:", name)
+		if err := runSpeedTest(config, group, size); err != nil {
+			log.Infof("  %v", err)
+			failed = true
+		}
+	}
+	if failed {
+		return fmt.Errorf(stderror.SpeedTestFailed)
+	}
 	return nil
 }
 
 var clientApplyConfigFunc = func(s []string) error {
-	_, err := appctl.LoadClientConfig()
+	oldConfig, err := appctl.LoadClientConfig()
 	if err == stderror.ErrFileNotExist {
 		if err = appctl.StoreClientConfig(&appctlpb.ClientConfig{}); err != nil {
 			return fmt.Errorf(stderror.StoreClientConfigFailedErr, err)
 		}
 	}
-	return appctl.ApplyJSONClientConfig(s[3])
+	if err := appctl.ApplyJSONClientConfig(s[3]); err != nil {
+		return err
+	}
+	recordClientConfigChange("apply config", oldConfig)
+	return nil
+}
+
+var clientValidateConfigFunc = func(s []string) error {
+	if err := appctl.ValidateJSONClientConfigFile(s[3]); err != nil {
+		return fmt.Errorf(stderror.ValidateConfigFileFailedErr, err)
+	}
+	log.Infof("%s is a valid mieru client config", s[3])
+	return nil
+}
+
+var clientEncryptConfigFunc = func(s []string) error {
+	if err := appctl.SetClientConfigEncryption(true); err != nil {
+		if errors.Is(err, stderror.ErrFileNotExist) {
+			return fmt.Errorf(stderror.ClientConfigNotExist)
+		}
+		return fmt.Errorf(stderror.StoreClientConfigFailedErr, err)
+	}
+	log.Infof("mieru client config is encrypted")
+	return nil
+}
+
+var clientDecryptConfigFunc = func(s []string) error {
+	if err := appctl.SetClientConfigEncryption(false); err != nil {
+		if errors.Is(err, stderror.ErrFileNotExist) {
+			return fmt.Errorf(stderror.ClientConfigNotExist)
+		}
+		return fmt.Errorf(stderror.StoreClientConfigFailedErr, err)
+	}
+	log.Infof("mieru client config is decrypted")
+	return nil
 }
 
 var clientDescribeConfigFunc = func(s []string) error {
@@ -595,22 +1492,52 @@ var clientDescribeConfigFunc = func(s []string) error {
 			return fmt.Errorf(stderror.StoreClientConfigFailedErr, err)
 		}
 	}
-	out, err := appctl.GetJSONClientConfig()
+	config, err := appctl.LoadClientConfig()
 	if err != nil {
 		return fmt.Errorf(stderror.GetClientConfigFailedErr, err)
 	}
-	log.Infof("%s", out)
-	return nil
+	return printConfig(s, config)
 }
 
 var clientImportConfigFunc = func(s []string) error {
-	_, err := appctl.LoadClientConfig()
+	oldConfig, err := appctl.LoadClientConfig()
 	if err == stderror.ErrFileNotExist {
 		if err = appctl.StoreClientConfig(&appctlpb.ClientConfig{}); err != nil {
 			return fmt.Errorf(stderror.StoreClientConfigFailedErr, err)
 		}
 	}
-	return appctl.ApplyURLClientConfig(s[3])
+	if appctl.IsMieruURL(s[3]) {
+		if err := appctl.ApplyURLClientConfig(s[3]); err != nil {
+			return err
+		}
+		recordClientConfigChange("import config", oldConfig)
+		return nil
+	}
+
+	// Import share links or Clash configuration of other proxy software.
+	input := s[3]
+	if b, err := os.ReadFile(s[3]); err == nil {
+		input = string(b)
+	}
+	stdin := bufio.NewReader(os.Stdin)
+	prompt := func(profileName, field string) (string, error) {
+		fmt.Printf("Enter %s of profile %q: ", field, profileName)
+		v, err := stdin.ReadString('\n')
+		v = strings.TrimSpace(v)
+		if v == "" {
+			if err != nil {
+				return "", fmt.Errorf("failed to read %s of profile %q: %w", field, profileName, err)
+			}
+			return "", fmt.Errorf("%s of profile %q is empty", field, profileName)
+		}
+		return v, nil
+	}
+	if err := appctl.ImportForeignClientConfig(input, prompt); err != nil {
+		return fmt.Errorf(stderror.ImportClientConfigFailedErr, err)
+	}
+	recordClientConfigChange("import config", oldConfig)
+	log.Infof("Client profiles are imported. Make sure mita server is configured with the same user name and password.")
+	return nil
 }
 
 var clientExportConfigFunc = func(s []string) error {
@@ -628,63 +1555,349 @@ var clientExportConfigFunc = func(s []string) error {
 	return nil
 }
 
+var clientGetConfigURLFunc = func(s []string) error {
+	profileNames, serverNames, err := parseConfigURLSelectors(s[3:])
+	if err != nil {
+		return err
+	}
+	out, err := appctl.GetScopedURLClientConfig(profileNames, serverNames)
+	if err != nil {
+		return fmt.Errorf(stderror.GetClientConfigFailedErr, err)
+	}
+	if jsonOutput {
+		b, err := json.Marshal(map[string]string{"url": out})
+		if err != nil {
+			return fmt.Errorf("json.Marshal() failed: %w", err)
+		}
+		out = string(b)
+	}
+	log.Infof("%s", out)
+	return nil
+}
+
+var clientGetDashboardURLFunc = func(s []string) error {
+	config, err := appctl.LoadActiveClientConfig()
+	if err != nil {
+		return fmt.Errorf(stderror.GetClientConfigFailedErr, err)
+	}
+	if config.WebDashboardPort == nil {
+		return fmt.Errorf("web dashboard is not enabled. Set webDashboardPort in client config")
+	}
+	token, err := appctl.ReadClientRPCToken()
+	if err != nil {
+		return fmt.Errorf("read client RPC token failed: %w. Start mieru client to create the token", err)
+	}
+	u := url.URL{
+		Scheme:   "http",
+		Host:     "localhost:" + strconv.Itoa(int(config.GetWebDashboardPort())),
+		Path:     "/",
+		RawQuery: url.Values{webui.TokenParam: []string{token}}.Encode(),
+	}
+	out := u.String()
+	if jsonOutput {
+		b, err := json.Marshal(map[string]string{"url": out})
+		if err != nil {
+			return fmt.Errorf("json.Marshal() failed: %w", err)
+		}
+		out = string(b)
+	}
+	log.Infof("%s", out)
+	return nil
+}
+
+var clientDecryptLogsFunc = func(s []string) error {
+	key, found := os.LookupEnv(log.LogEncryptionKeyEnv)
+	if !found {
+		return fmt.Errorf("environment variable %s is not set", log.LogEncryptionKeyEnv)
+	}
+	f, err := os.Open(s[3])
+	if err != nil {
+		return fmt.Errorf(stderror.DecryptLogFailedErr, err)
+	}
+	defer f.Close()
+	if err := log.DecryptLog(os.Stdout, f, []byte(key)); err != nil {
+		return fmt.Errorf(stderror.DecryptLogFailedErr, err)
+	}
+	return nil
+}
+
 var clientDeleteProfileFunc = func(s []string) error {
-	_, err := appctl.LoadClientConfig()
+	oldConfig, err := appctl.LoadClientConfig()
 	if err != nil {
 		return fmt.Errorf(stderror.LoadClientConfigFailedErr, err)
 	}
-	return appctl.DeleteClientConfigProfile(s[3])
+	if err := appctl.DeleteClientConfigProfile(s[3]); err != nil {
+		return err
+	}
+	recordClientConfigChange("delete profile", oldConfig)
+	return nil
+}
+
+var clientUpdateSubscriptionsFunc = func(s []string) error {
+	var updated []string
+	if err := appctl.IsClientDaemonRunning(context.Background()); err == nil {
+		// Let the client daemon connect to the new servers.
+		timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.SubscriptionRPCTimeout))
+		defer cancelFunc()
+		client, err := appctl.NewClientLifecycleRPCClient(timedctx)
+		if err != nil {
+			return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
+		}
+		res, err := client.UpdateSubscriptions(timedctx, &appctlpb.Empty{})
+		if err != nil {
+			return fmt.Errorf(stderror.UpdateSubscriptionsFailedErr, err)
+		}
+		updated = res.GetUpdatedProfiles()
+	} else {
+		oldConfig, err := appctl.LoadClientConfig()
+		if err != nil {
+			return fmt.Errorf(stderror.LoadClientConfigFailedErr, err)
+		}
+		updated, err = appctl.UpdateSubscriptions(context.Background())
+		if err != nil {
+			return fmt.Errorf(stderror.UpdateSubscriptionsFailedErr, err)
+		}
+		if len(updated) > 0 {
+			recordClientConfigChange("update subscriptions", oldConfig)
+		}
+	}
+	if len(updated) == 0 {
+		log.Infof("servers of all profiles are up to date")
+	}
+	for _, name := range updated {
+		log.Infof("servers of profile %q are updated", name)
+	}
+	return nil
 }
 
 var clientGetMetricsFunc = func(s []string) error {
 	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
-		log.Infof(stderror.ClientNotRunning)
-		return nil
+		return reportClientNotRunning()
 	}
 
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
 	if err != nil {
 		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
 	}
+	args, err := parseMetricsArgs(s)
+	if err != nil {
+		return err
+	}
+	if args.history != nil {
+		history, err := client.GetMetricsHistory(timedctx, args.history)
+		if err != nil {
+			return fmt.Errorf(stderror.GetMetricsFailedErr, err)
+		}
+		return printMetricsHistory(history)
+	}
 	metrics, err := client.GetMetrics(timedctx, &appctlpb.Empty{})
 	if err != nil {
 		return fmt.Errorf(stderror.GetMetricsFailedErr, err)
 	}
-	log.Infof("%s", metrics.GetJson())
+	out, err := filterMetrics(metrics.GetJson(), args)
+	if err != nil {
+		return err
+	}
+	log.Infof("%s", out)
 	return nil
 }
 
-var clientGetConnectionsFunc = func(s []string) error {
+var clientGetTopFunc = func(s []string) error {
 	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
-		log.Infof(stderror.ClientNotRunning)
+		return reportClientNotRunning()
+	}
+	req, err := parseTopDestinationsArgs(s)
+	if err != nil {
+		return err
+	}
+
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
+	if err != nil {
+		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
+	}
+	top, err := client.GetTopDestinations(timedctx, req)
+	if err != nil {
+		return fmt.Errorf(stderror.GetTopDestinationsFailedErr, err)
+	}
+	if jsonOutput {
+		return printJSON(top)
+	}
+	if len(top.GetDestinations()) == 0 {
+		log.Infof("no destination is connected via proxy yet")
 		return nil
 	}
+	rows := [][]string{{"Destination", "Connections", "Sent", "Received", "Last Seen"}}
+	for _, d := range top.GetDestinations() {
+		rows = append(rows, []string{
+			d.GetDestination(),
+			strconv.FormatInt(d.GetConnections(), 10),
+			formatBytes(d.GetBytesSent()),
+			formatBytes(d.GetBytesReceived()),
+			time.UnixMilli(d.GetLastSeenUnixMilli()).Format("2006-01-02 15:04:05"),
+		})
+	}
+	for _, line := range formatTable(rows) {
+		log.Infof("%s", line)
+	}
+	return nil
+}
+
+// defaultTopDestinations is the number of destinations shown by
+// "mieru get top" if --limit is not provided.
+const defaultTopDestinations = 20
+
+// parseTopDestinationsArgs parses "get top [--limit <N>]".
+func parseTopDestinationsArgs(s []string) (*appctlpb.GetTopDestinationsRequest, error) {
+	req := &appctlpb.GetTopDestinationsRequest{Limit: proto.Int32(defaultTopDestinations)}
+	if len(s) <= 3 {
+		return req, nil
+	}
+	if s[3] != "--limit" {
+		return nil, fmt.Errorf("unknown argument %q of get top command", s[3])
+	}
+	if len(s) != 5 {
+		return nil, fmt.Errorf("usage: mieru get top --limit <N>")
+	}
+	n, err := strconv.ParseInt(s[4], 10, 32)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("usage: mieru get top --limit <N>. %q is not a positive number", s[4])
+	}
+	req.Limit = proto.Int32(int32(n))
+	return req, nil
+}
+
+var clientGetAuditFunc = func(s []string) error {
+	req, err := parseAuditArgs(s)
+	if err != nil {
+		return err
+	}
+	auditLog, err := appctl.ReadClientAuditLog(int(req.GetLimit()))
+	if err != nil {
+		return fmt.Errorf(stderror.GetAuditLogFailedErr, err)
+	}
+	return printAuditLog(auditLog)
+}
+
+// recordClientConfigChange records the change of client config
+// made by the command line in the client audit log.
+func recordClientConfigChange(action string, oldConfig *appctlpb.ClientConfig) {
+	newConfig, err := appctl.LoadClientConfig()
+	if err != nil {
+		log.Warnf("unable to write audit log: %v", err)
+		return
+	}
+	appctl.RecordClientAudit(appctl.AuditSourceCLI, action, appctl.ConfigDiffSummary(oldConfig, newConfig))
+}
+
+var clientGetConnectionsFunc = func(s []string) error {
+	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
+		return reportClientNotRunning()
+	}
 
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
 	if err != nil {
 		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
 	}
+	if len(s) > 3 {
+		// Keep receiving updates until the user stops the command.
+		stream, err := client.WatchSessionInfo(context.Background(), &appctlpb.Empty{})
+		if err != nil {
+			return fmt.Errorf(stderror.GetConnectionsFailedErr, err)
+		}
+		return renderSessionInfoStream(stream.Recv)
+	}
 	info, err := client.GetSessionInfo(timedctx, &appctlpb.Empty{})
 	if err != nil {
 		return fmt.Errorf(stderror.GetConnectionsFailedErr, err)
 	}
+	if jsonOutput {
+		return printJSON(&appctlpb.SessionInfo{Sessions: info.GetSessions()})
+	}
 	for _, line := range info.GetTable() {
 		log.Infof("%s", line)
 	}
 	return nil
 }
 
+var clientTopFunc = func(s []string) error {
+	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
+		return reportClientNotRunning()
+	}
+
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
+	if err != nil {
+		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.WatchSessionInfo(ctx, &appctlpb.Empty{})
+	if err != nil {
+		return fmt.Errorf(stderror.GetConnectionsFailedErr, err)
+	}
+	getMetrics := func(ctx context.Context) (*appctlpb.Metrics, error) {
+		return client.GetMetrics(ctx, &appctlpb.Empty{})
+	}
+	return runTopDashboard(stream.Recv, getMetrics, cancel)
+}
+
+var clientSetLogLevelFunc = func(s []string) error {
+	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
+		return reportClientNotRunning()
+	}
+	req, err := parseSetLogLevelArgs(s)
+	if err != nil {
+		return err
+	}
+
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
+	if err != nil {
+		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
+	}
+	if _, err := client.SetLoggingLevel(timedctx, req); err != nil {
+		return fmt.Errorf(stderror.SetLoggingLevelFailedErr, err)
+	}
+	log.Infof("mieru client logging level is changed to %s", strings.ToLower(req.GetLevel().String()))
+	return nil
+}
+
+var clientLogsFunc = func(s []string) error {
+	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
+		return reportClientNotRunning()
+	}
+	req, err := parseLogsArgs(s)
+	if err != nil {
+		return err
+	}
+
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
+	if err != nil {
+		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
+	}
+	stream, err := client.GetLogs(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf(stderror.GetLogsFailedErr, err)
+	}
+	return printLogStream(stream.Recv)
+}
+
 var clientGetThreadDumpFunc = func(s []string) error {
 	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
-		log.Infof(stderror.ClientNotRunning)
-		return nil
+		return reportClientNotRunning()
 	}
 
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.ProfileRPCTimeout))
 	defer cancelFunc()
 	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
 	if err != nil {
@@ -694,36 +1907,70 @@ var clientGetThreadDumpFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.GetThreadDumpFailedErr, err)
 	}
+	if jsonOutput {
+		return printJSON(dump)
+	}
 	log.Infof("%s", dump.GetThreadDump())
 	return nil
 }
 
 var clientGetHeapProfileFunc = func(s []string) error {
 	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
-		log.Infof(stderror.ClientNotRunning)
-		return nil
+		return reportClientNotRunning()
 	}
 
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.ProfileRPCTimeout))
 	defer cancelFunc()
 	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
 	if err != nil {
 		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
 	}
-	if _, err := client.GetHeapProfile(timedctx, &appctlpb.ProfileSavePath{FilePath: proto.String(s[3])}); err != nil {
+	savePath := &appctlpb.ProfileSavePath{FilePath: proto.String(s[3])}
+	if _, err := client.GetHeapProfile(timedctx, savePath); err != nil {
 		return fmt.Errorf(stderror.GetHeapProfileFailedErr, err)
 	}
+	if jsonOutput {
+		return printJSON(savePath)
+	}
 	log.Infof("heap profile is saved to %q", s[3])
 	return nil
 }
 
+var clientTapSessionFunc = func(s []string) error {
+	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
+		log.Infof(stderror.ClientNotRunning)
+		return nil
+	}
+
+	sessionID, _ := strconv.ParseUint(s[3], 10, 32)
+	req := &appctlpb.SessionTap{SessionID: proto.Uint32(uint32(sessionID))}
+	if len(s) > 4 {
+		req.Address = proto.String(s[4])
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
+	if err != nil {
+		return fmt.Errorf(stderror.CreateClientLifecycleRPCClientFailedErr, err)
+	}
+	if _, err := client.SetSessionTap(timedctx, req); err != nil {
+		return fmt.Errorf(stderror.SetSessionTapFailedErr, err)
+	}
+	if req.Address != nil {
+		log.Infof("session %d is mirrored to %s", sessionID, req.GetAddress())
+	} else {
+		log.Infof("session %d is no longer mirrored", sessionID)
+	}
+	return nil
+}
+
 var clientStartCPUProfileFunc = func(s []string) error {
 	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
 		log.Infof(stderror.ClientNotRunning)
 		return nil
 	}
 
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
 	if err != nil {
@@ -742,7 +1989,7 @@ var clientStopCPUProfileFunc = func(s []string) error {
 		return nil
 	}
 
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	client, err := appctl.NewClientLifecycleRPCClient(timedctx)
 	if err != nil {
@@ -751,3 +1998,161 @@ var clientStopCPUProfileFunc = func(s []string) error {
 	client.StopCPUProfile(timedctx, &appctlpb.Empty{})
 	return nil
 }
+
+const (
+	// defaultConnectivityTestURL is fetched by "mieru test" if the URL is not provided.
+	defaultConnectivityTestURL = "https://www.google.com/generate_204"
+
+	// connectivityTestStepTimeout is the maximum duration of each step in "mieru test".
+	connectivityTestStepTimeout = 10 * time.Second
+)
+
+// connectivityTestStep is a step of "mieru test" command.
+type connectivityTestStep struct {
+	name string
+	hint string // how to fix the problem if the step failed
+	run  func(ctx context.Context) (detail string, err error)
+}
+
+// runConnectivityTest runs the steps in order and prints the latency of
+// each step. It stops at the first failed step.
+func runConnectivityTest(steps []connectivityTestStep) error {
+	begin := time.Now()
+	for i, step := range steps {
+		ctx, cancelFunc := context.WithTimeout(context.Background(), connectivityTestStepTimeout)
+		start := time.Now()
+		detail, err := step.run(ctx)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		cancelFunc()
+		if err != nil {
+			log.Infof("[%d/%d] %s: FAILED after %v", i+1, len(steps), step.name, elapsed)
+			log.Infof("Hint: %s", step.hint)
+			return fmt.Errorf(stderror.ConnectivityTestFailedErr, step.name, err)
+		}
+		if detail != "" {
+			log.Infof("[%d/%d] %s: OK in %v (%s)", i+1, len(steps), step.name, elapsed, detail)
+		} else {
+			log.Infof("[%d/%d] %s: OK in %v", i+1, len(steps), step.name, elapsed)
+		}
+	}
+	log.Infof("Connectivity test passed in %v", time.Since(begin).Round(time.Millisecond))
+	return nil
+}
+
+const (
+	// defaultSpeedTestMegabytes is the number of megabytes transferred in
+	// each direction by "mieru speedtest".
+	defaultSpeedTestMegabytes = 16
+
+	// speedTestPings is the number of round trips measured by "mieru speedtest".
+	speedTestPings = 10
+
+	// speedTestTimeout is the maximum duration of the speed test of each
+	// transport protocol.
+	speedTestTimeout = 5 * time.Minute
+)
+
+// parseSpeedTestSize returns the number of megabytes to transfer in speed test.
+func parseSpeedTestSize(arg string) (int, error) {
+	megabytes, err := strconv.Atoi(arg)
+	if err != nil || megabytes <= 0 || int64(megabytes)<<20 > speedtest.MaxBytes {
+		return 0, fmt.Errorf("usage: mieru speedtest [<MEGABYTES>]. %q is not a valid number of megabytes in range [1, %d]", arg, speedtest.MaxBytes>>20)
+	}
+	return megabytes, nil
+}
+
+// runSpeedTest measures the performance of the endpoints and prints the results.
+func runSpeedTest(config *appctlpb.ClientConfig, endpoints []protocolv2.UnderlayProperties, size int64) error {
+	mux, err := mieruclient.NewMux(config, endpoints)
+	if err != nil {
+		return err
+	}
+	defer mux.Close()
+	ctx, cancelFunc := context.WithTimeout(context.Background(), speedTestTimeout)
+	defer cancelFunc()
+	conn, err := mux.DialContext(ctx)
+	if err != nil {
+		return fmt.Errorf("connect to proxy server failed: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(speedTestTimeout))
+	socks5Server, err := socks5.New(&socks5.Config{
+		UseProxy:                 true,
+		ClientSideAuthentication: true,
+		ProxyMux:                 mux,
+		HandshakeTimeout:         connectivityTestStepTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	if err := socks5Server.ConnectProxyConn(conn, net.JoinHostPort(speedtest.Host, strconv.Itoa(speedtest.Port))); err != nil {
+		return fmt.Errorf("open speed test session failed: %w", err)
+	}
+	sentBefore := protocolv2.UnderlayUDPSegmentsSent.Load()
+	retransmitsBefore := protocolv2.UnderlayUDPRetransmits.Load()
+
+	ping, err := speedtest.Ping(conn, speedTestPings)
+	if err != nil {
+		return err
+	}
+	log.Infof("  RTT: %v", ping)
+	upload, err := speedtest.Upload(conn, size)
+	if err != nil {
+		return err
+	}
+	log.Infof("  Upload: %v", upload)
+	download, err := speedtest.Download(conn, size)
+	if err != nil {
+		return err
+	}
+	log.Infof("  Download: %v", download)
+	if endpoints[0].TransportProtocol() == util.UDPTransport {
+		sent := protocolv2.UnderlayUDPSegmentsSent.Load() - sentBefore
+		retransmits := protocolv2.UnderlayUDPRetransmits.Load() - retransmitsBefore
+		if sent > 0 {
+			log.Infof("  Retransmission: %.2f%% (%d of %d segments)", float64(retransmits)*100/float64(sent), retransmits, sent)
+		}
+	}
+	return nil
+}
+
+// reportClientNotRunning prints that mieru client is not running.
+// With --json flag, an error is returned instead, so nothing that is
+// not JSON is printed as the command output.
+func reportClientNotRunning() error {
+	if jsonOutput {
+		return fmt.Errorf(stderror.ClientNotRunning)
+	}
+	log.Infof(stderror.ClientNotRunning)
+	return nil
+}
+
+// hasSubscription returns true if any client profile has a subscription.
+func hasSubscription(config *appctlpb.ClientConfig) bool {
+	for _, profile := range config.GetProfiles() {
+		if profile.GetSubscription().GetUrl() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseConfigURLSelectors returns the profile names and server names
+// selected by "mieru get config-url" command options.
+func parseConfigURLSelectors(args []string) (profileNames, serverNames []string, err error) {
+	usage := "usage: mieru get config-url [--profile <PROFILE_NAME>] [--server <SERVER>]"
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return nil, nil, fmt.Errorf("%s. option %s has no value", usage, args[i])
+		}
+		switch args[i] {
+		case "--profile":
+			profileNames = append(profileNames, args[i+1])
+		case "--server":
+			serverNames = append(serverNames, args[i+1])
+		default:
+			return nil, nil, fmt.Errorf("%s. unknown option %s", usage, args[i])
+		}
+	}
+	return profileNames, serverNames, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestListenPrivateUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix file permission is not supported on Windows")
+	}
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "rpc.sock")
+
+	// The socket file left by the previous run is replaced.
+	stale, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := listenPrivateUnixSocket(socketPath)
+	if err != nil {
+		t.Fatalf("listenPrivateUnixSocket() failed: %v", err)
+	}
+	defer l.Close()
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("os.Stat() failed: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		t.Errorf("%q is not a socket", socketPath)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("got socket permission %v, want %v", perm, os.FileMode(0600))
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("os.ReadDir() failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d entries in socket directory, want 1", len(entries))
+	}
+
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	conn.Close()
+
+	// A file that is not a socket is not replaced.
+	filePath := filepath.Join(dir, "file")
+	if err := os.WriteFile(filePath, []byte("data"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	if _, err := listenPrivateUnixSocket(filePath); err == nil {
+		t.Errorf("listenPrivateUnixSocket() replaced a regular file")
+	}
+}
@@ -21,6 +21,7 @@ type helpFormatter struct {
 	appName  string
 	entries  []helpCmdEntry
 	advanced []helpCmdEntry
+	flags    []helpCmdEntry
 }
 
 type helpCmdEntry struct {
@@ -49,4 +50,26 @@ func (m helpFormatter) print() {
 			log.Infof("")
 		}
 	}
+	if len(m.flags) != 0 {
+		log.Infof("Flags accepted by all commands:")
+		for _, entry := range m.flags {
+			log.Infof("  %s", entry.cmd)
+			log.Infof("        %s", entry.help)
+			log.Infof("")
+		}
+	}
+}
+
+// globalFlagEntries returns the help of flags parsed by parseGlobalFlags().
+func globalFlagEntries() []helpCmdEntry {
+	return []helpCmdEntry{
+		{
+			cmd:  "--timeout <DURATION>",
+			help: "Set the timeout of RPC calls to the running daemon, e.g. \"30s\" or \"5m\".",
+		},
+		{
+			cmd:  "--json",
+			help: "Print machine-readable JSON output. Supported by status, describe config and get commands.",
+		},
+	}
 }
@@ -19,6 +19,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl"
 )
 
 // binaryName is the name of this program.
@@ -28,11 +31,18 @@ type matchProcessor struct {
 	matches   []string
 	validator func([]string) error
 	callback  func([]string) error
+
+	// json is true if the callback supports --json flag.
+	json bool
 }
 
 // hooks contains registered callbacks.
 var hooks = make([]matchProcessor, 0)
 
+// jsonOutput is true if --json flag is set.
+// Commands that support JSON output print machine-readable JSON to stdout.
+var jsonOutput bool
+
 // RegisterCallback registers a CLI parser callback before the CLI arguments are processed.
 //
 // exactMatches is a list of strings that must match os.Args for the callback to be selected.
@@ -50,10 +60,25 @@ func RegisterCallback(exactMatches []string, validator func([]string) error, cal
 	})
 }
 
+// RegisterJSONCallback is the same as RegisterCallback, except that
+// the command also supports --json flag. The callback must check
+// jsonOutput and print a JSON document in that case.
+func RegisterJSONCallback(exactMatches []string, validator func([]string) error, callback func([]string) error) {
+	hooks = append(hooks, matchProcessor{
+		matches:   exactMatches,
+		validator: validator,
+		callback:  callback,
+		json:      true,
+	})
+}
+
 // ParseAndExecute runs the command coming from args.
 // This function will wait for the command to finish before return.
 func ParseAndExecute() error {
-	args := os.Args
+	args, err := parseGlobalFlags(os.Args)
+	if err != nil {
+		return err
+	}
 	found := false
 	for _, hook := range hooks {
 		if !doExactMatch(args, hook.matches) {
@@ -63,6 +88,9 @@ func ParseAndExecute() error {
 		if err := hook.validator(args); err != nil {
 			return err
 		}
+		if jsonOutput && !hook.json {
+			return fmt.Errorf("command %q doesn't support --json flag", strings.Join(args[1:], " "))
+		}
 		err := hook.callback(args)
 		if err != nil {
 			return err
@@ -76,6 +104,46 @@ func ParseAndExecute() error {
 	return nil
 }
 
+// parseGlobalFlags applies the flags that are accepted by every command,
+// and returns the remaining arguments.
+//
+// Supported flags:
+//
+//	--timeout <DURATION> or --timeout=<DURATION>: the timeout of RPC calls.
+//	--json: print machine-readable JSON output.
+func parseGlobalFlags(args []string) ([]string, error) {
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		var value string
+		if args[i] == "--json" {
+			jsonOutput = true
+			continue
+		} else if args[i] == "--timeout" {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("usage: --timeout <DURATION>. No duration is provided")
+			}
+			value = args[i+1]
+			i++
+		} else if strings.HasPrefix(args[i], "--timeout=") {
+			value = strings.TrimPrefix(args[i], "--timeout=")
+		} else {
+			remaining = append(remaining, args[i])
+			continue
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --timeout value %q: %w", value, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("--timeout value %q must be positive", value)
+		}
+		if err := appctl.SetRPCTimeoutOverride(timeout); err != nil {
+			return nil, err
+		}
+	}
+	return remaining, nil
+}
+
 // doExactMatch checks whether `input` has the exact prefix as `want`.
 func doExactMatch(input, want []string) bool {
 	if len(input) < len(want) {
@@ -32,8 +32,10 @@ import (
 	"github.com/enfein/mieru/pkg/appctl/appctlpb"
 	"github.com/enfein/mieru/pkg/cipher"
 	"github.com/enfein/mieru/pkg/egress"
+	"github.com/enfein/mieru/pkg/event"
 	"github.com/enfein/mieru/pkg/http2socks"
 	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/logship"
 	"github.com/enfein/mieru/pkg/metrics"
 	"github.com/enfein/mieru/pkg/protocolv2"
 	"github.com/enfein/mieru/pkg/socks5"
@@ -81,7 +83,7 @@ func RegisterServerCommands() {
 		},
 		serverReloadFunc,
 	)
-	RegisterCallback(
+	RegisterJSONCallback(
 		[]string{"", "status"},
 		func(s []string) error {
 			return unexpectedArgsError(s, 2)
@@ -101,10 +103,20 @@ func RegisterServerCommands() {
 		serverApplyConfigFunc,
 	)
 	RegisterCallback(
-		[]string{"", "describe", "config"},
+		[]string{"", "validate", "config"},
 		func(s []string) error {
-			return unexpectedArgsError(s, 3)
+			if len(s) < 4 {
+				return fmt.Errorf("usage: mita validate config <FILE>. no config file is provided")
+			} else if len(s) > 4 {
+				return fmt.Errorf("usage: mita validate config <FILE>. more than 1 config file is provided")
+			}
+			return nil
 		},
+		serverValidateConfigFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "describe", "config"},
+		describeConfigArgsValidator,
 		serverDescribeConfigFunc,
 	)
 	RegisterCallback(
@@ -117,6 +129,30 @@ func RegisterServerCommands() {
 		},
 		serverDeleteUserFunc,
 	)
+	RegisterCallback(
+		[]string{"", "add", "binding"},
+		func(s []string) error {
+			if len(s) < 4 {
+				return fmt.Errorf("usage: mita add binding <PORT>/<PROTOCOL>. no port binding is provided")
+			} else if len(s) > 4 {
+				return fmt.Errorf("usage: mita add binding <PORT>/<PROTOCOL>. more than 1 port binding is provided")
+			}
+			return nil
+		},
+		serverAddBindingFunc,
+	)
+	RegisterCallback(
+		[]string{"", "delete", "binding"},
+		func(s []string) error {
+			if len(s) < 4 {
+				return fmt.Errorf("usage: mita delete binding <PORT>/<PROTOCOL>. no port binding is provided")
+			} else if len(s) > 4 {
+				return fmt.Errorf("usage: mita delete binding <PORT>/<PROTOCOL>. more than 1 port binding is provided")
+			}
+			return nil
+		},
+		serverDeleteBindingFunc,
+	)
 	RegisterCallback(
 		[]string{"", "version"},
 		func(s []string) error {
@@ -127,32 +163,117 @@ func RegisterServerCommands() {
 	RegisterCallback(
 		[]string{"", "check", "update"},
 		func(s []string) error {
-			return unexpectedArgsError(s, 3)
+			return unexpectedArgsError(s, 4)
 		},
 		checkUpdateFunc,
 	)
-	RegisterCallback(
+	RegisterJSONCallback(
 		[]string{"", "get", "metrics"},
 		func(s []string) error {
-			return unexpectedArgsError(s, 3)
+			_, err := parseMetricsArgs(s)
+			return err
 		},
 		serverGetMetricsFunc,
 	)
-	RegisterCallback(
+	RegisterJSONCallback(
 		[]string{"", "get", "connections"},
 		func(s []string) error {
+			if len(s) > 3 && s[3] == "--watch" {
+				return unexpectedArgsError(s, 4)
+			}
 			return unexpectedArgsError(s, 3)
 		},
 		serverGetConnectionsFunc,
 	)
+	RegisterJSONCallback(
+		[]string{"", "get", "audit"},
+		func(s []string) error {
+			_, err := parseAuditArgs(s)
+			return err
+		},
+		serverGetAuditFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "get", "bans"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		serverGetBansFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "get", "users"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 3)
+		},
+		serverGetUsersFunc,
+	)
+	RegisterCallback(
+		[]string{"", "ban", "ip"},
+		func(s []string) error {
+			if len(s) < 4 {
+				return fmt.Errorf("usage: mita ban ip <CIDR>. no IP range is provided")
+			} else if len(s) > 4 {
+				return fmt.Errorf("usage: mita ban ip <CIDR>. more than 1 IP range is provided")
+			}
+			return nil
+		},
+		serverBanIPFunc,
+	)
+	RegisterCallback(
+		[]string{"", "unban", "ip"},
+		func(s []string) error {
+			if len(s) < 4 {
+				return fmt.Errorf("usage: mita unban ip <ADDR>. no IP address is provided")
+			} else if len(s) > 4 {
+				return fmt.Errorf("usage: mita unban ip <ADDR>. more than 1 IP address is provided")
+			}
+			return nil
+		},
+		serverUnbanIPFunc,
+	)
+	RegisterCallback(
+		[]string{"", "top"},
+		func(s []string) error {
+			return unexpectedArgsError(s, 2)
+		},
+		serverTopFunc,
+	)
+	RegisterJSONCallback(
+		[]string{"", "logs"},
+		func(s []string) error {
+			_, err := parseLogsArgs(s)
+			return err
+		},
+		serverLogsFunc,
+	)
 	RegisterCallback(
+		[]string{"", "set", "log-level"},
+		func(s []string) error {
+			_, err := parseSetLogLevelArgs(s)
+			return err
+		},
+		serverSetLogLevelFunc,
+	)
+	RegisterCallback(
+		[]string{"", "send", "message"},
+		func(s []string) error {
+			if len(s) < 4 {
+				return fmt.Errorf("usage: mita send message <TEXT> [<USER_NAME>]. no message is provided")
+			} else if len(s) > 5 {
+				return fmt.Errorf("usage: mita send message <TEXT> [<USER_NAME>]. more than 1 user is provided")
+			}
+			return nil
+		},
+		serverSendMessageFunc,
+	)
+	RegisterJSONCallback(
 		[]string{"", "get", "thread-dump"},
 		func(s []string) error {
 			return unexpectedArgsError(s, 3)
 		},
 		serverGetThreadDumpFunc,
 	)
-	RegisterCallback(
+	RegisterJSONCallback(
 		[]string{"", "get", "heap-profile"},
 		func(s []string) error {
 			if len(s) < 4 {
@@ -211,31 +332,79 @@ var serverHelpFunc = func(s []string) error {
 			},
 			{
 				cmd:  "apply config <FILE>",
-				help: "Apply server configuration from JSON file.",
+				help: "Apply server configuration from JSON or YAML file.",
 			},
 			{
-				cmd:  "describe config",
-				help: "Show current server configuration.",
+				cmd:  "validate config <FILE>",
+				help: "Check server configuration in JSON or YAML file without applying it.",
+			},
+			{
+				cmd:  "describe config [--format json|yaml] [--show-secrets]",
+				help: "Show current server configuration. The default format is JSON. Passwords are hidden unless --show-secrets is used.",
 			},
 			{
 				cmd:  "delete user <USER_NAME>",
 				help: "Delete a user from server configuration.",
 			},
 			{
-				cmd:  "get metrics",
-				help: "Get mita server metrics.",
+				cmd:  "add binding <PORT>/<PROTOCOL>",
+				help: "Add a port binding like 443/tcp or 2012-2022/udp to server configuration. If proxy is running, it starts to listen to the ports without restart.",
+			},
+			{
+				cmd:  "delete binding <PORT>/<PROTOCOL>",
+				help: "Delete a port binding from server configuration. If proxy is running, it stops listening to the ports, and the connections from the ports are closed.",
+			},
+			{
+				cmd:  "get metrics [--history <DURATION>] [--group <GROUP>] [--name <NAME>] [--list-groups]",
+				help: "Get mita server metrics. With --history, show per-minute samples of the given duration, e.g. 1h. With --group and --name, only show metrics whose group and name contain the given text. With --list-groups, only show the names of metric groups.",
+			},
+			{
+				cmd:  "get connections [--watch]",
+				help: "Get mita server connections. With --watch, refresh the connections every second.",
+			},
+			{
+				cmd:  "get audit [--limit <N>]",
+				help: "Get the latest records of server configuration and lifecycle changes.",
+			},
+			{
+				cmd:  "get bans",
+				help: "Get source IP addresses that are banned or blocked.",
 			},
 			{
-				cmd:  "get connections",
-				help: "Get mita server connections.",
+				cmd:  "get users",
+				help: "Get users with active sessions, throughput, traffic, quota usage and last handshake time.",
+			},
+			{
+				cmd:  "ban ip <CIDR>",
+				help: "Block a source IP address or IP range. The blocklist is saved in server config.",
+			},
+			{
+				cmd:  "unban ip <CIDR>",
+				help: "Remove the ban of a source IP address, or remove an IP range from the blocklist.",
+			},
+			{
+				cmd:  "top",
+				help: "Show a live dashboard of mita server sessions, underlays and metrics.",
+			},
+			{
+				cmd:  "logs [-f] [--level <LEVEL>] [--lines <N>]",
+				help: "Show recent log lines of mita server. With -f, keep printing new log lines.",
+			},
+			{
+				cmd:  "set log-level <LEVEL>",
+				help: "Change the logging level of running mita server until it is restarted or reloaded, e.g. debug.",
+			},
+			{
+				cmd:  "send message <TEXT> [<USER_NAME>]",
+				help: "Send a message to connected clients. Optionally only send to a user.",
 			},
 			{
 				cmd:  "version",
 				help: "Show mita server version.",
 			},
 			{
-				cmd:  "check update",
-				help: "Check mita server update.",
+				cmd:  "check update [<URL>]",
+				help: "Check mita server update. Optionally query a mirror URL of GitHub latest release API.",
 			},
 		},
 		advanced: []helpCmdEntry{
@@ -260,6 +429,7 @@ var serverHelpFunc = func(s []string) error {
 				help: "Stop mita server CPU profile.",
 			},
 		},
+		flags: globalFlagEntries(),
 	}
 	helpFmt.print()
 	return nil
@@ -283,7 +453,7 @@ var serverStartFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	_, err = client.Start(timedctx, &appctlpb.Empty{})
 	if err != nil {
@@ -299,12 +469,20 @@ var serverRunFunc = func(s []string) error {
 	} else {
 		log.SetFormatter(&log.DaemonFormatter{})
 	}
+	if logFile, err := log.NewServerLogFile(); err != nil {
+		log.Errorf("log to stdout due to the following reason: %v", err)
+	} else if logFile != nil {
+		log.SetOutput(logFile)
+	}
 
 	appctl.SetAppStatus(appctlpb.AppStatus_IDLE)
 
 	var rpcTasks sync.WaitGroup
 	rpcTasks.Add(1)
 
+	// The RPC server doesn't serve requests until server privileges are dropped.
+	privilegeDropped := make(chan struct{})
+
 	// Run the RPC server in the background.
 	go func() {
 		rpcAddr := appctl.ServerUDS()
@@ -316,16 +494,24 @@ var serverRunFunc = func(s []string) error {
 		if err != nil {
 			log.Fatalf("listen on RPC address %q failed: %v", rpcAddr, err)
 		}
+		rpcToken, err := appctl.PrepareServerRPCToken()
+		if err != nil {
+			log.Fatalf("prepare server RPC token failed: %v", err)
+		}
 		if _, found := os.LookupEnv("MITA_INSECURE_UDS"); !found {
-			if err = updateServerUDSPermission(); err != nil {
+			if err = updateServerFilePermission(appctl.ServerUDS(), 0770); err != nil {
 				log.Fatalf("update server unix domain socket permission failed: %v", err)
 			}
+			if err = updateServerFilePermission(appctl.ServerRPCTokenPath(), 0640); err != nil {
+				log.Fatalf("update server RPC token permission failed: %v", err)
+			}
 		}
-		grpcServer := grpc.NewServer()
+		grpcServer := grpc.NewServer(appctl.RPCTokenServerOptions(rpcToken)...)
 		appctl.SetServerRPCServerRef(grpcServer)
 		appctlpb.RegisterServerLifecycleServiceServer(grpcServer, appctl.NewServerLifecycleService())
 		appctlpb.RegisterServerConfigServiceServer(grpcServer, appctl.NewServerConfigService())
 		close(appctl.ServerRPCServerStarted)
+		<-privilegeDropped
 		log.Infof("mita server daemon RPC server is running")
 		if err = grpcServer.Serve(rpcListener); err != nil {
 			log.Fatalf("run gRPC server failed: %v", err)
@@ -359,6 +545,12 @@ var serverRunFunc = func(s []string) error {
 		log.SetLevel(loggingLevel)
 	}
 
+	// Drop server privileges before the proxy is started.
+	if err := appctl.DropServerPrivileges(config.GetPrivilege()); err != nil {
+		return fmt.Errorf(stderror.DropServerPrivilegesFailedErr, err)
+	}
+	close(privilegeDropped)
+
 	// Disable client side metrics.
 	if clientDecryptionMetricGroup := metrics.GetMetricGroupByName(cipher.ClientDecryptionMetricGroupName); clientDecryptionMetricGroup != nil {
 		clientDecryptionMetricGroup.DisableLogging()
@@ -372,12 +564,40 @@ var serverRunFunc = func(s []string) error {
 		appctl.SetAppStatus(appctlpb.AppStatus_STARTING)
 
 		mux := protocolv2.NewMux(false).SetServerUsers(appctl.UserListToMap(config.GetUsers()))
+		if err := mux.SetReplayCache(appctl.ReplayCacheParameters(config)); err != nil {
+			return fmt.Errorf("SetReplayCache() failed: %w", err)
+		}
+		if err := protocolv2.SetRetransmissionConfig(appctl.RetransmissionConfig(config.GetAdvancedSettings().GetRetransmission())); err != nil {
+			return fmt.Errorf("SetRetransmissionConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetFlowControlConfig(appctl.FlowControlConfig(config.GetAdvancedSettings().GetFlowControl())); err != nil {
+			return fmt.Errorf("SetFlowControlConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetDeadPeerTimeout(appctl.DeadPeerTimeout(config.GetAdvancedSettings().GetDeadPeerTimeoutSeconds())); err != nil {
+			return fmt.Errorf("SetDeadPeerTimeout() failed: %w", err)
+		}
+		if err := protocolv2.SetTimingJitter(appctl.TimingJitter(config.GetAdvancedSettings().GetTimingJitterMillis())); err != nil {
+			return fmt.Errorf("SetTimingJitter() failed: %w", err)
+		}
+		if err := protocolv2.SetUnderlayTimeoutConfig(appctl.UnderlayTimeoutConfig(config.GetAdvancedSettings().GetUnderlayReadTimeoutSeconds(), config.GetAdvancedSettings().GetStuckUnderlayTimeoutSeconds())); err != nil {
+			return fmt.Errorf("SetUnderlayTimeoutConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetIPBanConfig(appctl.IPBanConfig(config.GetAdvancedSettings().GetIpBan())); err != nil {
+			return fmt.Errorf("SetIPBanConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetConnectionLimitConfig(appctl.ConnectionLimitConfig(config.GetAdvancedSettings().GetConnectionLimits())); err != nil {
+			return fmt.Errorf("SetConnectionLimitConfig() failed: %w", err)
+		}
+		if err := protocolv2.SetIPBlocklist(config.GetBlockedIPRanges()); err != nil {
+			return fmt.Errorf("SetIPBlocklist() failed: %w", err)
+		}
 		appctl.SetServerMuxRef(mux)
 		mtu := util.DefaultMTU
 		if config.GetMtu() != 0 {
 			mtu = int(config.GetMtu())
 		}
-		endpoints, err := appctl.PortBindingsToUnderlayProperties(config.GetPortBindings(), mtu)
+		rotatedPortBindings := appctl.RotatedPortBindings(config.GetPortRotation(), time.Now())
+		endpoints, err := appctl.PortBindingsToUnderlayProperties(append(config.GetPortBindings(), rotatedPortBindings...), mtu)
 		if err != nil {
 			return err
 		}
@@ -387,8 +607,25 @@ var serverRunFunc = func(s []string) error {
 		socks5Config := &socks5.Config{
 			AllowLocalDestination:    config.GetAdvancedSettings().GetAllowLocalDestination(),
 			ClientSideAuthentication: true,
+			ClientIsolation:          config.GetAdvancedSettings().GetClientIsolation(),
+			IsClientIP:               mux.IsPeerIP,
 			EgressController:         egress.NewSocks5Controller(config.GetEgress()),
 			HandshakeTimeout:         10 * time.Second,
+			Outbound:                 appctl.OutboundConfig(config.GetEgress().GetSource()),
+		}
+		socks5Config.Resolver, err = appctl.ServerDNSResolver(config.GetDns())
+		if err != nil {
+			return err
+		}
+		if len(config.GetAdvancedSettings().GetPriorityRules()) != 0 {
+			socks5Config.PriorityRules, err = socks5.NewPriorityRules(config.GetAdvancedSettings().GetPriorityRules())
+			if err != nil {
+				return fmt.Errorf("NewPriorityRules() failed: %w", err)
+			}
+		}
+		socks5Config.EgressPolicy, err = egress.NewPolicy(config.GetEgress().GetPolicy(), config.GetUsers(), config.GetAdvancedSettings().GetAllowLocalDestination())
+		if err != nil {
+			return fmt.Errorf("NewPolicy() failed: %w", err)
 		}
 		socks5Server, err := socks5.New(socks5Config)
 		if err != nil {
@@ -405,6 +642,7 @@ var serverRunFunc = func(s []string) error {
 			if err = mux.Start(); err != nil {
 				log.Fatalf("socks5 server listening failed: %v", err)
 			}
+			appctl.StartPortRotation(mux, config.GetPortRotation(), rotatedPortBindings, mtu)
 			initProxyTasks.Done()
 
 			log.Infof("mita server daemon socks5 server is running")
@@ -417,7 +655,23 @@ var serverRunFunc = func(s []string) error {
 
 		initProxyTasks.Wait()
 		metrics.EnableLogging()
+		if err := appctl.StartStatsdExport(config.GetStatsd()); err != nil {
+			log.Errorf("start StatsD export failed: %v", err)
+		}
+		if err := appctl.StartTracingExport(config.GetTracing(), "mita"); err != nil {
+			log.Errorf("start tracing export failed: %v", err)
+		}
+		if err := appctl.StartWebhookExport(config.GetWebhook(), "mita"); err != nil {
+			log.Errorf("start webhook export failed: %v", err)
+		}
+		if err := appctl.StartLogShipping(config.GetLogShipping(), "mita"); err != nil {
+			log.Errorf("start log shipping failed: %v", err)
+		}
+		if err := appctl.StartServerMetricsPersistence(); err != nil {
+			log.Errorf("start metrics persistence failed: %v", err)
+		}
 		appctl.SetAppStatus(appctlpb.AppStatus_RUNNING)
+		event.Emit(event.DaemonStart, "", nil, "mita server daemon is started")
 		proxyTasks.Wait()
 	}
 	// If fails to validate server configuration, do nothing.
@@ -425,6 +679,9 @@ var serverRunFunc = func(s []string) error {
 	// It will be set by new RPC calls.
 
 	rpcTasks.Wait()
+	metrics.StopPersistence()
+	event.DisableWebhook()
+	logship.Disable()
 
 	// Stop CPU profiling, if previously started.
 	pprof.StopCPUProfile()
@@ -450,7 +707,7 @@ var serverStopFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.StopRPCTimeout))
 	defer cancelFunc()
 	if _, err = client.Stop(timedctx, &appctlpb.Empty{}); err != nil {
 		return fmt.Errorf(stderror.StopServerProxyFailedErr, err)
@@ -472,7 +729,7 @@ var serverReloadFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	if _, err = client.Reload(timedctx, &appctlpb.Empty{}); err != nil {
 		return fmt.Errorf(stderror.ReloadServerFailedErr, err)
@@ -501,6 +758,9 @@ var serverStatusFunc = func(s []string) error {
 	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
 		return fmt.Errorf(stderror.ServerNotRunningErr, err)
 	}
+	if jsonOutput {
+		return printJSON(appStatus)
+	}
 	if err := appctl.IsServerProxyRunning(appStatus); err != nil {
 		log.Infof("%s", err.Error())
 	} else {
@@ -519,9 +779,9 @@ var serverApplyConfigFunc = func(s []string) error {
 	}
 
 	path := s[3]
-	b, err := os.ReadFile(path)
+	b, err := appctl.ReadConfigFileAsJSON(path)
 	if err != nil {
-		return fmt.Errorf("os.ReadFile(%q) failed: %w", path, err)
+		return err
 	}
 	patch := &appctlpb.ServerConfig{}
 	if err = appctl.Unmarshal(b, patch); err != nil {
@@ -535,7 +795,7 @@ var serverApplyConfigFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerConfigRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	_, err = client.SetConfig(timedctx, patch)
 	if err != nil {
@@ -544,6 +804,14 @@ var serverApplyConfigFunc = func(s []string) error {
 	return nil
 }
 
+var serverValidateConfigFunc = func(s []string) error {
+	if err := appctl.ValidateJSONServerConfigFile(s[3]); err != nil {
+		return fmt.Errorf(stderror.ValidateConfigFileFailedErr, err)
+	}
+	log.Infof("%s is a valid mita server config", s[3])
+	return nil
+}
+
 var serverDescribeConfigFunc = func(s []string) error {
 	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
 	if err != nil {
@@ -557,18 +825,13 @@ var serverDescribeConfigFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerConfigRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	config, err := client.GetConfig(timedctx, &appctlpb.Empty{})
 	if err != nil {
 		return fmt.Errorf(stderror.GetServerConfigFailedErr, err)
 	}
-	jsonBytes, err := appctl.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("protojson.Marshal() failed: %w", err)
-	}
-	log.Infof("%s", string(jsonBytes))
-	return nil
+	return printConfig(s, config)
 }
 
 var serverDeleteUserFunc = func(s []string) error {
@@ -584,7 +847,7 @@ var serverDeleteUserFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerConfigRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	config, err := client.GetConfig(timedctx, &appctlpb.Empty{})
 	if err != nil {
@@ -625,13 +888,267 @@ var serverGetMetricsFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
+	args, err := parseMetricsArgs(s)
+	if err != nil {
+		return err
+	}
+	if args.history != nil {
+		history, err := client.GetMetricsHistory(timedctx, args.history)
+		if err != nil {
+			return fmt.Errorf(stderror.GetMetricsFailedErr, err)
+		}
+		return printMetricsHistory(history)
+	}
 	metrics, err := client.GetMetrics(timedctx, &appctlpb.Empty{})
 	if err != nil {
 		return fmt.Errorf(stderror.GetMetricsFailedErr, err)
 	}
-	log.Infof("%s", metrics.GetJson())
+	out, err := filterMetrics(metrics.GetJson(), args)
+	if err != nil {
+		return err
+	}
+	log.Infof("%s", out)
+	return nil
+}
+
+var serverGetAuditFunc = func(s []string) error {
+	req, err := parseAuditArgs(s)
+	if err != nil {
+		return err
+	}
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	auditLog, err := client.GetAuditLog(timedctx, req)
+	if err != nil {
+		return fmt.Errorf(stderror.GetAuditLogFailedErr, err)
+	}
+	return printAuditLog(auditLog)
+}
+
+var serverGetBansFunc = func(s []string) error {
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	bans, err := client.GetBannedIPs(timedctx, &appctlpb.Empty{})
+	if err != nil {
+		return fmt.Errorf(stderror.GetBannedIPsFailedErr, err)
+	}
+	if jsonOutput {
+		return printJSON(bans)
+	}
+	if len(bans.GetBannedIPs()) == 0 {
+		log.Infof("no IP address is banned")
+		return nil
+	}
+	rows := [][]string{{"IP", "Failures", "BannedUntil"}}
+	for _, banned := range bans.GetBannedIPs() {
+		if banned.GetBlocklisted() {
+			rows = append(rows, []string{banned.GetIp(), "-", "blocklist"})
+			continue
+		}
+		rows = append(rows, []string{
+			banned.GetIp(),
+			fmt.Sprintf("%d", banned.GetFailures()),
+			time.UnixMilli(banned.GetUntilUnixMilli()).Format("2006-01-02 15:04:05"),
+		})
+	}
+	for _, line := range formatTable(rows) {
+		log.Infof("%s", line)
+	}
+	return nil
+}
+
+var serverGetUsersFunc = func(s []string) error {
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	users, err := client.GetUsers(timedctx, &appctlpb.Empty{})
+	if err != nil {
+		return fmt.Errorf(stderror.GetUsersFailedErr, err)
+	}
+	if jsonOutput {
+		return printJSON(users)
+	}
+	if len(users.GetUsers()) == 0 {
+		log.Infof("no user is configured")
+		return nil
+	}
+	rows := [][]string{{"User", "Sessions", "Read/s", "Write/s", "ReadBytes", "WriteBytes", "Quota", "LastHandshake"}}
+	for _, user := range users.GetUsers() {
+		quota := "-"
+		if len(user.GetQuotas()) > 0 {
+			var quotas []string
+			for _, q := range user.GetQuotas() {
+				status := fmt.Sprintf("%s / %d MiB %s", formatBytes(q.GetUsedBytes()), q.GetQuota().GetMegabytes(), quotaPeriodString(q.GetQuota()))
+				if q.GetExhausted() {
+					status += " (exhausted)"
+				}
+				quotas = append(quotas, status)
+			}
+			quota = strings.Join(quotas, ", ")
+		}
+		lastHandshake := "-"
+		if user.LastHandshakeUnixMilli != nil {
+			lastHandshake = time.UnixMilli(user.GetLastHandshakeUnixMilli()).Format("2006-01-02 15:04:05")
+		}
+		rows = append(rows, []string{
+			user.GetName(),
+			fmt.Sprintf("%d", user.GetActiveSessions()),
+			formatBytes(user.GetReadBytesPerSecond()),
+			formatBytes(user.GetWriteBytesPerSecond()),
+			formatBytes(user.GetReadBytes()),
+			formatBytes(user.GetWriteBytes()),
+			quota,
+			lastHandshake,
+		})
+	}
+	for _, line := range formatTable(rows) {
+		log.Infof("%s", line)
+	}
+	return nil
+}
+
+// quotaPeriodString returns the human readable period of the quota.
+func quotaPeriodString(quota *appctlpb.Quota) string {
+	switch quota.GetPeriod() {
+	case appctlpb.QuotaPeriod_QUOTA_PERIOD_CALENDAR_MONTH:
+		return "this month"
+	case appctlpb.QuotaPeriod_QUOTA_PERIOD_TOTAL:
+		return "in total"
+	default:
+		return fmt.Sprintf("in %d days", quota.GetDays())
+	}
+}
+
+var serverBanIPFunc = func(s []string) error {
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	if _, err := client.BanIP(timedctx, &appctlpb.BanIPRequest{IpRange: proto.String(s[3])}); err != nil {
+		return fmt.Errorf(stderror.BanIPFailedErr, err)
+	}
+	log.Infof("IP range %s is blocked", s[3])
+	return nil
+}
+
+var serverAddBindingFunc = func(s []string) error {
+	binding, err := appctl.ParsePortBinding(s[3])
+	if err != nil {
+		return fmt.Errorf(stderror.AddPortBindingFailedErr, err)
+	}
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	if _, err := client.AddPortBinding(timedctx, binding); err != nil {
+		return fmt.Errorf(stderror.AddPortBindingFailedErr, err)
+	}
+	log.Infof("port binding %s is added", s[3])
+	return nil
+}
+
+var serverDeleteBindingFunc = func(s []string) error {
+	binding, err := appctl.ParsePortBinding(s[3])
+	if err != nil {
+		return fmt.Errorf(stderror.DeletePortBindingFailedErr, err)
+	}
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	if _, err := client.DeletePortBinding(timedctx, binding); err != nil {
+		return fmt.Errorf(stderror.DeletePortBindingFailedErr, err)
+	}
+	log.Infof("port binding %s is deleted", s[3])
+	return nil
+}
+
+var serverUnbanIPFunc = func(s []string) error {
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	if _, err := client.UnbanIP(timedctx, &appctlpb.UnbanIPRequest{Ip: proto.String(s[3])}); err != nil {
+		return fmt.Errorf(stderror.UnbanIPFailedErr, err)
+	}
+	log.Infof("IP address %s is unbanned", s[3])
 	return nil
 }
 
@@ -648,18 +1165,131 @@ var serverGetConnectionsFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
+	if len(s) > 3 {
+		// Keep receiving updates until the user stops the command.
+		stream, err := client.WatchSessionInfo(context.Background(), &appctlpb.Empty{})
+		if err != nil {
+			return fmt.Errorf(stderror.GetConnectionsFailedErr, err)
+		}
+		return renderSessionInfoStream(stream.Recv)
+	}
 	info, err := client.GetSessionInfo(timedctx, &appctlpb.Empty{})
 	if err != nil {
 		return fmt.Errorf(stderror.GetConnectionsFailedErr, err)
 	}
+	if jsonOutput {
+		return printJSON(&appctlpb.SessionInfo{Sessions: info.GetSessions()})
+	}
 	for _, line := range info.GetTable() {
 		log.Infof("%s", line)
 	}
 	return nil
 }
 
+var serverTopFunc = func(s []string) error {
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.WatchSessionInfo(ctx, &appctlpb.Empty{})
+	if err != nil {
+		return fmt.Errorf(stderror.GetConnectionsFailedErr, err)
+	}
+	getMetrics := func(ctx context.Context) (*appctlpb.Metrics, error) {
+		return client.GetMetrics(ctx, &appctlpb.Empty{})
+	}
+	return runTopDashboard(stream.Recv, getMetrics, cancel)
+}
+
+var serverSetLogLevelFunc = func(s []string) error {
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+	req, err := parseSetLogLevelArgs(s)
+	if err != nil {
+		return err
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	if _, err := client.SetLoggingLevel(timedctx, req); err != nil {
+		return fmt.Errorf(stderror.SetLoggingLevelFailedErr, err)
+	}
+	log.Infof("mita server logging level is changed to %s", strings.ToLower(req.GetLevel().String()))
+	return nil
+}
+
+var serverLogsFunc = func(s []string) error {
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+	req, err := parseLogsArgs(s)
+	if err != nil {
+		return err
+	}
+
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	stream, err := client.GetLogs(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf(stderror.GetLogsFailedErr, err)
+	}
+	return printLogStream(stream.Recv)
+}
+
+var serverSendMessageFunc = func(s []string) error {
+	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
+	if err != nil {
+		return fmt.Errorf(stderror.GetServerStatusFailedErr, err)
+	}
+	if err := appctl.IsServerDaemonRunning(appStatus); err != nil {
+		return fmt.Errorf(stderror.ServerNotRunningErr, err)
+	}
+
+	msg := &appctlpb.ServerMessage{Text: proto.String(s[3])}
+	if len(s) == 5 {
+		msg.UserName = proto.String(s[4])
+	}
+	client, err := appctl.NewServerLifecycleRPCClient()
+	if err != nil {
+		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
+	}
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+	defer cancelFunc()
+	res, err := client.SendServerMessage(timedctx, msg)
+	if err != nil {
+		return fmt.Errorf(stderror.SendServerMessageFailedErr, err)
+	}
+	log.Infof("message is sent to %d connections", res.GetSessionCount())
+	return nil
+}
+
 var serverGetThreadDumpFunc = func(s []string) error {
 	appStatus, err := appctl.GetServerStatusWithRPC(context.Background())
 	if err != nil {
@@ -673,12 +1303,15 @@ var serverGetThreadDumpFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.ProfileRPCTimeout))
 	defer cancelFunc()
 	dump, err := client.GetThreadDump(timedctx, &appctlpb.Empty{})
 	if err != nil {
 		return fmt.Errorf(stderror.GetThreadDumpFailedErr, err)
 	}
+	if jsonOutput {
+		return printJSON(dump)
+	}
 	log.Infof("%s", dump.GetThreadDump())
 	return nil
 }
@@ -696,11 +1329,15 @@ var serverGetHeapProfileFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.ProfileRPCTimeout))
 	defer cancelFunc()
-	if _, err := client.GetHeapProfile(timedctx, &appctlpb.ProfileSavePath{FilePath: proto.String(s[3])}); err != nil {
+	savePath := &appctlpb.ProfileSavePath{FilePath: proto.String(s[3])}
+	if _, err := client.GetHeapProfile(timedctx, savePath); err != nil {
 		return fmt.Errorf(stderror.GetHeapProfileFailedErr, err)
 	}
+	if jsonOutput {
+		return printJSON(savePath)
+	}
 	log.Infof("heap profile is saved to %q", s[3])
 	return nil
 }
@@ -718,7 +1355,7 @@ var serverStartCPUProfileFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	if _, err := client.StartCPUProfile(timedctx, &appctlpb.ProfileSavePath{FilePath: proto.String(s[4])}); err != nil {
 		return fmt.Errorf(stderror.StartCPUProfileFailedErr, err)
@@ -740,14 +1377,15 @@ var serverStopCPUProfileFunc = func(s []string) error {
 	if err != nil {
 		return fmt.Errorf(stderror.CreateServerLifecycleRPCClientFailedErr, err)
 	}
-	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.RPCTimeout)
+	timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
 	defer cancelFunc()
 	client.StopCPUProfile(timedctx, &appctlpb.Empty{})
 	return nil
 }
 
-// Update server unix domain socket permission to 770, belongs to root:mita.
-func updateServerUDSPermission() error {
+// Update the permission of a server file, e.g. unix domain socket,
+// and let it belong to root:mita.
+func updateServerFilePermission(path string, perm os.FileMode) error {
 	rootUidStr, err := getUid("root")
 	if err != nil {
 		return fmt.Errorf("getUid(%q) failed: %w", "root", err)
@@ -764,11 +1402,11 @@ func updateServerUDSPermission() error {
 	if err != nil {
 		return fmt.Errorf("convert mita UID with strconv.Atoi(%q) failed: %w", mitaGidStr, err)
 	}
-	if err = os.Chown(appctl.ServerUDS(), rootUid, mitaGid); err != nil {
-		return fmt.Errorf("os.Chown(%q) failed: %w", appctl.ServerUDS(), err)
+	if err = os.Chown(path, rootUid, mitaGid); err != nil {
+		return fmt.Errorf("os.Chown(%q) failed: %w", path, err)
 	}
-	if err = os.Chmod(appctl.ServerUDS(), 0770); err != nil {
-		return fmt.Errorf("os.Chmod(%q) failed: %w", appctl.ServerUDS(), err)
+	if err = os.Chmod(path, perm); err != nil {
+		return fmt.Errorf("os.Chmod(%q) failed: %w", path, err)
 	}
 	return nil
 }
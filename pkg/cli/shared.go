@@ -16,10 +16,23 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/enfein/mieru/pkg/appctl"
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
 	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/stderror"
+	"github.com/enfein/mieru/pkg/util/yaml"
 	"github.com/enfein/mieru/pkg/version"
+	"google.golang.org/protobuf/proto"
 )
 
 var versionFunc = func(s []string) error {
@@ -28,10 +41,419 @@ var versionFunc = func(s []string) error {
 }
 
 var checkUpdateFunc = func(s []string) error {
-	_, msg, err := version.CheckUpdate()
+	opts := version.CheckUpdateOptions{}
+	if len(s) > 3 {
+		opts.LatestReleaseURL = s[3]
+	}
+	if appctl.IsClientApp() {
+		// Avoid direct connection to GitHub when the proxy is available.
+		if proxyURL, err := clientProxyURL(); err == nil {
+			log.Debugf("check update via %s", proxyURL)
+			opts.ProxyURL = proxyURL
+		}
+	}
+	_, msg, err := version.CheckUpdate(opts)
 	if err != nil {
 		return fmt.Errorf("check update failed: %w", err)
 	}
 	log.Infof("%s", msg)
 	return nil
 }
+
+// clientProxyURL returns the socks5 proxy URL of the running mieru client.
+func clientProxyURL() (string, error) {
+	if err := appctl.IsClientDaemonRunning(context.Background()); err != nil {
+		return "", err
+	}
+	config, err := appctl.LoadActiveClientConfig()
+	if err != nil {
+		return "", fmt.Errorf(stderror.LoadClientConfigFailedErr, err)
+	}
+	return fmt.Sprintf("socks5://127.0.0.1:%d", config.GetSocks5Port()), nil
+}
+
+// printJSON prints a protobuf message as a JSON document.
+func printJSON(m proto.Message) error {
+	b, err := appctl.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("protojson.Marshal() failed: %w", err)
+	}
+	log.Infof("%s", string(b))
+	return nil
+}
+
+// describeConfigArgs are the arguments of "describe config" command.
+type describeConfigArgs struct {
+	// format is either "json" or "yaml".
+	format string
+
+	// showSecrets is true if passwords are printed.
+	showSecrets bool
+}
+
+// parseDescribeConfigArgs parses
+// "describe config [--format json|yaml] [--show-secrets]".
+func parseDescribeConfigArgs(s []string) (describeConfigArgs, error) {
+	args := describeConfigArgs{format: "json"}
+	for i := 3; i < len(s); i++ {
+		switch s[i] {
+		case "--format":
+			if i+1 >= len(s) {
+				return args, fmt.Errorf("usage: describe config --format json|yaml. no format is provided")
+			}
+			i++
+			if s[i] != "json" && s[i] != "yaml" {
+				return args, fmt.Errorf("usage: describe config --format json|yaml. format %q is not supported", s[i])
+			}
+			args.format = s[i]
+		case "--show-secrets":
+			args.showSecrets = true
+		default:
+			return args, unexpectedArgsError(s, i)
+		}
+	}
+	return args, nil
+}
+
+// describeConfigArgsValidator accepts
+// "describe config [--format json|yaml] [--show-secrets]".
+func describeConfigArgsValidator(s []string) error {
+	_, err := parseDescribeConfigArgs(s)
+	return err
+}
+
+// printConfig prints the config in JSON format, or in YAML format if
+// "--format yaml" is in the arguments of describe config command.
+// Passwords are redacted unless "--show-secrets" is in the arguments.
+func printConfig[T proto.Message](s []string, config T) error {
+	args, err := parseDescribeConfigArgs(s)
+	if err != nil {
+		return err
+	}
+	if !args.showSecrets {
+		config = appctl.RedactSecrets(config)
+	}
+	jsonBytes, err := appctl.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("protojson.Marshal() failed: %w", err)
+	}
+	if args.format == "yaml" {
+		if jsonOutput {
+			return fmt.Errorf("--format yaml can't be used with --json flag")
+		}
+		b, err := yaml.FromJSON(jsonBytes)
+		if err != nil {
+			return fmt.Errorf("convert config to YAML failed: %w", err)
+		}
+		log.Infof("%s", strings.TrimSuffix(string(b), "\n"))
+		return nil
+	}
+	log.Infof("%s", string(jsonBytes))
+	return nil
+}
+
+// renderSessionInfoStream prints the session table every time an update is
+// received, until the stream is closed.
+func renderSessionInfoStream(recv func() (*appctlpb.SessionInfo, error)) error {
+	for {
+		info, err := recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf(stderror.GetConnectionsFailedErr, err)
+		}
+		if jsonOutput {
+			if err := printJSON(&appctlpb.SessionInfo{Sessions: info.GetSessions()}); err != nil {
+				return err
+			}
+			continue
+		}
+		// Move the cursor to the top left corner and clear the screen.
+		fmt.Print("\033[H\033[2J")
+		log.Infof("%s  %d sessions", time.Now().Format(time.RFC3339), len(info.GetSessions()))
+		log.Infof("")
+		for _, line := range info.GetTable() {
+			log.Infof("%s", line)
+		}
+	}
+}
+
+// parseLogsArgs parses "logs [-f] [--level <LEVEL>] [--lines <N>]".
+func parseLogsArgs(s []string) (*appctlpb.GetLogsRequest, error) {
+	req := &appctlpb.GetLogsRequest{}
+	for i := 2; i < len(s); i++ {
+		switch s[i] {
+		case "-f", "--follow":
+			req.Follow = proto.Bool(true)
+		case "--level":
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("usage: logs --level <LEVEL>. no level is provided")
+			}
+			i++
+			level, ok := appctlpb.LoggingLevel_value[strings.ToUpper(s[i])]
+			if !ok {
+				return nil, fmt.Errorf("usage: logs --level <LEVEL>. level %q is not supported", s[i])
+			}
+			req.Level = appctlpb.LoggingLevel(level).Enum()
+		case "--lines":
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("usage: logs --lines <N>. number of lines is not provided")
+			}
+			i++
+			n, err := strconv.ParseInt(s[i], 10, 32)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("usage: logs --lines <N>. %q is not a positive number", s[i])
+			}
+			req.Lines = proto.Int32(int32(n))
+		default:
+			return nil, fmt.Errorf("unknown argument %q of logs command", s[i])
+		}
+	}
+	return req, nil
+}
+
+// parseSetLogLevelArgs parses "set log-level <LEVEL>".
+func parseSetLogLevelArgs(s []string) (*appctlpb.SetLoggingLevelRequest, error) {
+	if len(s) < 4 {
+		return nil, fmt.Errorf("usage: set log-level <LEVEL>. no level is provided")
+	}
+	if len(s) > 4 {
+		return nil, fmt.Errorf("usage: set log-level <LEVEL>. more than 1 level is provided")
+	}
+	level, ok := appctlpb.LoggingLevel_value[strings.ToUpper(s[3])]
+	if !ok || level == int32(appctlpb.LoggingLevel_DEFAULT) {
+		return nil, fmt.Errorf("usage: set log-level <LEVEL>. level %q is not supported, supported levels are fatal, error, warn, info, debug and trace", s[3])
+	}
+	return &appctlpb.SetLoggingLevelRequest{Level: appctlpb.LoggingLevel(level).Enum()}, nil
+}
+
+// metricsArgs are the arguments of "get metrics" command.
+type metricsArgs struct {
+	// history is not nil if the history is requested.
+	history *appctlpb.GetMetricsHistoryRequest
+
+	// groups and names filter metric groups and metrics.
+	// A metric is shown if its group name contains any of the groups,
+	// and its name contains any of the names, ignoring case.
+	groups []string
+	names  []string
+
+	// listGroups is true if only the names of metric groups are shown.
+	listGroups bool
+}
+
+// parseMetricsArgs parses
+// "get metrics [--history <DURATION>] [--group <GROUP>]... [--name <NAME>]... [--list-groups]".
+func parseMetricsArgs(s []string) (metricsArgs, error) {
+	var args metricsArgs
+	for i := 3; i < len(s); i++ {
+		switch s[i] {
+		case "--history", "--group", "--name":
+			if i+1 >= len(s) {
+				return args, fmt.Errorf("usage: get metrics %s <VALUE>. no value is provided", s[i])
+			}
+			flag, value := s[i], s[i+1]
+			i++
+			switch flag {
+			case "--history":
+				if args.history != nil {
+					return args, fmt.Errorf("usage: get metrics --history <DURATION>. more than 1 duration is provided")
+				}
+				d, err := time.ParseDuration(value)
+				if err != nil || d <= 0 {
+					return args, fmt.Errorf("usage: get metrics --history <DURATION>. %q is not a positive duration like 1h or 30m", value)
+				}
+				args.history = &appctlpb.GetMetricsHistoryRequest{DurationSeconds: proto.Int64(int64(d.Seconds()))}
+			case "--group":
+				args.groups = append(args.groups, value)
+			case "--name":
+				args.names = append(args.names, value)
+			}
+		case "--list-groups":
+			args.listGroups = true
+		default:
+			return args, fmt.Errorf("unknown argument %q of get metrics command", s[i])
+		}
+	}
+	if args.history != nil && (args.listGroups || len(args.groups) > 0 || len(args.names) > 0) {
+		return args, fmt.Errorf("--history can't be used together with --group, --name or --list-groups")
+	}
+	if args.listGroups && len(args.names) > 0 {
+		return args, fmt.Errorf("--list-groups can't be used together with --name")
+	}
+	return args, nil
+}
+
+// containsAnyFold returns true if s contains any of the substrings,
+// ignoring case. It returns true if the list of substrings is empty.
+func containsAnyFold(s string, substrs []string) bool {
+	if len(substrs) == 0 {
+		return true
+	}
+	s = strings.ToLower(s)
+	for _, sub := range substrs {
+		if strings.Contains(s, strings.ToLower(sub)) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterMetrics selects metric groups and metrics from the JSON returned
+// by GetMetrics RPC. If listGroups is set, the output is a JSON list of
+// group names. Otherwise, the output has the same format as the input.
+func filterMetrics(raw string, args metricsArgs) (string, error) {
+	if !args.listGroups && len(args.groups) == 0 && len(args.names) == 0 {
+		return raw, nil
+	}
+	var groups map[string]map[string]int64
+	if err := json.Unmarshal([]byte(raw), &groups); err != nil {
+		return "", fmt.Errorf("json.Unmarshal() failed: %w", err)
+	}
+	// Sort group names without case, the same as the daemon.
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		if containsAnyFold(name, args.groups) {
+			groupNames = append(groupNames, name)
+		}
+	}
+	sort.Slice(groupNames, func(i, j int) bool {
+		return strings.ToLower(groupNames[i]) < strings.ToLower(groupNames[j])
+	})
+	if args.listGroups {
+		names := groupNames
+		if !jsonOutput {
+			return strings.Join(names, "\n"), nil
+		}
+		b, err := json.MarshalIndent(names, "", "    ")
+		if err != nil {
+			return "", fmt.Errorf("json.Marshal() failed: %w", err)
+		}
+		return string(b), nil
+	}
+	var sb strings.Builder
+	sb.WriteString("{")
+	for _, groupName := range groupNames {
+		selected := make(map[string]int64)
+		for name, value := range groups[groupName] {
+			if containsAnyFold(name, args.names) {
+				selected[name] = value
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+		k, _ := json.Marshal(groupName)
+		v, err := json.Marshal(selected)
+		if err != nil {
+			return "", fmt.Errorf("json.Marshal() failed: %w", err)
+		}
+		if sb.Len() > 1 {
+			sb.WriteString(",")
+		}
+		sb.Write(k)
+		sb.WriteString(":")
+		sb.Write(v)
+	}
+	sb.WriteString("}")
+	var b bytes.Buffer
+	if err := json.Indent(&b, []byte(sb.String()), "", "    "); err != nil {
+		return "", fmt.Errorf("json.Indent() failed: %w", err)
+	}
+	return b.String(), nil
+}
+
+// printMetricsHistory prints one line for each metrics sample.
+func printMetricsHistory(h *appctlpb.MetricsHistory) error {
+	if jsonOutput {
+		return printJSON(h)
+	}
+	if len(h.GetSamples()) == 0 {
+		log.Infof("no metrics history is available yet")
+		return nil
+	}
+	rows := [][]string{{"Time", "InBytes", "OutBytes", "ActiveSessions", "Errors"}}
+	for _, sample := range h.GetSamples() {
+		rows = append(rows, []string{
+			time.UnixMilli(sample.GetTimeUnixMilli()).Format("2006-01-02 15:04"),
+			formatBytes(sample.GetInBytes()),
+			formatBytes(sample.GetOutBytes()),
+			strconv.FormatInt(sample.GetActiveSessions(), 10),
+			strconv.FormatInt(sample.GetErrors(), 10),
+		})
+	}
+	for _, line := range formatTable(rows) {
+		log.Infof("%s", line)
+	}
+	return nil
+}
+
+// defaultAuditRecords is the number of audit records shown
+// if the limit is not provided.
+const defaultAuditRecords = 20
+
+// parseAuditArgs parses "get audit [--limit <N>]".
+func parseAuditArgs(s []string) (*appctlpb.GetAuditLogRequest, error) {
+	req := &appctlpb.GetAuditLogRequest{Limit: proto.Int32(defaultAuditRecords)}
+	if len(s) <= 3 {
+		return req, nil
+	}
+	if s[3] != "--limit" {
+		return nil, fmt.Errorf("unknown argument %q of get audit command", s[3])
+	}
+	if len(s) != 5 {
+		return nil, fmt.Errorf("usage: get audit --limit <N>")
+	}
+	n, err := strconv.ParseInt(s[4], 10, 32)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("usage: get audit --limit <N>. %q is not a positive number", s[4])
+	}
+	req.Limit = proto.Int32(int32(n))
+	return req, nil
+}
+
+// printAuditLog prints one line for each audit record.
+func printAuditLog(l *appctlpb.AuditLog) error {
+	if jsonOutput {
+		return printJSON(l)
+	}
+	if len(l.GetRecords()) == 0 {
+		log.Infof("no audit record is available yet")
+		return nil
+	}
+	rows := [][]string{{"Time", "Source", "User", "Action", "Summary"}}
+	for _, r := range l.GetRecords() {
+		rows = append(rows, []string{
+			time.UnixMilli(r.GetTimeUnixMilli()).Format("2006-01-02 15:04:05"),
+			r.GetSource(),
+			r.GetUser(),
+			r.GetAction(),
+			r.GetSummary(),
+		})
+	}
+	for _, line := range formatTable(rows) {
+		log.Infof("%s", line)
+	}
+	return nil
+}
+
+// printLogStream prints the log lines received until the stream is closed.
+func printLogStream(recv func() (*appctlpb.LogLine, error)) error {
+	for {
+		line, err := recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf(stderror.GetLogsFailedErr, err)
+		}
+		if jsonOutput {
+			if err := printJSON(line); err != nil {
+				return err
+			}
+			continue
+		}
+		log.Infof("%s", line.GetText())
+	}
+}
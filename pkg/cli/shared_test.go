@@ -0,0 +1,154 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMetricsArgs(t *testing.T) {
+	testCases := []struct {
+		args       []string
+		groups     []string
+		names      []string
+		listGroups bool
+		history    int64
+		wantErr    bool
+	}{
+		{args: []string{"mieru", "get", "metrics"}},
+		{args: []string{"mieru", "get", "metrics", "--group", "socks5", "--group", "UDP"}, groups: []string{"socks5", "UDP"}},
+		{args: []string{"mieru", "get", "metrics", "--group", "cipher", "--name", "Error"}, groups: []string{"cipher"}, names: []string{"Error"}},
+		{args: []string{"mieru", "get", "metrics", "--list-groups", "--group", "underlay"}, groups: []string{"underlay"}, listGroups: true},
+		{args: []string{"mieru", "get", "metrics", "--history", "1h"}, history: 3600},
+		{args: []string{"mieru", "get", "metrics", "--group"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--name"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--history"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--history", "abc"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--history", "-1h"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--history", "1h", "--history", "2h"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--history", "1h", "--group", "socks5"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--history", "1h", "--list-groups"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--list-groups", "--name", "Error"}, wantErr: true},
+		{args: []string{"mieru", "get", "metrics", "--unknown"}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		args, err := parseMetricsArgs(tc.args)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseMetricsArgs(%v) succeeded, want error", tc.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseMetricsArgs(%v) failed: %v", tc.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(args.groups, tc.groups) || !reflect.DeepEqual(args.names, tc.names) || args.listGroups != tc.listGroups {
+			t.Errorf("parseMetricsArgs(%v) = groups %v, names %v, list groups %v, want %v, %v, %v", tc.args, args.groups, args.names, args.listGroups, tc.groups, tc.names, tc.listGroups)
+		}
+		if got := args.history.GetDurationSeconds(); got != tc.history {
+			t.Errorf("parseMetricsArgs(%v) history = %d seconds, want %d", tc.args, got, tc.history)
+		}
+	}
+}
+
+func TestContainsAnyFold(t *testing.T) {
+	testCases := []struct {
+		s       string
+		substrs []string
+		want    bool
+	}{
+		{"UDPUnderlay", nil, true},
+		{"UDPUnderlay", []string{"udp"}, true},
+		{"UDPUnderlay", []string{"tcp", "UNDERLAY"}, true},
+		{"UDPUnderlay", []string{"tcp"}, false},
+		{"", []string{"tcp"}, false},
+	}
+	for _, tc := range testCases {
+		if got := containsAnyFold(tc.s, tc.substrs); got != tc.want {
+			t.Errorf("containsAnyFold(%q, %v) = %v, want %v", tc.s, tc.substrs, got, tc.want)
+		}
+	}
+}
+
+func TestFilterMetrics(t *testing.T) {
+	defer func() { jsonOutput = false }()
+	raw := `{"cipher - client":{"DirectDecrypt":3,"FailedDecrypt":1},"socks5":{"HandshakeErrors":2,"UDPAssociateErrors":0},"Underlay":{"ActiveOpens":5}}`
+	testCases := []struct {
+		name       string
+		args       metricsArgs
+		jsonOutput bool
+		want       string
+	}{
+		{
+			name: "no filter",
+			want: raw,
+		},
+		{
+			name: "group",
+			args: metricsArgs{groups: []string{"SOCKS"}},
+			want: "{\n    \"socks5\": {\n        \"HandshakeErrors\": 2,\n        \"UDPAssociateErrors\": 0\n    }\n}",
+		},
+		{
+			name: "name in all groups",
+			args: metricsArgs{names: []string{"errors", "decrypt"}},
+			want: "{\n    \"cipher - client\": {\n        \"DirectDecrypt\": 3,\n        \"FailedDecrypt\": 1\n    },\n    \"socks5\": {\n        \"HandshakeErrors\": 2,\n        \"UDPAssociateErrors\": 0\n    }\n}",
+		},
+		{
+			name: "group and name",
+			args: metricsArgs{groups: []string{"cipher"}, names: []string{"failed"}},
+			want: "{\n    \"cipher - client\": {\n        \"FailedDecrypt\": 1\n    }\n}",
+		},
+		{
+			name: "no match",
+			args: metricsArgs{groups: []string{"http"}},
+			want: "{}",
+		},
+		{
+			name: "list groups",
+			args: metricsArgs{listGroups: true},
+			want: "cipher - client\nsocks5\nUnderlay",
+		},
+		{
+			name: "list matched groups",
+			args: metricsArgs{groups: []string{"C"}, listGroups: true},
+			want: "cipher - client\nsocks5",
+		},
+		{
+			name:       "list groups in JSON",
+			args:       metricsArgs{groups: []string{"socks", "under"}, listGroups: true},
+			jsonOutput: true,
+			want:       "[\n    \"socks5\",\n    \"Underlay\"\n]",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonOutput = tc.jsonOutput
+			got, err := filterMetrics(raw, tc.args)
+			if err != nil {
+				t.Fatalf("filterMetrics() failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("filterMetrics() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := filterMetrics("not json", metricsArgs{listGroups: true}); err == nil {
+		t.Errorf("filterMetrics() with invalid JSON succeeded, want error")
+	}
+}
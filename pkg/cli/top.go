@@ -0,0 +1,236 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl"
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/mathext"
+	"github.com/enfein/mieru/pkg/stderror"
+)
+
+// topMetricGroups are the metric groups displayed by the dashboard.
+var topMetricGroups = []string{"connections", "underlay", "traffic"}
+
+// topDashboard renders the session info stream with throughput computed
+// from the difference between two consecutive updates.
+type topDashboard struct {
+	prevSessions map[uint32]*appctlpb.SessionEntry
+	prevTime     time.Time
+}
+
+// runTopDashboard shows the dashboard in the alternate screen of the
+// terminal until the stream is closed or the user presses Ctrl+C.
+func runTopDashboard(recv func() (*appctlpb.SessionInfo, error), getMetrics func(context.Context) (*appctlpb.Metrics, error), cancel context.CancelFunc) error {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	interrupted := make(chan struct{})
+	go func() {
+		if _, ok := <-sigChan; ok {
+			close(interrupted)
+			cancel()
+		}
+	}()
+
+	// Switch to the alternate screen and hide the cursor.
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	d := &topDashboard{}
+	for {
+		info, err := recv()
+		select {
+		case <-interrupted:
+			return nil
+		default:
+		}
+		if err != nil {
+			return fmt.Errorf(stderror.GetConnectionsFailedErr, err)
+		}
+		timedctx, cancelFunc := context.WithTimeout(context.Background(), appctl.GetRPCTimeout(appctl.RPCTimeout))
+		metrics, err := getMetrics(timedctx)
+		cancelFunc()
+		if err != nil {
+			return fmt.Errorf(stderror.GetMetricsFailedErr, err)
+		}
+		lines := d.render(info, metrics.GetJson(), time.Now())
+		// Move the cursor to the top left corner and clear the screen.
+		fmt.Print("\033[H\033[2J")
+		for _, line := range lines {
+			log.Infof("%s", line)
+		}
+	}
+}
+
+// render returns the lines of the dashboard.
+func (d *topDashboard) render(info *appctlpb.SessionInfo, metricsJSON string, now time.Time) []string {
+	var elapsed time.Duration
+	if !d.prevTime.IsZero() {
+		elapsed = now.Sub(d.prevTime)
+	}
+	sessions := make(map[uint32]*appctlpb.SessionEntry)
+	var totalRead, totalWrite int64
+
+	sessionRows := [][]string{
+		{"Session ID", "Protocol", "Remote", "State", "RTT", "Read/s", "Write/s", "Read", "Write"},
+	}
+	for _, s := range info.GetSessions() {
+		sessions[s.GetId()] = s
+		var readRate, writeRate int64
+		if prev, ok := d.prevSessions[s.GetId()]; ok && elapsed > 0 {
+			readRate = rate(s.GetBytesRead()-prev.GetBytesRead(), elapsed)
+			writeRate = rate(s.GetBytesWritten()-prev.GetBytesWritten(), elapsed)
+		}
+		totalRead += readRate
+		totalWrite += writeRate
+		rtt := "-"
+		if s.RttMillis != nil {
+			rtt = fmt.Sprintf("%dms", s.GetRttMillis())
+		}
+		sessionRows = append(sessionRows, []string{
+			fmt.Sprintf("%d", s.GetId()),
+			s.GetProtocol(),
+			s.GetRemoteAddr(),
+			s.GetState(),
+			rtt,
+			formatBytes(readRate) + "/s",
+			formatBytes(writeRate) + "/s",
+			formatBytes(s.GetBytesRead()),
+			formatBytes(s.GetBytesWritten()),
+		})
+	}
+	d.prevSessions = sessions
+	d.prevTime = now
+
+	underlayRows := [][]string{
+		{"Protocol", "Local", "Remote", "Sessions", "Last Recv", "Health"},
+	}
+	for _, u := range info.GetUnderlays() {
+		lastRecv := "-"
+		health := "-"
+		if u.LastRecvMillis != nil {
+			lastRecv = fmt.Sprintf("%v", (time.Duration(u.GetLastRecvMillis()) * time.Millisecond).Truncate(time.Second))
+			health = "OK"
+			if u.GetKeepalivePending() {
+				health = "PROBING"
+			}
+		}
+		underlayRows = append(underlayRows, []string{
+			u.GetProtocol(),
+			u.GetLocalAddr(),
+			u.GetRemoteAddr(),
+			fmt.Sprintf("%d", u.GetSessionCount()),
+			lastRecv,
+			health,
+		})
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s  %d sessions  %d underlays  read %s/s  write %s/s",
+			now.Format(time.RFC3339), len(info.GetSessions()), len(info.GetUnderlays()), formatBytes(totalRead), formatBytes(totalWrite)),
+		"",
+	}
+	lines = append(lines, formatTable(sessionRows)...)
+	lines = append(lines, "")
+	lines = append(lines, formatTable(underlayRows)...)
+	lines = append(lines, "")
+	lines = append(lines, formatMetricGroups(metricsJSON, topMetricGroups)...)
+	lines = append(lines, "", "Press Ctrl+C to quit.")
+	return lines
+}
+
+// rate returns the number of bytes per second.
+func rate(n int64, elapsed time.Duration) int64 {
+	if n < 0 {
+		// The session ID is reused by a new session.
+		return 0
+	}
+	return int64(float64(n) / elapsed.Seconds())
+}
+
+// formatBytes returns a human readable number of bytes.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n) / unit
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TiB", value)
+}
+
+// formatTable aligns the columns of the rows. The first row is the header.
+func formatTable(rows [][]string) []string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, col := range row {
+			widths[i] = mathext.Max(widths[i], len(col))
+		}
+	}
+	res := make([]string, 0, len(rows))
+	for _, row := range rows {
+		line := make([]string, 0, len(row))
+		for i, col := range row {
+			line = append(line, fmt.Sprintf("%-*s", widths[i], col))
+		}
+		res = append(res, strings.TrimRight(strings.Join(line, "  "), " "))
+	}
+	return res
+}
+
+// formatMetricGroups returns one line for each of the selected metric groups
+// from the metrics JSON document.
+func formatMetricGroups(metricsJSON string, groups []string) []string {
+	var all map[string]map[string]int64
+	if err := json.Unmarshal([]byte(metricsJSON), &all); err != nil {
+		return []string{fmt.Sprintf("metrics are unavailable: %v", err)}
+	}
+	res := make([]string, 0, len(groups))
+	for _, group := range groups {
+		metrics, ok := all[group]
+		if !ok {
+			continue
+		}
+		names := make([]string, 0, len(metrics))
+		for name := range metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		values := make([]string, 0, len(names))
+		for _, name := range names {
+			values = append(values, fmt.Sprintf("%s=%d", name, metrics[name]))
+		}
+		res = append(res, fmt.Sprintf("%s: %s", group, strings.Join(values, " ")))
+	}
+	return res
+}
@@ -49,6 +49,10 @@ type RTTStats struct {
 
 	maxAckDelay   time.Duration
 	rtoMultiplier float64
+
+	initialRTO time.Duration // RTO before the first measurement; 0 means default
+	minRTO     time.Duration // 0 means no lower bound
+	maxRTO     time.Duration // 0 means no upper bound
 }
 
 // NewRTTStats makes a properly initialized RTTStats object
@@ -79,11 +83,26 @@ func (r *RTTStats) MaxAckDelay() time.Duration { return r.maxAckDelay }
 // RTO gets the retransmission timeout.
 func (r *RTTStats) RTO() time.Duration {
 	if r.SmoothedRTT() == 0 {
-		return 2 * defaultInitialRTT
+		if r.initialRTO > 0 {
+			return r.ClampRTO(r.initialRTO)
+		}
+		return r.ClampRTO(2 * defaultInitialRTT)
 	}
 	rto := r.SmoothedRTT() + mathext.Max(4*r.MeanDeviation(), 10*time.Millisecond)
 	rto += r.MaxAckDelay()
-	return time.Duration(float64(rto) * r.rtoMultiplier)
+	return r.ClampRTO(time.Duration(float64(rto) * r.rtoMultiplier))
+}
+
+// ClampRTO limits the retransmission timeout within the bounds
+// set by SetRTOBounds.
+func (r *RTTStats) ClampRTO(rto time.Duration) time.Duration {
+	if r.minRTO > 0 && rto < r.minRTO {
+		rto = r.minRTO
+	}
+	if r.maxRTO > 0 && rto > r.maxRTO {
+		rto = r.maxRTO
+	}
+	return rto
 }
 
 // UpdateRTT updates the RTT based on a new sample.
@@ -120,6 +139,28 @@ func (r *RTTStats) SetRTOMultiplier(n float64) {
 	r.rtoMultiplier = n
 }
 
+// SetInitialRTO sets the retransmission timeout before the first
+// measurement. A value of 0 uses the default.
+func (r *RTTStats) SetInitialRTO(rto time.Duration) {
+	if rto < 0 {
+		panic("initial retransmission timeout can't be negative")
+	}
+	r.initialRTO = rto
+}
+
+// SetRTOBounds sets the minimum and maximum retransmission timeout.
+// A value of 0 means the bound is not set.
+func (r *RTTStats) SetRTOBounds(min, max time.Duration) {
+	if min < 0 || max < 0 {
+		panic("retransmission timeout bounds can't be negative")
+	}
+	if min > 0 && max > 0 && min > max {
+		panic("minimum retransmission timeout is bigger than maximum")
+	}
+	r.minRTO = min
+	r.maxRTO = max
+}
+
 // SetInitialRTT sets the initial RTT.
 // It is used during the 0-RTT handshake when restoring the RTT stats from the session state.
 func (r *RTTStats) SetInitialRTT(t time.Duration) {
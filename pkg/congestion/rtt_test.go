@@ -71,3 +71,23 @@ func TestRTO(t *testing.T) {
 		t.Errorf("RTO() = %v, want %v", s.RTO(), 1000*time.Millisecond)
 	}
 }
+
+func TestRTOBounds(t *testing.T) {
+	s := NewRTTStats()
+	s.SetInitialRTO(3 * time.Second)
+	if s.RTO() != 3*time.Second {
+		t.Errorf("RTO() = %v, want %v", s.RTO(), 3*time.Second)
+	}
+
+	s.SetRTOBounds(400*time.Millisecond, 2*time.Second)
+	if s.RTO() != 2*time.Second {
+		t.Errorf("RTO() = %v, want %v", s.RTO(), 2*time.Second)
+	}
+	s.UpdateRTT(50 * time.Millisecond)
+	if s.RTO() != 400*time.Millisecond {
+		t.Errorf("RTO() = %v, want %v", s.RTO(), 400*time.Millisecond)
+	}
+	if got := s.ClampRTO(10 * time.Second); got != 2*time.Second {
+		t.Errorf("ClampRTO() = %v, want %v", got, 2*time.Second)
+	}
+}
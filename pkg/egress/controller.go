@@ -20,6 +20,10 @@ import "github.com/enfein/mieru/pkg/appctl/appctlpb"
 type Input struct {
 	Protocol appctlpb.ProxyProtocol
 	Data     []byte
+
+	// UserName is the name of the proxy user who sends the request.
+	// It is empty if the user is unknown.
+	UserName string
 }
 
 type Action struct {
@@ -0,0 +1,181 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package egress
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DomainMatcher matches domain names against a set of full match, suffix
+// match and keyword match rules. Full match and suffix match rules are
+// stored in a trie indexed by domain labels from right to left, so the
+// cost of a lookup only depends on the number of labels in the domain name.
+type DomainMatcher struct {
+	root     *domainTrieNode
+	keywords []string
+	size     int
+}
+
+type domainTrieNode struct {
+	children map[string]*domainTrieNode
+
+	// full is true if the domain name ending at this node is matched.
+	full bool
+
+	// suffix is true if the domain name ending at this node and all
+	// the subdomains are matched.
+	suffix bool
+}
+
+// NewDomainMatcher creates an empty DomainMatcher.
+func NewDomainMatcher() *DomainMatcher {
+	return &DomainMatcher{
+		root: &domainTrieNode{},
+	}
+}
+
+// LoadDomainMatcher creates a DomainMatcher from a domain rule list file.
+func LoadDomainMatcher(filePath string) (*DomainMatcher, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open() failed: %w", err)
+	}
+	defer f.Close()
+	m := NewDomainMatcher()
+	if err := m.AddRules(f); err != nil {
+		return nil, fmt.Errorf("failed to load domain rules from %q: %w", filePath, err)
+	}
+	return m, nil
+}
+
+// AddRules adds the rules from the reader, one rule per line.
+//
+// A rule is one of "full:<DOMAIN>", "domain:<DOMAIN>", "keyword:<KEYWORD>",
+// or "<DOMAIN>" which is the same as "domain:<DOMAIN>". Empty lines and
+// lines started with "#" are ignored. Trailing attributes separated by
+// a space or "@" are dropped.
+func (m *DomainMatcher) AddRules(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexAny(line, " \t@#"); i >= 0 {
+			line = line[:i]
+		}
+		ruleType, value, found := strings.Cut(line, ":")
+		if !found {
+			ruleType, value = "domain", line
+		}
+		if value == "" {
+			return fmt.Errorf("line %d: rule value is empty", lineNum)
+		}
+		switch ruleType {
+		case "full":
+			m.AddFull(value)
+		case "domain":
+			m.AddSuffix(value)
+		case "keyword":
+			m.AddKeyword(value)
+		default:
+			return fmt.Errorf("line %d: unsupported rule type %q", lineNum, ruleType)
+		}
+	}
+	return scanner.Err()
+}
+
+// AddFull adds a rule that matches exactly the domain name.
+func (m *DomainMatcher) AddFull(domain string) {
+	m.insert(domain).full = true
+	m.size++
+}
+
+// AddSuffix adds a rule that matches the domain name and all the subdomains.
+func (m *DomainMatcher) AddSuffix(domain string) {
+	m.insert(domain).suffix = true
+	m.size++
+}
+
+// AddKeyword adds a rule that matches domain names containing the keyword.
+func (m *DomainMatcher) AddKeyword(keyword string) {
+	m.keywords = append(m.keywords, strings.ToLower(keyword))
+	m.size++
+}
+
+// Match returns true if the domain name is matched by any rule.
+func (m *DomainMatcher) Match(domain string) bool {
+	domain = normalizeDomainName(domain)
+	if domain == "" {
+		return false
+	}
+	node := m.root
+	labels := strings.Split(domain, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		next, ok := node.children[labels[i]]
+		if !ok {
+			node = nil
+			break
+		}
+		node = next
+		if node.suffix {
+			return true
+		}
+	}
+	if node != nil && node.full {
+		return true
+	}
+	for _, keyword := range m.keywords {
+		if strings.Contains(domain, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns the number of rules in the DomainMatcher.
+func (m *DomainMatcher) Size() int {
+	return m.size
+}
+
+func (m *DomainMatcher) insert(domain string) *domainTrieNode {
+	node := m.root
+	labels := strings.Split(normalizeDomainName(domain), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		if node.children == nil {
+			node.children = make(map[string]*domainTrieNode)
+		}
+		next, ok := node.children[labels[i]]
+		if !ok {
+			next = &domainTrieNode{}
+			node.children[labels[i]] = next
+		}
+		node = next
+	}
+	return node
+}
+
+// normalizeDomainName converts the domain name to lower case and removes
+// the leading and trailing dots.
+func normalizeDomainName(domain string) string {
+	return strings.Trim(strings.ToLower(domain), ".")
+}
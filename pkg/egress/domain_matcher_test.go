@@ -0,0 +1,117 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package egress_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/egress"
+)
+
+const domainRules = `
+# Comment line.
+example.com
+domain:google.com
+full:www.github.com
+keyword:ads
+full:api.example.org @cn
+`
+
+func TestDomainMatcher(t *testing.T) {
+	m := egress.NewDomainMatcher()
+	if err := m.AddRules(strings.NewReader(domainRules)); err != nil {
+		t.Fatalf("AddRules() failed: %v", err)
+	}
+	if m.Size() != 5 {
+		t.Errorf("Size() = %d, want %d", m.Size(), 5)
+	}
+	testcases := []struct {
+		domain string
+		want   bool
+	}{
+		{"example.com", true},
+		{"www.example.com", true},
+		{"WWW.Example.COM.", true},
+		{"notexample.com", false},
+		{"google.com", true},
+		{"mail.google.com", true},
+		{"google.com.hk", false},
+		{"www.github.com", true},
+		{"github.com", false},
+		{"api.www.github.com", false},
+		{"myads.net", true},
+		{"api.example.org", true},
+		{"example.org", false},
+		{"", false},
+	}
+	for _, tc := range testcases {
+		if got := m.Match(tc.domain); got != tc.want {
+			t.Errorf("Match(%q) = %v, want %v", tc.domain, got, tc.want)
+		}
+	}
+}
+
+func TestDomainMatcherRejectInvalidRule(t *testing.T) {
+	m := egress.NewDomainMatcher()
+	if err := m.AddRules(strings.NewReader("regexp:^.*$")); err == nil {
+		t.Errorf("want error in AddRules() with unsupported rule type, got no error")
+	}
+	if err := m.AddRules(strings.NewReader("full:")); err == nil {
+		t.Errorf("want error in AddRules() with empty rule value, got no error")
+	}
+}
+
+func TestDomainRuleController(t *testing.T) {
+	dir := t.TempDir()
+	directFile := filepath.Join(dir, "direct.txt")
+	if err := os.WriteFile(directFile, []byte("google.com\n"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	rejectFile := filepath.Join(dir, "reject.txt")
+	if err := os.WriteFile(rejectFile, []byte("keyword:o\n"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() failed: %v", err)
+	}
+	controller, err := egress.NewDomainRuleController([]*appctlpb.DomainRuleList{
+		{
+			FilePath: &directFile,
+			Action:   appctlpb.EgressAction_DIRECT.Enum(),
+		},
+		{
+			FilePath: &rejectFile,
+			Action:   appctlpb.EgressAction_REJECT.Enum(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewDomainRuleController() failed: %v", err)
+	}
+	if action := controller.FindAction(inputDomainName); action.Action != appctlpb.EgressAction_DIRECT {
+		t.Errorf("got action %s for domain name input, want %s", action.Action.String(), appctlpb.EgressAction_DIRECT.String())
+	}
+	if action := controller.FindAction(inputIPv4); action.Action != appctlpb.EgressAction_PROXY {
+		t.Errorf("got action %s for IPv4 input, want %s", action.Action.String(), appctlpb.EgressAction_PROXY.String())
+	}
+	yahoo := egress.Input{
+		Protocol: appctlpb.ProxyProtocol_SOCKS5_PROXY_PROTOCOL,
+		Data:     []byte{5, 1, 0, 3, 9, 'y', 'a', 'h', 'o', 'o', '.', 'c', 'o', 'm', 1, 187},
+	}
+	if action := controller.FindAction(yahoo); action.Action != appctlpb.EgressAction_REJECT {
+		t.Errorf("got action %s for domain name input, want %s", action.Action.String(), appctlpb.EgressAction_REJECT.String())
+	}
+}
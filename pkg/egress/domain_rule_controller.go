@@ -0,0 +1,101 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package egress
+
+import (
+	"fmt"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+)
+
+type domainRule struct {
+	matcher *DomainMatcher
+	action  appctlpb.EgressAction
+}
+
+// DomainRuleController decides the action of a socks5 request at proxy
+// client side based on the destination domain name.
+// If no rule is matched, the action is PROXY.
+type DomainRuleController struct {
+	rules []domainRule
+}
+
+var (
+	_ Controller = &DomainRuleController{}
+)
+
+// NewDomainRuleController creates a DomainRuleController by loading
+// the domain rule list files.
+func NewDomainRuleController(lists []*appctlpb.DomainRuleList) (*DomainRuleController, error) {
+	c := &DomainRuleController{}
+	for _, list := range lists {
+		m, err := LoadDomainMatcher(list.GetFilePath())
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("loaded %d domain rules from %q with action %s", m.Size(), list.GetFilePath(), list.GetAction().String())
+		c.rules = append(c.rules, domainRule{
+			matcher: m,
+			action:  list.GetAction(),
+		})
+	}
+	return c, nil
+}
+
+func (c *DomainRuleController) FindAction(in Input) Action {
+	proxy := Action{
+		Action: appctlpb.EgressAction_PROXY,
+	}
+	if in.Protocol != appctlpb.ProxyProtocol_SOCKS5_PROXY_PROTOCOL {
+		log.Debugf("egress DomainRuleController: %s is not supported", in.Protocol.String())
+		return proxy
+	}
+	domain, err := socks5RequestDomainName(in.Data)
+	if err != nil {
+		log.Debugf("egress DomainRuleController: %v", err)
+		return proxy
+	}
+	if domain == "" {
+		return proxy
+	}
+	for _, rule := range c.rules {
+		if rule.matcher.Match(domain) {
+			return Action{
+				Action: rule.action,
+			}
+		}
+	}
+	return proxy
+}
+
+// socks5RequestDomainName returns the destination domain name of a socks5
+// request. It returns an empty string if the destination is an IP address.
+func socks5RequestDomainName(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", fmt.Errorf("input %v is too short", data)
+	}
+	if data[0] != 0x05 {
+		return "", fmt.Errorf("input %v is not socks5 protocol", data)
+	}
+	if data[3] != 0x03 {
+		return "", nil
+	}
+	if len(data) < 5 || len(data) < 5+int(data[4]) {
+		return "", fmt.Errorf("input %v has incomplete domain name", data)
+	}
+	return string(data[5 : 5+int(data[4])]), nil
+}
@@ -0,0 +1,237 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package egress
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+)
+
+// privateIPNets are the IP ranges of private networks. They are rejected
+// by the egress policy unless private destinations are allowed.
+var privateIPNets = mustParseCIDRs(
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+// Policy decides whether the proxy server is allowed to connect to
+// a destination. It is enforced before the destination is dialed,
+// so a leaked password can't be used to attack the internal network
+// of the server.
+type Policy struct {
+	allowPrivate  bool
+	allowLoopback bool
+	deniedIPNets  []*net.IPNet
+	deniedDomains *DomainMatcher
+	deniedPorts   [][2]int
+	users         map[string]userPolicy
+}
+
+// userPolicy holds the destination rules of a user.
+type userPolicy struct {
+	allowedDomains *DomainMatcher
+	allowedIPNets  []*net.IPNet
+	allowedPorts   [][2]int // empty to allow all ports
+	deniedPorts    [][2]int
+}
+
+// hasDestinationAllowlist returns true if the user can only connect to
+// the allowed domain names and IP ranges.
+func (up userPolicy) hasDestinationAllowlist() bool {
+	return up.allowedDomains != nil || len(up.allowedIPNets) > 0
+}
+
+// NewPolicy creates the egress policy from config. The per-user destination
+// and port rules are read from the users. If allowLoopback is false,
+// loopback and unspecified addresses are rejected.
+func NewPolicy(config *appctlpb.EgressPolicy, users []*appctlpb.User, allowLoopback bool) (*Policy, error) {
+	p := &Policy{
+		allowPrivate:  config.GetAllowPrivateDestination(),
+		allowLoopback: allowLoopback,
+		deniedDomains: NewDomainMatcher(),
+		users:         make(map[string]userPolicy),
+	}
+	for _, cidr := range config.GetDeniedIPRanges() {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("egress policy: invalid denied IP range %q", cidr)
+		}
+		p.deniedIPNets = append(p.deniedIPNets, ipNet)
+	}
+	for _, domain := range config.GetDeniedDomainNames() {
+		if domain == "" {
+			return nil, fmt.Errorf("egress policy: denied domain name is empty")
+		}
+		p.deniedDomains.AddSuffix(domain)
+	}
+	var err error
+	if p.deniedPorts, err = parsePortRanges(config.GetDeniedPorts()); err != nil {
+		return nil, fmt.Errorf("egress policy: %w", err)
+	}
+	for _, user := range users {
+		if len(user.GetAllowedPorts()) == 0 && len(user.GetDeniedPorts()) == 0 &&
+			len(user.GetAllowedDomainNames()) == 0 && len(user.GetAllowedIPRanges()) == 0 {
+			continue
+		}
+		var up userPolicy
+		for _, domain := range user.GetAllowedDomainNames() {
+			if domain == "" {
+				return nil, fmt.Errorf("user %q: allowed domain name is empty", user.GetName())
+			}
+			if up.allowedDomains == nil {
+				up.allowedDomains = NewDomainMatcher()
+			}
+			up.allowedDomains.AddSuffix(domain)
+		}
+		for _, cidr := range user.GetAllowedIPRanges() {
+			_, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("user %q: invalid allowed IP range %q", user.GetName(), cidr)
+			}
+			up.allowedIPNets = append(up.allowedIPNets, ipNet)
+		}
+		if up.allowedPorts, err = parsePortRanges(user.GetAllowedPorts()); err != nil {
+			return nil, fmt.Errorf("user %q: %w", user.GetName(), err)
+		}
+		if up.deniedPorts, err = parsePortRanges(user.GetDeniedPorts()); err != nil {
+			return nil, fmt.Errorf("user %q: %w", user.GetName(), err)
+		}
+		p.users[user.GetName()] = up
+	}
+	return p, nil
+}
+
+// HasUserRules returns true if any user has destination or port rules.
+func (p *Policy) HasUserRules() bool {
+	return p != nil && len(p.users) > 0
+}
+
+// Check returns an error if the user is not allowed to connect to the
+// destination. domain is empty if the destination is an IP address.
+// ip is the resolved IP address of the destination.
+func (p *Policy) Check(userName, domain string, ip net.IP, port int) error {
+	if p == nil {
+		return nil
+	}
+	if domain != "" && p.deniedDomains.Match(domain) {
+		return fmt.Errorf("domain name %s is denied by egress policy", domain)
+	}
+	if ip != nil {
+		if !p.allowLoopback && (ip.IsLoopback() || ip.IsUnspecified()) {
+			return fmt.Errorf("loopback address %v is denied by egress policy", ip)
+		}
+		if !p.allowPrivate && containsIP(privateIPNets, ip) {
+			return fmt.Errorf("private address %v is denied by egress policy", ip)
+		}
+		if containsIP(p.deniedIPNets, ip) {
+			return fmt.Errorf("IP address %v is denied by egress policy", ip)
+		}
+	}
+	if containsPort(p.deniedPorts, port) {
+		return fmt.Errorf("port %d is denied by egress policy", port)
+	}
+	if up, ok := p.users[userName]; ok {
+		if up.hasDestinationAllowlist() {
+			domainAllowed := domain != "" && up.allowedDomains != nil && up.allowedDomains.Match(domain)
+			ipAllowed := ip != nil && containsIP(up.allowedIPNets, ip)
+			if !domainAllowed && !ipAllowed {
+				if domain != "" {
+					return fmt.Errorf("domain name %s is not allowed for user %q", domain, userName)
+				}
+				return fmt.Errorf("IP address %v is not allowed for user %q", ip, userName)
+			}
+		}
+		if len(up.allowedPorts) > 0 && !containsPort(up.allowedPorts, port) {
+			return fmt.Errorf("port %d is not allowed for user %q", port, userName)
+		}
+		if containsPort(up.deniedPorts, port) {
+			return fmt.Errorf("port %d is denied for user %q", port, userName)
+		}
+	}
+	return nil
+}
+
+// ParsePortRange parses a port number like "22" or a port range
+// like "8000-8100".
+func ParsePortRange(s string) ([2]int, error) {
+	begin, end, isRange := strings.Cut(s, "-")
+	small, err := strconv.Atoi(begin)
+	if err != nil {
+		return [2]int{}, fmt.Errorf("unable to parse port %q", s)
+	}
+	big := small
+	if isRange {
+		big, err = strconv.Atoi(end)
+		if err != nil {
+			return [2]int{}, fmt.Errorf("unable to parse port range %q", s)
+		}
+	}
+	if small < 1 || small > 65535 || big < 1 || big > 65535 || small > big {
+		return [2]int{}, fmt.Errorf("port range %q is invalid", s)
+	}
+	return [2]int{small, big}, nil
+}
+
+func parsePortRanges(ports []string) ([][2]int, error) {
+	var ranges [][2]int
+	for _, port := range ports {
+		r, err := ParsePortRange(port)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func containsPort(ranges [][2]int, port int) bool {
+	for _, r := range ranges {
+		if port >= r[0] && port <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIP(ipNets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range ipNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	ipNets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		ipNets = append(ipNets, ipNet)
+	}
+	return ipNets
+}
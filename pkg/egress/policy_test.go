@@ -0,0 +1,198 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package egress_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/egress"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPolicy(t *testing.T) {
+	p, err := egress.NewPolicy(&appctlpb.EgressPolicy{
+		DeniedIPRanges:    []string{"203.0.113.0/24"},
+		DeniedDomainNames: []string{"internal.example.com"},
+		DeniedPorts:       []string{"25"},
+	}, []*appctlpb.User{
+		{
+			Name:         proto.String("web"),
+			AllowedPorts: []string{"80", "443"},
+		},
+		{
+			Name:        proto.String("nossh"),
+			DeniedPorts: []string{"22"},
+		},
+	}, false)
+	if err != nil {
+		t.Fatalf("NewPolicy() failed: %v", err)
+	}
+
+	testCases := []struct {
+		user   string
+		domain string
+		ip     string
+		port   int
+		allow  bool
+	}{
+		{"", "www.example.com", "198.51.100.1", 443, true},
+		{"", "", "127.0.0.1", 80, false},
+		{"", "", "::1", 80, false},
+		{"", "", "0.0.0.0", 80, false},
+		{"", "", "10.1.2.3", 80, false},
+		{"", "", "192.168.1.1", 80, false},
+		{"", "", "169.254.169.254", 80, false},
+		{"", "", "fd00::1", 80, false},
+		{"", "", "203.0.113.5", 80, false},
+		{"", "db.internal.example.com", "198.51.100.1", 5432, false},
+		{"", "", "198.51.100.1", 25, false},
+		{"web", "", "198.51.100.1", 443, true},
+		{"web", "", "198.51.100.1", 22, false},
+		{"nossh", "", "198.51.100.1", 22, false},
+		{"nossh", "", "198.51.100.1", 8080, true},
+		{"other", "", "198.51.100.1", 22, true},
+	}
+	for _, tc := range testCases {
+		err := p.Check(tc.user, tc.domain, net.ParseIP(tc.ip), tc.port)
+		if tc.allow && err != nil {
+			t.Errorf("Check(%q, %q, %s, %d) = %v, want nil", tc.user, tc.domain, tc.ip, tc.port, err)
+		}
+		if !tc.allow && err == nil {
+			t.Errorf("Check(%q, %q, %s, %d) = nil, want error", tc.user, tc.domain, tc.ip, tc.port)
+		}
+	}
+}
+
+func TestPolicyUserAllowlist(t *testing.T) {
+	p, err := egress.NewPolicy(nil, []*appctlpb.User{
+		{
+			Name:               proto.String("family"),
+			AllowedDomainNames: []string{"mail.example.com", "wikipedia.org"},
+			AllowedIPRanges:    []string{"198.51.100.0/24"},
+			AllowedPorts:       []string{"443", "993"},
+		},
+	}, false)
+	if err != nil {
+		t.Fatalf("NewPolicy() failed: %v", err)
+	}
+
+	testCases := []struct {
+		domain string
+		ip     string
+		port   int
+		allow  bool
+	}{
+		{"mail.example.com", "203.0.113.1", 993, true},
+		{"en.wikipedia.org", "203.0.113.2", 443, true},
+		{"www.example.com", "203.0.113.3", 443, false},
+		{"", "198.51.100.7", 443, true},
+		{"", "203.0.113.4", 443, false},
+		{"cdn.example.net", "198.51.100.8", 443, true},
+		{"mail.example.com", "203.0.113.1", 25, false},
+	}
+	for _, tc := range testCases {
+		err := p.Check("family", tc.domain, net.ParseIP(tc.ip), tc.port)
+		if tc.allow && err != nil {
+			t.Errorf("Check(%q, %s, %d) = %v, want nil", tc.domain, tc.ip, tc.port, err)
+		}
+		if !tc.allow && err == nil {
+			t.Errorf("Check(%q, %s, %d) = nil, want error", tc.domain, tc.ip, tc.port)
+		}
+	}
+	if err := p.Check("other", "www.example.com", net.ParseIP("203.0.113.3"), 80); err != nil {
+		t.Errorf("Check() for user without allowlist = %v, want nil", err)
+	}
+}
+
+func TestPolicyAllowLocal(t *testing.T) {
+	p, err := egress.NewPolicy(&appctlpb.EgressPolicy{
+		AllowPrivateDestination: proto.Bool(true),
+	}, nil, true)
+	if err != nil {
+		t.Fatalf("NewPolicy() failed: %v", err)
+	}
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "fe80::1"} {
+		if err := p.Check("", "", net.ParseIP(ip), 80); err != nil {
+			t.Errorf("Check(%s) = %v, want nil", ip, err)
+		}
+	}
+}
+
+func TestPolicyNil(t *testing.T) {
+	var p *egress.Policy
+	if err := p.Check("", "", net.ParseIP("127.0.0.1"), 22); err != nil {
+		t.Errorf("Check() on nil policy = %v, want nil", err)
+	}
+	if p.HasUserRules() {
+		t.Errorf("HasUserRules() on nil policy = true, want false")
+	}
+}
+
+func TestPolicyHasUserRules(t *testing.T) {
+	p, err := egress.NewPolicy(nil, []*appctlpb.User{{Name: proto.String("u")}}, false)
+	if err != nil {
+		t.Fatalf("NewPolicy() failed: %v", err)
+	}
+	if p.HasUserRules() {
+		t.Errorf("HasUserRules() = true for user without rules, want false")
+	}
+	p, err = egress.NewPolicy(nil, []*appctlpb.User{{Name: proto.String("u"), DeniedPorts: []string{"22"}}}, false)
+	if err != nil {
+		t.Fatalf("NewPolicy() failed: %v", err)
+	}
+	if !p.HasUserRules() {
+		t.Errorf("HasUserRules() = false for user with rules, want true")
+	}
+}
+
+func TestNewPolicyInvalid(t *testing.T) {
+	testCases := []struct {
+		config *appctlpb.EgressPolicy
+		users  []*appctlpb.User
+	}{
+		{config: &appctlpb.EgressPolicy{DeniedIPRanges: []string{"10.0.0.0"}}},
+		{config: &appctlpb.EgressPolicy{DeniedDomainNames: []string{""}}},
+		{config: &appctlpb.EgressPolicy{DeniedPorts: []string{"0"}}},
+		{users: []*appctlpb.User{{Name: proto.String("u"), AllowedPorts: []string{"9000-8000"}}}},
+		{users: []*appctlpb.User{{Name: proto.String("u"), DeniedPorts: []string{"abc"}}}},
+		{users: []*appctlpb.User{{Name: proto.String("u"), AllowedDomainNames: []string{""}}}},
+		{users: []*appctlpb.User{{Name: proto.String("u"), AllowedIPRanges: []string{"198.51.100.0"}}}},
+	}
+	for i, tc := range testCases {
+		if _, err := egress.NewPolicy(tc.config, tc.users, false); err == nil {
+			t.Errorf("test case %d: NewPolicy() succeeded, want error", i)
+		}
+	}
+}
+
+func TestParsePortRange(t *testing.T) {
+	r, err := egress.ParsePortRange("8000-8100")
+	if err != nil {
+		t.Fatalf("ParsePortRange() failed: %v", err)
+	}
+	if r != [2]int{8000, 8100} {
+		t.Errorf("ParsePortRange() = %v, want [8000 8100]", r)
+	}
+	r, err = egress.ParsePortRange("22")
+	if err != nil {
+		t.Fatalf("ParsePortRange() failed: %v", err)
+	}
+	if r != [2]int{22, 22} {
+		t.Errorf("ParsePortRange() = %v, want [22 22]", r)
+	}
+}
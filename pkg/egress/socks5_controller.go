@@ -60,14 +60,23 @@ func (c *Socks5Controller) FindAction(in Input) Action {
 	} else if in.Data[1] == 0x01 {
 		isIP := in.Data[3] == 0x01 || in.Data[3] == 0x04
 		isDomainName := in.Data[3] == 0x03
-		for _, rule := range c.config.GetRules() {
-			if (isIP && len(rule.GetIpRanges()) > 0 && rule.GetIpRanges()[0] == "*") || (isDomainName && len(rule.GetDomainNames()) > 0 && rule.GetDomainNames()[0] == "*") {
-				for _, proxy := range c.config.GetProxies() {
-					if proxy.GetName() == rule.GetProxyName() {
-						return Action{
-							Action: rule.GetAction(),
-							Proxy:  proxy,
-						}
+		rule := c.findRule(in.UserName)
+		if rule == nil {
+			return Action{
+				Action: appctlpb.EgressAction_DIRECT,
+			}
+		}
+		if rule.GetAction() == appctlpb.EgressAction_DIRECT {
+			return Action{
+				Action: appctlpb.EgressAction_DIRECT,
+			}
+		}
+		if (isIP && len(rule.GetIpRanges()) > 0 && rule.GetIpRanges()[0] == "*") || (isDomainName && len(rule.GetDomainNames()) > 0 && rule.GetDomainNames()[0] == "*") {
+			for _, proxy := range c.config.GetProxies() {
+				if proxy.GetName() == rule.GetProxyName() {
+					return Action{
+						Action: rule.GetAction(),
+						Proxy:  proxy,
 					}
 				}
 			}
@@ -81,3 +90,27 @@ func (c *Socks5Controller) FindAction(in Input) Action {
 		}
 	}
 }
+
+// findRule returns the rule of the user. If no rule has the user name,
+// the rule without user names is returned. It returns nil if no rule
+// is found.
+func (c *Socks5Controller) findRule(userName string) *appctlpb.EgressRule {
+	var defaultRule *appctlpb.EgressRule
+	for _, rule := range c.config.GetRules() {
+		if len(rule.GetUserNames()) == 0 {
+			if defaultRule == nil {
+				defaultRule = rule
+			}
+			continue
+		}
+		if userName == "" {
+			continue
+		}
+		for _, name := range rule.GetUserNames() {
+			if name == userName {
+				return rule
+			}
+		}
+	}
+	return defaultRule
+}
@@ -107,3 +107,61 @@ func TestRule(t *testing.T) {
 		}
 	}
 }
+
+func TestUserRule(t *testing.T) {
+	controller := egress.NewSocks5Controller(&appctlpb.Egress{
+		Proxies: []*appctlpb.EgressProxy{
+			{
+				Name:     proto.String("default"),
+				Protocol: appctlpb.ProxyProtocol_SOCKS5_PROXY_PROTOCOL.Enum(),
+				Host:     proto.String("127.0.0.1"),
+				Port:     proto.Int32(6789),
+			},
+			{
+				Name:     proto.String("relay"),
+				Protocol: appctlpb.ProxyProtocol_MIERU_PROXY_PROTOCOL.Enum(),
+				Host:     proto.String("127.0.0.1"),
+				Port:     proto.Int32(6790),
+			},
+		},
+		Rules: []*appctlpb.EgressRule{
+			{
+				IpRanges:    []string{"*"},
+				DomainNames: []string{"*"},
+				Action:      appctlpb.EgressAction_PROXY.Enum(),
+				ProxyName:   proto.String("default"),
+			},
+			{
+				IpRanges:    []string{"*"},
+				DomainNames: []string{"*"},
+				Action:      appctlpb.EgressAction_PROXY.Enum(),
+				ProxyName:   proto.String("relay"),
+				UserNames:   []string{"alice"},
+			},
+			{
+				IpRanges:    []string{"*"},
+				DomainNames: []string{"*"},
+				Action:      appctlpb.EgressAction_DIRECT.Enum(),
+				UserNames:   []string{"bob"},
+			},
+		},
+	})
+	testCases := []struct {
+		user      string
+		action    appctlpb.EgressAction
+		proxyName string
+	}{
+		{"", appctlpb.EgressAction_PROXY, "default"},
+		{"carol", appctlpb.EgressAction_PROXY, "default"},
+		{"alice", appctlpb.EgressAction_PROXY, "relay"},
+		{"bob", appctlpb.EgressAction_DIRECT, ""},
+	}
+	for _, tc := range testCases {
+		input := inputDomainName
+		input.UserName = tc.user
+		action := controller.FindAction(input)
+		if action.Action != tc.action || action.Proxy.GetName() != tc.proxyName {
+			t.Errorf("FindAction() for user %q = %s %q, want %s %q", tc.user, action.Action, action.Proxy.GetName(), tc.action, tc.proxyName)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package event notifies operators about important events of the daemon,
+// such as daemon start and stop, by sending them to a webhook.
+package event
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/metrics"
+)
+
+// Type is the type of an event.
+type Type string
+
+const (
+	// The proxy is started.
+	DaemonStart Type = "daemon_start"
+
+	// The proxy is stopped.
+	DaemonStop Type = "daemon_stop"
+
+	// The client switches to another endpoint because the current one
+	// doesn't work.
+	EndpointFailover Type = "endpoint_failover"
+
+	// A user used all the traffic quota.
+	QuotaExceeded Type = "quota_exceeded"
+
+	// A possible replay attack is detected.
+	ReplayDetected Type = "replay_detected"
+)
+
+// Types is the list of all event types.
+var Types = []Type{DaemonStart, DaemonStop, EndpointFailover, QuotaExceeded, ReplayDetected}
+
+// IsValidType returns true if the event type is known.
+func IsValidType(t string) bool {
+	for _, known := range Types {
+		if string(known) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupInterval is the minimum time between two events with the same
+// type and key. It prevents flooding the webhook.
+const dedupInterval = 10 * time.Minute
+
+// Event is sent to the webhook as a JSON object.
+type Event struct {
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+
+	// Source is the name of the program, "mieru" or "mita".
+	Source string `json:"source"`
+
+	// Host is the host name of the machine that runs the program.
+	Host string `json:"host"`
+
+	// Text is a human readable summary of the event. Chat services
+	// that accept {"text": "..."} can show it without a converter.
+	Text string `json:"text"`
+
+	// Attributes has more details of the event.
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+var (
+	webhookRef atomic.Pointer[webhook]
+	webhookMu  sync.Mutex
+
+	// lastSent records the last time an event is sent for each
+	// type and key.
+	lastSent   = make(map[string]time.Time)
+	lastSentMu sync.Mutex
+
+	hostName, _ = os.Hostname()
+)
+
+// Emit sends an event to the webhook. It doesn't block.
+// If key is not empty, events with the same type and key are
+// sent at most once in every 10 minutes.
+// It does nothing if the webhook is not enabled.
+func Emit(t Type, key string, attrs map[string]string, format string, args ...any) {
+	w := webhookRef.Load()
+	if w == nil || !w.accept(t) {
+		return
+	}
+	now := time.Now()
+	if key != "" {
+		id := string(t) + "/" + key
+		lastSentMu.Lock()
+		if last, found := lastSent[id]; found && now.Sub(last) < dedupInterval {
+			lastSentMu.Unlock()
+			return
+		}
+		lastSent[id] = now
+		lastSentMu.Unlock()
+	}
+	w.enqueue(Event{
+		Type:       t,
+		Time:       now,
+		Source:     w.config.Source,
+		Host:       hostName,
+		Text:       fmt.Sprintf("[%s] %s", w.config.Source, fmt.Sprintf(format, args...)),
+		Attributes: attrs,
+	})
+}
+
+// EnableWebhook starts sending events to the webhook. If the webhook is
+// already enabled, the previous one is stopped.
+func EnableWebhook(config WebhookConfig) error {
+	w, err := newWebhook(config)
+	if err != nil {
+		return err
+	}
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+	if old := webhookRef.Swap(w); old != nil {
+		old.stop()
+	}
+	go w.loop()
+	metrics.GetMetricGroupByName(WebhookMetricGroupName).EnableLogging()
+	log.Infof("enabled sending events to webhook %s", w.url)
+	return nil
+}
+
+// DisableWebhook stops sending events. Events not yet sent are flushed.
+func DisableWebhook() {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+	if old := webhookRef.Swap(nil); old != nil {
+		old.stop()
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package event
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestEmitToWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("got method %q, want %q", r.Method, http.MethodPost)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("got content type %q, want %q", got, "application/json")
+		}
+		b, _ := io.ReadAll(r.Body)
+		var e Event
+		if err := json.Unmarshal(b, &e); err != nil {
+			t.Errorf("json.Unmarshal() failed: %v", err)
+		}
+		mu.Lock()
+		received = append(received, e)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	if err := EnableWebhook(WebhookConfig{
+		URL:    server.URL,
+		Source: "mita",
+		Types:  []Type{DaemonStart, QuotaExceeded},
+	}); err != nil {
+		t.Fatalf("EnableWebhook() failed: %v", err)
+	}
+	Emit(DaemonStart, "", nil, "started")
+	Emit(DaemonStop, "", nil, "stopped")
+	Emit(QuotaExceeded, "alice", map[string]string{"user": "alice"}, "user %s used all the quota", "alice")
+	Emit(QuotaExceeded, "alice", map[string]string{"user": "alice"}, "user %s used all the quota", "alice")
+	DisableWebhook()
+
+	// Events are not sent after webhook is disabled.
+	Emit(DaemonStart, "", nil, "started")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 {
+		t.Fatalf("got %d events, want 2", len(received))
+	}
+	if received[0].Type != DaemonStart || received[0].Source != "mita" || received[0].Text != "[mita] started" {
+		t.Errorf("got unexpected event %+v", received[0])
+	}
+	if received[1].Type != QuotaExceeded || received[1].Attributes["user"] != "alice" {
+		t.Errorf("got unexpected event %+v", received[1])
+	}
+}
+
+func TestEnableWebhookInvalidConfig(t *testing.T) {
+	cases := []WebhookConfig{
+		{URL: "ftp://example.com/hook"},
+		{URL: "https:///hook"},
+		{URL: "https://example.com/hook", Types: []Type{"unknown"}},
+	}
+	for _, c := range cases {
+		if err := EnableWebhook(c); err == nil {
+			t.Errorf("EnableWebhook(%+v) returned no error", c)
+			DisableWebhook()
+		}
+	}
+}
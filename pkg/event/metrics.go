@@ -0,0 +1,38 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package event
+
+import "github.com/enfein/mieru/pkg/metrics"
+
+const (
+	WebhookMetricGroupName = "webhook"
+)
+
+var (
+	// Number of events sent to the webhook.
+	SentEvents = metrics.RegisterMetric(WebhookMetricGroupName, "SentEvents", metrics.COUNTER)
+
+	// Number of events dropped because the queue is full.
+	DroppedEvents = metrics.RegisterMetric(WebhookMetricGroupName, "DroppedEvents", metrics.COUNTER)
+
+	// Number of failed webhook requests.
+	SendErrors = metrics.RegisterMetric(WebhookMetricGroupName, "SendErrors", metrics.COUNTER)
+)
+
+func init() {
+	// Webhook metrics are shown after webhook is enabled.
+	metrics.GetMetricGroupByName(WebhookMetricGroupName).DisableLogging()
+}
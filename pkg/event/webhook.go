@@ -0,0 +1,188 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+)
+
+const (
+	// webhookQueueCapacity is the maximum number of events waiting to be
+	// sent. New events are dropped if the queue is full.
+	webhookQueueCapacity = 256
+
+	// webhookTimeout is the timeout of a single webhook request.
+	webhookTimeout = 10 * time.Second
+
+	// webhookRetryDelay is the time to wait before sending a failed
+	// event again. An event is sent at most twice.
+	webhookRetryDelay = 5 * time.Second
+
+	// webhookFlushTimeout is the maximum time to send the remaining
+	// events when the webhook is stopped.
+	webhookFlushTimeout = 5 * time.Second
+)
+
+// WebhookConfig is the configuration to send events to a webhook.
+type WebhookConfig struct {
+	// URL is the HTTP or HTTPS URL that receives POST requests.
+	URL string
+
+	// Source is the name of the program, "mieru" or "mita".
+	Source string
+
+	// Types are the types of events to send.
+	// If it is empty, all types of events are sent.
+	Types []Type
+}
+
+// webhook sends events to a HTTP endpoint one by one.
+type webhook struct {
+	config WebhookConfig
+	url    string
+	types  map[Type]bool
+	client *http.Client
+	queue  chan Event
+	done   chan struct{}
+	exited chan struct{}
+}
+
+func newWebhook(config WebhookConfig) (*webhook, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL %q: %w", config.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("webhook URL %q must use http or https scheme", config.URL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("webhook URL %q has no host", config.URL)
+	}
+	types := make(map[Type]bool)
+	for _, t := range config.Types {
+		if !IsValidType(string(t)) {
+			return nil, fmt.Errorf("unknown event type %q", t)
+		}
+		types[t] = true
+	}
+	return &webhook{
+		config: config,
+		url:    u.Redacted(),
+		types:  types,
+		client: &http.Client{Timeout: webhookTimeout},
+		queue:  make(chan Event, webhookQueueCapacity),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}, nil
+}
+
+// accept returns true if the type of event should be sent.
+func (w *webhook) accept(t Type) bool {
+	return len(w.types) == 0 || w.types[t]
+}
+
+func (w *webhook) enqueue(e Event) {
+	select {
+	case w.queue <- e:
+	default:
+		DroppedEvents.Add(1)
+	}
+}
+
+// stop sends the remaining events and stops the webhook.
+func (w *webhook) stop() {
+	close(w.done)
+	<-w.exited
+}
+
+func (w *webhook) loop() {
+	defer close(w.exited)
+	for {
+		select {
+		case e := <-w.queue:
+			if err := w.send(e); err != nil {
+				log.Debugf("send event %s to webhook %s failed: %v, retry in %v", e.Type, w.url, err, webhookRetryDelay)
+				select {
+				case <-time.After(webhookRetryDelay):
+					w.sendAndCount(e)
+				case <-w.done:
+					w.sendAndCount(e)
+					w.flush()
+					return
+				}
+			} else {
+				SentEvents.Add(1)
+			}
+		case <-w.done:
+			w.flush()
+			return
+		}
+	}
+}
+
+// flush sends the events in the queue until the queue is empty
+// or the flush timeout is reached.
+func (w *webhook) flush() {
+	deadline := time.Now().Add(webhookFlushTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case e := <-w.queue:
+			w.sendAndCount(e)
+		default:
+			return
+		}
+	}
+}
+
+func (w *webhook) sendAndCount(e Event) {
+	if err := w.send(e); err != nil {
+		SendErrors.Add(1)
+		log.Debugf("send event %s to webhook %s failed: %v", e.Type, w.url, err)
+		return
+	}
+	SentEvents.Add(1)
+}
+
+func (w *webhook) send(e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned HTTP status %s", resp.Status)
+	}
+	return nil
+}
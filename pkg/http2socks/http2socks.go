@@ -16,8 +16,11 @@
 package http2socks
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -55,8 +58,14 @@ var hopByHopHeaders = []string{
 }
 
 type Proxy struct {
+	// ProxyURI is the URI of the socks5 server that HTTP requests are
+	// forwarded to. It is not used if DialContext is set.
 	ProxyURI string
 
+	// DialContext connects to the destination directly, without going
+	// through a socks5 server.
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+
 	client *http.Client // cached HTTP client
 	mu     sync.Mutex
 }
@@ -79,15 +88,31 @@ func NewHTTPServer(listenAddr string, proxy *Proxy) *http.Server {
 	}
 }
 
-// ServeHTTP implements http.Handler interface with a socks5 backend.
+// NewHTTPSServer returns a new HTTP proxy server that serves over TLS
+// with the given certificate. HTTP/2 is disabled because the CONNECT method
+// requires hijacking the underlying connection.
+func NewHTTPSServer(listenAddr string, proxy *Proxy, cert tls.Certificate) *http.Server {
+	server := NewHTTPServer(listenAddr, proxy)
+	if server == nil {
+		return nil
+	}
+	server.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	return server
+}
+
+// ServeHTTP implements http.Handler interface with a socks5 backend,
+// or the DialContext function if it is set.
 func (p *Proxy) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	HTTPRequests.Add(1)
 	if log.IsLevelEnabled(log.TraceLevel) {
 		log.Tracef("received HTTP proxy request %s %s", req.Method, req.URL.String())
 	}
 
-	// Dialer to socks5 server.
-	dialFunc := socks5client.Dial(p.ProxyURI, socks5client.ConnectCmd)
+	dialFunc := p.dialContextFunc()
 
 	if req.Method == http.MethodConnect {
 		// HTTPS
@@ -104,6 +129,8 @@ func (p *Proxy) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 			log.Debugf("hijack HTTP connection failed: %v", err)
 			return
 		}
+		// Deadlines set by the HTTP server must not apply to the tunnel.
+		httpConn.SetDeadline(time.Time{})
 
 		// Determine the destination port number.
 		port := req.URL.Port()
@@ -121,11 +148,14 @@ func (p *Proxy) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 			}
 		}
 
-		// Dial to socks server.
-		socksConn, err := dialFunc("tcp", util.MaybeDecorateIPv6(req.URL.Hostname())+":"+port)
+		// Dial to the destination.
+		ctx, cancelFunc := context.WithTimeout(req.Context(), clientTimeout)
+		socksConn, err := dialFunc(ctx, "tcp", util.MaybeDecorateIPv6(req.URL.Hostname())+":"+port)
+		cancelFunc()
 		if err != nil {
 			HTTPConnErrors.Add(1)
-			log.Debugf("HTTP proxy dial to socks5 server failed: %v", err)
+			httpConn.Close()
+			log.Debugf("HTTP proxy dial to %s failed: %v", req.URL.Redacted(), err)
 			return
 		}
 		httpConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
@@ -135,7 +165,7 @@ func (p *Proxy) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 		p.mu.Lock()
 		if p.client == nil {
 			tr := &http.Transport{
-				Dial: dialFunc,
+				DialContext: dialFunc,
 			}
 			p.client = &http.Client{
 				Transport: tr,
@@ -167,6 +197,18 @@ func (p *Proxy) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// dialContextFunc returns the function to connect to the destination.
+func (p *Proxy) dialContextFunc() func(ctx context.Context, network, address string) (net.Conn, error) {
+	if p.DialContext != nil {
+		return p.DialContext
+	}
+	// Dialer to socks5 server.
+	dialFunc := socks5client.Dial(p.ProxyURI, socks5client.ConnectCmd)
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialFunc(network, address)
+	}
+}
+
 // TransportProxyFunc returns the Proxy function used by http.Transport.
 func TransportProxyFunc(proxy string) func(*http.Request) (*url.URL, error) {
 	if !strings.HasPrefix(proxy, "http://") && !strings.HasPrefix(proxy, "https://") && !strings.HasPrefix(proxy, "socks5://") {
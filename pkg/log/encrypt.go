@@ -0,0 +1,160 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// LogEncryptionKeyEnv is the environment variable that holds the
+	// passphrase to encrypt and decrypt client log files.
+	LogEncryptionKeyEnv = "MIERU_LOG_ENCRYPTION_KEY"
+
+	// encryptedLogMagic is written at the beginning of an encrypted log file.
+	encryptedLogMagic = "MIERULOG\x01"
+
+	encryptedLogSaltSize = 16
+	encryptedLogKeyIter  = 4096
+
+	// maxEncryptedLogRecord is the maximum size of an encrypted record.
+	maxEncryptedLogRecord = 1 << 20
+)
+
+// encryptedWriter encrypts each Write() call as a separate AES-GCM record.
+// A record has the format of
+// [4-byte big endian length][12-byte nonce][ciphertext with tag].
+type encryptedWriter struct {
+	w    io.WriteCloser
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+var _ io.WriteCloser = &encryptedWriter{}
+
+// NewEncryptedWriter returns a writer that encrypts the data with the
+// key derived from the passphrase before writing to w.
+func NewEncryptedWriter(w io.WriteCloser, passphrase []byte) (io.WriteCloser, error) {
+	salt := make([]byte, encryptedLogSaltSize)
+	if _, err := crand.Read(salt); err != nil {
+		return nil, fmt.Errorf("rand.Read() failed: %w", err)
+	}
+	aead, err := newLogAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	header := append([]byte(encryptedLogMagic), salt...)
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("write encrypted log header failed: %w", err)
+	}
+	return &encryptedWriter{w: w, aead: aead}, nil
+}
+
+// Write implements io.Writer interface.
+func (e *encryptedWriter) Write(p []byte) (int, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := crand.Read(nonce); err != nil {
+		return 0, fmt.Errorf("rand.Read() failed: %w", err)
+	}
+	record := make([]byte, 4, 4+len(nonce)+len(p)+e.aead.Overhead())
+	record = append(record, nonce...)
+	record = e.aead.Seal(record, nonce, p, nil)
+	binary.BigEndian.PutUint32(record[:4], uint32(len(record)-4))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := e.w.Write(record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close implements io.Closer interface.
+func (e *encryptedWriter) Close() error {
+	return e.w.Close()
+}
+
+// DecryptLog reads an encrypted log from src and writes the plaintext to dst.
+func DecryptLog(dst io.Writer, src io.Reader, passphrase []byte) error {
+	r := bufio.NewReader(src)
+	header := make([]byte, len(encryptedLogMagic)+encryptedLogSaltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("read encrypted log header failed: %w", err)
+	}
+	if string(header[:len(encryptedLogMagic)]) != encryptedLogMagic {
+		return fmt.Errorf("not an encrypted mieru log file")
+	}
+	aead, err := newLogAEAD(passphrase, header[len(encryptedLogMagic):])
+	if err != nil {
+		return err
+	}
+	lenBuf := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, lenBuf); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read record length failed: %w", err)
+		}
+		n := binary.BigEndian.Uint32(lenBuf)
+		if n < uint32(aead.NonceSize()+aead.Overhead()) || n > maxEncryptedLogRecord {
+			return fmt.Errorf("invalid record length %d", n)
+		}
+		record := make([]byte, n)
+		if _, err := io.ReadFull(r, record); err != nil {
+			// The last record may be incomplete if the process is killed.
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return fmt.Errorf("read record failed: %w", err)
+		}
+		plaintext, err := aead.Open(nil, record[:aead.NonceSize()], record[aead.NonceSize():], nil)
+		if err != nil {
+			return fmt.Errorf("decrypt record failed, the key may be wrong: %w", err)
+		}
+		if _, err := dst.Write(plaintext); err != nil {
+			return err
+		}
+	}
+}
+
+// newLogAEAD creates the AES-GCM cipher from the passphrase and salt.
+func newLogAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("log encryption key is empty")
+	}
+	key := pbkdf2.Key(passphrase, salt, encryptedLogKeyIter, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher() failed: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cipher.NewGCM() failed: %w", err)
+	}
+	return aead, nil
+}
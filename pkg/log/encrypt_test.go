@@ -0,0 +1,68 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestEncryptedWriter(t *testing.T) {
+	var encrypted bytes.Buffer
+	w, err := NewEncryptedWriter(nopWriteCloser{&encrypted}, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("NewEncryptedWriter() failed: %v", err)
+	}
+	lines := []string{"first line\n", "second line\n"}
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	if bytes.Contains(encrypted.Bytes(), []byte("line")) {
+		t.Fatalf("encrypted log contains plaintext")
+	}
+
+	var decrypted bytes.Buffer
+	if err := DecryptLog(&decrypted, bytes.NewReader(encrypted.Bytes()), []byte("passphrase")); err != nil {
+		t.Fatalf("DecryptLog() failed: %v", err)
+	}
+	if decrypted.String() != strings.Join(lines, "") {
+		t.Errorf("DecryptLog() = %q, want %q", decrypted.String(), strings.Join(lines, ""))
+	}
+
+	// An incomplete last record is ignored.
+	decrypted.Reset()
+	truncated := encrypted.Bytes()[:encrypted.Len()-1]
+	if err := DecryptLog(&decrypted, bytes.NewReader(truncated), []byte("passphrase")); err != nil {
+		t.Fatalf("DecryptLog() failed with truncated log: %v", err)
+	}
+	if decrypted.String() != lines[0] {
+		t.Errorf("DecryptLog() = %q, want %q", decrypted.String(), lines[0])
+	}
+
+	if err := DecryptLog(io.Discard, bytes.NewReader(encrypted.Bytes()), []byte("wrong")); err == nil {
+		t.Errorf("DecryptLog() succeeded with a wrong key, want error")
+	}
+}
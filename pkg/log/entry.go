@@ -228,7 +228,7 @@ func (entry *Entry) log(level Level, msg string) {
 	}
 
 	newEntry.Level = level
-	newEntry.Message = msg
+	newEntry.Message = redactSecrets(msg)
 
 	newEntry.Logger.mu.Lock()
 	reportCaller := newEntry.Logger.ReportCaller
@@ -248,6 +248,7 @@ func (entry *Entry) log(level Level, msg string) {
 	buffer.Reset()
 	newEntry.Buffer = buffer
 
+	newEntry.fireHooks()
 	newEntry.write()
 
 	newEntry.Buffer = nil
@@ -267,6 +268,21 @@ func (entry *Entry) getBufferPool() (pool BufferPool) {
 	return bufferPool
 }
 
+func (entry *Entry) fireHooks() {
+	var tmpHooks LevelHooks
+	entry.Logger.mu.Lock()
+	tmpHooks = make(LevelHooks, len(entry.Logger.Hooks))
+	for k, v := range entry.Logger.Hooks {
+		tmpHooks[k] = v
+	}
+	entry.Logger.mu.Unlock()
+
+	err := tmpHooks.Fire(entry.Level, entry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+	}
+}
+
 func (entry *Entry) write() {
 	entry.Logger.mu.Lock()
 	defer entry.Logger.mu.Unlock()
@@ -278,6 +294,9 @@ func (entry *Entry) write() {
 	if _, err := entry.Logger.Out.Write(serialized); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
 	}
+	if entry.Logger == std {
+		recordRecent(entry, serialized)
+	}
 }
 
 func (entry *Entry) Logf(level Level, format string, args ...interface{}) {
@@ -26,6 +26,16 @@ func SetFormatter(formatter Formatter) {
 	std.SetFormatter(formatter)
 }
 
+// AddHook adds a hook to the standard logger hooks.
+func AddHook(hook Hook) {
+	std.AddHook(hook)
+}
+
+// RemoveHook removes a hook from the standard logger hooks.
+func RemoveHook(hook Hook) {
+	std.RemoveHook(hook)
+}
+
 // SetReportCaller sets whether the standard logger will include the calling
 // method as a field.
 func SetReportCaller(include bool) {
@@ -28,6 +28,11 @@ type Logger struct {
 	// formatters for examples.
 	Formatter Formatter
 
+	// Hooks for the logger instance. These allow firing events based on logging
+	// levels and log entries. For example, to send log entries to a remote
+	// collector.
+	Hooks LevelHooks
+
 	// Flag for whether to log caller info (off by default)
 	ReportCaller bool
 
@@ -78,6 +83,7 @@ func New() *Logger {
 	return &Logger{
 		Out:          os.Stderr,
 		Formatter:    new(CliFormatter),
+		Hooks:        make(LevelHooks),
 		Level:        InfoLevel,
 		ExitFunc:     os.Exit,
 		ReportCaller: false,
@@ -289,3 +295,17 @@ func (logger *Logger) SetBufferPool(pool BufferPool) {
 	defer logger.mu.Unlock()
 	logger.BufferPool = pool
 }
+
+// AddHook adds a hook to the logger hooks.
+func (logger *Logger) AddHook(hook Hook) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.Hooks.Add(hook)
+}
+
+// RemoveHook removes a hook from the logger hooks.
+func (logger *Logger) RemoveHook(hook Hook) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.Hooks.Remove(hook)
+}
@@ -25,8 +25,13 @@ import (
 	"time"
 )
 
-// maxClientLogFiles is the maximum number of client log files stored in the disk.
-const maxClientLogFiles = 25
+const (
+	// maxClientLogFiles is the maximum number of client log files stored in the disk.
+	maxClientLogFiles = 25
+
+	// encryptedLogFileSuffix is appended to the name of encrypted log files.
+	encryptedLogFileSuffix = ".enc"
+)
 
 // cachedClientLogDir is the directory where client log files are stored.
 //
@@ -45,6 +50,10 @@ func init() {
 }
 
 // NewClientLogFile returns a file handler for mieru client to write logs.
+// If the environment variable MIERU_LOG_ENCRYPTION_KEY is set, the log file
+// is encrypted with a key derived from the value. The log file is rotated
+// by size, which can be changed by MIERU_LOG_MAX_SIZE_MB and
+// MIERU_LOG_MAX_BACKUPS environment variables.
 func NewClientLogFile() (io.WriteCloser, error) {
 	if err := prepareClientLogDir(); err != nil {
 		return nil, fmt.Errorf("prepareClientLogDir() failed: %w", err)
@@ -53,9 +62,32 @@ func NewClientLogFile() (io.WriteCloser, error) {
 	timeStr := fmt.Sprintf("%04d%02d%02d_%02d%02d", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute())
 	pid := strconv.Itoa(os.Getpid())
 	fileName := cachedClientLogDir + string(os.PathSeparator) + timeStr + "_" + pid + ".log"
-	logFile, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	var passphrase []byte
+	if key, encrypted := os.LookupEnv(LogEncryptionKeyEnv); encrypted {
+		fileName += encryptedLogFileSuffix
+		passphrase = []byte(key)
+	}
+	maxSize, maxBackups := RotationFromEnv(ClientLogMaxSizeEnv, ClientLogMaxBackupsEnv)
+	logFile, err := NewRotatingFile(fileName, maxSize, maxBackups, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client log file: %w", err)
+	}
+	return logFile, nil
+}
+
+// NewServerLogFile returns a file handler for mita server to write logs,
+// if the environment variable MITA_LOG_FILE is set. Otherwise, it returns
+// nil. The log file is rotated by size, which can be changed by
+// MITA_LOG_MAX_SIZE_MB and MITA_LOG_MAX_BACKUPS environment variables.
+func NewServerLogFile() (io.WriteCloser, error) {
+	fileName, found := os.LookupEnv(ServerLogFileEnv)
+	if !found || fileName == "" {
+		return nil, nil
+	}
+	maxSize, maxBackups := RotationFromEnv(ServerLogMaxSizeEnv, ServerLogMaxBackupsEnv)
+	logFile, err := NewRotatingFile(fileName, maxSize, maxBackups, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create client log file: %v", logFile)
+		return nil, fmt.Errorf("failed to create server log file: %w", err)
 	}
 	return logFile, nil
 }
@@ -71,7 +103,7 @@ func RemoveOldClientLogFiles() error {
 	}
 	var logFiles []string
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".log") {
+		if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".log") || strings.HasSuffix(entry.Name(), ".log"+encryptedLogFileSuffix)) {
 			logFiles = append(logFiles, entry.Name())
 		}
 	}
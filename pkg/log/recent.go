@@ -0,0 +1,113 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRecentLines is the number of log lines kept in memory.
+const maxRecentLines = 1000
+
+// recentSubscriberCapacity is the number of log lines a subscriber
+// can fall behind. Newer lines are dropped for a slow subscriber.
+const recentSubscriberCapacity = 256
+
+// RecentLine is a log line kept in memory.
+type RecentLine struct {
+	Time  time.Time
+	Level Level
+	Text  string // formatted log line without the trailing new line
+}
+
+// recentLog stores the most recent log lines in a ring buffer,
+// and delivers new log lines to the subscribers.
+type recentLog struct {
+	mu          sync.Mutex
+	lines       []RecentLine
+	next        int // index of the next line to overwrite if the ring is full
+	subscribers map[chan RecentLine]struct{}
+}
+
+var recent = &recentLog{
+	lines:       make([]RecentLine, 0, maxRecentLines),
+	subscribers: make(map[chan RecentLine]struct{}),
+}
+
+func (r *recentLog) add(line RecentLine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.lines) < maxRecentLines {
+		r.lines = append(r.lines, line)
+	} else {
+		r.lines[r.next] = line
+		r.next = (r.next + 1) % maxRecentLines
+	}
+	for ch := range r.subscribers {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+}
+
+// snapshot returns the stored lines from the oldest to the newest.
+// Caller must hold the lock.
+func (r *recentLog) snapshot() []RecentLine {
+	res := make([]RecentLine, 0, len(r.lines))
+	res = append(res, r.lines[r.next:]...)
+	res = append(res, r.lines[:r.next]...)
+	return res
+}
+
+// recordRecent saves a formatted log entry.
+func recordRecent(entry *Entry, serialized []byte) {
+	recent.add(RecentLine{
+		Time:  entry.Time,
+		Level: entry.Level,
+		Text:  strings.TrimRight(string(serialized), "\n"),
+	})
+}
+
+// RecentLines returns the log lines kept in memory,
+// from the oldest to the newest.
+func RecentLines() []RecentLine {
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+	return recent.snapshot()
+}
+
+// SubscribeRecentLines returns the log lines kept in memory like RecentLines,
+// and a channel that receives the log lines written after that.
+// The returned function must be called to stop the subscription.
+func SubscribeRecentLines() ([]RecentLine, <-chan RecentLine, func()) {
+	ch := make(chan RecentLine, recentSubscriberCapacity)
+	recent.mu.Lock()
+	lines := recent.snapshot()
+	recent.subscribers[ch] = struct{}{}
+	recent.mu.Unlock()
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			recent.mu.Lock()
+			delete(recent.subscribers, ch)
+			recent.mu.Unlock()
+		})
+	}
+	return lines, ch, cancel
+}
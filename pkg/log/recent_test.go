@@ -0,0 +1,79 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecentLinesRing(t *testing.T) {
+	r := &recentLog{
+		lines:       make([]RecentLine, 0, maxRecentLines),
+		subscribers: make(map[chan RecentLine]struct{}),
+	}
+	for i := 0; i < maxRecentLines+10; i++ {
+		r.add(RecentLine{Level: InfoLevel, Text: fmt.Sprintf("%d", i)})
+	}
+	lines := r.snapshot()
+	if len(lines) != maxRecentLines {
+		t.Fatalf("got %d lines, want %d", len(lines), maxRecentLines)
+	}
+	if lines[0].Text != "10" {
+		t.Errorf("oldest line is %q, want %q", lines[0].Text, "10")
+	}
+	if want := fmt.Sprintf("%d", maxRecentLines+9); lines[len(lines)-1].Text != want {
+		t.Errorf("newest line is %q, want %q", lines[len(lines)-1].Text, want)
+	}
+}
+
+func TestSubscribeRecentLines(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+
+	Infof("before subscription")
+	lines, ch, cancel := SubscribeRecentLines()
+	defer cancel()
+	if len(lines) == 0 || !strings.Contains(lines[len(lines)-1].Text, "before subscription") {
+		t.Fatalf("recent lines don't contain the log written before subscription")
+	}
+
+	Warnf("after subscription")
+	select {
+	case line := <-ch:
+		if line.Level != WarnLevel {
+			t.Errorf("got level %v, want %v", line.Level, WarnLevel)
+		}
+		if !strings.Contains(line.Text, "after subscription") {
+			t.Errorf("got text %q, want the log written after subscription", line.Text)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("log line is not delivered to the subscriber")
+	}
+
+	cancel()
+	Infof("after cancel")
+	select {
+	case line := <-ch:
+		t.Errorf("got %q after subscription is canceled", line.Text)
+	default:
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RedactedSecret replaces a secret in log messages.
+const RedactedSecret = "[REDACTED]"
+
+// minSecretLength is the minimum length of a secret that is redacted.
+// Shorter values would hide common words in log messages.
+const minSecretLength = 4
+
+var (
+	secretsMu sync.Mutex
+
+	// secretGroups maps the owner of secrets to the secret values.
+	secretGroups = make(map[string][]string)
+
+	// secretReplacer is nil if no secret is registered.
+	secretReplacer atomic.Pointer[strings.Replacer]
+)
+
+// SetSecrets sets the secrets of a group, such as passwords loaded from
+// a config file. The secrets previously set in the same group are
+// replaced. Secrets are never written in log messages.
+func SetSecrets(group string, secrets []string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	if len(secrets) == 0 {
+		delete(secretGroups, group)
+	} else {
+		secretGroups[group] = append([]string(nil), secrets...)
+	}
+
+	unique := make(map[string]struct{})
+	for _, g := range secretGroups {
+		for _, s := range g {
+			if len(s) >= minSecretLength {
+				unique[s] = struct{}{}
+			}
+		}
+	}
+	if len(unique) == 0 {
+		secretReplacer.Store(nil)
+		return
+	}
+	all := make([]string, 0, len(unique))
+	for s := range unique {
+		all = append(all, s)
+	}
+	// Prefer the longer secret if one secret contains another.
+	sort.Slice(all, func(i, j int) bool {
+		if len(all[i]) != len(all[j]) {
+			return len(all[i]) > len(all[j])
+		}
+		return all[i] < all[j]
+	})
+	oldnew := make([]string, 0, 2*len(all))
+	for _, s := range all {
+		oldnew = append(oldnew, s, RedactedSecret)
+	}
+	secretReplacer.Store(strings.NewReplacer(oldnew...))
+}
+
+// redactSecrets replaces the secrets in the log message.
+func redactSecrets(msg string) string {
+	r := secretReplacer.Load()
+	if r == nil {
+		return msg
+	}
+	return r.Replace(msg)
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+
+	SetSecrets("test", []string{"secret", "secret-longer", "abc"})
+	defer SetSecrets("test", nil)
+
+	Infof("password is secret-longer, another password is secret, %s is too short", "abc")
+	out := buf.String()
+	if strings.Contains(out, "secret") {
+		t.Errorf("log output %q contains a secret", out)
+	}
+	if got := strings.Count(out, RedactedSecret); got != 2 {
+		t.Errorf("got %d redacted secrets in %q, want 2", got, out)
+	}
+	if !strings.Contains(out, "abc is too short") {
+		t.Errorf("log output %q doesn't contain the short value", out)
+	}
+
+	SetSecrets("test", nil)
+	buf.Reset()
+	Infof("password is secret")
+	if !strings.Contains(buf.String(), "password is secret") {
+		t.Errorf("log output %q is redacted after the secrets are removed", buf.String())
+	}
+}
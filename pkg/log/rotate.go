@@ -0,0 +1,224 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxLogFileSize is the default size of a log file that
+	// triggers rotation.
+	DefaultMaxLogFileSize = 20 * 1024 * 1024
+
+	// DefaultMaxLogBackups is the default number of rotated log files
+	// to keep, in addition to the current log file.
+	DefaultMaxLogBackups = 5
+
+	// ClientLogMaxSizeEnv is the environment variable that sets the size
+	// in megabytes of a client log file that triggers rotation.
+	ClientLogMaxSizeEnv = "MIERU_LOG_MAX_SIZE_MB"
+
+	// ClientLogMaxBackupsEnv is the environment variable that sets the
+	// number of rotated files to keep for each client log file.
+	ClientLogMaxBackupsEnv = "MIERU_LOG_MAX_BACKUPS"
+
+	// ServerLogFileEnv is the environment variable that sets the path
+	// of the server log file. If it is not set, server logs are
+	// printed to stdout.
+	ServerLogFileEnv = "MITA_LOG_FILE"
+
+	// ServerLogMaxSizeEnv is the environment variable that sets the size
+	// in megabytes of the server log file that triggers rotation.
+	ServerLogMaxSizeEnv = "MITA_LOG_MAX_SIZE_MB"
+
+	// ServerLogMaxBackupsEnv is the environment variable that sets the
+	// number of rotated server log files to keep.
+	ServerLogMaxBackupsEnv = "MITA_LOG_MAX_BACKUPS"
+)
+
+// RotatingFile is a log file that is rotated when the size reaches
+// the limit. The current file is renamed from "name.log" to "name.1.log",
+// "name.1.log" is renamed to "name.2.log", and so on. The oldest file
+// is deleted. Each rename is atomic, so no log line is split into
+// two files.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	passphrase []byte // encrypt the log file if not nil
+	w          io.WriteCloser
+	size       int64
+}
+
+var _ io.WriteCloser = &RotatingFile{}
+
+// NewRotatingFile opens the log file for appending. If passphrase is not
+// nil, the content is encrypted with a key derived from the passphrase.
+// If maxSize is not positive, DefaultMaxLogFileSize is used.
+// If maxBackups is negative, DefaultMaxLogBackups is used.
+func NewRotatingFile(path string, maxSize int64, maxBackups int, passphrase []byte) (*RotatingFile, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxLogFileSize
+	}
+	if maxBackups < 0 {
+		maxBackups = DefaultMaxLogBackups
+	}
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		passphrase: passphrase,
+	}
+	if passphrase != nil {
+		// Encrypted records can't be appended to an existing file,
+		// because each file has its own header.
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			if err := r.shift(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write implements io.Writer interface.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.w == nil {
+		return 0, fmt.Errorf("log file %s is closed", r.path)
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.w.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer interface.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.w == nil {
+		return nil
+	}
+	err := r.w.Close()
+	r.w = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile(%q) failed: %w", r.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("Stat() failed: %w", err)
+	}
+	r.size = info.Size()
+	if r.passphrase == nil {
+		r.w = f
+		return nil
+	}
+	w, err := NewEncryptedWriter(f, r.passphrase)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("NewEncryptedWriter() failed: %w", err)
+	}
+	r.w = w
+	return nil
+}
+
+// rotate closes the current file, renames the files and opens a new file.
+func (r *RotatingFile) rotate() error {
+	if err := r.w.Close(); err != nil {
+		return fmt.Errorf("Close() failed: %w", err)
+	}
+	r.w = nil
+	if err := r.shift(); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// shift moves the current file to the first backup, and each backup to
+// the next one. The current file doesn't exist after the call.
+func (r *RotatingFile) shift() error {
+	if r.maxBackups == 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("os.Remove() failed: %w", err)
+		}
+		return nil
+	}
+	if err := os.Remove(rotatedLogFileName(r.path, r.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("os.Remove() failed: %w", err)
+	}
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(rotatedLogFileName(r.path, i), rotatedLogFileName(r.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("os.Rename() failed: %w", err)
+		}
+	}
+	if err := os.Rename(r.path, rotatedLogFileName(r.path, 1)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("os.Rename() failed: %w", err)
+	}
+	return nil
+}
+
+// rotatedLogFileName returns the name of the i-th rotated file.
+// The index is inserted before the ".log" extension, so the rotated
+// file has the same extension as the original file.
+func rotatedLogFileName(path string, i int) string {
+	dir, base := filepath.Split(path)
+	if idx := strings.LastIndex(base, ".log"); idx > 0 {
+		return dir + base[:idx] + "." + strconv.Itoa(i) + base[idx:]
+	}
+	return path + "." + strconv.Itoa(i)
+}
+
+// RotationFromEnv returns the maximum file size and number of backups
+// of log rotation from the environment variables. Default values are
+// returned if the environment variables are not set or invalid.
+func RotationFromEnv(maxSizeEnv, maxBackupsEnv string) (maxSize int64, maxBackups int) {
+	maxSize = DefaultMaxLogFileSize
+	maxBackups = DefaultMaxLogBackups
+	if v, found := os.LookupEnv(maxSizeEnv); found {
+		if mb, err := strconv.Atoi(v); err == nil && mb > 0 {
+			maxSize = int64(mb) * 1024 * 1024
+		}
+	}
+	if v, found := os.LookupEnv(maxBackupsEnv); found {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxBackups = n
+		}
+	}
+	return
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatedLogFileName(t *testing.T) {
+	cases := []struct {
+		path string
+		i    int
+		want string
+	}{
+		{"/var/log/mita.log", 1, "/var/log/mita.1.log"},
+		{"/tmp/20240101_1200_42.log.enc", 2, "/tmp/20240101_1200_42.2.log.enc"},
+		{"/tmp/mita", 3, "/tmp/mita.3"},
+	}
+	for _, c := range cases {
+		if got := rotatedLogFileName(c.path, c.i); got != c.want {
+			t.Errorf("rotatedLogFileName(%q, %d) = %q, want %q", c.path, c.i, got, c.want)
+		}
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	r, err := NewRotatingFile(path, 10, 2, nil)
+	if err != nil {
+		t.Fatalf("NewRotatingFile() failed: %v", err)
+	}
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"test.log":   "dddddd\n",
+		"test.1.log": "cccccc\n",
+		"test.2.log": "bbbbbb\n",
+	}
+	for name, content := range want {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile(%q) failed: %v", name, err)
+		}
+		if string(b) != content {
+			t.Errorf("content of %q is %q, want %q", name, string(b), content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test.3.log")); !os.IsNotExist(err) {
+		t.Errorf("the oldest log file is not removed")
+	}
+}
+
+func TestRotatingFileEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log.enc")
+	passphrase := []byte("rotate")
+	r, err := NewRotatingFile(path, 16, 1, passphrase)
+	if err != nil {
+		t.Fatalf("NewRotatingFile() failed: %v", err)
+	}
+	r.Write([]byte("first line\n"))
+	r.Write([]byte("second line\n"))
+	r.Close()
+
+	// Opening an existing encrypted file moves it to a backup.
+	r, err = NewRotatingFile(path, 16, 1, passphrase)
+	if err != nil {
+		t.Fatalf("NewRotatingFile() failed: %v", err)
+	}
+	r.Write([]byte("third line\n"))
+	r.Close()
+
+	for name, content := range map[string]string{
+		"test.log.enc":   "third line\n",
+		"test.1.log.enc": "second line\n",
+	} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Open(%q) failed: %v", name, err)
+		}
+		var out bytes.Buffer
+		err = DecryptLog(&out, f, passphrase)
+		f.Close()
+		if err != nil {
+			t.Fatalf("DecryptLog(%q) failed: %v", name, err)
+		}
+		if !strings.Contains(out.String(), content) || strings.Count(out.String(), "\n") != 1 {
+			t.Errorf("content of %q is %q, want %q", name, out.String(), content)
+		}
+	}
+}
+
+func TestRotationFromEnv(t *testing.T) {
+	t.Setenv("TEST_LOG_MAX_SIZE_MB", "3")
+	t.Setenv("TEST_LOG_MAX_BACKUPS", "invalid")
+	maxSize, maxBackups := RotationFromEnv("TEST_LOG_MAX_SIZE_MB", "TEST_LOG_MAX_BACKUPS")
+	if maxSize != 3*1024*1024 {
+		t.Errorf("maxSize = %d, want %d", maxSize, 3*1024*1024)
+	}
+	if maxBackups != DefaultMaxLogBackups {
+		t.Errorf("maxBackups = %d, want %d", maxBackups, DefaultMaxLogBackups)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Sampler limits how often a call site writes log messages.
+// It is used in hot paths, such as reading and writing segments,
+// so enabling debug logging under load doesn't slow down the
+// application or fill the disk.
+//
+// Each call site should use its own Sampler. A Sampler is safe
+// for concurrent use.
+type Sampler struct {
+	interval time.Duration
+
+	// next is the UNIX nano time when the next message is allowed.
+	next atomic.Int64
+
+	// suppressed is the number of messages dropped since the
+	// last message is written.
+	suppressed atomic.Int64
+}
+
+// NewSampler returns a Sampler that writes at most one message
+// in each interval.
+func NewSampler(interval time.Duration) *Sampler {
+	return &Sampler{interval: interval}
+}
+
+// Allow returns true if a message can be written now.
+func (s *Sampler) Allow() bool {
+	now := time.Now().UnixNano()
+	next := s.next.Load()
+	if now < next {
+		return false
+	}
+	return s.next.CompareAndSwap(next, now+int64(s.interval))
+}
+
+// Tracef logs a message at level Trace on the standard logger,
+// unless another message is logged by the Sampler recently.
+func (s *Sampler) Tracef(format string, args ...interface{}) {
+	s.logf(TraceLevel, format, args...)
+}
+
+// Debugf logs a message at level Debug on the standard logger,
+// unless another message is logged by the Sampler recently.
+func (s *Sampler) Debugf(format string, args ...interface{}) {
+	s.logf(DebugLevel, format, args...)
+}
+
+// Warnf logs a message at level Warn on the standard logger,
+// unless another message is logged by the Sampler recently.
+func (s *Sampler) Warnf(format string, args ...interface{}) {
+	s.logf(WarnLevel, format, args...)
+}
+
+// Suppressed returns the number of messages dropped since
+// the last message is written.
+func (s *Sampler) Suppressed() int64 {
+	return s.suppressed.Load()
+}
+
+func (s *Sampler) logf(level Level, format string, args ...interface{}) {
+	if !std.IsLevelEnabled(level) {
+		return
+	}
+	if !s.Allow() {
+		s.suppressed.Add(1)
+		return
+	}
+	if n := s.suppressed.Swap(0); n > 0 {
+		format += " [%d similar messages are suppressed]"
+		args = append(args[:len(args):len(args)], n)
+	}
+	std.Logf(level, format, args...)
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+	level := GetLevel()
+	SetLevel("DEBUG")
+	defer SetLevel(level.String())
+
+	s := NewSampler(50 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		s.Debugf("sampled message %d", i)
+	}
+	if got := strings.Count(buf.String(), "sampled message"); got != 1 {
+		t.Fatalf("got %d messages, want 1", got)
+	}
+	if got := s.Suppressed(); got != 9 {
+		t.Errorf("Suppressed() = %d, want 9", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	s.Debugf("sampled message again")
+	if !strings.Contains(buf.String(), "sampled message again [9 similar messages are suppressed]") {
+		t.Errorf("log output %q doesn't contain the number of suppressed messages", buf.String())
+	}
+	if got := s.Suppressed(); got != 0 {
+		t.Errorf("Suppressed() = %d after writing a message, want 0", got)
+	}
+
+	// Messages below the logging level are not counted.
+	s.Tracef("trace message")
+	if got := s.Suppressed(); got != 0 {
+		t.Errorf("Suppressed() = %d after a trace message, want 0", got)
+	}
+}
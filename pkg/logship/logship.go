@@ -0,0 +1,338 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package logship sends log entries to a remote collector over TLS.
+package logship
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/metrics"
+)
+
+const (
+	// DefaultQueueSize is the default maximum number of log entries
+	// waiting to be shipped.
+	DefaultQueueSize = 4096
+
+	// batchSize is the maximum number of log entries sent at once.
+	batchSize = 256
+
+	// flushInterval is the maximum time a log entry waits in the queue
+	// if the collector is reachable.
+	flushInterval = time.Second
+
+	// ioTimeout is the timeout to connect and write to the collector.
+	ioTimeout = 10 * time.Second
+
+	// minRetryDelay and maxRetryDelay bound the time to wait
+	// before connecting to the collector again after a failure.
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+
+	// stopTimeout is the maximum time to ship the remaining log entries
+	// when log shipping is disabled.
+	stopTimeout = 5 * time.Second
+)
+
+// Config is the configuration of log shipping.
+type Config struct {
+	// Address is the TCP address of the collector.
+	Address string
+
+	// TLSConfig is used to connect to the collector.
+	TLSConfig *tls.Config
+
+	// Level is the least severe level of shipped log entries.
+	Level log.Level
+
+	// QueueSize is the maximum number of log entries waiting to be shipped.
+	// If it is not positive, DefaultQueueSize is used.
+	QueueSize int
+
+	// DropOldest drops the oldest log entries in the queue if it is full.
+	// Otherwise, new log entries are dropped.
+	DropOldest bool
+
+	// Source is the name of the program, "mieru" or "mita".
+	Source string
+}
+
+// NewTLSConfig returns the TLS config to verify the collector.
+// If caFile is empty, the CA certificates of the operating system are used.
+func NewTLSConfig(serverName, caFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if caFile != "" {
+		b, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file failed: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate is found in CA file %q", caFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// record is a log entry sent to the collector as a JSON object.
+type record struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Source  string    `json:"source"`
+	Host    string    `json:"host"`
+}
+
+var (
+	current   *shipper
+	currentMu sync.Mutex
+
+	hostName, _ = os.Hostname()
+)
+
+// Enable starts shipping log entries of the standard logger.
+// If log shipping is already enabled, the previous one is stopped.
+func Enable(config Config) error {
+	if config.Address == "" {
+		return fmt.Errorf("log shipping address is empty")
+	}
+	if _, _, err := net.SplitHostPort(config.Address); err != nil {
+		return fmt.Errorf("invalid log shipping address %q: %w", config.Address, err)
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.TLSConfig == nil {
+		config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if config.TLSConfig.ServerName == "" {
+		host, _, _ := net.SplitHostPort(config.Address)
+		config.TLSConfig = config.TLSConfig.Clone()
+		config.TLSConfig.ServerName = host
+	}
+	s := &shipper{
+		config: config,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current != nil {
+		current.stop()
+	}
+	current = s
+	go s.loop()
+	log.AddHook(s)
+	metrics.GetMetricGroupByName(LogShippingMetricGroupName).EnableLogging()
+	log.Infof("enabled shipping logs to %s", config.Address)
+	return nil
+}
+
+// Disable stops shipping log entries. Log entries in the queue are
+// shipped before it returns, unless the collector is not reachable.
+func Disable() {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current != nil {
+		current.stop()
+		current = nil
+	}
+}
+
+// shipper is a log hook that puts log entries in a queue,
+// and sends them to the collector in batches.
+type shipper struct {
+	config Config
+
+	mu    sync.Mutex
+	queue []record
+	head  uint64 // number of log entries removed from the queue
+
+	notify chan struct{}
+	done   chan struct{}
+	exited chan struct{}
+
+	conn       net.Conn
+	retryDelay time.Duration
+	retryAt    time.Time
+}
+
+var _ log.Hook = &shipper{}
+
+// Levels implements log.Hook interface.
+func (s *shipper) Levels() []log.Level {
+	var levels []log.Level
+	for _, level := range log.AllLevels {
+		if level <= s.config.Level {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// Fire implements log.Hook interface. It never blocks.
+func (s *shipper) Fire(entry *log.Entry) error {
+	r := record{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Source:  s.config.Source,
+		Host:    hostName,
+	}
+	s.mu.Lock()
+	if len(s.queue) >= s.config.QueueSize {
+		DroppedLogs.Add(1)
+		if !s.config.DropOldest {
+			s.mu.Unlock()
+			return nil
+		}
+		s.queue = s.queue[1:]
+		s.head++
+	}
+	s.queue = append(s.queue, r)
+	full := len(s.queue) >= batchSize
+	s.mu.Unlock()
+	if full {
+		select {
+		case s.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// stop removes the hook, ships the remaining log entries and closes
+// the connection.
+func (s *shipper) stop() {
+	log.RemoveHook(s)
+	close(s.done)
+	<-s.exited
+}
+
+func (s *shipper) loop() {
+	defer close(s.exited)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.notify:
+		case <-s.done:
+			ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+			s.retryAt = time.Time{}
+			for ctx.Err() == nil && s.flush() {
+			}
+			cancel()
+			if s.conn != nil {
+				s.conn.Close()
+			}
+			return
+		}
+		for s.flush() {
+		}
+	}
+}
+
+// flush sends a batch of log entries. It returns true if a full batch
+// is sent and there may be more entries in the queue.
+func (s *shipper) flush() bool {
+	if time.Now().Before(s.retryAt) {
+		return false
+	}
+	s.mu.Lock()
+	n := len(s.queue)
+	if n > batchSize {
+		n = batchSize
+	}
+	batch := make([]record, n)
+	copy(batch, s.queue)
+	end := s.head + uint64(n)
+	s.mu.Unlock()
+	if n == 0 {
+		return false
+	}
+
+	if err := s.send(batch); err != nil {
+		// Keep the log entries in the queue. New log entries are dropped
+		// by the drop policy if the queue becomes full.
+		ShipErrors.Add(1)
+		if s.conn != nil {
+			s.conn.Close()
+			s.conn = nil
+		}
+		if s.retryDelay == 0 {
+			s.retryDelay = minRetryDelay
+		} else if s.retryDelay *= 2; s.retryDelay > maxRetryDelay {
+			s.retryDelay = maxRetryDelay
+		}
+		s.retryAt = time.Now().Add(s.retryDelay)
+		return false
+	}
+	s.retryDelay = 0
+
+	// Remove the shipped log entries. Some of them may have been
+	// dropped while sending.
+	s.mu.Lock()
+	if s.head < end {
+		s.queue = s.queue[end-s.head:]
+		s.head = end
+	}
+	s.mu.Unlock()
+	ShippedLogs.Add(int64(n))
+	return n == batchSize
+}
+
+// send writes the log entries to the collector, one JSON object per line.
+func (s *shipper) send(batch []record) error {
+	if s.conn == nil {
+		dialer := &tls.Dialer{
+			NetDialer: &net.Dialer{Timeout: ioTimeout},
+			Config:    s.config.TLSConfig,
+		}
+		conn, err := dialer.Dial("tcp", s.config.Address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if err := s.conn.SetWriteDeadline(time.Now().Add(ioTimeout)); err != nil {
+		return err
+	}
+	w := bufio.NewWriter(s.conn)
+	enc := json.NewEncoder(w)
+	for _, r := range batch {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
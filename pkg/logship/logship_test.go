@@ -0,0 +1,112 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logship
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/http2socks"
+	"github.com/enfein/mieru/pkg/log"
+)
+
+func TestShipLogs(t *testing.T) {
+	certPEM, keyPEM, err := http2socks.GenerateCertificate([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("GenerateCertificate() failed: %v", err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair() failed: %v", err)
+	}
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("tls.Listen() failed: %v", err)
+	}
+	defer l.Close()
+	received := make(chan record, 16)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var r record
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				t.Errorf("json.Unmarshal() failed: %v", err)
+				return
+			}
+			received <- r
+		}
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+	if err := Enable(Config{
+		Address:   l.Addr().String(),
+		TLSConfig: &tls.Config{RootCAs: pool},
+		Level:     log.WarnLevel,
+		Source:    "mita",
+	}); err != nil {
+		t.Fatalf("Enable() failed: %v", err)
+	}
+	log.Infof("this line is not shipped")
+	log.Warnf("disk is almost full")
+	Disable()
+
+	select {
+	case r := <-received:
+		if r.Message != "disk is almost full" || r.Level != "warning" || r.Source != "mita" {
+			t.Errorf("got unexpected record %+v", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no log entry is received")
+	}
+	select {
+	case r := <-received:
+		t.Errorf("got unexpected record %+v", r)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDropPolicy(t *testing.T) {
+	for _, dropOldest := range []bool{false, true} {
+		s := &shipper{config: Config{QueueSize: 2, DropOldest: dropOldest, Level: log.InfoLevel}, notify: make(chan struct{}, 1)}
+		for _, msg := range []string{"1", "2", "3"} {
+			s.Fire(&log.Entry{Message: msg, Level: log.InfoLevel})
+		}
+		want := []string{"1", "2"}
+		if dropOldest {
+			want = []string{"2", "3"}
+		}
+		if len(s.queue) != 2 || s.queue[0].Message != want[0] || s.queue[1].Message != want[1] {
+			t.Errorf("dropOldest=%v: got queue %+v, want messages %v", dropOldest, s.queue, want)
+		}
+	}
+}
+
+func TestEnableInvalidAddress(t *testing.T) {
+	if err := Enable(Config{Address: "127.0.0.1"}); err == nil {
+		t.Errorf("Enable() with address without port returned no error")
+		Disable()
+	}
+}
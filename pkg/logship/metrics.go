@@ -0,0 +1,38 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logship
+
+import "github.com/enfein/mieru/pkg/metrics"
+
+const (
+	LogShippingMetricGroupName = "log shipping"
+)
+
+var (
+	// Number of log entries sent to the collector.
+	ShippedLogs = metrics.RegisterMetric(LogShippingMetricGroupName, "ShippedLogs", metrics.COUNTER)
+
+	// Number of log entries dropped because the queue is full.
+	DroppedLogs = metrics.RegisterMetric(LogShippingMetricGroupName, "DroppedLogs", metrics.COUNTER)
+
+	// Number of failed connections or writes to the collector.
+	ShipErrors = metrics.RegisterMetric(LogShippingMetricGroupName, "ShipErrors", metrics.COUNTER)
+)
+
+func init() {
+	// Log shipping metrics are shown after log shipping is enabled.
+	metrics.GetMetricGroupByName(LogShippingMetricGroupName).DisableLogging()
+}
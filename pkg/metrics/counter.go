@@ -20,6 +20,9 @@ import (
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -117,6 +120,52 @@ func (c *Counter) DeltaBetween(t1, t2 time.Time) int64 {
 	return sum
 }
 
+// dump returns the persisted state of the counter.
+func (c *Counter) dump() *appctlpb.CounterDump {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.op++
+
+	d := &appctlpb.CounterDump{
+		Name:       proto.String(c.name),
+		TimeSeries: proto.Bool(c.timeSeries),
+		Value:      proto.Int64(c.value),
+	}
+	for _, r := range c.history {
+		d.History = append(d.History, &appctlpb.CounterRecord{
+			TimeUnixMilli: proto.Int64(r.time.UnixMilli()),
+			Delta:         proto.Int64(r.delta),
+			RollUp:        proto.Int32(int32(r.label)),
+		})
+	}
+	return d
+}
+
+// restore adds the persisted state to the counter.
+func (c *Counter) restore(d *appctlpb.CounterDump) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.op++
+
+	c.value += d.GetValue()
+	if !c.timeSeries || len(d.GetHistory()) == 0 {
+		return
+	}
+	history := make([]record, 0, len(d.GetHistory())+len(c.history))
+	for _, r := range d.GetHistory() {
+		history = append(history, record{
+			time:  time.UnixMilli(r.GetTimeUnixMilli()),
+			delta: r.GetDelta(),
+			label: rollUpLabel(r.GetRollUp()),
+		})
+	}
+	history = append(history, c.history...)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].time.Before(history[j].time)
+	})
+	c.history = history
+}
+
 func (c *Counter) addWithTime(delta int64, time time.Time) int64 {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -0,0 +1,171 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// historySampleInterval is the time between two history samples.
+	historySampleInterval = time.Minute
+
+	// historyCapacity is the maximum number of history samples.
+	// It keeps the samples of the last 24 hours.
+	historyCapacity = 24 * 60
+)
+
+// HistorySample is a summary of metrics over one sample interval.
+type HistorySample struct {
+	// Time is the end of the sample interval.
+	Time time.Time
+
+	// InBytes and OutBytes are the number of bytes transferred
+	// during the interval.
+	InBytes  int64
+	OutBytes int64
+
+	// ActiveSessions is the number of established connections
+	// at the end of the interval.
+	ActiveSessions int64
+
+	// Errors is the number of errors during the interval.
+	// It is the sum of all the counters whose name ends with "Errors".
+	Errors int64
+}
+
+// historyRing stores the most recent history samples.
+type historyRing struct {
+	samples []HistorySample
+	next    int
+	full    bool
+
+	// Counter values of the previous sample.
+	lastIn     int64
+	lastOut    int64
+	lastErrors int64
+
+	mu sync.Mutex
+}
+
+var (
+	history = newHistoryRing(historyCapacity)
+
+	startHistoryOnce sync.Once
+)
+
+func newHistoryRing(capacity int) *historyRing {
+	return &historyRing{samples: make([]HistorySample, capacity)}
+}
+
+// StartHistory starts taking history samples in the background.
+// It is safe to call this function multiple times.
+func StartHistory() {
+	startHistoryOnce.Do(func() {
+		history.rebase()
+		go historyLoop()
+	})
+}
+
+// History returns the samples taken within the given duration,
+// from the oldest to the newest. If the duration is not positive,
+// all the samples are returned.
+func History(d time.Duration) []HistorySample {
+	var since time.Time
+	if d > 0 {
+		since = time.Now().Add(-d)
+	}
+	return history.since(since)
+}
+
+// add appends a sample, and overrides the oldest sample if the ring is full.
+func (r *historyRing) add(s HistorySample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// since returns the samples taken after the given time.
+func (r *historyRing) since(t time.Time) []HistorySample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ordered []HistorySample
+	if r.full {
+		ordered = append(ordered, r.samples[r.next:]...)
+	}
+	ordered = append(ordered, r.samples[:r.next]...)
+	res := make([]HistorySample, 0, len(ordered))
+	for _, s := range ordered {
+		if s.Time.After(t) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// rebase makes the next sample count from the current counter values.
+func (r *historyRing) rebase() {
+	in, out, errors := InBytes.Load(), OutBytes.Load(), sumErrors()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastIn, r.lastOut, r.lastErrors = in, out, errors
+}
+
+// sample adds a sample with the changes since the previous sample.
+func (r *historyRing) sample(now time.Time) {
+	in, out, errors := InBytes.Load(), OutBytes.Load(), sumErrors()
+	r.mu.Lock()
+	s := HistorySample{
+		Time:           now,
+		InBytes:        in - r.lastIn,
+		OutBytes:       out - r.lastOut,
+		ActiveSessions: CurrEstablished.Load(),
+		Errors:         errors - r.lastErrors,
+	}
+	r.lastIn, r.lastOut, r.lastErrors = in, out, errors
+	r.mu.Unlock()
+	r.add(s)
+}
+
+// sumErrors returns the sum of all the error counters.
+func sumErrors() int64 {
+	var sum int64
+	metricMap.Range(func(k, v any) bool {
+		group := v.(*MetricGroup)
+		group.metrics.Range(func(k, v any) bool {
+			if c, ok := v.(*Counter); ok && strings.HasSuffix(c.name, "Errors") {
+				sum += c.Load()
+			}
+			return true
+		})
+		return true
+	})
+	return sum
+}
+
+func historyLoop() {
+	ticker := time.NewTicker(historySampleInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		history.sample(now)
+	}
+}
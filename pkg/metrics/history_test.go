@@ -0,0 +1,65 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryRing(t *testing.T) {
+	r := newHistoryRing(3)
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		r.add(HistorySample{Time: start.Add(time.Duration(i) * time.Minute), InBytes: int64(i)})
+	}
+
+	// The oldest 2 samples are overridden.
+	all := r.since(time.Time{})
+	if len(all) != 3 {
+		t.Fatalf("got %d samples, want %d", len(all), 3)
+	}
+	for i, s := range all {
+		if s.InBytes != int64(i+2) {
+			t.Errorf("sample %d has InBytes %d, want %d", i, s.InBytes, i+2)
+		}
+	}
+
+	recent := r.since(start.Add(3 * time.Minute))
+	if len(recent) != 1 || recent[0].InBytes != 4 {
+		t.Errorf("since() = %v, want only the last sample", recent)
+	}
+}
+
+func TestHistorySample(t *testing.T) {
+	r := newHistoryRing(historyCapacity)
+	r.rebase()
+	InBytes.Add(100)
+	OutBytes.Add(200)
+	handshakeErrors := RegisterMetric("TestHistorySample", "HandshakeErrors", COUNTER)
+	handshakeErrors.Add(3)
+	now := time.Now()
+	r.sample(now)
+
+	samples := r.since(time.Time{})
+	if len(samples) != 1 {
+		t.Fatalf("got %d samples, want %d", len(samples), 1)
+	}
+	s := samples[0]
+	if !s.Time.Equal(now) || s.InBytes != 100 || s.OutBytes != 200 || s.Errors != 3 {
+		t.Errorf("sample = %+v, want InBytes 100, OutBytes 200, Errors 3", s)
+	}
+}
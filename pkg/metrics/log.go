@@ -41,6 +41,7 @@ func EnableLogging() {
 	if ticker == nil {
 		ticker = time.NewTicker(logDuration)
 		go logMetricsLoop()
+		StartHistory()
 		log.Infof("enabled metrics logging with duration %v", logDuration)
 	}
 }
@@ -90,6 +91,10 @@ func logMetricsLoop() {
 	for {
 		select {
 		case <-ticker.C:
+			// Metrics logging can wait while the tunnel is busy.
+			if !WaitUntilIdle(logDuration, done) {
+				return
+			}
 			LogMetricsNow()
 		case <-done:
 			return
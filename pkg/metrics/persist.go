@@ -0,0 +1,211 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultPersistInterval is the default interval to save counters to disk.
+const DefaultPersistInterval = time.Minute
+
+var (
+	persistPath string
+	persistDone chan struct{}
+
+	// persistRestored is true after counters are loaded from disk.
+	// They are loaded at most once in the lifetime of the process,
+	// otherwise the same values are added again.
+	persistRestored bool
+
+	persistMu sync.Mutex
+)
+
+// StartPersistence loads counters from the given file, and saves counters
+// to the file periodically in the background. If the interval is not
+// positive, DefaultPersistInterval is used. If persistence is already
+// started, the previous one is stopped first.
+func StartPersistence(path string, interval time.Duration) error {
+	if path == "" {
+		return fmt.Errorf("metrics file path is empty")
+	}
+	if interval <= 0 {
+		interval = DefaultPersistInterval
+	}
+	StopPersistence()
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+	if !persistRestored {
+		if err := LoadFromFile(path); err != nil {
+			return err
+		}
+		persistRestored = true
+	}
+	persistPath = path
+	persistDone = make(chan struct{})
+	go persistLoop(path, interval, persistDone)
+	log.Infof("metrics are saved to %q every %v", path, interval)
+	return nil
+}
+
+// StopPersistence stops saving counters in the background,
+// and saves the counters for the last time.
+func StopPersistence() {
+	persistMu.Lock()
+	defer persistMu.Unlock()
+	if persistDone == nil {
+		return
+	}
+	close(persistDone)
+	persistDone = nil
+	if err := SaveToFile(persistPath); err != nil {
+		log.Errorf("save metrics to %q failed: %v", persistPath, err)
+	}
+	persistPath = ""
+}
+
+// Dump returns the current state of all the counters.
+// Gauges are not included because they don't accumulate.
+func Dump() *appctlpb.MetricsDump {
+	dump := &appctlpb.MetricsDump{
+		TimeUnixMilli: proto.Int64(time.Now().UnixMilli()),
+	}
+	metricMap.Range(func(k, v any) bool {
+		group := v.(*MetricGroup)
+		groupDump := &appctlpb.MetricGroupDump{
+			Name: proto.String(group.name),
+		}
+		group.metrics.Range(func(k, v any) bool {
+			c, ok := v.(*Counter)
+			if !ok {
+				return true
+			}
+			if counterDump := c.dump(); counterDump.GetValue() != 0 {
+				groupDump.Counters = append(groupDump.Counters, counterDump)
+			}
+			return true
+		})
+		if len(groupDump.Counters) > 0 {
+			sort.Slice(groupDump.Counters, func(i, j int) bool {
+				return groupDump.Counters[i].GetName() < groupDump.Counters[j].GetName()
+			})
+			dump.Groups = append(dump.Groups, groupDump)
+		}
+		return true
+	})
+	sort.Slice(dump.Groups, func(i, j int) bool {
+		return dump.Groups[i].GetName() < dump.Groups[j].GetName()
+	})
+	return dump
+}
+
+// Restore adds the counters in the dump to the current counters.
+// Counters that don't exist are registered.
+func Restore(dump *appctlpb.MetricsDump) {
+	for _, groupDump := range dump.GetGroups() {
+		for _, counterDump := range groupDump.GetCounters() {
+			var m Metric
+			if group := GetMetricGroupByName(groupDump.GetName()); group != nil {
+				m, _ = group.GetMetric(counterDump.GetName())
+			}
+			if m == nil {
+				metricType := COUNTER
+				if counterDump.GetTimeSeries() {
+					metricType = COUNTER_TIME_SERIES
+				}
+				m = RegisterMetric(groupDump.GetName(), counterDump.GetName(), metricType)
+			}
+			c, ok := m.(*Counter)
+			if !ok {
+				log.Warnf("metric %s in group %s is not a Counter, skip restoring it", counterDump.GetName(), groupDump.GetName())
+				continue
+			}
+			c.restore(counterDump)
+		}
+	}
+}
+
+// SaveToFile writes the current state of all the counters to the file.
+func SaveToFile(path string) error {
+	b, err := proto.Marshal(Dump())
+	if err != nil {
+		return fmt.Errorf("proto.Marshal() failed: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp() failed: %w", err)
+	}
+	tmpName := f.Name()
+	defer os.Remove(tmpName)
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("Write() to %q failed: %w", tmpName, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Close() %q failed: %w", tmpName, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("os.Rename(%q, %q) failed: %w", tmpName, path, err)
+	}
+	return nil
+}
+
+// LoadFromFile restores counters from the file.
+// It is not an error if the file doesn't exist.
+func LoadFromFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("os.ReadFile(%q) failed: %w", path, err)
+	}
+	dump := &appctlpb.MetricsDump{}
+	if err := proto.Unmarshal(b, dump); err != nil {
+		return fmt.Errorf("proto.Unmarshal() failed: %w", err)
+	}
+	Restore(dump)
+	// Restored values are not the traffic of the current sample interval.
+	history.rebase()
+	log.Infof("restored metrics saved at %v from %q", time.UnixMilli(dump.GetTimeUnixMilli()), path)
+	return nil
+}
+
+func persistLoop(path string, interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := SaveToFile(path); err != nil {
+				log.Warnf("save metrics to %q failed: %v", path, err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
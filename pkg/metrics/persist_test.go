@@ -0,0 +1,69 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoadFile(t *testing.T) {
+	groupName := "TestSaveAndLoadFile"
+	plain := RegisterMetric(groupName, "Plain", COUNTER)
+	series := RegisterMetric(groupName, "Series", COUNTER_TIME_SERIES)
+	gauge := RegisterMetric(groupName, "Gauge", GAUGE)
+	plain.Add(7)
+	series.(*Counter).addWithTime(10, time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	series.(*Counter).addWithTime(20, time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC))
+	gauge.Store(100)
+
+	path := filepath.Join(t.TempDir(), "metrics.pb")
+	if err := SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile() failed: %v", err)
+	}
+
+	// Simulate a restart: the counters are registered again from zero.
+	metricMap.Delete(groupName)
+	plain = RegisterMetric(groupName, "Plain", COUNTER)
+	plain.Add(1)
+	series = RegisterMetric(groupName, "Series", COUNTER_TIME_SERIES)
+	series.(*Counter).addWithTime(5, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	if err := LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile() failed: %v", err)
+	}
+	if got := plain.Load(); got != 8 {
+		t.Errorf("Plain counter = %d, want %d", got, 8)
+	}
+	if got := series.Load(); got != 35 {
+		t.Errorf("Series counter = %d, want %d", got, 35)
+	}
+	t1 := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if got := series.(*Counter).DeltaBetween(t1, t2); got != 25 {
+		t.Errorf("DeltaBetween() = %d, want %d", got, 25)
+	}
+	if _, found := GetMetricGroupByName(groupName).GetMetric("Gauge"); found {
+		t.Errorf("Gauge is restored, want not restored")
+	}
+}
+
+func TestLoadFileNotExist(t *testing.T) {
+	if err := LoadFromFile(filepath.Join(t.TempDir(), "metrics.pb")); err != nil {
+		t.Errorf("LoadFromFile() failed: %v", err)
+	}
+}
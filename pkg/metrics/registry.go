@@ -17,6 +17,7 @@ package metrics
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -69,3 +70,41 @@ func GetMetricsAsJSON() ([]byte, error) {
 	})
 	return list.MarshalJSON()
 }
+
+// MetricValue is the value of a metric at a point of time.
+type MetricValue struct {
+	Group string
+	Name  string
+	Type  MetricType
+	Value int64
+}
+
+// Snapshot returns the values of metrics in the groups that enable logging,
+// sorted by group name and metric name.
+func Snapshot() []MetricValue {
+	var res []MetricValue
+	metricMap.Range(func(k, v any) bool {
+		group := v.(*MetricGroup)
+		if !group.IsLoggingEnabled() {
+			return true
+		}
+		group.metrics.Range(func(k, v any) bool {
+			m := v.(Metric)
+			res = append(res, MetricValue{
+				Group: group.name,
+				Name:  m.Name(),
+				Type:  m.Type(),
+				Value: m.Load(),
+			})
+			return true
+		})
+		return true
+	})
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Group != res[j].Group {
+			return res[i].Group < res[j].Group
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// loadSampleInterval is how often the tunnel throughput is sampled.
+	loadSampleInterval = time.Second
+
+	// defaultBusyThroughput is the default throughput in bytes per second
+	// above which the tunnel is considered busy.
+	defaultBusyThroughput = 256 * 1024
+)
+
+var (
+	// Number of background tasks deferred because the tunnel is busy.
+	DeferredBackgroundTasks = RegisterMetric("scheduler", "DeferredTasks", COUNTER)
+
+	// Number of background tasks run after reaching the maximum delay
+	// while the tunnel is still busy.
+	ForcedBackgroundTasks = RegisterMetric("scheduler", "ForcedTasks", COUNTER)
+
+	// throughput is the most recent tunnel throughput in bytes per second.
+	throughput atomic.Int64
+
+	// busyThroughput is the threshold of busy tunnel in bytes per second.
+	busyThroughput atomic.Int64
+
+	startLoadSamplerOnce sync.Once
+)
+
+func init() {
+	busyThroughput.Store(defaultBusyThroughput)
+}
+
+// SetBusyThroughput sets the throughput in bytes per second above which
+// background tasks are deferred. A value not greater than 0 restores
+// the default threshold.
+func SetBusyThroughput(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		bytesPerSecond = defaultBusyThroughput
+	}
+	busyThroughput.Store(bytesPerSecond)
+}
+
+// IsBusy returns true if the tunnel throughput is above the threshold.
+func IsBusy() bool {
+	startLoadSamplerOnce.Do(func() {
+		go loadSamplerLoop()
+	})
+	return throughput.Load() > busyThroughput.Load()
+}
+
+// WaitUntilIdle blocks until the tunnel is not busy, or maxDelay has passed.
+// Non-essential background work should call this before running,
+// so it doesn't compete with interactive traffic.
+// It returns false if the done channel is closed or receives a value
+// before the background work can run.
+func WaitUntilIdle(maxDelay time.Duration, done <-chan struct{}) bool {
+	if !IsBusy() {
+		return true
+	}
+	DeferredBackgroundTasks.Add(1)
+	deadline := time.NewTimer(maxDelay)
+	defer deadline.Stop()
+	check := time.NewTicker(loadSampleInterval)
+	defer check.Stop()
+	for {
+		select {
+		case <-done:
+			return false
+		case <-deadline.C:
+			ForcedBackgroundTasks.Add(1)
+			return true
+		case <-check.C:
+			if !IsBusy() {
+				return true
+			}
+		}
+	}
+}
+
+// loadSamplerLoop samples the tunnel throughput periodically.
+func loadSamplerLoop() {
+	ticker := time.NewTicker(loadSampleInterval)
+	defer ticker.Stop()
+	lastBytes := InBytes.Load() + OutBytes.Load()
+	lastTime := time.Now()
+	for now := range ticker.C {
+		bytes := InBytes.Load() + OutBytes.Load()
+		elapsed := now.Sub(lastTime).Seconds()
+		if elapsed > 0 {
+			throughput.Store(int64(float64(bytes-lastBytes) / elapsed))
+		}
+		lastBytes = bytes
+		lastTime = now
+	}
+}
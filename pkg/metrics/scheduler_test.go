@@ -0,0 +1,57 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWaitUntilIdle(t *testing.T) {
+	defer SetBusyThroughput(0)
+
+	// Not busy: run immediately.
+	busyThroughput.Store(math.MaxInt64)
+	deferred := DeferredBackgroundTasks.Load()
+	if !WaitUntilIdle(time.Hour, nil) {
+		t.Errorf("WaitUntilIdle() = false, want true")
+	}
+	if DeferredBackgroundTasks.Load() != deferred {
+		t.Errorf("task is deferred when the tunnel is not busy")
+	}
+
+	// Always busy: run after the maximum delay.
+	busyThroughput.Store(-1)
+	forced := ForcedBackgroundTasks.Load()
+	start := time.Now()
+	if !WaitUntilIdle(100*time.Millisecond, nil) {
+		t.Errorf("WaitUntilIdle() = false, want true")
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Errorf("task is not deferred when the tunnel is busy")
+	}
+	if ForcedBackgroundTasks.Load() != forced+1 {
+		t.Errorf("ForcedTasks = %d, want %d", ForcedBackgroundTasks.Load(), forced+1)
+	}
+
+	// Cancelled while waiting.
+	done := make(chan struct{})
+	close(done)
+	if WaitUntilIdle(time.Hour, done) {
+		t.Errorf("WaitUntilIdle() = true after done is closed, want false")
+	}
+}
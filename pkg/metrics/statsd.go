@@ -0,0 +1,209 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+)
+
+const (
+	// DefaultStatsdInterval is the default interval to push metrics.
+	DefaultStatsdInterval = 10 * time.Second
+
+	// DefaultStatsdPrefix is the default prefix of metric names.
+	DefaultStatsdPrefix = "mieru"
+
+	// statsdMaxPacketSize is the maximum size of a UDP packet sent to
+	// StatsD server. It fits into a typical Ethernet frame.
+	statsdMaxPacketSize = 1432
+)
+
+// StatsdConfig is the configuration to push metrics to a StatsD server.
+type StatsdConfig struct {
+	// Address is the UDP address of StatsD server, e.g. "127.0.0.1:8125".
+	Address string
+
+	// Interval is the time between two pushes.
+	// If it is not positive, DefaultStatsdInterval is used.
+	Interval time.Duration
+
+	// Prefix is added to the name of every metric.
+	// If it is empty, DefaultStatsdPrefix is used.
+	Prefix string
+
+	// Tags are added to every metric in DogStatsD format, e.g. "env:prod".
+	// Plain StatsD servers don't support tags, so it should be empty.
+	Tags []string
+}
+
+// statsdSink pushes metrics to a StatsD server.
+type statsdSink struct {
+	config StatsdConfig
+	conn   net.Conn
+
+	// lastCounters stores the counter values of the previous push,
+	// so only the increments are sent.
+	lastCounters map[string]int64
+
+	done chan struct{}
+}
+
+var (
+	statsd   *statsdSink
+	statsdMu sync.Mutex
+)
+
+// StartStatsd starts pushing metrics to a StatsD server in the background.
+// If it is already started, the previous one is stopped.
+func StartStatsd(config StatsdConfig) error {
+	if config.Interval <= 0 {
+		config.Interval = DefaultStatsdInterval
+	}
+	if config.Prefix == "" {
+		config.Prefix = DefaultStatsdPrefix
+	}
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return fmt.Errorf("dial StatsD server %q failed: %w", config.Address, err)
+	}
+	s := &statsdSink{
+		config:       config,
+		conn:         conn,
+		lastCounters: make(map[string]int64),
+		done:         make(chan struct{}),
+	}
+
+	statsdMu.Lock()
+	defer statsdMu.Unlock()
+	if statsd != nil {
+		statsd.stop()
+	}
+	statsd = s
+	go s.loop()
+	log.Infof("enabled pushing metrics to StatsD server %s with interval %v", config.Address, config.Interval)
+	return nil
+}
+
+// StopStatsd stops pushing metrics to StatsD server.
+func StopStatsd() {
+	statsdMu.Lock()
+	defer statsdMu.Unlock()
+	if statsd != nil {
+		statsd.stop()
+		statsd = nil
+		log.Infof("disabled pushing metrics to StatsD server")
+	}
+}
+
+func (s *statsdSink) stop() {
+	close(s.done)
+	s.conn.Close()
+}
+
+func (s *statsdSink) loop() {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.push(); err != nil {
+				log.Debugf("push metrics to StatsD server failed: %v", err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// push sends the current value of gauges and the increment of counters.
+func (s *statsdSink) push() error {
+	var packet []byte
+	for _, line := range s.lines() {
+		if len(packet) > 0 && len(packet)+1+len(line) > statsdMaxPacketSize {
+			if _, err := s.conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+	if len(packet) > 0 {
+		if _, err := s.conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lines returns the StatsD lines of all the metrics with logging enabled.
+func (s *statsdSink) lines() []string {
+	var tags string
+	if len(s.config.Tags) > 0 {
+		tags = "|#" + strings.Join(s.config.Tags, ",")
+	}
+	var lines []string
+	metricMap.Range(func(k, v any) bool {
+		group := v.(*MetricGroup)
+		if !group.IsLoggingEnabled() {
+			return true
+		}
+		group.metrics.Range(func(k, v any) bool {
+			m := v.(Metric)
+			name := s.config.Prefix + "." + statsdName(group.name) + "." + statsdName(m.Name())
+			value := m.Load()
+			if m.Type() == GAUGE {
+				lines = append(lines, fmt.Sprintf("%s:%d|g%s", name, value, tags))
+				return true
+			}
+			delta := value - s.lastCounters[name]
+			s.lastCounters[name] = value
+			if delta > 0 {
+				lines = append(lines, fmt.Sprintf("%s:%d|c%s", name, delta, tags))
+			}
+			return true
+		})
+		return true
+	})
+	sort.Strings(lines)
+	return lines
+}
+
+// statsdName replaces the characters that are not allowed in
+// StatsD metric names with underscores.
+func statsdName(name string) string {
+	var sb strings.Builder
+	underscore := false
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			sb.WriteRune(r)
+			underscore = false
+		} else if !underscore {
+			sb.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.Trim(sb.String(), "_")
+}
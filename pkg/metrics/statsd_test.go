@@ -0,0 +1,96 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsdName(t *testing.T) {
+	cases := map[string]string{
+		"traffic":              "traffic",
+		"socks5 UDP associate": "socks5_UDP_associate",
+		"cipher - client":      "cipher_client",
+		"InBytes":              "InBytes",
+	}
+	for input, want := range cases {
+		if got := statsdName(input); got != want {
+			t.Errorf("statsdName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestStatsdPush(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() failed: %v", err)
+	}
+	defer server.Close()
+	conn, err := net.Dial("udp", server.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	s := &statsdSink{
+		config:       StatsdConfig{Prefix: "test", Tags: []string{"env:test"}},
+		conn:         conn,
+		lastCounters: make(map[string]int64),
+		done:         make(chan struct{}),
+	}
+	defer s.stop()
+
+	counter := RegisterMetric("statsd test", "Counter", COUNTER)
+	gauge := RegisterMetric("statsd test", "Gauge", GAUGE)
+	counter.Add(5)
+	gauge.Store(7)
+
+	read := func() string {
+		buf := make([]byte, 65536)
+		server.SetReadDeadline(time.Now().Add(time.Second))
+		var sb strings.Builder
+		for {
+			n, _, err := server.ReadFrom(buf)
+			if err != nil {
+				return sb.String()
+			}
+			sb.WriteString(string(buf[:n]))
+			sb.WriteString("\n")
+			server.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		}
+	}
+
+	if err := s.push(); err != nil {
+		t.Fatalf("push() failed: %v", err)
+	}
+	got := read()
+	for _, want := range []string{"test.statsd_test.Counter:5|c|#env:test", "test.statsd_test.Gauge:7|g|#env:test"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("pushed metrics %q don't contain %q", got, want)
+		}
+	}
+
+	// Only the increment of counter is pushed.
+	counter.Add(3)
+	if err := s.push(); err != nil {
+		t.Fatalf("push() failed: %v", err)
+	}
+	got = read()
+	if !strings.Contains(got, "test.statsd_test.Counter:3|c") {
+		t.Errorf("pushed metrics %q don't contain the counter increment", got)
+	}
+}
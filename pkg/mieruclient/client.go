@@ -0,0 +1,218 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package mieruclient lets other Go programs use mieru as a dialer,
+// without running the socks5 listener of mieru client.
+//
+//	c, err := mieruclient.New(config)
+//	if err != nil {
+//		return err
+//	}
+//	if err := c.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer c.Stop()
+//	conn, err := c.DialContext(ctx, "tcp", "example.com:443")
+package mieruclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/enfein/mieru/pkg/appctl"
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/netmon"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/socks5"
+	"github.com/enfein/mieru/pkg/util"
+	"golang.org/x/net/proxy"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	_ proxy.ContextDialer = &Client{}
+	_ proxy.Dialer        = &Client{}
+)
+
+// Client connects to destinations through mieru proxy servers
+// of the active profile.
+type Client struct {
+	mu       sync.Mutex
+	config   atomic.Pointer[appctlpb.ClientConfig]
+	resolver *util.DNSResolver
+	mux      *protocolv2.Mux
+	dialer   *socks5.Server
+	monitor  *netmon.Monitor
+	done     chan struct{}
+}
+
+// New creates a client from client config. The ports to listen to,
+// such as socks5 port and RPC port, are not used.
+func New(config *appctlpb.ClientConfig) (*Client, error) {
+	if config == nil {
+		return nil, fmt.Errorf("client config is nil")
+	}
+	if err := appctl.ValidateClientConfigPatch(config); err != nil {
+		return nil, fmt.Errorf("invalid client config: %w", err)
+	}
+	if _, err := appctl.GetActiveProfileFromConfig(config, config.GetActiveProfile()); err != nil {
+		return nil, fmt.Errorf("invalid client config: %w", err)
+	}
+	c := &Client{}
+	c.config.Store(proto.Clone(config).(*appctlpb.ClientConfig))
+	return c, nil
+}
+
+// Start resolves the proxy servers and creates the connections to them.
+// ctx is only used to resolve the domain names of proxy servers.
+func (c *Client) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mux != nil {
+		return fmt.Errorf("client is already started")
+	}
+	config := c.config.Load()
+	resolver, err := NewResolver(config)
+	if err != nil {
+		return err
+	}
+	endpoints, err := Endpoints(ctx, config, resolver)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return fmt.Errorf("active profile has no proxy server")
+	}
+	mux, err := NewMux(config, endpoints)
+	if err != nil {
+		return err
+	}
+	socks5Config, err := Socks5Config(config, mux, resolver)
+	if err != nil {
+		mux.Close()
+		return err
+	}
+	dialer, err := socks5.New(socks5Config)
+	if err != nil {
+		mux.Close()
+		return err
+	}
+	c.resolver = resolver
+	c.mux = mux
+	c.dialer = dialer
+	c.done = make(chan struct{})
+	if HasPortRotation(config) {
+		go RefreshRotatedPorts(mux, resolver, &c.config, c.done)
+	}
+	c.monitor = netmon.New()
+	c.monitor.OnChange(func(reason string) {
+		mux.ResetUnderlays(reason)
+	})
+	c.monitor.Start()
+	return nil
+}
+
+// Stop closes the connections to proxy servers. Connections returned
+// by DialContext are closed.
+func (c *Client) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mux == nil {
+		return nil
+	}
+	close(c.done)
+	c.monitor.Close()
+	c.dialer.Close()
+	err := c.mux.Close()
+	c.mux = nil
+	c.dialer = nil
+	return err
+}
+
+// IsRunning returns true if the client is started and not stopped.
+func (c *Client) IsRunning() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mux != nil
+}
+
+// UpdateConfig changes the proxy servers of a running client. The user
+// and other settings of the previous config are not changed.
+func (c *Client) UpdateConfig(ctx context.Context, config *appctlpb.ClientConfig) error {
+	if _, err := appctl.GetActiveProfileFromConfig(config, config.GetActiveProfile()); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mux == nil {
+		return fmt.Errorf("client is not running")
+	}
+	endpoints, err := Endpoints(ctx, config, c.resolver)
+	if err != nil {
+		return err
+	}
+	c.config.Store(proto.Clone(config).(*appctlpb.ClientConfig))
+	c.mux.SetEndpoints(endpoints)
+	return nil
+}
+
+// NotifyNetworkChange closes the connections to proxy servers, so new
+// connections are created with the current network. Programs that
+// receive network change events from the operating system, such as
+// mobile apps, call it when the network is changed. Connections
+// returned by DialContext are closed.
+func (c *Client) NotifyNetworkChange() {
+	c.mu.Lock()
+	mux := c.mux
+	c.mu.Unlock()
+	if mux != nil {
+		mux.ResetUnderlays("network change is notified")
+	}
+}
+
+// DialContext connects to the address through the proxy. The address
+// is either "host:port" or "ip:port". Domain rule lists of client config
+// are applied. Only TCP network is supported.
+func (c *Client) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	c.mu.Lock()
+	dialer := c.dialer
+	c.mu.Unlock()
+	if dialer == nil {
+		return nil, fmt.Errorf("client is not running")
+	}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// ListenPacket creates a UDP association through the proxy. The returned
+// PacketConn sends UDP packets to any destination via the proxy server.
+// Domain rule lists are not applied.
+func (c *Client) ListenPacket(ctx context.Context) (net.PacketConn, error) {
+	c.mu.Lock()
+	dialer := c.dialer
+	c.mu.Unlock()
+	if dialer == nil {
+		return nil, fmt.Errorf("client is not running")
+	}
+	return dialer.ListenPacket(ctx)
+}
+
+// Dial connects to the address through the proxy.
+// It is the same as DialContext with a background context.
+func (c *Client) Dial(network, address string) (net.Conn, error) {
+	return c.DialContext(context.Background(), network, address)
+}
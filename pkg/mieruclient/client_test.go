@@ -0,0 +1,182 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieruclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/socks5"
+	"github.com/enfein/mieru/pkg/util"
+	"golang.org/x/net/proxy"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Errorf("New() with nil config returned no error")
+	}
+	config := testClientConfig(1)
+	config.ActiveProfile = proto.String("unknown")
+	if _, err := New(config); err == nil {
+		t.Errorf("New() without active profile returned no error")
+	}
+}
+
+func TestClientDialContext(t *testing.T) {
+	log.SetOutputToTest(t)
+	port, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	serverMux := protocolv2.NewMux(false).
+		SetServerUsers(map[string]*appctlpb.User{
+			"xiaochitang": {
+				Name:     proto.String("xiaochitang"),
+				Password: proto.String("kuiranbudong"),
+			},
+		}).
+		SetEndpoints([]protocolv2.UnderlayProperties{
+			protocolv2.NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil),
+		})
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer serverMux.Close()
+	time.Sleep(100 * time.Millisecond)
+	proxyServer, err := socks5.New(&socks5.Config{
+		AllowLocalDestination:    true,
+		ClientSideAuthentication: true,
+		HandshakeTimeout:         10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("socks5.New() failed: %v", err)
+	}
+	go proxyServer.Serve(serverMux)
+	defer proxyServer.Close()
+
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	c, err := New(testClientConfig(port))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.DialContext(ctx, "tcp", echo.Addr().String()); err == nil {
+		t.Errorf("DialContext() before Start() returned no error")
+	}
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	if !c.IsRunning() {
+		t.Errorf("IsRunning() = false after Start()")
+	}
+	conn, err := c.DialContext(ctx, "tcp", echo.Addr().String())
+	if err != nil {
+		t.Fatalf("DialContext() failed: %v", err)
+	}
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		t.Fatalf("io.ReadFull() failed: %v", err)
+	}
+	if string(b) != "ping" {
+		t.Errorf("got %q, want %q", b, "ping")
+	}
+	conn.Close()
+
+	// Use the client as a dialer of golang.org/x/net/proxy package.
+	var dialer proxy.Dialer = c
+	conn, err = dialer.Dial("tcp", echo.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	conn.Close()
+	var contextDialer proxy.ContextDialer = c
+	conn, err = contextDialer.DialContext(ctx, "tcp", echo.Addr().String())
+	if err != nil {
+		t.Fatalf("proxy.ContextDialer DialContext() failed: %v", err)
+	}
+	conn.Close()
+
+	// New connections are created after the network is changed.
+	c.NotifyNetworkChange()
+	conn, err = c.DialContext(ctx, "tcp", echo.Addr().String())
+	if err != nil {
+		t.Fatalf("DialContext() after NotifyNetworkChange() failed: %v", err)
+	}
+	conn.Close()
+
+	if err := c.Stop(); err != nil {
+		t.Errorf("Stop() failed: %v", err)
+	}
+	if c.IsRunning() {
+		t.Errorf("IsRunning() = true after Stop()")
+	}
+	if _, err := c.DialContext(ctx, "tcp", echo.Addr().String()); err == nil {
+		t.Errorf("DialContext() after Stop() returned no error")
+	}
+}
+
+func testClientConfig(port int) *appctlpb.ClientConfig {
+	return &appctlpb.ClientConfig{
+		Profiles: []*appctlpb.ClientProfile{
+			{
+				ProfileName: proto.String("default"),
+				User: &appctlpb.User{
+					Name:     proto.String("xiaochitang"),
+					Password: proto.String("kuiranbudong"),
+				},
+				Servers: []*appctlpb.ServerEndpoint{
+					{
+						IpAddress: proto.String("127.0.0.1"),
+						PortBindings: []*appctlpb.PortBinding{
+							{
+								Port:     proto.Int32(int32(port)),
+								Protocol: appctlpb.TransportProtocol_TCP.Enum(),
+							},
+						},
+					},
+				},
+			},
+		},
+		ActiveProfile: proto.String("default"),
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieruclient
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl"
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/egress"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/socks5"
+	"github.com/enfein/mieru/pkg/stderror"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+// defaultDNSLeakProtectionUpstream is the DNS server used by
+// DNS leak protection if the upstream is not set.
+const defaultDNSLeakProtectionUpstream = "1.1.1.1:53"
+
+// DNSLeakProtectionUpstream returns the DNS server that answers the
+// queries received by DNS leak protection.
+func DNSLeakProtectionUpstream(config *appctlpb.ClientConfig) string {
+	if upstream := config.GetDnsLeakProtection().GetUpstream(); upstream != "" {
+		return upstream
+	}
+	return defaultDNSLeakProtectionUpstream
+}
+
+// NewResolver returns the DNS resolver used by mieru client.
+func NewResolver(config *appctlpb.ClientConfig) (*util.DNSResolver, error) {
+	resolver := &util.DNSResolver{Cache: util.NewDNSCache()}
+	for _, u := range config.GetDnsUpstreams() {
+		upstream, err := util.NewDNSUpstream(u)
+		if err != nil {
+			return nil, fmt.Errorf(stderror.InvalidDNSUpstreamErr, err)
+		}
+		resolver.Upstreams = append(resolver.Upstreams, upstream)
+	}
+	return resolver, nil
+}
+
+// NewMux returns a client mux that connects to the endpoints
+// with the user of the active profile.
+func NewMux(config *appctlpb.ClientConfig, endpoints []protocolv2.UnderlayProperties) (*protocolv2.Mux, error) {
+	if err := protocolv2.SetRetransmissionConfig(appctl.RetransmissionConfig(config.GetAdvancedSettings().GetRetransmission())); err != nil {
+		return nil, fmt.Errorf("SetRetransmissionConfig() failed: %w", err)
+	}
+	if err := protocolv2.SetFlowControlConfig(appctl.FlowControlConfig(config.GetAdvancedSettings().GetFlowControl())); err != nil {
+		return nil, fmt.Errorf("SetFlowControlConfig() failed: %w", err)
+	}
+	if err := protocolv2.SetDeadPeerTimeout(appctl.DeadPeerTimeout(config.GetAdvancedSettings().GetDeadPeerTimeoutSeconds())); err != nil {
+		return nil, fmt.Errorf("SetDeadPeerTimeout() failed: %w", err)
+	}
+	if err := protocolv2.SetTimingJitter(appctl.TimingJitter(config.GetAdvancedSettings().GetTimingJitterMillis())); err != nil {
+		return nil, fmt.Errorf("SetTimingJitter() failed: %w", err)
+	}
+	if err := protocolv2.SetUnderlayTimeoutConfig(appctl.UnderlayTimeoutConfig(config.GetAdvancedSettings().GetUnderlayReadTimeoutSeconds(), config.GetAdvancedSettings().GetStuckUnderlayTimeoutSeconds())); err != nil {
+		return nil, fmt.Errorf("SetUnderlayTimeoutConfig() failed: %w", err)
+	}
+	mux := protocolv2.NewMux(true)
+	var hashedPassword []byte
+	activeProfile, err := appctl.GetActiveProfileFromConfig(config, config.GetActiveProfile())
+	if err != nil {
+		return nil, fmt.Errorf(stderror.ClientGetActiveProfileFailedErr, err)
+	}
+	user := activeProfile.GetUser()
+	if user.GetHashedPassword() != "" {
+		hashedPassword, err = hex.DecodeString(user.GetHashedPassword())
+		if err != nil {
+			return nil, fmt.Errorf(stderror.DecodeHashedPasswordFailedErr, err)
+		}
+	} else {
+		hashedPassword = cipher.HashPassword([]byte(user.GetPassword()), []byte(user.GetName()))
+	}
+	mux = mux.SetClientPassword(hashedPassword)
+	multiplexFactor := 1
+	switch activeProfile.GetMultiplexing().GetLevel() {
+	case appctlpb.MultiplexingLevel_MULTIPLEXING_OFF:
+		multiplexFactor = 0
+	case appctlpb.MultiplexingLevel_MULTIPLEXING_LOW:
+		multiplexFactor = 1
+	case appctlpb.MultiplexingLevel_MULTIPLEXING_MIDDLE:
+		multiplexFactor = 2
+	case appctlpb.MultiplexingLevel_MULTIPLEXING_HIGH:
+		multiplexFactor = 3
+	}
+	mux = mux.SetClientMultiplexFactor(multiplexFactor)
+	if activeProfile.GetMultiplexing().GetPrewarm() {
+		mux = mux.SetClientPrewarm(true)
+	}
+	mux = mux.SetClientIPv6SourcePreference(appctl.IPv6SourcePreference(activeProfile.GetIpv6SourceAddress()))
+	mux.SetEndpoints(endpoints)
+	return mux, nil
+}
+
+// Socks5Config returns the config of a socks5 server that forwards
+// connections through the client mux. Domain rule lists, priority
+// rules and application rules of client config are applied.
+func Socks5Config(config *appctlpb.ClientConfig, mux *protocolv2.Mux, resolver *util.DNSResolver) (*socks5.Config, error) {
+	socks5Config := &socks5.Config{
+		UseProxy:                 true,
+		ClientSideAuthentication: true,
+		ProxyMux:                 mux,
+		HandshakeTimeout:         10 * time.Second,
+		RemoteDNSResolution:      config.GetRemoteDNSResolution() || config.GetDnsLeakProtection() != nil,
+		Resolver:                 resolver,
+	}
+	if len(config.GetDomainRuleLists()) != 0 {
+		egressController, err := egress.NewDomainRuleController(config.GetDomainRuleLists())
+		if err != nil {
+			return nil, fmt.Errorf(stderror.CreateEgressControllerFailedErr, err)
+		}
+		socks5Config.EgressController = egressController
+		// Destinations that bypass the proxy are in the local network of the user.
+		socks5Config.AllowLocalDestination = true
+	}
+	if len(config.GetAdvancedSettings().GetPriorityRules()) != 0 {
+		priorityRules, err := socks5.NewPriorityRules(config.GetAdvancedSettings().GetPriorityRules())
+		if err != nil {
+			return nil, fmt.Errorf("NewPriorityRules() failed: %w", err)
+		}
+		socks5Config.PriorityRules = priorityRules
+	}
+	if len(config.GetTransparentProxy().GetApplicationRules()) != 0 {
+		applicationRules, err := socks5.NewApplicationRules(config.GetTransparentProxy().GetApplicationRules())
+		if err != nil {
+			return nil, fmt.Errorf("NewApplicationRules() failed: %w", err)
+		}
+		socks5Config.ApplicationRules = applicationRules
+	}
+	return socks5Config, nil
+}
+
+// HasPortRotation returns true if any server of the active profile
+// has port rotation.
+func HasPortRotation(config *appctlpb.ClientConfig) bool {
+	activeProfile, err := appctl.GetActiveProfileFromConfig(config, config.GetActiveProfile())
+	if err != nil {
+		return false
+	}
+	for _, serverInfo := range activeProfile.GetServers() {
+		if serverInfo.GetPortRotation() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rotatedPorts returns the active ports of servers with port rotation
+// in the active profile.
+func rotatedPorts(config *appctlpb.ClientConfig, t time.Time) []int32 {
+	activeProfile, err := appctl.GetActiveProfileFromConfig(config, config.GetActiveProfile())
+	if err != nil {
+		return nil
+	}
+	var ports []int32
+	for _, serverInfo := range activeProfile.GetServers() {
+		if serverInfo.GetPortRotation() != nil {
+			ports = append(ports, appctl.RotatedPort(serverInfo.GetPortRotation(), t))
+		}
+	}
+	return ports
+}
+
+// RefreshRotatedPorts updates the endpoints of the client mux
+// when the active port of any server with port rotation is changed.
+// It returns when done is closed.
+func RefreshRotatedPorts(mux *protocolv2.Mux, resolver *util.DNSResolver, config *atomic.Pointer[appctlpb.ClientConfig], done <-chan struct{}) {
+	ticker := time.NewTicker(appctl.PortRotationCheckInterval)
+	defer ticker.Stop()
+	last := rotatedPorts(config.Load(), time.Now())
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		current := config.Load()
+		ports := rotatedPorts(current, time.Now())
+		if reflect.DeepEqual(ports, last) {
+			continue
+		}
+		endpoints, err := Endpoints(context.Background(), current, resolver)
+		if err != nil {
+			log.Errorf("update rotated server ports failed: %v", err)
+			continue
+		}
+		log.Infof("server ports are rotated to %v", ports)
+		mux.SetEndpoints(endpoints)
+		last = ports
+	}
+}
+
+// Endpoints returns the endpoints of proxy servers in the active profile.
+func Endpoints(ctx context.Context, config *appctlpb.ClientConfig, resolver *util.DNSResolver) ([]protocolv2.UnderlayProperties, error) {
+	activeProfile, err := appctl.GetActiveProfileFromConfig(config, config.GetActiveProfile())
+	if err != nil {
+		return nil, fmt.Errorf(stderror.ClientGetActiveProfileFailedErr, err)
+	}
+	mtu := util.DefaultMTU
+	if activeProfile.GetMtu() != 0 {
+		mtu = int(activeProfile.GetMtu())
+	}
+	endpoints := make([]protocolv2.UnderlayProperties, 0)
+	for _, serverInfo := range activeProfile.GetServers() {
+		var proxyHost string
+		var proxyIP net.IP
+		if serverInfo.GetDomainName() != "" {
+			proxyHost = serverInfo.GetDomainName()
+			proxyIP, err = resolver.LookupIP(ctx, proxyHost)
+			if err != nil {
+				return nil, fmt.Errorf(stderror.LookupIPFailedErr, err)
+			}
+		} else {
+			proxyHost = serverInfo.GetIpAddress()
+			proxyIP = net.ParseIP(proxyHost)
+			if proxyIP == nil {
+				return nil, fmt.Errorf(stderror.ParseIPFailed)
+			}
+		}
+		ipVersion := util.GetIPVersion(proxyIP.String())
+		portBindings, err := appctl.FlatPortBindings(appctl.ServerEndpointPortBindings(serverInfo, time.Now()))
+		if err != nil {
+			return nil, fmt.Errorf(stderror.InvalidPortBindingsErr, err)
+		}
+		for _, bindingInfo := range portBindings {
+			proxyPort := bindingInfo.GetPort()
+			mimicry := appctl.UnderlayMimicry(bindingInfo.GetMimicry())
+			switch bindingInfo.GetProtocol() {
+			case appctlpb.TransportProtocol_TCP:
+				endpoint := protocolv2.NewUnderlayPropertiesWithMimicry(mtu, ipVersion, util.TCPTransport, mimicry, nil, &net.TCPAddr{IP: proxyIP, Port: int(proxyPort)})
+				endpoints = append(endpoints, endpoint)
+			case appctlpb.TransportProtocol_UDP:
+				endpoint := protocolv2.NewUnderlayPropertiesWithMimicry(mtu, ipVersion, util.UDPTransport, mimicry, nil, &net.UDPAddr{IP: proxyIP, Port: int(proxyPort)})
+				endpoints = append(endpoints, endpoint)
+			default:
+				return nil, fmt.Errorf(stderror.InvalidTransportProtocol)
+			}
+		}
+	}
+	return endpoints, nil
+}
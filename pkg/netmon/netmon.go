@@ -0,0 +1,183 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package netmon watches the network of the host, so the proxy client
+// can re-establish the connections to proxy servers after the host
+// switches to another network or is resumed from sleep.
+package netmon
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+)
+
+const (
+	// pollInterval is the interval to check the network interfaces.
+	pollInterval = 5 * time.Second
+
+	// settleDelay is the time to wait after a network event from the
+	// operating system before checking the network interfaces, because
+	// an interface change usually comes with a burst of events.
+	settleDelay = time.Second
+
+	// sleepThreshold is the minimum gap of the wall clock between two
+	// checks to consider the host was sleeping.
+	sleepThreshold = 30 * time.Second
+)
+
+// Monitor calls the handlers when the network of the host is changed.
+type Monitor struct {
+	mu       sync.Mutex
+	handlers []func(reason string)
+	done     chan struct{}
+	closed   bool
+
+	// interfaceAddrs returns the addresses of network interfaces.
+	// It is replaced in tests.
+	interfaceAddrs func() (string, error)
+}
+
+// New returns a new network monitor. Call Start to begin watching.
+func New() *Monitor {
+	return &Monitor{
+		done:           make(chan struct{}),
+		interfaceAddrs: fingerprint,
+	}
+}
+
+// OnChange adds a handler, which is called with the reason when the
+// network is changed or the host is resumed from sleep.
+func (m *Monitor) OnChange(handler func(reason string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// Start watches the network in the background until Close is called.
+// Network interfaces are checked periodically. On Linux, they are also
+// checked when the kernel reports a link, address or route change.
+func (m *Monitor) Start() {
+	kick := make(chan struct{}, 1)
+	if err := subscribe(kick, m.done); err != nil {
+		log.Debugf("unable to subscribe to network events, network is only polled: %v", err)
+	}
+	go m.run(kick)
+}
+
+// Close stops watching the network.
+func (m *Monitor) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.closed {
+		m.closed = true
+		close(m.done)
+	}
+	return nil
+}
+
+func (m *Monitor) run(kick <-chan struct{}) {
+	last, _ := m.interfaceAddrs()
+	lastCheck := time.Now()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-kick:
+			if settle == nil {
+				settle = time.After(settleDelay)
+			}
+			continue
+		case <-settle:
+			settle = nil
+		case <-ticker.C:
+		}
+		now := time.Now()
+		current, err := m.interfaceAddrs()
+		if err != nil {
+			log.Debugf("unable to get network interface addresses: %v", err)
+			continue
+		}
+		reason := changeReason(last, current, lastCheck, now)
+		last = current
+		lastCheck = now
+		if reason != "" {
+			m.notify(reason)
+		}
+	}
+}
+
+// changeReason returns why the network needs to be re-established,
+// or an empty string if it doesn't. The wall clock is compared,
+// because the monotonic clock may not advance while the host sleeps.
+func changeReason(lastAddrs, addrs string, lastCheck, now time.Time) string {
+	if now.Round(0).Sub(lastCheck.Round(0)) > sleepThreshold {
+		return "host is resumed from sleep"
+	}
+	if addrs != lastAddrs {
+		return "network is changed"
+	}
+	return ""
+}
+
+func (m *Monitor) notify(reason string) {
+	log.Infof("%s, re-establishing connections to proxy servers", reason)
+	m.mu.Lock()
+	handlers := append([]func(string){}, m.handlers...)
+	m.mu.Unlock()
+	for _, handler := range handlers {
+		handler(reason)
+	}
+}
+
+// fingerprint returns a string describing the addresses of the network
+// interfaces that are up. IPv6 addresses are reduced to their /64
+// prefix, so the rotation of temporary addresses is not a change.
+func fingerprint() (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+	var entries []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || !ipNet.IP.IsGlobalUnicast() {
+				continue
+			}
+			ip := ipNet.IP
+			if ip.To4() == nil {
+				ip = ip.Mask(net.CIDRMask(64, 128))
+			}
+			entries = append(entries, iface.Name+"/"+ip.String())
+		}
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ","), nil
+}
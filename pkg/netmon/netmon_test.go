@@ -0,0 +1,75 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package netmon
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChangeReason(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		lastAddrs string
+		addrs     string
+		lastCheck time.Time
+		want      string
+	}{
+		{"eth0/192.0.2.1", "eth0/192.0.2.1", now.Add(-pollInterval), ""},
+		{"eth0/192.0.2.1", "wlan0/198.51.100.1", now.Add(-pollInterval), "network is changed"},
+		{"eth0/192.0.2.1", "eth0/192.0.2.1", now.Add(-time.Hour), "host is resumed from sleep"},
+	}
+	for _, tc := range testCases {
+		if got := changeReason(tc.lastAddrs, tc.addrs, tc.lastCheck, now); got != tc.want {
+			t.Errorf("changeReason(%q, %q) = %q, want %q", tc.lastAddrs, tc.addrs, got, tc.want)
+		}
+	}
+}
+
+func TestMonitorNotifiesChange(t *testing.T) {
+	var addrs atomic.Value
+	addrs.Store("eth0/192.0.2.1")
+	m := New()
+	m.interfaceAddrs = func() (string, error) {
+		return addrs.Load().(string), nil
+	}
+	reasons := make(chan string, 1)
+	m.OnChange(func(reason string) {
+		reasons <- reason
+	})
+	kick := make(chan struct{}, 1)
+	go m.run(kick)
+	defer m.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	addrs.Store("wlan0/198.51.100.1")
+	kick <- struct{}{}
+	select {
+	case reason := <-reasons:
+		if reason != "network is changed" {
+			t.Errorf("got reason %q, want %q", reason, "network is changed")
+		}
+	case <-time.After(pollInterval):
+		t.Fatalf("handler is not called after network change")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	if _, err := fingerprint(); err != nil {
+		t.Errorf("fingerprint() failed: %v", err)
+	}
+}
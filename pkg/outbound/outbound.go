@@ -0,0 +1,153 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package outbound adapts mieru client to the outbound interfaces of
+// rule-based proxy clients, such as sing-box and clash. Those clients
+// decide the route of each connection, and use the adapter to dial TCP
+// and UDP through mieru proxy servers. The adapter only uses standard
+// library types, so a thin wrapper in those projects can convert their
+// own address and connection types to it.
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/mieruclient"
+)
+
+// Type is the protocol type name of the adapter.
+const Type = "mieru"
+
+// Adapter dials TCP and UDP connections through mieru proxy servers.
+// The client is started when the first connection is created.
+type Adapter struct {
+	name   string
+	client *mieruclient.Client
+
+	mu      sync.Mutex
+	started bool
+}
+
+// New creates an adapter from the name of the outbound and client config.
+func New(name string, config *appctlpb.ClientConfig) (*Adapter, error) {
+	client, err := mieruclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Adapter{name: name, client: client}, nil
+}
+
+// Name returns the name of the outbound.
+func (a *Adapter) Name() string {
+	return a.name
+}
+
+// Type returns the protocol type name.
+func (a *Adapter) Type() string {
+	return Type
+}
+
+// SupportUDP returns true because UDP is relayed by the proxy server.
+func (a *Adapter) SupportUDP() bool {
+	return true
+}
+
+// DialContext connects to the address through the proxy. If the network
+// is UDP, the returned connection only exchanges packets with the address.
+func (a *Adapter) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if err := a.start(ctx); err != nil {
+		return nil, err
+	}
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return a.client.DialContext(ctx, network, address)
+	case "udp", "udp4", "udp6":
+		packetConn, err := a.client.ListenPacket(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &udpConn{PacketConn: packetConn, remote: udpAddr(address)}, nil
+	default:
+		return nil, fmt.Errorf("network %s is not supported", network)
+	}
+}
+
+// ListenPacket creates a packet connection that sends UDP packets to any
+// destination through the proxy. The destination is not used to create
+// the connection.
+func (a *Adapter) ListenPacket(ctx context.Context, destination string) (net.PacketConn, error) {
+	if err := a.start(ctx); err != nil {
+		return nil, err
+	}
+	return a.client.ListenPacket(ctx)
+}
+
+// Close closes the connections to proxy servers.
+func (a *Adapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.started = false
+	return a.client.Stop()
+}
+
+func (a *Adapter) start(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.started {
+		return nil
+	}
+	if err := a.client.Start(ctx); err != nil {
+		return err
+	}
+	a.started = true
+	return nil
+}
+
+// udpAddr is the address of a UDP destination. The host can be
+// a domain name, which is resolved by the proxy server.
+type udpAddr string
+
+func (a udpAddr) Network() string {
+	return "udp"
+}
+
+func (a udpAddr) String() string {
+	return string(a)
+}
+
+// udpConn is a connected UDP connection through the proxy.
+type udpConn struct {
+	net.PacketConn
+	remote net.Addr
+}
+
+var _ net.Conn = &udpConn{}
+
+func (c *udpConn) Read(b []byte) (int, error) {
+	n, _, err := c.PacketConn.ReadFrom(b)
+	return n, err
+}
+
+func (c *udpConn) Write(b []byte) (int, error) {
+	return c.PacketConn.WriteTo(b, c.remote)
+}
+
+func (c *udpConn) RemoteAddr() net.Addr {
+	return c.remote
+}
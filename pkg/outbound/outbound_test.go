@@ -0,0 +1,173 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package outbound
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/socks5"
+	"github.com/enfein/mieru/pkg/util"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAdapterTCPAndUDP(t *testing.T) {
+	log.SetOutputToTest(t)
+	port, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	serverMux := protocolv2.NewMux(false).
+		SetServerUsers(map[string]*appctlpb.User{
+			"xiaochitang": {
+				Name:     proto.String("xiaochitang"),
+				Password: proto.String("kuiranbudong"),
+			},
+		}).
+		SetEndpoints([]protocolv2.UnderlayProperties{
+			protocolv2.NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil),
+		})
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer serverMux.Close()
+	time.Sleep(100 * time.Millisecond)
+	proxyServer, err := socks5.New(&socks5.Config{
+		AllowLocalDestination:    true,
+		ClientSideAuthentication: true,
+		HandshakeTimeout:         10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("socks5.New() failed: %v", err)
+	}
+	go proxyServer.Serve(serverMux)
+	defer proxyServer.Close()
+
+	tcpEcho, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer tcpEcho.Close()
+	go func() {
+		for {
+			conn, err := tcpEcho.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	udpEcho, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.ListenPacket() failed: %v", err)
+	}
+	defer udpEcho.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := udpEcho.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			udpEcho.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	adapter, err := New("mieru-test", &appctlpb.ClientConfig{
+		Profiles: []*appctlpb.ClientProfile{
+			{
+				ProfileName: proto.String("default"),
+				User: &appctlpb.User{
+					Name:     proto.String("xiaochitang"),
+					Password: proto.String("kuiranbudong"),
+				},
+				Servers: []*appctlpb.ServerEndpoint{
+					{
+						IpAddress: proto.String("127.0.0.1"),
+						PortBindings: []*appctlpb.PortBinding{
+							{
+								Port:     proto.Int32(int32(port)),
+								Protocol: appctlpb.TransportProtocol_TCP.Enum(),
+							},
+						},
+					},
+				},
+			},
+		},
+		ActiveProfile: proto.String("default"),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer adapter.Close()
+	if adapter.Name() != "mieru-test" || adapter.Type() != Type || !adapter.SupportUDP() {
+		t.Errorf("got name %q, type %q, UDP %v", adapter.Name(), adapter.Type(), adapter.SupportUDP())
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		network string
+		address string
+	}{
+		{"tcp", tcpEcho.Addr().String()},
+		{"udp", udpEcho.LocalAddr().String()},
+	} {
+		conn, err := adapter.DialContext(ctx, tc.network, tc.address)
+		if err != nil {
+			t.Fatalf("DialContext(%s) failed: %v", tc.network, err)
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write([]byte("ping")); err != nil {
+			t.Fatalf("Write(%s) failed: %v", tc.network, err)
+		}
+		b := make([]byte, 1500)
+		n, err := conn.Read(b)
+		if err != nil {
+			t.Fatalf("Read(%s) failed: %v", tc.network, err)
+		}
+		if string(b[:n]) != "ping" {
+			t.Errorf("got %q from %s, want %q", b[:n], tc.network, "ping")
+		}
+		conn.Close()
+	}
+
+	packetConn, err := adapter.ListenPacket(ctx, udpEcho.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("ListenPacket() failed: %v", err)
+	}
+	defer packetConn.Close()
+	packetConn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := packetConn.WriteTo([]byte("pong"), udpEcho.LocalAddr()); err != nil {
+		t.Fatalf("WriteTo() failed: %v", err)
+	}
+	b := make([]byte, 1500)
+	n, addr, err := packetConn.ReadFrom(b)
+	if err != nil {
+		t.Fatalf("ReadFrom() failed: %v", err)
+	}
+	if string(b[:n]) != "pong" || addr.String() != udpEcho.LocalAddr().String() {
+		t.Errorf("got %q from %v, want %q from %v", b[:n], addr, "pong", udpEcho.LocalAddr())
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+)
+
+// accessWindow is the parsed form of appctlpb.AccessWindow.
+type accessWindow struct {
+	weekdays [7]bool
+	start    int // minutes since midnight
+	end      int // minutes since midnight
+	location *time.Location
+}
+
+// ValidateAccessWindow returns an error if the access window is invalid.
+func ValidateAccessWindow(w *appctlpb.AccessWindow) error {
+	_, err := newAccessWindow(w)
+	return err
+}
+
+func newAccessWindow(w *appctlpb.AccessWindow) (*accessWindow, error) {
+	a := &accessWindow{location: time.Local}
+	if len(w.GetWeekdays()) == 0 {
+		for i := range a.weekdays {
+			a.weekdays[i] = true
+		}
+	}
+	for _, day := range w.GetWeekdays() {
+		if day < 0 || day > 6 {
+			return nil, fmt.Errorf("access window: weekday %d is invalid", day)
+		}
+		a.weekdays[day] = true
+	}
+	var err error
+	if a.start, err = parseTimeOfDay(w.GetStartTime()); err != nil {
+		return nil, fmt.Errorf("access window: start time: %w", err)
+	}
+	if a.end, err = parseTimeOfDay(w.GetEndTime()); err != nil {
+		return nil, fmt.Errorf("access window: end time: %w", err)
+	}
+	if w.GetTimeZone() != "" {
+		if a.location, err = time.LoadLocation(w.GetTimeZone()); err != nil {
+			return nil, fmt.Errorf("access window: time zone %q is invalid: %w", w.GetTimeZone(), err)
+		}
+	}
+	return a, nil
+}
+
+// contains returns true if the time t is inside the access window.
+func (a *accessWindow) contains(t time.Time) bool {
+	t = t.In(a.location)
+	minute := t.Hour()*60 + t.Minute()
+	today := int(t.Weekday())
+	yesterday := (today + 6) % 7
+	switch {
+	case a.start < a.end:
+		return a.weekdays[today] && minute >= a.start && minute < a.end
+	case a.start > a.end:
+		// The window crosses midnight.
+		return (a.weekdays[today] && minute >= a.start) || (a.weekdays[yesterday] && minute < a.end)
+	default:
+		// The window lasts 24 hours.
+		return (a.weekdays[today] && minute >= a.start) || (a.weekdays[yesterday] && minute < a.start)
+	}
+}
+
+// isInAccessWindows returns true if the user is allowed to open
+// new sessions at time t. Invalid access windows are ignored.
+func isInAccessWindows(windows []*appctlpb.AccessWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		a, err := newAccessWindow(w)
+		if err != nil {
+			continue
+		}
+		if a.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeOfDay parses "HH:MM" and returns the number of minutes
+// since midnight.
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not in HH:MM format", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
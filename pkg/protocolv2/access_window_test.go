@@ -0,0 +1,88 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestIsInAccessWindows(t *testing.T) {
+	// 2024-01-05 is a Friday.
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 5, hour, minute, 0, 0, time.UTC)
+	}
+	schoolNights := &appctlpb.AccessWindow{
+		Weekdays:  []int32{1, 2, 3, 4, 5},
+		StartTime: proto.String("16:00"),
+		EndTime:   proto.String("21:30"),
+		TimeZone:  proto.String("UTC"),
+	}
+	weekendNights := &appctlpb.AccessWindow{
+		Weekdays:  []int32{5, 6},
+		StartTime: proto.String("22:00"),
+		EndTime:   proto.String("02:00"),
+		TimeZone:  proto.String("UTC"),
+	}
+	allDay := &appctlpb.AccessWindow{
+		Weekdays:  []int32{0},
+		StartTime: proto.String("00:00"),
+		EndTime:   proto.String("00:00"),
+		TimeZone:  proto.String("UTC"),
+	}
+
+	testCases := []struct {
+		name    string
+		windows []*appctlpb.AccessWindow
+		t       time.Time
+		want    bool
+	}{
+		{"no window", nil, friday(3, 0), true},
+		{"before start", []*appctlpb.AccessWindow{schoolNights}, friday(15, 59), false},
+		{"at start", []*appctlpb.AccessWindow{schoolNights}, friday(16, 0), true},
+		{"at end", []*appctlpb.AccessWindow{schoolNights}, friday(21, 30), false},
+		{"other weekday", []*appctlpb.AccessWindow{schoolNights}, friday(17, 0).Add(24 * time.Hour), false},
+		{"cross midnight before", []*appctlpb.AccessWindow{weekendNights}, friday(23, 0), true},
+		{"cross midnight after", []*appctlpb.AccessWindow{weekendNights}, friday(1, 0).Add(24 * time.Hour), true},
+		{"cross midnight previous day not allowed", []*appctlpb.AccessWindow{weekendNights}, friday(1, 0), false},
+		{"multiple windows", []*appctlpb.AccessWindow{schoolNights, weekendNights}, friday(22, 30), true},
+		{"all day", []*appctlpb.AccessWindow{allDay}, friday(12, 0).Add(2 * 24 * time.Hour), true},
+		{"all day next morning", []*appctlpb.AccessWindow{allDay}, friday(0, 0).Add(3 * 24 * time.Hour), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isInAccessWindows(tc.windows, tc.t); got != tc.want {
+				t.Errorf("isInAccessWindows() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateAccessWindow(t *testing.T) {
+	invalid := []*appctlpb.AccessWindow{
+		{StartTime: proto.String("08:00"), EndTime: proto.String("24:00")},
+		{Weekdays: []int32{7}, StartTime: proto.String("08:00"), EndTime: proto.String("12:00")},
+		{StartTime: proto.String("08:00"), EndTime: proto.String("12:00"), TimeZone: proto.String("Mars/Olympus")},
+	}
+	for _, w := range invalid {
+		if err := ValidateAccessWindow(w); err == nil {
+			t.Errorf("ValidateAccessWindow(%v) = nil, want error", w)
+		}
+	}
+}
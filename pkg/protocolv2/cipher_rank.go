@@ -0,0 +1,184 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/cipher"
+)
+
+const (
+	// recentHitHalfLife is the half life of recent cipher hits.
+	recentHitHalfLife = 10 * time.Minute
+
+	// hourlyHitHalfLife is the half life of cipher hits in the same
+	// hour of day. Hits from previous days are still effective.
+	hourlyHitHalfLife = 3 * 24 * time.Hour
+
+	// cipherHitPruneInterval is the interval to remove small counters.
+	cipherHitPruneInterval = time.Hour
+
+	// minCipherHitScore is the minimum score to keep a counter.
+	minCipherHitScore = 0.01
+)
+
+// serverCipherHits records the users whose ciphers decrypted new underlays.
+var serverCipherHits = newCipherHitTracker()
+
+// cipherHitKey identifies a counter. hour is -1 for recent hits.
+type cipherHitKey struct {
+	port int
+	hour int
+	user string
+}
+
+// decayingCounter is a counter that exponentially decays over time.
+type decayingCounter struct {
+	value   float64
+	updated time.Time
+}
+
+func (d decayingCounter) get(now time.Time, halfLife time.Duration) float64 {
+	elapsed := now.Sub(d.updated)
+	if elapsed <= 0 {
+		return d.value
+	}
+	return d.value * math.Exp2(-elapsed.Seconds()/halfLife.Seconds())
+}
+
+// cipherHitTracker counts the users whose ciphers decrypted new underlays,
+// per server port and hour of day. The server tries cipher candidates of
+// users with more hits first, so decryption usually succeeds on the
+// first try even if there are many users.
+type cipherHitTracker struct {
+	mu        sync.Mutex
+	counters  map[cipherHitKey]decayingCounter
+	lastPrune time.Time
+}
+
+func newCipherHitTracker() *cipherHitTracker {
+	return &cipherHitTracker{
+		counters:  make(map[cipherHitKey]decayingCounter),
+		lastPrune: time.Now(),
+	}
+}
+
+// recordHit records that the cipher of user decrypted a new underlay
+// on the port.
+func (c *cipherHitTracker) recordHit(port int, user string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(cipherHitKey{port: port, hour: -1, user: user}, now, recentHitHalfLife)
+	c.add(cipherHitKey{port: port, hour: now.Hour(), user: user}, now, hourlyHitHalfLife)
+	if now.Sub(c.lastPrune) >= cipherHitPruneInterval {
+		c.prune(now)
+	}
+}
+
+// score returns the score of user on the port. Higher is more likely to hit.
+func (c *cipherHitTracker) score(port int, user string, now time.Time) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.scoreLocked(port, user, now)
+}
+
+// rankUsers returns the users sorted by score from high to low.
+// Users with the same score are sorted by name.
+func (c *cipherHitTracker) rankUsers(port int, users map[string]*appctlpb.User, now time.Time) []*appctlpb.User {
+	type scoredUser struct {
+		user  *appctlpb.User
+		score float64
+	}
+	scored := make([]scoredUser, 0, len(users))
+	c.mu.Lock()
+	for _, user := range users {
+		scored = append(scored, scoredUser{user: user, score: c.scoreLocked(port, user.GetName(), now)})
+	}
+	c.mu.Unlock()
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].user.GetName() < scored[j].user.GetName()
+	})
+	res := make([]*appctlpb.User, 0, len(scored))
+	for _, s := range scored {
+		res = append(res, s.user)
+	}
+	return res
+}
+
+// scoreLocked returns the score of user. The caller must hold mu lock.
+func (c *cipherHitTracker) scoreLocked(port int, user string, now time.Time) float64 {
+	var score float64
+	if counter, found := c.counters[cipherHitKey{port: port, hour: -1, user: user}]; found {
+		score += counter.get(now, recentHitHalfLife)
+	}
+	if counter, found := c.counters[cipherHitKey{port: port, hour: now.Hour(), user: user}]; found {
+		score += counter.get(now, hourlyHitHalfLife)
+	}
+	return score
+}
+
+// add increases a counter by 1. The caller must hold mu lock.
+func (c *cipherHitTracker) add(key cipherHitKey, now time.Time, halfLife time.Duration) {
+	counter := c.counters[key]
+	c.counters[key] = decayingCounter{
+		value:   counter.get(now, halfLife) + 1,
+		updated: now,
+	}
+}
+
+// prune removes counters with small values. The caller must hold mu lock.
+func (c *cipherHitTracker) prune(now time.Time) {
+	for key, counter := range c.counters {
+		halfLife := hourlyHitHalfLife
+		if key.hour == -1 {
+			halfLife = recentHitHalfLife
+		}
+		if counter.get(now, halfLife) < minCipherHitScore {
+			delete(c.counters, key)
+		}
+	}
+	c.lastPrune = now
+}
+
+// preferCurrentSalt moves the cipher block generated from the current time
+// salt to the front. Clients use this block unless the clock is skewed.
+func preferCurrentSalt(blocks []cipher.BlockCipher) []cipher.BlockCipher {
+	if len(blocks) == 3 {
+		blocks[0], blocks[1] = blocks[1], blocks[0]
+	}
+	return blocks
+}
+
+// localPort returns the port number of a TCP or UDP address, or 0.
+func localPort(addr net.Addr) int {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.Port
+	case *net.UDPAddr:
+		return a.Port
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCipherHitTrackerRankUsers(t *testing.T) {
+	users := map[string]*appctlpb.User{}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		users[name] = &appctlpb.User{Name: proto.String(name)}
+	}
+	names := func(users []*appctlpb.User) []string {
+		var res []string
+		for _, u := range users {
+			res = append(res, u.GetName())
+		}
+		return res
+	}
+	c := newCipherHitTracker()
+	now := time.Date(2024, 1, 1, 20, 0, 0, 0, time.Local)
+
+	// Without hits, users are sorted by name.
+	if got := names(c.rankUsers(8964, users, now)); got[0] != "alice" || got[1] != "bob" || got[2] != "carol" {
+		t.Errorf("rankUsers() = %v, want [alice bob carol]", got)
+	}
+
+	c.recordHit(8964, "carol", now)
+	c.recordHit(8964, "carol", now)
+	c.recordHit(8964, "bob", now)
+	if got := names(c.rankUsers(8964, users, now)); got[0] != "carol" || got[1] != "bob" || got[2] != "alice" {
+		t.Errorf("rankUsers() = %v, want [carol bob alice]", got)
+	}
+
+	// Hits on other ports don't change the order.
+	c.recordHit(9000, "alice", now)
+	if got := names(c.rankUsers(8964, users, now)); got[0] != "carol" {
+		t.Errorf("rankUsers() = %v, want carol first", got)
+	}
+
+	// Recent hits decay. Hits from the same hour of the previous day remain.
+	c.recordHit(8964, "alice", now.Add(12*time.Hour))
+	nextDay := now.Add(24 * time.Hour)
+	if got := names(c.rankUsers(8964, users, nextDay)); got[0] != "carol" {
+		t.Errorf("rankUsers() = %v, want carol first at the same hour of the next day", got)
+	}
+	if got := names(c.rankUsers(8964, users, now.Add(12*time.Hour+time.Minute))); got[0] != "alice" {
+		t.Errorf("rankUsers() = %v, want alice first after a recent hit", got)
+	}
+	if score := c.score(8964, "carol", now.Add(30*24*time.Hour)); score >= minCipherHitScore {
+		t.Errorf("score() = %v after a long time, want less than %v", score, minCipherHitScore)
+	}
+}
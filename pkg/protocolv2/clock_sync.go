@@ -0,0 +1,60 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"time"
+
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/log"
+)
+
+// clockSyncThreshold is the minimum change of clock offset that is applied.
+// Smaller changes are ignored because the server time is delayed by
+// network latency.
+const clockSyncThreshold = 5 * time.Second
+
+// serverNow returns the server time attached to the open session response.
+// It can be replaced in tests.
+var serverNow = cipher.Now
+
+// syncClock adjusts the clock offset of the client with the server time
+// attached to the open session response. The response is authenticated,
+// so the server time can be trusted. After that, the client is able to
+// connect even if the local clock drifts away from the server.
+//
+// The clock offset is shared by the whole process, so it is only adjusted
+// by underlays of a client mux that enables clock sync. Other client muxes,
+// for example the upstream of a proxy server, don't change it.
+func (b *baseUnderlay) syncClock(ss *sessionStruct) {
+	if !b.isClient || !b.clockSync || ss.flags&flagServerTime == 0 || ss.serverTime == 0 {
+		return
+	}
+	offset := clockOffsetFromServerTime(ss.serverTime, time.Now())
+	current := cipher.ClockOffset()
+	if diff := offset - current; diff > -clockSyncThreshold && diff < clockSyncThreshold {
+		return
+	}
+	log.Infof("Local clock differs from proxy server by %v, adjusting clock offset from %v", offset, current)
+	cipher.SetClockOffset(offset)
+	UnderlayClockAdjusts.Add(1)
+}
+
+// clockOffsetFromServerTime returns the difference between the server time
+// in milliseconds and the local time, rounded to seconds.
+func clockOffsetFromServerTime(serverTime uint64, now time.Time) time.Duration {
+	return time.UnixMilli(int64(serverTime)).Sub(now).Round(time.Second)
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/cipher"
+)
+
+func TestSyncClock(t *testing.T) {
+	defer cipher.SetClockOffset(0)
+	client := newBaseUnderlay(true, 1500, MimicryPlain)
+	client.clockSync = true
+	server := newBaseUnderlay(false, 1500, MimicryPlain)
+	serverTime := func(offset time.Duration) uint64 {
+		return uint64(time.Now().Add(offset).UnixMilli())
+	}
+
+	// Server ignores the server time.
+	server.syncClock(&sessionStruct{baseStruct: baseStruct{flags: flagServerTime}, serverTime: serverTime(time.Hour)})
+	if offset := cipher.ClockOffset(); offset != 0 {
+		t.Errorf("ClockOffset() = %v, want 0", offset)
+	}
+
+	// Client without clock sync ignores the server time.
+	other := newBaseUnderlay(true, 1500, MimicryPlain)
+	other.syncClock(&sessionStruct{baseStruct: baseStruct{flags: flagServerTime}, serverTime: serverTime(time.Hour)})
+	if offset := cipher.ClockOffset(); offset != 0 {
+		t.Errorf("ClockOffset() = %v, want 0", offset)
+	}
+
+	// Server time is not used if the flag is not set.
+	client.syncClock(&sessionStruct{serverTime: serverTime(time.Hour)})
+	if offset := cipher.ClockOffset(); offset != 0 {
+		t.Errorf("ClockOffset() = %v, want 0", offset)
+	}
+
+	// Small difference is ignored.
+	client.syncClock(&sessionStruct{baseStruct: baseStruct{flags: flagServerTime}, serverTime: serverTime(time.Second)})
+	if offset := cipher.ClockOffset(); offset != 0 {
+		t.Errorf("ClockOffset() = %v, want 0", offset)
+	}
+
+	client.syncClock(&sessionStruct{baseStruct: baseStruct{flags: flagServerTime}, serverTime: serverTime(-time.Minute)})
+	if offset := cipher.ClockOffset(); offset < -61*time.Second || offset > -59*time.Second {
+		t.Errorf("ClockOffset() = %v, want about -1m", offset)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// ConnectionLimitConfig limits the number of concurrent sessions and
+// underlays of each user and each source IP address. A zero value
+// means no limit.
+type ConnectionLimitConfig struct {
+	MaxSessionsPerUser      int
+	MaxSessionsPerSourceIP  int
+	MaxUnderlaysPerUser     int
+	MaxUnderlaysPerSourceIP int
+}
+
+// connectionLimits counts the concurrent sessions and underlays
+// of users and source IP addresses. The counts are kept even if
+// there is no limit, so the limits can be changed at any time.
+type connectionLimits struct {
+	mu        sync.Mutex
+	config    ConnectionLimitConfig
+	sessions  map[string]int
+	underlays map[string]int
+}
+
+// serverConnectionLimits is shared by all the server underlays.
+var serverConnectionLimits = &connectionLimits{
+	sessions:  make(map[string]int),
+	underlays: make(map[string]int),
+}
+
+// SetConnectionLimitConfig changes the limits of concurrent sessions
+// and underlays. Existing sessions and underlays are not closed.
+func SetConnectionLimitConfig(config ConnectionLimitConfig) error {
+	if config.MaxSessionsPerUser < 0 || config.MaxSessionsPerSourceIP < 0 || config.MaxUnderlaysPerUser < 0 || config.MaxUnderlaysPerSourceIP < 0 {
+		return fmt.Errorf("connection limit can't be negative")
+	}
+	serverConnectionLimits.mu.Lock()
+	serverConnectionLimits.config = config
+	serverConnectionLimits.mu.Unlock()
+	return nil
+}
+
+// acquireSession counts a new session of the user from the source
+// IP address. It returns an error if a limit is reached. Otherwise,
+// the returned function must be called when the session is closed.
+func (l *connectionLimits) acquireSession(userName string, ip net.IP) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.acquire(l.sessions, "sessions", userName, ip, l.config.MaxSessionsPerUser, l.config.MaxSessionsPerSourceIP)
+}
+
+// acquireUnderlay counts a new underlay of the user from the source
+// IP address. userName or ip can be empty if it is not known yet.
+// It returns an error if a limit is reached. Otherwise, the returned
+// function must be called when the underlay is closed.
+func (l *connectionLimits) acquireUnderlay(userName string, ip net.IP) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.acquire(l.underlays, "underlays", userName, ip, l.config.MaxUnderlaysPerUser, l.config.MaxUnderlaysPerSourceIP)
+}
+
+func (l *connectionLimits) acquire(counts map[string]int, kind, userName string, ip net.IP, userLimit, ipLimit int) (func(), error) {
+	var keys []string
+	if userName != "" {
+		key := "user/" + userName
+		if userLimit > 0 && counts[key] >= userLimit {
+			return nil, fmt.Errorf("user %s reached the limit of %d concurrent %s", userName, userLimit, kind)
+		}
+		keys = append(keys, key)
+	}
+	if ip != nil {
+		key := "ip/" + ip.String()
+		if ipLimit > 0 && counts[key] >= ipLimit {
+			return nil, fmt.Errorf("source IP %v reached the limit of %d concurrent %s", ip, ipLimit, kind)
+		}
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		counts[key]++
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for _, key := range keys {
+				if counts[key]--; counts[key] <= 0 {
+					delete(counts, key)
+				}
+			}
+		})
+	}, nil
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"testing"
+)
+
+func TestConnectionLimits(t *testing.T) {
+	l := &connectionLimits{
+		config: ConnectionLimitConfig{
+			MaxSessionsPerUser:     2,
+			MaxSessionsPerSourceIP: 1,
+		},
+		sessions:  make(map[string]int),
+		underlays: make(map[string]int),
+	}
+	ip1 := net.ParseIP("203.0.113.1")
+	ip2 := net.ParseIP("203.0.113.2")
+	ip3 := net.ParseIP("203.0.113.3")
+
+	release1, err := l.acquireSession("alice", ip1)
+	if err != nil {
+		t.Fatalf("acquireSession() failed: %v", err)
+	}
+	if _, err := l.acquireSession("bob", ip1); err == nil {
+		t.Errorf("source IP limit is not enforced")
+	}
+	release2, err := l.acquireSession("alice", ip2)
+	if err != nil {
+		t.Fatalf("acquireSession() failed: %v", err)
+	}
+	if _, err := l.acquireSession("alice", ip3); err == nil {
+		t.Errorf("user limit is not enforced")
+	}
+
+	// A rejected session is not counted.
+	if got := l.sessions["ip/"+ip3.String()]; got != 0 {
+		t.Errorf("rejected session is counted %d times", got)
+	}
+
+	// Releasing more than once has no effect.
+	release1()
+	release1()
+	if got := l.sessions["user/alice"]; got != 1 {
+		t.Errorf("user has %d sessions, want 1", got)
+	}
+	if _, err := l.acquireSession("bob", ip1); err != nil {
+		t.Errorf("acquireSession() failed after release: %v", err)
+	}
+	release2()
+
+	// Underlays are not limited.
+	for i := 0; i < 10; i++ {
+		if _, err := l.acquireUnderlay("alice", ip1); err != nil {
+			t.Fatalf("acquireUnderlay() failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultDeadPeerTimeout is the default time without receiving any
+	// segment before a UDP session considers the peer dead. The peer sends
+	// a heartbeat every sessionHeartbeatInterval if there is nothing else
+	// to send, so a few heartbeats need to be lost.
+	defaultDeadPeerTimeout = 4 * sessionHeartbeatInterval
+
+	// minDeadPeerTimeout is the minimum dead peer timeout.
+	minDeadPeerTimeout = 2 * sessionHeartbeatInterval
+)
+
+// deadPeerTimeout is the dead peer timeout of UDP sessions,
+// in nanoseconds. 0 means the default.
+var deadPeerTimeout atomic.Int64
+
+// SetDeadPeerTimeout changes the time without receiving any segment
+// before a UDP session is closed because the peer is considered dead.
+// A zero value restores the default.
+func SetDeadPeerTimeout(timeout time.Duration) error {
+	if timeout != 0 && timeout < minDeadPeerTimeout {
+		return fmt.Errorf("dead peer timeout %v is smaller than %v", timeout, minDeadPeerTimeout)
+	}
+	deadPeerTimeout.Store(int64(timeout))
+	return nil
+}
+
+// DeadPeerTimeout returns the dead peer timeout of UDP sessions.
+func DeadPeerTimeout() time.Duration {
+	if timeout := deadPeerTimeout.Load(); timeout != 0 {
+		return time.Duration(timeout)
+	}
+	return defaultDeadPeerTimeout
+}
+
+// checkDeadPeer returns an error if nothing is received from the peer
+// within the dead peer timeout. This includes the heartbeats the peer
+// sends when the session is idle, so a silently dead underlay is detected
+// quickly even if the application is not sending anything.
+func (s *Session) checkDeadPeer(now time.Time) error {
+	if silence := now.Sub(time.Unix(0, s.lastRXTime.Load())); silence > DeadPeerTimeout() {
+		return fmt.Errorf("nothing is received from the peer in %v", silence.Truncate(time.Millisecond))
+	}
+	return nil
+}
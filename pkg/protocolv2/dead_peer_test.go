@@ -0,0 +1,51 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckDeadPeer(t *testing.T) {
+	defer deadPeerTimeout.Store(0)
+
+	if err := SetDeadPeerTimeout(time.Second); err == nil {
+		t.Errorf("SetDeadPeerTimeout() with 1 second succeeded, want error")
+	}
+
+	s := NewSession(1, true, 1500)
+	now := time.Now()
+	if err := s.checkDeadPeer(now.Add(defaultDeadPeerTimeout - time.Second)); err != nil {
+		t.Errorf("checkDeadPeer() before the default timeout failed: %v", err)
+	}
+	if err := s.checkDeadPeer(now.Add(defaultDeadPeerTimeout + time.Second)); err == nil {
+		t.Errorf("checkDeadPeer() after the default timeout succeeded, want error")
+	}
+
+	if err := SetDeadPeerTimeout(time.Minute); err != nil {
+		t.Fatalf("SetDeadPeerTimeout() failed: %v", err)
+	}
+	if err := s.checkDeadPeer(now.Add(defaultDeadPeerTimeout + time.Second)); err != nil {
+		t.Errorf("checkDeadPeer() before the timeout failed: %v", err)
+	}
+
+	// Receiving a segment resets the timer.
+	s.lastRXTime.Store(now.Add(time.Minute).UnixNano())
+	if err := s.checkDeadPeer(now.Add(90 * time.Second)); err != nil {
+		t.Errorf("checkDeadPeer() after receiving a segment failed: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/stderror"
+)
+
+// listenerKey returns the key of a server endpoint, which is
+// the network and the local address.
+func listenerKey(p UnderlayProperties) string {
+	return p.LocalAddr().Network() + " " + p.LocalAddr().String()
+}
+
+// newEndpointContext returns the context to listen to a server endpoint.
+// The context is canceled when the endpoint is removed or the mux is closed.
+// It must be called with the mux lock held.
+func (m *Mux) newEndpointContext(p UnderlayProperties) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.listeners[listenerKey(p)] = cancel
+	return ctx
+}
+
+// AddEndpoint starts to listen to a new endpoint of a started server mux.
+// Unlike SetEndpoints, it returns an error if it is unable to listen.
+func (m *Mux) AddEndpoint(p UnderlayProperties) error {
+	if m.isClient {
+		return stderror.ErrInvalidOperation
+	}
+	key := listenerKey(p)
+	m.mu.Lock()
+	select {
+	case <-m.done:
+		m.mu.Unlock()
+		return fmt.Errorf("multiplexer is closed")
+	default:
+	}
+	if !m.used {
+		m.mu.Unlock()
+		return fmt.Errorf("multiplexer is not started")
+	}
+	if _, found := m.listeners[key]; found {
+		m.mu.Unlock()
+		return fmt.Errorf("endpoint %s already exists", key)
+	}
+	ctx := m.newEndpointContext(p)
+	m.mu.Unlock()
+
+	// The lock can't be held, because it is used by the accept loop.
+	started := make(chan error, 1)
+	go m.acceptUnderlayLoop(ctx, p, started)
+	err := <-started
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		if cancel, found := m.listeners[key]; found {
+			cancel()
+			delete(m.listeners, key)
+		}
+		return err
+	}
+	m.endpoints = append(m.endpoints, p)
+	return nil
+}
+
+// RemoveEndpoint stops listening to an endpoint of a server mux.
+// The underlays accepted from the endpoint are closed.
+func (m *Mux) RemoveEndpoint(p UnderlayProperties) error {
+	if m.isClient {
+		return stderror.ErrInvalidOperation
+	}
+	key := listenerKey(p)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	found := false
+	endpoints := make([]UnderlayProperties, 0, len(m.endpoints))
+	for _, e := range m.endpoints {
+		if listenerKey(e) == key {
+			found = true
+			continue
+		}
+		endpoints = append(endpoints, e)
+	}
+	if !found {
+		return fmt.Errorf("endpoint %s is not found", key)
+	}
+	m.endpoints = endpoints
+	if cancel, ok := m.listeners[key]; ok {
+		cancel()
+		delete(m.listeners, key)
+	}
+	log.Infof("Removed endpoint %s", key)
+	return nil
+}
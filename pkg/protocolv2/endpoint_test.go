@@ -0,0 +1,85 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/util"
+)
+
+func TestAddRemoveEndpoint(t *testing.T) {
+	ports := make([]int, 2)
+	for i := range ports {
+		port, err := util.UnusedTCPPort()
+		if err != nil {
+			t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+		}
+		ports[i] = port
+	}
+	properties := make([]UnderlayProperties, 2)
+	for i, port := range ports {
+		properties[i] = NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil)
+	}
+	serverMux := NewMux(false).
+		SetServerUsers(users).
+		SetEndpoints([]UnderlayProperties{properties[0]})
+	defer serverMux.Close()
+	if err := serverMux.AddEndpoint(properties[1]); err == nil {
+		t.Errorf("AddEndpoint() succeeded before the mux is started")
+	}
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	if err := serverMux.AddEndpoint(properties[1]); err != nil {
+		t.Fatalf("AddEndpoint() failed: %v", err)
+	}
+	if err := serverMux.AddEndpoint(properties[1]); err == nil {
+		t.Errorf("AddEndpoint() succeeded with an existing endpoint")
+	}
+	addr := "127.0.0.1:" + strconv.Itoa(ports[1])
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("net.Dial() to added endpoint failed: %v", err)
+	}
+	conn.Close()
+
+	if err := serverMux.RemoveEndpoint(properties[1]); err != nil {
+		t.Fatalf("RemoveEndpoint() failed: %v", err)
+	}
+	if err := serverMux.RemoveEndpoint(properties[1]); err == nil {
+		t.Errorf("RemoveEndpoint() succeeded with a removed endpoint")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatalf("removed endpoint is still listening")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := len(serverMux.endpoints); got != 1 {
+		t.Errorf("got %d endpoints, want 1", got)
+	}
+}
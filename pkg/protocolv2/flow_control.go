@@ -0,0 +1,121 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/enfein/mieru/pkg/congestion"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/mathext"
+)
+
+const (
+	// initialRecvWindowSize is the receive window size of a new session
+	// if the receive window is auto-tuned.
+	initialRecvWindowSize = 256
+)
+
+// FlowControlConfig holds the flow control parameters of UDP sessions.
+// Window sizes are in number of segments. A zero value field uses the default.
+type FlowControlConfig struct {
+	// SendWindowSize is the maximum congestion window size.
+	SendWindowSize int
+
+	// ReceiveWindowSize is the maximum receive window size.
+	ReceiveWindowSize int
+
+	// DisableAutoTuning uses a fixed receive window of ReceiveWindowSize.
+	// Otherwise, the receive window starts small and grows when the
+	// remote sends data fast enough to fill it within a round trip.
+	DisableAutoTuning bool
+}
+
+// flowControlConfig is used by new sessions.
+var flowControlConfig atomic.Pointer[FlowControlConfig]
+
+// SetFlowControlConfig changes the flow control parameters of new sessions.
+// Existing sessions are not impacted.
+func SetFlowControlConfig(config FlowControlConfig) error {
+	for name, size := range map[string]int{
+		"send window":    config.SendWindowSize,
+		"receive window": config.ReceiveWindowSize,
+	} {
+		if size != 0 && (size < minWindowSize || size > maxWindowSize) {
+			return fmt.Errorf("%s size %d is not between %d and %d", name, size, minWindowSize, maxWindowSize)
+		}
+	}
+	flowControlConfig.Store(&config)
+	return nil
+}
+
+// applyFlowControlConfig sets the flow control parameters of the session.
+func (s *Session) applyFlowControlConfig() {
+	sendWindow := maxWindowSize
+	s.maxRecvWindow = maxWindowSize
+	s.recvAutoTune = true
+	if config := flowControlConfig.Load(); config != nil {
+		if config.SendWindowSize != 0 {
+			sendWindow = config.SendWindowSize
+		}
+		if config.ReceiveWindowSize != 0 {
+			s.maxRecvWindow = config.ReceiveWindowSize
+		}
+		s.recvAutoTune = !config.DisableAutoTuning
+	}
+	s.sendAlgorithm = congestion.NewCubicSendAlgorithm(minWindowSize, uint32(sendWindow))
+	if s.recvAutoTune {
+		s.recvWindow.Store(int32(mathext.Min(initialRecvWindowSize, s.maxRecvWindow)))
+	} else {
+		s.recvWindow.Store(int32(s.maxRecvWindow))
+	}
+	s.recvEpochStart = time.Now()
+}
+
+// receiveWindowSize returns the number of segments the session is able to
+// receive, which is advertised to the remote.
+func (s *Session) receiveWindowSize() uint16 {
+	return uint16(mathext.Max(0, int(s.recvWindow.Load())-s.recvBuf.Len()))
+}
+
+// onSegmentReceived grows the receive window if the remote delivered
+// at least half of the window within a round trip. It must be called
+// from the input loop when a segment is moved to recvQueue.
+func (s *Session) onSegmentReceived() {
+	if !s.recvAutoTune {
+		return
+	}
+	s.recvEpochCount++
+	rtt := s.rttStat.SmoothedRTT()
+	if rtt == 0 {
+		rtt = s.rttStat.RTO()
+	}
+	if time.Since(s.recvEpochStart) < rtt {
+		return
+	}
+	window := int(s.recvWindow.Load())
+	if s.recvEpochCount*2 >= window && window < s.maxRecvWindow {
+		newWindow := mathext.Min(window*2, s.maxRecvWindow)
+		s.recvWindow.Store(int32(newWindow))
+		if log.IsLevelEnabled(log.TraceLevel) {
+			log.Tracef("%v receive window is increased from %d to %d", s, window, newWindow)
+		}
+	}
+	s.recvEpochStart = time.Now()
+	s.recvEpochCount = 0
+}
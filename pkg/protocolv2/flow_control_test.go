@@ -0,0 +1,82 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetFlowControlConfig(t *testing.T) {
+	defer flowControlConfig.Store(nil)
+
+	if err := SetFlowControlConfig(FlowControlConfig{SendWindowSize: 8}); err == nil {
+		t.Errorf("SetFlowControlConfig() with send window size 8 succeeded, want error")
+	}
+	if err := SetFlowControlConfig(FlowControlConfig{ReceiveWindowSize: maxWindowSize + 1}); err == nil {
+		t.Errorf("SetFlowControlConfig() with receive window size %d succeeded, want error", maxWindowSize+1)
+	}
+
+	s := NewSession(1, true, 1500)
+	if got := s.receiveWindowSize(); got != initialRecvWindowSize {
+		t.Errorf("default receiveWindowSize() = %d, want %d", got, initialRecvWindowSize)
+	}
+
+	if err := SetFlowControlConfig(FlowControlConfig{ReceiveWindowSize: 1000, DisableAutoTuning: true}); err != nil {
+		t.Fatalf("SetFlowControlConfig() failed: %v", err)
+	}
+	s = NewSession(2, true, 1500)
+	if got := s.receiveWindowSize(); got != 1000 {
+		t.Errorf("receiveWindowSize() = %d, want %d", got, 1000)
+	}
+}
+
+func TestReceiveWindowAutoTuning(t *testing.T) {
+	defer flowControlConfig.Store(nil)
+
+	if err := SetFlowControlConfig(FlowControlConfig{ReceiveWindowSize: 600}); err != nil {
+		t.Fatalf("SetFlowControlConfig() failed: %v", err)
+	}
+	s := NewSession(1, true, 1500)
+	s.rttStat.UpdateRTT(time.Second)
+
+	// The window doesn't grow if the remote is slow.
+	s.recvEpochStart = time.Now().Add(-2 * time.Second)
+	s.onSegmentReceived()
+	if got := s.recvWindow.Load(); got != initialRecvWindowSize {
+		t.Fatalf("receive window = %d, want %d", got, initialRecvWindowSize)
+	}
+
+	// The window grows if half of the window is received within a round trip.
+	for i := 0; i < initialRecvWindowSize/2; i++ {
+		s.onSegmentReceived()
+	}
+	s.recvEpochStart = time.Now().Add(-2 * time.Second)
+	s.onSegmentReceived()
+	if got := s.recvWindow.Load(); got != 2*initialRecvWindowSize {
+		t.Fatalf("receive window = %d, want %d", got, 2*initialRecvWindowSize)
+	}
+
+	// The window doesn't exceed the maximum.
+	for i := 0; i < initialRecvWindowSize; i++ {
+		s.onSegmentReceived()
+	}
+	s.recvEpochStart = time.Now().Add(-2 * time.Second)
+	s.onSegmentReceived()
+	if got := s.recvWindow.Load(); got != 600 {
+		t.Errorf("receive window = %d, want %d", got, 600)
+	}
+}
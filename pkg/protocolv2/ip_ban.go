@@ -0,0 +1,221 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+// maxTrackedIPs is the maximum number of source IP addresses tracked
+// by the ban list. It limits the memory used when the server is scanned
+// from many addresses.
+const maxTrackedIPs = 65536
+
+// IPBanConfig controls the automatic banning of source IP addresses
+// that fail to decrypt or handshake with the server.
+type IPBanConfig struct {
+	// MaxFailures is the number of failures within FindTime that bans
+	// the source IP address. A zero value disables banning.
+	MaxFailures int
+
+	// FindTime is the time window to count the failures.
+	FindTime time.Duration
+
+	// BanTime is how long the source IP address is banned.
+	BanTime time.Duration
+}
+
+// BannedIP describes a source IP address that is banned.
+type BannedIP struct {
+	IP       string
+	Failures int
+	Until    time.Time
+}
+
+// ipBanEntry tracks the failures of one source IP address.
+type ipBanEntry struct {
+	failures    int
+	windowStart time.Time
+	bannedUntil time.Time
+}
+
+// ipBanList bans source IP addresses with too many failures, and the
+// IP ranges in the blocklist. Packets and connections from a banned
+// address are dropped silently.
+type ipBanList struct {
+	mu        sync.Mutex
+	config    IPBanConfig
+	entries   map[string]*ipBanEntry
+	blocklist []*net.IPNet
+}
+
+// serverIPBans is the ban list shared by all the server underlays.
+var serverIPBans = &ipBanList{entries: make(map[string]*ipBanEntry)}
+
+// SetIPBanConfig changes the automatic banning of source IP addresses.
+// Existing bans are kept until they expire.
+func SetIPBanConfig(config IPBanConfig) error {
+	if config.MaxFailures < 0 {
+		return fmt.Errorf("IP ban max failures %d is negative", config.MaxFailures)
+	}
+	if config.MaxFailures > 0 && (config.FindTime <= 0 || config.BanTime <= 0) {
+		return fmt.Errorf("IP ban find time %v and ban time %v must be positive", config.FindTime, config.BanTime)
+	}
+	serverIPBans.mu.Lock()
+	serverIPBans.config = config
+	serverIPBans.mu.Unlock()
+	return nil
+}
+
+// SetIPBlocklist replaces the IP ranges that are always banned.
+// Each item is an IP range in CIDR format or a single IP address.
+func SetIPBlocklist(ipRanges []string) error {
+	blocklist := make([]*net.IPNet, 0, len(ipRanges))
+	for _, ipRange := range ipRanges {
+		ipNet, err := util.ParseIPRange(ipRange)
+		if err != nil {
+			return fmt.Errorf("invalid IP blocklist: %w", err)
+		}
+		blocklist = append(blocklist, ipNet)
+	}
+	serverIPBans.mu.Lock()
+	serverIPBans.blocklist = blocklist
+	serverIPBans.mu.Unlock()
+	return nil
+}
+
+// BannedIPs returns the source IP addresses that are currently banned,
+// sorted by the time the ban expires.
+func BannedIPs() []BannedIP {
+	return serverIPBans.banned(time.Now())
+}
+
+// UnbanIP removes the ban of the source IP address. It returns false if
+// the IP address is not banned.
+func UnbanIP(ip net.IP) bool {
+	return serverIPBans.unban(ip, time.Now())
+}
+
+// isBanned returns true if the source IP address is banned
+// or in the blocklist.
+func (l *ipBanList) isBanned(ip net.IP, now time.Time) bool {
+	if ip == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, ipNet := range l.blocklist {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	entry, ok := l.entries[ip.String()]
+	return ok && now.Before(entry.bannedUntil)
+}
+
+// recordFailure counts a failed decryption or handshake from the source
+// IP address, and bans the address if it has too many failures.
+// Only failures of TCP underlays are counted, because the TCP handshake
+// proves the source address. The source address of a UDP packet can be
+// spoofed to get a legitimate client banned.
+func (l *ipBanList) recordFailure(ip net.IP, now time.Time) {
+	if ip == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.config.MaxFailures <= 0 {
+		return
+	}
+	key := ip.String()
+	entry, ok := l.entries[key]
+	if !ok {
+		if len(l.entries) >= maxTrackedIPs {
+			l.evictLocked(now)
+			if len(l.entries) >= maxTrackedIPs {
+				return
+			}
+		}
+		entry = &ipBanEntry{windowStart: now}
+		l.entries[key] = entry
+	}
+	if now.Before(entry.bannedUntil) {
+		return
+	}
+	if now.Sub(entry.windowStart) > l.config.FindTime {
+		entry.failures = 0
+		entry.windowStart = now
+	}
+	entry.failures++
+	if entry.failures >= l.config.MaxFailures {
+		entry.bannedUntil = now.Add(l.config.BanTime)
+		UnderlayIPBans.Add(1)
+		log.Infof("Banned source IP %s for %v after %d failed attempts", key, l.config.BanTime, entry.failures)
+	}
+}
+
+// banned returns the banned source IP addresses.
+func (l *ipBanList) banned(now time.Time) []BannedIP {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var res []BannedIP
+	for key, entry := range l.entries {
+		if now.Before(entry.bannedUntil) {
+			res = append(res, BannedIP{
+				IP:       key,
+				Failures: entry.failures,
+				Until:    entry.bannedUntil,
+			})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Until.Before(res[j].Until)
+	})
+	return res
+}
+
+// unban removes the ban and the failure count of the source IP address.
+func (l *ipBanList) unban(ip net.IP, now time.Time) bool {
+	if ip == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := ip.String()
+	entry, ok := l.entries[key]
+	if !ok {
+		return false
+	}
+	delete(l.entries, key)
+	return now.Before(entry.bannedUntil)
+}
+
+// evictLocked removes the entries that are neither banned nor within
+// the find time. This method MUST be called when holding the mu lock.
+func (l *ipBanList) evictLocked(now time.Time) {
+	for key, entry := range l.entries {
+		if !now.Before(entry.bannedUntil) && now.Sub(entry.windowStart) > l.config.FindTime {
+			delete(l.entries, key)
+		}
+	}
+}
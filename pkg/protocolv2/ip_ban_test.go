@@ -0,0 +1,214 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/replay"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestIPBanList(t *testing.T) {
+	l := &ipBanList{
+		config: IPBanConfig{
+			MaxFailures: 3,
+			FindTime:    time.Minute,
+			BanTime:     time.Hour,
+		},
+		entries: make(map[string]*ipBanEntry),
+	}
+	ip := net.ParseIP("203.0.113.1")
+	other := net.ParseIP("203.0.113.2")
+	now := time.Now()
+
+	l.recordFailure(ip, now)
+	l.recordFailure(ip, now.Add(time.Second))
+	if l.isBanned(ip, now.Add(2*time.Second)) {
+		t.Fatalf("IP is banned after 2 failures")
+	}
+	l.recordFailure(ip, now.Add(2*time.Second))
+	if !l.isBanned(ip, now.Add(3*time.Second)) {
+		t.Fatalf("IP is not banned after 3 failures")
+	}
+	if l.isBanned(other, now.Add(3*time.Second)) {
+		t.Errorf("other IP is banned")
+	}
+	banned := l.banned(now.Add(3 * time.Second))
+	if len(banned) != 1 || banned[0].IP != ip.String() || banned[0].Failures != 3 {
+		t.Errorf("banned() = %v, want %s with 3 failures", banned, ip)
+	}
+	if l.isBanned(ip, now.Add(2*time.Hour)) {
+		t.Errorf("IP is still banned after ban time")
+	}
+
+	if !l.unban(ip, now.Add(4*time.Second)) {
+		t.Errorf("unban() = false, want true")
+	}
+	if l.isBanned(ip, now.Add(5*time.Second)) {
+		t.Errorf("IP is banned after unban")
+	}
+	if l.unban(ip, now.Add(6*time.Second)) {
+		t.Errorf("unban() of IP that is not banned = true, want false")
+	}
+}
+
+func TestIPBanListFindTime(t *testing.T) {
+	l := &ipBanList{
+		config: IPBanConfig{
+			MaxFailures: 2,
+			FindTime:    time.Minute,
+			BanTime:     time.Hour,
+		},
+		entries: make(map[string]*ipBanEntry),
+	}
+	ip := net.ParseIP("2001:db8::1")
+	now := time.Now()
+	l.recordFailure(ip, now)
+	l.recordFailure(ip, now.Add(2*time.Minute))
+	if l.isBanned(ip, now.Add(2*time.Minute)) {
+		t.Errorf("IP is banned with failures outside of find time")
+	}
+	l.recordFailure(ip, now.Add(2*time.Minute+time.Second))
+	if !l.isBanned(ip, now.Add(2*time.Minute+time.Second)) {
+		t.Errorf("IP is not banned with 2 failures within find time")
+	}
+}
+
+func TestIPBanListDisabled(t *testing.T) {
+	l := &ipBanList{entries: make(map[string]*ipBanEntry)}
+	ip := net.ParseIP("203.0.113.1")
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		l.recordFailure(ip, now)
+	}
+	if l.isBanned(ip, now) {
+		t.Errorf("IP is banned when banning is disabled")
+	}
+	if len(l.entries) != 0 {
+		t.Errorf("got %d entries when banning is disabled, want 0", len(l.entries))
+	}
+}
+
+func TestIPBlocklist(t *testing.T) {
+	if err := SetIPBlocklist([]string{"198.51.100.0/24", "2001:db8::1"}); err != nil {
+		t.Fatalf("SetIPBlocklist() failed: %v", err)
+	}
+	defer SetIPBlocklist(nil)
+	now := time.Now()
+	for _, tc := range []struct {
+		ip     string
+		banned bool
+	}{
+		{"198.51.100.7", true},
+		{"198.51.101.7", false},
+		{"2001:db8::1", true},
+		{"2001:db8::2", false},
+	} {
+		if got := serverIPBans.isBanned(net.ParseIP(tc.ip), now); got != tc.banned {
+			t.Errorf("isBanned(%s) = %v, want %v", tc.ip, got, tc.banned)
+		}
+	}
+	if err := SetIPBlocklist([]string{"scanner"}); err == nil {
+		t.Errorf("SetIPBlocklist() with invalid IP range succeeded")
+	}
+}
+
+func TestSetIPBanConfig(t *testing.T) {
+	if err := SetIPBanConfig(IPBanConfig{MaxFailures: -1}); err == nil {
+		t.Errorf("SetIPBanConfig() with negative max failures succeeded")
+	}
+	if err := SetIPBanConfig(IPBanConfig{MaxFailures: 5}); err == nil {
+		t.Errorf("SetIPBanConfig() without find time and ban time succeeded")
+	}
+	if err := SetIPBanConfig(IPBanConfig{}); err != nil {
+		t.Errorf("SetIPBanConfig() failed: %v", err)
+	}
+}
+
+func TestUDPFailuresNotBanned(t *testing.T) {
+	if err := SetIPBanConfig(IPBanConfig{MaxFailures: 1, FindTime: time.Minute, BanTime: time.Hour}); err != nil {
+		t.Fatalf("SetIPBanConfig() failed: %v", err)
+	}
+	defer SetIPBanConfig(IPBanConfig{})
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("net.ListenUDP() failed: %v", err)
+	}
+	wrappedConn, err := wrapUDPConn(conn, MimicryPlain, false)
+	if err != nil {
+		t.Fatalf("wrapUDPConn() failed: %v", err)
+	}
+	u := &UDPUnderlay{
+		baseUnderlay:      *newBaseUnderlay(false, 1500, MimicryPlain),
+		conn:              wrappedConn,
+		idleSessionTicker: time.NewTicker(idleSessionTickerInterval),
+		users: map[string]*appctlpb.User{
+			"user": {
+				Name:     proto.String("user"),
+				Password: proto.String("password"),
+			},
+		},
+	}
+	readDone := make(chan struct{})
+	go func() {
+		u.readOneSegment()
+		close(readDone)
+	}()
+
+	packet := make([]byte, 256)
+	rand.Read(packet)
+	failedDecrypt := cipher.ServerFailedIterateDecrypt.Load()
+	replays := replay.NewSession.Load()
+	bannedDrops := UnderlayBannedDrops.Load()
+
+	// The packet can't be decrypted. When it is sent again from another
+	// source address, it is a replay.
+	var clientIP net.IP
+	for i := 0; i < 2; i++ {
+		client, err := net.DialUDP("udp4", nil, conn.LocalAddr().(*net.UDPAddr))
+		if err != nil {
+			t.Fatalf("net.DialUDP() failed: %v", err)
+		}
+		defer client.Close()
+		clientIP = client.LocalAddr().(*net.UDPAddr).IP
+		if _, err := client.Write(packet); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for cipher.ServerFailedIterateDecrypt.Load() == failedDecrypt || (replay.NewSession.Load() == replays && UnderlayBannedDrops.Load() == bannedDrops) {
+		if time.Now().After(deadline) {
+			t.Fatalf("UDP packets are not processed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	u.Close()
+	<-readDone
+
+	if serverIPBans.isBanned(clientIP, time.Now()) {
+		t.Errorf("source IP address is banned by UDP failures")
+	}
+	if banned := BannedIPs(); len(banned) != 0 {
+		t.Errorf("BannedIPs() = %v, want none", banned)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+)
+
+const (
+	// keepaliveCheckInterval is how often a client TCP underlay checks
+	// the liveness of the server.
+	keepaliveCheckInterval = time.Second
+
+	// keepaliveIdleTime is the time without receiving anything from the
+	// server before a client TCP underlay sends a keepalive request.
+	keepaliveIdleTime = 5 * time.Second
+
+	// keepaliveTimeout is the maximum time to wait for any data from the
+	// server after a keepalive request is sent.
+	keepaliveTimeout = 5 * time.Second
+)
+
+// errDeadPeer is returned if the server doesn't answer keepalive request.
+var errDeadPeer = errors.New("no response to keepalive request")
+
+// runKeepalive checks the liveness of the server until the underlay
+// is closed. If the server is dead, the underlay is closed so sessions
+// can be established with a new underlay.
+func (t *TCPUnderlay) runKeepalive(ctx context.Context) {
+	ticker := time.NewTicker(keepaliveCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.done:
+			return
+		case <-ticker.C:
+			if err := t.checkKeepalive(time.Now()); err != nil {
+				if errors.Is(err, errDeadPeer) {
+					UnderlayDeadPeers.Add(1)
+					log.Debugf("%v detected dead peer: %v", t, err)
+				} else {
+					log.Debugf("%v checkKeepalive() failed: %v", t, err)
+				}
+				t.Close()
+				return
+			}
+		}
+	}
+}
+
+// checkKeepalive sends a keepalive request if the underlay has been idle,
+// and returns errDeadPeer if a pending request is not answered in time.
+// Keepalive is only used if the server supports it and the underlay
+// carries sessions.
+func (t *TCPUnderlay) checkKeepalive(now time.Time) error {
+	if !t.peerKeepalive.Load() || !t.hasSessions() {
+		return nil
+	}
+	if sent := t.keepaliveSentTime.Load(); sent != 0 {
+		if now.Sub(time.Unix(0, sent)) > keepaliveTimeout {
+			return errDeadPeer
+		}
+		return nil
+	}
+	if now.Sub(time.Unix(0, t.lastRecvTime.Load())) < keepaliveIdleTime {
+		return nil
+	}
+	t.keepaliveSentTime.Store(now.UnixNano())
+	return t.writeOneSegment(&segment{
+		metadata: &sessionStruct{
+			baseStruct: baseStruct{
+				protocol: uint8(keepaliveRequest),
+			},
+			seq: t.keepaliveSeq.Add(1),
+		},
+		transport: t.TransportProtocol(),
+	})
+}
+
+// onKeepaliveRequest answers the keepalive request from the client.
+func (t *TCPUnderlay) onKeepaliveRequest(seg *segment) error {
+	if t.isClient {
+		log.Debugf("%v ignored keepalive request sent by server", t)
+		return nil
+	}
+	return t.writeOneSegment(&segment{
+		metadata: &sessionStruct{
+			baseStruct: baseStruct{
+				protocol: uint8(keepaliveResponse),
+			},
+			seq: seg.metadata.(*sessionStruct).seq,
+		},
+		transport: t.TransportProtocol(),
+	})
+}
+
+// hasSessions returns true if at least one session is attached.
+func (t *TCPUnderlay) hasSessions() bool {
+	return t.sessionMap.Len() > 0
+}
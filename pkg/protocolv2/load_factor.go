@@ -0,0 +1,140 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	mrand "math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/enfein/mieru/pkg/metrics"
+)
+
+const (
+	// maxLoadFactor is the load factor of a server that is full.
+	maxLoadFactor = 100
+
+	// loadFactorTTL is how long a load factor reported by a server
+	// is used in endpoint selection.
+	loadFactorTTL = 5 * time.Minute
+
+	// unknownLoadWeight is the weight of an endpoint without a recent
+	// load factor. It is the same as a server that is half full.
+	unknownLoadWeight = (maxLoadFactor + 1) - maxLoadFactor/2
+)
+
+// serverSessionCapacity is the number of sessions the server is able to
+// handle. The server doesn't advertise load factor if it is 0.
+var serverSessionCapacity atomic.Int64
+
+// serverLoadFactor returns the current load factor of the server in
+// percent, and whether the server advertises load factor.
+func serverLoadFactor() (uint8, bool) {
+	capacity := serverSessionCapacity.Load()
+	if capacity <= 0 {
+		return 0, false
+	}
+	sessions := metrics.CurrEstablished.Load()
+	if sessions < 0 {
+		sessions = 0
+	}
+	load := sessions * maxLoadFactor / capacity
+	if load > maxLoadFactor {
+		load = maxLoadFactor
+	}
+	return uint8(load), true
+}
+
+// clientEndpointLoads records the load factor reported by each endpoint.
+var clientEndpointLoads = newEndpointLoadTracker()
+
+type endpointLoad struct {
+	loadFactor uint8
+	updated    time.Time
+}
+
+// endpointLoadTracker remembers the latest load factor reported by servers.
+// The client uses it to prefer less busy servers when opening a new underlay.
+type endpointLoadTracker struct {
+	mu    sync.Mutex
+	loads map[string]endpointLoad
+}
+
+func newEndpointLoadTracker() *endpointLoadTracker {
+	return &endpointLoadTracker{
+		loads: make(map[string]endpointLoad),
+	}
+}
+
+// record saves the load factor reported by the endpoint.
+func (e *endpointLoadTracker) record(endpoint string, loadFactor uint8, now time.Time) {
+	if endpoint == "" {
+		return
+	}
+	if loadFactor > maxLoadFactor {
+		loadFactor = maxLoadFactor
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.loads[endpoint] = endpointLoad{loadFactor: loadFactor, updated: now}
+}
+
+// weight returns the selection weight of the endpoint.
+// A server with lower load factor has higher weight.
+func (e *endpointLoadTracker) weight(endpoint string, now time.Time) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	l, ok := e.loads[endpoint]
+	if !ok || now.Sub(l.updated) > loadFactorTTL {
+		return unknownLoadWeight
+	}
+	return (maxLoadFactor + 1) - int(l.loadFactor)
+}
+
+// pick returns the index of an endpoint chosen by weighted random selection.
+func (e *endpointLoadTracker) pick(endpoints []UnderlayProperties, now time.Time) int {
+	if len(endpoints) <= 1 {
+		return 0
+	}
+	weights := make([]int, len(endpoints))
+	total := 0
+	for i, p := range endpoints {
+		weights[i] = e.weight(endpointKey(p), now)
+		total += weights[i]
+	}
+	n := mrand.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return i
+		}
+		n -= w
+	}
+	return len(endpoints) - 1
+}
+
+// endpointKey returns the key of the endpoint in the load tracker.
+func endpointKey(p UnderlayProperties) string {
+	return p.RemoteAddr().Network() + "/" + p.RemoteAddr().String()
+}
+
+// recordLoadFactor saves the load factor attached to the open session response.
+func (b *baseUnderlay) recordLoadFactor(ss *sessionStruct) {
+	if !b.isClient || ss.flags&flagLoadFactor == 0 {
+		return
+	}
+	clientEndpointLoads.record(b.endpoint, ss.loadFactor, time.Now())
+}
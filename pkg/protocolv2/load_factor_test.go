@@ -0,0 +1,76 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/metrics"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+func TestServerLoadFactor(t *testing.T) {
+	defer serverSessionCapacity.Store(0)
+	defer metrics.CurrEstablished.Store(metrics.CurrEstablished.Load())
+
+	serverSessionCapacity.Store(0)
+	if _, ok := serverLoadFactor(); ok {
+		t.Errorf("serverLoadFactor() is advertised without capacity")
+	}
+
+	serverSessionCapacity.Store(200)
+	metrics.CurrEstablished.Store(50)
+	if lf, ok := serverLoadFactor(); !ok || lf != 25 {
+		t.Errorf("serverLoadFactor() = %d, %v, want 25, true", lf, ok)
+	}
+	metrics.CurrEstablished.Store(500)
+	if lf, ok := serverLoadFactor(); !ok || lf != maxLoadFactor {
+		t.Errorf("serverLoadFactor() = %d, %v, want %d, true", lf, ok, maxLoadFactor)
+	}
+}
+
+func TestEndpointLoadTrackerPick(t *testing.T) {
+	endpoints := []UnderlayProperties{
+		NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, nil, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8964}),
+		NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, nil, &net.TCPAddr{IP: net.ParseIP("127.0.0.2"), Port: 8964}),
+	}
+	e := newEndpointLoadTracker()
+	now := time.Now()
+
+	// Without load factor, both endpoints have the same weight.
+	if w0, w1 := e.weight(endpointKey(endpoints[0]), now), e.weight(endpointKey(endpoints[1]), now); w0 != w1 {
+		t.Errorf("weights are %d and %d, want equal", w0, w1)
+	}
+
+	// A full server is rarely picked.
+	e.record(endpointKey(endpoints[0]), maxLoadFactor, now)
+	e.record(endpointKey(endpoints[1]), 0, now)
+	picks := [2]int{}
+	for i := 0; i < 1000; i++ {
+		picks[e.pick(endpoints, now)]++
+	}
+	if picks[0] >= picks[1]/10 {
+		t.Errorf("full server is picked %d times, idle server is picked %d times", picks[0], picks[1])
+	}
+
+	// Load factor expires.
+	later := now.Add(loadFactorTTL + time.Second)
+	if w := e.weight(endpointKey(endpoints[0]), later); w != unknownLoadWeight {
+		t.Errorf("weight after expiration = %d, want %d", w, unknownLoadWeight)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+)
+
+// hotPathLogInterval is the minimum interval between two log messages
+// written by the same call site in the segment read and write paths.
+const hotPathLogInterval = time.Second
+
+// Samplers of log messages written for each read, write or segment.
+// Without sampling, enabling debug or trace logging under load slows
+// down the proxy and fills the disk.
+var (
+	sessionReadStartLogSampler  = log.NewSampler(hotPathLogInterval)
+	sessionReadDoneLogSampler   = log.NewSampler(hotPathLogInterval)
+	sessionWriteStartLogSampler = log.NewSampler(hotPathLogInterval)
+	sessionWriteDoneLogSampler  = log.NewSampler(hotPathLogInterval)
+	sessionWriteRetryLogSampler = log.NewSampler(hotPathLogInterval)
+	segmentReplaceLogSampler    = log.NewSampler(hotPathLogInterval)
+	unknownProtocolLogSampler   = log.NewSampler(hotPathLogInterval)
+	tcpReceiveLogSampler        = log.NewSampler(hotPathLogInterval)
+	tcpSendLogSampler           = log.NewSampler(hotPathLogInterval)
+	udpReceiveLogSampler        = log.NewSampler(hotPathLogInterval)
+	udpIgnoreLogSampler         = log.NewSampler(hotPathLogInterval)
+	udpReadSegmentLogSampler    = log.NewSampler(hotPathLogInterval)
+	udpSendLogSampler           = log.NewSampler(hotPathLogInterval)
+
+	// udpInvalidPacketLogSampler is shared by all the messages about
+	// packets that can't be decrypted, which can be sent by anyone.
+	udpInvalidPacketLogSampler = log.NewSampler(hotPathLogInterval)
+
+	// connectionLimitLogSampler is shared by all the messages about
+	// sessions and underlays rejected by the connection limits.
+	connectionLimitLogSampler = log.NewSampler(hotPathLogInterval)
+)
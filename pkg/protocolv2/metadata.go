@@ -18,8 +18,8 @@ package protocolv2
 import (
 	"encoding/binary"
 	"fmt"
-	"time"
 
+	"github.com/enfein/mieru/pkg/cipher"
 	"github.com/enfein/mieru/pkg/mathext"
 )
 
@@ -36,6 +36,9 @@ const (
 	dataServerToClient   protocolType = 7
 	ackClientToServer    protocolType = 8
 	ackServerToClient    protocolType = 9
+	serverMessage        protocolType = 10
+	keepaliveRequest     protocolType = 11
+	keepaliveResponse    protocolType = 12
 )
 
 func (p protocolType) Equals(other byte) bool {
@@ -64,6 +67,12 @@ func (p protocolType) String() string {
 		return "ackClientToServer"
 	case ackServerToClient:
 		return "ackServerToClient"
+	case serverMessage:
+		return "serverMessage"
+	case keepaliveRequest:
+		return "keepaliveRequest"
+	case keepaliveResponse:
+		return "keepaliveResponse"
 	default:
 		return "UNKNOWN"
 	}
@@ -72,8 +81,10 @@ func (p protocolType) String() string {
 type statusCode byte
 
 const (
-	statusOK             statusCode = 0
-	statusQuotaExhausted statusCode = 1
+	statusOK                 statusCode = 0
+	statusQuotaExhausted     statusCode = 1
+	statusAccessWindowClosed statusCode = 2
+	statusConnectionLimit    statusCode = 3
 )
 
 func (c statusCode) String() string {
@@ -82,11 +93,54 @@ func (c statusCode) String() string {
 		return "OK"
 	case statusQuotaExhausted:
 		return "quotaExhausted"
+	case statusAccessWindowClosed:
+		return "accessWindowClosed"
+	case statusConnectionLimit:
+		return "connectionLimit"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+const (
+	// flagServerMessage is set in open session request if the client
+	// is able to receive server messages.
+	flagServerMessage uint8 = 1 << 0
+
+	// flagKeepalive is set in open session request if the client is able
+	// to send keepalive requests, and in open session response if the
+	// server is able to answer them.
+	flagKeepalive uint8 = 1 << 1
+
+	// flagLoadFactor is set in open session response if the server
+	// advertises its load factor.
+	flagLoadFactor uint8 = 1 << 2
+
+	// flagServerTime is set in open session request if the client wants
+	// to synchronize its clock with the server, and in open session
+	// response if the server time is attached.
+	flagServerTime uint8 = 1 << 3
+
+	// flagSelectiveAck is set in data and ack segments if selective
+	// acknowledgment blocks are attached.
+	flagSelectiveAck uint8 = 1 << 4
+
+	// flagVersion is set in open session request and open session response
+	// if the protocol version is attached.
+	flagVersion uint8 = 1 << 5
+)
+
+// maxSackBlocks is the maximum number of selective acknowledgment blocks
+// in data and ack segments.
+const maxSackBlocks = 2
+
+// sackBlock is a range of segments received after the unacknowledged
+// sequence number.
+type sackBlock struct {
+	offset uint16 // first sequence number of the range minus unAckSeq
+	length uint8  // number of segments in the range; 0 if the block is not used
+}
+
 const (
 	// Number of bytes used by metadata before encryption.
 	MetadataLength = 32
@@ -119,11 +173,11 @@ var (
 // baseStruct is shared by all metadata struct.
 type baseStruct struct {
 	protocol  uint8  // byte 0: protocol type
-	reserved  uint8  // byte 1: reserved
+	flags     uint8  // byte 1: feature flags
 	timestamp uint32 // byte 2 - 5: timestamp, number of minutes after UNIX epoch
 }
 
-// sessionStruct is used to open or close a session.
+// sessionStruct is used to open or close a session, or to deliver a server message.
 type sessionStruct struct {
 	baseStruct
 	sessionID  uint32 // byte 6 - 9: session ID number
@@ -131,6 +185,9 @@ type sessionStruct struct {
 	statusCode uint8  // byte 14: status of opening or closing session
 	payloadLen uint16 // byte 15 - 16: length of encapsulated payload, not including auth tag
 	suffixLen  uint8  // byte 17: length of suffix padding
+	loadFactor uint8  // byte 18: server load factor in percent, valid if flagLoadFactor is set
+	serverTime uint64 // byte 19 - 26: server time in milliseconds after UNIX epoch, valid if flagServerTime is set
+	version    uint8  // byte 27: protocol version, valid if flagVersion is set
 }
 
 func (ss *sessionStruct) Protocol() protocolType {
@@ -140,13 +197,17 @@ func (ss *sessionStruct) Protocol() protocolType {
 func (ss *sessionStruct) Marshal() []byte {
 	b := make([]byte, MetadataLength)
 	b[0] = ss.baseStruct.protocol
-	ss.baseStruct.timestamp = uint32(time.Now().Unix() / 60)
+	b[1] = ss.baseStruct.flags
+	ss.baseStruct.timestamp = uint32(cipher.Now().Unix() / 60)
 	binary.BigEndian.PutUint32(b[2:], ss.baseStruct.timestamp)
 	binary.BigEndian.PutUint32(b[6:], ss.sessionID)
 	binary.BigEndian.PutUint32(b[10:], ss.seq)
 	b[14] = ss.statusCode
 	binary.BigEndian.PutUint16(b[15:], ss.payloadLen)
 	b[17] = ss.suffixLen
+	b[18] = ss.loadFactor
+	binary.BigEndian.PutUint64(b[19:], ss.serverTime)
+	b[27] = ss.version
 	return b
 }
 
@@ -155,11 +216,11 @@ func (ss *sessionStruct) Unmarshal(b []byte) error {
 	if len(b) != MetadataLength {
 		return fmt.Errorf("input bytes: %d, want %d", len(b), MetadataLength)
 	}
-	if !openSessionRequest.Equals(b[0]) && !openSessionResponse.Equals(b[0]) && !closeSessionRequest.Equals(b[0]) && !closeSessionResponse.Equals(b[0]) {
+	if !openSessionRequest.Equals(b[0]) && !openSessionResponse.Equals(b[0]) && !closeSessionRequest.Equals(b[0]) && !closeSessionResponse.Equals(b[0]) && !serverMessage.Equals(b[0]) && !keepaliveRequest.Equals(b[0]) && !keepaliveResponse.Equals(b[0]) {
 		return fmt.Errorf("invalid protocol %d", b[0])
 	}
 	originalTimestamp := binary.BigEndian.Uint32(b[2:])
-	currentTimestamp := uint32(time.Now().Unix() / 60)
+	currentTimestamp := uint32(cipher.Now().Unix() / 60)
 	if !mathext.WithinRange(currentTimestamp, originalTimestamp, 1) {
 		return fmt.Errorf("invalid timestamp %d", originalTimestamp*60)
 	}
@@ -169,12 +230,16 @@ func (ss *sessionStruct) Unmarshal(b []byte) error {
 
 	// Do unmarshal.
 	ss.baseStruct.protocol = b[0]
+	ss.baseStruct.flags = b[1]
 	ss.baseStruct.timestamp = originalTimestamp
 	ss.sessionID = binary.BigEndian.Uint32(b[6:])
 	ss.seq = binary.BigEndian.Uint32(b[10:])
 	ss.statusCode = b[14]
 	ss.payloadLen = binary.BigEndian.Uint16(b[15:])
 	ss.suffixLen = b[17]
+	ss.loadFactor = b[18]
+	ss.serverTime = binary.BigEndian.Uint64(b[19:])
+	ss.version = b[27]
 	return nil
 }
 
@@ -183,7 +248,7 @@ func (ss *sessionStruct) String() string {
 }
 
 func isSessionProtocol(p protocolType) bool {
-	return p == openSessionRequest || p == openSessionResponse || p == closeSessionRequest || p == closeSessionResponse
+	return p == openSessionRequest || p == openSessionResponse || p == closeSessionRequest || p == closeSessionResponse || p == serverMessage || p == keepaliveRequest || p == keepaliveResponse
 }
 
 func toSessionStruct(m metadata) (*sessionStruct, bool) {
@@ -196,14 +261,15 @@ func toSessionStruct(m metadata) (*sessionStruct, bool) {
 // dataAckStruct is used by data and ack protocols.
 type dataAckStruct struct {
 	baseStruct
-	sessionID  uint32 // byte 6 - 9: session ID number
-	seq        uint32 // byte 10 - 13: sequence number or ack number
-	unAckSeq   uint32 // byte 14 - 17: unacknowledged sequence number; sequences smaller than this are acknowledged
-	windowSize uint16 // byte 18 - 19: receive window size, in number of segments
-	fragment   uint8  // byte 20: fragment number; this number reduces over time, 0 is the last fragment
-	prefixLen  uint8  // byte 21: length of prefix padding
-	payloadLen uint16 // byte 22 - 23: length of encapsulated payload, not including auth tag
-	suffixLen  uint8  // byte 24: length of suffix padding
+	sessionID  uint32                   // byte 6 - 9: session ID number
+	seq        uint32                   // byte 10 - 13: sequence number or ack number
+	unAckSeq   uint32                   // byte 14 - 17: unacknowledged sequence number; sequences smaller than this are acknowledged
+	windowSize uint16                   // byte 18 - 19: receive window size, in number of segments
+	fragment   uint8                    // byte 20: fragment number; this number reduces over time, 0 is the last fragment
+	prefixLen  uint8                    // byte 21: length of prefix padding
+	payloadLen uint16                   // byte 22 - 23: length of encapsulated payload, not including auth tag
+	suffixLen  uint8                    // byte 24: length of suffix padding
+	sack       [maxSackBlocks]sackBlock // byte 25 - 30: selective acknowledgment blocks, valid if flagSelectiveAck is set
 }
 
 func (das *dataAckStruct) Protocol() protocolType {
@@ -213,7 +279,8 @@ func (das *dataAckStruct) Protocol() protocolType {
 func (das *dataAckStruct) Marshal() []byte {
 	b := make([]byte, MetadataLength)
 	b[0] = das.baseStruct.protocol
-	das.baseStruct.timestamp = uint32(time.Now().Unix() / 60)
+	b[1] = das.baseStruct.flags
+	das.baseStruct.timestamp = uint32(cipher.Now().Unix() / 60)
 	binary.BigEndian.PutUint32(b[2:], das.baseStruct.timestamp)
 	binary.BigEndian.PutUint32(b[6:], das.sessionID)
 	binary.BigEndian.PutUint32(b[10:], das.seq)
@@ -223,6 +290,12 @@ func (das *dataAckStruct) Marshal() []byte {
 	b[21] = das.prefixLen
 	binary.BigEndian.PutUint16(b[22:], das.payloadLen)
 	b[24] = das.suffixLen
+	if das.flags&flagSelectiveAck != 0 {
+		for i, block := range das.sack {
+			binary.BigEndian.PutUint16(b[25+3*i:], block.offset)
+			b[27+3*i] = block.length
+		}
+	}
 	return b
 }
 
@@ -235,13 +308,14 @@ func (das *dataAckStruct) Unmarshal(b []byte) error {
 		return fmt.Errorf("invalid protocol %d", b[0])
 	}
 	originalTimestamp := binary.BigEndian.Uint32(b[2:])
-	currentTimestamp := uint32(time.Now().Unix() / 60)
+	currentTimestamp := uint32(cipher.Now().Unix() / 60)
 	if !mathext.WithinRange(currentTimestamp, originalTimestamp, 1) {
 		return fmt.Errorf("invalid timestamp %d", originalTimestamp*60)
 	}
 
 	// Do unmarshal.
 	das.baseStruct.protocol = b[0]
+	das.baseStruct.flags = b[1]
 	das.baseStruct.timestamp = originalTimestamp
 	das.sessionID = binary.BigEndian.Uint32(b[6:])
 	das.seq = binary.BigEndian.Uint32(b[10:])
@@ -251,13 +325,50 @@ func (das *dataAckStruct) Unmarshal(b []byte) error {
 	das.prefixLen = b[21]
 	das.payloadLen = binary.BigEndian.Uint16(b[22:])
 	das.suffixLen = b[24]
+	if das.flags&flagSelectiveAck != 0 {
+		for i := range das.sack {
+			das.sack[i].offset = binary.BigEndian.Uint16(b[25+3*i:])
+			das.sack[i].length = b[27+3*i]
+		}
+	}
 	return nil
 }
 
 func (das *dataAckStruct) String() string {
+	if das.flags&flagSelectiveAck != 0 {
+		return fmt.Sprintf("dataAckStruct{protocol=%v, sessionID=%v, seq=%v, unAckSeq=%v, windowSize=%v, fragment=%v, prefixLen=%v, payloadLen=%v, suffixLen=%v, sack=%v}", protocolType(das.protocol), das.sessionID, das.seq, das.unAckSeq, das.windowSize, das.fragment, das.prefixLen, das.payloadLen, das.suffixLen, das.sackRanges())
+	}
 	return fmt.Sprintf("dataAckStruct{protocol=%v, sessionID=%v, seq=%v, unAckSeq=%v, windowSize=%v, fragment=%v, prefixLen=%v, payloadLen=%v, suffixLen=%v}", protocolType(das.protocol), das.sessionID, das.seq, das.unAckSeq, das.windowSize, das.fragment, das.prefixLen, das.payloadLen, das.suffixLen)
 }
 
+// isSelectivelyAcked returns true if the sequence number is in one of
+// the selective acknowledgment blocks.
+func (das *dataAckStruct) isSelectivelyAcked(seq uint32) bool {
+	if das.flags&flagSelectiveAck == 0 {
+		return false
+	}
+	for _, block := range das.sack {
+		start := das.unAckSeq + uint32(block.offset)
+		if block.length > 0 && seq >= start && seq < start+uint32(block.length) {
+			return true
+		}
+	}
+	return false
+}
+
+// sackRanges returns the selective acknowledgment blocks as
+// human readable sequence number ranges.
+func (das *dataAckStruct) sackRanges() []string {
+	var ranges []string
+	for _, block := range das.sack {
+		if block.length > 0 {
+			start := das.unAckSeq + uint32(block.offset)
+			ranges = append(ranges, fmt.Sprintf("%d-%d", start, start+uint32(block.length)-1))
+		}
+	}
+	return ranges
+}
+
 func isDataAckProtocol(p protocolType) bool {
 	return p == dataClientToServer || p == dataServerToClient || p == ackClientToServer || p == ackServerToClient
 }
@@ -31,6 +31,9 @@ func TestSessionStruct(t *testing.T) {
 		seq:        mrand.Uint32(),
 		payloadLen: uint16(mrand.Uint32()),
 		suffixLen:  uint8(mrand.Uint32()),
+		loadFactor: uint8(mrand.Uint32()),
+		serverTime: mrand.Uint64(),
+		version:    uint8(mrand.Uint32()),
 	}
 	b := s.Marshal()
 	s2 := &sessionStruct{}
@@ -71,3 +74,39 @@ func TestDataAckStruct(t *testing.T) {
 		t.Errorf("Not equal:\n%s\n====\n%s", s.String(), s2.String())
 	}
 }
+
+func TestDataAckStructSelectiveAck(t *testing.T) {
+	s := &dataAckStruct{
+		baseStruct: baseStruct{
+			protocol: uint8(ackClientToServer),
+			flags:    flagSelectiveAck,
+		},
+		sessionID: mrand.Uint32(),
+		seq:       mrand.Uint32(),
+		unAckSeq:  100,
+		sack:      [maxSackBlocks]sackBlock{{offset: 2, length: 3}, {offset: 300, length: 1}},
+	}
+	b := s.Marshal()
+	s2 := &dataAckStruct{}
+	if err := s2.Unmarshal(b); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if !reflect.DeepEqual(s, s2) {
+		t.Errorf("Not equal:\n%v\n====\n%v", s, s2)
+	}
+	for seq, want := range map[uint32]bool{100: false, 101: false, 102: true, 104: true, 105: false, 400: true, 401: false} {
+		if got := s2.isSelectivelyAcked(seq); got != want {
+			t.Errorf("isSelectivelyAcked(%d) = %v, want %v", seq, got, want)
+		}
+	}
+
+	// Selective acknowledgment blocks are ignored without the flag.
+	s.flags = 0
+	s3 := &dataAckStruct{}
+	if err := s3.Unmarshal(s.Marshal()); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if s3.isSelectivelyAcked(102) {
+		t.Errorf("isSelectivelyAcked(102) = true without flagSelectiveAck")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+)
+
+// Mimicry is the disguise of the traffic carried by an underlay.
+type Mimicry uint8
+
+const (
+	MimicryPlain Mimicry = iota
+	MimicryTLS
+	MimicryWebSocket
+	MimicryDNS
+)
+
+func (m Mimicry) String() string {
+	switch m {
+	case MimicryPlain:
+		return "PLAIN"
+	case MimicryTLS:
+		return "TLS"
+	case MimicryWebSocket:
+		return "WEBSOCKET"
+	case MimicryDNS:
+		return "DNS"
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+var (
+	// serverTLSConfig is shared by all the TLS listeners of the server.
+	serverTLSConfig     *tls.Config
+	serverTLSConfigErr  error
+	serverTLSConfigOnce sync.Once
+)
+
+// clientWrapTCPConn applies the mimicry to a TCP connection dialed by client.
+func clientWrapTCPConn(conn net.Conn, mimicry Mimicry) (net.Conn, error) {
+	switch mimicry {
+	case MimicryPlain:
+		return conn, nil
+	case MimicryTLS:
+		// mieru authenticates the server with the user's password,
+		// so the TLS certificate is not verified.
+		return tls.Client(conn, &tls.Config{
+			InsecureSkipVerify: true,
+			MinVersion:         tls.VersionTLS12,
+		}), nil
+	case MimicryWebSocket:
+		return newWebSocketConn(conn, true), nil
+	default:
+		return nil, fmt.Errorf("mimicry %v is not supported by TCP underlay", mimicry)
+	}
+}
+
+// serverWrapTCPConn applies the mimicry to a TCP connection accepted by server.
+// The handshake of the mimicry is done in the first read or write,
+// so this function doesn't block the accept loop.
+func serverWrapTCPConn(conn net.Conn, mimicry Mimicry) (net.Conn, error) {
+	switch mimicry {
+	case MimicryPlain:
+		return conn, nil
+	case MimicryTLS:
+		serverTLSConfigOnce.Do(func() {
+			serverTLSConfig, serverTLSConfigErr = newSelfSignedTLSConfig()
+		})
+		if serverTLSConfigErr != nil {
+			return nil, fmt.Errorf("newSelfSignedTLSConfig() failed: %w", serverTLSConfigErr)
+		}
+		return tls.Server(conn, serverTLSConfig), nil
+	case MimicryWebSocket:
+		return newWebSocketConn(conn, false), nil
+	default:
+		return nil, fmt.Errorf("mimicry %v is not supported by TCP underlay", mimicry)
+	}
+}
+
+// newSelfSignedTLSConfig creates a TLS server configuration with
+// a self-signed certificate that is generated in memory.
+func newSelfSignedTLSConfig() (*tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("ecdsa.GenerateKey() failed: %w", err)
+	}
+	serial, err := crand.Int(crand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("rand.Int() failed: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             now.Add(-24 * time.Hour),
+		NotAfter:              now.Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(crand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("x509.CreateCertificate() failed: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{
+			{
+				Certificate: [][]byte{der},
+				PrivateKey:  key,
+			},
+		},
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
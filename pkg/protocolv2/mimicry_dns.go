@@ -0,0 +1,122 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+const (
+	// dnsHeaderLen is the length of DNS message header.
+	dnsHeaderLen = 12
+
+	dnsQueryFlags    uint16 = 0x0100 // standard query, recursion desired
+	dnsResponseFlags uint16 = 0x8180 // standard response, recursion desired and available
+)
+
+// udpPacketConn is the network connection used by UDP underlay.
+type udpPacketConn interface {
+	net.Conn
+	ReadFromUDP(b []byte) (int, *net.UDPAddr, error)
+	WriteToUDP(b []byte, addr *net.UDPAddr) (int, error)
+}
+
+var (
+	_ udpPacketConn = &net.UDPConn{}
+	_ udpPacketConn = &dnsPacketConn{}
+)
+
+// dnsPacketConn prepends a DNS message header to each UDP packet,
+// and removes the header from each received UDP packet.
+// Client sends DNS queries and server sends DNS responses.
+type dnsPacketConn struct {
+	*net.UDPConn
+	isClient bool
+}
+
+func newDNSPacketConn(conn *net.UDPConn, isClient bool) *dnsPacketConn {
+	return &dnsPacketConn{
+		UDPConn:  conn,
+		isClient: isClient,
+	}
+}
+
+func (c *dnsPacketConn) ReadFromUDP(b []byte) (int, *net.UDPAddr, error) {
+	buf := make([]byte, len(b)+dnsHeaderLen)
+	for {
+		n, addr, err := c.UDPConn.ReadFromUDP(buf)
+		if err != nil {
+			return 0, addr, err
+		}
+		if n < dnsHeaderLen {
+			UnderlayMalformedUDP.Add(1)
+			continue
+		}
+		flags := binary.BigEndian.Uint16(buf[2:4])
+		isResponse := flags&0x8000 != 0
+		if isResponse != c.isClient {
+			UnderlayMalformedUDP.Add(1)
+			continue
+		}
+		return copy(b, buf[dnsHeaderLen:n]), addr, nil
+	}
+}
+
+func (c *dnsPacketConn) WriteToUDP(b []byte, addr *net.UDPAddr) (int, error) {
+	header := make([]byte, dnsHeaderLen)
+	if _, err := crand.Read(header[:2]); err != nil {
+		return 0, fmt.Errorf("rand.Read() failed: %w", err)
+	}
+	if c.isClient {
+		binary.BigEndian.PutUint16(header[2:4], dnsQueryFlags)
+		binary.BigEndian.PutUint16(header[4:6], 1) // QDCOUNT
+	} else {
+		binary.BigEndian.PutUint16(header[2:4], dnsResponseFlags)
+		binary.BigEndian.PutUint16(header[4:6], 1) // QDCOUNT
+		binary.BigEndian.PutUint16(header[6:8], 1) // ANCOUNT
+	}
+	if _, err := c.UDPConn.WriteToUDP(append(header, b...), addr); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// wrapUDPConn applies the mimicry to a UDP connection.
+func wrapUDPConn(conn *net.UDPConn, mimicry Mimicry, isClient bool) (udpPacketConn, error) {
+	switch mimicry {
+	case MimicryPlain:
+		if udpBatchSupported {
+			return newBatchUDPConn(conn), nil
+		}
+		return conn, nil
+	case MimicryDNS:
+		return newDNSPacketConn(conn, isClient), nil
+	default:
+		return nil, fmt.Errorf("mimicry %v is not supported by UDP underlay", mimicry)
+	}
+}
+
+// mimicryMTU returns the MTU available to mieru protocol after
+// the mimicry overhead is removed.
+func mimicryMTU(mtu int, mimicry Mimicry) int {
+	if mimicry == MimicryDNS {
+		return mtu - dnsHeaderLen
+	}
+	return mtu
+}
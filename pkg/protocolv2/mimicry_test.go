@@ -0,0 +1,82 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/testtool"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+func TestMimicryUnderlay(t *testing.T) {
+	testcases := []struct {
+		mimicry   Mimicry
+		transport util.TransportProtocol
+	}{
+		{MimicryTLS, util.TCPTransport},
+		{MimicryWebSocket, util.TCPTransport},
+		{MimicryDNS, util.UDPTransport},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.mimicry.String(), func(t *testing.T) {
+			log.SetOutputToTest(t)
+			log.SetLevel("DEBUG")
+			var serverAddr, clientAddr net.Addr
+			if tc.transport == util.TCPTransport {
+				port, err := util.UnusedTCPPort()
+				if err != nil {
+					t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+				}
+				serverAddr = &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}
+				clientAddr = &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}
+			} else {
+				port, err := util.UnusedUDPPort()
+				if err != nil {
+					t.Fatalf("util.UnusedUDPPort() failed: %v", err)
+				}
+				serverAddr = &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}
+				clientAddr = &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}
+			}
+			serverProperties := NewUnderlayPropertiesWithMimicry(1500, util.IPVersion4, tc.transport, tc.mimicry, serverAddr, nil)
+			serverMux := NewMux(false).
+				SetServerUsers(users).
+				SetEndpoints([]UnderlayProperties{serverProperties})
+			testServer := testtool.NewTestHelperServer()
+
+			if err := serverMux.Start(); err != nil {
+				t.Fatalf("Start() failed: %v", err)
+			}
+			time.Sleep(100 * time.Millisecond)
+			go func() {
+				if err := testServer.Serve(serverMux); err != nil {
+					t.Errorf("Serve() failed: %v", err)
+				}
+			}()
+			defer testServer.Close()
+			time.Sleep(100 * time.Millisecond)
+
+			clientProperties := NewUnderlayPropertiesWithMimicry(1500, util.IPVersion4, tc.transport, tc.mimicry, nil, clientAddr)
+			runClient(t, clientProperties, []byte("xiaochitang"), []byte("kuiranbudong"), 2)
+			if err := serverMux.Close(); err != nil {
+				t.Errorf("Server mux close failed: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"bufio"
+	crand "crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// webSocketGUID is defined in RFC 6455 to compute Sec-WebSocket-Accept.
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	wsOpContinuation byte = 0x0
+	wsOpBinary       byte = 0x2
+	wsOpClose        byte = 0x8
+	wsOpPing         byte = 0x9
+	wsOpPong         byte = 0xA
+
+	wsMaxHandshakeSize = 4096
+	wsMaxFrameSize     = 1024 * 1024
+)
+
+// webSocketConn carries a byte stream with WebSocket binary frames.
+// The WebSocket handshake is done in the first Read() or Write().
+type webSocketConn struct {
+	net.Conn
+	isClient bool
+	reader   *bufio.Reader
+
+	handshakeOnce sync.Once
+	handshakeErr  error
+
+	readMu  sync.Mutex
+	pending []byte // payload of the current frame that is not consumed
+
+	writeMu sync.Mutex
+}
+
+var _ net.Conn = &webSocketConn{}
+
+func newWebSocketConn(conn net.Conn, isClient bool) *webSocketConn {
+	return &webSocketConn{
+		Conn:     conn,
+		isClient: isClient,
+		reader:   bufio.NewReader(conn),
+	}
+}
+
+func (c *webSocketConn) Read(b []byte) (int, error) {
+	if err := c.handshake(); err != nil {
+		return 0, err
+	}
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	for len(c.pending) == 0 {
+		op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case wsOpBinary, wsOpContinuation:
+			c.pending = payload
+		case wsOpClose:
+			return 0, io.EOF
+		case wsOpPing, wsOpPong:
+			// Peer is not expected to send control frames other than close.
+		default:
+			return 0, fmt.Errorf("unsupported WebSocket opcode %d", op)
+		}
+	}
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *webSocketConn) Write(b []byte) (int, error) {
+	if err := c.handshake(); err != nil {
+		return 0, err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.Conn.Write(c.encodeFrame(wsOpBinary, b)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *webSocketConn) handshake() error {
+	c.handshakeOnce.Do(func() {
+		if c.isClient {
+			c.handshakeErr = c.clientHandshake()
+		} else {
+			c.handshakeErr = c.serverHandshake()
+		}
+	})
+	return c.handshakeErr
+}
+
+func (c *webSocketConn) clientHandshake() error {
+	nonce := make([]byte, 16)
+	if _, err := crand.Read(nonce); err != nil {
+		return fmt.Errorf("rand.Read() failed: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := "GET / HTTP/1.1\r\n" +
+		"Host: " + c.Conn.RemoteAddr().String() + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := c.Conn.Write([]byte(req)); err != nil {
+		return fmt.Errorf("failed to write WebSocket handshake request: %w", err)
+	}
+	resp, err := http.ReadResponse(c.reader, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read WebSocket handshake response: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("WebSocket handshake returned HTTP status %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != webSocketAcceptKey(key) {
+		return fmt.Errorf("WebSocket handshake returned invalid Sec-WebSocket-Accept")
+	}
+	return nil
+}
+
+func (c *webSocketConn) serverHandshake() error {
+	req, err := http.ReadRequest(bufio.NewReaderSize(io.LimitReader(c.reader, wsMaxHandshakeSize), wsMaxHandshakeSize))
+	if err != nil {
+		return fmt.Errorf("failed to read WebSocket handshake request: %w", err)
+	}
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") || key == "" {
+		c.Conn.Write([]byte("HTTP/1.1 400 Bad Request\r\nConnection: close\r\n\r\n"))
+		return fmt.Errorf("received non WebSocket HTTP request")
+	}
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + webSocketAcceptKey(key) + "\r\n\r\n"
+	if _, err := c.Conn.Write([]byte(resp)); err != nil {
+		return fmt.Errorf("failed to write WebSocket handshake response: %w", err)
+	}
+	return nil
+}
+
+// readFrame reads one WebSocket frame and returns the opcode and unmasked payload.
+func (c *webSocketConn) readFrame() (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return 0, nil, err
+	}
+	op := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, ext); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, ext); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if length > wsMaxFrameSize {
+		return 0, nil, fmt.Errorf("WebSocket frame length %d is too big", length)
+	}
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.reader, mask); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return op, payload, nil
+}
+
+// encodeFrame returns a WebSocket frame with the payload.
+// As required by RFC 6455, frames sent by client are masked.
+func (c *webSocketConn) encodeFrame(op byte, payload []byte) []byte {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|op)
+	var maskBit byte
+	if c.isClient {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, maskBit|byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	if !c.isClient {
+		return append(frame, payload...)
+	}
+	mask := make([]byte, 4)
+	crand.Read(mask)
+	frame = append(frame, mask...)
+	start := len(frame)
+	frame = append(frame, payload...)
+	for i := range payload {
+		frame[start+i] ^= mask[i%4]
+	}
+	return frame
+}
+
+func webSocketAcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
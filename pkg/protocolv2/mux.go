@@ -25,6 +25,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/enfein/mieru/pkg/appctl/appctlpb"
@@ -32,6 +33,7 @@ import (
 	"github.com/enfein/mieru/pkg/log"
 	"github.com/enfein/mieru/pkg/mathext"
 	"github.com/enfein/mieru/pkg/stderror"
+	"github.com/enfein/mieru/pkg/tracing"
 	"github.com/enfein/mieru/pkg/util"
 	"github.com/enfein/mieru/pkg/util/sockopts"
 )
@@ -50,13 +52,33 @@ type Mux struct {
 	done        chan struct{}
 	mu          sync.Mutex
 	cleaner     *time.Ticker
+	observers   atomic.Pointer[[]Observer]
 
 	// ---- client fields ----
 	password        []byte
 	multiplexFactor int
+	ipv6SourcePref  sockopts.IPv6SourcePreference
+	clockSync       bool
+
+	// udpFailures is the number of consecutive UDP underlays
+	// without any response from the server.
+	udpFailures int
+
+	// UDP endpoints are carried over TCP until this time.
+	udpFallbackUntil time.Time
+
+	// prewarm keeps pre-established underlays in warmUnderlays,
+	// so a new session doesn't need to wait for the dial.
+	prewarm       bool
+	warmUnderlays []warmUnderlay
+	prewarmKick   chan struct{}
 
 	// ---- server fields ----
 	users map[string]*appctlpb.User
+
+	// listeners maps the key of an endpoint to the function that
+	// stops listening to the endpoint.
+	listeners map[string]context.CancelFunc
 }
 
 var _ net.Listener = &Mux{}
@@ -75,6 +97,8 @@ func NewMux(isClinet bool) *Mux {
 		chAcceptErr: make(chan error, 1), // non-blocking
 		done:        make(chan struct{}),
 		cleaner:     time.NewTicker(idleUnderlayTickerInterval),
+		prewarmKick: make(chan struct{}, 1),
+		listeners:   make(map[string]context.CancelFunc),
 	}
 
 	// Run idle underlay cleaner in the background.
@@ -85,6 +109,10 @@ func NewMux(isClinet bool) *Mux {
 				mux.mu.Lock()
 				mux.cleanUnderlay()
 				mux.mu.Unlock()
+				recordStatsMetrics(mux.Stats())
+				mux.refillWarmPool()
+			case <-mux.prewarmKick:
+				mux.refillWarmPool()
 			case <-mux.done:
 				mux.cleaner.Stop()
 				return
@@ -123,6 +151,37 @@ func (m *Mux) SetClientMultiplexFactor(n int) *Mux {
 	return m
 }
 
+// SetClientIPv6SourcePreference panics if the mux is already started.
+func (m *Mux) SetClientIPv6SourcePreference(pref sockopts.IPv6SourcePreference) *Mux {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.isClient {
+		panic("Can't set IPv6 source preference in server mux")
+	}
+	if m.used {
+		panic("Can't set IPv6 source preference after mux is used")
+	}
+	m.ipv6SourcePref = pref
+	return m
+}
+
+// SetClientClockSync sets whether the process wide clock offset follows the
+// time of the proxy server. Only one client mux in a process should enable
+// it, otherwise the clock offset may be changed back and forth by servers
+// with different time. It panics if the mux is already started.
+func (m *Mux) SetClientClockSync(enable bool) *Mux {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.isClient {
+		panic("Can't set clock sync in server mux")
+	}
+	if m.used {
+		panic("Can't set clock sync after mux is used")
+	}
+	m.clockSync = enable
+	return m
+}
+
 // SetServerUsers updates the registered users, even if mux is already started.
 func (m *Mux) SetServerUsers(users map[string]*appctlpb.User) *Mux {
 	m.mu.Lock()
@@ -143,13 +202,40 @@ func (m *Mux) SetServerUsers(users map[string]*appctlpb.User) *Mux {
 	return m
 }
 
+// SetServerSessionCapacity updates the number of sessions the server is able
+// to handle, even if mux is already started. The server advertises its load
+// factor to clients if capacity is positive.
+func (m *Mux) SetServerSessionCapacity(capacity int) *Mux {
+	if m.isClient {
+		panic("Can't set server session capacity in client mux")
+	}
+	serverSessionCapacity.Store(int64(capacity))
+	return m
+}
+
+// SetReplayCache updates the capacity and expire interval of the replay
+// caches used by TCP and UDP underlays, even if mux is already started.
+func (m *Mux) SetReplayCache(capacity int, expireInterval time.Duration) error {
+	if err := tcpReplayCache.Configure(capacity, expireInterval); err != nil {
+		return err
+	}
+	return udpReplayCache.Configure(capacity, expireInterval)
+}
+
 // SetEndpoints updates the endpoints that mux is listening to.
 // If mux is started and new endpoints are added, mux also starts
 // to listen to those new endpoints. In that case, old endpoints
 // are not impacted.
+//
+// In client mux, the endpoints are replaced. New underlays connect to
+// the new endpoints, while existing underlays are not impacted.
 func (m *Mux) SetEndpoints(endpoints []UnderlayProperties) *Mux {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.isClient {
+		m.endpoints = endpoints
+		return m
+	}
 	new := m.newEndpoints(m.endpoints, endpoints)
 	if len(new) > 0 {
 		if m.used {
@@ -158,7 +244,7 @@ func (m *Mux) SetEndpoints(endpoints []UnderlayProperties) *Mux {
 				log.Infof("Unable to add new endpoint after multiplexer is closed")
 			default:
 				for _, p := range new {
-					go m.acceptUnderlayLoop(context.Background(), p)
+					go m.acceptUnderlayLoop(m.newEndpointContext(p), p, nil)
 				}
 				m.endpoints = endpoints
 			}
@@ -198,6 +284,14 @@ func (m *Mux) Close() error {
 		underlay.Close()
 	}
 	m.underlays = make([]Underlay, 0)
+	for _, w := range m.warmUnderlays {
+		w.underlay.Close()
+	}
+	m.warmUnderlays = nil
+	for key, cancel := range m.listeners {
+		cancel()
+		delete(m.listeners, key)
+	}
 	close(m.done)
 	return nil
 }
@@ -230,7 +324,7 @@ func (m *Mux) Start() error {
 	defer m.mu.Unlock()
 	m.used = true
 	for _, p := range m.endpoints {
-		go m.acceptUnderlayLoop(context.Background(), p)
+		go m.acceptUnderlayLoop(m.newEndpointContext(p), p, nil)
 	}
 	return nil
 }
@@ -241,6 +335,8 @@ func (m *Mux) DialContext(ctx context.Context) (net.Conn, error) {
 	if !m.isClient {
 		return nil, stderror.ErrInvalidOperation
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if len(m.password) == 0 {
 		return nil, fmt.Errorf("client password is not set")
 	}
@@ -252,9 +348,6 @@ func (m *Mux) DialContext(ctx context.Context) (net.Conn, error) {
 			return nil, fmt.Errorf("endpoint remote address is not set")
 		}
 	}
-
-	m.mu.Lock()
-	defer m.mu.Unlock()
 	m.used = true
 	var err error
 
@@ -284,63 +377,107 @@ func (m *Mux) DialContext(ctx context.Context) (net.Conn, error) {
 		underlay.Scheduler().DecPending()
 	}()
 	session := NewSession(mrand.Uint32(), true, underlay.MTU())
+	session.startTrace(ctx)
 	if err := underlay.AddSession(session, nil); err != nil {
 		return nil, fmt.Errorf("AddSession() failed: %v", err)
 	}
+	m.notifySessionOpen(session, underlay)
 	return session, nil
 }
 
+// ExportSessionInfoList returns the information of all the sessions.
+func (m *Mux) ExportSessionInfoList() []SessionInfo {
+	return m.Stats().Sessions
+}
+
+// ExportUnderlayInfoList returns the information of all the underlays.
+func (m *Mux) ExportUnderlayInfoList() []UnderlayInfo {
+	return m.Stats().Underlays
+}
+
 // ExportSessionInfoTable returns multiple lines of strings that display
 // session info in a table format.
 func (m *Mux) ExportSessionInfoTable() []string {
-	header := SessionInfo{
-		ID:         "Session ID",
-		Protocol:   "Protocol",
-		LocalAddr:  "Local",
-		RemoteAddr: "Remote",
-		State:      "State",
-		RecvQBuf:   "Recv Q+Buf",
-		SendQBuf:   "Send Q+Buf",
-		LastRecv:   "Last Recv",
-		LastSend:   "Last Send",
-	}
-	info := []SessionInfo{header}
-	m.mu.Lock()
-	for _, underlay := range m.underlays {
-		info = append(info, underlay.Sessions()...)
-	}
-	m.mu.Unlock()
+	return FormatSessionInfoTable(m.ExportSessionInfoList())
+}
 
-	var idLen, protocolLen, localAddrLen, remoteAddrLen, stateLen, recvQLen, sendQLen, lastRecvLen, lastSendLen int
+// FormatSessionInfoTable returns multiple lines of strings that display
+// the session info in a table format. The first line is the header.
+func FormatSessionInfoTable(info []SessionInfo) []string {
+	rows := [][]string{
+		{"Session ID", "Protocol", "Local", "Remote", "State", "Age", "Rx Bytes", "Tx Bytes", "RTT", "Retrans", "Recv Q+Buf", "Send Q+Buf", "Last Recv", "Last Send"},
+	}
 	for _, si := range info {
-		idLen = mathext.Max(idLen, len(si.ID))
-		protocolLen = mathext.Max(protocolLen, len(si.Protocol))
-		localAddrLen = mathext.Max(localAddrLen, len(si.LocalAddr))
-		remoteAddrLen = mathext.Max(remoteAddrLen, len(si.RemoteAddr))
-		stateLen = mathext.Max(stateLen, len(si.State))
-		recvQLen = mathext.Max(recvQLen, len(si.RecvQBuf))
-		sendQLen = mathext.Max(sendQLen, len(si.SendQBuf))
-		lastRecvLen = mathext.Max(lastRecvLen, len(si.LastRecv))
-		lastSendLen = mathext.Max(lastSendLen, len(si.LastSend))
-	}
-	res := make([]string, 0)
+		rtt := "-"
+		if si.SmoothedRTT > 0 {
+			rtt = fmt.Sprintf("%v", si.SmoothedRTT.Truncate(time.Millisecond))
+		}
+		retrans := "-"
+		if si.Protocol == "UDP" {
+			retrans = fmt.Sprintf("%d (%.1f%%)", si.Retransmits, si.LossRate()*100)
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", si.ID),
+			si.Protocol,
+			si.LocalAddr,
+			si.RemoteAddr,
+			si.State,
+			fmt.Sprintf("%v", si.Age.Truncate(time.Second)),
+			fmt.Sprintf("%d", si.BytesRead),
+			fmt.Sprintf("%d", si.BytesWritten),
+			rtt,
+			retrans,
+			fmt.Sprintf("%d+%d", si.RecvQueue, si.RecvBuf),
+			fmt.Sprintf("%d+%d", si.SendQueue, si.SendBuf),
+			fmt.Sprintf("%v", si.LastRecv.Truncate(time.Second)),
+			fmt.Sprintf("%v", si.LastSend.Truncate(time.Second)),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, col := range row {
+			widths[i] = mathext.Max(widths[i], len(col))
+		}
+	}
+	res := make([]string, 0, len(rows))
 	delim := "  "
-	for _, si := range info {
-		line := make([]string, 0)
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", idLen)+"s", si.ID))
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", protocolLen)+"s", si.Protocol))
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", localAddrLen)+"s", si.LocalAddr))
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", remoteAddrLen)+"s", si.RemoteAddr))
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", stateLen)+"s", si.State))
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", recvQLen)+"s", si.RecvQBuf))
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", sendQLen)+"s", si.SendQBuf))
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", lastRecvLen)+"s", si.LastRecv))
-		line = append(line, fmt.Sprintf("%-"+fmt.Sprintf("%d", lastSendLen)+"s", si.LastSend))
+	for _, row := range rows {
+		line := make([]string, 0, len(row))
+		for i, col := range row {
+			line = append(line, fmt.Sprintf("%-*s", widths[i], col))
+		}
 		res = append(res, strings.Join(line, delim))
 	}
 	return res
 }
 
+// IsPeerIP returns true if the IP address belongs to
+// the remote end of any underlay or session.
+func (m *Mux) IsPeerIP(ip net.IP) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, underlay := range m.underlays {
+		if addrIP(underlay.RemoteAddr()).Equal(ip) {
+			return true
+		}
+		if udpUnderlay, ok := underlay.(*UDPUnderlay); ok {
+			found := false
+			udpUnderlay.sessionMap.Range(func(id uint32, session *Session) bool {
+				if addrIP(session.remoteAddr).Equal(ip) {
+					found = true
+					return false
+				}
+				return true
+			})
+			if found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (m *Mux) newEndpoints(old, new []UnderlayProperties) []UnderlayProperties {
 	newEndpoints := []UnderlayProperties{}
 
@@ -360,10 +497,20 @@ func (m *Mux) newEndpoints(old, new []UnderlayProperties) []UnderlayProperties {
 	return newEndpoints
 }
 
-func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayProperties) {
+// acceptUnderlayLoop listens to the endpoint and accepts underlays
+// until ctx is done. If started is not nil, the result of listening
+// is sent to it. Otherwise, the error is sent to chAcceptErr.
+func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayProperties, started chan<- error) {
+	reportErr := func(err error) {
+		if started != nil {
+			started <- err
+		} else {
+			m.chAcceptErr <- err
+		}
+	}
 	laddr := properties.LocalAddr().String()
 	if laddr == "" {
-		m.chAcceptErr <- fmt.Errorf("underlay local address is empty")
+		reportErr(fmt.Errorf("underlay local address is empty"))
 		return
 	}
 
@@ -373,10 +520,19 @@ func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayPropert
 		listenConfig := sockopts.ListenConfigWithControls()
 		rawListener, err := listenConfig.Listen(ctx, network, laddr)
 		if err != nil {
-			m.chAcceptErr <- fmt.Errorf("Listen() failed: %w", err)
+			reportErr(fmt.Errorf("Listen() failed: %w", err))
 			return
 		}
 		log.Infof("Mux is listening to endpoint %s %s", network, laddr)
+		if started != nil {
+			started <- nil
+		}
+		if ctx.Done() != nil {
+			go func() {
+				<-ctx.Done()
+				rawListener.Close()
+			}()
+		}
 
 		acceptLoopDone := ctx.Done()
 		for {
@@ -386,6 +542,10 @@ func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayPropert
 			default:
 				underlay, err := m.acceptTCPUnderlay(rawListener, properties)
 				if err != nil {
+					if ctx.Err() != nil {
+						log.Infof("Mux stopped listening to endpoint %s %s", network, laddr)
+						return
+					}
 					m.chAcceptErr <- err
 					return
 				}
@@ -394,6 +554,7 @@ func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayPropert
 				m.underlays = append(m.underlays, underlay)
 				m.cleanUnderlay()
 				m.mu.Unlock()
+				m.notifyUnderlayUp(underlay)
 				UnderlayPassiveOpens.Add(1)
 				currEst := UnderlayCurrEstablished.Add(1)
 				maxConn := UnderlayMaxConn.Load()
@@ -418,6 +579,9 @@ func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayPropert
 							}
 							break
 						}
+						if session, ok := conn.(*Session); ok {
+							m.notifySessionOpen(session, underlay)
+						}
 						select {
 						case m.chAccept <- conn:
 						case <-ctx.Done():
@@ -430,17 +594,27 @@ func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayPropert
 	case "udp", "udp4", "udp6":
 		conn, err := net.ListenUDP(network, properties.LocalAddr().(*net.UDPAddr))
 		if err != nil {
-			m.chAcceptErr <- fmt.Errorf("ListenUDP() failed: %w", err)
+			reportErr(fmt.Errorf("ListenUDP() failed: %w", err))
 			return
 		}
 		if err := sockopts.ApplyUDPControls(conn); err != nil {
-			m.chAcceptErr <- fmt.Errorf("ApplyUDPControls() failed: %w", err)
+			conn.Close()
+			reportErr(fmt.Errorf("ApplyUDPControls() failed: %w", err))
+			return
+		}
+		wrappedConn, err := wrapUDPConn(conn, properties.Mimicry(), false)
+		if err != nil {
+			conn.Close()
+			reportErr(err)
 			return
 		}
 		log.Infof("Mux is listening to endpoint %s %s", network, laddr)
+		if started != nil {
+			started <- nil
+		}
 		underlay := &UDPUnderlay{
-			baseUnderlay:      *newBaseUnderlay(false, properties.MTU()),
-			conn:              conn,
+			baseUnderlay:      *newBaseUnderlay(false, properties.MTU(), properties.Mimicry()),
+			conn:              wrappedConn,
 			idleSessionTicker: time.NewTicker(idleSessionTickerInterval),
 			users:             m.users,
 		}
@@ -449,6 +623,7 @@ func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayPropert
 		m.underlays = append(m.underlays, underlay)
 		m.cleanUnderlay()
 		m.mu.Unlock()
+		m.notifyUnderlayUp(underlay)
 		UnderlayPassiveOpens.Add(1)
 		currEst := UnderlayCurrEstablished.Add(1)
 		maxConn := UnderlayMaxConn.Load()
@@ -473,6 +648,9 @@ func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayPropert
 					}
 					break
 				}
+				if session, ok := conn.(*Session); ok {
+					m.notifySessionOpen(session, underlay)
+				}
 				select {
 				case m.chAccept <- conn:
 				case <-ctx.Done():
@@ -481,26 +659,57 @@ func (m *Mux) acceptUnderlayLoop(ctx context.Context, properties UnderlayPropert
 			}
 		}(ctx, underlay)
 	default:
-		m.chAcceptErr <- fmt.Errorf("unsupported underlay network type %q", network)
+		reportErr(fmt.Errorf("unsupported underlay network type %q", network))
 	}
 }
 
 func (m *Mux) acceptTCPUnderlay(rawListener net.Listener, properties UnderlayProperties) (Underlay, error) {
-	rawConn, err := rawListener.Accept()
+	var rawConn net.Conn
+	var release func()
+	for {
+		var err error
+		rawConn, err = rawListener.Accept()
+		if err != nil {
+			return nil, fmt.Errorf("Accept() underlay failed: %w", err)
+		}
+		if tcpAddr, ok := rawConn.RemoteAddr().(*net.TCPAddr); ok && serverIPBans.isBanned(tcpAddr.IP, time.Now()) {
+			UnderlayBannedDrops.Add(1)
+			rawConn.Close()
+			continue
+		}
+		release, err = serverConnectionLimits.acquireUnderlay("", addrIP(rawConn.RemoteAddr()))
+		if err != nil {
+			UnderlayLimitRejects.Add(1)
+			connectionLimitLogSampler.Warnf("Rejected TCP connection from %v: %v", rawConn.RemoteAddr(), err)
+			rawConn.Close()
+			continue
+		}
+		break
+	}
+	conn, err := serverWrapTCPConn(rawConn, properties.Mimicry())
 	if err != nil {
-		return nil, fmt.Errorf("Accept() underlay failed: %w", err)
+		release()
+		rawConn.Close()
+		return nil, err
+	}
+	underlay := m.serverWrapTCPConn(conn, properties.MTU(), properties.Mimicry(), m.users)
+	if t, ok := underlay.(*TCPUnderlay); ok {
+		t.addConnectionLimitRelease(release)
+	} else {
+		release()
 	}
-	return m.serverWrapTCPConn(rawConn, properties.MTU(), m.users), nil
+	return underlay, nil
 }
 
-func (m *Mux) serverWrapTCPConn(rawConn net.Conn, mtu int, users map[string]*appctlpb.User) Underlay {
+func (m *Mux) serverWrapTCPConn(conn net.Conn, mtu int, mimicry Mimicry, users map[string]*appctlpb.User) Underlay {
 	var err error
 	var blocks []cipher.BlockCipher
-	for _, user := range users {
+	// Try the users that are more likely to connect first.
+	for _, user := range serverCipherHits.rankUsers(localPort(conn.LocalAddr()), users, time.Now()) {
 		var password []byte
 		password, err = hex.DecodeString(user.GetHashedPassword())
 		if err != nil {
-			log.Debugf("Unable to decode hashed password %q from user %q", user.GetHashedPassword(), user.GetName())
+			log.Debugf("Unable to decode hashed password of user %q", user.GetName())
 			continue
 		}
 		if len(password) == 0 {
@@ -516,11 +725,11 @@ func (m *Mux) serverWrapTCPConn(rawConn net.Conn, mtu int, users map[string]*app
 				UserName: user.GetName(),
 			})
 		}
-		blocks = append(blocks, blocksFromUser...)
+		blocks = append(blocks, preferCurrentSalt(blocksFromUser)...)
 	}
 	return &TCPUnderlay{
-		baseUnderlay: *newBaseUnderlay(false, mtu),
-		conn:         rawConn.(*net.TCPConn),
+		baseUnderlay: *newBaseUnderlay(false, mtu, mimicry),
+		conn:         conn,
 		candidates:   blocks,
 		users:        users,
 	}
@@ -529,45 +738,87 @@ func (m *Mux) serverWrapTCPConn(rawConn net.Conn, mtu int, users map[string]*app
 // newUnderlay returns a new underlay.
 // This method MUST be called only when holding the mu lock.
 func (m *Mux) newUnderlay(ctx context.Context) (Underlay, error) {
-	var underlay Underlay
-	i := mrand.Intn(len(m.endpoints))
+	i := clientEndpointLoads.pick(m.endpoints, time.Now())
 	p := m.endpoints[i]
+	key := endpointKey(p)
+	if p.TransportProtocol() == util.UDPTransport && m.isUDPFallbackActive() {
+		p = m.udpFallbackEndpoint(p)
+		UnderlayUDPFallbacks.Add(1)
+	}
+	underlay := m.takeWarmUnderlay(endpointKey(p), time.Now())
+	if underlay != nil {
+		UnderlayPrewarmHits.Add(1)
+		log.Debugf("Using pre-established underlay %v", underlay)
+	} else {
+		var err error
+		underlay, err = m.dialUnderlay(ctx, p, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m.prewarm {
+		m.kickPrewarm()
+	}
+	m.underlays = append(m.underlays, underlay)
+	m.notifyUnderlayUp(underlay)
+	UnderlayActiveOpens.Add(1)
+	currEst := UnderlayCurrEstablished.Add(1)
+	maxConn := UnderlayMaxConn.Load()
+	if currEst > maxConn {
+		UnderlayMaxConn.Store(currEst)
+	}
+	go func() {
+		err := underlay.RunEventLoop(ctx)
+		if err != nil && !stderror.IsEOF(err) && !stderror.IsClosed(err) {
+			log.Debugf("%v RunEventLoop(): %v", underlay, err)
+		}
+		underlay.Close()
+		if udpUnderlay, ok := underlay.(*UDPUnderlay); ok {
+			m.onUDPUnderlayClosed(udpUnderlay)
+		}
+	}()
+	return underlay, nil
+}
+
+// dialUnderlay connects to the endpoint. "key" identifies the endpoint
+// selected by the load balancer, which may be different from the endpoint
+// connected when UDP is carried over TCP.
+func (m *Mux) dialUnderlay(ctx context.Context, p UnderlayProperties, key string) (Underlay, error) {
+	var underlay Underlay
 	switch p.TransportProtocol() {
 	case util.TCPTransport:
 		block, err := cipher.BlockCipherFromPassword(m.password, false)
 		if err != nil {
 			return nil, fmt.Errorf("cipher.BlockCipherFromPassword() failed: %v", err)
 		}
-		underlay, err = NewTCPUnderlay(ctx, p.RemoteAddr().Network(), "", p.RemoteAddr().String(), p.MTU(), block)
+		dialCtx, span := tracing.Start(ctx, "mieru.underlay.dial", tracing.String("underlay.protocol", "TCP"), tracing.String("underlay.remote", p.RemoteAddr().String()))
+		tcpUnderlay, err := NewTCPUnderlay(dialCtx, p.RemoteAddr().Network(), "", p.RemoteAddr().String(), p.MTU(), block, p.Mimicry(), m.ipv6SourcePref)
+		span.RecordError(err)
+		span.End()
 		if err != nil {
 			return nil, fmt.Errorf("NewTCPUnderlay() failed: %v", err)
 		}
+		tcpUnderlay.endpoint = key
+		tcpUnderlay.clockSync = m.clockSync
+		underlay = tcpUnderlay
 	case util.UDPTransport:
 		block, err := cipher.BlockCipherFromPassword(m.password, true)
 		if err != nil {
 			return nil, fmt.Errorf("cipher.BlockCipherFromPassword() failed: %v", err)
 		}
-		underlay, err = NewUDPUnderlay(ctx, p.RemoteAddr().Network(), "", p.RemoteAddr().String(), p.MTU(), block)
+		dialCtx, span := tracing.Start(ctx, "mieru.underlay.dial", tracing.String("underlay.protocol", "UDP"), tracing.String("underlay.remote", p.RemoteAddr().String()))
+		udpUnderlay, err := NewUDPUnderlay(dialCtx, p.RemoteAddr().Network(), "", p.RemoteAddr().String(), p.MTU(), block, p.Mimicry(), m.ipv6SourcePref)
+		span.RecordError(err)
+		span.End()
 		if err != nil {
 			return nil, fmt.Errorf("NewUDPUnderlay() failed: %v", err)
 		}
+		udpUnderlay.endpoint = key
+		udpUnderlay.clockSync = m.clockSync
+		underlay = udpUnderlay
 	default:
 		return nil, fmt.Errorf("unsupport transport protocol %v", p.TransportProtocol())
 	}
-	m.underlays = append(m.underlays, underlay)
-	UnderlayActiveOpens.Add(1)
-	currEst := UnderlayCurrEstablished.Add(1)
-	maxConn := UnderlayMaxConn.Load()
-	if currEst > maxConn {
-		UnderlayMaxConn.Store(currEst)
-	}
-	go func() {
-		err := underlay.RunEventLoop(ctx)
-		if err != nil && !stderror.IsEOF(err) && !stderror.IsClosed(err) {
-			log.Debugf("%v RunEventLoop(): %v", underlay, err)
-		}
-		underlay.Close()
-	}()
 	return underlay, nil
 }
 
@@ -597,11 +848,13 @@ func (m *Mux) maybePickExistingUnderlay() Underlay {
 	return nil
 }
 
-// cleanUnderlay removes closed underlays.
+// cleanUnderlay removes closed underlays. It also closes idle underlays,
+// and TCP underlays that are stuck without receiving anything.
 // This method MUST be called only when holding the mu lock.
 func (m *Mux) cleanUnderlay() {
 	remaining := make([]Underlay, 0)
 	cnt := 0
+	now := time.Now()
 	for _, underlay := range m.underlays {
 		select {
 		case <-underlay.Done():
@@ -609,6 +862,11 @@ func (m *Mux) cleanUnderlay() {
 			if underlay.Scheduler().Idle() {
 				underlay.Close()
 				cnt++
+			} else if t, ok := underlay.(*TCPUnderlay); ok && t.isStuck(now) {
+				log.Debugf("Mux reaped stuck underlay %v", t)
+				UnderlayReaped.Add(1)
+				underlay.Close()
+				cnt++
 			} else {
 				remaining = append(remaining, underlay)
 			}
@@ -619,3 +877,15 @@ func (m *Mux) cleanUnderlay() {
 		log.Debugf("Mux cleaned %d underlays", cnt)
 	}
 }
+
+// addrIP returns the IP address of a TCP or UDP network address.
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	default:
+		return nil
+	}
+}
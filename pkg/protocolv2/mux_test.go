@@ -18,11 +18,13 @@ package protocolv2
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	mrand "math/rand"
 	"net"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -83,6 +85,13 @@ func runClient(t *testing.T, properties UnderlayProperties, username, password [
 			if len(sessionInfoTable) < 2 {
 				t.Errorf("connection is not shown in the session info table: %v", sessionInfoTable)
 			}
+			info := conn.(*Session).ToSessionInfo()
+			if info.BytesRead == 0 || info.BytesRead != info.BytesWritten {
+				t.Errorf("got %d bytes read and %d bytes written, want equal non-zero values", info.BytesRead, info.BytesWritten)
+			}
+			if underlays := clientMux.ExportUnderlayInfoList(); len(underlays) == 0 {
+				t.Errorf("ExportUnderlayInfoList() returned no underlay")
+			}
 		}()
 	}
 	wg.Wait()
@@ -270,3 +279,353 @@ func TestNewEndpoints(t *testing.T) {
 		}
 	}
 }
+
+type countingObserver struct {
+	NopObserver
+	underlayUp   atomic.Int32
+	underlayDown atomic.Int32
+	sessionOpen  atomic.Int32
+	sessionClose atomic.Int32
+}
+
+func (o *countingObserver) OnUnderlayUp(UnderlayEvent)   { o.underlayUp.Add(1) }
+func (o *countingObserver) OnUnderlayDown(UnderlayEvent) { o.underlayDown.Add(1) }
+func (o *countingObserver) OnSessionOpen(SessionEvent)   { o.sessionOpen.Add(1) }
+func (o *countingObserver) OnSessionClose(SessionEvent)  { o.sessionClose.Add(1) }
+
+func TestMuxObserver(t *testing.T) {
+	log.SetOutputToTest(t)
+	log.SetLevel("DEBUG")
+	port, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	serverObserver := &countingObserver{}
+	serverProperties := NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil)
+	serverMux := NewMux(false).
+		SetServerUsers(users).
+		SetEndpoints([]UnderlayProperties{serverProperties}).
+		AddObserver(serverObserver)
+	testServer := testtool.NewTestHelperServer()
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	go testServer.Serve(serverMux)
+	defer testServer.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	clientObserver := &countingObserver{}
+	clientProperties := NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, nil, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port})
+	clientMux := NewMux(true).
+		SetClientPassword(cipher.HashPassword([]byte("kuiranbudong"), []byte("xiaochitang"))).
+		SetEndpoints([]UnderlayProperties{clientProperties}).
+		AddObserver(clientObserver)
+	conn, err := clientMux.DialContext(context.Background())
+	if err != nil {
+		t.Fatalf("DialContext() failed: %v", err)
+	}
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 5)); err != nil {
+		t.Fatalf("io.ReadFull() failed: %v", err)
+	}
+	if got := clientObserver.underlayUp.Load(); got != 1 {
+		t.Errorf("client OnUnderlayUp() is called %d times, want 1", got)
+	}
+	if got := clientObserver.sessionOpen.Load(); got != 1 {
+		t.Errorf("client OnSessionOpen() is called %d times, want 1", got)
+	}
+	if got := serverObserver.sessionOpen.Load(); got != 1 {
+		t.Errorf("server OnSessionOpen() is called %d times, want 1", got)
+	}
+
+	conn.Close()
+	if err := clientMux.Close(); err != nil {
+		t.Errorf("Close client mux failed: %v", err)
+	}
+	if err := serverMux.Close(); err != nil {
+		t.Errorf("Close server mux failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if clientObserver.sessionClose.Load() == 1 && clientObserver.underlayDown.Load() == 1 && serverObserver.underlayDown.Load() == serverObserver.underlayUp.Load() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := clientObserver.sessionClose.Load(); got != 1 {
+		t.Errorf("client OnSessionClose() is called %d times, want 1", got)
+	}
+	if got := clientObserver.underlayDown.Load(); got != 1 {
+		t.Errorf("client OnUnderlayDown() is called %d times, want 1", got)
+	}
+	if up, down := serverObserver.underlayUp.Load(), serverObserver.underlayDown.Load(); up == 0 || up != down {
+		t.Errorf("server OnUnderlayUp() is called %d times, OnUnderlayDown() is called %d times", up, down)
+	}
+}
+
+func TestServerMessage(t *testing.T) {
+	log.SetOutputToTest(t)
+	log.SetLevel("DEBUG")
+	port, err := util.UnusedUDPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedUDPPort() failed: %v", err)
+	}
+	serverProperties := NewUnderlayProperties(1500, util.IPVersion4, util.UDPTransport, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil)
+	serverMux := NewMux(false).
+		SetServerUsers(users).
+		SetEndpoints([]UnderlayProperties{serverProperties})
+	testServer := testtool.NewTestHelperServer()
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	go testServer.Serve(serverMux)
+	defer testServer.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	clientProperties := NewUnderlayProperties(1500, util.IPVersion4, util.UDPTransport, nil, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port})
+	clientMux := NewMux(true).
+		SetClientPassword(cipher.HashPassword([]byte("kuiranbudong"), []byte("xiaochitang"))).
+		SetEndpoints([]UnderlayProperties{clientProperties})
+	conn, err := clientMux.DialContext(context.Background())
+	if err != nil {
+		t.Fatalf("DialContext() failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 5)); err != nil {
+		t.Fatalf("io.ReadFull() failed: %v", err)
+	}
+
+	if _, err := clientMux.SendServerMessage("", "hello"); err == nil {
+		t.Errorf("SendServerMessage() from client mux succeeded, want error")
+	}
+	if n, err := serverMux.SendServerMessage("nobody", "hello"); err != nil || n != 0 {
+		t.Errorf("SendServerMessage() to unknown user = %d, %v, want 0, nil", n, err)
+	}
+	text := "planned maintenance at 02:00 UTC"
+	n, err := serverMux.SendServerMessage("xiaochitang", text)
+	if err != nil {
+		t.Fatalf("SendServerMessage() failed: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("SendServerMessage() sent to %d sessions, want 1", n)
+	}
+	var found bool
+	deadline := time.Now().Add(5 * time.Second)
+	for !found && time.Now().Before(deadline) {
+		for _, msg := range RecentServerMessages() {
+			if msg.Text == text {
+				found = true
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !found {
+		t.Errorf("server message %q is not received by client", text)
+	}
+
+	if err := clientMux.Close(); err != nil {
+		t.Errorf("Close client mux failed: %v", err)
+	}
+	if err := serverMux.Close(); err != nil {
+		t.Errorf("Close server mux failed: %v", err)
+	}
+}
+
+// TestClientClockSync verifies that only the client mux with clock sync
+// enabled follows the server time. A default client mux, like the upstream
+// mux of a proxy server, doesn't change the process wide clock offset.
+func TestClientClockSync(t *testing.T) {
+	log.SetOutputToTest(t)
+	log.SetLevel("DEBUG")
+	serverNow = func() time.Time { return time.Now().Add(time.Hour) }
+	defer func() {
+		serverNow = cipher.Now
+		cipher.SetClockOffset(0)
+	}()
+	port, err := util.UnusedUDPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedUDPPort() failed: %v", err)
+	}
+	serverProperties := NewUnderlayProperties(1500, util.IPVersion4, util.UDPTransport, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil)
+	serverMux := NewMux(false).
+		SetServerUsers(users).
+		SetEndpoints([]UnderlayProperties{serverProperties})
+	testServer := testtool.NewTestHelperServer()
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	go testServer.Serve(serverMux)
+	defer testServer.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	dial := func(clockSync bool) {
+		clientProperties := NewUnderlayProperties(1500, util.IPVersion4, util.UDPTransport, nil, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port})
+		clientMux := NewMux(true).
+			SetClientPassword(cipher.HashPassword([]byte("kuiranbudong"), []byte("xiaochitang"))).
+			SetEndpoints([]UnderlayProperties{clientProperties})
+		if clockSync {
+			clientMux.SetClientClockSync(true)
+		}
+		defer clientMux.Close()
+		conn, err := clientMux.DialContext(context.Background())
+		if err != nil {
+			t.Fatalf("DialContext() failed: %v", err)
+		}
+		defer conn.Close()
+		if _, err := conn.Write([]byte("hello")); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+		if clockSync {
+			// The server in this process shares the clock offset, so it
+			// can't decrypt the echo after the offset is adjusted.
+			deadline := time.Now().Add(5 * time.Second)
+			for cipher.ClockOffset() == 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			return
+		}
+		if _, err := io.ReadFull(conn, make([]byte, 5)); err != nil {
+			t.Fatalf("io.ReadFull() failed: %v", err)
+		}
+	}
+
+	dial(false)
+	if offset := cipher.ClockOffset(); offset != 0 {
+		t.Errorf("ClockOffset() = %v after default client mux, want 0", offset)
+	}
+
+	dial(true)
+	if offset := cipher.ClockOffset(); offset < 59*time.Minute || offset > 61*time.Minute {
+		t.Errorf("ClockOffset() = %v after client mux with clock sync, want about 1h", offset)
+	}
+
+	if err := serverMux.Close(); err != nil {
+		t.Errorf("Close server mux failed: %v", err)
+	}
+}
+
+func TestTCPUnderlayKeepalive(t *testing.T) {
+	log.SetOutputToTest(t)
+	log.SetLevel("DEBUG")
+	port, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	serverProperties := NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil)
+	serverMux := NewMux(false).
+		SetServerUsers(users).
+		SetEndpoints([]UnderlayProperties{serverProperties})
+	testServer := testtool.NewTestHelperServer()
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	go testServer.Serve(serverMux)
+	defer testServer.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	clientProperties := NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, nil, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port})
+	clientMux := NewMux(true).
+		SetClientPassword(cipher.HashPassword([]byte("kuiranbudong"), []byte("xiaochitang"))).
+		SetEndpoints([]UnderlayProperties{clientProperties})
+	conn, err := clientMux.DialContext(context.Background())
+	if err != nil {
+		t.Fatalf("DialContext() failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 5)); err != nil {
+		t.Fatalf("io.ReadFull() failed: %v", err)
+	}
+
+	clientMux.mu.Lock()
+	underlay := clientMux.underlays[0].(*TCPUnderlay)
+	clientMux.mu.Unlock()
+	if !underlay.peerKeepalive.Load() {
+		t.Fatalf("server doesn't advertise keepalive support")
+	}
+
+	// The server answers the keepalive request.
+	if err := underlay.checkKeepalive(time.Now().Add(keepaliveIdleTime)); err != nil {
+		t.Fatalf("checkKeepalive() failed: %v", err)
+	}
+	if underlay.keepaliveSentTime.Load() == 0 {
+		t.Fatalf("keepalive request is not sent")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for underlay.keepaliveSentTime.Load() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if underlay.keepaliveSentTime.Load() != 0 {
+		t.Fatalf("keepalive response is not received")
+	}
+
+	// A pending request that is not answered in time means dead peer.
+	deadUnderlay := &TCPUnderlay{baseUnderlay: *newBaseUnderlay(true, 1500, MimicryPlain)}
+	deadUnderlay.sessionMap.LoadOrStore(1, NewSession(1, true, 1500))
+	deadUnderlay.peerKeepalive.Store(true)
+	deadUnderlay.keepaliveSentTime.Store(time.Now().Add(-2 * keepaliveTimeout).UnixNano())
+	if err := deadUnderlay.checkKeepalive(time.Now()); !errors.Is(err, errDeadPeer) {
+		t.Errorf("checkKeepalive() = %v, want %v", err, errDeadPeer)
+	}
+
+	if err := clientMux.Close(); err != nil {
+		t.Errorf("Close client mux failed: %v", err)
+	}
+	if err := serverMux.Close(); err != nil {
+		t.Errorf("Close server mux failed: %v", err)
+	}
+}
+
+func TestFormatSessionInfoTable(t *testing.T) {
+	info := []SessionInfo{
+		{
+			ID:         12345,
+			Protocol:   "TCP",
+			LocalAddr:  "127.0.0.1:1234",
+			RemoteAddr: "127.0.0.1:5678",
+			State:      "ESTABLISHED",
+			RecvQueue:  1,
+			RecvBuf:    2,
+			SendQueue:  3,
+			SendBuf:    4,
+			LastRecv:   1500 * time.Millisecond,
+			LastSend:   2 * time.Minute,
+			BytesRead:  100,
+			Age:        3 * time.Minute,
+		},
+		{
+			ID:           678,
+			Protocol:     "UDP",
+			LocalAddr:    "127.0.0.1:1234",
+			RemoteAddr:   "127.0.0.1:5678",
+			State:        "ESTABLISHED",
+			LastRecv:     time.Second,
+			LastSend:     time.Second,
+			BytesRead:    2048,
+			BytesWritten: 512,
+			SmoothedRTT:  52500 * time.Microsecond,
+			Age:          time.Hour,
+			SegmentsSent: 200,
+			Retransmits:  3,
+		},
+	}
+	want := []string{
+		"Session ID  Protocol  Local           Remote          State        Age     Rx Bytes  Tx Bytes  RTT   Retrans   Recv Q+Buf  Send Q+Buf  Last Recv  Last Send",
+		"12345       TCP       127.0.0.1:1234  127.0.0.1:5678  ESTABLISHED  3m0s    100       0         -     -         1+2         3+4         1s         2m0s     ",
+		"678         UDP       127.0.0.1:1234  127.0.0.1:5678  ESTABLISHED  1h0m0s  2048      512       52ms  3 (1.5%)  0+0         0+0         1s         1s       ",
+	}
+	if got := FormatSessionInfoTable(info); !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatSessionInfoTable() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+)
+
+// ResetUnderlays closes all the underlays of the client mux, including
+// the pre-established underlays. It is called when the network of the
+// host is changed, because the underlays bound to the old network are
+// unlikely to work, and waiting for the TCP timeout takes long. The UDP
+// fallback state is also reset, because the new network may not block
+// UDP. It returns the number of closed underlays.
+func (m *Mux) ResetUnderlays(reason string) int {
+	m.mu.Lock()
+	if !m.isClient {
+		m.mu.Unlock()
+		return 0
+	}
+	underlays := m.underlays
+	m.underlays = make([]Underlay, 0)
+	for _, w := range m.warmUnderlays {
+		underlays = append(underlays, w.underlay)
+	}
+	m.warmUnderlays = nil
+	m.udpFailures = 0
+	m.udpFallbackUntil = time.Time{}
+	m.mu.Unlock()
+
+	cnt := 0
+	for _, underlay := range underlays {
+		select {
+		case <-underlay.Done():
+		default:
+			underlay.Close()
+			cnt++
+		}
+	}
+	if cnt > 0 {
+		UnderlayNetworkResets.Add(int64(cnt))
+		log.Infof("Mux closed %d underlays because %s", cnt, reason)
+	}
+	m.kickPrewarm()
+	return cnt
+}
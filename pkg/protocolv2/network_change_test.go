@@ -0,0 +1,62 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestResetUnderlays(t *testing.T) {
+	newUnderlay := func() *TCPUnderlay {
+		conn, peer := net.Pipe()
+		t.Cleanup(func() { peer.Close() })
+		return &TCPUnderlay{baseUnderlay: *newBaseUnderlay(true, 1500, MimicryPlain), conn: conn}
+	}
+	active := newUnderlay()
+	warm := newUnderlay()
+
+	m := NewMux(true)
+	defer m.Close()
+	m.mu.Lock()
+	m.underlays = []Underlay{active}
+	m.warmUnderlays = []warmUnderlay{{underlay: warm, createTime: time.Now()}}
+	m.udpFailures = udpFallbackThreshold
+	m.udpFallbackUntil = time.Now().Add(udpFallbackDuration)
+	m.mu.Unlock()
+
+	resets := UnderlayNetworkResets.Load()
+	if got := m.ResetUnderlays("network is changed"); got != 2 {
+		t.Errorf("ResetUnderlays() = %d, want 2", got)
+	}
+	for _, underlay := range []*TCPUnderlay{active, warm} {
+		select {
+		case <-underlay.Done():
+		default:
+			t.Errorf("underlay is not closed")
+		}
+	}
+	if got := UnderlayNetworkResets.Load() - resets; got != 2 {
+		t.Errorf("got %d network resets, want 2", got)
+	}
+	if m.UDPFallbackActive() {
+		t.Errorf("UDP fallback is active after reset")
+	}
+	if got := m.ResetUnderlays("network is changed"); got != 0 {
+		t.Errorf("ResetUnderlays() = %d after reset, want 0", got)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"time"
+
+	"github.com/enfein/mieru/pkg/util"
+)
+
+// UnderlayEvent contains the metadata of an underlay.
+type UnderlayEvent struct {
+	IsClient          bool
+	TransportProtocol util.TransportProtocol
+	Mimicry           Mimicry
+	LocalAddr         net.Addr
+	RemoteAddr        net.Addr
+	UpTime            time.Time
+	DownTime          time.Time // zero if the underlay is up
+}
+
+// SessionEvent contains the metadata of a session.
+type SessionEvent struct {
+	ID                uint32
+	IsClient          bool
+	TransportProtocol util.TransportProtocol
+	LocalAddr         net.Addr
+	RemoteAddr        net.Addr
+	OpenTime          time.Time
+	CloseTime         time.Time // zero if the session is open
+}
+
+// Observer receives underlay and session events from a Mux.
+//
+// The methods are called synchronously. They must return quickly,
+// and must not call methods of the Mux.
+type Observer interface {
+	// OnUnderlayUp is called when an underlay is created.
+	OnUnderlayUp(UnderlayEvent)
+
+	// OnUnderlayDown is called when an underlay is closed.
+	OnUnderlayDown(UnderlayEvent)
+
+	// OnSessionOpen is called when a session is created by the client,
+	// or accepted by the server.
+	OnSessionOpen(SessionEvent)
+
+	// OnSessionClose is called when a session is closed.
+	OnSessionClose(SessionEvent)
+}
+
+// NopObserver ignores all the events. It can be embedded by an
+// Observer implementation that only handles some of the events.
+type NopObserver struct{}
+
+var _ Observer = NopObserver{}
+
+func (NopObserver) OnUnderlayUp(UnderlayEvent) {}
+
+func (NopObserver) OnUnderlayDown(UnderlayEvent) {}
+
+func (NopObserver) OnSessionOpen(SessionEvent) {}
+
+func (NopObserver) OnSessionClose(SessionEvent) {}
+
+// AddObserver registers an observer of underlay and session events.
+// Events happened before the registration are not delivered.
+func (m *Mux) AddObserver(o Observer) *Mux {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var observers []Observer
+	if old := m.observers.Load(); old != nil {
+		observers = append(observers, *old...)
+	}
+	observers = append(observers, o)
+	m.observers.Store(&observers)
+	return m
+}
+
+// notifyUnderlayUp delivers the underlay up event to observers,
+// and delivers the underlay down event when the underlay is closed.
+func (m *Mux) notifyUnderlayUp(underlay Underlay) {
+	observers := m.observers.Load()
+	if observers == nil {
+		return
+	}
+	event := UnderlayEvent{
+		IsClient:          m.isClient,
+		TransportProtocol: underlay.TransportProtocol(),
+		Mimicry:           underlay.Mimicry(),
+		LocalAddr:         underlay.LocalAddr(),
+		RemoteAddr:        underlay.RemoteAddr(),
+		UpTime:            time.Now(),
+	}
+	for _, o := range *observers {
+		o.OnUnderlayUp(event)
+	}
+	go func() {
+		<-underlay.Done()
+		event.DownTime = time.Now()
+		for _, o := range *observers {
+			o.OnUnderlayDown(event)
+		}
+	}()
+}
+
+// notifySessionOpen delivers the session open event to observers,
+// and delivers the session close event when the session is closed.
+func (m *Mux) notifySessionOpen(session *Session, underlay Underlay) {
+	observers := m.observers.Load()
+	if observers == nil {
+		return
+	}
+	event := SessionEvent{
+		ID:                session.id,
+		IsClient:          session.isClient,
+		TransportProtocol: underlay.TransportProtocol(),
+		LocalAddr:         underlay.LocalAddr(),
+		RemoteAddr:        session.RemoteAddr(),
+		OpenTime:          time.Now(),
+	}
+	for _, o := range *observers {
+		o.OnSessionOpen(event)
+	}
+	go func() {
+		<-session.done
+		event.CloseTime = time.Now()
+		for _, o := range *observers {
+			o.OnSessionClose(event)
+		}
+	}()
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"context"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+// maxWarmUnderlayAge is the maximum time a pre-established underlay
+// waits in the pool. Older underlays are replaced, because middleboxes
+// may silently drop idle TCP connections.
+const maxWarmUnderlayAge = 60 * time.Second
+
+// warmUnderlay is a pre-established underlay that has not been used
+// by any session yet.
+type warmUnderlay struct {
+	underlay   Underlay
+	endpoint   string
+	createTime time.Time
+}
+
+// SetClientPrewarm panics if the mux is already started.
+func (m *Mux) SetClientPrewarm(enable bool) *Mux {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.isClient {
+		panic("Can't set prewarm in server mux")
+	}
+	if m.used {
+		panic("Can't set prewarm after mux is used")
+	}
+	m.prewarm = enable
+	if enable {
+		log.Infof("Mux underlay prewarm is enabled")
+	}
+	return m
+}
+
+// warmPoolSize returns the number of pre-established underlays
+// to keep for each endpoint. If underlays are not shared by sessions,
+// a burst of new sessions needs more than one underlay.
+// This method MUST be called only when holding the mu lock.
+func (m *Mux) warmPoolSize() int {
+	if m.multiplexFactor == 0 {
+		return 2
+	}
+	return 1
+}
+
+// takeWarmUnderlay removes a pre-established underlay of the endpoint
+// from the pool and returns it. It returns nil if the pool has no
+// usable underlay of the endpoint.
+// This method MUST be called only when holding the mu lock.
+func (m *Mux) takeWarmUnderlay(endpoint string, now time.Time) Underlay {
+	for i, w := range m.warmUnderlays {
+		if w.endpoint != endpoint || now.Sub(w.createTime) > maxWarmUnderlayAge {
+			continue
+		}
+		select {
+		case <-w.underlay.Done():
+			continue
+		default:
+		}
+		m.warmUnderlays = append(m.warmUnderlays[:i], m.warmUnderlays[i+1:]...)
+		return w.underlay
+	}
+	return nil
+}
+
+// kickPrewarm asks the background goroutine to refill the pool
+// without waiting for the next tick.
+func (m *Mux) kickPrewarm() {
+	select {
+	case m.prewarmKick <- struct{}{}:
+	default:
+	}
+}
+
+// refillWarmPool closes stale pre-established underlays and dials new
+// ones until each TCP endpoint has enough of them. UDP endpoints are
+// not pooled because creating a UDP underlay doesn't need a round trip.
+// This method MUST be called without holding the mu lock.
+func (m *Mux) refillWarmPool() {
+	m.mu.Lock()
+	if !m.prewarm || !m.used {
+		m.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	want := make(map[string]UnderlayProperties)
+	for _, p := range m.endpoints {
+		if p.TransportProtocol() == util.TCPTransport {
+			want[endpointKey(p)] = p
+		}
+	}
+	have := make(map[string]int)
+	remaining := make([]warmUnderlay, 0, len(m.warmUnderlays))
+	for _, w := range m.warmUnderlays {
+		stale := now.Sub(w.createTime) > maxWarmUnderlayAge
+		select {
+		case <-w.underlay.Done():
+			stale = true
+		default:
+		}
+		if _, ok := want[w.endpoint]; !ok || stale {
+			w.underlay.Close()
+			continue
+		}
+		remaining = append(remaining, w)
+		have[w.endpoint]++
+	}
+	m.warmUnderlays = remaining
+	var dial []UnderlayProperties
+	for key, p := range want {
+		for i := have[key]; i < m.warmPoolSize(); i++ {
+			dial = append(dial, p)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, p := range dial {
+		ctx, cancel := context.WithTimeout(context.Background(), idleUnderlayTickerInterval)
+		underlay, err := m.dialUnderlay(ctx, p, endpointKey(p))
+		cancel()
+		if err != nil {
+			log.Debugf("Unable to prewarm underlay: %v", err)
+			return
+		}
+		m.mu.Lock()
+		select {
+		case <-m.done:
+			underlay.Close()
+			m.mu.Unlock()
+			return
+		default:
+		}
+		m.warmUnderlays = append(m.warmUnderlays, warmUnderlay{
+			underlay:   underlay,
+			endpoint:   endpointKey(p),
+			createTime: time.Now(),
+		})
+		m.mu.Unlock()
+	}
+}
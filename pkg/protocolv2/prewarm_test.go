@@ -0,0 +1,119 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/testtool"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+func TestMuxPrewarm(t *testing.T) {
+	log.SetOutputToTest(t)
+	log.SetLevel("DEBUG")
+	port, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	serverProperties := NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil)
+	serverMux := NewMux(false).
+		SetServerUsers(users).
+		SetEndpoints([]UnderlayProperties{serverProperties})
+	testServer := testtool.NewTestHelperServer()
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	go testServer.Serve(serverMux)
+	defer testServer.Close()
+	defer serverMux.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	clientProperties := NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, nil, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port})
+	clientMux := NewMux(true).
+		SetClientPassword(cipher.HashPassword([]byte("kuiranbudong"), []byte("xiaochitang"))).
+		SetClientMultiplexFactor(0).
+		SetClientPrewarm(true).
+		SetEndpoints([]UnderlayProperties{clientProperties})
+	defer clientMux.Close()
+
+	echo := func() {
+		conn, err := clientMux.DialContext(context.Background())
+		if err != nil {
+			t.Fatalf("DialContext() failed: %v", err)
+		}
+		defer conn.Close()
+		if _, err := conn.Write([]byte("hello")); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+		if _, err := io.ReadFull(conn, make([]byte, 5)); err != nil {
+			t.Fatalf("io.ReadFull() failed: %v", err)
+		}
+	}
+
+	// The first session dials the underlay and fills the pool.
+	hits := UnderlayPrewarmHits.Load()
+	echo()
+	if got := UnderlayPrewarmHits.Load() - hits; got != 0 {
+		t.Errorf("got %d prewarm hits, want 0", got)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		clientMux.mu.Lock()
+		n := len(clientMux.warmUnderlays)
+		clientMux.mu.Unlock()
+		if n == clientMux.warmPoolSize() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The next sessions use the pre-established underlays.
+	echo()
+	echo()
+	if got := UnderlayPrewarmHits.Load() - hits; got != 2 {
+		t.Errorf("got %d prewarm hits, want 2", got)
+	}
+}
+
+func TestTakeWarmUnderlay(t *testing.T) {
+	now := time.Now()
+	fresh := &TCPUnderlay{baseUnderlay: *newBaseUnderlay(true, 1500, MimicryPlain)}
+	stale := &TCPUnderlay{baseUnderlay: *newBaseUnderlay(true, 1500, MimicryPlain)}
+	m := NewMux(true)
+	m.warmUnderlays = []warmUnderlay{
+		{underlay: stale, endpoint: "a", createTime: now.Add(-2 * maxWarmUnderlayAge)},
+		{underlay: fresh, endpoint: "a", createTime: now},
+	}
+	if got := m.takeWarmUnderlay("b", now); got != nil {
+		t.Errorf("takeWarmUnderlay() returned an underlay of another endpoint")
+	}
+	if got := m.takeWarmUnderlay("a", now); got != fresh {
+		t.Errorf("takeWarmUnderlay() didn't return the fresh underlay")
+	}
+	if got := m.takeWarmUnderlay("a", now); got != nil {
+		t.Errorf("takeWarmUnderlay() returned a stale underlay")
+	}
+	m.warmUnderlays = nil
+	m.Close()
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"sync"
+)
+
+// Priority decides the order to send segments of different sessions
+// when they are waiting for the same underlay.
+type Priority uint8
+
+const (
+	PriorityNormal      Priority = 0
+	PriorityInteractive Priority = 1
+	PriorityBulk        Priority = 2
+
+	numPriorities = 3
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBulk:
+		return "bulk"
+	default:
+		return "unknown"
+	}
+}
+
+// rank returns the order of the priority. Segments with a higher rank
+// are sent first.
+func (p Priority) rank() int {
+	switch p {
+	case PriorityInteractive:
+		return 2
+	case PriorityBulk:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// SetPriority changes the priority of the session.
+func (s *Session) SetPriority(p Priority) {
+	s.priority.Store(uint32(p))
+}
+
+// Priority returns the priority of the session.
+func (s *Session) Priority() Priority {
+	return Priority(s.priority.Load())
+}
+
+// priorityMutex is a mutual exclusion lock. When the lock is released,
+// it is handed over to the waiter with the highest priority. Waiters of
+// the same priority acquire the lock in FIFO order.
+//
+// The zero value is an unlocked mutex.
+type priorityMutex struct {
+	mu      sync.Mutex
+	locked  bool
+	waiters [numPriorities][]chan struct{}
+}
+
+// Lock acquires the mutex with the given priority.
+func (m *priorityMutex) Lock(p Priority) {
+	m.mu.Lock()
+	if !m.locked {
+		m.locked = true
+		m.mu.Unlock()
+		return
+	}
+	ch := make(chan struct{})
+	m.waiters[p.rank()] = append(m.waiters[p.rank()], ch)
+	m.mu.Unlock()
+	<-ch
+}
+
+// Unlock releases the mutex.
+func (m *priorityMutex) Unlock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.locked {
+		panic("unlock of unlocked priorityMutex")
+	}
+	for rank := numPriorities - 1; rank >= 0; rank-- {
+		if len(m.waiters[rank]) > 0 {
+			ch := m.waiters[rank][0]
+			m.waiters[rank][0] = nil
+			m.waiters[rank] = m.waiters[rank][1:]
+			close(ch)
+			return
+		}
+	}
+	m.locked = false
+}
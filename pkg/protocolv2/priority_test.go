@@ -0,0 +1,58 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriorityMutex(t *testing.T) {
+	var m priorityMutex
+	m.Lock(PriorityNormal)
+
+	order := make(chan Priority, 3)
+	waiting := func(n int) bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		total := 0
+		for _, w := range m.waiters {
+			total += len(w)
+		}
+		return total == n
+	}
+	for i, p := range []Priority{PriorityBulk, PriorityNormal, PriorityInteractive} {
+		go func(p Priority) {
+			m.Lock(p)
+			order <- p
+			m.Unlock()
+		}(p)
+		deadline := time.Now().Add(5 * time.Second)
+		for !waiting(i + 1) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timeout waiting for the lock waiters")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	m.Unlock()
+	for _, want := range []Priority{PriorityInteractive, PriorityNormal, PriorityBulk} {
+		if got := <-order; got != want {
+			t.Errorf("lock acquired by %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/metrics"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+// userThrottles maps a user name to the *util.RateLimiter shared by
+// the throttled sessions of the user.
+var userThrottles sync.Map
+
+// quotaStart returns the beginning of the current quota period.
+func quotaStart(quota *appctlpb.Quota, now time.Time) time.Time {
+	switch quota.GetPeriod() {
+	case appctlpb.QuotaPeriod_QUOTA_PERIOD_CALENDAR_MONTH:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case appctlpb.QuotaPeriod_QUOTA_PERIOD_TOTAL:
+		return time.Time{}
+	default:
+		return now.Add(-time.Duration(quota.GetDays()) * 24 * time.Hour)
+	}
+}
+
+// quotaDecision is the result of checking the quotas of a user.
+type quotaDecision struct {
+	// reject is true if new sessions must be rejected.
+	reject bool
+
+	// nearlyExhausted is true if a quota is almost used up.
+	nearlyExhausted bool
+
+	// throttleBytesPerSecond is the speed limit of the user.
+	// It is 0 if the speed is not limited.
+	throttleBytesPerSecond int64
+}
+
+// evaluateQuotas checks the quotas with the number of bytes used since
+// a given time. If several quotas are exhausted, rejection wins over
+// throttling, and the lowest speed limit is used.
+func evaluateQuotas(quotas []*appctlpb.Quota, usedBytes func(since time.Time) int64, now time.Time) quotaDecision {
+	var d quotaDecision
+	for _, quota := range quotas {
+		totalBytes := usedBytes(quotaStart(quota, now))
+		if totalBytes/1048576 > int64(quota.GetMegabytes()) {
+			if quota.GetAction() != appctlpb.QuotaAction_QUOTA_ACTION_THROTTLE {
+				d.reject = true
+				continue
+			}
+			rate := int64(quota.GetThrottleKilobytesPerSecond()) * 1024
+			if d.throttleBytesPerSecond == 0 || rate < d.throttleBytesPerSecond {
+				d.throttleBytesPerSecond = rate
+			}
+			continue
+		}
+		if float64(totalBytes)/1048576 > quotaWarningRatio*float64(quota.GetMegabytes()) {
+			d.nearlyExhausted = true
+		}
+	}
+	return d
+}
+
+// userUsedBytes returns a function that counts the bytes read and
+// written by the user from a given time to now.
+func userUsedBytes(userName string, now time.Time) (func(since time.Time) int64, error) {
+	metricGroupName := fmt.Sprintf(metrics.UserMetricGroupFormat, userName)
+	metricGroup := metrics.GetMetricGroupByName(metricGroupName)
+	if metricGroup == nil {
+		return nil, fmt.Errorf("metric group %s is not found", metricGroupName)
+	}
+	readBytes, found := metricGroup.GetMetric(metrics.UserMetricReadBytes)
+	if !found {
+		return nil, fmt.Errorf("metric %s in group %s is not found", metrics.UserMetricReadBytes, metricGroupName)
+	}
+	writeBytes, found := metricGroup.GetMetric(metrics.UserMetricWriteBytes)
+	if !found {
+		return nil, fmt.Errorf("metric %s in group %s is not found", metrics.UserMetricWriteBytes, metricGroupName)
+	}
+	return func(since time.Time) int64 {
+		return readBytes.(*metrics.Counter).DeltaBetween(since, now) + writeBytes.(*metrics.Counter).DeltaBetween(since, now)
+	}, nil
+}
+
+// QuotaUsage is the number of bytes used in the current period of a quota.
+type QuotaUsage struct {
+	Quota     *appctlpb.Quota
+	UsedBytes int64
+	Exhausted bool
+}
+
+// UserQuotaUsage returns the usage of each quota of the user. The used
+// bytes are 0 if the user has no traffic since the server is started.
+func UserQuotaUsage(user *appctlpb.User, now time.Time) []QuotaUsage {
+	usedBytes, err := userUsedBytes(user.GetName(), now)
+	if err != nil {
+		usedBytes = func(since time.Time) int64 { return 0 }
+	}
+	usage := make([]QuotaUsage, 0, len(user.GetQuotas()))
+	for _, quota := range user.GetQuotas() {
+		used := usedBytes(quotaStart(quota, now))
+		usage = append(usage, QuotaUsage{
+			Quota:     quota,
+			UsedBytes: used,
+			Exhausted: used/1048576 > int64(quota.GetMegabytes()),
+		})
+	}
+	return usage
+}
+
+// userThrottle returns the rate limiter shared by the throttled sessions
+// of the user, updated to the given speed.
+func userThrottle(userName string, bytesPerSecond int64) *util.RateLimiter {
+	return sharedRateLimiter(&userThrottles, userName, bytesPerSecond)
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestQuotaStart(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		quota *appctlpb.Quota
+		want  time.Time
+	}{
+		{
+			quota: &appctlpb.Quota{Days: proto.Int32(7)},
+			want:  time.Date(2024, 3, 8, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			quota: &appctlpb.Quota{Period: appctlpb.QuotaPeriod_QUOTA_PERIOD_CALENDAR_MONTH.Enum()},
+			want:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			quota: &appctlpb.Quota{Period: appctlpb.QuotaPeriod_QUOTA_PERIOD_TOTAL.Enum()},
+			want:  time.Time{},
+		},
+	}
+	for _, tc := range testCases {
+		if got := quotaStart(tc.quota, now); !got.Equal(tc.want) {
+			t.Errorf("quotaStart(%v) = %v, want %v", tc.quota, got, tc.want)
+		}
+	}
+}
+
+func TestEvaluateQuotas(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	monthStart := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	// 50 MiB used this month, 500 MiB used in total.
+	usedBytes := func(since time.Time) int64 {
+		if since.Before(monthStart) {
+			return 500 * 1048576
+		}
+		return 50 * 1048576
+	}
+	monthly := func(megabytes int32, action appctlpb.QuotaAction, kbps int32) *appctlpb.Quota {
+		return &appctlpb.Quota{
+			Period:                     appctlpb.QuotaPeriod_QUOTA_PERIOD_CALENDAR_MONTH.Enum(),
+			Megabytes:                  proto.Int32(megabytes),
+			Action:                     action.Enum(),
+			ThrottleKilobytesPerSecond: proto.Int32(kbps),
+		}
+	}
+	total := func(megabytes int32, action appctlpb.QuotaAction, kbps int32) *appctlpb.Quota {
+		return &appctlpb.Quota{
+			Period:                     appctlpb.QuotaPeriod_QUOTA_PERIOD_TOTAL.Enum(),
+			Megabytes:                  proto.Int32(megabytes),
+			Action:                     action.Enum(),
+			ThrottleKilobytesPerSecond: proto.Int32(kbps),
+		}
+	}
+	reject := appctlpb.QuotaAction_QUOTA_ACTION_REJECT
+	throttle := appctlpb.QuotaAction_QUOTA_ACTION_THROTTLE
+
+	testCases := []struct {
+		name   string
+		quotas []*appctlpb.Quota
+		want   quotaDecision
+	}{
+		{
+			name:   "within quota",
+			quotas: []*appctlpb.Quota{monthly(100, reject, 0), total(1000, reject, 0)},
+			want:   quotaDecision{},
+		},
+		{
+			name:   "nearly exhausted",
+			quotas: []*appctlpb.Quota{monthly(54, reject, 0)},
+			want:   quotaDecision{nearlyExhausted: true},
+		},
+		{
+			name:   "reject",
+			quotas: []*appctlpb.Quota{monthly(100, reject, 0), total(400, reject, 0)},
+			want:   quotaDecision{reject: true},
+		},
+		{
+			name:   "throttle with lowest speed",
+			quotas: []*appctlpb.Quota{monthly(40, throttle, 256), total(400, throttle, 64)},
+			want:   quotaDecision{throttleBytesPerSecond: 64 * 1024},
+		},
+		{
+			name:   "reject wins over throttle",
+			quotas: []*appctlpb.Quota{monthly(40, throttle, 256), total(400, reject, 0)},
+			want:   quotaDecision{reject: true, throttleBytesPerSecond: 256 * 1024},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := evaluateQuotas(tc.quotas, usedBytes, now); got != tc.want {
+				t.Errorf("evaluateQuotas() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUserThrottle(t *testing.T) {
+	l1 := userThrottle("TestUserThrottle", 1024)
+	l2 := userThrottle("TestUserThrottle", 2048)
+	if l1 != l2 {
+		t.Fatalf("userThrottle() returned different rate limiters for the same user")
+	}
+	if l1.Rate() != 2048 {
+		t.Errorf("Rate() = %d, want %d", l1.Rate(), 2048)
+	}
+}
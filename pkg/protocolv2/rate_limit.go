@@ -0,0 +1,113 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+const (
+	// maxSourceRateLimiters is the number of per source IP rate limiters
+	// that triggers removing the idle ones.
+	maxSourceRateLimiters = 4096
+
+	// sourceRateLimiterIdleTimeout is the time after which an unused
+	// per source IP rate limiter can be removed.
+	sourceRateLimiterIdleTimeout = 10 * time.Minute
+)
+
+var (
+	// userRateLimiters maps a user name to the *util.RateLimiter shared by
+	// all the sessions of the user.
+	userRateLimiters sync.Map
+
+	// sourceRateLimiters maps a user name and a source IP address to the
+	// *util.RateLimiter shared by the sessions of the user from that IP.
+	sourceRateLimiters     sync.Map
+	sourceRateLimiterCount atomic.Int64
+)
+
+// sharedRateLimiter returns the rate limiter of the key stored in m,
+// updated to the given speed. A new rate limiter is created if the key
+// doesn't exist.
+func sharedRateLimiter(m *sync.Map, key string, bytesPerSecond int64) *util.RateLimiter {
+	v, loaded := m.LoadOrStore(key, util.NewRateLimiter(bytesPerSecond))
+	l := v.(*util.RateLimiter)
+	if loaded && l.Rate() != bytesPerSecond {
+		l.SetRate(bytesPerSecond)
+	}
+	return l
+}
+
+// userRateLimits returns the rate limiters that apply to a session of
+// the user from the remote address. The returned list is empty if
+// the user has no rate limit.
+func userRateLimits(user *appctlpb.User, remoteAddr net.Addr) []*util.RateLimiter {
+	var limiters []*util.RateLimiter
+	rateLimit := user.GetRateLimit()
+	if rateLimit.GetKilobytesPerSecond() > 0 {
+		limiters = append(limiters, sharedRateLimiter(&userRateLimiters, user.GetName(), int64(rateLimit.GetKilobytesPerSecond())*1024))
+	}
+	if rateLimit.GetPerSourceIPKilobytesPerSecond() > 0 && !util.IsNilNetAddr(remoteAddr) {
+		ip := remoteAddr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+		key := user.GetName() + "/" + ip
+		if _, found := sourceRateLimiters.Load(key); !found {
+			if sourceRateLimiterCount.Add(1) > maxSourceRateLimiters {
+				removeIdleSourceRateLimiters(time.Now())
+			}
+		}
+		limiters = append(limiters, sharedRateLimiter(&sourceRateLimiters, key, int64(rateLimit.GetPerSourceIPKilobytesPerSecond())*1024))
+	}
+	return limiters
+}
+
+// removeIdleSourceRateLimiters removes the per source IP rate limiters
+// that are not used recently.
+func removeIdleSourceRateLimiters(now time.Time) {
+	var n int64
+	sourceRateLimiters.Range(func(k, v any) bool {
+		if now.Sub(v.(*util.RateLimiter).LastUsed()) > sourceRateLimiterIdleTimeout {
+			sourceRateLimiters.Delete(k)
+		} else {
+			n++
+		}
+		return true
+	})
+	sourceRateLimiterCount.Store(n)
+}
+
+// waitRateLimits blocks until n bytes can be transferred by all the
+// rate limiters.
+func waitRateLimits(limiters []*util.RateLimiter, n int) {
+	var wait time.Duration
+	for _, l := range limiters {
+		if d := l.Reserve(n); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUserRateLimits(t *testing.T) {
+	user := &appctlpb.User{
+		Name: proto.String("rate_limit_user"),
+		RateLimit: &appctlpb.RateLimit{
+			KilobytesPerSecond:            proto.Int32(1024),
+			PerSourceIPKilobytesPerSecond: proto.Int32(256),
+		},
+	}
+	addr1 := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1000}
+	addr2 := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 2000}
+	addr3 := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 1000}
+
+	l1 := userRateLimits(user, addr1)
+	l2 := userRateLimits(user, addr2)
+	l3 := userRateLimits(user, addr3)
+	if len(l1) != 2 || len(l2) != 2 || len(l3) != 2 {
+		t.Fatalf("got %d, %d, %d rate limiters, want 2 each", len(l1), len(l2), len(l3))
+	}
+	if l1[0] != l2[0] || l1[0] != l3[0] {
+		t.Errorf("sessions of the same user don't share the user rate limiter")
+	}
+	if l1[1] != l2[1] {
+		t.Errorf("sessions from the same IP address don't share the rate limiter")
+	}
+	if l1[1] == l3[1] {
+		t.Errorf("sessions from different IP addresses share the rate limiter")
+	}
+	if got := l1[0].Rate(); got != 1024*1024 {
+		t.Errorf("user rate = %d, want %d", got, 1024*1024)
+	}
+	if got := l1[1].Rate(); got != 256*1024 {
+		t.Errorf("per source IP rate = %d, want %d", got, 256*1024)
+	}
+
+	user.RateLimit.KilobytesPerSecond = proto.Int32(512)
+	if l := userRateLimits(user, addr1); l[0] != l1[0] || l1[0].Rate() != 512*1024 {
+		t.Errorf("user rate limiter is not updated")
+	}
+
+	if l := userRateLimits(&appctlpb.User{Name: proto.String("no_limit")}, addr1); len(l) != 0 {
+		t.Errorf("got %d rate limiters for user without rate limit, want 0", len(l))
+	}
+}
+
+func TestRemoveIdleSourceRateLimiters(t *testing.T) {
+	user := &appctlpb.User{
+		Name: proto.String("idle_user"),
+		RateLimit: &appctlpb.RateLimit{
+			PerSourceIPKilobytesPerSecond: proto.Int32(64),
+		},
+	}
+	l := userRateLimits(user, &net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 1000})
+	removeIdleSourceRateLimiters(time.Now())
+	if _, found := sourceRateLimiters.Load("idle_user/198.51.100.1"); !found {
+		t.Fatalf("rate limiter in use is removed")
+	}
+	removeIdleSourceRateLimiters(l[0].LastUsed().Add(2 * sourceRateLimiterIdleTimeout))
+	if _, found := sourceRateLimiters.Load("idle_user/198.51.100.1"); found {
+		t.Errorf("idle rate limiter is not removed")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// RetransmissionConfig holds the retransmission parameters of UDP sessions.
+// A zero value field uses the default.
+type RetransmissionConfig struct {
+	// InitialRTO is the retransmission timeout before the round trip time
+	// is measured.
+	InitialRTO time.Duration
+
+	// MinRTO is the minimum retransmission timeout.
+	MinRTO time.Duration
+
+	// MaxRTO is the maximum retransmission timeout, including backoff.
+	MaxRTO time.Duration
+
+	// BackoffMultiplier is multiplied to the retransmission timeout
+	// each time a segment is retransmitted. It must not be smaller than 1.
+	BackoffMultiplier float64
+}
+
+// retransmissionConfig is used by new sessions.
+var retransmissionConfig atomic.Pointer[RetransmissionConfig]
+
+// SetRetransmissionConfig changes the retransmission parameters of
+// new sessions. Existing sessions are not impacted.
+func SetRetransmissionConfig(config RetransmissionConfig) error {
+	if config.InitialRTO < 0 || config.MinRTO < 0 || config.MaxRTO < 0 {
+		return fmt.Errorf("retransmission timeout can't be negative")
+	}
+	if config.MinRTO > 0 && config.MaxRTO > 0 && config.MinRTO > config.MaxRTO {
+		return fmt.Errorf("minimum retransmission timeout %v is bigger than maximum %v", config.MinRTO, config.MaxRTO)
+	}
+	if config.BackoffMultiplier != 0 && config.BackoffMultiplier < 1 {
+		return fmt.Errorf("retransmission backoff multiplier %v is smaller than 1", config.BackoffMultiplier)
+	}
+	retransmissionConfig.Store(&config)
+	return nil
+}
+
+// applyRetransmissionConfig sets the retransmission parameters of the session.
+func (s *Session) applyRetransmissionConfig() {
+	s.txBackOff = txTimeoutBackOff
+	config := retransmissionConfig.Load()
+	if config == nil {
+		return
+	}
+	s.rttStat.SetInitialRTO(config.InitialRTO)
+	s.rttStat.SetRTOBounds(config.MinRTO, config.MaxRTO)
+	if config.BackoffMultiplier != 0 {
+		s.txBackOff = config.BackoffMultiplier
+	}
+}
+
+// txTimeout returns the time to wait for the acknowledgment of a segment
+// that has been transmitted txCount times.
+func (s *Session) txTimeout(txCount byte) time.Duration {
+	timeout := float64(s.rttStat.RTO()) * math.Pow(s.txBackOff, float64(txCount))
+	if timeout > math.MaxInt64 {
+		timeout = math.MaxInt64
+	}
+	return s.rttStat.ClampRTO(time.Duration(timeout))
+}
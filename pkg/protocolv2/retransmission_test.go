@@ -0,0 +1,51 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetRetransmissionConfig(t *testing.T) {
+	defer retransmissionConfig.Store(nil)
+
+	if err := SetRetransmissionConfig(RetransmissionConfig{MinRTO: 2 * time.Second, MaxRTO: time.Second}); err == nil {
+		t.Errorf("SetRetransmissionConfig() with minimum RTO bigger than maximum RTO succeeded, want error")
+	}
+	if err := SetRetransmissionConfig(RetransmissionConfig{BackoffMultiplier: 0.5}); err == nil {
+		t.Errorf("SetRetransmissionConfig() with backoff multiplier smaller than 1 succeeded, want error")
+	}
+
+	s := NewSession(1, true, 1500)
+	if got := s.txTimeout(0); got != time.Second {
+		t.Errorf("default txTimeout(0) = %v, want %v", got, time.Second)
+	}
+
+	if err := SetRetransmissionConfig(RetransmissionConfig{
+		InitialRTO:        2 * time.Second,
+		MaxRTO:            5 * time.Second,
+		BackoffMultiplier: 2,
+	}); err != nil {
+		t.Fatalf("SetRetransmissionConfig() failed: %v", err)
+	}
+	s = NewSession(2, true, 1500)
+	for txCount, want := range map[byte]time.Duration{0: 2 * time.Second, 1: 4 * time.Second, 2: 5 * time.Second} {
+		if got := s.txTimeout(txCount); got != want {
+			t.Errorf("txTimeout(%d) = %v, want %v", txCount, got, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"math"
+	"time"
+)
+
+// ackState is the acknowledgment information attached to
+// outgoing data and ack segments of a UDP session.
+type ackState struct {
+	unAckSeq uint32
+	sack     [maxSackBlocks]sackBlock
+	hasSack  bool
+}
+
+// apply writes the acknowledgment information to the metadata.
+func (st ackState) apply(das *dataAckStruct) {
+	das.unAckSeq = st.unAckSeq
+	if st.hasSack {
+		das.flags |= flagSelectiveAck
+		das.sack = st.sack
+	} else {
+		das.flags &^= flagSelectiveAck
+		das.sack = [maxSackBlocks]sackBlock{}
+	}
+}
+
+// currentAckState returns the acknowledgment information of the session.
+// Segments received out of order are reported with selective acknowledgment
+// blocks: the first block is the range with the smallest sequence numbers,
+// and the second block is the range with the largest sequence numbers,
+// which is the most recently received in most cases.
+func (s *Session) currentAckState() ackState {
+	st := ackState{unAckSeq: s.nextRecv}
+	if s.recvBuf.Len() == 0 {
+		return st
+	}
+	var current sackBlock
+	var ranges int
+	record := func(block sackBlock) {
+		if ranges == 0 {
+			st.sack[0] = block
+		} else {
+			st.sack[1] = block
+		}
+		ranges++
+	}
+	s.recvBuf.Ascend(func(iter *segment) bool {
+		seq, err := iter.Seq()
+		if err != nil || seq < st.unAckSeq {
+			return true
+		}
+		if seq-st.unAckSeq > math.MaxUint16 {
+			return false
+		}
+		offset := uint16(seq - st.unAckSeq)
+		end := uint32(current.offset) + uint32(current.length)
+		if current.length > 0 && uint32(offset) < end {
+			// Duplicated sequence number.
+			return true
+		}
+		if current.length > 0 && uint32(offset) == end && current.length < math.MaxUint8 {
+			current.length++
+			return true
+		}
+		if current.length > 0 {
+			record(current)
+		}
+		current = sackBlock{offset: offset, length: 1}
+		return true
+	})
+	if current.length > 0 {
+		record(current)
+	}
+	st.hasSack = ranges > 0
+	return st
+}
+
+// onSelectiveAck marks the segments in sendBuf that are selectively
+// acknowledged by the peer, so they are not retransmitted.
+func (s *Session) onSelectiveAck(das *dataAckStruct) {
+	if das.flags&flagSelectiveAck == 0 {
+		return
+	}
+	var maxEnd uint32
+	for _, block := range das.sack {
+		if end := das.unAckSeq + uint32(block.offset) + uint32(block.length); end > maxEnd {
+			maxEnd = end
+		}
+	}
+	s.sendBuf.Ascend(func(iter *segment) bool {
+		seq, _ := iter.Seq()
+		if seq >= maxEnd {
+			return false
+		}
+		if !iter.acked && das.isSelectivelyAcked(seq) {
+			iter.acked = true
+			s.onSegmentAcked(iter)
+			UnderlayUDPSelectiveAcks.Add(1)
+		}
+		return true
+	})
+}
+
+// onSegmentAcked updates RTT and congestion control after a segment
+// is acknowledged by the peer.
+func (s *Session) onSegmentAcked(seg *segment) {
+	s.rttStat.UpdateRTT(time.Since(seg.txTime))
+	s.smoothedRTT.Store(int64(s.rttStat.SmoothedRTT()))
+	s.sendAlgorithm.OnAck()
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestDataSegment(seq uint32) *segment {
+	return &segment{
+		metadata: &dataAckStruct{
+			baseStruct: baseStruct{
+				protocol: uint8(dataClientToServer),
+			},
+			sessionID: 1,
+			seq:       seq,
+		},
+		payload: []byte{0},
+	}
+}
+
+func TestCurrentAckState(t *testing.T) {
+	s := NewSession(1, false, 1500)
+	s.nextRecv = 10
+	if st := s.currentAckState(); st.hasSack || st.unAckSeq != 10 {
+		t.Errorf("currentAckState() = %+v, want no selective acknowledgment", st)
+	}
+
+	for _, seq := range []uint32{12, 13, 14, 17, 20, 21} {
+		s.recvBuf.InsertBlocking(newTestDataSegment(seq))
+	}
+	st := s.currentAckState()
+	if !st.hasSack {
+		t.Fatalf("currentAckState() has no selective acknowledgment")
+	}
+	want := [maxSackBlocks]sackBlock{{offset: 2, length: 3}, {offset: 10, length: 2}}
+	if st.sack != want {
+		t.Errorf("sack blocks = %v, want %v", st.sack, want)
+	}
+
+	das := &dataAckStruct{}
+	st.apply(das)
+	if das.flags&flagSelectiveAck == 0 || das.unAckSeq != 10 {
+		t.Errorf("apply() got %v", das)
+	}
+	ackState{unAckSeq: 11}.apply(das)
+	if das.flags&flagSelectiveAck != 0 || das.sack != ([maxSackBlocks]sackBlock{}) {
+		t.Errorf("apply() didn't clear selective acknowledgment: %v", das)
+	}
+}
+
+func TestOnSelectiveAck(t *testing.T) {
+	s := NewSession(1, true, 1500)
+	for seq := uint32(0); seq < 6; seq++ {
+		seg := newTestDataSegment(seq)
+		seg.txTime = time.Now()
+		s.sendBuf.InsertBlocking(seg)
+	}
+	ack := &dataAckStruct{
+		baseStruct: baseStruct{
+			protocol: uint8(ackServerToClient),
+			flags:    flagSelectiveAck,
+		},
+		unAckSeq: 1,
+		sack:     [maxSackBlocks]sackBlock{{offset: 1, length: 2}, {offset: 4, length: 1}},
+	}
+	s.onSelectiveAck(ack)
+
+	var acked []uint32
+	s.sendBuf.Ascend(func(iter *segment) bool {
+		if iter.acked {
+			seq, _ := iter.Seq()
+			acked = append(acked, seq)
+		}
+		return true
+	})
+	if len(acked) != 3 || acked[0] != 2 || acked[1] != 3 || acked[2] != 5 {
+		t.Errorf("selectively acknowledged segments = %v, want [2 3 5]", acked)
+	}
+}
@@ -93,6 +93,8 @@ type segment struct {
 	txTime    time.Time              // most recent tx time
 	txTimeout time.Duration          // need to receive ACK within this duration
 	block     cipher.BlockCipher     // cipher block to encrypt or decrypt the payload
+	acked     bool                   // selectively acknowledged by the peer
+	priority  Priority               // priority of the session
 }
 
 // Protocol returns the protocol of the segment.
@@ -204,7 +206,7 @@ func (t *segmentTree) Insert(seg *segment) (ok bool) {
 	prev, replace := t.tr.ReplaceOrInsert(seg)
 	if replace {
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v is replaced by %v", prev, seg)
+			segmentReplaceLogSampler.Tracef("%v is replaced by %v", prev, seg)
 		}
 	} else {
 		t.notifyNotEmpty()
@@ -227,7 +229,7 @@ func (t *segmentTree) InsertBlocking(seg *segment) {
 	prev, replace := t.tr.ReplaceOrInsert(seg)
 	if replace {
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v is replaced by %v", prev, seg)
+			segmentReplaceLogSampler.Tracef("%v is replaced by %v", prev, seg)
 		}
 	} else {
 		t.notifyNotEmpty()
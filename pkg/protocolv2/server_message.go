@@ -0,0 +1,160 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/stderror"
+)
+
+const (
+	// MaxServerMessageLength is the maximum number of bytes in a server message.
+	MaxServerMessageLength = 512
+
+	// maxRecentServerMessages is the maximum number of server messages
+	// kept by the client.
+	maxRecentServerMessages = 16
+
+	// quotaWarningRatio is the fraction of a quota that triggers
+	// a quota warning message.
+	quotaWarningRatio = 0.9
+
+	// quotaWarningInterval is the minimum interval between two quota
+	// warning messages sent to the same user.
+	quotaWarningInterval = time.Hour
+
+	quotaWarningMessage = "Your traffic quota is nearly exhausted."
+)
+
+// ServerMessage is a text message sent from proxy server to proxy client.
+type ServerMessage struct {
+	Text        string
+	ReceiveTime time.Time
+}
+
+var (
+	recentServerMessagesMu sync.Mutex
+	recentServerMessages   []ServerMessage
+
+	// quotaWarningTime records the last time a quota warning
+	// is sent to a user.
+	quotaWarningTime sync.Map // Map<userName, time.Time>
+)
+
+// RecentServerMessages returns the most recent server messages received
+// by the client, from the oldest to the newest.
+func RecentServerMessages() []ServerMessage {
+	recentServerMessagesMu.Lock()
+	defer recentServerMessagesMu.Unlock()
+	res := make([]ServerMessage, len(recentServerMessages))
+	copy(res, recentServerMessages)
+	return res
+}
+
+func recordServerMessage(text string) {
+	log.Infof("Message from proxy server: %s", text)
+	recentServerMessagesMu.Lock()
+	defer recentServerMessagesMu.Unlock()
+	recentServerMessages = append(recentServerMessages, ServerMessage{
+		Text:        text,
+		ReceiveTime: time.Now(),
+	})
+	if len(recentServerMessages) > maxRecentServerMessages {
+		recentServerMessages = recentServerMessages[len(recentServerMessages)-maxRecentServerMessages:]
+	}
+}
+
+func shouldSendQuotaWarning(userName string) bool {
+	now := time.Now()
+	if last, ok := quotaWarningTime.Load(userName); ok && now.Sub(last.(time.Time)) < quotaWarningInterval {
+		return false
+	}
+	quotaWarningTime.Store(userName, now)
+	return true
+}
+
+// SendServerMessage sends a text message to proxy clients connected to
+// the server. If userName is not empty, only the sessions of that user
+// receive the message. Clients that are unable to receive server messages
+// are skipped. It returns the number of sessions the message is sent to.
+//
+// Delivery is best effort. A message sent over UDP may be lost.
+func (m *Mux) SendServerMessage(userName, text string) (int, error) {
+	if m.isClient {
+		return 0, stderror.ErrInvalidOperation
+	}
+	if text == "" {
+		return 0, fmt.Errorf("server message is empty")
+	}
+	if len(text) > MaxServerMessageLength {
+		return 0, fmt.Errorf("server message size %d exceeds maximum value %d", len(text), MaxServerMessageLength)
+	}
+
+	sessions := make([]*Session, 0)
+	m.mu.Lock()
+	for _, underlay := range m.underlays {
+		var b *baseUnderlay
+		switch u := underlay.(type) {
+		case *TCPUnderlay:
+			b = &u.baseUnderlay
+		case *UDPUnderlay:
+			b = &u.baseUnderlay
+		default:
+			continue
+		}
+		b.sessionMap.Range(func(id uint32, s *Session) bool {
+			sessions = append(sessions, s)
+			return true
+		})
+	}
+	m.mu.Unlock()
+
+	cnt := 0
+	for _, session := range sessions {
+		if !session.acceptServerMessage.Load() || !session.isState(sessionEstablished) {
+			continue
+		}
+		if userName != "" && (session.block == nil || session.block.BlockContext().UserName != userName) {
+			continue
+		}
+		if err := session.sendServerMessage(text); err != nil {
+			log.Debugf("%v sendServerMessage() failed: %v", session, err)
+			continue
+		}
+		cnt++
+	}
+	return cnt, nil
+}
+
+// sendServerMessage sends a text message to the client of this session.
+func (s *Session) sendServerMessage(text string) error {
+	seg := &segment{
+		metadata: &sessionStruct{
+			baseStruct: baseStruct{
+				protocol: uint8(serverMessage),
+			},
+			sessionID:  s.id,
+			payloadLen: uint16(len(text)),
+		},
+		payload:   []byte(text),
+		transport: s.conn.TransportProtocol(),
+	}
+	return s.output(seg, s.RemoteAddr())
+}
@@ -19,18 +19,20 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"math"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/enfein/mieru/pkg/appctl/appctlpb"
 	"github.com/enfein/mieru/pkg/cipher"
 	"github.com/enfein/mieru/pkg/congestion"
+	"github.com/enfein/mieru/pkg/event"
 	"github.com/enfein/mieru/pkg/log"
 	"github.com/enfein/mieru/pkg/mathext"
 	"github.com/enfein/mieru/pkg/metrics"
 	"github.com/enfein/mieru/pkg/stderror"
+	"github.com/enfein/mieru/pkg/tracing"
 	"github.com/enfein/mieru/pkg/util"
 )
 
@@ -86,6 +88,20 @@ type Session struct {
 	status     statusCode   // session status
 	users      map[string]*appctlpb.User
 
+	// acceptServerMessage is set at server side
+	// if the client is able to receive server messages.
+	acceptServerMessage atomic.Bool
+
+	// userName is set at server side to the user who opened the session.
+	userName atomic.Pointer[string]
+
+	// releaseConnectionLimit is set at server side to give back the
+	// session counted by the connection limits.
+	releaseConnectionLimit atomic.Pointer[func()]
+
+	// tap mirrors byte counts and timing of the session for debugging.
+	tap atomic.Pointer[sessionTap]
+
 	ready         chan struct{} // indicate the session is ready to use
 	done          chan struct{} // indicate the session is complete
 	readDeadline  time.Time     // read deadline
@@ -99,19 +115,47 @@ type Session struct {
 	recvQueue *segmentTree  // segments waiting to be read by application
 	recvChan  chan *segment // channel to receive segments from underlay
 
-	nextSend      uint32    // next sequence number to send a segment
-	nextRecv      uint32    // next sequence number to receive
-	lastRecvAcked uint32    // last receive sequence number that is acked
-	lastRXTime    time.Time // last timestamp when a segment is received
-	lastTXTime    time.Time // last timestamp when a segment is sent
-	unreadBuf     []byte    // payload removed from the recvQueue that haven't been read by application
+	nextSend      uint32       // next sequence number to send a segment
+	nextRecv      uint32       // next sequence number to receive
+	lastRecvAcked uint32       // last receive sequence number that is acked
+	lastRXTime    atomic.Int64 // last unix nanoseconds when a segment is received
+	lastTXTime    time.Time    // last timestamp when a segment is sent
+	unreadBuf     []byte       // payload removed from the recvQueue that haven't been read by application
 
 	readBytes  metrics.Metric // number of bytes delivered to the application
 	writeBytes metrics.Metric // number of bytes sent from the application
 
+	// Per session counters and smoothed RTT, exported by ToSessionInfo.
+	createTime   time.Time
+	bytesRead    atomic.Int64
+	bytesWritten atomic.Int64
+	smoothedRTT  atomic.Int64 // nanoseconds
+	segmentsSent atomic.Int64 // UDP segments sent for the first time
+	retransmits  atomic.Int64 // UDP segments sent again
+
+	traceCtx context.Context // carries the span of the session
+	span     *tracing.Span   // nil if tracing is disabled
+
+	// throttle limits the speed of the session after the user quota is
+	// exhausted. It is nil if the speed is not limited.
+	throttle atomic.Pointer[util.RateLimiter]
+
+	// rateLimits are the speed limits of the user that apply to the
+	// session. It is nil if the user has no speed limit.
+	rateLimits atomic.Pointer[[]*util.RateLimiter]
+
 	rttStat          *congestion.RTTStats
 	sendAlgorithm    *congestion.CubicSendAlgorithm
-	remoteWindowSize uint16
+	remoteWindowSize atomic.Uint32 // window size advertised by the peer
+	txBackOff        float64       // multiplier of retransmission timeout
+	protocolVersion  atomic.Uint32 // negotiated protocol version
+	priority         atomic.Uint32
+
+	recvWindow     atomic.Int32 // receive window size, in number of segments
+	maxRecvWindow  int
+	recvAutoTune   bool
+	recvEpochStart time.Time // start of the receive window auto-tuning epoch
+	recvEpochCount int       // number of segments received in the epoch
 
 	wg    sync.WaitGroup
 	rLock sync.Mutex
@@ -122,37 +166,62 @@ type Session struct {
 
 // Session must implement net.Conn interface.
 var _ net.Conn = &Session{}
+var _ io.WriterTo = &Session{}
 
 // NewSession creates a new session.
 func NewSession(id uint32, isClient bool, mtu int) *Session {
 	rttStat := congestion.NewRTTStats()
 	rttStat.SetMaxAckDelay(segmentAckDelay)
 	rttStat.SetRTOMultiplier(1.5)
-	return &Session{
-		conn:             nil,
-		block:            nil,
-		id:               id,
-		isClient:         isClient,
-		mtu:              mtu,
-		state:            sessionInit,
-		status:           statusOK,
-		ready:            make(chan struct{}),
-		done:             make(chan struct{}),
-		readDeadline:     util.ZeroTime(),
-		writeDeadline:    util.ZeroTime(),
-		inputErr:         make(chan error, 2), // allow nested
-		outputErr:        make(chan error, 2), // allow nested
-		sendQueue:        newSegmentTree(segmentTreeCapacity),
-		sendBuf:          newSegmentTree(segmentTreeCapacity),
-		recvBuf:          newSegmentTree(segmentTreeCapacity),
-		recvQueue:        newSegmentTree(segmentTreeCapacity),
-		recvChan:         make(chan *segment, segmentChanCapacity),
-		lastRXTime:       time.Now(),
-		lastTXTime:       time.Now(),
-		rttStat:          rttStat,
-		sendAlgorithm:    congestion.NewCubicSendAlgorithm(minWindowSize, maxWindowSize),
-		remoteWindowSize: minWindowSize,
+	s := &Session{
+		conn:          nil,
+		block:         nil,
+		id:            id,
+		isClient:      isClient,
+		mtu:           mtu,
+		state:         sessionInit,
+		status:        statusOK,
+		ready:         make(chan struct{}),
+		done:          make(chan struct{}),
+		readDeadline:  util.ZeroTime(),
+		writeDeadline: util.ZeroTime(),
+		inputErr:      make(chan error, 2), // allow nested
+		outputErr:     make(chan error, 2), // allow nested
+		sendQueue:     newSegmentTree(segmentTreeCapacity),
+		sendBuf:       newSegmentTree(segmentTreeCapacity),
+		recvBuf:       newSegmentTree(segmentTreeCapacity),
+		recvQueue:     newSegmentTree(segmentTreeCapacity),
+		recvChan:      make(chan *segment, segmentChanCapacity),
+		createTime:    time.Now(),
+		lastTXTime:    time.Now(),
+		rttStat:       rttStat,
+		traceCtx:      context.Background(),
 	}
+	s.applyRetransmissionConfig()
+	s.applyFlowControlConfig()
+	s.remoteWindowSize.Store(minWindowSize)
+	s.lastRXTime.Store(time.Now().UnixNano())
+	return s
+}
+
+// startTrace starts the span of the session lifetime. The span is a child
+// of the span carried by ctx.
+func (s *Session) startTrace(ctx context.Context) {
+	s.traceCtx, s.span = tracing.Start(ctx, "mieru.session", tracing.Int64("session.id", int64(s.id)), tracing.Bool("session.client", s.isClient))
+}
+
+// TraceContext implements tracing.Carrier.
+func (s *Session) TraceContext() context.Context {
+	return s.traceCtx
+}
+
+// UserName returns the name of the user who opened the session.
+// It is empty at client side.
+func (s *Session) UserName() string {
+	if name := s.userName.Load(); name != nil {
+		return *name
+	}
+	return ""
 }
 
 func (s *Session) String() string {
@@ -176,26 +245,85 @@ func (s *Session) Read(b []byte) (n int, err error) {
 		s.readDeadline = util.ZeroTime()
 	}()
 	if log.IsLevelEnabled(log.TraceLevel) {
-		log.Tracef("%v trying to read %d bytes", s, len(b))
+		sessionReadStartLogSampler.Tracef("%v trying to read %d bytes", s, len(b))
 	}
 
 	// Read remaining data that application failed to read last time.
-	if len(s.unreadBuf) > 0 {
-		n = copy(b, s.unreadBuf)
-		if n == len(s.unreadBuf) {
-			s.unreadBuf = nil
-		} else {
-			s.unreadBuf = s.unreadBuf[n:]
+	// If there is no such data, wait for new segments.
+	if len(s.unreadBuf) == 0 {
+		payloads, err := s.waitPayloads()
+		if err != nil {
+			return 0, err
 		}
-		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v read %d bytes", s, n)
+		for _, payload := range payloads {
+			s.unreadBuf = append(s.unreadBuf, payload...)
 		}
-		if s.readBytes != nil {
-			s.readBytes.Add(int64(n))
+	}
+
+	n = copy(b, s.unreadBuf)
+	if n == len(s.unreadBuf) {
+		s.unreadBuf = nil
+	} else {
+		s.unreadBuf = s.unreadBuf[n:]
+	}
+	if log.IsLevelEnabled(log.TraceLevel) {
+		sessionReadDoneLogSampler.Tracef("%v read %d bytes", s, n)
+	}
+	s.recordRead(n)
+	return n, nil
+}
+
+// WriteTo writes the data from receive queue to w until the session
+// is closed. The payload of each segment is written to w directly,
+// without copying it to an intermediate buffer. It implements io.WriterTo,
+// which is preferred by io.Copy.
+func (s *Session) WriteTo(w io.Writer) (written int64, err error) {
+	for {
+		payloads, err := s.readPayloads()
+		if err != nil {
+			if err == io.EOF {
+				return written, nil
+			}
+			return written, err
+		}
+		for _, payload := range payloads {
+			n, err := w.Write(payload)
+			written += int64(n)
+			s.recordRead(n)
+			if err != nil {
+				return written, err
+			}
 		}
-		return n, nil
 	}
+}
+
+// readPayloads returns the data that application failed to read last time,
+// or waits for the payloads of new segments.
+func (s *Session) readPayloads() ([][]byte, error) {
+	s.rLock.Lock()
+	defer s.rLock.Unlock()
+	if s.isStateBefore(sessionAttached, false) {
+		return nil, fmt.Errorf("%v is not ready for WriteTo()", s)
+	}
+	if s.isStateAfter(sessionClosed, true) {
+		return nil, io.ErrClosedPipe
+	}
+	defer func() {
+		s.readDeadline = util.ZeroTime()
+	}()
 
+	if len(s.unreadBuf) > 0 {
+		payload := s.unreadBuf
+		s.unreadBuf = nil
+		return [][]byte{payload}, nil
+	}
+	return s.waitPayloads()
+}
+
+// waitPayloads waits until some segments in receive queue are ready to read,
+// and returns the non-empty payloads of those segments.
+// The caller must hold rLock.
+func (s *Session) waitPayloads() ([][]byte, error) {
 	// Stop reading when deadline is reached.
 	var timeC <-chan time.Time
 	if !util.IsZeroTime(s.readDeadline) {
@@ -205,6 +333,7 @@ func (s *Session) Read(b []byte) (n int, err error) {
 	for {
 		if s.recvQueue.Len() > 0 {
 			// Some segments in segment tree are ready to read.
+			var payloads [][]byte
 			for {
 				seg, ok := s.recvQueue.DeleteMin()
 				if !ok {
@@ -213,42 +342,38 @@ func (s *Session) Read(b []byte) (n int, err error) {
 				if s.isClient && seg.metadata.Protocol() == openSessionResponse && s.isState(sessionAttached) {
 					s.forwardStateTo(sessionEstablished)
 				}
-				if s.unreadBuf == nil {
-					s.unreadBuf = make([]byte, 0)
+				if len(seg.payload) > 0 {
+					payloads = append(payloads, seg.payload)
 				}
-				s.unreadBuf = append(s.unreadBuf, seg.payload...)
 			}
-			if len(s.unreadBuf) > 0 {
-				break
+			if len(payloads) > 0 {
+				return payloads, nil
 			}
 		} else {
 			// Wait for incoming segments.
 			select {
 			case <-s.done:
-				return 0, io.EOF
+				return nil, io.EOF
 			case <-s.inputErr:
-				return 0, io.ErrUnexpectedEOF
+				return nil, io.ErrUnexpectedEOF
 			case <-timeC:
-				return 0, stderror.ErrTimeout
+				return nil, stderror.ErrTimeout
 			case <-s.recvQueue.chanNotEmptyEvent:
 				// New segments are ready to read.
 			}
 		}
 	}
+}
 
-	n = copy(b, s.unreadBuf)
-	if n == len(s.unreadBuf) {
-		s.unreadBuf = nil
-	} else {
-		s.unreadBuf = s.unreadBuf[n:]
-	}
-	if log.IsLevelEnabled(log.TraceLevel) {
-		log.Tracef("%v read %d bytes", s, n)
-	}
+// recordRead updates the counters after n bytes are read by application,
+// and blocks if the session is throttled.
+func (s *Session) recordRead(n int) {
+	s.bytesRead.Add(int64(n))
 	if s.readBytes != nil {
 		s.readBytes.Add(int64(n))
 	}
-	return n, nil
+	s.recordTap("read", n)
+	s.waitThrottle(n)
 }
 
 // Write stores the data to send queue.
@@ -272,9 +397,11 @@ func (s *Session) Write(b []byte) (n int, err error) {
 			metadata: &sessionStruct{
 				baseStruct: baseStruct{
 					protocol: uint8(openSessionRequest),
+					flags:    flagServerMessage | flagKeepalive | flagServerTime | flagVersion,
 				},
 				sessionID: s.id,
 				seq:       s.nextSend,
+				version:   currentProtocolVersion,
 			},
 			transport: s.conn.TransportProtocol(),
 		}
@@ -285,17 +412,19 @@ func (s *Session) Write(b []byte) (n int, err error) {
 			copy(seg.payload, b)
 		}
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v writing %d bytes with open session request", s, len(seg.payload))
+			sessionWriteStartLogSampler.Tracef("%v writing %d bytes with open session request", s, len(seg.payload))
 		}
 		s.sendQueue.InsertBlocking(seg)
 		if len(seg.payload) > 0 {
+			s.bytesWritten.Add(int64(len(seg.payload)))
+			s.recordTap("write", len(seg.payload))
 			return len(seg.payload), nil
 		}
 	}
 
 	n = len(b)
 	if log.IsLevelEnabled(log.TraceLevel) {
-		log.Tracef("%v writing %d bytes", s, n)
+		sessionWriteStartLogSampler.Tracef("%v writing %d bytes", s, n)
 	}
 	for len(b) > 0 {
 		sizeToSend := mathext.Min(len(b), maxPDU)
@@ -305,11 +434,14 @@ func (s *Session) Write(b []byte) (n int, err error) {
 		b = b[sizeToSend:]
 	}
 	if log.IsLevelEnabled(log.TraceLevel) {
-		log.Tracef("%v wrote %d bytes", s, n)
+		sessionWriteDoneLogSampler.Tracef("%v wrote %d bytes", s, n)
 	}
+	s.bytesWritten.Add(int64(n))
 	if s.writeBytes != nil {
 		s.writeBytes.Add(int64(n))
 	}
+	s.recordTap("write", n)
+	s.waitThrottle(n)
 	return n, nil
 }
 
@@ -325,6 +457,10 @@ func (s *Session) Close() error {
 	}
 
 	log.Debugf("Closing %v", s)
+	if tap := s.tap.Swap(nil); tap != nil {
+		tap.record("close", 0)
+		tap.close()
+	}
 	s.sendQueue.DeleteAll()
 	s.sendBuf.DeleteAll()
 	s.recvBuf.DeleteAll()
@@ -366,8 +502,13 @@ func (s *Session) Close() error {
 
 	s.forwardStateTo(sessionClosed)
 	close(s.done)
+	if release := s.releaseConnectionLimit.Swap(nil); release != nil {
+		(*release)()
+	}
 	log.Debugf("Closed %v", s)
 	metrics.CurrEstablished.Add(-1)
+	s.span.SetAttributes(tracing.Int64("session.bytes_read", s.bytesRead.Load()), tracing.Int64("session.bytes_written", s.bytesWritten.Load()))
+	s.span.End()
 	return nil
 }
 
@@ -404,14 +545,28 @@ func (s *Session) SetWriteDeadline(t time.Time) error {
 // ToSessionInfo creates related SessionInfo structure.
 func (s *Session) ToSessionInfo() SessionInfo {
 	info := SessionInfo{
-		ID:         fmt.Sprintf("%d", s.id),
-		LocalAddr:  s.LocalAddr().String(),
-		RemoteAddr: s.RemoteAddr().String(),
-		State:      s.state.String(),
-		RecvQBuf:   fmt.Sprintf("%d+%d", s.recvQueue.Len(), s.recvBuf.Len()),
-		SendQBuf:   fmt.Sprintf("%d+%d", s.sendQueue.Len(), s.sendBuf.Len()),
-		LastRecv:   fmt.Sprintf("%v", time.Since(s.lastRXTime).Truncate(time.Second)),
-		LastSend:   fmt.Sprintf("%v", time.Since(s.lastTXTime).Truncate(time.Second)),
+		ID:              s.id,
+		LocalAddr:       s.LocalAddr().String(),
+		RemoteAddr:      s.RemoteAddr().String(),
+		State:           s.state.String(),
+		RecvQueue:       s.recvQueue.Len(),
+		RecvBuf:         s.recvBuf.Len(),
+		SendQueue:       s.sendQueue.Len(),
+		SendBuf:         s.sendBuf.Len(),
+		LastRecv:        time.Since(time.Unix(0, s.lastRXTime.Load())),
+		LastSend:        time.Since(s.lastTXTime),
+		BytesRead:       s.bytesRead.Load(),
+		BytesWritten:    s.bytesWritten.Load(),
+		SmoothedRTT:     time.Duration(s.smoothedRTT.Load()),
+		Age:             time.Since(s.createTime),
+		SegmentsSent:    s.segmentsSent.Load(),
+		Retransmits:     s.retransmits.Load(),
+		PeerWindow:      int(s.remoteWindowSize.Load()),
+		RecvWindow:      int(s.recvWindow.Load()),
+		ProtocolVersion: int(s.ProtocolVersion()),
+	}
+	if s.sendAlgorithm != nil {
+		info.CongestionWindow = int(s.sendAlgorithm.CongestionWindowSize())
 	}
 	if _, ok := s.conn.(*TCPUnderlay); ok {
 		info.Protocol = "TCP"
@@ -505,7 +660,7 @@ func (s *Session) writeChunk(b []byte) (n int, err error) {
 				sessionID:  s.id,
 				seq:        s.nextSend,
 				unAckSeq:   s.nextRecv,
-				windowSize: uint16(mathext.Max(0, int(s.sendAlgorithm.CongestionWindowSize())-s.recvBuf.Len())),
+				windowSize: s.receiveWindowSize(),
 				fragment:   uint8(i),
 				payloadLen: uint16(partLen),
 			},
@@ -572,12 +727,25 @@ func (s *Session) runOutputLoop(ctx context.Context) error {
 				}
 			}
 		case util.UDPTransport:
+			if err := s.checkDeadPeer(time.Now()); err != nil {
+				UnderlayDeadPeers.Add(1)
+				log.Debugf("%v detected dead peer: %v", s, err)
+				s.outputErr <- err
+				s.Close()
+				continue
+			}
 			closeSession := false
 			hasTimeout := false
+			ack := s.currentAckState()
 
 			// Resend segments in sendBuf.
 			// To avoid deadlock, session can't be closed inside Ascend().
+			var resend []*segment
 			s.sendBuf.Ascend(func(iter *segment) bool {
+				if iter.acked {
+					// The peer has received this segment.
+					return true
+				}
 				if iter.txCount >= txCountLimit {
 					err := fmt.Errorf("too many retransmission of %v", iter)
 					log.Debugf("%v is unhealthy: %v", s, err)
@@ -588,23 +756,27 @@ func (s *Session) runOutputLoop(ctx context.Context) error {
 				if time.Since(iter.txTime) > iter.txTimeout {
 					hasTimeout = true
 					iter.txCount++
+					UnderlayUDPRetransmits.Add(1)
+					s.retransmits.Add(1)
 					iter.txTime = time.Now()
-					iter.txTimeout = s.rttStat.RTO() * time.Duration(math.Pow(txTimeoutBackOff, float64(iter.txCount)))
+					iter.txTimeout = s.txTimeout(iter.txCount)
 					if isDataAckProtocol(iter.metadata.Protocol()) {
 						das, _ := toDataAckStruct(iter.metadata)
-						das.unAckSeq = s.nextRecv
-					}
-					if err := s.output(iter, s.RemoteAddr()); err != nil {
-						err = fmt.Errorf("output() failed: %w", err)
-						log.Debugf("%v %v", s, err)
-						s.outputErr <- err
-						closeSession = true
-						return false
+						ack.apply(das)
 					}
+					resend = append(resend, iter)
 					return true
 				}
 				return true
 			})
+			if !closeSession && len(resend) > 0 {
+				if err := s.outputBatch(resend, s.RemoteAddr()); err != nil {
+					err = fmt.Errorf("outputBatch() failed: %w", err)
+					log.Debugf("%v %v", s, err)
+					s.outputErr <- err
+					closeSession = true
+				}
+			}
 			if closeSession {
 				s.Close()
 			}
@@ -617,7 +789,8 @@ func (s *Session) runOutputLoop(ctx context.Context) error {
 			if s.sendQueue.Len() > 0 {
 				maxSegmentToMove := mathext.Min(s.sendQueue.Len(), s.sendBuf.Remaining())
 				maxSegmentToMove = mathext.Min(maxSegmentToMove, int(s.sendAlgorithm.CongestionWindowSize()))
-				maxSegmentToMove = mathext.Min(maxSegmentToMove, int(s.remoteWindowSize))
+				maxSegmentToMove = mathext.Min(maxSegmentToMove, int(s.remoteWindowSize.Load()))
+				batch := make([]*segment, 0, maxSegmentToMove)
 				for {
 					seg, deleted := s.sendQueue.DeleteMinIf(func(iter *segment) bool {
 						if segmentMoved >= maxSegmentToMove {
@@ -631,19 +804,21 @@ func (s *Session) runOutputLoop(ctx context.Context) error {
 					}
 					seg.txCount++
 					seg.txTime = time.Now()
-					seg.txTimeout = s.rttStat.RTO() * time.Duration(math.Pow(txTimeoutBackOff, float64(seg.txCount)))
+					seg.txTimeout = s.txTimeout(seg.txCount)
 					if isDataAckProtocol(seg.metadata.Protocol()) {
 						das, _ := toDataAckStruct(seg.metadata)
-						das.unAckSeq = s.nextRecv
+						ack.apply(das)
 					}
 					s.sendBuf.InsertBlocking(seg)
-					if err := s.output(seg, s.RemoteAddr()); err != nil {
-						err = fmt.Errorf("output() failed: %w", err)
-						log.Debugf("%v %v", s, err)
-						s.outputErr <- err
-						s.Close()
-						break
-					}
+					UnderlayUDPSegmentsSent.Add(1)
+					s.segmentsSent.Add(1)
+					batch = append(batch, seg)
+				}
+				if err := s.outputBatch(batch, s.RemoteAddr()); err != nil {
+					err = fmt.Errorf("outputBatch() failed: %w", err)
+					log.Debugf("%v %v", s, err)
+					s.outputErr <- err
+					s.Close()
 				}
 			}
 
@@ -659,14 +834,15 @@ func (s *Session) runOutputLoop(ctx context.Context) error {
 					} else {
 						baseStruct.protocol = uint8(ackServerToClient)
 					}
+					ackMeta := &dataAckStruct{
+						baseStruct: baseStruct,
+						sessionID:  s.id,
+						seq:        uint32(mathext.Max(0, int(s.nextSend)-1)),
+						windowSize: s.receiveWindowSize(),
+					}
+					s.currentAckState().apply(ackMeta)
 					ackSeg := &segment{
-						metadata: &dataAckStruct{
-							baseStruct: baseStruct,
-							sessionID:  s.id,
-							seq:        uint32(mathext.Max(0, int(s.nextSend)-1)),
-							unAckSeq:   s.nextRecv,
-							windowSize: uint16(mathext.Max(0, int(s.sendAlgorithm.CongestionWindowSize())-s.recvBuf.Len())),
-						},
+						metadata:  ackMeta,
 						transport: s.conn.TransportProtocol(),
 					}
 					if err := s.output(ackSeg, s.RemoteAddr()); err != nil {
@@ -702,6 +878,9 @@ func (s *Session) input(seg *segment) error {
 	}
 	if seg.block != nil {
 		s.block = seg.block
+		if name := s.block.BlockContext().UserName; name != "" && s.userName.Load() == nil {
+			s.userName.Store(&name)
+		}
 		if s.readBytes == nil && s.block.BlockContext().UserName != "" {
 			s.readBytes = metrics.RegisterMetric(fmt.Sprintf(metrics.UserMetricGroupFormat, s.block.BlockContext().UserName), metrics.UserMetricReadBytes, metrics.COUNTER_TIME_SERIES)
 		}
@@ -709,7 +888,7 @@ func (s *Session) input(seg *segment) error {
 			s.writeBytes = metrics.RegisterMetric(fmt.Sprintf(metrics.UserMetricGroupFormat, s.block.BlockContext().UserName), metrics.UserMetricWriteBytes, metrics.COUNTER_TIME_SERIES)
 		}
 	}
-	s.lastRXTime = time.Now()
+	s.lastRXTime.Store(time.Now().UnixNano())
 	if protocol == openSessionRequest || protocol == openSessionResponse || protocol == dataServerToClient || protocol == dataClientToServer {
 		return s.inputData(seg)
 	} else if protocol == ackServerToClient || protocol == ackClientToServer {
@@ -738,10 +917,12 @@ func (s *Session) inputData(seg *segment) error {
 				if !deleted {
 					break
 				}
-				s.rttStat.UpdateRTT(time.Since(seg2.txTime))
-				s.sendAlgorithm.OnAck()
+				if !seg2.acked {
+					s.onSegmentAcked(seg2)
+				}
 			}
-			s.remoteWindowSize = das.windowSize
+			s.onSelectiveAck(das)
+			s.remoteWindowSize.Store(uint32(das.windowSize))
 		}
 
 		// Deliver the segment to recvBuf.
@@ -760,9 +941,10 @@ func (s *Session) inputData(seg *segment) error {
 			if seq == s.nextRecv {
 				s.recvQueue.InsertBlocking(seg3)
 				s.nextRecv++
+				s.onSegmentReceived()
 				das, ok := seg3.metadata.(*dataAckStruct)
 				if ok {
-					s.remoteWindowSize = das.windowSize
+					s.remoteWindowSize.Store(uint32(das.windowSize))
 				}
 			}
 		}
@@ -771,6 +953,12 @@ func (s *Session) inputData(seg *segment) error {
 	}
 
 	if !s.isClient && seg.metadata.Protocol() == openSessionRequest {
+		var responseFlags uint8
+		if ss, ok := seg.metadata.(*sessionStruct); ok {
+			s.acceptServerMessage.Store(ss.flags&flagServerMessage != 0)
+			responseFlags = ss.flags & (flagKeepalive | flagServerTime | flagVersion)
+			s.acceptProtocolVersion(ss)
+		}
 		s.wLock.Lock()
 		if s.isState(sessionAttached) {
 			// Server needs to send open session response.
@@ -781,26 +969,66 @@ func (s *Session) inputData(seg *segment) error {
 			} else if s.block != nil && s.block.BlockContext().UserName != "" {
 				userName = s.block.BlockContext().UserName
 			}
+			var quotaWarning bool
+			if userName != "" && !s.checkAccessWindow(userName) {
+				s.status = statusAccessWindowClosed
+				log.Debugf("Closing %v because user %s is outside of access windows", s, userName)
+				s.wLock.Unlock()
+				s.Close()
+				return nil
+			}
+			release, err := serverConnectionLimits.acquireSession(userName, addrIP(s.RemoteAddr()))
+			if err != nil {
+				s.status = statusConnectionLimit
+				UnderlaySessionLimitRejects.Add(1)
+				connectionLimitLogSampler.Warnf("Rejected %v: %v", s, err)
+				s.wLock.Unlock()
+				s.Close()
+				return nil
+			}
+			s.releaseConnectionLimit.Store(&release)
+			recordUserHandshake(userName, time.Now())
 			if userName != "" {
-				quotaOK, err := s.checkQuota(userName)
+				decision, err := s.checkQuota(userName)
 				if err != nil {
 					log.Debugf("%v checkQuota() failed: %v", s, err)
 				}
-				if !quotaOK {
+				quotaWarning = decision.nearlyExhausted
+				if decision.reject {
 					s.status = statusQuotaExhausted
 					log.Debugf("Closing %v because user %s used all the quota", s, userName)
+					event.Emit(event.QuotaExceeded, userName, map[string]string{"user": userName, "action": "reject"}, "user %s used all the quota, new sessions are rejected", userName)
 					s.wLock.Unlock()
 					s.Close()
 					return nil
 				}
+				if decision.throttleBytesPerSecond > 0 {
+					log.Debugf("Throttling %v to %d bytes per second because user %s used all the quota", s, decision.throttleBytesPerSecond, userName)
+					event.Emit(event.QuotaExceeded, userName, map[string]string{"user": userName, "action": "throttle"}, "user %s used all the quota, traffic is throttled to %d bytes per second", userName, decision.throttleBytesPerSecond)
+					s.throttle.Store(userThrottle(userName, decision.throttleBytesPerSecond))
+				}
+				s.applyRateLimits(userName)
+			}
+			var loadFactor uint8
+			if lf, ok := serverLoadFactor(); ok {
+				responseFlags |= flagLoadFactor
+				loadFactor = lf
+			}
+			var serverTime uint64
+			if responseFlags&flagServerTime != 0 {
+				serverTime = uint64(serverNow().UnixMilli())
 			}
 			seg4 := &segment{
 				metadata: &sessionStruct{
 					baseStruct: baseStruct{
 						protocol: uint8(openSessionResponse),
+						flags:    responseFlags,
 					},
-					sessionID: s.id,
-					seq:       s.nextSend,
+					sessionID:  s.id,
+					seq:        s.nextSend,
+					loadFactor: loadFactor,
+					serverTime: serverTime,
+					version:    s.ProtocolVersion(),
 				},
 				transport: s.conn.TransportProtocol(),
 			}
@@ -810,6 +1038,11 @@ func (s *Session) inputData(seg *segment) error {
 			}
 			s.sendQueue.InsertBlocking(seg4)
 			s.forwardStateTo(sessionEstablished)
+			if quotaWarning && s.acceptServerMessage.Load() && shouldSendQuotaWarning(userName) {
+				if err := s.sendServerMessage(quotaWarningMessage); err != nil {
+					log.Debugf("%v sendServerMessage() failed: %v", s, err)
+				}
+			}
 		}
 		s.wLock.Unlock()
 	}
@@ -833,10 +1066,12 @@ func (s *Session) inputAck(seg *segment) error {
 			if !deleted {
 				break
 			}
-			s.rttStat.UpdateRTT(time.Since(seg2.txTime))
-			s.sendAlgorithm.OnAck()
+			if !seg2.acked {
+				s.onSegmentAcked(seg2)
+			}
 		}
-		s.remoteWindowSize = das.windowSize
+		s.onSelectiveAck(das)
+		s.remoteWindowSize.Store(uint32(das.windowSize))
 		return nil
 	default:
 		return fmt.Errorf("unsupported transport protocol %v", s.conn.TransportProtocol())
@@ -868,6 +1103,10 @@ func (s *Session) inputClose(seg *segment) error {
 		// Immediately shutdown event loop.
 		if seg.metadata.(*sessionStruct).statusCode == uint8(statusQuotaExhausted) {
 			log.Infof("Remote requested to shut down the session because user has exhausted quota")
+		} else if seg.metadata.(*sessionStruct).statusCode == uint8(statusAccessWindowClosed) {
+			log.Infof("Remote requested to shut down the session because user is outside of access windows")
+		} else if seg.metadata.(*sessionStruct).statusCode == uint8(statusConnectionLimit) {
+			log.Infof("Remote requested to shut down the session because of too many concurrent connections")
 		} else {
 			log.Debugf("Remote requested to shut down %v", s)
 		}
@@ -883,6 +1122,7 @@ func (s *Session) inputClose(seg *segment) error {
 }
 
 func (s *Session) output(seg *segment, remoteAddr net.Addr) error {
+	seg.priority = s.Priority()
 	switch s.conn.TransportProtocol() {
 	case util.TCPTransport:
 		if err := s.conn.(*TCPUnderlay).writeOneSegment(seg); err != nil {
@@ -895,7 +1135,7 @@ func (s *Session) output(seg *segment, remoteAddr net.Addr) error {
 				return fmt.Errorf("UDPUnderlay.writeOneSegment() failed: %v", err)
 			}
 			if log.IsLevelEnabled(log.TraceLevel) {
-				log.Tracef("UDPUnderlay.writeOneSegment() failed: %v. Will retry later.", err)
+				sessionWriteRetryLogSampler.Tracef("UDPUnderlay.writeOneSegment() failed: %v. Will retry later.", err)
 			}
 			return nil
 		}
@@ -906,52 +1146,133 @@ func (s *Session) output(seg *segment, remoteAddr net.Addr) error {
 	return nil
 }
 
-func (s *Session) checkQuota(userName string) (ok bool, err error) {
+// outputBatch writes multiple segments to the underlay. UDP segments are
+// written in batches if it is supported.
+func (s *Session) outputBatch(segs []*segment, remoteAddr net.Addr) error {
+	if len(segs) == 0 {
+		return nil
+	}
+	if s.conn.TransportProtocol() != util.UDPTransport {
+		for _, seg := range segs {
+			if err := s.output(seg, remoteAddr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, seg := range segs {
+		seg.priority = s.Priority()
+	}
+	err := s.conn.(*UDPUnderlay).writeSegments(segs, remoteAddr.(*net.UDPAddr))
+	if err != nil {
+		if !stderror.IsNotReady(err) {
+			return fmt.Errorf("UDPUnderlay.writeSegments() failed: %v", err)
+		}
+		if log.IsLevelEnabled(log.TraceLevel) {
+			sessionWriteRetryLogSampler.Tracef("UDPUnderlay.writeSegments() failed: %v. Will retry later.", err)
+		}
+		return nil
+	}
+	s.lastTXTime = time.Now()
+	return nil
+}
+
+// checkQuota evaluates the quotas of the user.
+func (s *Session) checkQuota(userName string) (quotaDecision, error) {
 	if len(s.users) == 0 {
-		return true, fmt.Errorf("no registered user")
+		return quotaDecision{}, fmt.Errorf("no registered user")
 	}
 	user, found := s.users[userName]
 	if !found {
-		return true, fmt.Errorf("user %s is not found", userName)
+		return quotaDecision{}, fmt.Errorf("user %s is not found", userName)
 	}
 	if len(user.GetQuotas()) == 0 {
-		return true, nil
+		return quotaDecision{}, nil
 	}
 
-	metricGroupName := fmt.Sprintf(metrics.UserMetricGroupFormat, userName)
-	metricGroup := metrics.GetMetricGroupByName(metricGroupName)
-	if metricGroup == nil {
-		return true, fmt.Errorf("metric group %s is not found", metricGroupName)
+	now := time.Now()
+	usedBytes, err := userUsedBytes(userName, now)
+	if err != nil {
+		return quotaDecision{}, err
 	}
-	readBytes, found := metricGroup.GetMetric(metrics.UserMetricReadBytes)
-	if !found {
-		return true, fmt.Errorf("metric %s in group %s is not found", metrics.UserMetricReadBytes, metricGroupName)
+	return evaluateQuotas(user.GetQuotas(), usedBytes, now), nil
+}
+
+// waitThrottle blocks until n bytes can be transferred,
+// if the session is throttled or rate limited.
+func (s *Session) waitThrottle(n int) {
+	if n <= 0 {
+		return
+	}
+	l := s.throttle.Load()
+	rl := s.rateLimits.Load()
+	if rl == nil {
+		if l != nil {
+			l.Wait(n)
+		}
+		return
+	}
+	limiters := *rl
+	if l != nil {
+		limiters = append([]*util.RateLimiter{l}, limiters...)
 	}
-	writeBytes, found := metricGroup.GetMetric(metrics.UserMetricWriteBytes)
+	waitRateLimits(limiters, n)
+}
+
+// applyRateLimits sets the speed limits of the user to the session.
+func (s *Session) applyRateLimits(userName string) {
+	user, found := s.users[userName]
 	if !found {
-		return true, fmt.Errorf("metric %s in group %s is not found", metrics.UserMetricWriteBytes, metricGroupName)
+		return
 	}
-	for _, quota := range user.GetQuotas() {
-		now := time.Now()
-		then := now.Add(-time.Duration(quota.GetDays()) * 24 * time.Hour)
-		totalBytes := readBytes.(*metrics.Counter).DeltaBetween(then, now)
-		totalBytes += writeBytes.(*metrics.Counter).DeltaBetween(then, now)
-		if totalBytes/1048576 > int64(quota.GetMegabytes()) {
-			return false, nil
-		}
+	if limiters := userRateLimits(user, s.RemoteAddr()); len(limiters) > 0 {
+		s.rateLimits.Store(&limiters)
 	}
-	return true, nil
 }
 
-// SessionInfo provides a string representation of a Session.
+// checkAccessWindow returns true if the user is allowed to open
+// a new session at this time.
+func (s *Session) checkAccessWindow(userName string) bool {
+	user, found := s.users[userName]
+	if !found {
+		return true
+	}
+	return isInAccessWindows(user.GetAccessWindows(), time.Now())
+}
+
+// SessionInfo provides the information of a Session.
 type SessionInfo struct {
-	ID         string
+	ID         uint32
 	Protocol   string
 	LocalAddr  string
 	RemoteAddr string
 	State      string
-	RecvQBuf   string
-	SendQBuf   string
-	LastRecv   string
-	LastSend   string
+	RecvQueue  int // number of segments in receive queue
+	RecvBuf    int // number of segments in receive buffer
+	SendQueue  int // number of segments in send queue
+	SendBuf    int // number of segments in send buffer
+	LastRecv   time.Duration
+	LastSend   time.Duration
+
+	BytesRead    int64         // number of bytes delivered to the application
+	BytesWritten int64         // number of bytes sent from the application
+	SmoothedRTT  time.Duration // zero if no RTT sample is collected
+	Age          time.Duration // time since the session is created
+	SegmentsSent int64         // UDP segments sent for the first time
+	Retransmits  int64         // UDP segments sent again
+
+	CongestionWindow int // number of segments that can be sent without acknowledgement
+	PeerWindow       int // receive window advertised by the peer, in segments
+	RecvWindow       int // receive window of this session, in segments
+
+	ProtocolVersion int // protocol version negotiated with the peer
+}
+
+// LossRate returns the ratio of retransmitted UDP segments,
+// which estimates the packet loss of the session.
+func (si SessionInfo) LossRate() float64 {
+	if si.SegmentsSent == 0 {
+		return 0
+	}
+	return float64(si.Retransmits) / float64(si.SegmentsSent)
 }
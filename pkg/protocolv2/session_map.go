@@ -0,0 +1,136 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// sessionMapShards is the number of shards in a session map.
+// It must be a power of 2.
+const sessionMapShards = 16
+
+// sessionMap stores the sessions of an underlay by session ID.
+//
+// Sessions are distributed to shards by session ID. Each shard is
+// copy-on-write: lookups read an immutable snapshot without locking,
+// while adding or removing a session copies the snapshot of one shard.
+// Lookups happen for every segment, but sessions are added and removed
+// much less frequently.
+type sessionMap struct {
+	shards [sessionMapShards]sessionMapShard
+	size   atomic.Int64
+}
+
+type sessionMapShard struct {
+	// mu is required to replace the snapshot.
+	mu sync.Mutex
+
+	// snapshot is the current immutable map of this shard.
+	snapshot atomic.Pointer[map[uint32]*Session]
+}
+
+// Load returns the session with the ID.
+func (m *sessionMap) Load(id uint32) (*Session, bool) {
+	snapshot := m.shardOf(id).snapshot.Load()
+	if snapshot == nil {
+		return nil, false
+	}
+	s, ok := (*snapshot)[id]
+	return s, ok
+}
+
+// LoadOrStore returns the existing session with the ID if present.
+// Otherwise, it stores the session. The loaded result is true if the
+// session was loaded, false if stored.
+func (m *sessionMap) LoadOrStore(id uint32, s *Session) (actual *Session, loaded bool) {
+	shard := m.shardOf(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	old := shard.load()
+	if existing, ok := old[id]; ok {
+		return existing, true
+	}
+	next := make(map[uint32]*Session, len(old)+1)
+	for k, v := range old {
+		next[k] = v
+	}
+	next[id] = s
+	shard.snapshot.Store(&next)
+	m.size.Add(1)
+	return s, false
+}
+
+// Delete removes the session with the ID.
+func (m *sessionMap) Delete(id uint32) {
+	shard := m.shardOf(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	old := shard.load()
+	if _, ok := old[id]; !ok {
+		return
+	}
+	next := make(map[uint32]*Session, len(old))
+	for k, v := range old {
+		if k != id {
+			next[k] = v
+		}
+	}
+	shard.snapshot.Store(&next)
+	m.size.Add(-1)
+}
+
+// Range calls f for each session until f returns false.
+// f may add or remove sessions, which is not visible to this iteration.
+func (m *sessionMap) Range(f func(id uint32, s *Session) bool) {
+	for i := range m.shards {
+		for id, s := range m.shards[i].load() {
+			if !f(id, s) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of sessions.
+func (m *sessionMap) Len() int {
+	return int(m.size.Load())
+}
+
+// Clear removes all the sessions.
+func (m *sessionMap) Clear() {
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mu.Lock()
+		m.size.Add(-int64(len(shard.load())))
+		shard.snapshot.Store(nil)
+		shard.mu.Unlock()
+	}
+}
+
+func (m *sessionMap) shardOf(id uint32) *sessionMapShard {
+	return &m.shards[id&(sessionMapShards-1)]
+}
+
+// load returns the current snapshot of the shard. The returned map
+// must not be modified.
+func (s *sessionMapShard) load() map[uint32]*Session {
+	if snapshot := s.snapshot.Load(); snapshot != nil {
+		return *snapshot
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSessionMap(t *testing.T) {
+	var m sessionMap
+	if _, ok := m.Load(1); ok {
+		t.Errorf("Load() from empty map succeeded")
+	}
+	for i := uint32(1); i <= 100; i++ {
+		if _, loaded := m.LoadOrStore(i, NewSession(i, true, 1500)); loaded {
+			t.Errorf("LoadOrStore(%d) loaded an existing session", i)
+		}
+	}
+	s, loaded := m.LoadOrStore(1, NewSession(1, true, 1500))
+	if !loaded || s.id != 1 {
+		t.Errorf("LoadOrStore(1) = %v, %v, want the existing session", s, loaded)
+	}
+	if m.Len() != 100 {
+		t.Errorf("Len() = %d, want 100", m.Len())
+	}
+
+	// Remove sessions while iterating.
+	visited := 0
+	m.Range(func(id uint32, s *Session) bool {
+		visited++
+		if id%2 == 0 {
+			m.Delete(id)
+		}
+		return true
+	})
+	if visited != 100 {
+		t.Errorf("Range() visited %d sessions, want 100", visited)
+	}
+	if m.Len() != 50 {
+		t.Errorf("Len() = %d, want 50", m.Len())
+	}
+	if _, ok := m.Load(2); ok {
+		t.Errorf("Load(2) succeeded after Delete()")
+	}
+	if s, ok := m.Load(3); !ok || s.id != 3 {
+		t.Errorf("Load(3) = %v, %v, want session 3", s, ok)
+	}
+
+	m.Clear()
+	if m.Len() != 0 {
+		t.Errorf("Len() = %d after Clear(), want 0", m.Len())
+	}
+}
+
+func TestSessionMapConcurrent(t *testing.T) {
+	var m sessionMap
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(base uint32) {
+			defer wg.Done()
+			for id := base; id < base+100; id++ {
+				m.LoadOrStore(id, NewSession(id, true, 1500))
+				if _, ok := m.Load(id); !ok {
+					t.Errorf("Load(%d) failed after LoadOrStore()", id)
+				}
+				m.Delete(id)
+			}
+		}(uint32(i*1000 + 1))
+	}
+	wg.Wait()
+	if m.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", m.Len())
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// sessionTap mirrors byte counts and timing of a session to a local
+// UDP socket for debugging. The content of the session is never mirrored.
+//
+// Each event is sent as a JSON object in one UDP packet, for example
+//
+//	{"sessionID":123,"event":"read","bytes":512,"unixNano":1700000000000000000,"elapsedMicros":1500}
+//
+// where "elapsedMicros" is the time since the tap is attached.
+type sessionTap struct {
+	conn      net.Conn
+	sessionID uint32
+	start     time.Time
+}
+
+func newSessionTap(sessionID uint32, address string) (*sessionTap, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("net.ResolveUDPAddr() failed: %w", err)
+	}
+	if !addr.IP.IsLoopback() {
+		return nil, fmt.Errorf("session tap address %v is not a loopback address", addr)
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, fmt.Errorf("net.DialUDP() failed: %w", err)
+	}
+	return &sessionTap{
+		conn:      conn,
+		sessionID: sessionID,
+		start:     time.Now(),
+	}, nil
+}
+
+// record sends one event to the tap. Errors are ignored because the
+// analysis socket may not be listening.
+func (t *sessionTap) record(event string, n int) {
+	now := time.Now()
+	msg := fmt.Sprintf(`{"sessionID":%d,"event":%q,"bytes":%d,"unixNano":%d,"elapsedMicros":%d}`, t.sessionID, event, n, now.UnixNano(), now.Sub(t.start).Microseconds())
+	t.conn.Write([]byte(msg))
+}
+
+func (t *sessionTap) close() {
+	t.record("detach", 0)
+	t.conn.Close()
+}
+
+// recordTap sends one event to the tap of the session, if any.
+func (s *Session) recordTap(event string, n int) {
+	if tap := s.tap.Load(); tap != nil {
+		tap.record(event, n)
+	}
+}
+
+// SetSessionTap mirrors byte counts and timing of the session with the ID
+// to a UDP socket listening at the loopback address. If the address is
+// empty, the existing tap of the session is removed.
+func (m *Mux) SetSessionTap(sessionID uint32, address string) error {
+	session := m.findSession(sessionID)
+	if session == nil {
+		return fmt.Errorf("session %d is not found", sessionID)
+	}
+	if address == "" {
+		if old := session.tap.Swap(nil); old != nil {
+			old.close()
+		}
+		return nil
+	}
+	tap, err := newSessionTap(sessionID, address)
+	if err != nil {
+		return err
+	}
+	tap.record("attach", 0)
+	if old := session.tap.Swap(tap); old != nil {
+		old.close()
+	}
+	return nil
+}
+
+// findSession returns the session with the ID, or nil if it is not found.
+func (m *Mux) findSession(sessionID uint32) *Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, underlay := range m.underlays {
+		var base *baseUnderlay
+		switch u := underlay.(type) {
+		case *TCPUnderlay:
+			base = &u.baseUnderlay
+		case *UDPUnderlay:
+			base = &u.baseUnderlay
+		default:
+			continue
+		}
+		if session, found := base.sessionMap.Load(sessionID); found {
+			return session
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSessionTap(t *testing.T) {
+	if _, err := newSessionTap(1, "192.0.2.1:9000"); err == nil {
+		t.Errorf("newSessionTap() with non-loopback address succeeded, want error")
+	}
+
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatalf("net.ListenUDP() failed: %v", err)
+	}
+	defer listener.Close()
+	tap, err := newSessionTap(42, listener.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("newSessionTap() failed: %v", err)
+	}
+	s := NewSession(42, true, 1500)
+	s.tap.Store(tap)
+	s.recordTap("write", 512)
+
+	buf := make([]byte, 1500)
+	listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	var event struct {
+		SessionID uint32 `json:"sessionID"`
+		Event     string `json:"event"`
+		Bytes     int    `json:"bytes"`
+	}
+	if err := json.Unmarshal(buf[:n], &event); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	if event.SessionID != 42 || event.Event != "write" || event.Bytes != 512 {
+		t.Errorf("got event %+v, want session 42 write 512 bytes", event)
+	}
+	tap.close()
+}
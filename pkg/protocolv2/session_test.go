@@ -0,0 +1,51 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSessionWriteTo(t *testing.T) {
+	s := NewSession(1, false, 1500)
+	s.forwardStateTo(sessionAttached)
+	for i, payload := range []string{"hello ", "", "world"} {
+		s.recvQueue.InsertBlocking(&segment{
+			metadata: &dataAckStruct{
+				baseStruct: baseStruct{
+					protocol: uint8(dataClientToServer),
+				},
+				sessionID: 1,
+				seq:       uint32(i),
+			},
+			payload: []byte(payload),
+		})
+	}
+	close(s.done)
+
+	var buf bytes.Buffer
+	n, err := s.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() failed: %v", err)
+	}
+	if n != int64(len("hello world")) || buf.String() != "hello world" {
+		t.Errorf("WriteTo() wrote %d bytes %q, want %q", n, buf.String(), "hello world")
+	}
+	if got := s.bytesRead.Load(); got != n {
+		t.Errorf("bytesRead = %d, want %d", got, n)
+	}
+}
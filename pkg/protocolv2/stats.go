@@ -0,0 +1,75 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"time"
+
+	"github.com/enfein/mieru/pkg/metrics"
+)
+
+var (
+	// Smoothed round trip time of the sessions that have an RTT sample,
+	// updated by the mux periodically.
+	SessionAvgRTTMillis = metrics.RegisterMetric("underlay", "SessionAvgRTTMillis", metrics.GAUGE)
+	SessionMaxRTTMillis = metrics.RegisterMetric("underlay", "SessionMaxRTTMillis", metrics.GAUGE)
+)
+
+// Stats is a snapshot of the sessions and underlays of a mux.
+type Stats struct {
+	Sessions  []SessionInfo
+	Underlays []UnderlayInfo
+}
+
+// Stats returns the information of all the sessions and underlays.
+// Sessions and underlays are collected at the same time, so they are
+// consistent with each other.
+func (m *Mux) Stats() Stats {
+	stats := Stats{
+		Sessions:  make([]SessionInfo, 0),
+		Underlays: make([]UnderlayInfo, 0),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, underlay := range m.underlays {
+		stats.Sessions = append(stats.Sessions, underlay.Sessions()...)
+		stats.Underlays = append(stats.Underlays, ToUnderlayInfo(underlay))
+	}
+	return stats
+}
+
+// recordStatsMetrics updates the metrics derived from the stats.
+func recordStatsMetrics(stats Stats) {
+	var sum, max time.Duration
+	n := 0
+	for _, si := range stats.Sessions {
+		if si.SmoothedRTT <= 0 {
+			continue
+		}
+		sum += si.SmoothedRTT
+		if si.SmoothedRTT > max {
+			max = si.SmoothedRTT
+		}
+		n++
+	}
+	if n == 0 {
+		SessionAvgRTTMillis.Store(0)
+		SessionMaxRTTMillis.Store(0)
+		return
+	}
+	SessionAvgRTTMillis.Store((sum / time.Duration(n)).Milliseconds())
+	SessionMaxRTTMillis.Store(max.Milliseconds())
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/testtool"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+func TestMuxStats(t *testing.T) {
+	log.SetOutputToTest(t)
+	log.SetLevel("INFO")
+	port, err := util.UnusedUDPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedUDPPort() failed: %v", err)
+	}
+	serverProperties := NewUnderlayProperties(1500, util.IPVersion4, util.UDPTransport, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil)
+	serverMux := NewMux(false).
+		SetServerUsers(users).
+		SetEndpoints([]UnderlayProperties{serverProperties})
+	testServer := testtool.NewTestHelperServer()
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	go testServer.Serve(serverMux)
+	defer testServer.Close()
+	defer serverMux.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	clientProperties := NewUnderlayProperties(1500, util.IPVersion4, util.UDPTransport, nil, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: port})
+	clientMux := NewMux(true).
+		SetClientPassword(cipher.HashPassword([]byte("kuiranbudong"), []byte("xiaochitang"))).
+		SetEndpoints([]UnderlayProperties{clientProperties})
+	defer clientMux.Close()
+	conn, err := clientMux.DialContext(context.Background())
+	if err != nil {
+		t.Fatalf("DialContext() failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 5)); err != nil {
+		t.Fatalf("io.ReadFull() failed: %v", err)
+	}
+
+	stats := clientMux.Stats()
+	if len(stats.Sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(stats.Sessions))
+	}
+	si := stats.Sessions[0]
+	if si.BytesWritten != 5 || si.BytesRead != 5 {
+		t.Errorf("session wrote %d bytes and read %d bytes, want 5 and 5", si.BytesWritten, si.BytesRead)
+	}
+	if si.ProtocolVersion != int(currentProtocolVersion) {
+		t.Errorf("session protocol version is %d, want %d", si.ProtocolVersion, currentProtocolVersion)
+	}
+	if si.CongestionWindow <= 0 || si.PeerWindow <= 0 || si.RecvWindow <= 0 {
+		t.Errorf("got congestion window %d, peer window %d, receive window %d, want positive values", si.CongestionWindow, si.PeerWindow, si.RecvWindow)
+	}
+	if len(stats.Underlays) != 1 {
+		t.Fatalf("got %d underlays, want 1", len(stats.Underlays))
+	}
+	ui := stats.Underlays[0]
+	if ui.Sessions != 1 {
+		t.Errorf("underlay has %d sessions, want 1", ui.Sessions)
+	}
+	if ui.SegmentsSent == 0 || ui.SegmentsReceived == 0 {
+		t.Errorf("underlay sent %d segments and received %d segments, want both positive", ui.SegmentsSent, ui.SegmentsReceived)
+	}
+	if ui.BytesSent <= 5 || ui.BytesReceived <= 5 {
+		t.Errorf("underlay sent %d bytes and received %d bytes, want more than the payload", ui.BytesSent, ui.BytesReceived)
+	}
+}
+
+func TestRecordStatsMetrics(t *testing.T) {
+	recordStatsMetrics(Stats{
+		Sessions: []SessionInfo{
+			{SmoothedRTT: 10 * time.Millisecond},
+			{SmoothedRTT: 30 * time.Millisecond},
+			{},
+		},
+	})
+	if got := SessionAvgRTTMillis.Load(); got != 20 {
+		t.Errorf("SessionAvgRTTMillis = %d, want 20", got)
+	}
+	if got := SessionMaxRTTMillis.Load(); got != 30 {
+		t.Errorf("SessionMaxRTTMillis = %d, want 30", got)
+	}
+	recordStatsMetrics(Stats{})
+	if got := SessionAvgRTTMillis.Load(); got != 0 {
+		t.Errorf("SessionAvgRTTMillis = %d, want 0", got)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/enfein/mieru/pkg/rng"
+)
+
+// MaxTimingJitter is the maximum timing jitter allowed. A larger value
+// would hurt the throughput because segments of an underlay are flushed
+// one after another.
+const MaxTimingJitter = 50 * time.Millisecond
+
+// timingJitter is the maximum delay before a segment is flushed
+// to the network, in nanoseconds. 0 means disabled.
+var timingJitter atomic.Int64
+
+// SetTimingJitter changes the maximum random delay before a segment is
+// flushed to the network. The delay breaks the precise inter-packet timing
+// that can be used by traffic classifiers. A zero value disables it.
+func SetTimingJitter(max time.Duration) error {
+	if max < 0 || max > MaxTimingJitter {
+		return fmt.Errorf("timing jitter %v is not between 0 and %v", max, MaxTimingJitter)
+	}
+	timingJitter.Store(int64(max))
+	return nil
+}
+
+// TimingJitter returns the maximum random delay before a segment is flushed.
+func TimingJitter() time.Duration {
+	return time.Duration(timingJitter.Load())
+}
+
+// nextTimingJitter returns a random delay between 0 and the maximum
+// timing jitter. Short delays are more likely than long delays.
+func nextTimingJitter() time.Duration {
+	max := timingJitter.Load()
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rng.Int63n(max))
+}
+
+// waitTimingJitter sleeps for a random delay before a segment is flushed.
+// It is called with the send lock of the underlay held, so the order of
+// segments is not changed.
+func waitTimingJitter() {
+	if d := nextTimingJitter(); d > 0 {
+		UnderlayJitterDelays.Add(1)
+		time.Sleep(d)
+	}
+}
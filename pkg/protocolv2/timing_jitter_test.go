@@ -0,0 +1,54 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimingJitter(t *testing.T) {
+	defer timingJitter.Store(0)
+
+	if d := nextTimingJitter(); d != 0 {
+		t.Errorf("nextTimingJitter() = %v when disabled, want 0", d)
+	}
+	if err := SetTimingJitter(-time.Millisecond); err == nil {
+		t.Errorf("SetTimingJitter() with negative value succeeded, want error")
+	}
+	if err := SetTimingJitter(MaxTimingJitter + time.Millisecond); err == nil {
+		t.Errorf("SetTimingJitter() above maximum succeeded, want error")
+	}
+
+	if err := SetTimingJitter(5 * time.Millisecond); err != nil {
+		t.Fatalf("SetTimingJitter() failed: %v", err)
+	}
+	if got := TimingJitter(); got != 5*time.Millisecond {
+		t.Errorf("TimingJitter() = %v, want %v", got, 5*time.Millisecond)
+	}
+	for i := 0; i < 1000; i++ {
+		if d := nextTimingJitter(); d < 0 || d > 5*time.Millisecond {
+			t.Fatalf("nextTimingJitter() = %v, want between 0 and %v", d, 5*time.Millisecond)
+		}
+	}
+
+	if err := SetTimingJitter(0); err != nil {
+		t.Fatalf("SetTimingJitter() failed: %v", err)
+	}
+	if d := nextTimingJitter(); d != 0 {
+		t.Errorf("nextTimingJitter() = %v after disabled, want 0", d)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"io"
+	"net"
+	"sync"
+
+	"github.com/enfein/mieru/pkg/util"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	// udpBatchSize is the maximum number of UDP packets
+	// read or written in a single system call.
+	udpBatchSize = 32
+
+	// udpMaxPacketSize is the size of buffer to receive a UDP packet.
+	// Peer may select a different MTU. Use the largest possible value.
+	udpMaxPacketSize = 1500
+)
+
+// batchPacketConn reads and writes multiple UDP packets in a
+// single system call. Both ipv4.PacketConn and ipv6.PacketConn
+// implement this interface.
+type batchPacketConn interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+	WriteBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// udpBatchWriter writes multiple UDP packets to the same address.
+type udpBatchWriter interface {
+	WriteBatchToUDP(packets [][]byte, addr *net.UDPAddr) (int, error)
+}
+
+// batchUDPConn is a UDP connection that reads packets in batches,
+// and can write packets in batches.
+type batchUDPConn struct {
+	*net.UDPConn
+	batch batchPacketConn
+
+	readMu   sync.Mutex
+	readMsgs []ipv4.Message
+	readN    int // number of received packets in readMsgs
+	readPos  int // position of next packet to return in readMsgs
+
+	writeMu   sync.Mutex
+	writeMsgs []ipv4.Message
+}
+
+var (
+	_ udpPacketConn  = &batchUDPConn{}
+	_ udpBatchWriter = &batchUDPConn{}
+)
+
+// newBatchUDPConn returns a UDP connection that uses recvmmsg and sendmmsg.
+func newBatchUDPConn(conn *net.UDPConn) *batchUDPConn {
+	c := &batchUDPConn{
+		UDPConn:   conn,
+		readMsgs:  make([]ipv4.Message, udpBatchSize),
+		writeMsgs: make([]ipv4.Message, udpBatchSize),
+	}
+	if util.GetIPVersion(conn.LocalAddr().String()) == util.IPVersion4 {
+		c.batch = ipv4.NewPacketConn(conn)
+	} else {
+		c.batch = ipv6.NewPacketConn(conn)
+	}
+	for i := range c.readMsgs {
+		c.readMsgs[i].Buffers = [][]byte{make([]byte, udpMaxPacketSize)}
+	}
+	for i := range c.writeMsgs {
+		c.writeMsgs[i].Buffers = make([][]byte, 1)
+	}
+	return c
+}
+
+// ReadFromUDP returns the next received packet. When all the packets
+// of the previous batch are returned, a new batch is read from the socket.
+func (c *batchUDPConn) ReadFromUDP(b []byte) (int, *net.UDPAddr, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	for c.readPos >= c.readN {
+		n, err := c.batch.ReadBatch(c.readMsgs, 0)
+		if err != nil {
+			return 0, nil, err
+		}
+		UnderlayUDPBatchReads.Add(1)
+		c.readN = n
+		c.readPos = 0
+	}
+	m := &c.readMsgs[c.readPos]
+	c.readPos++
+	n := copy(b, m.Buffers[0][:m.N])
+	addr, _ := m.Addr.(*net.UDPAddr)
+	return n, addr, nil
+}
+
+// WriteBatchToUDP writes the packets to the address, with as few system
+// calls as possible. It returns the number of packets written.
+func (c *batchUDPConn) WriteBatchToUDP(packets [][]byte, addr *net.UDPAddr) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	written := 0
+	for written < len(packets) {
+		end := written + udpBatchSize
+		if end > len(packets) {
+			end = len(packets)
+		}
+		msgs := c.writeMsgs[:end-written]
+		for i := range msgs {
+			msgs[i].Buffers[0] = packets[written+i]
+			msgs[i].Addr = addr
+		}
+		n, err := c.batch.WriteBatch(msgs, 0)
+		for i := range msgs {
+			msgs[i].Buffers[0] = nil
+		}
+		if err != nil {
+			return written, err
+		}
+		if n == 0 {
+			return written, io.ErrShortWrite
+		}
+		UnderlayUDPBatchWrites.Add(1)
+		written += n
+	}
+	return written, nil
+}
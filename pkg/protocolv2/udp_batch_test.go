@@ -0,0 +1,66 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestBatchUDPConn(t *testing.T) {
+	serverConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("net.ListenUDP() failed: %v", err)
+	}
+	server := newBatchUDPConn(serverConn)
+	defer server.Close()
+	clientConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("net.ListenUDP() failed: %v", err)
+	}
+	client := newBatchUDPConn(clientConn)
+	defer client.Close()
+
+	n := udpBatchSize + 8
+	packets := make([][]byte, n)
+	for i := range packets {
+		packets[i] = []byte(fmt.Sprintf("packet %d", i))
+	}
+	written, err := client.WriteBatchToUDP(packets, server.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("WriteBatchToUDP() failed: %v", err)
+	}
+	if written != n {
+		t.Fatalf("WriteBatchToUDP() wrote %d packets, want %d", written, n)
+	}
+
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, udpMaxPacketSize)
+	for i := 0; i < n; i++ {
+		size, addr, err := server.ReadFromUDP(b)
+		if err != nil {
+			t.Fatalf("ReadFromUDP() failed: %v", err)
+		}
+		if got, want := string(b[:size]), string(packets[i]); got != want {
+			t.Errorf("received %q, want %q", got, want)
+		}
+		if addr.String() != client.LocalAddr().String() {
+			t.Errorf("received packet from %v, want %v", addr, client.LocalAddr())
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"errors"
+	mrand "math/rand"
+	"net"
+	"time"
+
+	"github.com/enfein/mieru/pkg/event"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+const (
+	// udpNoResponseTimeout is the maximum time a client UDP underlay
+	// waits for the first packet from the server after it starts sending.
+	udpNoResponseTimeout = 10 * time.Second
+
+	// udpFallbackThreshold is the number of consecutive client UDP underlays
+	// without any response to start the fallback to TCP.
+	udpFallbackThreshold = 2
+
+	// udpFallbackDuration is how long UDP endpoints are carried over TCP
+	// before UDP is tried again.
+	udpFallbackDuration = 5 * time.Minute
+)
+
+// errUDPNoResponse is returned from the event loop of a client UDP underlay
+// if the server never responds.
+var errUDPNoResponse = errors.New("no response from UDP server")
+
+// isUnresponsive returns true if the client UDP underlay has sent packets
+// but didn't receive anything from the server within udpNoResponseTimeout.
+func (u *UDPUnderlay) isUnresponsive() bool {
+	if !u.isClient || u.responded.Load() {
+		return false
+	}
+	firstSend := u.firstSendTime.Load()
+	return firstSend != 0 && time.Since(time.Unix(0, firstSend)) > udpNoResponseTimeout
+}
+
+// UDPFallbackActive returns true if the client mux carries UDP endpoints
+// over TCP because the network drops UDP packets.
+func (m *Mux) UDPFallbackActive() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.isUDPFallbackActive()
+}
+
+// isUDPFallbackActive returns true if UDP endpoints should use TCP.
+// This method MUST be called only when holding the mu lock.
+func (m *Mux) isUDPFallbackActive() bool {
+	return time.Now().Before(m.udpFallbackUntil)
+}
+
+// onUDPUnderlayClosed updates the UDP fallback state when a client
+// UDP underlay is closed. Underlays closed before udpNoResponseTimeout
+// without any response are not counted.
+func (m *Mux) onUDPUnderlayClosed(u *UDPUnderlay) {
+	if u.responded.Load() {
+		m.reportUDPResult(true)
+	} else if u.isUnresponsive() {
+		m.reportUDPResult(false)
+	}
+}
+
+// reportUDPResult updates the UDP fallback state with the result of
+// a closed client UDP underlay.
+func (m *Mux) reportUDPResult(responded bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if responded {
+		if m.udpFailures >= udpFallbackThreshold {
+			log.Infof("UDP traffic to proxy server is restored")
+		}
+		m.udpFailures = 0
+		return
+	}
+	m.udpFailures++
+	if m.udpFailures >= udpFallbackThreshold && !m.isUDPFallbackActive() {
+		m.udpFallbackUntil = time.Now().Add(udpFallbackDuration)
+		log.Warnf("UDP traffic to proxy server is blocked, UDP endpoints fall back to TCP in the next %v", udpFallbackDuration)
+		event.Emit(event.EndpointFailover, "udp", nil, "UDP traffic to proxy server is blocked, UDP endpoints fall back to TCP in the next %v", udpFallbackDuration)
+	}
+}
+
+// udpFallbackEndpoint returns the endpoint to replace a UDP endpoint
+// when the fallback is active. A configured TCP endpoint is preferred.
+// Otherwise, TCP is used to connect the same address and port.
+// This method MUST be called only when holding the mu lock.
+func (m *Mux) udpFallbackEndpoint(p UnderlayProperties) UnderlayProperties {
+	tcpEndpoints := make([]UnderlayProperties, 0)
+	for _, endpoint := range m.endpoints {
+		if endpoint.TransportProtocol() == util.TCPTransport {
+			tcpEndpoints = append(tcpEndpoints, endpoint)
+		}
+	}
+	if len(tcpEndpoints) > 0 {
+		return tcpEndpoints[mrand.Intn(len(tcpEndpoints))]
+	}
+	udpAddr, ok := p.RemoteAddr().(*net.UDPAddr)
+	if !ok {
+		return p
+	}
+	tcpAddr := &net.TCPAddr{IP: udpAddr.IP, Port: udpAddr.Port, Zone: udpAddr.Zone}
+	return NewUnderlayPropertiesWithMimicry(p.MTU(), p.IPVersion(), util.TCPTransport, MimicryPlain, nil, tcpAddr)
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/util"
+)
+
+func TestUDPFallback(t *testing.T) {
+	mux := NewMux(true)
+	defer mux.Close()
+	udpAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8964}
+	udpEndpoint := NewUnderlayProperties(1400, util.IPVersion4, util.UDPTransport, nil, udpAddr)
+	mux.SetEndpoints([]UnderlayProperties{udpEndpoint})
+
+	for i := 0; i < udpFallbackThreshold; i++ {
+		if mux.UDPFallbackActive() {
+			t.Fatalf("UDP fallback is active after %d failures", i)
+		}
+		mux.reportUDPResult(false)
+	}
+	if !mux.UDPFallbackActive() {
+		t.Fatalf("UDP fallback is not active after %d failures", udpFallbackThreshold)
+	}
+
+	mux.mu.Lock()
+	p := mux.udpFallbackEndpoint(udpEndpoint)
+	mux.mu.Unlock()
+	if p.TransportProtocol() != util.TCPTransport {
+		t.Errorf("fallback transport protocol = %v, want %v", p.TransportProtocol(), util.TCPTransport)
+	}
+	if p.RemoteAddr().String() != udpAddr.String() {
+		t.Errorf("fallback remote address = %v, want %v", p.RemoteAddr(), udpAddr)
+	}
+
+	// A configured TCP endpoint is preferred.
+	tcpAddr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8965}
+	tcpEndpoint := NewUnderlayProperties(1400, util.IPVersion4, util.TCPTransport, nil, tcpAddr)
+	mux.SetEndpoints([]UnderlayProperties{udpEndpoint, tcpEndpoint})
+	mux.mu.Lock()
+	p = mux.udpFallbackEndpoint(udpEndpoint)
+	mux.mu.Unlock()
+	if p.RemoteAddr().String() != tcpAddr.String() {
+		t.Errorf("fallback remote address = %v, want %v", p.RemoteAddr(), tcpAddr)
+	}
+
+	// Expired fallback is not renewed until UDP fails again.
+	mux.mu.Lock()
+	mux.udpFallbackUntil = time.Now().Add(-time.Second)
+	mux.mu.Unlock()
+	if mux.UDPFallbackActive() {
+		t.Errorf("UDP fallback is active after it expires")
+	}
+	mux.reportUDPResult(true)
+	mux.reportUDPResult(false)
+	if mux.UDPFallbackActive() {
+		t.Errorf("UDP fallback is active after UDP is restored")
+	}
+}
+
+func TestUDPUnderlayIsUnresponsive(t *testing.T) {
+	u := &UDPUnderlay{baseUnderlay: *newBaseUnderlay(true, 1400, MimicryPlain)}
+	if u.isUnresponsive() {
+		t.Errorf("underlay is unresponsive before sending anything")
+	}
+	u.firstSendTime.Store(time.Now().Add(-2 * udpNoResponseTimeout).UnixNano())
+	if !u.isUnresponsive() {
+		t.Errorf("underlay is not unresponsive without any response")
+	}
+	u.responded.Store(true)
+	if u.isUnresponsive() {
+		t.Errorf("underlay is unresponsive after receiving a response")
+	}
+}
@@ -18,18 +18,36 @@ package protocolv2
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/enfein/mieru/pkg/metrics"
 	"github.com/enfein/mieru/pkg/util"
 )
 
 var (
-	UnderlayMaxConn         = metrics.RegisterMetric("underlay", "MaxConn", metrics.GAUGE)
-	UnderlayActiveOpens     = metrics.RegisterMetric("underlay", "ActiveOpens", metrics.COUNTER)
-	UnderlayPassiveOpens    = metrics.RegisterMetric("underlay", "PassiveOpens", metrics.COUNTER)
-	UnderlayCurrEstablished = metrics.RegisterMetric("underlay", "CurrEstablished", metrics.GAUGE)
-	UnderlayMalformedUDP    = metrics.RegisterMetric("underlay", "UnderlayMalformedUDP", metrics.COUNTER)
-	UnderlayUnsolicitedUDP  = metrics.RegisterMetric("underlay", "UnsolicitedUDP", metrics.COUNTER)
+	UnderlayMaxConn             = metrics.RegisterMetric("underlay", "MaxConn", metrics.GAUGE)
+	UnderlayActiveOpens         = metrics.RegisterMetric("underlay", "ActiveOpens", metrics.COUNTER)
+	UnderlayPassiveOpens        = metrics.RegisterMetric("underlay", "PassiveOpens", metrics.COUNTER)
+	UnderlayCurrEstablished     = metrics.RegisterMetric("underlay", "CurrEstablished", metrics.GAUGE)
+	UnderlayMalformedUDP        = metrics.RegisterMetric("underlay", "UnderlayMalformedUDP", metrics.COUNTER)
+	UnderlayUnsolicitedUDP      = metrics.RegisterMetric("underlay", "UnsolicitedUDP", metrics.COUNTER)
+	UnderlayUDPFallbacks        = metrics.RegisterMetric("underlay", "UDPFallbacks", metrics.COUNTER)
+	UnderlayDeadPeers           = metrics.RegisterMetric("underlay", "DeadPeers", metrics.COUNTER)
+	UnderlayPrewarmHits         = metrics.RegisterMetric("underlay", "PrewarmHits", metrics.COUNTER)
+	UnderlayClockAdjusts        = metrics.RegisterMetric("underlay", "ClockAdjusts", metrics.COUNTER)
+	UnderlayUDPSegmentsSent     = metrics.RegisterMetric("underlay", "UDPSegmentsSent", metrics.COUNTER)
+	UnderlayUDPRetransmits      = metrics.RegisterMetric("underlay", "UDPRetransmits", metrics.COUNTER)
+	UnderlayUDPSelectiveAcks    = metrics.RegisterMetric("underlay", "UDPSelectiveAcks", metrics.COUNTER)
+	UnderlayUDPBatchReads       = metrics.RegisterMetric("underlay", "UDPBatchReads", metrics.COUNTER)
+	UnderlayUDPBatchWrites      = metrics.RegisterMetric("underlay", "UDPBatchWrites", metrics.COUNTER)
+	UnderlayJitterDelays        = metrics.RegisterMetric("underlay", "JitterDelays", metrics.COUNTER)
+	UnderlayReadTimeouts        = metrics.RegisterMetric("underlay", "ReadTimeouts", metrics.COUNTER)
+	UnderlayReaped              = metrics.RegisterMetric("underlay", "Reaped", metrics.COUNTER)
+	UnderlayNetworkResets       = metrics.RegisterMetric("underlay", "NetworkResets", metrics.COUNTER)
+	UnderlayIPBans              = metrics.RegisterMetric("underlay", "IPBans", metrics.COUNTER)
+	UnderlayBannedDrops         = metrics.RegisterMetric("underlay", "BannedDrops", metrics.COUNTER)
+	UnderlayLimitRejects        = metrics.RegisterMetric("underlay", "LimitRejects", metrics.COUNTER)
+	UnderlaySessionLimitRejects = metrics.RegisterMetric("underlay", "SessionLimitRejects", metrics.COUNTER)
 )
 
 // UnderlayProperties defines network properties of a underlay.
@@ -43,6 +61,9 @@ type UnderlayProperties interface {
 	// The transport protocol used to implement the underlay.
 	TransportProtocol() util.TransportProtocol
 
+	// The disguise of the traffic carried by the underlay.
+	Mimicry() Mimicry
+
 	// LocalAddr implements net.Conn interface.
 	LocalAddr() net.Addr
 
@@ -86,6 +107,7 @@ type underlayDescriptor struct {
 	mtu               int
 	ipVersion         util.IPVersion
 	transportProtocol util.TransportProtocol
+	mimicry           Mimicry
 	localAddr         net.Addr
 	remoteAddr        net.Addr
 }
@@ -104,6 +126,10 @@ func (d *underlayDescriptor) TransportProtocol() util.TransportProtocol {
 	return d.transportProtocol
 }
 
+func (d *underlayDescriptor) Mimicry() Mimicry {
+	return d.mimicry
+}
+
 func (d *underlayDescriptor) LocalAddr() net.Addr {
 	return d.localAddr
 }
@@ -112,12 +138,20 @@ func (d *underlayDescriptor) RemoteAddr() net.Addr {
 	return d.remoteAddr
 }
 
-// NewUnderlayProperties creates a new instance of UnderlayProperties.
+// NewUnderlayProperties creates a new instance of UnderlayProperties
+// without mimicry.
 func NewUnderlayProperties(mtu int, ipVersion util.IPVersion, transportProtocol util.TransportProtocol, localAddr net.Addr, remoteAddr net.Addr) UnderlayProperties {
+	return NewUnderlayPropertiesWithMimicry(mtu, ipVersion, transportProtocol, MimicryPlain, localAddr, remoteAddr)
+}
+
+// NewUnderlayPropertiesWithMimicry creates a new instance of UnderlayProperties
+// that disguises the traffic with the mimicry.
+func NewUnderlayPropertiesWithMimicry(mtu int, ipVersion util.IPVersion, transportProtocol util.TransportProtocol, mimicry Mimicry, localAddr net.Addr, remoteAddr net.Addr) UnderlayProperties {
 	d := &underlayDescriptor{
 		mtu:               mtu,
 		ipVersion:         ipVersion,
 		transportProtocol: transportProtocol,
+		mimicry:           mimicry,
 		localAddr:         localAddr,
 		remoteAddr:        remoteAddr,
 	}
@@ -129,3 +163,55 @@ func NewUnderlayProperties(mtu int, ipVersion util.IPVersion, transportProtocol
 	}
 	return d
 }
+
+// UnderlayInfo provides the information of an Underlay.
+type UnderlayInfo struct {
+	Protocol   string
+	LocalAddr  string
+	RemoteAddr string
+	Sessions   int // number of attached sessions
+
+	BytesSent        int64 // bytes written to the network, including padding
+	BytesReceived    int64 // bytes read from the network, including padding
+	SegmentsSent     int64
+	SegmentsReceived int64
+
+	// The following fields are only available for client TCP underlays.
+	LastRecv         time.Duration // time since anything is received from the server
+	KeepalivePending bool          // a keepalive request is not answered yet
+}
+
+// ToUnderlayInfo creates related UnderlayInfo structure.
+func ToUnderlayInfo(u Underlay) UnderlayInfo {
+	info := UnderlayInfo{
+		LocalAddr:  u.LocalAddr().String(),
+		RemoteAddr: u.RemoteAddr().String(),
+		Sessions:   len(u.Sessions()),
+	}
+	switch u.TransportProtocol() {
+	case util.TCPTransport:
+		info.Protocol = "TCP"
+	case util.UDPTransport:
+		info.Protocol = "UDP"
+	default:
+		info.Protocol = "UNKNOWN"
+	}
+	var base *baseUnderlay
+	switch v := u.(type) {
+	case *TCPUnderlay:
+		base = &v.baseUnderlay
+	case *UDPUnderlay:
+		base = &v.baseUnderlay
+	}
+	if base != nil {
+		info.BytesSent = base.bytesSent.Load()
+		info.BytesReceived = base.bytesReceived.Load()
+		info.SegmentsSent = base.segmentsSent.Load()
+		info.SegmentsReceived = base.segmentsReceived.Load()
+	}
+	if t, ok := u.(*TCPUnderlay); ok && t.isClient {
+		info.LastRecv = time.Since(time.Unix(0, t.lastRecvTime.Load()))
+		info.KeepalivePending = t.keepaliveSentTime.Load() != 0
+	}
+	return info
+}
@@ -21,7 +21,9 @@ import (
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 
+	"github.com/enfein/mieru/pkg/event"
 	"github.com/enfein/mieru/pkg/metrics"
 	"github.com/enfein/mieru/pkg/stderror"
 	"github.com/enfein/mieru/pkg/util"
@@ -34,27 +36,37 @@ type baseUnderlay struct {
 	isClient  bool
 	mtu       int
 	ipVersion util.IPVersion
+	mimicry   Mimicry
 	done      chan struct{} // if the underlay is closed
 
-	sessionMap    sync.Map      // Map<sessionID, *Session>
+	sessionMap    sessionMap    // Map<sessionID, *Session>
 	readySessions chan *Session // sessions that completed handshake and ready for consume
 
-	sendMutex  sync.Mutex // protect writing data to the connection
-	closeMutex sync.Mutex // protect closing the connection
+	sendMutex  priorityMutex // protect writing data to the connection
+	closeMutex sync.Mutex    // protect closing the connection
+
+	// Traffic counters, exported by ToUnderlayInfo.
+	bytesSent        atomic.Int64
+	bytesReceived    atomic.Int64
+	segmentsSent     atomic.Int64
+	segmentsReceived atomic.Int64
 
 	// ---- client fields ----
 	scheduler *ScheduleController
+	endpoint  string // key of the endpoint in clientEndpointLoads
+	clockSync bool   // if the clock offset follows the server time
 }
 
 var (
 	_ Underlay = &baseUnderlay{}
 )
 
-func newBaseUnderlay(isClient bool, mtu int) *baseUnderlay {
+func newBaseUnderlay(isClient bool, mtu int, mimicry Mimicry) *baseUnderlay {
 	return &baseUnderlay{
 		isClient:      isClient,
-		mtu:           mtu,
+		mtu:           mimicryMTU(mtu, mimicry),
 		ipVersion:     util.IPVersionUnknown,
+		mimicry:       mimicry,
 		done:          make(chan struct{}),
 		readySessions: make(chan *Session, sessionChanCapacity),
 		scheduler:     &ScheduleController{},
@@ -79,15 +91,14 @@ func (b *baseUnderlay) Close() error {
 	default:
 	}
 
-	b.sessionMap.Range(func(k, v any) bool {
-		s := v.(*Session)
+	b.sessionMap.Range(func(id uint32, s *Session) bool {
 		s.Close()
 		s.wg.Wait()
 		s.conn = nil
 		s = nil
 		return true
 	})
-	b.sessionMap = sync.Map{}
+	b.sessionMap.Clear()
 	close(b.done)
 	UnderlayCurrEstablished.Add(-1)
 	return nil
@@ -110,6 +121,10 @@ func (b *baseUnderlay) TransportProtocol() util.TransportProtocol {
 	return util.UnknownTransport
 }
 
+func (b *baseUnderlay) Mimicry() Mimicry {
+	return b.mimicry
+}
+
 func (b *baseUnderlay) LocalAddr() net.Addr {
 	return util.NilNetAddr()
 }
@@ -170,12 +185,7 @@ func (b *baseUnderlay) RemoveSession(s *Session) error {
 
 	if b.isClient {
 		// May disable scheduling if the underlay has no session.
-		sessions := 0
-		b.sessionMap.Range(func(k, v any) bool {
-			sessions += 1
-			return false
-		})
-		if sessions == 0 {
+		if b.sessionMap.Len() == 0 {
 			b.scheduler.TryDisable()
 		}
 	}
@@ -185,8 +195,7 @@ func (b *baseUnderlay) RemoveSession(s *Session) error {
 
 func (b *baseUnderlay) Sessions() []SessionInfo {
 	res := make([]SessionInfo, 0)
-	b.sessionMap.Range(func(k, v any) bool {
-		s := v.(*Session)
+	b.sessionMap.Range(func(id uint32, s *Session) bool {
 		res = append(res, s.ToSessionInfo())
 		return true
 	})
@@ -204,3 +213,31 @@ func (b *baseUnderlay) Scheduler() *ScheduleController {
 func (b *baseUnderlay) Done() chan struct{} {
 	return b.done
 }
+
+// emitReplayDetected sends a replay detected event. Events from the same
+// remote IP address are merged.
+func emitReplayDetected(network string, remoteAddr net.Addr) {
+	if remoteAddr == nil {
+		return
+	}
+	ip := remoteAddr.String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	event.Emit(event.ReplayDetected, ip, map[string]string{
+		"network": network,
+		"remote":  remoteAddr.String(),
+	}, "found possible replay attack from %s over %s", ip, network)
+}
+
+// addInBytes counts bytes read from the network.
+func (b *baseUnderlay) addInBytes(n int64) {
+	b.bytesReceived.Add(n)
+	metrics.InBytes.Add(n)
+}
+
+// addOutBytes counts bytes written to the network.
+func (b *baseUnderlay) addOutBytes(n int64) {
+	b.bytesSent.Add(n)
+	metrics.OutBytes.Add(n)
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultStuckUnderlayTimeout is the default time without receiving
+	// any segment before a TCP underlay is closed by the reaper.
+	// A client with sessions sends a keepalive request after
+	// keepaliveIdleTime, so a live underlay is never reaped.
+	defaultStuckUnderlayTimeout = 10 * time.Minute
+
+	// minUnderlayTimeout is the minimum read timeout and stuck timeout.
+	minUnderlayTimeout = 2 * keepaliveIdleTime
+)
+
+// UnderlayTimeoutConfig holds the timeouts of TCP underlays.
+type UnderlayTimeoutConfig struct {
+	// ReadTimeout is the maximum time to wait for the next segment.
+	// If it is exceeded, the read fails and the underlay is closed.
+	// A zero value disables the read deadline.
+	ReadTimeout time.Duration
+
+	// StuckTimeout is the maximum time without receiving any segment
+	// before the underlay is closed by the reaper of the multiplexer.
+	// A zero value uses the default.
+	StuckTimeout time.Duration
+}
+
+// underlayTimeoutConfig is used by TCP underlays.
+var underlayTimeoutConfig atomic.Pointer[UnderlayTimeoutConfig]
+
+// SetUnderlayTimeoutConfig changes the timeouts of TCP underlays.
+// The read timeout of an existing underlay is changed from the next read.
+func SetUnderlayTimeoutConfig(config UnderlayTimeoutConfig) error {
+	for name, timeout := range map[string]time.Duration{
+		"read timeout":  config.ReadTimeout,
+		"stuck timeout": config.StuckTimeout,
+	} {
+		if timeout != 0 && timeout < minUnderlayTimeout {
+			return fmt.Errorf("underlay %s %v is smaller than %v", name, timeout, minUnderlayTimeout)
+		}
+	}
+	underlayTimeoutConfig.Store(&config)
+	return nil
+}
+
+// underlayReadTimeout returns the read timeout of TCP underlays.
+// 0 means there is no read deadline.
+func underlayReadTimeout() time.Duration {
+	if config := underlayTimeoutConfig.Load(); config != nil {
+		return config.ReadTimeout
+	}
+	return 0
+}
+
+// stuckUnderlayTimeout returns the stuck timeout of TCP underlays.
+func stuckUnderlayTimeout() time.Duration {
+	if config := underlayTimeoutConfig.Load(); config != nil && config.StuckTimeout != 0 {
+		return config.StuckTimeout
+	}
+	return defaultStuckUnderlayTimeout
+}
+
+// isStuck returns true if the underlay hasn't received any segment
+// within the stuck timeout. Such an underlay is likely half-dead, and
+// its event loop may be blocked forever in reading. An underlay whose
+// event loop is not started is never stuck.
+func (t *TCPUnderlay) isStuck(now time.Time) bool {
+	last := t.lastRecvTime.Load()
+	if last == 0 {
+		return false
+	}
+	return now.Sub(time.Unix(0, last)) > stuckUnderlayTimeout()
+}
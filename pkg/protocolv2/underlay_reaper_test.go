@@ -0,0 +1,96 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSetUnderlayTimeoutConfig(t *testing.T) {
+	defer underlayTimeoutConfig.Store(nil)
+
+	if err := SetUnderlayTimeoutConfig(UnderlayTimeoutConfig{ReadTimeout: time.Second}); err == nil {
+		t.Errorf("SetUnderlayTimeoutConfig() with 1 second read timeout succeeded, want error")
+	}
+	if err := SetUnderlayTimeoutConfig(UnderlayTimeoutConfig{StuckTimeout: time.Second}); err == nil {
+		t.Errorf("SetUnderlayTimeoutConfig() with 1 second stuck timeout succeeded, want error")
+	}
+	if got := stuckUnderlayTimeout(); got != defaultStuckUnderlayTimeout {
+		t.Errorf("stuckUnderlayTimeout() = %v, want %v", got, defaultStuckUnderlayTimeout)
+	}
+	if err := SetUnderlayTimeoutConfig(UnderlayTimeoutConfig{ReadTimeout: time.Minute, StuckTimeout: 2 * time.Minute}); err != nil {
+		t.Fatalf("SetUnderlayTimeoutConfig() failed: %v", err)
+	}
+	if got := underlayReadTimeout(); got != time.Minute {
+		t.Errorf("underlayReadTimeout() = %v, want %v", got, time.Minute)
+	}
+	if got := stuckUnderlayTimeout(); got != 2*time.Minute {
+		t.Errorf("stuckUnderlayTimeout() = %v, want %v", got, 2*time.Minute)
+	}
+}
+
+func TestTCPUnderlayReadTimeout(t *testing.T) {
+	defer underlayTimeoutConfig.Store(nil)
+	underlayTimeoutConfig.Store(&UnderlayTimeoutConfig{ReadTimeout: 50 * time.Millisecond})
+
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	underlay := &TCPUnderlay{baseUnderlay: *newBaseUnderlay(false, 1500, MimicryPlain), conn: conn}
+	defer underlay.Close()
+	if _, err, _ := underlay.readOneSegment(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("readOneSegment() = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+}
+
+func TestCleanUnderlayReapsStuckUnderlay(t *testing.T) {
+	now := time.Now()
+	newUnderlay := func(lastRecv time.Time) *TCPUnderlay {
+		conn, peer := net.Pipe()
+		t.Cleanup(func() { peer.Close() })
+		underlay := &TCPUnderlay{baseUnderlay: *newBaseUnderlay(false, 1500, MimicryPlain), conn: conn}
+		if !lastRecv.IsZero() {
+			underlay.lastRecvTime.Store(lastRecv.UnixNano())
+		}
+		return underlay
+	}
+	live := newUnderlay(now)
+	stuck := newUnderlay(now.Add(-2 * defaultStuckUnderlayTimeout))
+	notStarted := newUnderlay(time.Time{})
+
+	m := NewMux(false)
+	defer m.Close()
+	reaped := UnderlayReaped.Load()
+	m.mu.Lock()
+	m.underlays = []Underlay{live, stuck, notStarted}
+	m.cleanUnderlay()
+	remaining := len(m.underlays)
+	m.mu.Unlock()
+	if remaining != 2 {
+		t.Errorf("got %d underlays after clean, want 2", remaining)
+	}
+	select {
+	case <-stuck.Done():
+	default:
+		t.Errorf("stuck underlay is not closed")
+	}
+	if got := UnderlayReaped.Load() - reaped; got != 1 {
+		t.Errorf("got %d reaped underlays, want 1", got)
+	}
+}
@@ -17,9 +17,12 @@ package protocolv2
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/enfein/mieru/pkg/appctl/appctlpb"
@@ -36,7 +39,7 @@ import (
 
 type TCPUnderlay struct {
 	baseUnderlay
-	conn *net.TCPConn
+	conn net.Conn
 
 	send cipher.BlockCipher
 	recv cipher.BlockCipher
@@ -45,18 +48,31 @@ type TCPUnderlay struct {
 	// When isClient is true, there must be exactly 1 element in the slice.
 	candidates []cipher.BlockCipher
 
+	lastRecvTime atomic.Int64 // unix nanoseconds of the last received segment
+
+	// ---- client fields ----
+	peerKeepalive     atomic.Bool  // server answers keepalive requests
+	keepaliveSentTime atomic.Int64 // unix nanoseconds, 0 if no request is pending
+	keepaliveSeq      atomic.Uint32
+
 	// ---- server fields ----
 	users map[string]*appctlpb.User
+
+	// connectionLimitReleases give back the underlay counted by
+	// the connection limits. They are called when the underlay is closed.
+	connectionLimitReleases []func()
 }
 
 var _ Underlay = &TCPUnderlay{}
 
-var tcpReplayCache = replay.NewCache(4*1024*1024, 2*time.Minute)
+var tcpReplayCache = replay.NewCache(replay.DefaultCapacity, replay.DefaultExpireInterval)
 
 // NewTCPUnderlay connects to the remote address "raddr" on the network "tcp"
 // with packet encryption. If "laddr" is empty, an automatic address is used.
 // "block" is the block encryption algorithm to encrypt packets.
-func NewTCPUnderlay(ctx context.Context, network, laddr, raddr string, mtu int, block cipher.BlockCipher) (*TCPUnderlay, error) {
+// "mimicry" is the disguise of the TCP connection.
+// "srcPref" is the preference of local IPv6 source address.
+func NewTCPUnderlay(ctx context.Context, network, laddr, raddr string, mtu int, block cipher.BlockCipher, mimicry Mimicry, srcPref sockopts.IPv6SourcePreference) (*TCPUnderlay, error) {
 	switch network {
 	case "tcp", "tcp4", "tcp6":
 	default:
@@ -66,7 +82,7 @@ func NewTCPUnderlay(ctx context.Context, network, laddr, raddr string, mtu int,
 		return nil, fmt.Errorf("TCP block cipher must not be stateless")
 	}
 	dialer := net.Dialer{
-		Control: sockopts.ReuseAddrPort(),
+		Control: sockopts.Append(sockopts.ReuseAddrPort(), sockopts.IPv6SourcePreferenceControl(srcPref)),
 	}
 	if laddr != "" {
 		tcpLocalAddr, err := net.ResolveTCPAddr(network, laddr)
@@ -76,13 +92,18 @@ func NewTCPUnderlay(ctx context.Context, network, laddr, raddr string, mtu int,
 		dialer.LocalAddr = tcpLocalAddr
 	}
 
-	conn, err := dialer.DialContext(ctx, network, raddr)
+	rawConn, err := dialer.DialContext(ctx, network, raddr)
 	if err != nil {
 		return nil, fmt.Errorf("DialContext() failed: %w", err)
 	}
+	conn, err := clientWrapTCPConn(rawConn, mimicry)
+	if err != nil {
+		rawConn.Close()
+		return nil, err
+	}
 	t := &TCPUnderlay{
-		baseUnderlay: *newBaseUnderlay(true, mtu),
-		conn:         conn.(*net.TCPConn),
+		baseUnderlay: *newBaseUnderlay(true, mtu, mimicry),
+		conn:         conn,
 		candidates:   []cipher.BlockCipher{block},
 	}
 	log.Debugf("Created new client TCP underlay %v", t)
@@ -93,6 +114,9 @@ func (t *TCPUnderlay) String() string {
 	if t.conn == nil {
 		return "TCPUnderlay{}"
 	}
+	if t.mimicry != MimicryPlain {
+		return fmt.Sprintf("TCPUnderlay{local=%v, remote=%v, mtu=%v, ipVersion=%v, mimicry=%v}", t.conn.LocalAddr(), t.conn.RemoteAddr(), t.mtu, t.IPVersion(), t.mimicry)
+	}
 	return fmt.Sprintf("TCPUnderlay{local=%v, remote=%v, mtu=%v, ipVersion=%v}", t.conn.LocalAddr(), t.conn.RemoteAddr(), t.mtu, t.IPVersion())
 }
 
@@ -107,9 +131,28 @@ func (t *TCPUnderlay) Close() error {
 
 	log.Debugf("Closing %v", t)
 	t.baseUnderlay.Close()
+	for _, release := range t.connectionLimitReleases {
+		release()
+	}
+	t.connectionLimitReleases = nil
 	return t.conn.Close()
 }
 
+// addConnectionLimitRelease registers a function to give back the
+// underlay counted by the connection limits. The function is called
+// immediately if the underlay is already closed.
+func (t *TCPUnderlay) addConnectionLimitRelease(release func()) {
+	t.closeMutex.Lock()
+	defer t.closeMutex.Unlock()
+	select {
+	case <-t.done:
+		release()
+		return
+	default:
+	}
+	t.connectionLimitReleases = append(t.connectionLimitReleases, release)
+}
+
 func (t *TCPUnderlay) Addr() net.Addr {
 	return t.LocalAddr()
 }
@@ -170,6 +213,10 @@ func (t *TCPUnderlay) RunEventLoop(ctx context.Context) error {
 	if t.conn == nil {
 		return stderror.ErrNullPointer
 	}
+	t.lastRecvTime.Store(time.Now().UnixNano())
+	if t.isClient {
+		go t.runKeepalive(ctx)
+	}
 
 	for {
 		select {
@@ -182,12 +229,25 @@ func (t *TCPUnderlay) RunEventLoop(ctx context.Context) error {
 		seg, err, errType := t.readOneSegment()
 		if err != nil {
 			if errType == stderror.CRYPTO_ERROR || errType == stderror.REPLAY_ERROR {
+				if !t.isClient {
+					if tcpAddr, ok := t.conn.RemoteAddr().(*net.TCPAddr); ok {
+						serverIPBans.recordFailure(tcpAddr.IP, time.Now())
+					}
+				}
 				t.drainAfterError()
 			}
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				UnderlayReadTimeouts.Add(1)
+			}
 			return fmt.Errorf("readOneSegment() failed: %w", err)
 		}
+		t.segmentsReceived.Add(1)
+		t.lastRecvTime.Store(time.Now().UnixNano())
+		if t.isClient {
+			t.keepaliveSentTime.Store(0)
+		}
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v received %v", t, seg)
+			tcpReceiveLogSampler.Tracef("%v received %v", t, seg)
 		}
 		if isSessionProtocol(seg.metadata.Protocol()) {
 			switch seg.metadata.Protocol() {
@@ -203,6 +263,14 @@ func (t *TCPUnderlay) RunEventLoop(ctx context.Context) error {
 				if err := t.onCloseSession(seg); err != nil {
 					return fmt.Errorf("onCloseSession() failed: %w", err)
 				}
+			case serverMessage:
+				t.onServerMessage(seg)
+			case keepaliveRequest:
+				if err := t.onKeepaliveRequest(seg); err != nil {
+					return fmt.Errorf("onKeepaliveRequest() failed: %w", err)
+				}
+			case keepaliveResponse:
+				// Receive time is already recorded.
 			default:
 				panic(fmt.Sprintf("Protocol %d is a session protocol but not recognized by TCP underlay", seg.metadata.Protocol()))
 			}
@@ -229,9 +297,9 @@ func (t *TCPUnderlay) RunEventLoop(ctx context.Context) error {
 				}
 				continue
 			}
-			session.(*Session).recvChan <- seg
+			session.recvChan <- seg
 		} else {
-			log.Debugf("Ignore unknown protocol %d", seg.metadata.Protocol())
+			unknownProtocolLogSampler.Debugf("Ignore unknown protocol %d", seg.metadata.Protocol())
 		}
 	}
 }
@@ -252,6 +320,7 @@ func (t *TCPUnderlay) onOpenSessionRequest(seg *segment) error {
 		return fmt.Errorf("%v received open session request, but session ID %d is already used", t, sessionID)
 	}
 	session := NewSession(sessionID, false, t.MTU())
+	session.startTrace(context.Background())
 	session.users = t.users
 	t.AddSession(session, nil)
 	session.recvChan <- seg
@@ -264,30 +333,44 @@ func (t *TCPUnderlay) onOpenSessionResponse(seg *segment) error {
 		return stderror.ErrInvalidOperation
 	}
 
-	sessionID := seg.metadata.(*sessionStruct).sessionID
+	ss := seg.metadata.(*sessionStruct)
+	if ss.flags&flagKeepalive != 0 {
+		t.peerKeepalive.Store(true)
+	}
+	t.recordLoadFactor(ss)
+	t.syncClock(ss)
+	sessionID := ss.sessionID
 	session, found := t.sessionMap.Load(sessionID)
 	if !found {
 		return fmt.Errorf("session ID %d is not found", sessionID)
 	}
-	session.(*Session).recvChan <- seg
+	session.acceptProtocolVersion(ss)
+	session.recvChan <- seg
 	return nil
 }
 
 func (t *TCPUnderlay) onCloseSession(seg *segment) error {
 	ss := seg.metadata.(*sessionStruct)
 	sessionID := ss.sessionID
-	session, found := t.sessionMap.Load(sessionID)
+	s, found := t.sessionMap.Load(sessionID)
 	if !found {
 		log.Debugf("%v received close session request or response, but session ID %d is not found", t, sessionID)
 		return nil
 	}
-	s := session.(*Session)
 	s.recvChan <- seg
 	s.wg.Wait()
 	t.RemoveSession(s)
 	return nil
 }
 
+func (t *TCPUnderlay) onServerMessage(seg *segment) {
+	if !t.isClient {
+		log.Debugf("%v ignored server message sent by client", t)
+		return
+	}
+	recordServerMessage(string(seg.payload))
+}
+
 func (t *TCPUnderlay) readOneSegment() (*segment, error, stderror.ErrorType) {
 	var firstRead bool
 	var err error
@@ -299,14 +382,18 @@ func (t *TCPUnderlay) readOneSegment() (*segment, error, stderror.ErrorType) {
 		firstRead = true
 		readLen += cipher.DefaultNonceSize
 	}
+	if timeout := underlayReadTimeout(); timeout > 0 {
+		t.conn.SetReadDeadline(time.Now().Add(timeout))
+	}
 	encryptedMeta := make([]byte, readLen)
 	if _, err := io.ReadFull(t.conn, encryptedMeta); err != nil {
 		return nil, fmt.Errorf("metadata: read %d bytes from TCPUnderlay failed: %w", readLen, err), stderror.NETWORK_ERROR
 	}
-	metrics.InBytes.Add(int64(len(encryptedMeta)))
+	t.addInBytes(int64(len(encryptedMeta)))
 	if tcpReplayCache.IsDuplicate(encryptedMeta[:cipher.DefaultOverhead], replay.EmptyTag) {
 		if firstRead {
 			replay.NewSession.Add(1)
+			emitReplayDetected("tcp", t.conn.RemoteAddr())
 			return nil, fmt.Errorf("found possible replay attack in %v", t), stderror.REPLAY_ERROR
 		} else {
 			replay.KnownSession.Add(1)
@@ -327,6 +414,15 @@ func (t *TCPUnderlay) readOneSegment() (*segment, error, stderror.ErrorType) {
 			return nil, fmt.Errorf("cipher.SelectDecrypt() failed: %w", err), stderror.CRYPTO_ERROR
 		}
 		t.recv = peerBlock.Clone()
+		userName := peerBlock.BlockContext().UserName
+		serverCipherHits.recordHit(localPort(t.LocalAddr()), userName, time.Now())
+		release, err := serverConnectionLimits.acquireUnderlay(userName, nil)
+		if err != nil {
+			UnderlayLimitRejects.Add(1)
+			connectionLimitLogSampler.Warnf("Rejected %v: %v", t, err)
+			return nil, err, stderror.PROTOCOL_ERROR
+		}
+		t.addConnectionLimitRelease(release)
 	} else {
 		decryptedMeta, err = t.recv.Decrypt(encryptedMeta)
 		if t.isClient {
@@ -374,7 +470,7 @@ func (t *TCPUnderlay) readSessionSegment(ss *sessionStruct) (*segment, error, st
 		if _, err := io.ReadFull(t.conn, encryptedPayload); err != nil {
 			return nil, fmt.Errorf("payload: read %d bytes from TCPUnderlay failed: %w", ss.payloadLen+cipher.DefaultOverhead, err), stderror.NETWORK_ERROR
 		}
-		metrics.InBytes.Add(int64(len(encryptedPayload)))
+		t.addInBytes(int64(len(encryptedPayload)))
 		if tcpReplayCache.IsDuplicate(encryptedPayload[:cipher.DefaultOverhead], replay.EmptyTag) {
 			replay.KnownSession.Add(1)
 		}
@@ -398,7 +494,7 @@ func (t *TCPUnderlay) readSessionSegment(ss *sessionStruct) (*segment, error, st
 		if _, err := io.ReadFull(t.conn, padding); err != nil {
 			return nil, fmt.Errorf("padding: read %d bytes from TCPUnderlay failed: %w", ss.suffixLen, err), stderror.NETWORK_ERROR
 		}
-		metrics.InBytes.Add(int64(len(padding)))
+		t.addInBytes(int64(len(padding)))
 	}
 
 	return &segment{
@@ -418,14 +514,14 @@ func (t *TCPUnderlay) readDataAckSegment(das *dataAckStruct) (*segment, error, s
 		if _, err := io.ReadFull(t.conn, padding1); err != nil {
 			return nil, fmt.Errorf("padding: read %d bytes from TCPUnderlay failed: %w", das.prefixLen, err), stderror.NETWORK_ERROR
 		}
-		metrics.InBytes.Add(int64(len(padding1)))
+		t.addInBytes(int64(len(padding1)))
 	}
 	if das.payloadLen > 0 {
 		encryptedPayload := make([]byte, das.payloadLen+cipher.DefaultOverhead)
 		if _, err := io.ReadFull(t.conn, encryptedPayload); err != nil {
 			return nil, fmt.Errorf("payload: read %d bytes from TCPUnderlay failed: %w", das.payloadLen+cipher.DefaultOverhead, err), stderror.NETWORK_ERROR
 		}
-		metrics.InBytes.Add(int64(len(encryptedPayload)))
+		t.addInBytes(int64(len(encryptedPayload)))
 		if tcpReplayCache.IsDuplicate(encryptedPayload[:cipher.DefaultOverhead], replay.EmptyTag) {
 			replay.KnownSession.Add(1)
 		}
@@ -449,7 +545,7 @@ func (t *TCPUnderlay) readDataAckSegment(das *dataAckStruct) (*segment, error, s
 		if _, err := io.ReadFull(t.conn, padding2); err != nil {
 			return nil, fmt.Errorf("padding: read %d bytes from TCPUnderlay failed: %w", das.suffixLen, err), stderror.NETWORK_ERROR
 		}
-		metrics.InBytes.Add(int64(len(padding2)))
+		t.addInBytes(int64(len(padding2)))
 	}
 
 	return &segment{
@@ -465,8 +561,9 @@ func (t *TCPUnderlay) writeOneSegment(seg *segment) error {
 		return stderror.ErrNullPointer
 	}
 
-	t.sendMutex.Lock()
+	t.sendMutex.Lock(seg.priority)
 	defer t.sendMutex.Unlock()
+	waitTimingJitter()
 
 	if ss, ok := toSessionStruct(seg.metadata); ok {
 		maxPaddingSize := MaxPaddingSize(t.mtu, t.IPVersion(), t.TransportProtocol(), int(ss.payloadLen), 0)
@@ -476,7 +573,7 @@ func (t *TCPUnderlay) writeOneSegment(seg *segment) error {
 		})
 		ss.suffixLen = uint8(len(padding))
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v is sending %v", t, seg)
+			tcpSendLogSampler.Tracef("%v is sending %v", t, seg)
 		}
 
 		plaintextMetadata := seg.metadata.Marshal()
@@ -487,19 +584,21 @@ func (t *TCPUnderlay) writeOneSegment(seg *segment) error {
 		if err != nil {
 			return fmt.Errorf("Encrypt() failed: %w", err)
 		}
-		dataToSend := encryptedMetadata
+		dataToSend := net.Buffers{encryptedMetadata}
 		if len(seg.payload) > 0 {
 			encryptedPayload, err := t.send.Encrypt(seg.payload)
 			if err != nil {
 				return fmt.Errorf("Encrypt() failed: %w", err)
 			}
-			dataToSend = append(dataToSend, encryptedPayload...)
+			dataToSend = append(dataToSend, encryptedPayload)
 		}
-		dataToSend = append(dataToSend, padding...)
-		if _, err := t.conn.Write(dataToSend); err != nil {
-			return fmt.Errorf("Write() failed: %w", err)
+		dataToSend = append(dataToSend, padding)
+		n, err := t.writeBuffers(dataToSend)
+		if err != nil {
+			return fmt.Errorf("writeBuffers() failed: %w", err)
 		}
-		metrics.OutBytes.Add(int64(len(dataToSend)))
+		t.addOutBytes(n)
+		t.segmentsSent.Add(1)
 		metrics.OutPaddingBytes.Add(int64(len(padding)))
 	} else if das, ok := toDataAckStruct(seg.metadata); ok {
 		padding1 := newPadding(paddingOpts{
@@ -511,7 +610,7 @@ func (t *TCPUnderlay) writeOneSegment(seg *segment) error {
 		das.prefixLen = uint8(len(padding1))
 		das.suffixLen = uint8(len(padding2))
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v is sending %v", t, seg)
+			tcpSendLogSampler.Tracef("%v is sending %v", t, seg)
 		}
 
 		plaintextMetadata := seg.metadata.Marshal()
@@ -522,19 +621,21 @@ func (t *TCPUnderlay) writeOneSegment(seg *segment) error {
 		if err != nil {
 			return fmt.Errorf("Encrypt() failed: %w", err)
 		}
-		dataToSend := append(encryptedMetadata, padding1...)
+		dataToSend := net.Buffers{encryptedMetadata, padding1}
 		if len(seg.payload) > 0 {
 			encryptedPayload, err := t.send.Encrypt(seg.payload)
 			if err != nil {
 				return fmt.Errorf("Encrypt() failed: %w", err)
 			}
-			dataToSend = append(dataToSend, encryptedPayload...)
+			dataToSend = append(dataToSend, encryptedPayload)
 		}
-		dataToSend = append(dataToSend, padding2...)
-		if _, err := t.conn.Write(dataToSend); err != nil {
-			return fmt.Errorf("Write() failed: %w", err)
+		dataToSend = append(dataToSend, padding2)
+		n, err := t.writeBuffers(dataToSend)
+		if err != nil {
+			return fmt.Errorf("writeBuffers() failed: %w", err)
 		}
-		metrics.OutBytes.Add(int64(len(dataToSend)))
+		t.addOutBytes(n)
+		t.segmentsSent.Add(1)
 		metrics.OutPaddingBytes.Add(int64(len(padding1)))
 		metrics.OutPaddingBytes.Add(int64(len(padding2)))
 	} else {
@@ -543,6 +644,27 @@ func (t *TCPUnderlay) writeOneSegment(seg *segment) error {
 	return nil
 }
 
+// writeBuffers writes the buffers to the connection as a single segment.
+// If the connection is a plain TCP connection, the buffers are sent
+// with one writev system call without concatenation. Otherwise, for example
+// when the connection is wrapped by a mimicry, the buffers are concatenated
+// before writing, so they are not split into multiple records or packets.
+func (t *TCPUnderlay) writeBuffers(buffers net.Buffers) (int64, error) {
+	if _, ok := t.conn.(*net.TCPConn); ok {
+		return buffers.WriteTo(t.conn)
+	}
+	size := 0
+	for _, b := range buffers {
+		size += len(b)
+	}
+	data := make([]byte, 0, size)
+	for _, b := range buffers {
+		data = append(data, b...)
+	}
+	n, err := t.conn.Write(data)
+	return int64(n), err
+}
+
 func (t *TCPUnderlay) maybeInitSendBlockCipher() error {
 	if t.send != nil {
 		return nil
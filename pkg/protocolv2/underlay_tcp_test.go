@@ -0,0 +1,68 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestTCPUnderlayWriteBuffers(t *testing.T) {
+	listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatalf("net.ListenTCP() failed: %v", err)
+	}
+	defer listener.Close()
+	tcpConn, err := net.DialTCP("tcp", nil, listener.Addr().(*net.TCPAddr))
+	if err != nil {
+		t.Fatalf("net.DialTCP() failed: %v", err)
+	}
+	defer tcpConn.Close()
+	peer, err := listener.AcceptTCP()
+	if err != nil {
+		t.Fatalf("AcceptTCP() failed: %v", err)
+	}
+	defer peer.Close()
+	pipeConn, pipePeer := net.Pipe()
+	defer pipeConn.Close()
+	defer pipePeer.Close()
+
+	want := []byte("metadata|payload|padding")
+	for _, tc := range []struct {
+		name string
+		conn net.Conn
+		peer net.Conn
+	}{
+		{"tcp", tcpConn, peer},
+		{"wrapped", pipeConn, pipePeer},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			underlay := &TCPUnderlay{conn: tc.conn}
+			go func() {
+				underlay.writeBuffers(net.Buffers{[]byte("metadata|"), []byte("payload|"), []byte("padding")})
+			}()
+			got := make([]byte, len(want))
+			if _, err := io.ReadFull(tc.peer, got); err != nil {
+				t.Fatalf("io.ReadFull() failed: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
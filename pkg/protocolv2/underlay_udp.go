@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/enfein/mieru/pkg/appctl/appctlpb"
@@ -44,18 +45,20 @@ const (
 	readOneSegmentTimeout = 5 * time.Second
 )
 
-var udpReplayCache = replay.NewCache(4*1024*1024, 2*time.Minute)
+var udpReplayCache = replay.NewCache(replay.DefaultCapacity, replay.DefaultExpireInterval)
 
 type UDPUnderlay struct {
 	// ---- common fields ----
 	baseUnderlay
-	conn *net.UDPConn
+	conn udpPacketConn
 
 	idleSessionTicker *time.Ticker
 
 	// ---- client fields ----
-	serverAddr *net.UDPAddr
-	block      cipher.BlockCipher
+	serverAddr    *net.UDPAddr
+	block         cipher.BlockCipher
+	firstSendTime atomic.Int64 // unix nanoseconds
+	responded     atomic.Bool
 
 	// ---- server fields ----
 	users map[string]*appctlpb.User
@@ -66,7 +69,9 @@ var _ Underlay = &UDPUnderlay{}
 // NewUDPUnderlay connects to the remote address "raddr" on the network "udp"
 // with packet encryption. If "laddr" is empty, an automatic address is used.
 // "block" is the block encryption algorithm to encrypt packets.
-func NewUDPUnderlay(ctx context.Context, network, laddr, raddr string, mtu int, block cipher.BlockCipher) (*UDPUnderlay, error) {
+// "mimicry" is the disguise of the UDP packets.
+// "srcPref" is the preference of local IPv6 source address.
+func NewUDPUnderlay(ctx context.Context, network, laddr, raddr string, mtu int, block cipher.BlockCipher, mimicry Mimicry, srcPref sockopts.IPv6SourcePreference) (*UDPUnderlay, error) {
 	switch network {
 	case "udp", "udp4", "udp6":
 	default:
@@ -95,9 +100,18 @@ func NewUDPUnderlay(ctx context.Context, network, laddr, raddr string, mtu int,
 	if err := sockopts.ApplyUDPControls(conn); err != nil {
 		return nil, fmt.Errorf("ApplyUDPControls() failed: %w", err)
 	}
+	if err := sockopts.ApplyIPv6SourcePreference(conn, srcPref); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ApplyIPv6SourcePreference() failed: %w", err)
+	}
+	wrappedConn, err := wrapUDPConn(conn, mimicry, true)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
 	u := &UDPUnderlay{
-		baseUnderlay:      *newBaseUnderlay(true, mtu),
-		conn:              conn,
+		baseUnderlay:      *newBaseUnderlay(true, mtu, mimicry),
+		conn:              wrappedConn,
 		idleSessionTicker: time.NewTicker(idleSessionTickerInterval),
 		serverAddr:        remoteAddr,
 		block:             block,
@@ -194,8 +208,7 @@ func (u *UDPUnderlay) RunEventLoop(ctx context.Context) error {
 			return nil
 		case <-u.idleSessionTicker.C:
 			// Close idle sessions.
-			u.sessionMap.Range(func(k, v any) bool {
-				session := v.(*Session)
+			u.sessionMap.Range(func(id uint32, session *Session) bool {
 				select {
 				case <-session.done:
 					log.Debugf("Found closed %v", session)
@@ -204,7 +217,7 @@ func (u *UDPUnderlay) RunEventLoop(ctx context.Context) error {
 					}
 				default:
 				}
-				if time.Since(session.lastRXTime) > idleSessionTimeout {
+				if time.Since(time.Unix(0, session.lastRXTime.Load())) > idleSessionTimeout {
 					log.Debugf("Found idle %v", session)
 					if err := u.RemoveSession(session); err != nil {
 						log.Debugf("%v RemoveSession() failed: %v", u, err)
@@ -212,6 +225,9 @@ func (u *UDPUnderlay) RunEventLoop(ctx context.Context) error {
 				}
 				return true
 			})
+			if u.isUnresponsive() {
+				return errUDPNoResponse
+			}
 		default:
 		}
 		seg, addr, err := u.readOneSegment()
@@ -221,8 +237,12 @@ func (u *UDPUnderlay) RunEventLoop(ctx context.Context) error {
 			}
 			return fmt.Errorf("readOneSegment() failed: %w", err)
 		}
+		u.segmentsReceived.Add(1)
+		if u.isClient {
+			u.responded.Store(true)
+		}
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v received %v from peer %v", u, seg, addr)
+			udpReceiveLogSampler.Tracef("%v received %v from peer %v", u, seg, addr)
 		}
 		if isSessionProtocol(seg.metadata.Protocol()) {
 			switch seg.metadata.Protocol() {
@@ -238,6 +258,10 @@ func (u *UDPUnderlay) RunEventLoop(ctx context.Context) error {
 				if err := u.onCloseSession(seg); err != nil {
 					return fmt.Errorf("onCloseSession() failed: %w", err)
 				}
+			case serverMessage:
+				u.onServerMessage(seg)
+			case keepaliveRequest, keepaliveResponse:
+				udpIgnoreLogSampler.Debugf("%v ignored %v", u, seg.metadata.Protocol())
 			default:
 				panic(fmt.Sprintf("Protocol %d is a session protocol but not recognized by UDP underlay", seg.metadata.Protocol()))
 			}
@@ -267,9 +291,9 @@ func (u *UDPUnderlay) RunEventLoop(ctx context.Context) error {
 				}
 				continue
 			}
-			session.(*Session).recvChan <- seg
+			session.recvChan <- seg
 		} else {
-			log.Debugf("Ignore unknown protocol %d", seg.metadata.Protocol())
+			unknownProtocolLogSampler.Debugf("Ignore unknown protocol %d", seg.metadata.Protocol())
 		}
 	}
 }
@@ -291,6 +315,7 @@ func (u *UDPUnderlay) onOpenSessionRequest(seg *segment, remoteAddr net.Addr) er
 		return nil
 	}
 	session := NewSession(sessionID, false, u.MTU())
+	session.startTrace(context.Background())
 	session.users = u.users
 	u.AddSession(session, remoteAddr)
 	session.recvChan <- seg
@@ -303,30 +328,41 @@ func (u *UDPUnderlay) onOpenSessionResponse(seg *segment) error {
 		return stderror.ErrInvalidOperation
 	}
 
-	sessionID := seg.metadata.(*sessionStruct).sessionID
+	ss := seg.metadata.(*sessionStruct)
+	u.recordLoadFactor(ss)
+	u.syncClock(ss)
+	sessionID := ss.sessionID
 	session, found := u.sessionMap.Load(sessionID)
 	if !found {
 		return fmt.Errorf("session ID %d is not found", sessionID)
 	}
-	session.(*Session).recvChan <- seg
+	session.acceptProtocolVersion(ss)
+	session.recvChan <- seg
 	return nil
 }
 
 func (u *UDPUnderlay) onCloseSession(seg *segment) error {
 	ss := seg.metadata.(*sessionStruct)
 	sessionID := ss.sessionID
-	session, found := u.sessionMap.Load(sessionID)
+	s, found := u.sessionMap.Load(sessionID)
 	if !found {
 		log.Debugf("%v received close session request or response, but session ID %d is not found", u, sessionID)
 		return nil
 	}
-	s := session.(*Session)
 	s.recvChan <- seg
 	s.wg.Wait()
 	u.RemoveSession(s)
 	return nil
 }
 
+func (u *UDPUnderlay) onServerMessage(seg *segment) {
+	if !u.isClient {
+		log.Debugf("%v ignored server message sent by client", u)
+		return
+	}
+	recordServerMessage(string(seg.payload))
+}
+
 func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 	var n int
 	var addr *net.UDPAddr
@@ -340,9 +376,7 @@ func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 
 		util.SetReadTimeout(u.conn, readOneSegmentTimeout)
 		defer util.SetReadTimeout(u.conn, 0)
-		// Peer may select a different MTU.
-		// Use the largest possible value here to avoid error.
-		b := make([]byte, 1500)
+		b := make([]byte, udpMaxPacketSize)
 		n, addr, err = u.conn.ReadFromUDP(b)
 		if err != nil {
 			if stderror.IsTimeout(err) {
@@ -353,25 +387,32 @@ func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 		if u.isClient && addr.String() != u.serverAddr.String() {
 			UnderlayUnsolicitedUDP.Add(1)
 			if log.IsLevelEnabled(log.TraceLevel) {
-				log.Tracef("%v received unsolicited UDP packet from %v", u, addr)
+				udpInvalidPacketLogSampler.Tracef("%v received unsolicited UDP packet from %v", u, addr)
 			}
 			continue
 		}
 		if n < udpNonHeaderPosition {
 			UnderlayMalformedUDP.Add(1)
 			if log.IsLevelEnabled(log.TraceLevel) {
-				log.Tracef("%v received UDP packet from %v with only %d bytes, which is too short", u, addr, n)
+				udpInvalidPacketLogSampler.Tracef("%v received UDP packet from %v with only %d bytes, which is too short", u, addr, n)
 			}
 			continue
 		}
+		if !u.isClient && serverIPBans.isBanned(addr.IP, time.Now()) {
+			UnderlayBannedDrops.Add(1)
+			continue
+		}
 		b = b[:n]
-		metrics.InBytes.Add(int64(n))
+		u.addInBytes(int64(n))
 
 		// Read encrypted metadata.
 		encryptedMeta := b[:udpNonHeaderPosition]
 		if udpReplayCache.IsDuplicate(encryptedMeta[:cipher.DefaultOverhead], addr.String()) {
 			replay.NewSession.Add(1)
 			log.Debugf("found possible replay attack in %v from %v", u, addr)
+			emitReplayDetected("udp", addr)
+			// Replays are not counted toward IP bans, because anyone
+			// can replay a captured packet with any source address.
 			continue
 		}
 		nonce := encryptedMeta[:cipher.DefaultNonceSize]
@@ -385,7 +426,7 @@ func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 			if err != nil {
 				cipher.ClientFailedDirectDecrypt.Add(1)
 				if log.IsLevelEnabled(log.TraceLevel) {
-					log.Tracef("%v Decrypt() failed with UDP packet from %v", u, addr)
+					udpInvalidPacketLogSampler.Tracef("%v Decrypt() failed with UDP packet from %v", u, addr)
 				}
 				continue
 			}
@@ -394,8 +435,7 @@ func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 			var err error
 			// Try existing sessions.
 			cipher.ServerIterateDecrypt.Add(1)
-			u.sessionMap.Range(func(k, v any) bool {
-				session := v.(*Session)
+			u.sessionMap.Range(func(id uint32, session *Session) bool {
 				if session.block != nil && session.RemoteAddr().String() == addr.String() {
 					decryptedMeta, err = session.block.Decrypt(encryptedMeta)
 					if err == nil {
@@ -408,11 +448,12 @@ func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 			})
 			if !decrypted {
 				// This is a new session. Try all registered users.
-				for _, user := range u.users {
+				// Try the users that are more likely to connect first.
+				for _, user := range serverCipherHits.rankUsers(localPort(u.LocalAddr()), u.users, time.Now()) {
 					var password []byte
 					password, err = hex.DecodeString(user.GetHashedPassword())
 					if err != nil {
-						log.Debugf("Unable to decode hashed password %q from user %q", user.GetHashedPassword(), user.GetName())
+						log.Debugf("Unable to decode hashed password of user %q", user.GetName())
 						continue
 					}
 					if len(password) == 0 {
@@ -424,14 +465,17 @@ func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 						blockCipher.SetBlockContext(cipher.BlockContext{
 							UserName: user.GetName(),
 						})
+						serverCipherHits.recordHit(localPort(u.LocalAddr()), user.GetName(), time.Now())
 						break
 					}
 				}
 			}
 			if !decrypted {
 				cipher.ServerFailedIterateDecrypt.Add(1)
+				// The source address of a UDP packet can be spoofed, so
+				// the failure is not counted toward IP bans.
 				if log.IsLevelEnabled(log.TraceLevel) {
-					log.Tracef("%v TryDecrypt() failed with UDP packet from %v", u, addr)
+					udpInvalidPacketLogSampler.Tracef("%v TryDecrypt() failed with UDP packet from %v", u, addr)
 				}
 				continue
 			} else {
@@ -462,7 +506,7 @@ func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 				if u.isClient {
 					return nil, nil, err
 				} else {
-					log.Debugf("%v readSessionSegment() failed: %v", u, err)
+					udpReadSegmentLogSampler.Debugf("%v readSessionSegment() failed: %v", u, err)
 					continue
 				}
 			}
@@ -485,7 +529,7 @@ func (u *UDPUnderlay) readOneSegment() (*segment, *net.UDPAddr, error) {
 				if u.isClient {
 					return nil, nil, err
 				} else {
-					log.Debugf("%v readDataAckSegment() failed: %v", u, err)
+					udpReadSegmentLogSampler.Debugf("%v readDataAckSegment() failed: %v", u, err)
 					continue
 				}
 			}
@@ -597,42 +641,93 @@ func (u *UDPUnderlay) writeOneSegment(seg *segment, addr *net.UDPAddr) error {
 	if seg == nil {
 		return stderror.ErrNullPointer
 	}
+	return u.writeSegments([]*segment{seg}, addr)
+}
+
+// writeSegments encrypts the segments and writes them to the address.
+// If it is supported, the UDP packets are written in batches.
+func (u *UDPUnderlay) writeSegments(segs []*segment, addr *net.UDPAddr) error {
 	if u.isClient && addr.String() != u.serverAddr.String() {
 		return fmt.Errorf("can't write to %v, UDP server address is %v", addr, u.serverAddr)
 	}
+	if u.isClient {
+		u.firstSendTime.CompareAndSwap(0, time.Now().UnixNano())
+	}
 
-	u.sendMutex.Lock()
+	priority := PriorityNormal
+	if len(segs) > 0 {
+		priority = segs[0].priority
+	}
+	u.sendMutex.Lock(priority)
 	defer u.sendMutex.Unlock()
+	waitTimingJitter()
 
-	var blockCipher cipher.BlockCipher
-	if u.isClient {
-		if u.block == nil {
-			panic(fmt.Sprintf("%v cipher block is not ready", u))
-		} else {
-			blockCipher = u.block
+	packets := make([][]byte, 0, len(segs))
+	paddingLen := 0
+	for _, seg := range segs {
+		if seg == nil {
+			return stderror.ErrNullPointer
+		}
+		blockCipher, err := u.segmentCipher(seg)
+		if err != nil {
+			return err
+		}
+		packet, n, err := u.encodeSegment(seg, blockCipher)
+		if err != nil {
+			return err
+		}
+		packets = append(packets, packet)
+		paddingLen += n
+	}
+
+	if w, ok := u.conn.(udpBatchWriter); ok && len(packets) > 1 {
+		if _, err := w.WriteBatchToUDP(packets, addr); err != nil {
+			return fmt.Errorf("WriteBatchToUDP() failed: %w", err)
 		}
 	} else {
-		if seg.block != nil {
-			blockCipher = seg.block
-		} else {
-			sessionID, err := seg.SessionID()
-			if err != nil {
-				return fmt.Errorf("%v SessionID() failed: %v", seg, err)
-			}
-			session, ok := u.sessionMap.Load(sessionID)
-			if !ok {
-				return fmt.Errorf("session %d not found", sessionID)
-			}
-			s := session.(*Session)
-			if s.block == nil {
-				// stderror.ErrNotReady is needed to trigger stderror.ShouldRetry.
-				return fmt.Errorf("%v cipher block is not ready, please try again later: %w", s, stderror.ErrNotReady)
-			} else {
-				blockCipher = s.block
+		for _, packet := range packets {
+			if _, err := u.conn.WriteToUDP(packet, addr); err != nil {
+				return fmt.Errorf("WriteToUDP() failed: %w", err)
 			}
 		}
 	}
+	for _, packet := range packets {
+		u.addOutBytes(int64(len(packet)))
+	}
+	u.segmentsSent.Add(int64(len(packets)))
+	metrics.OutPaddingBytes.Add(int64(paddingLen))
+	return nil
+}
 
+// segmentCipher returns the block cipher to encrypt the segment.
+func (u *UDPUnderlay) segmentCipher(seg *segment) (cipher.BlockCipher, error) {
+	if u.isClient {
+		if u.block == nil {
+			panic(fmt.Sprintf("%v cipher block is not ready", u))
+		}
+		return u.block, nil
+	}
+	if seg.block != nil {
+		return seg.block, nil
+	}
+	sessionID, err := seg.SessionID()
+	if err != nil {
+		return nil, fmt.Errorf("%v SessionID() failed: %v", seg, err)
+	}
+	s, ok := u.sessionMap.Load(sessionID)
+	if !ok {
+		return nil, fmt.Errorf("session %d not found", sessionID)
+	}
+	if s.block == nil {
+		// stderror.ErrNotReady is needed to trigger stderror.ShouldRetry.
+		return nil, fmt.Errorf("%v cipher block is not ready, please try again later: %w", s, stderror.ErrNotReady)
+	}
+	return s.block, nil
+}
+
+// encodeSegment returns the encrypted UDP packet of the segment,
+// and the number of padding bytes in the packet.
+func (u *UDPUnderlay) encodeSegment(seg *segment, blockCipher cipher.BlockCipher) ([]byte, int, error) {
 	if ss, ok := toSessionStruct(seg.metadata); ok {
 		maxPaddingSize := MaxPaddingSize(u.mtu, u.IPVersion(), u.TransportProtocol(), int(ss.payloadLen), 0)
 		padding := newPadding(paddingOpts{
@@ -641,29 +736,25 @@ func (u *UDPUnderlay) writeOneSegment(seg *segment, addr *net.UDPAddr) error {
 		})
 		ss.suffixLen = uint8(len(padding))
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v is sending %v", u, seg)
+			udpSendLogSampler.Tracef("%v is sending %v", u, seg)
 		}
 
 		plaintextMetadata := seg.metadata.Marshal()
 		encryptedMetadata, err := blockCipher.Encrypt(plaintextMetadata)
 		if err != nil {
-			return fmt.Errorf("Encrypt() failed: %w", err)
+			return nil, 0, fmt.Errorf("Encrypt() failed: %w", err)
 		}
 		nonce := encryptedMetadata[:cipher.DefaultNonceSize]
 		dataToSend := encryptedMetadata
 		if len(seg.payload) > 0 {
 			encryptedPayload, err := blockCipher.EncryptWithNonce(seg.payload, nonce)
 			if err != nil {
-				return fmt.Errorf("EncryptWithNonce() failed: %w", err)
+				return nil, 0, fmt.Errorf("EncryptWithNonce() failed: %w", err)
 			}
 			dataToSend = append(dataToSend, encryptedPayload...)
 		}
 		dataToSend = append(dataToSend, padding...)
-		if _, err := u.conn.WriteToUDP(dataToSend, addr); err != nil {
-			return fmt.Errorf("WriteToUDP() failed: %w", err)
-		}
-		metrics.OutBytes.Add(int64(len(dataToSend)))
-		metrics.OutPaddingBytes.Add(int64(len(padding)))
+		return dataToSend, len(padding), nil
 	} else if das, ok := toDataAckStruct(seg.metadata); ok {
 		padding1 := newPadding(paddingOpts{
 			maxLen: MaxPaddingSize(u.mtu, u.IPVersion(), u.TransportProtocol(), int(das.payloadLen), 0),
@@ -674,32 +765,25 @@ func (u *UDPUnderlay) writeOneSegment(seg *segment, addr *net.UDPAddr) error {
 		das.prefixLen = uint8(len(padding1))
 		das.suffixLen = uint8(len(padding2))
 		if log.IsLevelEnabled(log.TraceLevel) {
-			log.Tracef("%v is sending %v", u, seg)
+			udpSendLogSampler.Tracef("%v is sending %v", u, seg)
 		}
 
 		plaintextMetadata := seg.metadata.Marshal()
 		encryptedMetadata, err := blockCipher.Encrypt(plaintextMetadata)
 		if err != nil {
-			return fmt.Errorf("Encrypt() failed: %w", err)
+			return nil, 0, fmt.Errorf("Encrypt() failed: %w", err)
 		}
 		nonce := encryptedMetadata[:cipher.DefaultNonceSize]
 		dataToSend := append(encryptedMetadata, padding1...)
 		if len(seg.payload) > 0 {
 			encryptedPayload, err := blockCipher.EncryptWithNonce(seg.payload, nonce)
 			if err != nil {
-				return fmt.Errorf("EncryptWithNonce() failed: %w", err)
+				return nil, 0, fmt.Errorf("EncryptWithNonce() failed: %w", err)
 			}
 			dataToSend = append(dataToSend, encryptedPayload...)
 		}
 		dataToSend = append(dataToSend, padding2...)
-		if _, err := u.conn.WriteToUDP(dataToSend, addr); err != nil {
-			return fmt.Errorf("WriteToUDP() failed: %w", err)
-		}
-		metrics.OutBytes.Add(int64(len(dataToSend)))
-		metrics.OutPaddingBytes.Add(int64(len(padding1)))
-		metrics.OutPaddingBytes.Add(int64(len(padding2)))
-	} else {
-		return stderror.ErrInvalidArgument
+		return dataToSend, len(padding1) + len(padding2), nil
 	}
-	return nil
+	return nil, 0, stderror.ErrInvalidArgument
 }
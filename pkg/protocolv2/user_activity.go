@@ -0,0 +1,58 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// userHandshakes maps a user name to the time.Time the user
+// last opened a session.
+var userHandshakes sync.Map
+
+// recordUserHandshake records that the user opened a session.
+func recordUserHandshake(userName string, now time.Time) {
+	if userName == "" {
+		return
+	}
+	userHandshakes.Store(userName, now)
+}
+
+// UserLastHandshake returns the last time the user opened a session.
+// It returns false if the user has not opened a session since the
+// server is started.
+func UserLastHandshake(userName string) (time.Time, bool) {
+	v, ok := userHandshakes.Load(userName)
+	if !ok {
+		return time.Time{}, false
+	}
+	return v.(time.Time), true
+}
+
+// ActiveSessionsByUser returns the number of active sessions of each user.
+func ActiveSessionsByUser() map[string]int {
+	serverConnectionLimits.mu.Lock()
+	defer serverConnectionLimits.mu.Unlock()
+	res := make(map[string]int)
+	for key, n := range serverConnectionLimits.sessions {
+		if userName, ok := strings.CutPrefix(key, "user/"); ok {
+			res[userName] = n
+		}
+	}
+	return res
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+const (
+	// protocolVersionInitial is the wire format used by peers that
+	// don't attach the protocol version to the open session request
+	// and open session response.
+	protocolVersionInitial uint8 = 0
+
+	// protocolVersionNegotiation is the first version that negotiates
+	// the protocol version. It has the same wire format as
+	// protocolVersionInitial.
+	protocolVersionNegotiation uint8 = 1
+
+	// currentProtocolVersion is the highest protocol version supported
+	// by this implementation. Increase it when the wire format changes,
+	// and only use the new format in sessions that negotiated the new
+	// version.
+	currentProtocolVersion = protocolVersionNegotiation
+)
+
+// negotiateProtocolVersion returns the protocol version used by a session
+// when the peer supports up to the offered version. A peer with a newer
+// version degrades to the version supported by this implementation.
+func negotiateProtocolVersion(offered uint8) uint8 {
+	if offered < currentProtocolVersion {
+		return offered
+	}
+	return currentProtocolVersion
+}
+
+// ProtocolVersion returns the protocol version negotiated by the session.
+// It is protocolVersionInitial before the session is established, or if
+// the peer doesn't negotiate the protocol version.
+func (s *Session) ProtocolVersion() uint8 {
+	return uint8(s.protocolVersion.Load())
+}
+
+// acceptProtocolVersion sets the protocol version of the session from
+// the open session request or open session response of the peer.
+func (s *Session) acceptProtocolVersion(ss *sessionStruct) {
+	if ss.flags&flagVersion == 0 {
+		s.protocolVersion.Store(uint32(protocolVersionInitial))
+		return
+	}
+	s.protocolVersion.Store(uint32(negotiateProtocolVersion(ss.version)))
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package protocolv2
+
+import (
+	"testing"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	testCases := []struct {
+		offered uint8
+		want    uint8
+	}{
+		{protocolVersionInitial, protocolVersionInitial},
+		{currentProtocolVersion, currentProtocolVersion},
+		{currentProtocolVersion + 1, currentProtocolVersion},
+		{255, currentProtocolVersion},
+	}
+	for _, tc := range testCases {
+		if got := negotiateProtocolVersion(tc.offered); got != tc.want {
+			t.Errorf("negotiateProtocolVersion(%d) = %d, want %d", tc.offered, got, tc.want)
+		}
+	}
+}
+
+func TestAcceptProtocolVersion(t *testing.T) {
+	s := NewSession(1, true, 1500)
+	s.acceptProtocolVersion(&sessionStruct{baseStruct: baseStruct{flags: flagVersion}, version: 255})
+	if got := s.ProtocolVersion(); got != currentProtocolVersion {
+		t.Errorf("ProtocolVersion() = %d, want %d", got, currentProtocolVersion)
+	}
+
+	// A peer that doesn't negotiate uses the initial version,
+	// even if the version byte is not zero.
+	s.acceptProtocolVersion(&sessionStruct{version: 255})
+	if got := s.ProtocolVersion(); got != protocolVersionInitial {
+		t.Errorf("ProtocolVersion() = %d, want %d", got, protocolVersionInitial)
+	}
+}
@@ -16,8 +16,10 @@
 package replay
 
 import (
+	"fmt"
 	"hash/fnv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/enfein/mieru/pkg/metrics"
@@ -25,6 +27,23 @@ import (
 
 const (
 	EmptyTag = ""
+
+	// DefaultCapacity is the default maximum number of entries in a replay cache.
+	DefaultCapacity = 4 * 1024 * 1024
+
+	// DefaultExpireInterval is the default maximum time to keep an entry
+	// in `current` map of a replay cache.
+	DefaultExpireInterval = 2 * time.Minute
+
+	// entryOverheadBytes is the estimated memory used by a map entry,
+	// including the signature, the tag header and the map bucket overhead.
+	entryOverheadBytes = 48
+
+	// maxShardBits limits the number of shards to 2^maxShardBits.
+	maxShardBits = 6
+
+	// minShardCapacity is the minimum number of entries in one shard.
+	minShardCapacity = 1024
 )
 
 var (
@@ -33,15 +52,57 @@ var (
 
 	// Number of replay packets sent from a known session.
 	KnownSession = metrics.RegisterMetric("replay", "KnownSession", metrics.COUNTER)
+
+	// Number of times the lock of a replay cache shard is acquired.
+	LockAcquisitions = metrics.RegisterMetric("replay", "LockAcquisitions", metrics.COUNTER)
+
+	// Number of times the lock of a replay cache shard is held by another goroutine
+	// when it is acquired.
+	LockContentions = metrics.RegisterMetric("replay", "LockContentions", metrics.COUNTER)
+
+	// Number of entries in all replay caches.
+	Entries = metrics.RegisterMetric("replay", "Entries", metrics.GAUGE)
+
+	// Estimated memory used by all replay caches, in bytes.
+	MemoryBytes = metrics.RegisterMetric("replay", "MemoryBytes", metrics.GAUGE)
+
+	// Number of entries removed from replay caches because they are expired
+	// or the capacity is used up.
+	Evictions = metrics.RegisterMetric("replay", "Evictions", metrics.COUNTER)
 )
 
 // ReplayCache stores the signature of recent decrypted packets to avoid
 // a replay attack.
+//
+// The signatures are distributed to multiple shards by the prefix of
+// the signature. Each shard has an independent lock, so concurrent
+// lookups from different connections rarely block each other.
 type ReplayCache struct {
-	// mu is required to access the replay cache.
-	mu sync.Mutex
+	state atomic.Pointer[cacheState]
+}
 
+// cacheState holds the parameters and shards of a replay cache.
+type cacheState struct {
 	// capacity is the maximum number of entries in one replay cache.
+	// A value of 0 disables the replay cache.
+	capacity int
+
+	// expireInterval is the maximum time to keep an entry in `current` map.
+	expireInterval time.Duration
+
+	// shards stores the signatures. The number of shards is a power of 2.
+	shards []*replayShard
+
+	// shardBits is the number of signature prefix bits used to select a shard.
+	shardBits int
+}
+
+// replayShard stores a subset of signatures of the replay cache.
+type replayShard struct {
+	// mu is required to access the shard.
+	mu sync.Mutex
+
+	// capacity is the maximum number of entries in this shard.
 	// If the size of `current` map is bigger than capacity, `current` map
 	// will replace `previous` map.
 	capacity int
@@ -58,23 +119,71 @@ type ReplayCache struct {
 
 	// previous stores the previous set of packet signatures.
 	previous map[uint64]string
+
+	// currentBytes is the estimated memory used by `current` map.
+	currentBytes int64
+
+	// previousBytes is the estimated memory used by `previous` map.
+	previousBytes int64
 }
 
 // NewCache creates a new replay cache.
 func NewCache(capacity int, expireInterval time.Duration) *ReplayCache {
-	if capacity < 0 {
-		panic("replay cache capacity can't be negative")
+	if err := checkParameters(capacity, expireInterval); err != nil {
+		panic(err.Error())
 	}
-	if expireInterval.Nanoseconds() <= 0 {
-		panic("replay cache expire interval must be a positive time range")
+	c := &ReplayCache{}
+	c.state.Store(newCacheState(capacity, expireInterval))
+	return c
+}
+
+// Configure changes the capacity and expire interval of the replay cache.
+// Existing signatures are kept until the new expire interval is reached
+// or the new capacity is used up.
+func (c *ReplayCache) Configure(capacity int, expireInterval time.Duration) error {
+	if err := checkParameters(capacity, expireInterval); err != nil {
+		return err
 	}
-	return &ReplayCache{
-		capacity:       capacity,
-		expireTime:     time.Now().Add(expireInterval),
-		expireInterval: expireInterval,
-		current:        make(map[uint64]string),
-		previous:       make(map[uint64]string),
+	old := c.state.Load()
+	if old.capacity == capacity && old.expireInterval == expireInterval {
+		return nil
+	}
+	state := newCacheState(capacity, expireInterval)
+	for _, shard := range old.shards {
+		shard.lock()
+		defer shard.mu.Unlock()
+	}
+	for _, shard := range old.shards {
+		for _, m := range []map[uint64]string{shard.previous, shard.current} {
+			for signature, tag := range m {
+				dst := state.shardOf(signature)
+				dst.previous[signature] = tag
+				dst.previousBytes += entrySize(tag)
+			}
+		}
+	}
+	// Entries and memory usage are counted again after the move.
+	for _, shard := range old.shards {
+		Entries.Add(-int64(len(shard.current) + len(shard.previous)))
+		MemoryBytes.Add(-(shard.currentBytes + shard.previousBytes))
 	}
+	for _, shard := range state.shards {
+		Entries.Add(int64(len(shard.previous)))
+		MemoryBytes.Add(shard.previousBytes)
+	}
+	c.state.Store(state)
+	return nil
+}
+
+// Capacity returns the maximum number of entries in the replay cache.
+func (c *ReplayCache) Capacity() int {
+	return c.state.Load().capacity
+}
+
+// ExpireInterval returns the maximum time to keep an entry
+// before it is moved out of `current` map.
+func (c *ReplayCache) ExpireInterval() time.Duration {
+	return c.state.Load().expireInterval
 }
 
 // IsDuplicate checks if the given data is present in the replay cache.
@@ -82,29 +191,42 @@ func NewCache(capacity int, expireInterval time.Duration) *ReplayCache {
 // when the same tag is used. This can allow retransmit exact content
 // from the same network address. Use an empty tag disables this feature.
 func (c *ReplayCache) IsDuplicate(data []byte, tag string) bool {
-	if c == nil || c.capacity == 0 {
+	if c == nil {
+		return false
+	}
+	state := c.state.Load()
+	if state.capacity == 0 {
 		// The replay cache is disabled.
 		return false
 	}
-	signature := c.computeSignature(data)
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	signature := computeSignature(data)
+	shard := state.shardOf(signature)
+	shard.lock()
+	defer shard.mu.Unlock()
 
-	if len(c.current) >= c.capacity || time.Now().After(c.expireTime) {
-		c.previous = c.current
-		c.current = make(map[uint64]string)
-		c.expireTime = time.Now().Add(c.expireInterval)
+	if len(shard.current) >= shard.capacity || time.Now().After(shard.expireTime) {
+		Evictions.Add(int64(len(shard.previous)))
+		Entries.Add(-int64(len(shard.previous)))
+		MemoryBytes.Add(-shard.previousBytes)
+		shard.previous = shard.current
+		shard.previousBytes = shard.currentBytes
+		shard.current = make(map[uint64]string)
+		shard.currentBytes = 0
+		shard.expireTime = time.Now().Add(shard.expireInterval)
 	}
 
-	if existingTag, ok := c.current[signature]; ok {
+	if existingTag, ok := shard.current[signature]; ok {
 		if existingTag == EmptyTag || tag == EmptyTag {
 			return true
 		}
 		return existingTag != tag
 	} else {
-		c.current[signature] = tag
+		shard.current[signature] = tag
+		shard.currentBytes += entrySize(tag)
+		Entries.Add(1)
+		MemoryBytes.Add(entrySize(tag))
 	}
-	if existingTag, ok := c.previous[signature]; ok {
+	if existingTag, ok := shard.previous[signature]; ok {
 		if existingTag == EmptyTag || tag == EmptyTag {
 			return true
 		}
@@ -115,21 +237,95 @@ func (c *ReplayCache) IsDuplicate(data []byte, tag string) bool {
 
 // Sizes returns the number of entries in `current` map and `previous` map.
 func (c *ReplayCache) Sizes() (int, int) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return len(c.current), len(c.previous)
+	curr, prev := 0, 0
+	for _, shard := range c.state.Load().shards {
+		shard.lock()
+		curr += len(shard.current)
+		prev += len(shard.previous)
+		shard.mu.Unlock()
+	}
+	return curr, prev
+}
+
+// Shards returns the number of shards in the replay cache.
+func (c *ReplayCache) Shards() int {
+	return len(c.state.Load().shards)
 }
 
 // Clear removes all the data in the replay cache.
 func (c *ReplayCache) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.current = make(map[uint64]string)
-	c.previous = make(map[uint64]string)
+	for _, shard := range c.state.Load().shards {
+		shard.lock()
+		Entries.Add(-int64(len(shard.current) + len(shard.previous)))
+		MemoryBytes.Add(-(shard.currentBytes + shard.previousBytes))
+		shard.current = make(map[uint64]string)
+		shard.previous = make(map[uint64]string)
+		shard.currentBytes = 0
+		shard.previousBytes = 0
+		shard.mu.Unlock()
+	}
 }
 
-func (c *ReplayCache) computeSignature(data []byte) uint64 {
+func newCacheState(capacity int, expireInterval time.Duration) *cacheState {
+	// Small caches use a single shard, so the capacity is exact.
+	shardBits := 0
+	for shardBits < maxShardBits && capacity>>(shardBits+1) >= minShardCapacity {
+		shardBits++
+	}
+	state := &cacheState{
+		capacity:       capacity,
+		expireInterval: expireInterval,
+		shards:         make([]*replayShard, 1<<shardBits),
+		shardBits:      shardBits,
+	}
+	for i := range state.shards {
+		state.shards[i] = &replayShard{
+			capacity:       capacity >> shardBits,
+			expireTime:     time.Now().Add(expireInterval),
+			expireInterval: expireInterval,
+			current:        make(map[uint64]string),
+			previous:       make(map[uint64]string),
+		}
+	}
+	return state
+}
+
+// shardOf returns the shard that stores the signature.
+func (s *cacheState) shardOf(signature uint64) *replayShard {
+	if s.shardBits == 0 {
+		return s.shards[0]
+	}
+	return s.shards[signature>>(64-s.shardBits)]
+}
+
+// lock acquires the shard lock and records contention
+// if the lock is held by another goroutine.
+func (s *replayShard) lock() {
+	LockAcquisitions.Add(1)
+	if s.mu.TryLock() {
+		return
+	}
+	LockContentions.Add(1)
+	s.mu.Lock()
+}
+
+func checkParameters(capacity int, expireInterval time.Duration) error {
+	if capacity < 0 {
+		return fmt.Errorf("replay cache capacity can't be negative")
+	}
+	if expireInterval.Nanoseconds() <= 0 {
+		return fmt.Errorf("replay cache expire interval must be a positive time range")
+	}
+	return nil
+}
+
+func computeSignature(data []byte) uint64 {
 	hash := fnv.New64a()
 	hash.Write(data)
 	return hash.Sum64()
 }
+
+// entrySize returns the estimated memory used by an entry.
+func entrySize(tag string) int64 {
+	return entryOverheadBytes + int64(len(tag))
+}
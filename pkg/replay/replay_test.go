@@ -17,6 +17,7 @@ package replay_test
 
 import (
 	crand "crypto/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -188,3 +189,88 @@ func TestExpireInterval(t *testing.T) {
 		t.Errorf("cache sizes are %d %d, want 1 1.", curr, prev)
 	}
 }
+
+func TestShards(t *testing.T) {
+	if shards := replay.NewCache(10, 1*time.Minute).Shards(); shards != 1 {
+		t.Errorf("Shards() = %d, want 1", shards)
+	}
+	cache := replay.NewCache(4*1024*1024, 1*time.Minute)
+	if shards := cache.Shards(); shards != 64 {
+		t.Errorf("Shards() = %d, want 64", shards)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				data := make([]byte, 32)
+				if _, err := crand.Read(data); err != nil {
+					t.Errorf("rand.Read() failed: %v", err)
+					return
+				}
+				if cache.IsDuplicate(data, replay.EmptyTag) {
+					t.Errorf("IsDuplicate() = true, want false")
+				}
+				if !cache.IsDuplicate(data, replay.EmptyTag) {
+					t.Errorf("IsDuplicate() = false, want true")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if curr, prev := cache.Sizes(); curr != 8000 || prev != 0 {
+		t.Errorf("cache sizes are %d %d, want 8000 0.", curr, prev)
+	}
+	if replay.LockAcquisitions.Load() == 0 {
+		t.Errorf("LockAcquisitions is 0")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	cache := replay.NewCache(10, 1*time.Minute)
+	data := make([]byte, 32)
+	if _, err := crand.Read(data); err != nil {
+		t.Fatalf("rand.Read() failed: %v", err)
+	}
+	entries := replay.Entries.Load()
+	memory := replay.MemoryBytes.Load()
+	if res := cache.IsDuplicate(data, replay.EmptyTag); res == true {
+		t.Errorf("IsDuplicate() = true, want false")
+	}
+	if got := replay.Entries.Load() - entries; got != 1 {
+		t.Errorf("Entries increased by %d, want 1", got)
+	}
+	if got := replay.MemoryBytes.Load() - memory; got <= 0 {
+		t.Errorf("MemoryBytes increased by %d, want a positive value", got)
+	}
+
+	if err := cache.Configure(-1, 1*time.Minute); err == nil {
+		t.Errorf("Configure() with negative capacity succeeded, want error")
+	}
+	if err := cache.Configure(1024*1024, 5*time.Minute); err != nil {
+		t.Fatalf("Configure() failed: %v", err)
+	}
+	if cache.Capacity() != 1024*1024 || cache.ExpireInterval() != 5*time.Minute {
+		t.Errorf("got capacity %d expire interval %v, want %d %v", cache.Capacity(), cache.ExpireInterval(), 1024*1024, 5*time.Minute)
+	}
+	if cache.Shards() <= 1 {
+		t.Errorf("Shards() = %d, want more than 1", cache.Shards())
+	}
+	if res := cache.IsDuplicate(data, replay.EmptyTag); res == false {
+		t.Errorf("IsDuplicate() = false after Configure(), want true")
+	}
+
+	evictions := replay.Evictions.Load()
+	cache.Clear()
+	if curr, prev := cache.Sizes(); curr != 0 || prev != 0 {
+		t.Errorf("cache sizes are %d %d, want 0 0.", curr, prev)
+	}
+	if got := replay.Entries.Load(); got != entries {
+		t.Errorf("Entries = %d after Clear(), want %d", got, entries)
+	}
+	if got := replay.Evictions.Load(); got != evictions {
+		t.Errorf("Evictions changed by Clear()")
+	}
+}
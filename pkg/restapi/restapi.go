@@ -0,0 +1,334 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package restapi exposes the methods of a gRPC service as a REST API
+// with JSON encoding, in the style of grpc-gateway. Method "Foo" is
+// called with "POST /v1/Foo", where the request body is the JSON
+// encoding of the input message, or with "GET /v1/Foo", where the
+// fields of the input message are set from the URL query. The output
+// message is returned in JSON encoding. Server streaming methods return
+// one JSON message per line until the stream ends.
+package restapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// PathPrefix is the URL path prefix of the methods.
+	PathPrefix = "/v1/"
+
+	// maxBodySize is the maximum size of a request body.
+	maxBodySize = 1024 * 1024
+)
+
+// Gateway translates REST API requests to RPC calls of a gRPC service.
+type Gateway struct {
+	conn    grpc.ClientConnInterface
+	service protoreflect.ServiceDescriptor
+	token   string
+}
+
+var _ http.Handler = (*Gateway)(nil)
+
+// NewGateway returns a gateway that calls the methods of the service
+// with the connection. Every request must carry the token in the
+// "Authorization: Bearer <token>" header.
+func NewGateway(conn grpc.ClientConnInterface, service protoreflect.ServiceDescriptor, token string) *Gateway {
+	return &Gateway{
+		conn:    conn,
+		service: service,
+		token:   token,
+	}
+}
+
+// NewServer returns the HTTP server of the gateway.
+func NewServer(listenAddr string, gateway *Gateway) *http.Server {
+	return &http.Server{
+		Addr:              listenAddr,
+		Handler:           gateway,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(g.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, status.Error(codes.Unauthenticated, "RPC token is missing or invalid"))
+		return
+	}
+	if r.URL.Path == strings.TrimSuffix(PathPrefix, "/") || r.URL.Path == PathPrefix {
+		g.serveMethodList(w, r)
+		return
+	}
+	name, ok := strings.CutPrefix(r.URL.Path, PathPrefix)
+	if !ok {
+		writeError(w, status.Errorf(codes.NotFound, "path %q is not found", r.URL.Path))
+		return
+	}
+	method := g.service.Methods().ByName(protoreflect.Name(name))
+	if method == nil {
+		writeError(w, status.Errorf(codes.NotFound, "method %q is not found", name))
+		return
+	}
+	if method.IsStreamingClient() {
+		writeError(w, status.Errorf(codes.Unimplemented, "client streaming method %q is not supported", name))
+		return
+	}
+
+	in := dynamicpb.NewMessage(method.Input())
+	switch r.Method {
+	case http.MethodGet:
+		if err := unmarshalQuery(r, in); err != nil {
+			writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "read request body failed: %v", err))
+			return
+		}
+		if len(strings.TrimSpace(string(body))) > 0 {
+			if err := protojson.Unmarshal(body, in); err != nil {
+				writeError(w, status.Errorf(codes.InvalidArgument, "decode request body failed: %v", err))
+				return
+			}
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method is not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fullMethod := fmt.Sprintf("/%s/%s", g.service.FullName(), method.Name())
+	if method.IsStreamingServer() {
+		g.serveStream(w, r, fullMethod, method, in)
+		return
+	}
+	out := dynamicpb.NewMessage(method.Output())
+	if err := g.conn.Invoke(r.Context(), fullMethod, in, out); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeMessage(w, out)
+}
+
+// serveMethodList returns the methods of the service.
+func (g *Gateway) serveMethodList(w http.ResponseWriter, r *http.Request) {
+	type methodInfo struct {
+		Name            string `json:"name"`
+		Input           string `json:"input"`
+		Output          string `json:"output"`
+		ServerStreaming bool   `json:"serverStreaming,omitempty"`
+	}
+	var methods []methodInfo
+	for i := 0; i < g.service.Methods().Len(); i++ {
+		m := g.service.Methods().Get(i)
+		if m.IsStreamingClient() {
+			continue
+		}
+		methods = append(methods, methodInfo{
+			Name:            string(m.Name()),
+			Input:           string(m.Input().FullName()),
+			Output:          string(m.Output().FullName()),
+			ServerStreaming: m.IsStreamingServer(),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"service": g.service.FullName(),
+		"methods": methods,
+	})
+}
+
+// serveStream writes the messages of a server streaming method,
+// one JSON message per line. If the stream fails after a message is
+// written, the error is written as the last line.
+func (g *Gateway) serveStream(w http.ResponseWriter, r *http.Request, fullMethod string, method protoreflect.MethodDescriptor, in proto.Message) {
+	stream, err := g.conn.NewStream(r.Context(), &grpc.StreamDesc{ServerStreams: true}, fullMethod)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := stream.SendMsg(in); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := stream.CloseSend(); err != nil {
+		writeError(w, err)
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	for i := 0; ; i++ {
+		out := dynamicpb.NewMessage(method.Output())
+		err := stream.RecvMsg(out)
+		if err == io.EOF {
+			if i == 0 {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.WriteHeader(http.StatusOK)
+			}
+			return
+		}
+		if err != nil {
+			if i == 0 {
+				writeError(w, err)
+				return
+			}
+			b, _ := json.Marshal(map[string]errorBody{"error": newErrorBody(err)})
+			w.Write(append(b, '\n'))
+			return
+		}
+		b, err := protojson.Marshal(out)
+		if err != nil {
+			return
+		}
+		if i == 0 {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+		}
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// unmarshalQuery sets the fields of the message from the URL query.
+// Only fields of scalar and enum types are supported. A repeated field
+// is set by repeating the query parameter.
+func unmarshalQuery(r *http.Request, m proto.Message) error {
+	fields := m.ProtoReflect().Descriptor().Fields()
+	obj := make(map[string]any)
+	for key, values := range r.URL.Query() {
+		fd := fields.ByJSONName(key)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(key))
+		}
+		if fd == nil {
+			return fmt.Errorf("field %q is not found", key)
+		}
+		if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind || fd.IsMap() {
+			return fmt.Errorf("field %q can't be set from URL query", key)
+		}
+		// protojson accepts numbers and enums in strings.
+		var converted []any
+		for _, v := range values {
+			if fd.Kind() == protoreflect.BoolKind {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return fmt.Errorf("field %q: %w", key, err)
+				}
+				converted = append(converted, b)
+			} else {
+				converted = append(converted, v)
+			}
+		}
+		if fd.IsList() {
+			obj[fd.JSONName()] = converted
+		} else if len(converted) > 1 {
+			return fmt.Errorf("field %q is set more than once", key)
+		} else {
+			obj[fd.JSONName()] = converted[0]
+		}
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(b, m)
+}
+
+// writeMessage writes the message in JSON format.
+func writeMessage(w http.ResponseWriter, m proto.Message) {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "protojson.Marshal() failed: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// errorBody is the JSON encoding of a gRPC status.
+type errorBody struct {
+	Code    codes.Code `json:"code"`
+	Message string     `json:"message"`
+}
+
+func newErrorBody(err error) errorBody {
+	s := status.Convert(err)
+	return errorBody{Code: s.Code(), Message: s.Message()}
+}
+
+// writeError writes the gRPC status of the error with the matching
+// HTTP status code.
+func writeError(w http.ResponseWriter, err error) {
+	body := newErrorBody(err)
+	b, _ := json.Marshal(body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatusFromCode(body.Code))
+	w.Write(b)
+}
+
+// httpStatusFromCode returns the HTTP status code of the gRPC status code.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
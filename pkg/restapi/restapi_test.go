@@ -0,0 +1,194 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package restapi
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const testToken = "0123456789abcdef"
+
+type testService struct {
+	pb.UnimplementedClientLifecycleServiceServer
+	level pb.LoggingLevel
+	limit int32
+}
+
+func (s *testService) GetStatus(ctx context.Context, req *pb.Empty) (*pb.AppStatusMsg, error) {
+	return &pb.AppStatusMsg{Status: pb.AppStatus_RUNNING.Enum()}, nil
+}
+
+func (s *testService) SetLoggingLevel(ctx context.Context, req *pb.SetLoggingLevelRequest) (*pb.Empty, error) {
+	s.level = req.GetLevel()
+	return &pb.Empty{}, nil
+}
+
+func (s *testService) GetTopDestinations(ctx context.Context, req *pb.GetTopDestinationsRequest) (*pb.TopDestinations, error) {
+	s.limit = req.GetLimit()
+	return &pb.TopDestinations{}, nil
+}
+
+func (s *testService) GetLogs(req *pb.GetLogsRequest, stream pb.ClientLifecycleService_GetLogsServer) error {
+	for _, text := range []string{"first line", "second line"} {
+		if err := stream.Send(&pb.LogLine{Text: proto.String(text)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestGateway(t *testing.T, service *testService) *httptest.Server {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterClientLifecycleServiceServer(grpcServer, service)
+	go grpcServer.Serve(l)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("grpc.Dial() failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	desc := pb.File_lifecycle_proto.Services().ByName("ClientLifecycleService")
+	server := httptest.NewServer(NewGateway(conn, desc, testToken))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func do(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("http.NewRequest() failed: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("io.ReadAll() failed: %v", err)
+	}
+	return resp.StatusCode, string(b)
+}
+
+func TestGatewayToken(t *testing.T) {
+	server := newTestGateway(t, &testService{})
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/GetStatus", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q got status %d, want %d", header, resp.StatusCode, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestGatewayUnary(t *testing.T) {
+	service := &testService{}
+	server := newTestGateway(t, service)
+
+	code, body := do(t, http.MethodGet, server.URL+"/v1/GetStatus", "")
+	if code != http.StatusOK || !strings.Contains(body, `"RUNNING"`) {
+		t.Errorf("GetStatus got %d %s", code, body)
+	}
+
+	code, body = do(t, http.MethodPost, server.URL+"/v1/SetLoggingLevel", `{"level": "DEBUG"}`)
+	if code != http.StatusOK {
+		t.Errorf("SetLoggingLevel got %d %s", code, body)
+	}
+	if service.level != pb.LoggingLevel_DEBUG {
+		t.Errorf("logging level is %v, want %v", service.level, pb.LoggingLevel_DEBUG)
+	}
+
+	code, body = do(t, http.MethodGet, server.URL+"/v1/GetTopDestinations?limit=5", "")
+	if code != http.StatusOK {
+		t.Errorf("GetTopDestinations got %d %s", code, body)
+	}
+	if service.limit != 5 {
+		t.Errorf("limit is %d, want 5", service.limit)
+	}
+
+	testCases := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{http.MethodGet, "/v1/NoSuchMethod", "", http.StatusNotFound},
+		{http.MethodGet, "/v1/GetTopDestinations?noSuchField=1", "", http.StatusBadRequest},
+		{http.MethodPost, "/v1/SetLoggingLevel", `{"level": `, http.StatusBadRequest},
+		{http.MethodDelete, "/v1/GetStatus", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/v1/GetMetrics", "", http.StatusNotImplemented},
+	}
+	for _, tc := range testCases {
+		if code, body := do(t, tc.method, server.URL+tc.path, tc.body); code != tc.want {
+			t.Errorf("%s %s got %d %s, want %d", tc.method, tc.path, code, body, tc.want)
+		}
+	}
+}
+
+func TestGatewayStream(t *testing.T) {
+	server := newTestGateway(t, &testService{})
+	code, body := do(t, http.MethodPost, server.URL+"/v1/GetLogs", "")
+	if code != http.StatusOK {
+		t.Fatalf("GetLogs got %d %s", code, body)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 || !strings.Contains(lines[1], "second line") {
+		t.Errorf("GetLogs got lines %v", lines)
+	}
+}
+
+func TestGatewayMethodList(t *testing.T) {
+	server := newTestGateway(t, &testService{})
+	code, body := do(t, http.MethodGet, server.URL+"/v1", "")
+	if code != http.StatusOK {
+		t.Fatalf("method list got %d %s", code, body)
+	}
+	for _, want := range []string{`"GetStatus"`, `"GetLogs"`, `"serverStreaming":true`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("method list %s doesn't contain %s", body, want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/util/sockopts"
+)
+
+// ApplicationRules decide the egress action of connections by the local
+// process that creates them.
+type ApplicationRules struct {
+	rules        []applicationRule
+	lookupCgroup bool // true if any rule matches cgroup path
+}
+
+type applicationRule struct {
+	uid        int // -1 to match all users
+	cgroupPath string
+	action     appctlpb.EgressAction
+}
+
+// NewApplicationRules parses the application rules from config.
+func NewApplicationRules(rules []*appctlpb.ApplicationRule) (*ApplicationRules, error) {
+	a := &ApplicationRules{}
+	for i, rule := range rules {
+		if rule.Uid == nil && rule.CgroupPath == nil {
+			return nil, fmt.Errorf("application rule %d has neither uid nor cgroup path", i)
+		}
+		if _, ok := appctlpb.EgressAction_name[int32(rule.GetAction())]; !ok {
+			return nil, fmt.Errorf("application rule %d: unknown action %v", i, rule.GetAction())
+		}
+		r := applicationRule{
+			uid:    -1,
+			action: rule.GetAction(),
+		}
+		if rule.Uid != nil {
+			if rule.GetUid() < 0 {
+				return nil, fmt.Errorf("application rule %d: invalid uid %d", i, rule.GetUid())
+			}
+			r.uid = int(rule.GetUid())
+		}
+		if rule.CgroupPath != nil {
+			if !strings.HasPrefix(rule.GetCgroupPath(), "/") {
+				return nil, fmt.Errorf("application rule %d: cgroup path %q is not an absolute path", i, rule.GetCgroupPath())
+			}
+			r.cgroupPath = rule.GetCgroupPath()
+			a.lookupCgroup = true
+		}
+		a.rules = append(a.rules, r)
+	}
+	return a, nil
+}
+
+// Match returns the action of the first rule matched by the owner of
+// the connection. It returns false if no rule is matched.
+func (a *ApplicationRules) Match(owner *sockopts.SocketOwner) (appctlpb.EgressAction, bool) {
+	if a == nil || owner == nil {
+		return appctlpb.EgressAction_PROXY, false
+	}
+	for _, r := range a.rules {
+		if r.match(owner) {
+			return r.action, true
+		}
+	}
+	return appctlpb.EgressAction_PROXY, false
+}
+
+// find returns the action of a TCP connection from the local address
+// to the remote address. It returns false if the connection is not
+// created by a local process or no rule is matched.
+func (a *ApplicationRules) find(local, remote *net.TCPAddr) (appctlpb.EgressAction, bool) {
+	if a == nil || len(a.rules) == 0 {
+		return appctlpb.EgressAction_PROXY, false
+	}
+	owner, err := sockopts.TCPSocketOwner(local, remote, a.lookupCgroup)
+	if err != nil {
+		// Connections from other devices don't have a local owner.
+		if log.IsLevelEnabled(log.TraceLevel) {
+			log.Tracef("unable to find the owner of connection from %v to %v: %v", local, remote, err)
+		}
+		return appctlpb.EgressAction_PROXY, false
+	}
+	action, ok := a.Match(owner)
+	if ok {
+		log.Debugf("connection from %v to %v created by uid %d in cgroup %q matches application rule with action %s", local, remote, owner.UID, owner.CgroupPath, action.String())
+	}
+	return action, ok
+}
+
+func (r applicationRule) match(owner *sockopts.SocketOwner) bool {
+	if r.uid >= 0 && owner.UID != r.uid {
+		return false
+	}
+	if r.cgroupPath != "" {
+		path := strings.TrimSuffix(r.cgroupPath, "/")
+		if owner.CgroupPath != path && !strings.HasPrefix(owner.CgroupPath, path+"/") {
+			return false
+		}
+	}
+	return true
+}
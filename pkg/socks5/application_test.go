@@ -0,0 +1,130 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"context"
+	"net"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/util/sockopts"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestApplicationRules(t *testing.T) {
+	rules, err := NewApplicationRules([]*appctlpb.ApplicationRule{
+		{
+			Uid:        proto.Int32(1000),
+			CgroupPath: proto.String("/user.slice/user-1000.slice/app.slice/steam.scope"),
+			Action:     appctlpb.EgressAction_DIRECT.Enum(),
+		},
+		{
+			CgroupPath: proto.String("/system.slice/"),
+			Action:     appctlpb.EgressAction_REJECT.Enum(),
+		},
+		{
+			Uid:    proto.Int32(0),
+			Action: appctlpb.EgressAction_PROXY.Enum(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewApplicationRules() failed: %v", err)
+	}
+	testCases := []struct {
+		owner  *sockopts.SocketOwner
+		want   appctlpb.EgressAction
+		wantOK bool
+	}{
+		{&sockopts.SocketOwner{UID: 1000, CgroupPath: "/user.slice/user-1000.slice/app.slice/steam.scope"}, appctlpb.EgressAction_DIRECT, true},
+		{&sockopts.SocketOwner{UID: 1001, CgroupPath: "/user.slice/user-1000.slice/app.slice/steam.scope"}, appctlpb.EgressAction_PROXY, false},
+		{&sockopts.SocketOwner{UID: 1000, CgroupPath: "/user.slice/user-1000.slice/app.slice/steam.scope.d"}, appctlpb.EgressAction_PROXY, false},
+		{&sockopts.SocketOwner{UID: 1000, CgroupPath: "/system.slice/sshd.service"}, appctlpb.EgressAction_REJECT, true},
+		{&sockopts.SocketOwner{UID: 0, CgroupPath: "/system.slice"}, appctlpb.EgressAction_REJECT, true},
+		{&sockopts.SocketOwner{UID: 0, CgroupPath: "/init.scope"}, appctlpb.EgressAction_PROXY, true},
+		{&sockopts.SocketOwner{UID: 1000}, appctlpb.EgressAction_PROXY, false},
+		{nil, appctlpb.EgressAction_PROXY, false},
+	}
+	for _, tc := range testCases {
+		got, ok := rules.Match(tc.owner)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("Match(%v) = %v, %v, want %v, %v", tc.owner, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestApplicationRulesInvalid(t *testing.T) {
+	testCases := []*appctlpb.ApplicationRule{
+		{Action: appctlpb.EgressAction_DIRECT.Enum()},
+		{Uid: proto.Int32(-1)},
+		{CgroupPath: proto.String("user.slice")},
+		{Uid: proto.Int32(1000), Action: appctlpb.EgressAction(100).Enum()},
+	}
+	for _, tc := range testCases {
+		if _, err := NewApplicationRules([]*appctlpb.ApplicationRule{tc}); err == nil {
+			t.Errorf("NewApplicationRules(%v) succeeded, want error", tc)
+		}
+	}
+}
+
+func TestDialTransparentApplicationRules(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skipf("socket owner lookup is not supported on %s", runtime.GOOS)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer l.Close()
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer client.Close()
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Accept() failed: %v", err)
+	}
+	defer conn.Close()
+	dst := conn.LocalAddr().(*net.TCPAddr)
+
+	for _, action := range []appctlpb.EgressAction{appctlpb.EgressAction_DIRECT, appctlpb.EgressAction_REJECT} {
+		rules, err := NewApplicationRules([]*appctlpb.ApplicationRule{
+			{
+				Uid:    proto.Int32(int32(os.Getuid())),
+				Action: action.Enum(),
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewApplicationRules() failed: %v", err)
+		}
+		s := &Server{config: &Config{ApplicationRules: rules}}
+		directConn, err := s.dialTransparent(context.Background(), conn, dst)
+		if action == appctlpb.EgressAction_REJECT {
+			if err == nil {
+				directConn.Close()
+				t.Errorf("dialTransparent() succeeded, want rejected")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("dialTransparent() failed: %v", err)
+		}
+		directConn.Close()
+	}
+}
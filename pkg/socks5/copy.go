@@ -16,14 +16,27 @@
 package socks5
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net"
 	"sync/atomic"
 
 	"github.com/enfein/mieru/pkg/log"
 	"github.com/enfein/mieru/pkg/stderror"
+	"github.com/enfein/mieru/pkg/tracing"
+	"github.com/enfein/mieru/pkg/util"
 )
 
+// relay does bi-directional data copy, and records the time in a span.
+func relay(ctx context.Context, conn1, conn2 io.ReadWriteCloser) error {
+	_, span := tracing.Start(ctx, "relay")
+	err := util.BidiCopy(conn1, conn2)
+	span.RecordError(err)
+	span.End()
+	return err
+}
+
 // BidiCopyUDP does bi-directional data copy between a proxy client UDP endpoint
 // and the proxy tunnel.
 func BidiCopyUDP(udpConn *net.UDPConn, tunnelConn *UDPAssociateTunnelConn) error {
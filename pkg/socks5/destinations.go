@@ -0,0 +1,181 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxDestinations is the maximum number of destinations tracked
+// by DestinationStats. When it is reached, the destination with the
+// least traffic is removed to make room for a new one.
+const maxDestinations = 1024
+
+// DestinationStat is the traffic of one destination.
+type DestinationStat struct {
+	// Destination is a domain name or an IP address, without port.
+	Destination string
+
+	// Number of proxy connections to the destination.
+	Connections int64
+
+	// Number of bytes sent to and received from the destination.
+	BytesSent     int64
+	BytesReceived int64
+
+	// LastSeen is the last time a connection to the destination is opened.
+	LastSeen time.Time
+}
+
+// destinationEntry holds the counters of one destination.
+// The byte counters are updated without holding the lock of
+// DestinationStats.
+type destinationEntry struct {
+	destination   string
+	connections   atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+	lastSeen      atomic.Int64 // unix nanoseconds
+}
+
+func (e *destinationEntry) totalBytes() int64 {
+	return e.bytesSent.Load() + e.bytesReceived.Load()
+}
+
+func (e *destinationEntry) stat() DestinationStat {
+	return DestinationStat{
+		Destination:   e.destination,
+		Connections:   e.connections.Load(),
+		BytesSent:     e.bytesSent.Load(),
+		BytesReceived: e.bytesReceived.Load(),
+		LastSeen:      time.Unix(0, e.lastSeen.Load()),
+	}
+}
+
+// DestinationStats aggregates the traffic of proxy connections by
+// destination. The data is only kept in memory.
+type DestinationStats struct {
+	entries map[string]*destinationEntry
+	mu      sync.Mutex
+}
+
+// NewDestinationStats creates an empty DestinationStats.
+func NewDestinationStats() *DestinationStats {
+	return &DestinationStats{
+		entries: make(map[string]*destinationEntry),
+	}
+}
+
+// Top returns at most n destinations with the most traffic.
+// If n is not positive, all the destinations are returned.
+func (d *DestinationStats) Top(n int) []DestinationStat {
+	d.mu.Lock()
+	res := make([]DestinationStat, 0, len(d.entries))
+	for _, e := range d.entries {
+		res = append(res, e.stat())
+	}
+	d.mu.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		ti := res[i].BytesSent + res[i].BytesReceived
+		tj := res[j].BytesSent + res[j].BytesReceived
+		if ti != tj {
+			return ti > tj
+		}
+		return res[i].Destination < res[j].Destination
+	})
+	if n > 0 && len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// Reset removes all the destinations.
+func (d *DestinationStats) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = make(map[string]*destinationEntry)
+}
+
+// open records a new connection to the destination.
+func (d *DestinationStats) open(destination string) *destinationEntry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, found := d.entries[destination]
+	if !found {
+		if len(d.entries) >= maxDestinations {
+			d.evictLocked()
+		}
+		e = &destinationEntry{destination: destination}
+		d.entries[destination] = e
+	}
+	e.connections.Add(1)
+	e.lastSeen.Store(time.Now().UnixNano())
+	return e
+}
+
+// evictLocked removes the destination with the least traffic.
+func (d *DestinationStats) evictLocked() {
+	var victim *destinationEntry
+	for _, e := range d.entries {
+		if victim == nil || e.totalBytes() < victim.totalBytes() {
+			victim = e
+		}
+	}
+	if victim != nil {
+		delete(d.entries, victim.destination)
+	}
+}
+
+// wrap returns a connection that counts the traffic to the destination
+// of the socks5 request. Bytes written to the returned connection are sent
+// to the destination.
+func (d *DestinationStats) wrap(conn io.ReadWriteCloser, addr *AddrSpec) io.ReadWriteCloser {
+	return &destinationConn{
+		ReadWriteCloser: conn,
+		entry:           d.open(destinationName(addr)),
+	}
+}
+
+// destinationName returns the domain name or IP address of the destination.
+func destinationName(addr *AddrSpec) string {
+	if addr.FQDN != "" {
+		return addr.FQDN
+	}
+	return addr.IP.String()
+}
+
+// destinationConn counts the bytes read from and written to the proxy
+// connection of a destination.
+type destinationConn struct {
+	io.ReadWriteCloser
+	entry *destinationEntry
+}
+
+func (c *destinationConn) Read(b []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(b)
+	c.entry.bytesReceived.Add(int64(n))
+	return n, err
+}
+
+func (c *destinationConn) Write(b []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(b)
+	c.entry.bytesSent.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"net"
+	"testing"
+
+	"github.com/enfein/mieru/pkg/testtool"
+)
+
+func TestDestinationStats(t *testing.T) {
+	stats := NewDestinationStats()
+	c1, c2 := testtool.BufPipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	conn := stats.wrap(c1, &AddrSpec{FQDN: "example.com", Port: 443})
+	if _, err := conn.Write(make([]byte, 100)); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := c2.Write(make([]byte, 30)); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := conn.Read(make([]byte, 30)); err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	stats.wrap(c1, &AddrSpec{FQDN: "example.com", Port: 80})
+	small := stats.wrap(c1, &AddrSpec{IP: net.ParseIP("1.2.3.4"), Port: 53})
+	small.Write(make([]byte, 10))
+
+	top := stats.Top(0)
+	if len(top) != 2 {
+		t.Fatalf("got %d destinations, want 2", len(top))
+	}
+	if top[0].Destination != "example.com" || top[0].Connections != 2 || top[0].BytesSent != 100 || top[0].BytesReceived != 30 {
+		t.Errorf("top[0] = %+v, want example.com with 2 connections, 100 bytes sent and 30 bytes received", top[0])
+	}
+	if top[1].Destination != "1.2.3.4" {
+		t.Errorf("top[1] = %+v, want 1.2.3.4", top[1])
+	}
+	if got := stats.Top(1); len(got) != 1 {
+		t.Errorf("Top(1) returned %d destinations", len(got))
+	}
+
+	stats.Reset()
+	if got := stats.Top(0); len(got) != 0 {
+		t.Errorf("got %d destinations after Reset(), want 0", len(got))
+	}
+}
+
+func TestDestinationStatsEviction(t *testing.T) {
+	stats := NewDestinationStats()
+	big := stats.open("big.example.com")
+	big.bytesSent.Add(1000)
+	for i := 0; i < maxDestinations+10; i++ {
+		stats.open(net.IPv4(10, 0, byte(i/256), byte(i%256)).String())
+	}
+	top := stats.Top(0)
+	if len(top) != maxDestinations {
+		t.Errorf("got %d destinations, want %d", len(top), maxDestinations)
+	}
+	if top[0].Destination != "big.example.com" {
+		t.Errorf("destination with the most traffic is evicted")
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/egress"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/tracing"
+)
+
+// DialContext connects to the address through the proxy, as if a socks5
+// CONNECT request is received by the server. Egress rules are applied to
+// the request. It skips the local socks5 handshake, so other proxy
+// protocols served by mieru client can forward traffic without
+// an extra hop to the socks5 port. Only TCP network is supported.
+func (s *Server) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("network %s is not supported", network)
+	}
+	if !s.config.UseProxy || !s.config.ClientSideAuthentication {
+		return nil, fmt.Errorf("DialContext() is only supported by socks5 client with client side authentication")
+	}
+	req, err := newConnectRequest(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply egress rules at client side.
+	if s.config.EgressController != nil {
+		action := s.config.EgressController.FindAction(egress.Input{
+			Protocol: appctlpb.ProxyProtocol_SOCKS5_PROXY_PROTOCOL,
+			Data:     req.Raw,
+		})
+		log.Debugf("Client egress decision of dial request %v is %s", req.Raw, action.Action.String())
+		switch action.Action {
+		case appctlpb.EgressAction_DIRECT:
+			if !s.config.RemoteDNSResolution || req.DestAddr.FQDN == "" {
+				return s.dialDirect(ctx, network, req)
+			}
+		case appctlpb.EgressAction_REJECT:
+			return nil, fmt.Errorf("connection to %s is rejected by egress rules", address)
+		}
+	}
+
+	return s.dialProxy(ctx, req, address)
+}
+
+// dialProxy connects to the destination of the request through the proxy.
+func (s *Server) dialProxy(ctx context.Context, req *Request, address string) (net.Conn, error) {
+	proxyConn, err := s.config.ProxyMux.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mux DialContext() failed: %w", err)
+	}
+	if err := s.connectProxyConn(proxyConn, req, address); err != nil {
+		proxyConn.Close()
+		return nil, err
+	}
+	return proxyConn, nil
+}
+
+// ConnectProxyConn asks the proxy server to connect to the address through
+// an established proxy connection, and waits for the reply. Egress rules
+// are not applied. The proxy connection is not closed if it fails.
+func (s *Server) ConnectProxyConn(proxyConn net.Conn, address string) error {
+	req, err := newConnectRequest(address)
+	if err != nil {
+		return err
+	}
+	return s.connectProxyConn(proxyConn, req, address)
+}
+
+func (s *Server) connectProxyConn(proxyConn net.Conn, req *Request, address string) error {
+	connResp, err := s.exchangeConnReq(proxyConn, req.Raw)
+	if err != nil {
+		HandshakeErrors.Add(1)
+		return err
+	}
+	if connResp[1] != successReply {
+		return fmt.Errorf("proxy server failed to connect to %s with reply code %d", address, connResp[1])
+	}
+	return nil
+}
+
+// dialDirect connects to the destination without proxy.
+func (s *Server) dialDirect(ctx context.Context, network string, req *Request) (net.Conn, error) {
+	dest := req.DestAddr
+	if dest.FQDN != "" {
+		addr, err := s.lookupIP(ctx, dest.FQDN)
+		if err != nil {
+			DNSResolveErrors.Add(1)
+			return nil, fmt.Errorf("failed to resolve destination %q: %w", dest.FQDN, err)
+		}
+		dest.IP = addr
+	}
+	if !s.config.AllowLocalDestination && isLocalhostDest(req) {
+		return nil, fmt.Errorf("access to localhost resource via proxy is not allowed")
+	}
+	return s.dial(ctx, network, dest.Address())
+}
+
+// lookupIP resolves the domain name, and records the time in a span.
+func (s *Server) lookupIP(ctx context.Context, host string) (net.IP, error) {
+	ctx, span := tracing.Start(ctx, "dns.resolve", tracing.String("dns.host", host))
+	addr, err := s.config.Resolver.LookupIP(ctx, host)
+	span.RecordError(err)
+	span.End()
+	return addr, err
+}
+
+// dial connects to the address, and records the time in a span.
+func (s *Server) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	ctx, span := tracing.Start(ctx, "dial", tracing.String("dial.network", network), tracing.String("dial.address", address))
+	conn, err := s.config.Outbound.dialer(address).DialContext(ctx, network, address)
+	span.RecordError(err)
+	span.End()
+	return conn, err
+}
+
+// newConnectRequest returns a socks5 CONNECT request to the address.
+func newConnectRequest(address string) (*Request, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("net.SplitHostPort() failed: %w", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid port number %q", portStr)
+	}
+	dest := &AddrSpec{Port: port}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			dest.IP = ip4
+			dest.Raw = append([]byte{ipv4Address}, ip4...)
+		} else {
+			dest.IP = ip
+			dest.Raw = append([]byte{ipv6Address}, ip.To16()...)
+		}
+	} else {
+		if len(host) == 0 || len(host) > 255 {
+			return nil, fmt.Errorf("invalid host name %q", host)
+		}
+		dest.FQDN = host
+		dest.Raw = append([]byte{fqdnAddress, byte(len(host))}, []byte(host)...)
+	}
+	dest.Raw = append(dest.Raw, byte(port>>8), byte(port))
+	return &Request{
+		Version:  socks5Version,
+		Command:  connectCommand,
+		DestAddr: dest,
+		Raw:      append([]byte{socks5Version, connectCommand, 0}, dest.Raw...),
+	}, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/metrics"
+	"github.com/enfein/mieru/pkg/stderror"
+)
+
+const (
+	// dnsForwardTimeout is the maximum time to answer a DNS query.
+	dnsForwardTimeout = 10 * time.Second
+
+	// maxDNSMessageSize is the maximum size of a DNS message over TCP.
+	maxDNSMessageSize = 65535
+)
+
+var (
+	DNSForwardQueries = metrics.RegisterMetric("dns forward", "Queries", metrics.COUNTER)
+	DNSForwardErrors  = metrics.RegisterMetric("dns forward", "Errors", metrics.COUNTER)
+)
+
+// ServeDNS answers plain DNS queries received from the UDP connection and
+// the TCP listener. Each query is sent to the upstream DNS server through
+// the proxy with DNS over TCP. Egress rules are not applied, so the queries
+// never leave the host without the proxy. It returns when the server is
+// closed, or either of the UDP connection and the TCP listener fails.
+func (s *Server) ServeDNS(pc net.PacketConn, l net.Listener, upstream string) error {
+	if !s.config.UseProxy || s.config.ProxyMux == nil {
+		return fmt.Errorf("ServeDNS() is only supported by socks5 client")
+	}
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- s.serveDNSOverUDP(pc, upstream)
+	}()
+	go func() {
+		errCh <- s.serveDNSOverTCP(l, upstream)
+	}()
+	var err error
+	select {
+	case <-s.die:
+	case err = <-errCh:
+	}
+	pc.Close()
+	l.Close()
+	select {
+	case <-s.die:
+		return nil
+	default:
+		return err
+	}
+}
+
+func (s *Server) serveDNSOverUDP(pc net.PacketConn, upstream string) error {
+	buf := make([]byte, maxDNSMessageSize)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		query := make([]byte, n)
+		copy(query, buf[:n])
+		go func() {
+			resp, err := s.forwardDNS(query, upstream)
+			if err != nil {
+				DNSForwardErrors.Add(1)
+				log.Debugf("forward DNS query from %v failed: %v", addr, err)
+				return
+			}
+			pc.WriteTo(resp, addr)
+		}()
+	}
+}
+
+func (s *Server) serveDNSOverTCP(l net.Listener, upstream string) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			for {
+				conn.SetReadDeadline(time.Now().Add(dnsForwardTimeout))
+				query, err := readDNSMessage(conn)
+				if err != nil {
+					if !stderror.IsEOF(err) && !stderror.IsClosed(err) {
+						log.Debugf("read DNS query from %v failed: %v", conn.RemoteAddr(), err)
+					}
+					return
+				}
+				resp, err := s.forwardDNS(query, upstream)
+				if err != nil {
+					DNSForwardErrors.Add(1)
+					log.Debugf("forward DNS query from %v failed: %v", conn.RemoteAddr(), err)
+					return
+				}
+				if err := writeDNSMessage(conn, resp); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// forwardDNS sends the DNS query to the upstream DNS server through
+// the proxy, and returns the response.
+func (s *Server) forwardDNS(query []byte, upstream string) ([]byte, error) {
+	DNSForwardQueries.Add(1)
+	ctx, cancel := context.WithTimeout(context.Background(), dnsForwardTimeout)
+	defer cancel()
+	proxyConn, err := s.config.ProxyMux.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mux DialContext() failed: %w", err)
+	}
+	defer proxyConn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		proxyConn.SetDeadline(deadline)
+	}
+	if err := s.ConnectProxyConn(proxyConn, upstream); err != nil {
+		return nil, err
+	}
+	if err := writeDNSMessage(proxyConn, query); err != nil {
+		return nil, fmt.Errorf("failed to send DNS query: %w", err)
+	}
+	resp, err := readDNSMessage(proxyConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive DNS response: %w", err)
+	}
+	return resp, nil
+}
+
+// readDNSMessage reads a DNS message with the 2 bytes length prefix
+// defined by DNS over TCP.
+func readDNSMessage(r io.Reader) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// writeDNSMessage writes a DNS message with the 2 bytes length prefix
+// defined by DNS over TCP.
+func writeDNSMessage(w io.Writer, msg []byte) error {
+	if len(msg) > maxDNSMessageSize {
+		return fmt.Errorf("DNS message size %d is too big", len(msg))
+	}
+	b := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(b, uint16(len(msg)))
+	copy(b[2:], msg)
+	_, err := w.Write(b)
+	return err
+}
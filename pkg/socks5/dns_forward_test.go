@@ -0,0 +1,188 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/util"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDNSMessageRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	msg := []byte("dns message")
+	if err := writeDNSMessage(&buf, msg); err != nil {
+		t.Fatalf("writeDNSMessage() failed: %v", err)
+	}
+	if buf.Len() != 2+len(msg) {
+		t.Errorf("got %d bytes, want %d", buf.Len(), 2+len(msg))
+	}
+	got, err := readDNSMessage(&buf)
+	if err != nil {
+		t.Fatalf("readDNSMessage() failed: %v", err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("got %q, want %q", got, msg)
+	}
+	if err := writeDNSMessage(&buf, make([]byte, maxDNSMessageSize+1)); err == nil {
+		t.Errorf("writeDNSMessage() with a too big message returned no error")
+	}
+}
+
+func TestServeDNS(t *testing.T) {
+	log.SetOutputToTest(t)
+	port, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	serverMux := protocolv2.NewMux(false).
+		SetServerUsers(map[string]*appctlpb.User{
+			"xiaochitang": {
+				Name:     proto.String("xiaochitang"),
+				Password: proto.String("kuiranbudong"),
+			},
+		}).
+		SetEndpoints([]protocolv2.UnderlayProperties{
+			protocolv2.NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil),
+		})
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer serverMux.Close()
+	time.Sleep(100 * time.Millisecond)
+	proxyServer, err := New(&Config{
+		AllowLocalDestination:    true,
+		ClientSideAuthentication: true,
+		HandshakeTimeout:         10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	go proxyServer.Serve(serverMux)
+	defer proxyServer.Close()
+
+	// The upstream DNS server answers with the query in reverse order.
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				for {
+					query, err := readDNSMessage(conn)
+					if err != nil {
+						return
+					}
+					resp := make([]byte, len(query))
+					for i := range query {
+						resp[i] = query[len(query)-1-i]
+					}
+					writeDNSMessage(conn, resp)
+				}
+			}()
+		}
+	}()
+
+	clientMux := protocolv2.NewMux(true).
+		SetClientPassword(cipher.HashPassword([]byte("kuiranbudong"), []byte("xiaochitang"))).
+		SetEndpoints([]protocolv2.UnderlayProperties{
+			protocolv2.NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, nil, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}),
+		})
+	defer clientMux.Close()
+	client, err := New(&Config{
+		UseProxy:                 true,
+		ClientSideAuthentication: true,
+		ProxyMux:                 clientMux,
+		HandshakeTimeout:         10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.ListenPacket() failed: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- client.ServeDNS(pc, l, upstream.Addr().String())
+	}()
+
+	// Query over UDP.
+	udpConn, err := net.Dial("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer udpConn.Close()
+	if _, err := udpConn.Write([]byte("abc")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	udpConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, 16)
+	n, err := udpConn.Read(b)
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if string(b[:n]) != "cba" {
+		t.Errorf("got UDP response %q, want %q", b[:n], "cba")
+	}
+
+	// Query over TCP.
+	tcpConn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer tcpConn.Close()
+	if err := writeDNSMessage(tcpConn, []byte("xyz")); err != nil {
+		t.Fatalf("writeDNSMessage() failed: %v", err)
+	}
+	tcpConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := readDNSMessage(tcpConn)
+	if err != nil {
+		t.Fatalf("readDNSMessage() failed: %v", err)
+	}
+	if string(resp) != "zyx" {
+		t.Errorf("got TCP response %q, want %q", resp, "zyx")
+	}
+
+	client.Close()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("ServeDNS() failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("ServeDNS() is not returned after Close()")
+	}
+}
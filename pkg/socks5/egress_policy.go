@@ -0,0 +1,67 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+// mieruSession returns the mieru session carried by the connection,
+// or nil if the connection is not a mieru session.
+func mieruSession(conn io.ReadWriteCloser) *protocolv2.Session {
+	for {
+		if session, ok := conn.(*protocolv2.Session); ok {
+			return session
+		}
+		passthrough, ok := conn.(util.PassthroughConn)
+		if !ok {
+			return nil
+		}
+		conn = passthrough.PassthroughConn()
+	}
+}
+
+// sessionUserName returns the name of the user who opened the mieru
+// session carried by the connection. It is empty if the user is unknown.
+func sessionUserName(conn io.ReadWriteCloser) string {
+	if session := mieruSession(conn); session != nil {
+		return session.UserName()
+	}
+	return ""
+}
+
+// checkEgressPolicy returns an error if the egress policy doesn't
+// allow the user to connect to the destination. If the policy has
+// per-user rules, the connection is denied when the user is unknown.
+func (s *Server) checkEgressPolicy(userName string, fqdn string, ip net.IP, port int) error {
+	if s.config.EgressPolicy == nil {
+		return nil
+	}
+	if userName == "" && s.config.EgressPolicy.HasUserRules() {
+		EgressPolicyErrors.Add(1)
+		return fmt.Errorf("user is unknown, egress policy has per-user rules")
+	}
+	if err := s.config.EgressPolicy.Check(userName, fqdn, ip, port); err != nil {
+		EgressPolicyErrors.Add(1)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+)
+
+// localIPRefreshInterval is the interval to refresh IP addresses
+// of local network interfaces.
+const localIPRefreshInterval = time.Minute
+
+var (
+	localIPMu      sync.Mutex
+	localIPs       []net.IP
+	localIPRefresh time.Time
+)
+
+// isLocalInterfaceIP returns true if the IP address belongs to
+// a network interface of this machine.
+func isLocalInterfaceIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return true
+	}
+	localIPMu.Lock()
+	defer localIPMu.Unlock()
+	if time.Since(localIPRefresh) >= localIPRefreshInterval {
+		addrs, err := net.InterfaceAddrs()
+		if err != nil {
+			log.Debugf("net.InterfaceAddrs() failed: %v", err)
+		} else {
+			localIPs = localIPs[:0]
+			for _, addr := range addrs {
+				if ipNet, ok := addr.(*net.IPNet); ok {
+					localIPs = append(localIPs, ipNet.IP)
+				}
+			}
+			localIPRefresh = time.Now()
+		}
+	}
+	for _, localIP := range localIPs {
+		if localIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIsolatedDest returns true if client isolation is enabled and the
+// destination IP address is the server itself or a proxy client.
+func (s *Server) isIsolatedDest(ip net.IP) bool {
+	if !s.config.ClientIsolation || ip == nil {
+		return false
+	}
+	if isLocalInterfaceIP(ip) {
+		return true
+	}
+	return s.config.IsClientIP != nil && s.config.IsClientIP(ip)
+}
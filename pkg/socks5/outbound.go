@@ -0,0 +1,89 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"context"
+	"net"
+
+	"github.com/enfein/mieru/pkg/util"
+	"github.com/enfein/mieru/pkg/util/sockopts"
+)
+
+// OutboundConfig selects the network interface and the source IP
+// addresses used by proxy server to connect to destinations.
+type OutboundConfig struct {
+	// Name of the network interface. If empty, the interface is selected
+	// by the routing table.
+	Interface string
+
+	// Source IP address to connect to IPv4 destinations.
+	IPv4 net.IP
+
+	// Source IP address to connect to IPv6 destinations.
+	IPv6 net.IP
+}
+
+// dialer returns the dialer to connect to the address.
+func (o *OutboundConfig) dialer(address string) *net.Dialer {
+	d := &net.Dialer{}
+	if o == nil {
+		return d
+	}
+	if o.Interface != "" {
+		d.Control = sockopts.BindToDevice(o.Interface)
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return d
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return d
+	}
+	if ip.To4() != nil && o.IPv4 != nil {
+		d.LocalAddr = &net.TCPAddr{IP: o.IPv4}
+	} else if ip.To4() == nil && o.IPv6 != nil {
+		d.LocalAddr = &net.TCPAddr{IP: o.IPv6}
+	}
+	return d
+}
+
+// listenUDP creates the UDP connection to send packets to destinations.
+// If both IPv4 and IPv6 source addresses are set, the IPv4 address is used,
+// and IPv6 destinations are not reachable.
+func (o *OutboundConfig) listenUDP() (*net.UDPConn, error) {
+	network := "udp"
+	addr := util.MaybeDecorateIPv6(util.AllIPAddr()) + ":0"
+	var lc net.ListenConfig
+	if o != nil {
+		if o.Interface != "" {
+			lc.Control = sockopts.BindToDevice(o.Interface)
+		}
+		if o.IPv4 != nil {
+			network = "udp4"
+			addr = o.IPv4.String() + ":0"
+		} else if o.IPv6 != nil {
+			network = "udp6"
+			addr = "[" + o.IPv6.String() + "]:0"
+		}
+	}
+	conn, err := lc.ListenPacket(context.Background(), network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UDPConn), nil
+}
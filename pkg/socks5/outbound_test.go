@@ -0,0 +1,63 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"net"
+	"testing"
+)
+
+func TestOutboundDialer(t *testing.T) {
+	o := &OutboundConfig{
+		IPv4: net.ParseIP("192.0.2.1"),
+		IPv6: net.ParseIP("2001:db8::1"),
+	}
+	testCases := []struct {
+		address string
+		want    string
+	}{
+		{"198.51.100.1:443", "192.0.2.1:0"},
+		{"[2001:db8::2]:443", "[2001:db8::1]:0"},
+		{"example.com:443", ""},
+	}
+	for _, tc := range testCases {
+		d := o.dialer(tc.address)
+		got := ""
+		if d.LocalAddr != nil {
+			got = d.LocalAddr.String()
+		}
+		if got != tc.want {
+			t.Errorf("local address to connect %s is %q, want %q", tc.address, got, tc.want)
+		}
+	}
+
+	var nilConfig *OutboundConfig
+	if d := nilConfig.dialer("198.51.100.1:443"); d.LocalAddr != nil || d.Control != nil {
+		t.Errorf("dialer of nil config is not the default dialer")
+	}
+}
+
+func TestOutboundListenUDP(t *testing.T) {
+	o := &OutboundConfig{IPv4: net.ParseIP("127.0.0.1")}
+	conn, err := o.listenUDP()
+	if err != nil {
+		t.Fatalf("listenUDP() failed: %v", err)
+	}
+	defer conn.Close()
+	if ip := conn.LocalAddr().(*net.UDPAddr).IP; !ip.Equal(o.IPv4) {
+		t.Errorf("UDP connection is bound to %v, want %v", ip, o.IPv4)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/enfein/mieru/pkg/stderror"
+)
+
+// ListenPacket creates a UDP association through the proxy, as if a socks5
+// UDP ASSOCIATE request is received by the server. The returned PacketConn
+// sends UDP packets to any destination via the proxy server. Egress rules
+// are not applied.
+func (s *Server) ListenPacket(ctx context.Context) (net.PacketConn, error) {
+	if !s.config.UseProxy || !s.config.ClientSideAuthentication {
+		return nil, fmt.Errorf("ListenPacket() is only supported by socks5 client with client side authentication")
+	}
+	proxyConn, err := s.config.ProxyMux.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mux DialContext() failed: %w", err)
+	}
+	req := []byte{socks5Version, associateCommand, 0, ipv4Address, 0, 0, 0, 0, 0, 0}
+	connResp, err := s.exchangeConnReq(proxyConn, req)
+	if err != nil {
+		HandshakeErrors.Add(1)
+		proxyConn.Close()
+		return nil, err
+	}
+	if connResp[1] != successReply {
+		UDPAssociateErrors.Add(1)
+		proxyConn.Close()
+		return nil, fmt.Errorf("proxy server failed to create UDP association with reply code %d", connResp[1])
+	}
+	return &proxyPacketConn{
+		conn:   proxyConn,
+		tunnel: WrapUDPAssociateTunnel(proxyConn),
+	}, nil
+}
+
+// proxyPacketConn sends and receives UDP packets in a UDP association
+// through the proxy tunnel.
+type proxyPacketConn struct {
+	conn   net.Conn
+	tunnel *UDPAssociateTunnelConn
+
+	readMu  sync.Mutex
+	readBuf []byte
+	writeMu sync.Mutex
+}
+
+var _ net.PacketConn = &proxyPacketConn{}
+
+// ReadFrom reads a UDP packet and the address of the sender.
+func (c *proxyPacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	if c.readBuf == nil {
+		c.readBuf = make([]byte, 1<<16)
+	}
+	n, err := c.tunnel.Read(c.readBuf)
+	if err != nil {
+		return 0, nil, err
+	}
+	UDPAssociateInPkts.Add(1)
+	UDPAssociateInBytes.Add(int64(n))
+	buf := c.readBuf[:n]
+	if n <= 6 || buf[0] != 0x00 || buf[1] != 0x00 || buf[2] != 0x00 {
+		UDPAssociateErrors.Add(1)
+		return 0, nil, stderror.ErrInvalidArgument
+	}
+	var addr net.Addr
+	var data []byte
+	switch buf[3] {
+	case ipv4Address:
+		if n <= 10 {
+			return 0, nil, stderror.ErrNoEnoughData
+		}
+		addr = &net.UDPAddr{IP: net.IP(append([]byte{}, buf[4:8]...)), Port: int(buf[8])<<8 | int(buf[9])}
+		data = buf[10:]
+	case ipv6Address:
+		if n <= 22 {
+			return 0, nil, stderror.ErrNoEnoughData
+		}
+		addr = &net.UDPAddr{IP: net.IP(append([]byte{}, buf[4:20]...)), Port: int(buf[20])<<8 | int(buf[21])}
+		data = buf[22:]
+	case fqdnAddress:
+		// The proxy server replies with the domain name in the request.
+		fqdnLen := int(buf[4])
+		if n <= fqdnLen+7 {
+			return 0, nil, stderror.ErrNoEnoughData
+		}
+		addr = &fqdnUDPAddr{
+			host: string(buf[5 : 5+fqdnLen]),
+			port: int(buf[5+fqdnLen])<<8 | int(buf[6+fqdnLen]),
+		}
+		data = buf[7+fqdnLen:]
+	default:
+		UDPAssociateErrors.Add(1)
+		return 0, nil, stderror.ErrInvalidArgument
+	}
+	if len(data) > len(b) {
+		return 0, addr, fmt.Errorf("buffer size %d is smaller than UDP packet size %d", len(b), len(data))
+	}
+	return copy(b, data), addr, nil
+}
+
+// WriteTo sends a UDP packet to the address. The address can have
+// a domain name, which is resolved by the proxy server.
+func (c *proxyPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	var header []byte
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		header = udpAddrToHeader(udpAddr)
+	} else {
+		req, err := newConnectRequest(addr.String())
+		if err != nil {
+			return 0, err
+		}
+		header = append([]byte{0, 0, 0}, req.DestAddr.Raw...)
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.tunnel.Write(append(header, b...)); err != nil {
+		return 0, err
+	}
+	UDPAssociateOutPkts.Add(1)
+	UDPAssociateOutBytes.Add(int64(len(header) + len(b)))
+	return len(b), nil
+}
+
+// fqdnUDPAddr is a UDP address with a domain name.
+type fqdnUDPAddr struct {
+	host string
+	port int
+}
+
+func (a *fqdnUDPAddr) Network() string {
+	return "udp"
+}
+
+func (a *fqdnUDPAddr) String() string {
+	return net.JoinHostPort(a.host, strconv.Itoa(a.port))
+}
+
+// Close ends the UDP association.
+func (c *proxyPacketConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *proxyPacketConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *proxyPacketConn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+func (c *proxyPacketConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *proxyPacketConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/egress"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/protocolv2"
+)
+
+// PriorityRules assign priority to proxy sessions by destination.
+type PriorityRules struct {
+	rules []priorityRule
+}
+
+type priorityRule struct {
+	ports    [][2]int // inclusive port ranges, empty to match all ports
+	domains  *egress.DomainMatcher
+	priority protocolv2.Priority
+}
+
+// NewPriorityRules parses the priority rules from config.
+func NewPriorityRules(rules []*appctlpb.PriorityRule) (*PriorityRules, error) {
+	p := &PriorityRules{}
+	for i, rule := range rules {
+		if len(rule.GetPorts()) == 0 && len(rule.GetDomainNames()) == 0 {
+			return nil, fmt.Errorf("priority rule %d has neither ports nor domain names", i)
+		}
+		r := priorityRule{}
+		switch rule.GetPriority() {
+		case appctlpb.SessionPriority_NORMAL_PRIORITY:
+			r.priority = protocolv2.PriorityNormal
+		case appctlpb.SessionPriority_INTERACTIVE_PRIORITY:
+			r.priority = protocolv2.PriorityInteractive
+		case appctlpb.SessionPriority_BULK_PRIORITY:
+			r.priority = protocolv2.PriorityBulk
+		default:
+			return nil, fmt.Errorf("priority rule %d: unknown priority %v", i, rule.GetPriority())
+		}
+		for _, port := range rule.GetPorts() {
+			portRange, err := egress.ParsePortRange(port)
+			if err != nil {
+				return nil, fmt.Errorf("priority rule %d: %w", i, err)
+			}
+			r.ports = append(r.ports, portRange)
+		}
+		if len(rule.GetDomainNames()) > 0 {
+			r.domains = egress.NewDomainMatcher()
+			for _, domain := range rule.GetDomainNames() {
+				r.domains.AddSuffix(domain)
+			}
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// Match returns the priority of the first rule matched by the destination.
+// If no rule is matched, the normal priority is returned.
+func (p *PriorityRules) Match(dest *AddrSpec) protocolv2.Priority {
+	if p == nil || dest == nil {
+		return protocolv2.PriorityNormal
+	}
+	for _, r := range p.rules {
+		if r.match(dest) {
+			return r.priority
+		}
+	}
+	return protocolv2.PriorityNormal
+}
+
+// apply sets the priority of the mieru session carried by the connection.
+func (p *PriorityRules) apply(conn net.Conn, dest *AddrSpec) {
+	if p == nil {
+		return
+	}
+	if session := mieruSession(conn); session != nil {
+		priority := p.Match(dest)
+		session.SetPriority(priority)
+		if log.IsLevelEnabled(log.TraceLevel) {
+			log.Tracef("%v priority is %v", session, priority)
+		}
+	}
+}
+
+func (r priorityRule) match(dest *AddrSpec) bool {
+	if len(r.ports) > 0 {
+		matched := false
+		for _, portRange := range r.ports {
+			if dest.Port >= portRange[0] && dest.Port <= portRange[1] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.domains != nil && (dest.FQDN == "" || !r.domains.Match(dest.FQDN)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"net"
+	"testing"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/protocolv2"
+)
+
+func TestPriorityRules(t *testing.T) {
+	rules, err := NewPriorityRules([]*appctlpb.PriorityRule{
+		{
+			Ports:    []string{"22", "5900-5910"},
+			Priority: appctlpb.SessionPriority_INTERACTIVE_PRIORITY.Enum(),
+		},
+		{
+			Ports:       []string{"443"},
+			DomainNames: []string{"download.example.com"},
+			Priority:    appctlpb.SessionPriority_BULK_PRIORITY.Enum(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewPriorityRules() failed: %v", err)
+	}
+	testCases := []struct {
+		dest *AddrSpec
+		want protocolv2.Priority
+	}{
+		{&AddrSpec{IP: net.ParseIP("192.168.1.1"), Port: 22}, protocolv2.PriorityInteractive},
+		{&AddrSpec{FQDN: "example.com", Port: 5905}, protocolv2.PriorityInteractive},
+		{&AddrSpec{FQDN: "a.download.example.com", Port: 443}, protocolv2.PriorityBulk},
+		{&AddrSpec{FQDN: "download.example.com", Port: 80}, protocolv2.PriorityNormal},
+		{&AddrSpec{IP: net.ParseIP("192.168.1.1"), Port: 443}, protocolv2.PriorityNormal},
+		{nil, protocolv2.PriorityNormal},
+	}
+	for _, tc := range testCases {
+		if got := rules.Match(tc.dest); got != tc.want {
+			t.Errorf("Match(%v) = %v, want %v", tc.dest, got, tc.want)
+		}
+	}
+}
+
+func TestPriorityRulesInvalid(t *testing.T) {
+	testCases := []*appctlpb.PriorityRule{
+		{Priority: appctlpb.SessionPriority_BULK_PRIORITY.Enum()},
+		{Ports: []string{"0"}},
+		{Ports: []string{"100-20"}},
+		{Ports: []string{"ssh"}},
+		{Ports: []string{"22"}, Priority: appctlpb.SessionPriority(100).Enum()},
+	}
+	for _, tc := range testCases {
+		if _, err := NewPriorityRules([]*appctlpb.PriorityRule{tc}); err == nil {
+			t.Errorf("NewPriorityRules(%v) succeeded, want error", tc)
+		}
+	}
+}
@@ -112,7 +112,7 @@ func (s *Server) handleRequest(ctx context.Context, req *Request, conn io.ReadWr
 	// Resolve the address if we have a FQDN.
 	dest := req.DestAddr
 	if dest.FQDN != "" {
-		addr, err := s.config.Resolver.LookupIP(ctx, dest.FQDN)
+		addr, err := s.lookupIP(ctx, dest.FQDN)
 		if err != nil {
 			DNSResolveErrors.Add(1)
 			if err := sendReply(conn, hostUnreachable, nil); err != nil {
@@ -128,6 +128,23 @@ func (s *Server) handleRequest(ctx context.Context, req *Request, conn io.ReadWr
 		return fmt.Errorf("access to localhost resource via proxy is not allowed")
 	}
 
+	// Return error if the destination is isolated.
+	if s.isIsolatedDest(dest.IP) {
+		IsolatedDestErrors.Add(1)
+		if err := sendReply(conn, ruleFailure, nil); err != nil {
+			return fmt.Errorf("failed to send reply: %w", err)
+		}
+		return fmt.Errorf("access to %v is not allowed by client isolation", dest.IP)
+	}
+
+	// Return error if the destination is denied by egress policy.
+	if err := s.checkEgressPolicy(sessionUserName(conn), dest.FQDN, dest.IP, dest.Port); err != nil {
+		if err := sendReply(conn, ruleFailure, nil); err != nil {
+			return fmt.Errorf("failed to send reply: %w", err)
+		}
+		return err
+	}
+
 	// Switch on the command.
 	switch req.Command {
 	case connectCommand:
@@ -147,8 +164,7 @@ func (s *Server) handleRequest(ctx context.Context, req *Request, conn io.ReadWr
 
 // handleConnect is used to handle a connect command.
 func (s *Server) handleConnect(ctx context.Context, req *Request, conn io.ReadWriteCloser) error {
-	var d net.Dialer
-	target, err := d.DialContext(ctx, "tcp", req.DestAddr.Address())
+	target, err := s.dial(ctx, "tcp", req.DestAddr.Address())
 	if err != nil {
 		msg := err.Error()
 		var resp uint8
@@ -177,7 +193,7 @@ func (s *Server) handleConnect(ctx context.Context, req *Request, conn io.ReadWr
 		return fmt.Errorf("failed to send reply: %w", err)
 	}
 
-	return util.BidiCopy(conn, target)
+	return relay(ctx, conn, target)
 }
 
 // handleBind is used to handle a bind command.
@@ -194,12 +210,7 @@ func (s *Server) handleBind(ctx context.Context, req *Request, conn io.ReadWrite
 func (s *Server) handleAssociate(ctx context.Context, req *Request, conn io.ReadWriteCloser) error {
 	// Create a UDP listener on a random port.
 	// All the requests associated to this connection will go through this port.
-	udpListenerAddr, err := net.ResolveUDPAddr("udp", util.MaybeDecorateIPv6(util.AllIPAddr())+":0")
-	if err != nil {
-		UDPAssociateErrors.Add(1)
-		return fmt.Errorf("failed to resolve UDP address: %w", err)
-	}
-	udpConn, err := net.ListenUDP("udp", udpListenerAddr)
+	udpConn, err := s.config.Outbound.listenUDP()
 	if err != nil {
 		UDPAssociateErrors.Add(1)
 		return fmt.Errorf("failed to listen UDP: %w", err)
@@ -225,6 +236,7 @@ func (s *Server) handleAssociate(ctx context.Context, req *Request, conn io.Read
 		return fmt.Errorf("failed to send reply: %w", err)
 	}
 
+	userName := sessionUserName(conn)
 	conn = WrapUDPAssociateTunnel(conn)
 	var udpErr atomic.Value
 
@@ -284,6 +296,14 @@ func (s *Server) handleAssociate(ctx context.Context, req *Request, conn io.Read
 					IP:   net.IP(buf[4:8]),
 					Port: int(buf[8])<<8 + int(buf[9]),
 				}
+				if s.isIsolatedDest(dstAddr.IP) {
+					IsolatedDestErrors.Add(1)
+					break
+				}
+				if err := s.checkEgressPolicy(userName, "", dstAddr.IP, dstAddr.Port); err != nil {
+					log.Debugf("UDP associate %v: %v", udpConn.LocalAddr(), err)
+					break
+				}
 				addrMap.Store(dstAddr.String(), buf[:10])
 				ws, err := udpConn.WriteToUDP(buf[10:n], dstAddr)
 				if err != nil {
@@ -302,6 +322,14 @@ func (s *Server) handleAssociate(ctx context.Context, req *Request, conn io.Read
 					UDPAssociateErrors.Add(1)
 					break
 				}
+				if s.isIsolatedDest(dstAddr.IP) {
+					IsolatedDestErrors.Add(1)
+					break
+				}
+				if err := s.checkEgressPolicy(userName, fqdn, dstAddr.IP, dstAddr.Port); err != nil {
+					log.Debugf("UDP associate %v: %v", udpConn.LocalAddr(), err)
+					break
+				}
 				addrMap.Store(dstAddr.String(), buf[:7+fqdnLen])
 				ws, err := udpConn.WriteToUDP(buf[7+fqdnLen:n], dstAddr)
 				if err != nil {
@@ -316,6 +344,14 @@ func (s *Server) handleAssociate(ctx context.Context, req *Request, conn io.Read
 					IP:   net.IP(buf[4:20]),
 					Port: int(buf[20])<<8 + int(buf[21]),
 				}
+				if s.isIsolatedDest(dstAddr.IP) {
+					IsolatedDestErrors.Add(1)
+					break
+				}
+				if err := s.checkEgressPolicy(userName, "", dstAddr.IP, dstAddr.Port); err != nil {
+					log.Debugf("UDP associate %v: %v", udpConn.LocalAddr(), err)
+					break
+				}
 				addrMap.Store(dstAddr.String(), buf[:22])
 				ws, err := udpConn.WriteToUDP(buf[22:n], dstAddr)
 				if err != nil {
@@ -418,42 +454,91 @@ func (s *Server) proxySocks5AuthReq(conn, proxyConn net.Conn) error {
 }
 
 // proxySocks5ConnReq transfers the socks5 connection request and response
-// between socks5 client and server. Optionally, if UDP association is used,
-// return the created UDP connection.
-func (s *Server) proxySocks5ConnReq(conn, proxyConn net.Conn) (*net.UDPConn, error) {
+// between socks5 client and server. If the request is already read from
+// the socks5 client, it is provided by req. Otherwise req is nil.
+// Optionally, if UDP association is used, return the created UDP connection.
+func (s *Server) proxySocks5ConnReq(conn, proxyConn net.Conn, req *Request) (*net.UDPConn, error) {
 	// Send the connection request to the server.
 	defer util.SetReadTimeout(conn, 0)
 	defer util.SetReadTimeout(proxyConn, 0)
-	util.SetReadTimeout(conn, s.config.HandshakeTimeout)
-	connReq := make([]byte, 4)
-	if _, err := io.ReadFull(conn, connReq); err != nil {
-		return nil, fmt.Errorf("failed to get socks5 connection request: %w", err)
-	}
-	cmd := connReq[1]
-	reqAddrType := connReq[3]
-	var reqFQDNLen []byte
-	var dstAddr []byte
-	switch reqAddrType {
-	case ipv4Address:
-		dstAddr = make([]byte, 6)
-	case fqdnAddress:
-		reqFQDNLen = []byte{0}
-		if _, err := io.ReadFull(conn, reqFQDNLen); err != nil {
-			return nil, fmt.Errorf("failed to get FQDN length: %w", err)
+	var cmd byte
+	var connReq []byte
+	if req != nil {
+		cmd = req.Command
+		connReq = req.Raw
+	} else {
+		util.SetReadTimeout(conn, s.config.HandshakeTimeout)
+		connReq = make([]byte, 4)
+		if _, err := io.ReadFull(conn, connReq); err != nil {
+			return nil, fmt.Errorf("failed to get socks5 connection request: %w", err)
 		}
-		dstAddr = make([]byte, reqFQDNLen[0]+2)
-	case ipv6Address:
-		dstAddr = make([]byte, 18)
-	default:
-		return nil, fmt.Errorf("unsupported address type: %d", reqAddrType)
+		cmd = connReq[1]
+		reqAddrType := connReq[3]
+		var reqFQDNLen []byte
+		var dstAddr []byte
+		switch reqAddrType {
+		case ipv4Address:
+			dstAddr = make([]byte, 6)
+		case fqdnAddress:
+			reqFQDNLen = []byte{0}
+			if _, err := io.ReadFull(conn, reqFQDNLen); err != nil {
+				return nil, fmt.Errorf("failed to get FQDN length: %w", err)
+			}
+			dstAddr = make([]byte, reqFQDNLen[0]+2)
+		case ipv6Address:
+			dstAddr = make([]byte, 18)
+		default:
+			return nil, fmt.Errorf("unsupported address type: %d", reqAddrType)
+		}
+		if _, err := io.ReadFull(conn, dstAddr); err != nil {
+			return nil, fmt.Errorf("failed to get destination address: %w", err)
+		}
+		if len(reqFQDNLen) != 0 {
+			connReq = append(connReq, reqFQDNLen...)
+		}
+		connReq = append(connReq, dstAddr...)
 	}
-	if _, err := io.ReadFull(conn, dstAddr); err != nil {
-		return nil, fmt.Errorf("failed to get destination address: %w", err)
+	connResp, err := s.exchangeConnReq(proxyConn, connReq)
+	if err != nil {
+		return nil, err
 	}
-	if len(reqFQDNLen) != 0 {
-		connReq = append(connReq, reqFQDNLen...)
+
+	var udpConn *net.UDPConn
+	if cmd == associateCommand {
+		// Create a UDP listener on a random port in IPv4 network.
+		var err error
+		udpAddr := &net.UDPAddr{IP: net.IP{0, 0, 0, 0}, Port: 0}
+		udpConn, err = net.ListenUDP("udp4", udpAddr)
+		if err != nil {
+			return nil, fmt.Errorf("net.ListenUDP() failed: %w", err)
+		}
+		// Get the port number and rewrite the response.
+		_, udpPortStr, err := net.SplitHostPort(udpConn.LocalAddr().String())
+		if err != nil {
+			udpConn.Close()
+			return nil, fmt.Errorf("net.SplitHostPort() failed: %w", err)
+		}
+		udpPort, err := strconv.Atoi(udpPortStr)
+		if err != nil {
+			udpConn.Close()
+			return nil, fmt.Errorf("strconv.Atoi() failed: %w", err)
+		}
+		lenResp := len(connResp)
+		connResp[lenResp-2] = byte(udpPort >> 8)
+		connResp[lenResp-1] = byte(udpPort)
+	}
+
+	if _, err := conn.Write(connResp); err != nil {
+		return nil, fmt.Errorf("failed to write connection response to the socks5 client: %w", err)
 	}
-	connReq = append(connReq, dstAddr...)
+
+	return udpConn, nil
+}
+
+// exchangeConnReq sends the socks5 connection request to the proxy server
+// and returns the connection response.
+func (s *Server) exchangeConnReq(proxyConn net.Conn, connReq []byte) ([]byte, error) {
+	defer util.SetReadTimeout(proxyConn, 0)
 	if _, err := proxyConn.Write(connReq); err != nil {
 		return nil, fmt.Errorf("failed to write connection request to the server: %w", err)
 	}
@@ -489,37 +574,7 @@ func (s *Server) proxySocks5ConnReq(conn, proxyConn net.Conn) (*net.UDPConn, err
 		connResp = append(connResp, respFQDNLen...)
 	}
 	connResp = append(connResp, bindAddr...)
-
-	var udpConn *net.UDPConn
-	if cmd == associateCommand {
-		// Create a UDP listener on a random port in IPv4 network.
-		var err error
-		udpAddr := &net.UDPAddr{IP: net.IP{0, 0, 0, 0}, Port: 0}
-		udpConn, err = net.ListenUDP("udp4", udpAddr)
-		if err != nil {
-			return nil, fmt.Errorf("net.ListenUDP() failed: %w", err)
-		}
-		// Get the port number and rewrite the response.
-		_, udpPortStr, err := net.SplitHostPort(udpConn.LocalAddr().String())
-		if err != nil {
-			udpConn.Close()
-			return nil, fmt.Errorf("net.SplitHostPort() failed: %w", err)
-		}
-		udpPort, err := strconv.Atoi(udpPortStr)
-		if err != nil {
-			udpConn.Close()
-			return nil, fmt.Errorf("strconv.Atoi() failed: %w", err)
-		}
-		lenResp := len(connResp)
-		connResp[lenResp-2] = byte(udpPort >> 8)
-		connResp[lenResp-1] = byte(udpPort)
-	}
-
-	if _, err := conn.Write(connResp); err != nil {
-		return nil, fmt.Errorf("failed to write connection response to the socks5 client: %w", err)
-	}
-
-	return udpConn, nil
+	return connResp, nil
 }
 
 // readAddrSpec is used to read AddrSpec.
@@ -137,3 +137,65 @@ func TestRequestUnsupportedCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestIsIsolatedDest(t *testing.T) {
+	clientIP := net.ParseIP("203.0.113.7")
+	s := &Server{
+		config: &Config{
+			ClientIsolation: true,
+			IsClientIP: func(ip net.IP) bool {
+				return ip.Equal(clientIP)
+			},
+		},
+	}
+	testCases := []struct {
+		ip   net.IP
+		want bool
+	}{
+		{net.ParseIP("127.0.0.1"), true},
+		{net.ParseIP("::1"), true},
+		{net.ParseIP("0.0.0.0"), true},
+		{clientIP, true},
+		{net.ParseIP("198.51.100.1"), false},
+		{nil, false},
+	}
+	for _, tc := range testCases {
+		if got := s.isIsolatedDest(tc.ip); got != tc.want {
+			t.Errorf("isIsolatedDest(%v) = %v, want %v", tc.ip, got, tc.want)
+		}
+	}
+
+	s.config.ClientIsolation = false
+	if s.isIsolatedDest(clientIP) {
+		t.Errorf("isIsolatedDest() = true when client isolation is disabled")
+	}
+}
+
+func TestNewConnectRequest(t *testing.T) {
+	testCases := []struct {
+		address string
+		raw     []byte
+	}{
+		{"1.2.3.4:80", []byte{5, 1, 0, 1, 1, 2, 3, 4, 0, 80}},
+		{"[::1]:443", []byte{5, 1, 0, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 187}},
+		{"example.com:8080", []byte{5, 1, 0, 3, 11, 'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'c', 'o', 'm', 31, 144}},
+	}
+	for _, tc := range testCases {
+		req, err := newConnectRequest(tc.address)
+		if err != nil {
+			t.Fatalf("newConnectRequest(%q) failed: %v", tc.address, err)
+		}
+		if !bytes.Equal(req.Raw, tc.raw) {
+			t.Errorf("newConnectRequest(%q) raw = %v, want %v", tc.address, req.Raw, tc.raw)
+		}
+		if req.DestAddr.Address() != tc.address {
+			t.Errorf("newConnectRequest(%q) address = %q", tc.address, req.DestAddr.Address())
+		}
+	}
+
+	for _, address := range []string{"example.com", "example.com:http", "example.com:65536"} {
+		if _, err := newConnectRequest(address); err == nil {
+			t.Errorf("newConnectRequest(%q) succeeded, want error", address)
+		}
+	}
+}
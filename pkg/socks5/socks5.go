@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/enfein/mieru/pkg/appctl/appctlpb"
@@ -15,6 +15,7 @@ import (
 	"github.com/enfein/mieru/pkg/metrics"
 	"github.com/enfein/mieru/pkg/protocolv2"
 	"github.com/enfein/mieru/pkg/stderror"
+	"github.com/enfein/mieru/pkg/tracing"
 	"github.com/enfein/mieru/pkg/util"
 )
 
@@ -24,6 +25,10 @@ const (
 
 	// No authentication required.
 	noAuth byte = 0
+
+	// drainCheckInterval is the interval to check if all the
+	// connections are finished when the server is draining.
+	drainCheckInterval = 100 * time.Millisecond
 )
 
 var (
@@ -34,6 +39,8 @@ var (
 	HostUnreachableErrors    = metrics.RegisterMetric("socks5", "HostUnreachableErrors", metrics.COUNTER)
 	ConnectionRefusedErrors  = metrics.RegisterMetric("socks5", "ConnectionRefusedErrors", metrics.COUNTER)
 	UDPAssociateErrors       = metrics.RegisterMetric("socks5", "UDPAssociateErrors", metrics.COUNTER)
+	IsolatedDestErrors       = metrics.RegisterMetric("socks5", "IsolatedDestErrors", metrics.COUNTER)
+	EgressPolicyErrors       = metrics.RegisterMetric("socks5", "EgressPolicyErrors", metrics.COUNTER)
 
 	UDPAssociateInBytes  = metrics.RegisterMetric("socks5 UDP associate", "InBytes", metrics.COUNTER)
 	UDPAssociateOutBytes = metrics.RegisterMetric("socks5 UDP associate", "OutBytes", metrics.COUNTER)
@@ -47,6 +54,13 @@ type Config struct {
 	ProxyMux *protocolv2.Mux
 
 	// Egress controller.
+	//
+	// At proxy server side, the default controller always connects
+	// to the destination directly.
+	//
+	// At proxy client side, if set, the controller decides whether to
+	// connect to the destination via proxy, directly, or reject it.
+	// This requires ClientSideAuthentication.
 	EgressController egress.Controller
 
 	// Resolver can be provided to do custom name resolution.
@@ -67,6 +81,39 @@ type Config struct {
 
 	// Do socks5 authentication at proxy client side.
 	ClientSideAuthentication bool
+
+	// Reject destinations that are IP addresses of the proxy server
+	// or proxy clients, so clients of a shared proxy server can't
+	// access each other.
+	ClientIsolation bool
+
+	// IsClientIP returns true if the IP address belongs to a proxy client.
+	// It is used by ClientIsolation.
+	IsClientIP func(net.IP) bool
+
+	// If set, proxy server rejects destinations that are not allowed
+	// by the egress policy before connecting to them.
+	EgressPolicy *egress.Policy
+
+	// If set, proxy server connects to destinations from the network
+	// interface and the source IP addresses.
+	Outbound *OutboundConfig
+
+	// Let proxy server resolve domain names. If set, proxy client never
+	// resolves the destination locally, even if the egress decision is DIRECT.
+	RemoteDNSResolution bool
+
+	// If set, proxy client counts the traffic of each destination
+	// that is connected via proxy. This requires ClientSideAuthentication.
+	Destinations *DestinationStats
+
+	// If set, the priority of mieru sessions is decided by the destination.
+	// At proxy client side, this requires ClientSideAuthentication.
+	PriorityRules *PriorityRules
+
+	// If set, the egress action of connections received by the transparent
+	// proxy is decided by the local process that creates them.
+	ApplicationRules *ApplicationRules
 }
 
 // Server is responsible for accepting connections and handling
@@ -77,6 +124,11 @@ type Server struct {
 	chAccept    chan net.Conn
 	chAcceptErr chan error
 	die         chan struct{}
+
+	draining    atomic.Bool  // new connections are rejected
+	activeConns atomic.Int64 // number of connections being served
+
+	upstream upstreamMuxes // connections to upstream mieru servers
 }
 
 // New creates a new Server and potentially returns an error.
@@ -85,8 +137,8 @@ func New(conf *Config) (*Server, error) {
 		return nil, fmt.Errorf("ProxyMux must be set when proxy is enabled")
 	}
 
-	// Ensure we have a egress controller.
-	if conf.EgressController == nil {
+	// Ensure we have a egress controller at server side.
+	if !conf.UseProxy && conf.EgressController == nil {
 		conf.EgressController = egress.AlwaysDirectController{}
 	}
 
@@ -127,6 +179,13 @@ func (s *Server) Serve(l net.Listener) error {
 	for {
 		select {
 		case conn := <-s.chAccept:
+			if s.draining.Load() {
+				if log.IsLevelEnabled(log.TraceLevel) {
+					log.Tracef("socks5 server is draining, rejected connection [%v - %v]", conn.LocalAddr(), conn.RemoteAddr())
+				}
+				conn.Close()
+				continue
+			}
 			go func() {
 				err := s.ServeConn(conn)
 				if err != nil && !stderror.IsEOF(err) && !stderror.IsClosed(err) {
@@ -152,22 +211,56 @@ func (s *Server) Serve(l net.Listener) error {
 
 // ServeConn is used to serve a single connection.
 func (s *Server) ServeConn(conn net.Conn) error {
+	s.activeConns.Add(1)
+	defer s.activeConns.Add(-1)
+	// If the connection is a mieru session, the span is a child of the session.
+	ctx, span := tracing.Start(tracing.ContextFrom(conn), "socks5.serve")
+	defer span.End()
 	conn = util.WrapHierarchyConn(conn)
 	defer conn.Close()
 	if log.IsLevelEnabled(log.TraceLevel) {
 		log.Tracef("socks5 server starts to serve connection [%v - %v]", conn.LocalAddr(), conn.RemoteAddr())
 	}
 
+	var err error
 	if s.config.UseProxy {
-		return s.clientServeConn(conn)
+		err = s.clientServeConn(ctx, conn)
 	} else {
-		return s.serverServeConn(conn)
+		err = s.serverServeConn(ctx, conn)
+	}
+	span.RecordError(err)
+	return err
+}
+
+// ActiveConnections returns the number of connections being served.
+func (s *Server) ActiveConnections() int64 {
+	return s.activeConns.Load()
+}
+
+// Drain stops accepting new connections, and waits until all the
+// connections being served are finished or the context is done.
+// The server is not closed. Call Close after Drain returns.
+func (s *Server) Drain(ctx context.Context) error {
+	s.draining.Store(true)
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+	for {
+		n := s.activeConns.Load()
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d connections are not finished: %w", n, ctx.Err())
+		case <-ticker.C:
+		}
 	}
 }
 
 // Close closes the network listener used by the server.
 func (s *Server) Close() error {
 	close(s.die)
+	s.upstream.close()
 	return nil
 }
 
@@ -185,30 +278,87 @@ func (s *Server) acceptLoop() {
 	}
 }
 
-func (s *Server) clientServeConn(conn net.Conn) error {
+func (s *Server) clientServeConn(ctx context.Context, conn net.Conn) error {
+	_, handshakeSpan := tracing.Start(ctx, "socks5.handshake")
 	if s.config.ClientSideAuthentication {
 		if err := s.handleAuthentication(conn); err != nil {
+			handshakeSpan.RecordError(err)
+			handshakeSpan.End()
 			return err
 		}
 	}
 
+	// Read the destination at client side to apply egress rules
+	// and count the traffic.
+	var request *Request
+	var err error
+	if s.config.ClientSideAuthentication && (s.config.EgressController != nil || s.config.Destinations != nil || s.config.PriorityRules != nil) {
+		request, err = s.newRequest(conn)
+		handshakeSpan.RecordError(err)
+		if err != nil {
+			handshakeSpan.End()
+			HandshakeErrors.Add(1)
+			if errors.Is(err, errUnrecognizedAddrType) {
+				if err := sendReply(conn, addrTypeNotSupported, nil); err != nil {
+					return fmt.Errorf("failed to send reply: %w", err)
+				}
+			}
+			return fmt.Errorf("failed to read destination address: %w", err)
+		}
+		handshakeSpan.SetAttributes(tracing.String("socks5.destination", request.DestAddr.String()))
+	}
+	if request != nil && s.config.EgressController != nil {
+		action := s.config.EgressController.FindAction(egress.Input{
+			Protocol: appctlpb.ProxyProtocol_SOCKS5_PROXY_PROTOCOL,
+			Data:     request.Raw,
+		})
+		log.Debugf("Client egress decision of socks5 request %v is %s", request.Raw, action.Action.String())
+		handshakeSpan.SetAttributes(tracing.String("egress.action", action.Action.String()))
+		handshakeSpan.End()
+		switch action.Action {
+		case appctlpb.EgressAction_DIRECT:
+			if s.config.RemoteDNSResolution && (request.DestAddr.FQDN != "" || request.Command == associateCommand) {
+				// UDP associate may also carry domain names in the packets.
+				log.Debugf("Forward socks5 request %v to proxy for remote DNS resolution", request.Raw)
+				break
+			}
+			if err := s.handleRequest(ctx, request, conn); err != nil {
+				return fmt.Errorf("handleRequest() failed: %w", err)
+			}
+			return nil
+		case appctlpb.EgressAction_REJECT:
+			if err := sendReply(conn, ruleFailure, nil); err != nil {
+				return fmt.Errorf("failed to send reply: %w", err)
+			}
+			return fmt.Errorf("connection is rejected by egress rules")
+		}
+	}
+
+	handshakeSpan.End()
+
 	// Forward remaining bytes to proxy.
-	ctx := context.Background()
 	var proxyConn net.Conn
-	var err error
 	proxyConn, err = s.config.ProxyMux.DialContext(ctx)
 	if err != nil {
 		return fmt.Errorf("mux DialContext() failed: %w", err)
 	}
+	if request != nil {
+		s.config.PriorityRules.apply(proxyConn, request.DestAddr)
+	}
 
+	_, proxyHandshakeSpan := tracing.Start(tracing.ContextFrom(proxyConn), "socks5.proxy_handshake")
 	if !s.config.ClientSideAuthentication {
 		if err := s.proxySocks5AuthReq(conn, proxyConn); err != nil {
 			HandshakeErrors.Add(1)
+			proxyHandshakeSpan.RecordError(err)
+			proxyHandshakeSpan.End()
 			proxyConn.Close()
 			return err
 		}
 	}
-	udpAssociateConn, err := s.proxySocks5ConnReq(conn, proxyConn)
+	udpAssociateConn, err := s.proxySocks5ConnReq(conn, proxyConn, request)
+	proxyHandshakeSpan.RecordError(err)
+	proxyHandshakeSpan.End()
 	if err != nil {
 		HandshakeErrors.Add(1)
 		proxyConn.Close()
@@ -224,18 +374,26 @@ func (s *Server) clientServeConn(conn net.Conn) error {
 		}()
 		return BidiCopyUDP(udpAssociateConn, WrapUDPAssociateTunnel(proxyConn))
 	}
-	return util.BidiCopy(conn, proxyConn)
+	if s.config.Destinations != nil && request != nil {
+		return relay(tracing.ContextFrom(proxyConn), conn, s.config.Destinations.wrap(proxyConn, request.DestAddr))
+	}
+	return relay(tracing.ContextFrom(proxyConn), conn, proxyConn)
 }
 
-func (s *Server) serverServeConn(conn net.Conn) error {
+func (s *Server) serverServeConn(ctx context.Context, conn net.Conn) error {
+	_, handshakeSpan := tracing.Start(ctx, "socks5.handshake")
 	if !s.config.ClientSideAuthentication {
 		if err := s.handleAuthentication(conn); err != nil {
+			handshakeSpan.RecordError(err)
+			handshakeSpan.End()
 			return err
 		}
 	}
 
 	request, err := s.newRequest(conn)
 	if err != nil {
+		handshakeSpan.RecordError(err)
+		handshakeSpan.End()
 		HandshakeErrors.Add(1)
 		if errors.Is(err, errUnrecognizedAddrType) {
 			if err := sendReply(conn, addrTypeNotSupported, nil); err != nil {
@@ -245,21 +403,37 @@ func (s *Server) serverServeConn(conn net.Conn) error {
 		return fmt.Errorf("failed to read destination address: %w", err)
 	}
 
+	handshakeSpan.SetAttributes(tracing.String("socks5.destination", request.DestAddr.String()))
+	handshakeSpan.End()
+	s.config.PriorityRules.apply(conn, request.DestAddr)
+	if isSpeedTestRequest(request) {
+		return s.handleSpeedTest(conn)
+	}
+
 	action := s.config.EgressController.FindAction(egress.Input{
 		Protocol: appctlpb.ProxyProtocol_SOCKS5_PROXY_PROTOCOL,
 		Data:     request.Raw,
+		UserName: sessionUserName(conn),
 	})
 	log.Debugf("Egress decision of socks5 request %v is %s", request.Raw, action.Action.String())
 	switch action.Action {
 	case appctlpb.EgressAction_DIRECT:
-		if err := s.handleRequest(context.Background(), request, conn); err != nil {
+		if err := s.handleRequest(ctx, request, conn); err != nil {
 			return fmt.Errorf("handleRequest() failed: %w", err)
 		}
 	case appctlpb.EgressAction_PROXY:
 		if action.Proxy == nil {
 			return fmt.Errorf("egress action is PROXY but proxy info is unavailable")
 		}
-		return s.handleForwarding(request, conn, action.Proxy.GetHost(), action.Proxy.GetPort())
+		// The egress proxy resolves the destination, so the policy
+		// checks the domain name or IP address in the request.
+		if err := s.checkEgressPolicy(sessionUserName(conn), request.DestAddr.FQDN, request.DestAddr.IP, request.DestAddr.Port); err != nil {
+			if err := sendReply(conn, ruleFailure, nil); err != nil {
+				return fmt.Errorf("failed to send reply: %w", err)
+			}
+			return err
+		}
+		return s.handleForwarding(ctx, request, conn, action.Proxy)
 	case appctlpb.EgressAction_REJECT:
 		return fmt.Errorf("connection is rejected by egress rules")
 	}
@@ -313,36 +487,11 @@ func (s *Server) handleAuthentication(conn net.Conn) error {
 	return nil
 }
 
-func (s *Server) handleForwarding(req *Request, conn net.Conn, forwardHost string, forwardPort int32) error {
-	proxyConn, err := net.Dial("tcp", util.MaybeDecorateIPv6(forwardHost)+":"+strconv.Itoa(int(forwardPort)))
+func (s *Server) handleForwarding(ctx context.Context, req *Request, conn net.Conn, proxy *appctlpb.EgressProxy) error {
+	proxyConn, err := s.dialUpstream(ctx, req, conn, proxy)
 	if err != nil {
 		HandshakeErrors.Add(1)
-		return fmt.Errorf("dial to egress proxy failed: %w", err)
-	}
-
-	// Authenticate with the egress proxy.
-	if _, err := proxyConn.Write([]byte{socks5Version, 1, noAuth}); err != nil {
-		HandshakeErrors.Add(1)
-		proxyConn.Close()
-		return fmt.Errorf("failed to write socks5 auth header to egress proxy: %w", err)
-	}
-	util.SetReadTimeout(proxyConn, s.config.HandshakeTimeout)
-	defer util.SetReadTimeout(proxyConn, 0)
-	resp := []byte{0, 0}
-	if _, err := io.ReadFull(proxyConn, resp); err != nil {
-		HandshakeErrors.Add(1)
-		proxyConn.Close()
-		return fmt.Errorf("failed to read socks5 auth response from egress proxy: %w", err)
-	}
-	if resp[0] != socks5Version || resp[1] != noAuth {
-		HandshakeErrors.Add(1)
-		proxyConn.Close()
-		return fmt.Errorf("got unexpected socks5 auth response from egress proxy: %v", resp)
-	}
-	if _, err := proxyConn.Write(req.Raw); err != nil {
-		HandshakeErrors.Add(1)
-		proxyConn.Close()
-		return fmt.Errorf("failed to write socks5 request to egress proxy: %w", err)
+		return fmt.Errorf("dial to egress proxy %s failed: %w", proxy.GetName(), err)
 	}
 	return util.BidiCopy(conn, proxyConn)
 }
@@ -2,6 +2,7 @@ package socks5
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"io"
 	"net"
@@ -9,7 +10,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/egress"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/speedtest"
 	"github.com/enfein/mieru/pkg/util"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestSocks5Connect(t *testing.T) {
@@ -242,3 +250,212 @@ func TestSocks5UDPAssociation(t *testing.T) {
 		t.Errorf("UDPAssociateOutPkts value %d is not increased", UDPAssociateOutPkts.Load())
 	}
 }
+
+func TestSocks5SpeedTest(t *testing.T) {
+	serv, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	serverPort, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	go func() {
+		if err := serv.ListenAndServe("tcp", "127.0.0.1:"+strconv.Itoa(serverPort)); err != nil {
+			t.Errorf("ListenAndServe() failed: %v", err)
+			return
+		}
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	conn, err := net.Dial("tcp", "127.0.0.1:"+strconv.Itoa(serverPort))
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req := bytes.NewBuffer(nil)
+	req.Write([]byte{5, 1, noAuth})
+	req.Write([]byte{5, 1, 0, 3, byte(len(speedtest.Host))})
+	req.Write([]byte(speedtest.Host))
+	req.Write([]byte{0, speedtest.Port})
+	if _, err := conn.Write(req.Bytes()); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	resp := make([]byte, 12)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		t.Fatalf("io.ReadFull() failed: %v", err)
+	}
+	if resp[3] != successReply {
+		t.Fatalf("got reply code %d, want %d", resp[3], successReply)
+	}
+
+	if _, err := speedtest.Ping(conn, 3); err != nil {
+		t.Errorf("Ping() failed: %v", err)
+	}
+	if _, err := speedtest.Upload(conn, 1<<16); err != nil {
+		t.Errorf("Upload() failed: %v", err)
+	}
+	if _, err := speedtest.Download(conn, 1<<16); err != nil {
+		t.Errorf("Download() failed: %v", err)
+	}
+}
+
+func TestSocks5Drain(t *testing.T) {
+	serv, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	serverPort, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	go func() {
+		if err := serv.ListenAndServe("tcp", "127.0.0.1:"+strconv.Itoa(serverPort)); err != nil {
+			t.Errorf("ListenAndServe() failed: %v", err)
+		}
+	}()
+	time.Sleep(200 * time.Millisecond)
+	defer serv.Close()
+
+	// The connection is active until the client closes it.
+	conn, err := net.Dial("tcp", "127.0.0.1:"+strconv.Itoa(serverPort))
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for serv.ActiveConnections() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("ActiveConnections() = %d, want 1", serv.ActiveConnections())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancelFunc()
+	if err := serv.Drain(ctx); err == nil {
+		t.Errorf("Drain() succeeded with an active connection, want error")
+	}
+
+	// New connections are rejected.
+	conn2, err := net.Dial("tcp", "127.0.0.1:"+strconv.Itoa(serverPort))
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	conn2.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn2.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Read() from a rejected connection got %v, want %v", err, io.EOF)
+	}
+	conn2.Close()
+
+	conn.Close()
+	ctx2, cancelFunc2 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFunc2()
+	if err := serv.Drain(ctx2); err != nil {
+		t.Errorf("Drain() failed: %v", err)
+	}
+}
+
+func TestSocks5RemoteDNSResolution(t *testing.T) {
+	log.SetOutputToTest(t)
+	port, err := util.UnusedTCPPort()
+	if err != nil {
+		t.Fatalf("util.UnusedTCPPort() failed: %v", err)
+	}
+	serverMux := protocolv2.NewMux(false).
+		SetServerUsers(map[string]*appctlpb.User{
+			"xiaochitang": {
+				Name:     proto.String("xiaochitang"),
+				Password: proto.String("kuiranbudong"),
+			},
+		}).
+		SetEndpoints([]protocolv2.UnderlayProperties{
+			protocolv2.NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}, nil),
+		})
+	if err := serverMux.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer serverMux.Close()
+
+	// The proxy server records the socks5 requests it receives.
+	const fqdn = "remote-dns.invalid"
+	want := []byte{socks5Version, connectCommand, 0, fqdnAddress, byte(len(fqdn))}
+	want = append(want, fqdn...)
+	want = append(want, 1, 187)
+	received := make(chan []byte, 2)
+	go func() {
+		for {
+			conn, err := serverMux.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				b := make([]byte, len(want))
+				if _, err := io.ReadFull(conn, b); err != nil {
+					return
+				}
+				received <- b
+				conn.Write([]byte{socks5Version, successReply, 0, ipv4Address, 0, 0, 0, 0, 0, 0})
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+
+	clientMux := protocolv2.NewMux(true).
+		SetClientPassword(cipher.HashPassword([]byte("kuiranbudong"), []byte("xiaochitang"))).
+		SetEndpoints([]protocolv2.UnderlayProperties{
+			protocolv2.NewUnderlayProperties(1500, util.IPVersion4, util.TCPTransport, nil, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port}),
+		})
+	defer clientMux.Close()
+	// The egress rules decide DIRECT, but the domain name must not be
+	// resolved at client side.
+	client, err := New(&Config{
+		UseProxy:                 true,
+		ClientSideAuthentication: true,
+		ProxyMux:                 clientMux,
+		EgressController:         egress.AlwaysDirectController{},
+		RemoteDNSResolution:      true,
+		HandshakeTimeout:         10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	go client.Serve(l)
+	defer client.Close()
+
+	// Socks5 request.
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write(append([]byte{socks5Version, 1, noAuth}, want...)); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	select {
+	case got := <-received:
+		if !bytes.Equal(got, want) {
+			t.Errorf("proxy server received socks5 request %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("socks5 request is not forwarded to proxy server")
+	}
+
+	// Dial from other proxy protocols.
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFunc()
+	proxyConn, err := client.DialContext(ctx, "tcp", net.JoinHostPort(fqdn, "443"))
+	if err != nil {
+		t.Fatalf("DialContext() failed: %v", err)
+	}
+	defer proxyConn.Close()
+	if got := <-received; !bytes.Equal(got, want) {
+		t.Errorf("proxy server received socks5 request %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/enfein/mieru/pkg/metrics"
+	"github.com/enfein/mieru/pkg/speedtest"
+)
+
+var SpeedTestRequests = metrics.RegisterMetric("socks5", "SpeedTestRequests", metrics.COUNTER)
+
+// isSpeedTestRequest returns true if the request is a speed test request
+// that should be served by the proxy server itself.
+func isSpeedTestRequest(req *Request) bool {
+	return req.Command == connectCommand && req.DestAddr.FQDN == speedtest.Host && req.DestAddr.Port == speedtest.Port
+}
+
+// handleSpeedTest serves the speed test protocol in the connection.
+func (s *Server) handleSpeedTest(conn io.ReadWriter) error {
+	SpeedTestRequests.Add(1)
+	if err := sendReply(conn, successReply, nil); err != nil {
+		HandshakeErrors.Add(1)
+		return fmt.Errorf("failed to send reply: %w", err)
+	}
+	return speedtest.Serve(conn)
+}
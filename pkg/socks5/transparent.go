@@ -0,0 +1,163 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/metrics"
+	"github.com/enfein/mieru/pkg/stderror"
+	"github.com/enfein/mieru/pkg/util/sockopts"
+)
+
+var (
+	TransparentProxyConns  = metrics.RegisterMetric("transparent proxy", "Conns", metrics.COUNTER)
+	TransparentProxyErrors = metrics.RegisterMetric("transparent proxy", "Errors", metrics.COUNTER)
+)
+
+// ServeTransparent accepts TCP connections diverted by iptables from
+// the listener, and forwards them to the original destination through
+// the proxy. It returns when the listener is closed or the server is closed.
+func (s *Server) ServeTransparent(l net.Listener, mode appctlpb.TransparentProxyMode) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-s.die:
+			l.Close()
+		case <-done:
+		}
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-s.die:
+				return nil
+			default:
+				return err
+			}
+		}
+		if s.draining.Load() {
+			conn.Close()
+			continue
+		}
+		go func() {
+			if err := s.serveTransparentConn(conn, l.Addr(), mode); err != nil && !stderror.IsEOF(err) && !stderror.IsClosed(err) {
+				TransparentProxyErrors.Add(1)
+				log.Debugf("transparent proxy listener %v failed to serve connection from %v: %v", l.Addr(), conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// serveTransparentConn forwards a diverted connection to its
+// original destination.
+func (s *Server) serveTransparentConn(conn net.Conn, listenAddr net.Addr, mode appctlpb.TransparentProxyMode) error {
+	s.activeConns.Add(1)
+	defer s.activeConns.Add(-1)
+	defer conn.Close()
+	TransparentProxyConns.Add(1)
+
+	dst, err := transparentDestination(conn, mode)
+	if err != nil {
+		return err
+	}
+	if isListenAddr(dst, listenAddr) {
+		return fmt.Errorf("connection to the transparent proxy port %v is not diverted", dst)
+	}
+	if log.IsLevelEnabled(log.TraceLevel) {
+		log.Tracef("transparent proxy received connection from %v to %v", conn.RemoteAddr(), dst)
+	}
+	ctx := context.Background()
+	proxyConn, err := s.dialTransparent(ctx, conn, dst)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %v: %w", dst, err)
+	}
+	return relay(ctx, conn, proxyConn)
+}
+
+// dialTransparent connects to the original destination of a diverted
+// connection. If the connection is created by a local process that
+// matches an application rule, the action of the rule is used.
+// Otherwise, the egress rules are applied.
+func (s *Server) dialTransparent(ctx context.Context, conn net.Conn, dst *net.TCPAddr) (net.Conn, error) {
+	src, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return s.DialContext(ctx, "tcp", dst.String())
+	}
+	action, ok := s.config.ApplicationRules.find(src, dst)
+	if !ok {
+		return s.DialContext(ctx, "tcp", dst.String())
+	}
+	switch action {
+	case appctlpb.EgressAction_DIRECT:
+		return s.dial(ctx, "tcp", dst.String())
+	case appctlpb.EgressAction_REJECT:
+		return nil, fmt.Errorf("connection from %v is rejected by application rules", src)
+	default:
+		req, err := newConnectRequest(dst.String())
+		if err != nil {
+			return nil, err
+		}
+		return s.dialProxy(ctx, req, dst.String())
+	}
+}
+
+// transparentDestination returns the original destination of
+// a diverted connection.
+func transparentDestination(conn net.Conn, mode appctlpb.TransparentProxyMode) (*net.TCPAddr, error) {
+	if mode == appctlpb.TransparentProxyMode_TRANSPARENT_PROXY_TPROXY {
+		// TPROXY doesn't change the destination of packets.
+		addr, ok := conn.LocalAddr().(*net.TCPAddr)
+		if !ok {
+			return nil, fmt.Errorf("local address %v is not a TCP address", conn.LocalAddr())
+		}
+		return addr, nil
+	}
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil, fmt.Errorf("connection is not a TCP connection")
+	}
+	return sockopts.OriginalDestination(tcpConn)
+}
+
+// isListenAddr returns true if the destination is the address of
+// the transparent proxy listener. Forwarding such connections
+// creates a loop.
+func isListenAddr(dst *net.TCPAddr, listenAddr net.Addr) bool {
+	l, ok := listenAddr.(*net.TCPAddr)
+	if !ok || dst.Port != l.Port {
+		return false
+	}
+	if dst.IP.IsLoopback() || dst.IP.IsUnspecified() || dst.IP.Equal(l.IP) {
+		return true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(dst.IP) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"net"
+	"testing"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+)
+
+func TestTransparentDestinationTProxy(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Accept() failed: %v", err)
+	}
+	defer conn.Close()
+
+	dst, err := transparentDestination(conn, appctlpb.TransparentProxyMode_TRANSPARENT_PROXY_TPROXY)
+	if err != nil {
+		t.Fatalf("transparentDestination() failed: %v", err)
+	}
+	if dst.String() != l.Addr().String() {
+		t.Errorf("got destination %v, want %v", dst, l.Addr())
+	}
+	if !isListenAddr(dst, l.Addr()) {
+		t.Errorf("isListenAddr(%v) = false, want true", dst)
+	}
+}
+
+func TestIsListenAddr(t *testing.T) {
+	listenAddr := &net.TCPAddr{IP: net.IPv6unspecified, Port: 12345}
+	testCases := []struct {
+		dst  *net.TCPAddr
+		want bool
+	}{
+		{&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, true},
+		{&net.TCPAddr{IP: net.ParseIP("::1"), Port: 12345}, true},
+		{&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 443}, false},
+		{&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 12345}, false},
+	}
+	for _, tc := range testCases {
+		if got := isListenAddr(tc.dst, listenAddr); got != tc.want {
+			t.Errorf("isListenAddr(%v) = %v, want %v", tc.dst, got, tc.want)
+		}
+	}
+}
@@ -50,6 +50,10 @@ func (c *UDPAssociateTunnelConn) Read(b []byte) (n int, err error) {
 	}
 	length := int(binary.BigEndian.Uint16(lengthBytes))
 	if length > len(b) {
+		// Drop this packet to keep the packet boundary of the next read.
+		if _, err = io.CopyN(io.Discard, c.ReadWriteCloser, int64(length)+1); err != nil {
+			return 0, err
+		}
 		return 0, io.ErrShortBuffer
 	}
 
@@ -17,6 +17,8 @@ package socks5
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"net"
 	"testing"
 
@@ -54,6 +56,31 @@ func TestUDPAssociateTunnelConn(t *testing.T) {
 	}
 }
 
+func TestUDPAssociateTunnelConnShortBuffer(t *testing.T) {
+	in, out := testtool.BufPipe()
+	inConn := WrapUDPAssociateTunnel(in)
+	outConn := WrapUDPAssociateTunnel(out)
+
+	if _, err := inConn.Write([]byte{1, 2, 3, 4, 5, 6, 7, 8}); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := inConn.Write([]byte{8, 9, 6, 4}); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	buf := make([]byte, 4)
+	if _, err := outConn.Read(buf); !errors.Is(err, io.ErrShortBuffer) {
+		t.Fatalf("Read() error = %v, want %v", err, io.ErrShortBuffer)
+	}
+	n, err := outConn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if !bytes.Equal(buf[:n], []byte{8, 9, 6, 4}) {
+		t.Errorf("Read() got %v, want %v", buf[:n], []byte{8, 9, 6, 4})
+	}
+}
+
 func TestUDPAddrToHeader(t *testing.T) {
 	testcases := []struct {
 		addr   *net.UDPAddr
@@ -0,0 +1,58 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"net"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/stderror"
+)
+
+// ServeUnix accepts socks5 connections from the Unix domain socket
+// listener, in addition to the listener passed to Serve. It returns
+// when the listener is closed or the server is closed.
+func (s *Server) ServeUnix(l net.Listener) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-s.die:
+			l.Close()
+		case <-done:
+		}
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-s.die:
+				return nil
+			default:
+				return err
+			}
+		}
+		if s.draining.Load() {
+			conn.Close()
+			continue
+		}
+		go func() {
+			if err := s.ServeConn(conn); err != nil && !stderror.IsEOF(err) && !stderror.IsClosed(err) {
+				log.Debugf("socks5 server unix socket listener %v ServeConn() failed: %v", l.Addr(), err)
+			}
+		}()
+	}
+}
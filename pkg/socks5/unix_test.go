@@ -0,0 +1,98 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServeUnix(t *testing.T) {
+	// Create a local listener as the destination target.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}()
+	lAddr := l.Addr().(*net.TCPAddr)
+
+	serv, err := New(&Config{
+		AllowLocalDestination: true,
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	socketPath := filepath.Join(t.TempDir(), "socks5.sock")
+	unixListener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("Unix domain socket is not supported: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- serv.ServeUnix(unixListener)
+	}()
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer conn.Close()
+	req := bytes.NewBuffer(nil)
+	req.Write([]byte{socks5Version, 1, noAuth})
+	req.Write([]byte{socks5Version, connectCommand, 0, ipv4Address, 127, 0, 0, 1})
+	port := []byte{0, 0}
+	binary.BigEndian.PutUint16(port, uint16(lAddr.Port))
+	req.Write(port)
+	req.Write([]byte("ping"))
+	if _, err := conn.Write(req.Bytes()); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	out := make([]byte, 2+10+4)
+	conn.SetDeadline(time.Now().Add(time.Second))
+	if _, err := io.ReadFull(conn, out); err != nil {
+		t.Fatalf("io.ReadFull() failed: %v", err)
+	}
+	if out[3] != successReply {
+		t.Errorf("got socks5 reply %v, want success", out[3])
+	}
+	if string(out[12:]) != "ping" {
+		t.Errorf("got %q, want %q", out[12:], "ping")
+	}
+
+	serv.Close()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("ServeUnix() failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("ServeUnix() is not returned after Close()")
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/cipher"
+	"github.com/enfein/mieru/pkg/protocolv2"
+	"github.com/enfein/mieru/pkg/util"
+)
+
+const (
+	// Username and password authentication defined in RFC 1929.
+	userPassAuth byte = 2
+
+	// Version of username and password authentication.
+	userPassAuthVersion byte = 1
+)
+
+// upstreamMuxes holds the multiplexers connected to upstream mieru
+// servers. A multiplexer is created when the proxy is first used,
+// and reused by the following connections.
+type upstreamMuxes struct {
+	mu    sync.Mutex
+	muxes map[string]*protocolv2.Mux
+}
+
+// get returns the multiplexer connected to the mieru proxy.
+func (u *upstreamMuxes) get(ctx context.Context, proxy *appctlpb.EgressProxy, resolver *util.DNSResolver) (*protocolv2.Mux, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if mux, ok := u.muxes[proxy.GetName()]; ok {
+		return mux, nil
+	}
+	proxyIP := net.ParseIP(proxy.GetHost())
+	if proxyIP == nil {
+		var err error
+		proxyIP, err = resolver.LookupIP(ctx, proxy.GetHost())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve egress proxy %s: %w", proxy.GetHost(), err)
+		}
+	}
+	ipVersion := util.GetIPVersion(proxyIP.String())
+	var endpoint protocolv2.UnderlayProperties
+	if proxy.GetTransportProtocol() == appctlpb.TransportProtocol_UDP {
+		endpoint = protocolv2.NewUnderlayProperties(util.DefaultMTU, ipVersion, util.UDPTransport, nil, &net.UDPAddr{IP: proxyIP, Port: int(proxy.GetPort())})
+	} else {
+		endpoint = protocolv2.NewUnderlayProperties(util.DefaultMTU, ipVersion, util.TCPTransport, nil, &net.TCPAddr{IP: proxyIP, Port: int(proxy.GetPort())})
+	}
+	mux := protocolv2.NewMux(true).
+		SetClientPassword(cipher.HashPassword([]byte(proxy.GetPassword()), []byte(proxy.GetUser()))).
+		SetClientMultiplexFactor(1)
+	mux.SetEndpoints([]protocolv2.UnderlayProperties{endpoint})
+	if u.muxes == nil {
+		u.muxes = make(map[string]*protocolv2.Mux)
+	}
+	u.muxes[proxy.GetName()] = mux
+	return mux, nil
+}
+
+// close closes all the multiplexers.
+func (u *upstreamMuxes) close() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for name, mux := range u.muxes {
+		mux.Close()
+		delete(u.muxes, name)
+	}
+}
+
+// dialUpstream returns a connection to the destination of the request
+// through the egress proxy. The socks5 response is written to conn if
+// it is not relayed from the egress proxy.
+func (s *Server) dialUpstream(ctx context.Context, req *Request, conn net.Conn, proxy *appctlpb.EgressProxy) (net.Conn, error) {
+	switch proxy.GetProtocol() {
+	case appctlpb.ProxyProtocol_HTTP_PROXY_PROTOCOL:
+		return s.dialHTTPUpstream(req, conn, proxy)
+	case appctlpb.ProxyProtocol_MIERU_PROXY_PROTOCOL:
+		mux, err := s.upstream.get(ctx, proxy, s.config.Resolver)
+		if err != nil {
+			return nil, err
+		}
+		proxyConn, err := mux.DialContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("mux DialContext() failed: %w", err)
+		}
+		// mieru server doesn't do socks5 authentication.
+		if _, err := proxyConn.Write(req.Raw); err != nil {
+			proxyConn.Close()
+			return nil, fmt.Errorf("failed to write socks5 request to egress proxy: %w", err)
+		}
+		return proxyConn, nil
+	default:
+		return s.dialSocks5Upstream(req, proxy)
+	}
+}
+
+// dialSocks5Upstream connects to the destination through a socks5 proxy.
+func (s *Server) dialSocks5Upstream(req *Request, proxy *appctlpb.EgressProxy) (net.Conn, error) {
+	proxyAddr := net.JoinHostPort(proxy.GetHost(), strconv.Itoa(int(proxy.GetPort())))
+	proxyConn, err := s.config.Outbound.dialer(proxyAddr).Dial("tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.socks5UpstreamAuth(proxyConn, proxy); err != nil {
+		proxyConn.Close()
+		return nil, err
+	}
+	if _, err := proxyConn.Write(req.Raw); err != nil {
+		proxyConn.Close()
+		return nil, fmt.Errorf("failed to write socks5 request to egress proxy: %w", err)
+	}
+	return proxyConn, nil
+}
+
+// socks5UpstreamAuth authenticates with the socks5 proxy. Username and
+// password authentication is used if the user of proxy is set.
+func (s *Server) socks5UpstreamAuth(proxyConn net.Conn, proxy *appctlpb.EgressProxy) error {
+	method := noAuth
+	if proxy.GetUser() != "" {
+		method = userPassAuth
+	}
+	if _, err := proxyConn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return fmt.Errorf("failed to write socks5 auth header to egress proxy: %w", err)
+	}
+	util.SetReadTimeout(proxyConn, s.config.HandshakeTimeout)
+	defer util.SetReadTimeout(proxyConn, 0)
+	resp := []byte{0, 0}
+	if _, err := io.ReadFull(proxyConn, resp); err != nil {
+		return fmt.Errorf("failed to read socks5 auth response from egress proxy: %w", err)
+	}
+	if resp[0] != socks5Version || resp[1] != method {
+		return fmt.Errorf("got unexpected socks5 auth response from egress proxy: %v", resp)
+	}
+	if method == noAuth {
+		return nil
+	}
+
+	user := []byte(proxy.GetUser())
+	password := []byte(proxy.GetPassword())
+	if len(user) > 255 || len(password) > 255 {
+		return fmt.Errorf("socks5 user or password of egress proxy is too long")
+	}
+	authReq := []byte{userPassAuthVersion, byte(len(user))}
+	authReq = append(authReq, user...)
+	authReq = append(authReq, byte(len(password)))
+	authReq = append(authReq, password...)
+	if _, err := proxyConn.Write(authReq); err != nil {
+		return fmt.Errorf("failed to write socks5 user and password to egress proxy: %w", err)
+	}
+	if _, err := io.ReadFull(proxyConn, resp); err != nil {
+		return fmt.Errorf("failed to read socks5 user and password response from egress proxy: %w", err)
+	}
+	if resp[0] != userPassAuthVersion || resp[1] != 0 {
+		return fmt.Errorf("socks5 user and password are rejected by egress proxy")
+	}
+	return nil
+}
+
+// dialHTTPUpstream connects to the destination through a HTTP proxy
+// with the CONNECT method.
+func (s *Server) dialHTTPUpstream(req *Request, conn net.Conn, proxy *appctlpb.EgressProxy) (net.Conn, error) {
+	if req.Command != connectCommand {
+		sendReply(conn, commandNotSupported, nil)
+		return nil, fmt.Errorf("socks5 command %d is not supported by HTTP egress proxy", req.Command)
+	}
+	proxyAddr := net.JoinHostPort(proxy.GetHost(), strconv.Itoa(int(proxy.GetPort())))
+	proxyConn, err := s.config.Outbound.dialer(proxyAddr).Dial("tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	host := req.DestAddr.FQDN
+	if host == "" {
+		host = req.DestAddr.IP.String()
+	}
+	target := net.JoinHostPort(host, strconv.Itoa(req.DestAddr.Port))
+	connectReq := "CONNECT " + target + " HTTP/1.1\r\nHost: " + target + "\r\n"
+	if proxy.GetUser() != "" {
+		credential := base64.StdEncoding.EncodeToString([]byte(proxy.GetUser() + ":" + proxy.GetPassword()))
+		connectReq += "Proxy-Authorization: Basic " + credential + "\r\n"
+	}
+	connectReq += "\r\n"
+	if _, err := proxyConn.Write([]byte(connectReq)); err != nil {
+		proxyConn.Close()
+		return nil, fmt.Errorf("failed to write HTTP CONNECT request to egress proxy: %w", err)
+	}
+
+	util.SetReadTimeout(proxyConn, s.config.HandshakeTimeout)
+	defer util.SetReadTimeout(proxyConn, 0)
+	reader := bufio.NewReader(proxyConn)
+	resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		proxyConn.Close()
+		return nil, fmt.Errorf("failed to read HTTP CONNECT response from egress proxy: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		proxyConn.Close()
+		sendReply(conn, hostUnreachable, nil)
+		return nil, fmt.Errorf("HTTP CONNECT is rejected by egress proxy: %s", resp.Status)
+	}
+	if err := sendReply(conn, successReply, nil); err != nil {
+		proxyConn.Close()
+		return nil, fmt.Errorf("failed to send reply: %w", err)
+	}
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: proxyConn, reader: reader}, nil
+	}
+	return proxyConn, nil
+}
+
+// bufferedConn returns the bytes already buffered by the reader
+// before reading from the network connection.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
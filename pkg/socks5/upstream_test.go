@@ -0,0 +1,247 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package socks5
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"github.com/enfein/mieru/pkg/egress"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDialHTTPUpstream(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			t.Errorf("Accept() failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			t.Errorf("http.ReadRequest() failed: %v", err)
+			return
+		}
+		if req.Method != http.MethodConnect || req.Host != "example.com:443" {
+			t.Errorf("got request %s %s, want CONNECT example.com:443", req.Method, req.Host)
+		}
+		if got := req.Header.Get("Proxy-Authorization"); got != "Basic dXNlcjpwYXNz" {
+			t.Errorf("Proxy-Authorization is %q", got)
+		}
+		// The data after the response must not be lost.
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\npong"))
+	}()
+
+	s, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	proxy := &appctlpb.EgressProxy{
+		Name:     proto.String("http"),
+		Protocol: appctlpb.ProxyProtocol_HTTP_PROXY_PROTOCOL.Enum(),
+		Host:     proto.String("127.0.0.1"),
+		Port:     proto.Int32(int32(l.Addr().(*net.TCPAddr).Port)),
+		User:     proto.String("user"),
+		Password: proto.String("pass"),
+	}
+	req := &Request{
+		Command:  connectCommand,
+		DestAddr: &AddrSpec{FQDN: "example.com", Port: 443},
+	}
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+	reply := make(chan []byte, 1)
+	go func() {
+		b := make([]byte, 10)
+		io.ReadFull(peer, b)
+		reply <- b
+	}()
+
+	proxyConn, err := s.dialHTTPUpstream(req, conn, proxy)
+	if err != nil {
+		t.Fatalf("dialHTTPUpstream() failed: %v", err)
+	}
+	defer proxyConn.Close()
+	want := []byte{socks5Version, successReply, 0, ipv4Address, 0, 0, 0, 0, 0, 0}
+	if got := <-reply; !bytes.Equal(got, want) {
+		t.Errorf("socks5 reply is %v, want %v", got, want)
+	}
+	proxyConn.SetReadDeadline(time.Now().Add(time.Second))
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(proxyConn, b); err != nil {
+		t.Fatalf("io.ReadFull() failed: %v", err)
+	}
+	if string(b) != "pong" {
+		t.Errorf("got %q, want %q", b, "pong")
+	}
+}
+
+func TestSocks5UpstreamAuth(t *testing.T) {
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+	go func() {
+		b := make([]byte, 3)
+		io.ReadFull(peer, b)
+		if !bytes.Equal(b, []byte{socks5Version, 1, userPassAuth}) {
+			t.Errorf("got auth header %v", b)
+		}
+		peer.Write([]byte{socks5Version, userPassAuth})
+		b = make([]byte, 11)
+		io.ReadFull(peer, b)
+		if !bytes.Equal(b, []byte("\x01\x04user\x04pass")) {
+			t.Errorf("got user and password %q", b)
+		}
+		peer.Write([]byte{userPassAuthVersion, 0})
+	}()
+
+	s, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	proxy := &appctlpb.EgressProxy{
+		User:     proto.String("user"),
+		Password: proto.String("pass"),
+	}
+	if err := s.socks5UpstreamAuth(conn, proxy); err != nil {
+		t.Errorf("socks5UpstreamAuth() failed: %v", err)
+	}
+}
+
+// proxyController forwards all the requests to the egress proxy.
+type proxyController struct {
+	proxy *appctlpb.EgressProxy
+}
+
+func (c proxyController) FindAction(in egress.Input) egress.Action {
+	return egress.Action{Action: appctlpb.EgressAction_PROXY, Proxy: c.proxy}
+}
+
+func TestForwardingEgressPolicy(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer l.Close()
+	accepted := make(chan struct{}, 4)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- struct{}{}
+			conn.Close()
+		}
+	}()
+	proxy := &appctlpb.EgressProxy{
+		Name:     proto.String("socks5"),
+		Protocol: appctlpb.ProxyProtocol_SOCKS5_PROXY_PROTOCOL.Enum(),
+		Host:     proto.String("127.0.0.1"),
+		Port:     proto.Int32(int32(l.Addr().(*net.TCPAddr).Port)),
+	}
+	// Request to connect to internal.example.com:443.
+	request := []byte{socks5Version, connectCommand, 0, fqdnAddress, 20}
+	request = append(request, "internal.example.com"...)
+	request = append(request, 1, 187)
+
+	testCases := []struct {
+		name   string
+		config *appctlpb.EgressPolicy
+		users  []*appctlpb.User
+		allow  bool
+	}{
+		{
+			name:  "allowed",
+			allow: true,
+		},
+		{
+			name:   "denied domain name",
+			config: &appctlpb.EgressPolicy{DeniedDomainNames: []string{"internal.example.com"}},
+		},
+		{
+			name:  "unknown user with user rules",
+			users: []*appctlpb.User{{Name: proto.String("web"), AllowedPorts: []string{"443"}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := egress.NewPolicy(tc.config, tc.users, false)
+			if err != nil {
+				t.Fatalf("NewPolicy() failed: %v", err)
+			}
+			s, err := New(&Config{
+				ClientSideAuthentication: true,
+				EgressController:         proxyController{proxy: proxy},
+				EgressPolicy:             policy,
+			})
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			conn, peer := net.Pipe()
+			defer peer.Close()
+			reply := make(chan []byte, 1)
+			go func() {
+				peer.Write(request)
+				b := make([]byte, 10)
+				peer.SetReadDeadline(time.Now().Add(time.Second))
+				n, _ := io.ReadFull(peer, b)
+				reply <- b[:n]
+			}()
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- s.serverServeConn(context.Background(), conn)
+				conn.Close()
+			}()
+
+			if tc.allow {
+				select {
+				case <-accepted:
+				case <-time.After(time.Second):
+					t.Fatalf("request is not forwarded to egress proxy")
+				}
+				<-errCh
+				return
+			}
+			want := []byte{socks5Version, ruleFailure, 0, ipv4Address, 0, 0, 0, 0, 0, 0}
+			if got := <-reply; !bytes.Equal(got, want) {
+				t.Errorf("socks5 reply is %v, want %v", got, want)
+			}
+			if err := <-errCh; err == nil {
+				t.Errorf("serverServeConn() succeeded, want error")
+			}
+			select {
+			case <-accepted:
+				t.Errorf("request denied by egress policy is forwarded to egress proxy")
+			default:
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package speedtest measures the throughput and round trip time between
+// the proxy client and the proxy server.
+//
+// The client opens a proxy connection to Host and Port. Instead of
+// connecting to the destination, the proxy server serves the speed test
+// protocol in the connection. Each request has a 1 byte command and an
+// 8 byte big endian argument.
+//
+//   - upload: the client sends argument bytes, then the server replies the
+//     number of bytes it received in 8 bytes.
+//   - download: the server sends argument bytes.
+//   - ping: repeat argument times, the client sends 8 bytes and the server
+//     echoes them back.
+package speedtest
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// Host is the destination host name of speed test requests.
+	// The top level domain "invalid" is reserved and never resolves.
+	Host = "speedtest.mieru.invalid"
+
+	// Port is the destination port of speed test requests.
+	Port = 9
+
+	// MaxBytes is the maximum number of bytes of an upload or download request.
+	MaxBytes = 1 << 30
+
+	// MaxPings is the maximum number of round trips of a ping request.
+	MaxPings = 1000
+
+	commandUpload   byte = 1
+	commandDownload byte = 2
+	commandPing     byte = 3
+
+	chunkSize = 16 * 1024
+)
+
+// Result is the result of an upload or download test.
+type Result struct {
+	Bytes    int64
+	Duration time.Duration
+}
+
+// BitsPerSecond returns the throughput.
+func (r Result) BitsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) * 8 / r.Duration.Seconds()
+}
+
+// String returns a human readable throughput.
+func (r Result) String() string {
+	return fmt.Sprintf("%.2f Mbps (%d bytes in %v)", r.BitsPerSecond()/1e6, r.Bytes, r.Duration.Round(time.Millisecond))
+}
+
+// PingResult is the result of a ping test.
+type PingResult struct {
+	Count int
+	Min   time.Duration
+	Avg   time.Duration
+	Max   time.Duration
+}
+
+// String returns a human readable round trip time.
+func (r PingResult) String() string {
+	return fmt.Sprintf("min %v, avg %v, max %v", r.Min.Round(time.Microsecond), r.Avg.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+}
+
+// Serve handles speed test requests in the connection until it is closed.
+func Serve(conn io.ReadWriter) error {
+	header := make([]byte, 9)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read speed test request: %w", err)
+		}
+		arg := binary.BigEndian.Uint64(header[1:])
+		switch header[0] {
+		case commandUpload:
+			if arg > MaxBytes {
+				return fmt.Errorf("upload size %d is too large", arg)
+			}
+			n, err := io.CopyN(io.Discard, conn, int64(arg))
+			if err != nil {
+				return fmt.Errorf("failed to receive upload data: %w", err)
+			}
+			reply := make([]byte, 8)
+			binary.BigEndian.PutUint64(reply, uint64(n))
+			if _, err := conn.Write(reply); err != nil {
+				return fmt.Errorf("failed to send upload result: %w", err)
+			}
+		case commandDownload:
+			if arg > MaxBytes {
+				return fmt.Errorf("download size %d is too large", arg)
+			}
+			if err := writeData(conn, int64(arg)); err != nil {
+				return fmt.Errorf("failed to send download data: %w", err)
+			}
+		case commandPing:
+			if arg > MaxPings {
+				return fmt.Errorf("ping count %d is too large", arg)
+			}
+			buf := make([]byte, 8)
+			for i := uint64(0); i < arg; i++ {
+				if _, err := io.ReadFull(conn, buf); err != nil {
+					return fmt.Errorf("failed to read ping: %w", err)
+				}
+				if _, err := conn.Write(buf); err != nil {
+					return fmt.Errorf("failed to write pong: %w", err)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown speed test command %d", header[0])
+		}
+	}
+}
+
+// Upload sends n bytes to the server and waits until the server has
+// received all of them.
+func Upload(conn io.ReadWriter, n int64) (Result, error) {
+	if n <= 0 || n > MaxBytes {
+		return Result{}, fmt.Errorf("upload size %d is out of range", n)
+	}
+	start := time.Now()
+	if err := writeRequest(conn, commandUpload, uint64(n)); err != nil {
+		return Result{}, err
+	}
+	if err := writeData(conn, n); err != nil {
+		return Result{}, fmt.Errorf("failed to send upload data: %w", err)
+	}
+	reply := make([]byte, 8)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return Result{}, fmt.Errorf("failed to read upload result: %w", err)
+	}
+	received := int64(binary.BigEndian.Uint64(reply))
+	if received != n {
+		return Result{}, fmt.Errorf("server received %d bytes, want %d", received, n)
+	}
+	return Result{Bytes: n, Duration: time.Since(start)}, nil
+}
+
+// Download receives n bytes from the server.
+func Download(conn io.ReadWriter, n int64) (Result, error) {
+	if n <= 0 || n > MaxBytes {
+		return Result{}, fmt.Errorf("download size %d is out of range", n)
+	}
+	start := time.Now()
+	if err := writeRequest(conn, commandDownload, uint64(n)); err != nil {
+		return Result{}, err
+	}
+	if _, err := io.CopyN(io.Discard, conn, n); err != nil {
+		return Result{}, fmt.Errorf("failed to receive download data: %w", err)
+	}
+	return Result{Bytes: n, Duration: time.Since(start)}, nil
+}
+
+// Ping measures the round trip time to the server count times.
+func Ping(conn io.ReadWriter, count int) (PingResult, error) {
+	if count <= 0 || count > MaxPings {
+		return PingResult{}, fmt.Errorf("ping count %d is out of range", count)
+	}
+	if err := writeRequest(conn, commandPing, uint64(count)); err != nil {
+		return PingResult{}, err
+	}
+	res := PingResult{Count: count}
+	var total time.Duration
+	buf := make([]byte, 8)
+	for i := 0; i < count; i++ {
+		start := time.Now()
+		binary.BigEndian.PutUint64(buf, uint64(start.UnixNano()))
+		if _, err := conn.Write(buf); err != nil {
+			return PingResult{}, fmt.Errorf("failed to write ping: %w", err)
+		}
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return PingResult{}, fmt.Errorf("failed to read pong: %w", err)
+		}
+		rtt := time.Since(start)
+		total += rtt
+		if i == 0 || rtt < res.Min {
+			res.Min = rtt
+		}
+		if rtt > res.Max {
+			res.Max = rtt
+		}
+	}
+	res.Avg = total / time.Duration(count)
+	return res, nil
+}
+
+func writeRequest(w io.Writer, command byte, arg uint64) error {
+	header := make([]byte, 9)
+	header[0] = command
+	binary.BigEndian.PutUint64(header[1:], arg)
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write speed test request: %w", err)
+	}
+	return nil
+}
+
+// writeData writes n bytes of random data.
+func writeData(w io.Writer, n int64) error {
+	chunk := make([]byte, chunkSize)
+	crand.Read(chunk)
+	for n > 0 {
+		size := int64(len(chunk))
+		if n < size {
+			size = n
+		}
+		if _, err := w.Write(chunk[:size]); err != nil {
+			return err
+		}
+		n -= size
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package speedtest
+
+import (
+	"net"
+	"testing"
+)
+
+func TestSpeedTest(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(server)
+		server.Close()
+	}()
+
+	ping, err := Ping(client, 10)
+	if err != nil {
+		t.Fatalf("Ping() failed: %v", err)
+	}
+	if ping.Count != 10 || ping.Min > ping.Avg || ping.Avg > ping.Max {
+		t.Errorf("unexpected ping result %+v", ping)
+	}
+
+	upload, err := Upload(client, 100000)
+	if err != nil {
+		t.Fatalf("Upload() failed: %v", err)
+	}
+	if upload.Bytes != 100000 || upload.BitsPerSecond() <= 0 {
+		t.Errorf("unexpected upload result %+v", upload)
+	}
+
+	download, err := Download(client, 100000)
+	if err != nil {
+		t.Fatalf("Download() failed: %v", err)
+	}
+	if download.Bytes != 100000 || download.BitsPerSecond() <= 0 {
+		t.Errorf("unexpected download result %+v", download)
+	}
+
+	client.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Serve() failed: %v", err)
+	}
+}
+
+func TestSpeedTestRejectLargeRequest(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(server)
+		server.Close()
+	}()
+
+	if err := writeRequest(client, commandDownload, MaxBytes+1); err != nil {
+		t.Fatalf("writeRequest() failed: %v", err)
+	}
+	if err := <-done; err == nil {
+		t.Errorf("Serve() accepted a download request larger than %d bytes", MaxBytes)
+	}
+}
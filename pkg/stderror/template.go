@@ -16,25 +16,40 @@
 package stderror
 
 const (
+	AddPortBindingFailedErr                 = "add port binding failed: %w"
+	BanIPFailedErr                          = "ban IP address failed: %w"
 	ClientConfigIsEmpty                     = "mieru client config is empty"
 	ClientConfigNotExist                    = "mieru client config file doesn't exist"
 	ClientGetActiveProfileFailedErr         = "mieru client get active profile failed: %w"
 	ClientNotRunning                        = "mieru client is not running"
 	ClientNotRunningErr                     = "mieru client is not running: %w"
+	ConnectivityTestFailedErr               = "connectivity test failed at step %q: %w"
 	CreateClientLifecycleRPCClientFailedErr = "create mieru client lifecycle RPC client failed: %w"
+	CreateEgressControllerFailedErr         = "create egress controller failed: %w"
 	CreateEmptyServerConfigFailedErr        = "create empty mieru server config file failed: %w"
 	CreateServerConfigRPCClientFailedErr    = "create mieru server config RPC client failed: %w"
 	CreateServerLifecycleRPCClientFailedErr = "create mieru server lifecycle RPC client failed: %w"
 	CreateSocks5ServerFailedErr             = "create socks5 server failed: %w"
 	DecodeHashedPasswordFailedErr           = "decode hashed password failed: %w"
+	DecryptLogFailedErr                     = "decrypt log failed: %w"
+	DeletePortBindingFailedErr              = "delete port binding failed: %w"
+	DropServerPrivilegesFailedErr           = "drop server privileges failed: %w"
 	ExitFailedErr                           = "process exit failed: %w"
+	GetAuditLogFailedErr                    = "get audit log failed: %w"
+	GetBannedIPsFailedErr                   = "get banned IP addresses failed: %w"
 	GetClientConfigFailedErr                = "get mieru client config failed: %w"
 	GetConnectionsFailedErr                 = "get connections failed: %w"
 	GetHeapProfileFailedErr                 = "get heap profile failed: %w"
+	GetLogsFailedErr                        = "get logs failed: %w"
 	GetMetricsFailedErr                     = "get metrics failed: %w"
 	GetServerConfigFailedErr                = "get mieru server config failed: %w"
 	GetServerStatusFailedErr                = "get mieru server status failed: %w"
 	GetThreadDumpFailedErr                  = "get thread dump failed: %w"
+	GetTopDestinationsFailedErr             = "get top destinations failed: %w"
+	GetUsersFailedErr                       = "get users failed: %w"
+	ImportClientConfigFailedErr             = "import mieru client config failed: %w"
+	InstallServiceFailedErr                 = "install service failed: %w"
+	InvalidDNSUpstreamErr                   = "invalid DNS upstream: %w"
 	InvalidPortBindingsErr                  = "invalid port bindings: %w"
 	InvalidTransportProtocol                = "invalid transport protocol"
 	LoadClientConfigFailedErr               = "load mieru client config failed: %w"
@@ -42,16 +57,26 @@ const (
 	LookupIPFailedErr                       = "look up IP address failed: %w"
 	ParseIPFailed                           = "parse IP address failed"
 	ReloadServerFailedErr                   = "reload mieru server failed: %w"
+	RestoreSystemProxyFailedErr             = "restore system proxy failed: %w"
 	SegmentSizeTooBig                       = "segment size too big"
 	ServerNotRunning                        = "mieru server daemon is not running"
 	ServerNotRunningErr                     = "mieru server daemon is not running: %w"
 	ServerProxyNotRunningErr                = "mieru server proxy is not running: %w"
+	SendServerMessageFailedErr              = "send server message failed: %w"
+	SetLoggingLevelFailedErr                = "set logging level failed: %w"
 	SetServerConfigFailedErr                = "set mieru server config failed: %w"
+	SetSessionTapFailedErr                  = "set session tap failed: %w"
+	SetSystemProxyFailedErr                 = "set system proxy failed: %w"
+	SpeedTestFailed                         = "speed test failed"
 	StartClientFailedErr                    = "start mieru client failed: %w"
 	StartCPUProfileFailedErr                = "start CPU profile failed: %w"
 	StartServerProxyFailedErr               = "start mieru server proxy failed: %w"
 	StopServerProxyFailedErr                = "stop mieru server proxy failed: %w"
 	StoreClientConfigFailedErr              = "store mieru client config failed: %w"
+	UnbanIPFailedErr                        = "unban IP address failed: %w"
+	UninstallServiceFailedErr               = "uninstall service failed: %w"
+	UpdateSubscriptionsFailedErr            = "update subscriptions failed: %w"
+	ValidateConfigFileFailedErr             = "validate config file failed: %w"
 	ValidateFullClientConfigFailedErr       = "validate full client config failed: %w"
 	ValidateServerConfigPatchFailedErr      = "validate server config patch failed: %w"
 )
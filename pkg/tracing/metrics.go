@@ -0,0 +1,38 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tracing
+
+import "github.com/enfein/mieru/pkg/metrics"
+
+const (
+	TracingMetricGroupName = "tracing"
+)
+
+var (
+	// Number of spans exported to the OTLP receiver.
+	ExportedSpans = metrics.RegisterMetric(TracingMetricGroupName, "ExportedSpans", metrics.COUNTER)
+
+	// Number of spans dropped because the export queue is full.
+	DroppedSpans = metrics.RegisterMetric(TracingMetricGroupName, "DroppedSpans", metrics.COUNTER)
+
+	// Number of failed export requests.
+	ExportErrors = metrics.RegisterMetric(TracingMetricGroupName, "ExportErrors", metrics.COUNTER)
+)
+
+func init() {
+	// Tracing metrics are shown after tracing is enabled.
+	metrics.GetMetricGroupByName(TracingMetricGroupName).DisableLogging()
+}
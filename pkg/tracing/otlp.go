@@ -0,0 +1,287 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+)
+
+const (
+	// exportInterval is the maximum time a span waits to be exported.
+	exportInterval = 5 * time.Second
+
+	// exportBatchSize is the number of spans that triggers an export.
+	exportBatchSize = 256
+
+	// exportQueueCapacity is the maximum number of spans waiting to be
+	// exported. New spans are dropped if the queue is full.
+	exportQueueCapacity = 4096
+
+	// exportTimeout is the timeout of a single export request.
+	exportTimeout = 10 * time.Second
+
+	// scopeName is the instrumentation scope of all the spans.
+	scopeName = "github.com/enfein/mieru"
+)
+
+// spanData is a completed span.
+type spanData struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    []Attribute
+	errMsg   string
+}
+
+// exporter sends spans to an OTLP HTTP receiver in JSON encoding.
+type exporter struct {
+	config Config
+	url    string
+	client *http.Client
+	queue  chan spanData
+	done   chan struct{}
+	exited chan struct{}
+}
+
+func newExporter(config Config) (*exporter, error) {
+	u, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: %w", config.Endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("OTLP endpoint %q must use http or https scheme", config.Endpoint)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("OTLP endpoint %q has no host", config.Endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	return &exporter{
+		config: config,
+		url:    u.String(),
+		client: &http.Client{Timeout: exportTimeout},
+		queue:  make(chan spanData, exportQueueCapacity),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}, nil
+}
+
+func (e *exporter) enqueue(data spanData) {
+	select {
+	case e.queue <- data:
+	default:
+		DroppedSpans.Add(1)
+	}
+}
+
+// stop flushes the remaining spans and stops the exporter.
+func (e *exporter) stop() {
+	close(e.done)
+	<-e.exited
+}
+
+func (e *exporter) loop() {
+	defer close(e.exited)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	batch := make([]spanData, 0, exportBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil {
+			ExportErrors.Add(1)
+			log.Debugf("export %d spans to %s failed: %v", len(batch), e.url, err)
+		} else {
+			ExportedSpans.Add(int64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case data := <-e.queue:
+			batch = append(batch, data)
+			if len(batch) >= exportBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.done:
+			for {
+				select {
+				case data := <-e.queue:
+					batch = append(batch, data)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *exporter) export(batch []spanData) error {
+	b, err := json.Marshal(e.request(batch))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("OTLP receiver returned HTTP status %s", resp.Status)
+	}
+	return nil
+}
+
+// The following types follow the JSON encoding of OTLP
+// ExportTraceServiceRequest message.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusCodeError  = 2
+)
+
+func (e *exporter) request(batch []spanData) otlpRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, data := range batch {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(data.traceID[:]),
+			SpanID:            hex.EncodeToString(data.spanID[:]),
+			Name:              data.name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(data.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(data.end.UnixNano(), 10),
+			Attributes:        toKeyValues(data.attrs),
+		}
+		if data.parentID != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(data.parentID[:])
+		}
+		if data.errMsg != "" {
+			span.Status = &otlpStatus{Code: otlpStatusCodeError, Message: data.errMsg}
+		}
+		spans = append(spans, span)
+	}
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: toKeyValues([]Attribute{String("service.name", e.config.ServiceName)}),
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: scopeName},
+						Spans: spans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func toKeyValues(attrs []Attribute) []otlpKeyValue {
+	res := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		kv := otlpKeyValue{Key: attr.Key}
+		switch v := attr.Value.(type) {
+		case string:
+			kv.Value.StringValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			kv.Value.IntValue = &s
+		case bool:
+			kv.Value.BoolValue = &v
+		default:
+			s := fmt.Sprint(v)
+			kv.Value.StringValue = &s
+		}
+		res = append(res, kv)
+	}
+	return res
+}
@@ -0,0 +1,238 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package tracing records spans of the session lifecycle and exports them
+// to an OpenTelemetry collector with OTLP over HTTP. Tracing is disabled
+// by default, and all the operations are no-op until it is enabled.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/enfein/mieru/pkg/log"
+	"github.com/enfein/mieru/pkg/metrics"
+)
+
+// Config is the configuration of tracing.
+type Config struct {
+	// Endpoint is the URL of OTLP HTTP receiver, e.g. "http://127.0.0.1:4318".
+	// If the path is empty, "/v1/traces" is used.
+	Endpoint string
+
+	// ServiceName is reported as the "service.name" resource attribute.
+	ServiceName string
+
+	// SampleRatio is the fraction of traces recorded, in the range of (0, 1].
+	// If it is not positive, all the traces are recorded.
+	SampleRatio float64
+}
+
+// Attribute is a key value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value any // string, int64 or bool
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int64 returns an integer attribute.
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is a timed operation. A nil span is valid and does nothing.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	sampled  bool
+	name     string
+	start    time.Time
+
+	mu     sync.Mutex
+	attrs  []Attribute
+	errMsg string
+	ended  bool
+}
+
+// TraceID returns the trace ID in hex format.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil || !s.sampled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// RecordError marks the span as failed if err is not nil.
+func (s *Span) RecordError(err error) {
+	if s == nil || !s.sampled || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errMsg = err.Error()
+}
+
+// End completes the span and sends it to the exporter.
+// Calling End more than once has no effect.
+func (s *Span) End() {
+	if s == nil || !s.sampled {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := spanData{
+		traceID:  s.traceID,
+		spanID:   s.spanID,
+		parentID: s.parentID,
+		name:     s.name,
+		start:    s.start,
+		end:      time.Now(),
+		attrs:    s.attrs,
+		errMsg:   s.errMsg,
+	}
+	s.mu.Unlock()
+	if e := exporterRef.Load(); e != nil {
+		e.enqueue(data)
+	}
+}
+
+// Carrier is implemented by connections that belong to a trace.
+type Carrier interface {
+	// TraceContext returns a context that carries the span of the connection.
+	TraceContext() context.Context
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a child context that carries the span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by the context, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextFrom returns the trace context of v if it is a Carrier.
+// Otherwise, it returns context.Background().
+func ContextFrom(v any) context.Context {
+	if c, ok := v.(Carrier); ok {
+		if ctx := c.TraceContext(); ctx != nil {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+// Enabled returns true if tracing is enabled.
+func Enabled() bool {
+	return exporterRef.Load() != nil
+}
+
+// Start creates a new span. If the context carries a span, the new span is
+// its child. The returned context carries the new span.
+// If tracing is disabled, the context is returned as is with a nil span.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	e := exporterRef.Load()
+	if e == nil {
+		return ctx, nil
+	}
+	span := &Span{
+		name:  name,
+		start: time.Now(),
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+		span.sampled = parent.sampled
+	} else {
+		mrand.Read(span.traceID[:])
+		span.sampled = e.config.SampleRatio <= 0 || mrand.Float64() < e.config.SampleRatio
+	}
+	mrand.Read(span.spanID[:])
+	if span.sampled {
+		span.attrs = append(span.attrs, attrs...)
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+var (
+	exporterRef atomic.Pointer[exporter]
+	exporterMu  sync.Mutex
+)
+
+// Enable starts exporting spans. If tracing is already enabled,
+// the previous exporter is stopped.
+func Enable(config Config) error {
+	if config.SampleRatio > 1 {
+		return fmt.Errorf("sample ratio %v is greater than 1", config.SampleRatio)
+	}
+	e, err := newExporter(config)
+	if err != nil {
+		return err
+	}
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+	if old := exporterRef.Swap(e); old != nil {
+		old.stop()
+	}
+	go e.loop()
+	metrics.GetMetricGroupByName(TracingMetricGroupName).EnableLogging()
+	log.Infof("enabled exporting traces to %s", e.url)
+	return nil
+}
+
+// Disable stops exporting spans. Spans not yet exported are flushed.
+func Disable() {
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+	if old := exporterRef.Swap(nil); old != nil {
+		old.stop()
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestStartWhenDisabled(t *testing.T) {
+	Disable()
+	ctx := context.Background()
+	newCtx, span := Start(ctx, "test")
+	if span != nil {
+		t.Errorf("got a span when tracing is disabled")
+	}
+	if newCtx != ctx {
+		t.Errorf("context is changed when tracing is disabled")
+	}
+	// Operations on nil span must not panic.
+	span.SetAttributes(String("key", "value"))
+	span.RecordError(errors.New("error"))
+	span.End()
+}
+
+func TestExportSpans(t *testing.T) {
+	var mu sync.Mutex
+	var received []otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("got request path %q, want %q", r.URL.Path, "/v1/traces")
+		}
+		b, _ := io.ReadAll(r.Body)
+		var req otlpRequest
+		if err := json.Unmarshal(b, &req); err != nil {
+			t.Errorf("json.Unmarshal() failed: %v", err)
+		}
+		mu.Lock()
+		received = append(received, req)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	if err := Enable(Config{Endpoint: server.URL, ServiceName: "test"}); err != nil {
+		t.Fatalf("Enable() failed: %v", err)
+	}
+	ctx, parent := Start(context.Background(), "parent", Int64("id", 1))
+	_, child := Start(ctx, "child")
+	child.RecordError(errors.New("dial failed"))
+	child.End()
+	parent.End()
+	parent.End()
+	Disable()
+
+	mu.Lock()
+	defer mu.Unlock()
+	spans := map[string]otlpSpan{}
+	for _, req := range received {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					spans[span.Name] = span
+				}
+			}
+		}
+	}
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	p, c := spans["parent"], spans["child"]
+	if p.TraceID != c.TraceID {
+		t.Errorf("parent and child have different trace IDs")
+	}
+	if c.ParentSpanID != p.SpanID {
+		t.Errorf("child parent span ID = %q, want %q", c.ParentSpanID, p.SpanID)
+	}
+	if p.ParentSpanID != "" {
+		t.Errorf("root span has parent span ID %q", p.ParentSpanID)
+	}
+	if c.Status == nil || c.Status.Code != otlpStatusCodeError {
+		t.Errorf("child span status is not error")
+	}
+	if len(p.Attributes) != 1 || p.Attributes[0].Key != "id" || p.Attributes[0].Value.IntValue == nil || *p.Attributes[0].Value.IntValue != "1" {
+		t.Errorf("unexpected parent span attributes %+v", p.Attributes)
+	}
+}
+
+func TestEnableRejectsInvalidConfig(t *testing.T) {
+	for _, config := range []Config{
+		{Endpoint: "127.0.0.1:4318"},
+		{Endpoint: "ftp://127.0.0.1:4318"},
+		{Endpoint: "http://127.0.0.1:4318", SampleRatio: 2},
+	} {
+		if err := Enable(config); err == nil {
+			Disable()
+			t.Errorf("Enable(%+v) succeeded, want error", config)
+		}
+	}
+}
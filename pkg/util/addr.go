@@ -15,7 +15,10 @@
 
 package util
 
-import "net"
+import (
+	"fmt"
+	"net"
+)
 
 // NetAddr implements net.Addr interface.
 type NetAddr struct {
@@ -40,3 +43,19 @@ func NilNetAddr() net.Addr {
 func IsNilNetAddr(addr net.Addr) bool {
 	return addr == nil || (addr.Network() == "" && addr.String() == "")
 }
+
+// ParseIPRange parses an IP range in CIDR format like "192.0.2.0/24",
+// or a single IP address like "192.0.2.1", which is a range of one address.
+func ParseIPRange(s string) (*net.IPNet, error) {
+	if _, ipNet, err := net.ParseCIDR(s); err == nil {
+		return ipNet, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is neither an IP address nor an IP range", s)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
@@ -37,3 +37,30 @@ func TestIsNilNetAddr(t *testing.T) {
 		}
 	}
 }
+
+func TestParseIPRange(t *testing.T) {
+	testcases := []struct {
+		input string
+		want  string
+	}{
+		{"192.0.2.0/24", "192.0.2.0/24"},
+		{"192.0.2.77/24", "192.0.2.0/24"},
+		{"192.0.2.1", "192.0.2.1/32"},
+		{"2001:db8::/32", "2001:db8::/32"},
+		{"2001:db8::1", "2001:db8::1/128"},
+	}
+	for _, tc := range testcases {
+		got, err := ParseIPRange(tc.input)
+		if err != nil {
+			t.Fatalf("ParseIPRange(%q) failed: %v", tc.input, err)
+		}
+		if got.String() != tc.want {
+			t.Errorf("ParseIPRange(%q) = %v, want %s", tc.input, got, tc.want)
+		}
+	}
+	for _, input := range []string{"", "example.com", "192.0.2.0/33"} {
+		if _, err := ParseIPRange(input); err == nil {
+			t.Errorf("ParseIPRange(%q) succeeded, want error", input)
+		}
+	}
+}
@@ -17,18 +17,25 @@ package util
 
 import (
 	"io"
+	"net"
 )
 
+// PassthroughConn is implemented by a connection wrapper that doesn't
+// transform the data read from or written to the wrapped connection.
+type PassthroughConn interface {
+	PassthroughConn() net.Conn
+}
+
 // BidiCopy does bi-directional data copy.
 func BidiCopy(conn1, conn2 io.ReadWriteCloser) error {
 	errCh := make(chan error, 2)
 	go func() {
-		_, err := io.Copy(conn1, conn2)
+		_, err := copyConn(conn1, conn2)
 		conn1.Close()
 		errCh <- err
 	}()
 	go func() {
-		_, err := io.Copy(conn2, conn1)
+		_, err := copyConn(conn2, conn1)
 		conn2.Close()
 		errCh <- err
 	}()
@@ -37,3 +44,29 @@ func BidiCopy(conn1, conn2 io.ReadWriteCloser) error {
 	<-errCh
 	return err
 }
+
+// copyConn copies data from src to dst. If both of them are TCP connections,
+// possibly wrapped by PassthroughConn, data is copied within the kernel
+// (with splice on Linux) without going through user space.
+func copyConn(dst io.Writer, src io.Reader) (int64, error) {
+	if dstTCP, ok := unwrapTCPConn(dst); ok {
+		if srcTCP, ok := unwrapTCPConn(src); ok {
+			return dstTCP.ReadFrom(srcTCP)
+		}
+	}
+	return io.Copy(dst, src)
+}
+
+// unwrapTCPConn returns the TCP connection wrapped by PassthroughConn.
+func unwrapTCPConn(v any) (*net.TCPConn, bool) {
+	for {
+		switch c := v.(type) {
+		case *net.TCPConn:
+			return c, true
+		case PassthroughConn:
+			v = c.PassthroughConn()
+		default:
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2022  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func tcpPair(t *testing.T) (*net.TCPConn, *net.TCPConn) {
+	t.Helper()
+	listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatalf("net.ListenTCP() failed: %v", err)
+	}
+	defer listener.Close()
+	client, err := net.DialTCP("tcp", nil, listener.Addr().(*net.TCPAddr))
+	if err != nil {
+		t.Fatalf("net.DialTCP() failed: %v", err)
+	}
+	server, err := listener.AcceptTCP()
+	if err != nil {
+		t.Fatalf("AcceptTCP() failed: %v", err)
+	}
+	return client, server
+}
+
+func TestUnwrapTCPConn(t *testing.T) {
+	client, server := tcpPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	wrapped := WrapHierarchyConn(WrapHierarchyConn(client))
+	if c, ok := unwrapTCPConn(wrapped); !ok || c != client {
+		t.Errorf("unwrapTCPConn() = %v, %v, want %v, true", c, ok, client)
+	}
+	if _, ok := unwrapTCPConn(counterCloser{Conn: client}); ok {
+		t.Errorf("unwrapTCPConn() succeeded with a connection that is not PassthroughConn")
+	}
+}
+
+func TestCopyConn(t *testing.T) {
+	srcClient, srcServer := tcpPair(t)
+	defer srcServer.Close()
+	dstClient, dstServer := tcpPair(t)
+	defer dstServer.Close()
+
+	data := bytes.Repeat([]byte("mieru"), 1<<16)
+	go func() {
+		srcClient.Write(data)
+		srcClient.Close()
+	}()
+	go func() {
+		copyConn(WrapHierarchyConn(dstClient), WrapHierarchyConn(srcServer))
+		dstClient.Close()
+	}()
+
+	got, err := io.ReadAll(dstServer)
+	if err != nil {
+		t.Fatalf("io.ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("received %d bytes, want %d bytes", len(got), len(data))
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
+	"time"
 )
 
 type DNSPolicy uint8
@@ -45,6 +47,37 @@ func (p DNSPolicy) String() string {
 // DNSResolver uses Golang's default DNS implementation to resolve host names.
 type DNSResolver struct {
 	DNSPolicy DNSPolicy
+
+	// Cache stores the lookup results if it is not nil.
+	Cache *DNSCache
+
+	// Upstreams are encrypted DNS servers to send DNS queries.
+	// If empty, DNS servers of the operating system are used.
+	Upstreams []DNSUpstream
+
+	// Overrides change how specific domain names are resolved.
+	// The first matched override is used.
+	Overrides []DNSOverride
+}
+
+// DNSOverride resolves a domain name and its subdomains to a fixed
+// IP address, or with different DNS upstreams.
+type DNSOverride struct {
+	Domain string
+
+	// IP is the lookup result if it is not nil.
+	IP net.IP
+
+	// Upstreams are used to send DNS queries if IP is nil.
+	// If empty, DNS servers of the operating system are used.
+	Upstreams []DNSUpstream
+}
+
+// match returns true if host is the domain name or its subdomain.
+func (o DNSOverride) match(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	domain := strings.ToLower(strings.TrimSuffix(o.Domain, "."))
+	return host == domain || strings.HasSuffix(host, "."+domain)
 }
 
 // LookupIP looks up host for the given network using the DNS resolver.
@@ -56,12 +89,53 @@ func (d *DNSResolver) LookupIP(ctx context.Context, host string) (net.IP, error)
 	case DNSPolicyIPv6Only:
 		network = "ip6"
 	}
-	ips, err := net.DefaultResolver.LookupIP(ctx, network, host)
+	upstreams := d.Upstreams
+	for _, o := range d.Overrides {
+		if !o.match(host) {
+			continue
+		}
+		if o.IP != nil {
+			if (network == "ip4" && o.IP.To4() == nil) || (network == "ip6" && o.IP.To4() != nil) {
+				return nil, fmt.Errorf("IP address %v of %s doesn't match DNS policy %v", o.IP, host, d.DNSPolicy)
+			}
+			return o.IP, nil
+		}
+		upstreams = o.Upstreams
+		break
+	}
+	if d.Cache == nil {
+		var ips []net.IP
+		var err error
+		if len(upstreams) == 0 {
+			ips, err = net.DefaultResolver.LookupIP(ctx, network, host)
+		} else {
+			ips, _, err = lookupIPWithTTL(ctx, network, host, upstreams)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("lookup IP from %s returned no result", host)
+		}
+		return ips[0], nil
+	}
+
+	if ip, err, found := d.Cache.get(network, host, time.Now()); found {
+		return ip, err
+	}
+	ips, ttl, err := lookupIPWithTTL(ctx, network, host, upstreams)
 	if err != nil {
+		// Don't cache the error if the lookup is canceled.
+		if ctx.Err() == nil {
+			d.Cache.put(network, host, nil, 0, err, time.Now())
+		}
 		return nil, err
 	}
 	if len(ips) == 0 {
-		return nil, fmt.Errorf("lookup IP from %s returned no result", host)
+		err = fmt.Errorf("lookup IP from %s returned no result", host)
+		d.Cache.put(network, host, nil, 0, err, time.Now())
+		return nil, err
 	}
+	d.Cache.put(network, host, ips[0], ttl, nil, time.Now())
 	return ips[0], nil
 }
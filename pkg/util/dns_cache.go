@@ -0,0 +1,266 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// DefaultDNSCacheMaxTTL is the default maximum time to cache
+	// a successful DNS lookup.
+	DefaultDNSCacheMaxTTL = 5 * time.Minute
+
+	// DefaultDNSCacheNegativeTTL is the default time to cache
+	// a failed DNS lookup.
+	DefaultDNSCacheNegativeTTL = 10 * time.Second
+
+	// maxDNSCacheEntries is the maximum number of entries in a DNS cache.
+	maxDNSCacheEntries = 4096
+)
+
+// DNSCache stores the results of DNS lookups.
+// A successful lookup is cached until the TTL of the DNS records expires,
+// but no longer than MaxTTL. If the TTL is unknown, for example, the result
+// is from the hosts file or the system resolver, MaxTTL is used.
+// A failed lookup is cached for NegativeTTL.
+type DNSCache struct {
+	// MaxTTL is the maximum time to cache a successful lookup.
+	// Successful lookups are not cached if MaxTTL is 0 or negative.
+	MaxTTL time.Duration
+
+	// NegativeTTL is the time to cache a failed lookup.
+	// Failed lookups are not cached if NegativeTTL is 0 or negative.
+	NegativeTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[dnsCacheKey]dnsCacheEntry
+}
+
+type dnsCacheKey struct {
+	network string
+	host    string
+}
+
+type dnsCacheEntry struct {
+	ip     net.IP
+	err    error
+	expire time.Time
+}
+
+// NewDNSCache creates a new DNS cache with default TTLs.
+func NewDNSCache() *DNSCache {
+	return &DNSCache{
+		MaxTTL:      DefaultDNSCacheMaxTTL,
+		NegativeTTL: DefaultDNSCacheNegativeTTL,
+	}
+}
+
+// Len returns the number of entries in the cache, including expired entries.
+func (c *DNSCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// get returns the cached lookup result. The last return value is false
+// if the result is not found or expired.
+func (c *DNSCache) get(network, host string, now time.Time) (net.IP, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := dnsCacheKey{network: network, host: host}
+	entry, found := c.entries[key]
+	if !found {
+		return nil, nil, false
+	}
+	if !now.Before(entry.expire) {
+		delete(c.entries, key)
+		return nil, nil, false
+	}
+	return entry.ip, entry.err, true
+}
+
+// put stores the lookup result. ttl is the TTL of DNS records,
+// or 0 if it is unknown.
+func (c *DNSCache) put(network, host string, ip net.IP, ttl time.Duration, err error, now time.Time) {
+	var d time.Duration
+	if err != nil {
+		d = c.NegativeTTL
+	} else {
+		d = c.MaxTTL
+		if ttl > 0 && ttl < d {
+			d = ttl
+		}
+	}
+	if d <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[dnsCacheKey]dnsCacheEntry)
+	}
+	if len(c.entries) >= maxDNSCacheEntries {
+		for k, v := range c.entries {
+			if !now.Before(v.expire) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= maxDNSCacheEntries {
+		// Randomly evict one entry.
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[dnsCacheKey{network: network, host: host}] = dnsCacheEntry{
+		ip:     ip,
+		err:    err,
+		expire: now.Add(d),
+	}
+}
+
+// lookupIPWithTTL looks up host and returns the IP addresses with the
+// minimum TTL of the DNS records. The returned TTL is 0 if it is unknown.
+// If upstreams is not empty, DNS queries are sent to the upstreams.
+func lookupIPWithTTL(ctx context.Context, network, host string, upstreams []DNSUpstream) ([]net.IP, time.Duration, error) {
+	recorder := &dnsTTLRecorder{upstreams: upstreams}
+	resolver := &net.Resolver{Dial: recorder.dial}
+	if len(upstreams) > 0 {
+		// Only Go's DNS resolver can use the upstreams.
+		resolver.PreferGo = true
+	}
+	ips, err := resolver.LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, 0, err
+	}
+	return ips, recorder.ttl(), nil
+}
+
+// dnsTTLRecorder records the minimum TTL of A and AAAA records
+// from DNS responses received by Go's DNS resolver.
+type dnsTTLRecorder struct {
+	mu        sync.Mutex
+	minTTL    uint32
+	found     bool
+	upstreams []DNSUpstream
+}
+
+func (r *dnsTTLRecorder) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if len(r.upstreams) > 0 {
+		// Ignore the DNS server address from the operating system.
+		return &dnsTTLStreamConn{Conn: newDNSUpstreamConn(ctx, r.upstreams), recorder: r}, nil
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if udpConn, ok := conn.(*net.UDPConn); ok && strings.HasPrefix(network, "udp") {
+		// Go's DNS resolver checks if the connection is a net.PacketConn.
+		return &dnsTTLPacketConn{UDPConn: udpConn, recorder: r}, nil
+	}
+	return &dnsTTLStreamConn{Conn: conn, recorder: r}, nil
+}
+
+func (r *dnsTTLRecorder) ttl() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.found {
+		return 0
+	}
+	return time.Duration(r.minTTL) * time.Second
+}
+
+// record parses a DNS response message and records the TTL.
+func (r *dnsTTLRecorder) record(msg []byte) {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil || !header.Response || header.RCode != dnsmessage.RCodeSuccess {
+		return
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return
+	}
+	for {
+		h, err := p.AnswerHeader()
+		if err != nil {
+			if !errors.Is(err, dnsmessage.ErrSectionDone) {
+				return
+			}
+			break
+		}
+		if h.Type == dnsmessage.TypeA || h.Type == dnsmessage.TypeAAAA {
+			r.mu.Lock()
+			if !r.found || h.TTL < r.minTTL {
+				r.minTTL = h.TTL
+				r.found = true
+			}
+			r.mu.Unlock()
+		}
+		if err := p.SkipAnswer(); err != nil {
+			return
+		}
+	}
+}
+
+// dnsTTLPacketConn records the TTL of DNS responses received from UDP.
+type dnsTTLPacketConn struct {
+	*net.UDPConn
+	recorder *dnsTTLRecorder
+}
+
+func (c *dnsTTLPacketConn) Read(b []byte) (int, error) {
+	n, err := c.UDPConn.Read(b)
+	if n > 0 {
+		c.recorder.record(b[:n])
+	}
+	return n, err
+}
+
+// dnsTTLStreamConn records the TTL of DNS responses received from TCP.
+// Each DNS message is prefixed with a 2 bytes length.
+type dnsTTLStreamConn struct {
+	net.Conn
+	recorder *dnsTTLRecorder
+	buf      []byte
+}
+
+func (c *dnsTTLStreamConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.buf = append(c.buf, b[:n]...)
+		for len(c.buf) >= 2 {
+			l := int(binary.BigEndian.Uint16(c.buf))
+			if len(c.buf) < 2+l {
+				break
+			}
+			c.recorder.record(c.buf[2 : 2+l])
+			c.buf = c.buf[2+l:]
+		}
+	}
+	return n, err
+}
@@ -17,7 +17,15 @@ package util
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
 )
 
 func TestDNSResolver(t *testing.T) {
@@ -56,3 +64,233 @@ func TestDNSResolver(t *testing.T) {
 		}
 	}
 }
+
+func TestDNSCache(t *testing.T) {
+	c := NewDNSCache()
+	now := time.Now()
+	ip := net.ParseIP("1.2.3.4")
+
+	// TTL of DNS records is respected.
+	c.put("ip", "a.example.com", ip, 30*time.Second, nil, now)
+	if got, err, found := c.get("ip", "a.example.com", now.Add(29*time.Second)); !found || err != nil || !got.Equal(ip) {
+		t.Errorf("get() = %v, %v, %v, want %v, nil, true", got, err, found, ip)
+	}
+	if _, _, found := c.get("ip", "a.example.com", now.Add(30*time.Second)); found {
+		t.Errorf("cache entry is not expired")
+	}
+
+	// Unknown or large TTL is capped by MaxTTL.
+	c.put("ip", "b.example.com", ip, 0, nil, now)
+	c.put("ip", "c.example.com", ip, time.Hour, nil, now)
+	for _, host := range []string{"b.example.com", "c.example.com"} {
+		if _, _, found := c.get("ip", host, now.Add(c.MaxTTL-time.Second)); !found {
+			t.Errorf("cache entry of %s is not found", host)
+		}
+		if _, _, found := c.get("ip", host, now.Add(c.MaxTTL)); found {
+			t.Errorf("cache entry of %s is not expired", host)
+		}
+	}
+
+	// Negative caching.
+	lookupErr := errors.New("no such host")
+	c.put("ip4", "d.example.com", nil, 0, lookupErr, now)
+	if _, err, found := c.get("ip4", "d.example.com", now); !found || err != lookupErr {
+		t.Errorf("failed lookup is not cached")
+	}
+	if _, _, found := c.get("ip6", "d.example.com", now); found {
+		t.Errorf("cache entry of a different network is found")
+	}
+	if _, _, found := c.get("ip4", "d.example.com", now.Add(c.NegativeTTL)); found {
+		t.Errorf("negative cache entry is not expired")
+	}
+}
+
+func TestDNSTTLRecorder(t *testing.T) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true, Authoritative: true})
+	b.EnableCompression()
+	name := dnsmessage.MustNewName("example.com.")
+	if err := b.StartQuestions(); err != nil {
+		t.Fatalf("StartQuestions() failed: %v", err)
+	}
+	if err := b.Question(dnsmessage.Question{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}); err != nil {
+		t.Fatalf("Question() failed: %v", err)
+	}
+	if err := b.StartAnswers(); err != nil {
+		t.Fatalf("StartAnswers() failed: %v", err)
+	}
+	for _, ttl := range []uint32{300, 60} {
+		h := dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl}
+		if err := b.AResource(h, dnsmessage.AResource{A: [4]byte{1, 2, 3, 4}}); err != nil {
+			t.Fatalf("AResource() failed: %v", err)
+		}
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		t.Fatalf("Finish() failed: %v", err)
+	}
+
+	// Response from a stream is split into multiple reads.
+	r := &dnsTTLRecorder{}
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		stream := append([]byte{byte(len(msg) >> 8), byte(len(msg))}, msg...)
+		for i := 0; i < len(stream); i += 7 {
+			end := i + 7
+			if end > len(stream) {
+				end = len(stream)
+			}
+			server.Write(stream[i:end])
+		}
+		server.Close()
+	}()
+	conn := &dnsTTLStreamConn{Conn: client, recorder: r}
+	buf := make([]byte, 4)
+	for {
+		if _, err := conn.Read(buf); err != nil {
+			break
+		}
+	}
+	if got := r.ttl(); got != 60*time.Second {
+		t.Errorf("ttl() = %v, want %v", got, 60*time.Second)
+	}
+}
+
+func TestDNSResolverWithDoHUpstream(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var p dnsmessage.Parser
+		header, err := p.Start(query)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		q, err := p.Question()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, RecursionAvailable: true})
+		b.StartQuestions()
+		b.Question(q)
+		b.StartAnswers()
+		if q.Type == dnsmessage.TypeA {
+			b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 120}, dnsmessage.AResource{A: [4]byte{1, 2, 3, 4}})
+		}
+		resp, err := b.Finish()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(resp)
+	}))
+	defer server.Close()
+
+	upstream, err := NewDNSUpstream(server.URL + "/dns-query")
+	if err != nil {
+		t.Fatalf("NewDNSUpstream() failed: %v", err)
+	}
+	upstream.(*dohUpstream).client = server.Client()
+	d := &DNSResolver{
+		DNSPolicy: DNSPolicyIPv4Only,
+		Cache:     NewDNSCache(),
+		Upstreams: []DNSUpstream{upstream},
+	}
+	ip, err := d.LookupIP(context.Background(), "mieru.example.com")
+	if err != nil {
+		t.Fatalf("LookupIP() failed: %v", err)
+	}
+	if !ip.Equal(net.IPv4(1, 2, 3, 4)) {
+		t.Errorf("LookupIP() = %v, want 1.2.3.4", ip)
+	}
+	if _, _, found := d.Cache.get("ip4", "mieru.example.com", time.Now().Add(119*time.Second)); !found {
+		t.Errorf("lookup result is not cached")
+	}
+	if _, _, found := d.Cache.get("ip4", "mieru.example.com", time.Now().Add(121*time.Second)); found {
+		t.Errorf("TTL of DNS record is not respected")
+	}
+}
+
+func TestNewDNSUpstream(t *testing.T) {
+	valid := []string{"https://1.1.1.1/dns-query", "tls://1.1.1.1", "tls://dns.google:853"}
+	for _, u := range valid {
+		if _, err := NewDNSUpstream(u); err != nil {
+			t.Errorf("NewDNSUpstream(%q) failed: %v", u, err)
+		}
+	}
+	invalid := []string{"", "udp://1.1.1.1", "https:///dns-query", "tls://1.1.1.1/dns-query"}
+	for _, u := range invalid {
+		if _, err := NewDNSUpstream(u); err == nil {
+			t.Errorf("NewDNSUpstream(%q) returned no error", u)
+		}
+	}
+}
+
+// fakeDNSUpstream answers every A query with the same IP address.
+type fakeDNSUpstream struct {
+	ip [4]byte
+}
+
+func (f *fakeDNSUpstream) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, RecursionAvailable: true})
+	b.StartQuestions()
+	b.Question(q)
+	b.StartAnswers()
+	if q.Type == dnsmessage.TypeA {
+		b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60}, dnsmessage.AResource{A: f.ip})
+	}
+	return b.Finish()
+}
+
+func (f *fakeDNSUpstream) String() string {
+	return "fake"
+}
+
+func TestDNSResolverOverride(t *testing.T) {
+	d := &DNSResolver{
+		DNSPolicy: DNSPolicyIPv4Only,
+		Cache:     NewDNSCache(),
+		Upstreams: []DNSUpstream{&fakeDNSUpstream{ip: [4]byte{1, 1, 1, 1}}},
+		Overrides: []DNSOverride{
+			{Domain: "fixed.example.com", IP: net.ParseIP("10.0.0.1")},
+			{Domain: "example.com", Upstreams: []DNSUpstream{&fakeDNSUpstream{ip: [4]byte{2, 2, 2, 2}}}},
+			{Domain: "v6.example.org", IP: net.ParseIP("2001:db8::1")},
+		},
+	}
+	testCases := []struct {
+		host string
+		want net.IP
+	}{
+		{"fixed.example.com", net.IPv4(10, 0, 0, 1)},
+		{"a.fixed.example.com", net.IPv4(10, 0, 0, 1)},
+		{"www.example.com", net.IPv4(2, 2, 2, 2)},
+		{"example.com", net.IPv4(2, 2, 2, 2)},
+		{"notexample.com", net.IPv4(1, 1, 1, 1)},
+	}
+	for _, tc := range testCases {
+		ip, err := d.LookupIP(context.Background(), tc.host)
+		if err != nil {
+			t.Fatalf("LookupIP(%q) failed: %v", tc.host, err)
+		}
+		if !ip.Equal(tc.want) {
+			t.Errorf("LookupIP(%q) = %v, want %v", tc.host, ip, tc.want)
+		}
+	}
+	if _, err := d.LookupIP(context.Background(), "v6.example.org"); err == nil {
+		t.Errorf("LookupIP() returned IPv6 address with IPv4 only policy")
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// dnsUpstreamTimeout is the timeout of one DNS exchange with an upstream,
+	// if the context doesn't have a deadline.
+	dnsUpstreamTimeout = 10 * time.Second
+
+	// maxDNSMessageSize is the maximum size of a DNS message over TCP.
+	maxDNSMessageSize = 65535
+)
+
+// DNSUpstream is an encrypted DNS server.
+type DNSUpstream interface {
+	// Exchange sends a DNS query message and returns the response message.
+	Exchange(ctx context.Context, query []byte) ([]byte, error)
+
+	// String returns the URL of the upstream.
+	String() string
+}
+
+// NewDNSUpstream creates a DNS upstream from a URL. Supported URLs are
+//
+// "https://<HOST>[:<PORT>]/<PATH>" for DNS over HTTPS (RFC 8484), and
+//
+// "tls://<HOST>[:<PORT>]" for DNS over TLS (RFC 7858). The default port is 853.
+//
+// If the host is a domain name, it is resolved by the operating system.
+func NewDNSUpstream(rawURL string) (DNSUpstream, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse() failed: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("DNS upstream %q has no host", rawURL)
+	}
+	switch u.Scheme {
+	case "https":
+		return &dohUpstream{
+			url: u.String(),
+			client: &http.Client{
+				Timeout: dnsUpstreamTimeout,
+			},
+		}, nil
+	case "tls":
+		if u.Path != "" && u.Path != "/" {
+			return nil, fmt.Errorf("DNS over TLS upstream %q can't have a path", rawURL)
+		}
+		port := u.Port()
+		if port == "" {
+			port = "853"
+		}
+		return &dotUpstream{
+			url:        rawURL,
+			addr:       net.JoinHostPort(u.Hostname(), port),
+			serverName: u.Hostname(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("DNS upstream %q has unsupported scheme %q", rawURL, u.Scheme)
+	}
+}
+
+// dohUpstream is a DNS over HTTPS server.
+type dohUpstream struct {
+	url    string
+	client *http.Client
+}
+
+func (d *dohUpstream) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(query))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext() failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request to %s failed: %w", d.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP request to %s returned status code %d", d.url, resp.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxDNSMessageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll() failed: %w", err)
+	}
+	if len(b) > maxDNSMessageSize {
+		return nil, fmt.Errorf("DNS response from %s is too large", d.url)
+	}
+	return b, nil
+}
+
+func (d *dohUpstream) String() string {
+	return d.url
+}
+
+// dotUpstream is a DNS over TLS server.
+type dotUpstream struct {
+	url        string
+	addr       string
+	serverName string
+}
+
+func (d *dotUpstream) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	if len(query) > maxDNSMessageSize {
+		return nil, fmt.Errorf("DNS query is too large")
+	}
+	dialer := &tls.Dialer{
+		Config: &tls.Config{ServerName: d.serverName},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", d.addr)
+	if err != nil {
+		return nil, fmt.Errorf("TLS dial to %s failed: %w", d.addr, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	b := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(b, uint16(len(query)))
+	copy(b[2:], query)
+	if _, err := conn.Write(b); err != nil {
+		return nil, fmt.Errorf("Write() to %s failed: %w", d.addr, err)
+	}
+	if _, err := io.ReadFull(conn, b[:2]); err != nil {
+		return nil, fmt.Errorf("read DNS response length from %s failed: %w", d.addr, err)
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(b[:2]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, fmt.Errorf("read DNS response from %s failed: %w", d.addr, err)
+	}
+	return resp, nil
+}
+
+func (d *dotUpstream) String() string {
+	return d.url
+}
+
+// dnsUpstreamConn is a net.Conn used by Go's DNS resolver. It receives
+// DNS queries in TCP format, and forwards them to the upstreams.
+// Upstreams are tried in order until one of them responds.
+type dnsUpstreamConn struct {
+	ctx       context.Context
+	upstreams []DNSUpstream
+
+	mu     sync.Mutex
+	in     []byte // received bytes of DNS queries
+	out    []byte // DNS responses to read
+	closed bool
+}
+
+var _ net.Conn = &dnsUpstreamConn{}
+
+func newDNSUpstreamConn(ctx context.Context, upstreams []DNSUpstream) *dnsUpstreamConn {
+	return &dnsUpstreamConn{
+		ctx:       ctx,
+		upstreams: upstreams,
+	}
+}
+
+func (c *dnsUpstreamConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	c.in = append(c.in, b...)
+	for len(c.in) >= 2 {
+		l := int(binary.BigEndian.Uint16(c.in))
+		if len(c.in) < 2+l {
+			break
+		}
+		query := c.in[2 : 2+l]
+		c.in = c.in[2+l:]
+		resp, err := c.exchange(query)
+		if err != nil {
+			return 0, err
+		}
+		framed := make([]byte, 2+len(resp))
+		binary.BigEndian.PutUint16(framed, uint16(len(resp)))
+		copy(framed[2:], resp)
+		c.out = append(c.out, framed...)
+	}
+	return len(b), nil
+}
+
+func (c *dnsUpstreamConn) Read(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	if len(c.out) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, c.out)
+	c.out = c.out[n:]
+	return n, nil
+}
+
+func (c *dnsUpstreamConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *dnsUpstreamConn) LocalAddr() net.Addr {
+	return NilNetAddr()
+}
+
+func (c *dnsUpstreamConn) RemoteAddr() net.Addr {
+	return NilNetAddr()
+}
+
+// SetDeadline is not supported. The deadline of the dial context is used.
+func (c *dnsUpstreamConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+// SetReadDeadline is not supported. The deadline of the dial context is used.
+func (c *dnsUpstreamConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline is not supported. The deadline of the dial context is used.
+func (c *dnsUpstreamConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// exchange sends the query to upstreams in order and returns the first response.
+func (c *dnsUpstreamConn) exchange(query []byte) ([]byte, error) {
+	var errs []error
+	for _, upstream := range c.upstreams {
+		ctx := c.ctx
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, dnsUpstreamTimeout)
+			defer cancel()
+		}
+		resp, err := upstream.Exchange(ctx, query)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, err)
+		if c.ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
@@ -16,6 +16,7 @@
 package util
 
 import (
+	"io"
 	"net"
 	"sync"
 )
@@ -44,6 +45,21 @@ func (h *hierarchyConnImpl) Close() error {
 	return h.Conn.Close()
 }
 
+// WriteTo uses the io.WriterTo implementation of the wrapped connection
+// if it exists, so io.Copy doesn't need an intermediate buffer.
+func (h *hierarchyConnImpl) WriteTo(w io.Writer) (int64, error) {
+	if wt, ok := h.Conn.(io.WriterTo); ok {
+		return wt.WriteTo(w)
+	}
+	return io.Copy(w, h.Conn)
+}
+
+// PassthroughConn returns the wrapped connection.
+// HierarchyConn doesn't transform the data read or written.
+func (h *hierarchyConnImpl) PassthroughConn() net.Conn {
+	return h.Conn
+}
+
 func (h *hierarchyConnImpl) AddSubConnection(conn net.Conn) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -0,0 +1,53 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package keychain stores small secrets in the credential store
+// provided by the operating system.
+//
+// In Mac OS, the secrets are stored in the login keychain.
+//
+// In Linux, the secrets are stored by the secret service, such as
+// GNOME Keyring or KWallet. The secret-tool command must be installed.
+//
+// In Windows, the secrets are protected by DPAPI and stored in the
+// user's application data directory.
+package keychain
+
+import "errors"
+
+var (
+	// ErrNotFound is returned if the secret doesn't exist.
+	ErrNotFound = errors.New("secret is not found in keychain")
+
+	// ErrUnsupported is returned if the operating system
+	// doesn't provide a supported credential store.
+	ErrUnsupported = errors.New("keychain is not supported by this operating system")
+)
+
+// Get returns the secret identified by the service and account.
+func Get(service, account string) ([]byte, error) {
+	return get(service, account)
+}
+
+// Set creates or replaces the secret identified by the service and account.
+func Set(service, account string, secret []byte) error {
+	return set(service, account, secret)
+}
+
+// Delete removes the secret identified by the service and account.
+// It is not an error if the secret doesn't exist.
+func Delete(service, account string) error {
+	return del(service, account)
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build darwin && !ios
+
+package keychain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The secret is hex encoded because the security command
+// reads and prints it as a string.
+
+func get(service, account string) ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 44 {
+			// errSecItemNotFound
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("security find-generic-password failed: %w", err)
+	}
+	secret, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString() failed: %w", err)
+	}
+	return secret, nil
+}
+
+// set runs the security command in interactive mode and writes the command
+// to stdin, so the secret doesn't appear in the command line arguments that
+// can be read by other processes. The interactive mode doesn't report the
+// result with exit code, so the item is read back to verify it.
+func set(service, account string, secret []byte) error {
+	if strings.ContainsAny(service+account, " \t\r\n\"'\\") {
+		return fmt.Errorf("service %q or account %q contains unsupported characters", service, account)
+	}
+	encoded := hex.EncodeToString(secret)
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", service, account, encoded))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("security add-generic-password failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	got, err := get(service, account)
+	if err != nil {
+		return fmt.Errorf("security add-generic-password failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if hex.EncodeToString(got) != encoded {
+		return fmt.Errorf("security add-generic-password failed: secret is not saved: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func del(service, account string) error {
+	out, err := exec.Command("security", "delete-generic-password", "-s", service, "-a", account).CombinedOutput()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 44 {
+			return nil
+		}
+		return fmt.Errorf("security delete-generic-password failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
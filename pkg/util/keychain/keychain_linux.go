@@ -0,0 +1,66 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build linux && !android
+
+package keychain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The secret is hex encoded because secret-tool
+// reads and prints it as a string.
+
+func get(service, account string) ([]byte, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) == 0 {
+			// secret-tool exits with 1 and prints nothing if the secret is not found.
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("secret-tool lookup failed: %w", err)
+	}
+	secret, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString() failed: %w", err)
+	}
+	return secret, nil
+}
+
+func set(service, account string, secret []byte) error {
+	cmd := exec.Command("secret-tool", "store", "--label="+service+" "+account, "service", service, "account", account)
+	cmd.Stdin = bytes.NewBufferString(hex.EncodeToString(secret))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func del(service, account string) error {
+	out, err := exec.Command("secret-tool", "clear", "service", service, "account", account).CombinedOutput()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(bytes.TrimSpace(out)) == 0 && exitErr.ExitCode() == 1 {
+			// Nothing is removed.
+			return nil
+		}
+		return fmt.Errorf("secret-tool clear failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build windows
+
+package keychain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// secretFilePath returns the file that stores the DPAPI protected secret.
+func secretFilePath(service, account string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString([]byte(service)) + "_" + hex.EncodeToString([]byte(account))
+	return filepath.Join(dir, "mieru", "keychain", name), nil
+}
+
+func newBlob(b []byte) *windows.DataBlob {
+	if len(b) == 0 {
+		return &windows.DataBlob{}
+	}
+	return &windows.DataBlob{Size: uint32(len(b)), Data: &b[0]}
+}
+
+// blobBytes copies the data of a blob allocated by Windows and frees it.
+func blobBytes(blob *windows.DataBlob) []byte {
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(blob.Data)))
+	res := make([]byte, blob.Size)
+	copy(res, unsafe.Slice(blob.Data, blob.Size))
+	return res
+}
+
+func get(service, account string) ([]byte, error) {
+	path, err := secretFilePath(service, account)
+	if err != nil {
+		return nil, fmt.Errorf("secretFilePath() failed: %w", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("os.ReadFile(%q) failed: %w", path, err)
+	}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(newBlob(b), nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("CryptUnprotectData() failed: %w", err)
+	}
+	return blobBytes(&out), nil
+}
+
+func set(service, account string, secret []byte) error {
+	path, err := secretFilePath(service, account)
+	if err != nil {
+		return fmt.Errorf("secretFilePath() failed: %w", err)
+	}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(newBlob(secret), nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return fmt.Errorf("CryptProtectData() failed: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("os.MkdirAll() failed: %w", err)
+	}
+	if err := os.WriteFile(path, blobBytes(&out), 0600); err != nil {
+		return fmt.Errorf("os.WriteFile(%q) failed: %w", path, err)
+	}
+	return nil
+}
+
+func del(service, account string) error {
+	path, err := secretFilePath(service, account)
+	if err != nil {
+		return fmt.Errorf("secretFilePath() failed: %w", err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("os.Remove(%q) failed: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// minRateLimiterBurst is the minimum number of bytes that can be consumed
+// at once without waiting.
+const minRateLimiterBurst = 16 * 1024
+
+// RateLimiter is a token bucket that limits the number of bytes per second.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter that allows
+// the given number of bytes per second.
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	l := &RateLimiter{last: time.Now()}
+	l.SetRate(bytesPerSecond)
+	l.tokens = l.burst
+	return l
+}
+
+// SetRate changes the number of bytes allowed per second.
+func (l *RateLimiter) SetRate(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		bytesPerSecond = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = float64(bytesPerSecond)
+	l.burst = l.rate
+	if l.burst < minRateLimiterBurst {
+		l.burst = minRateLimiterBurst
+	}
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// Rate returns the number of bytes allowed per second.
+func (l *RateLimiter) Rate() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int64(l.rate)
+}
+
+// LastUsed returns the last time bytes are consumed.
+func (l *RateLimiter) LastUsed() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last
+}
+
+// Reserve consumes n bytes, and returns the duration the caller
+// must wait before using them.
+func (l *RateLimiter) Reserve(n int) time.Duration {
+	return l.reserveAt(n, time.Now())
+}
+
+// Wait consumes n bytes, and blocks until they can be used.
+func (l *RateLimiter) Wait(n int) {
+	if d := l.Reserve(n); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+func (l *RateLimiter) reserveAt(n int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens += elapsed * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+	}
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
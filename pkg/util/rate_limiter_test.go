@@ -0,0 +1,57 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(100 * 1024)
+	now := l.last
+
+	// The initial burst is available without waiting.
+	if d := l.reserveAt(100*1024, now); d != 0 {
+		t.Errorf("reserveAt() = %v, want 0", d)
+	}
+	// The next 50 KiB needs to wait half a second.
+	if d := l.reserveAt(50*1024, now); d != 500*time.Millisecond {
+		t.Errorf("reserveAt() = %v, want %v", d, 500*time.Millisecond)
+	}
+	// After 1.5 seconds the bucket is refilled.
+	now = now.Add(1500 * time.Millisecond)
+	if d := l.reserveAt(100*1024, now); d != 0 {
+		t.Errorf("reserveAt() = %v, want 0", d)
+	}
+
+	// Tokens don't exceed the burst.
+	now = now.Add(time.Hour)
+	if d := l.reserveAt(200*1024, now); d != time.Second {
+		t.Errorf("reserveAt() = %v, want %v", d, time.Second)
+	}
+}
+
+func TestRateLimiterMinBurst(t *testing.T) {
+	l := NewRateLimiter(1024)
+	if d := l.reserveAt(minRateLimiterBurst, l.last); d != 0 {
+		t.Errorf("reserveAt() = %v, want 0", d)
+	}
+	l.SetRate(2048)
+	if got := l.Rate(); got != 2048 {
+		t.Errorf("Rate() = %d, want 2048", got)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build android || linux
+
+package sockopts
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// BindToDevice sets SO_BINDTODEVICE option to a given connection,
+// so the packets are sent and received from the network interface.
+func BindToDevice(device string) Control {
+	return func(network, address string, conn syscall.RawConn) error {
+		var err error
+		conn.Control(func(fd uintptr) { err = BindToDeviceRawErr(device)(fd) })
+		return err
+	}
+}
+
+func BindToDeviceRaw(device string) RawControl {
+	return func(fd uintptr) {
+		BindToDeviceRawErr(device)(fd)
+	}
+}
+
+func BindToDeviceRawErr(device string) RawControlErr {
+	return func(fd uintptr) error {
+		return unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, device)
+	}
+}
@@ -31,6 +31,21 @@ type RawControl = func(fd uintptr)
 // RawControlErr returns an error with RawControl.
 type RawControlErr = func(fd uintptr) error
 
+// IPv6SourcePreference controls the source address selected by the
+// operating system when a socket sends IPv6 packets.
+type IPv6SourcePreference int
+
+const (
+	// IPv6SourceDefault uses the default preference of the operating system.
+	IPv6SourceDefault IPv6SourcePreference = iota
+
+	// IPv6SourceTemporary prefers temporary (privacy) addresses.
+	IPv6SourceTemporary
+
+	// IPv6SourcePublic prefers stable public addresses.
+	IPv6SourcePublic
+)
+
 // Append returns a Control function that chains next after prev.
 func Append(prev, next Control) Control {
 	if prev == nil {
@@ -72,3 +87,17 @@ func ApplyUDPControls(conn *net.UDPConn) error {
 	}
 	return nil
 }
+
+// ApplyIPv6SourcePreference applies the IPv6 source address preference
+// to the UDP connection.
+func ApplyIPv6SourcePreference(conn *net.UDPConn, pref IPv6SourcePreference) error {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("SyscallConn() failed: %w", err)
+	}
+	var controlErr error
+	if err := rawConn.Control(func(fd uintptr) { controlErr = IPv6SourcePreferenceRawErr(pref)(fd) }); err != nil {
+		return err
+	}
+	return controlErr
+}
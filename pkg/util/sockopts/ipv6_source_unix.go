@@ -0,0 +1,68 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build android || linux
+
+package sockopts
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Values of IPV6_ADDR_PREFERENCES socket option defined in linux/in6.h.
+const (
+	ipv6PreferSrcTmp    = 0x0001
+	ipv6PreferSrcPublic = 0x0002
+)
+
+// IPv6SourcePreferenceControl sets IPV6_ADDR_PREFERENCES option to a given
+// connection. It does nothing if the connection is not an IPv6 socket.
+func IPv6SourcePreferenceControl(pref IPv6SourcePreference) Control {
+	return func(network, address string, conn syscall.RawConn) error {
+		var err error
+		conn.Control(func(fd uintptr) { err = IPv6SourcePreferenceRawErr(pref)(fd) })
+		return err
+	}
+}
+
+func IPv6SourcePreferenceRaw(pref IPv6SourcePreference) RawControl {
+	return func(fd uintptr) {
+		IPv6SourcePreferenceRawErr(pref)(fd)
+	}
+}
+
+func IPv6SourcePreferenceRawErr(pref IPv6SourcePreference) RawControlErr {
+	return func(fd uintptr) error {
+		var value int
+		switch pref {
+		case IPv6SourceTemporary:
+			value = ipv6PreferSrcTmp
+		case IPv6SourcePublic:
+			value = ipv6PreferSrcPublic
+		default:
+			return nil
+		}
+		sa, err := unix.Getsockname(int(fd))
+		if err != nil {
+			return err
+		}
+		if _, ok := sa.(*unix.SockaddrInet6); !ok {
+			return nil
+		}
+		return unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_ADDR_PREFERENCES, value)
+	}
+}
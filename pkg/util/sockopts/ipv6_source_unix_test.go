@@ -0,0 +1,107 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build linux
+
+package sockopts
+
+import (
+	"net"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// getIPv6AddrPreferences returns the IPV6_ADDR_PREFERENCES option of the socket.
+func getIPv6AddrPreferences(t *testing.T, rawConn syscall.RawConn) int {
+	t.Helper()
+	var value int
+	var err error
+	if ctrlErr := rawConn.Control(func(fd uintptr) {
+		value, err = unix.GetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_ADDR_PREFERENCES)
+	}); ctrlErr != nil {
+		t.Fatalf("Control() failed: %v", ctrlErr)
+	}
+	if err != nil {
+		t.Fatalf("GetsockoptInt() failed: %v", err)
+	}
+	return value
+}
+
+func TestApplyIPv6SourcePreference(t *testing.T) {
+	testCases := []struct {
+		pref IPv6SourcePreference
+		want int
+	}{
+		{IPv6SourceTemporary, ipv6PreferSrcTmp},
+		{IPv6SourcePublic, ipv6PreferSrcPublic},
+	}
+	for _, tc := range testCases {
+		conn, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+		if err != nil {
+			t.Skipf("IPv6 is not available: %v", err)
+		}
+		rawConn, err := conn.SyscallConn()
+		if err != nil {
+			t.Fatalf("SyscallConn() failed: %v", err)
+		}
+		before := getIPv6AddrPreferences(t, rawConn)
+		if err := ApplyIPv6SourcePreference(conn, IPv6SourceDefault); err != nil {
+			t.Errorf("ApplyIPv6SourcePreference(IPv6SourceDefault) failed: %v", err)
+		}
+		if got := getIPv6AddrPreferences(t, rawConn); got != before {
+			t.Errorf("IPV6_ADDR_PREFERENCES = %#x after IPv6SourceDefault, want %#x", got, before)
+		}
+		if err := ApplyIPv6SourcePreference(conn, tc.pref); err != nil {
+			t.Errorf("ApplyIPv6SourcePreference(%d) failed: %v", tc.pref, err)
+		}
+		if got := getIPv6AddrPreferences(t, rawConn); got&tc.want == 0 {
+			t.Errorf("IPV6_ADDR_PREFERENCES = %#x after preference %d, want bit %#x", got, tc.pref, tc.want)
+		}
+		conn.Close()
+	}
+
+	// The preference is ignored by IPv4 sockets.
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("net.ListenUDP() failed: %v", err)
+	}
+	defer conn.Close()
+	if err := ApplyIPv6SourcePreference(conn, IPv6SourcePublic); err != nil {
+		t.Errorf("ApplyIPv6SourcePreference() on IPv4 socket failed: %v", err)
+	}
+}
+
+func TestIPv6SourcePreferenceControl(t *testing.T) {
+	l, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 is not available: %v", err)
+	}
+	defer l.Close()
+	dialer := net.Dialer{Control: IPv6SourcePreferenceControl(IPv6SourceTemporary)}
+	conn, err := dialer.Dial("tcp6", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	defer conn.Close()
+	rawConn, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatalf("SyscallConn() failed: %v", err)
+	}
+	if got := getIPv6AddrPreferences(t, rawConn); got&ipv6PreferSrcTmp == 0 {
+		t.Errorf("IPV6_ADDR_PREFERENCES = %#x, want bit %#x", got, ipv6PreferSrcTmp)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sockopts
+
+// SocketOwner is the local process that owns a socket.
+type SocketOwner struct {
+	// UID is the user ID of the socket.
+	UID int
+
+	// CgroupPath is the cgroup v2 path of the process that owns the socket.
+	// It is empty if the cgroup is not looked up or not found.
+	CgroupPath string
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build android || linux
+
+package sockopts
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// procRoot is the mount point of procfs.
+const procRoot = "/proc"
+
+// nativeEndian is the byte order used by the kernel to print
+// IP addresses in /proc/net/tcp and /proc/net/tcp6.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// TCPSocketOwner returns the owner of the local TCP socket connected from
+// the local address to the remote address. The owner is read from procfs.
+// If lookupCgroup is true, the cgroup of the process that owns the socket
+// is also returned. It scans the file descriptors of all processes, which
+// requires the CAP_SYS_PTRACE capability to read processes of other users.
+func TCPSocketOwner(local, remote *net.TCPAddr, lookupCgroup bool) (*SocketOwner, error) {
+	var uid int
+	var inode uint64
+	var found bool
+	for _, name := range []string{"tcp", "tcp6"} {
+		f, err := os.Open(filepath.Join(procRoot, "net", name))
+		if err != nil {
+			// tcp6 doesn't exist if IPv6 is disabled.
+			continue
+		}
+		uid, inode, found, err = findTCPSocket(f, local, remote)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read /proc/net/%s: %w", name, err)
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("TCP socket from %v to %v is not found", local, remote)
+	}
+	owner := &SocketOwner{UID: uid}
+	if lookupCgroup {
+		if pid, ok := socketProcess(inode); ok {
+			owner.CgroupPath = processCgroup(pid)
+		}
+	}
+	return owner, nil
+}
+
+// findTCPSocket returns the user ID and the inode of the TCP socket
+// in the format of /proc/net/tcp.
+func findTCPSocket(r io.Reader, local, remote *net.TCPAddr) (uid int, inode uint64, found bool, err error) {
+	scanner := bufio.NewScanner(r)
+	header := true
+	for scanner.Scan() {
+		if header {
+			header = false
+			continue
+		}
+		// The fields are "sl local_address rem_address st tx_queue:rx_queue
+		// tr:tm->when retrnsmt uid timeout inode ...".
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		inode, err = strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			// Sockets in TIME_WAIT state don't have an owner.
+			continue
+		}
+		if !procNetAddrEqual(fields[1], local) || !procNetAddrEqual(fields[2], remote) {
+			continue
+		}
+		uid, err = strconv.Atoi(fields[7])
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("invalid uid %q", fields[7])
+		}
+		return uid, inode, true, nil
+	}
+	return 0, 0, false, scanner.Err()
+}
+
+// procNetAddrEqual returns true if the address in the format of
+// /proc/net/tcp is the same as the TCP address. The IP address is
+// printed as 32 bit integers in native byte order, and the port is
+// printed as a 16 bit integer.
+func procNetAddrEqual(s string, addr *net.TCPAddr) bool {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return false
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil || int(port) != addr.Port {
+		return false
+	}
+	b, err := hex.DecodeString(hexIP)
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return false
+	}
+	ip := make(net.IP, len(b))
+	for i := 0; i < len(b); i += 4 {
+		nativeEndian.PutUint32(ip[i:], binary.BigEndian.Uint32(b[i:]))
+	}
+	return ip.Equal(addr.IP)
+}
+
+// socketProcess returns the ID of a process that has the socket inode.
+func socketProcess(inode uint64) (int, bool) {
+	target := "socket:[" + strconv.FormatUint(inode, 10) + "]"
+	procs, err := os.ReadDir(procRoot)
+	if err != nil {
+		return 0, false
+	}
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procRoot, proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if link, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && link == target {
+				return pid, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// processCgroup returns the cgroup v2 path of the process.
+// It returns an empty string if the path is not found.
+func processCgroup(pid int) string {
+	f, err := os.Open(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// cgroup v2 hierarchy is in the format of "0::<PATH>".
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return path
+		}
+	}
+	return ""
+}
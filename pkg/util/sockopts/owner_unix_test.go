@@ -0,0 +1,79 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build linux
+
+package sockopts
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestTCPSocketOwner(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() failed: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			buf := make([]byte, 1)
+			conn.Read(buf)
+		}
+	}()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	local := conn.LocalAddr().(*net.TCPAddr)
+	remote := conn.RemoteAddr().(*net.TCPAddr)
+	owner, err := TCPSocketOwner(local, remote, true)
+	if err != nil {
+		t.Fatalf("TCPSocketOwner() failed: %v", err)
+	}
+	if owner.UID != os.Getuid() {
+		t.Errorf("got uid %d, want %d", owner.UID, os.Getuid())
+	}
+	if want := processCgroup(os.Getpid()); owner.CgroupPath != want {
+		t.Errorf("got cgroup path %q, want %q", owner.CgroupPath, want)
+	}
+
+	// The socket accepted by the listener is in the reversed direction.
+	if _, err := TCPSocketOwner(&net.TCPAddr{IP: local.IP, Port: local.Port + 1}, remote, false); err == nil {
+		t.Errorf("TCPSocketOwner() returned no error for a socket that doesn't exist")
+	}
+}
+
+func TestProcNetAddrEqual(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+	// The kernel prints the IP address in memory as a 32 bit integer.
+	s := fmt.Sprintf("%08X:%04X", nativeEndian.Uint32(addr.IP.To4()), addr.Port)
+	if !procNetAddrEqual(s, addr) {
+		t.Errorf("procNetAddrEqual(%q, %v) = false, want true", s, addr)
+	}
+	if procNetAddrEqual(s, &net.TCPAddr{IP: addr.IP, Port: 8081}) {
+		t.Errorf("procNetAddrEqual(%q) matched a different port", s)
+	}
+	if procNetAddrEqual("invalid", addr) {
+		t.Errorf("procNetAddrEqual() matched an invalid address")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build android || linux
+
+package sockopts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// soOriginalDst is the SO_ORIGINAL_DST socket option of netfilter.
+// IP6T_SO_ORIGINAL_DST has the same value.
+const soOriginalDst = 80
+
+// Transparent sets IP_TRANSPARENT and IPV6_TRANSPARENT options to a given
+// listener, so it can accept connections diverted by iptables TPROXY target.
+func Transparent() Control {
+	return func(network, address string, conn syscall.RawConn) error {
+		var err error
+		conn.Control(func(fd uintptr) {
+			if network != "tcp6" && network != "udp6" {
+				if err = unix.SetsockoptInt(int(fd), unix.SOL_IP, unix.IP_TRANSPARENT, 1); err != nil {
+					err = fmt.Errorf("set IP_TRANSPARENT failed: %w", err)
+					return
+				}
+			}
+			if network != "tcp4" && network != "udp4" {
+				if err = unix.SetsockoptInt(int(fd), unix.SOL_IPV6, unix.IPV6_TRANSPARENT, 1); err != nil {
+					err = fmt.Errorf("set IPV6_TRANSPARENT failed: %w", err)
+				}
+			}
+		})
+		return err
+	}
+}
+
+// OriginalDestination returns the destination of a TCP connection
+// before it is diverted by iptables REDIRECT target.
+func OriginalDestination(conn *net.TCPConn) (*net.TCPAddr, error) {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return nil, fmt.Errorf("SyscallConn() failed: %w", err)
+	}
+	isIPv4 := true
+	if localAddr, ok := conn.LocalAddr().(*net.TCPAddr); ok && localAddr.IP.To4() == nil {
+		isIPv4 = false
+	}
+	var addr *net.TCPAddr
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		if isIPv4 {
+			// The result is a sockaddr_in structure.
+			var mreq *unix.IPv6Mreq
+			mreq, sockErr = unix.GetsockoptIPv6Mreq(int(fd), unix.SOL_IP, soOriginalDst)
+			if sockErr != nil {
+				return
+			}
+			b := mreq.Multiaddr
+			addr = &net.TCPAddr{
+				IP:   net.IPv4(b[4], b[5], b[6], b[7]),
+				Port: int(binary.BigEndian.Uint16(b[2:4])),
+			}
+			return
+		}
+		// The result is a sockaddr_in6 structure.
+		var info *unix.IPv6MTUInfo
+		info, sockErr = unix.GetsockoptIPv6MTUInfo(int(fd), unix.SOL_IPV6, soOriginalDst)
+		if sockErr != nil {
+			return
+		}
+		// The port is in network byte order.
+		port := (*[2]byte)(unsafe.Pointer(&info.Addr.Port))
+		addr = &net.TCPAddr{
+			IP:   append(net.IP(nil), info.Addr.Addr[:]...),
+			Port: int(binary.BigEndian.Uint16(port[:])),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sockErr != nil {
+		return nil, fmt.Errorf("get SO_ORIGINAL_DST failed: %w", sockErr)
+	}
+	return addr, nil
+}
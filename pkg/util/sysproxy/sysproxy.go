@@ -0,0 +1,84 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package sysproxy changes the proxy settings of the operating system.
+//
+// In Mac OS, the proxies of all enabled network services are changed
+// by the networksetup command.
+//
+// In Linux, the proxy settings of GNOME are changed by the gsettings command.
+//
+// In Windows, the WinINET proxy settings of the current user are changed.
+// WinINET uses SOCKS version 4, so only the HTTP proxy is supported.
+package sysproxy
+
+import (
+	"errors"
+	"net"
+	"strconv"
+)
+
+var (
+	// ErrUnsupported is returned if changing the proxy settings
+	// is not supported by this operating system.
+	ErrUnsupported = errors.New("system proxy is not supported by this operating system")
+
+	// ErrNoHTTPProxy is returned if the operating system
+	// requires a HTTP proxy, but it is not provided.
+	ErrNoHTTPProxy = errors.New("system proxy of this operating system requires a HTTP proxy")
+)
+
+// Settings are the proxies set to the operating system.
+type Settings struct {
+	// HTTPAddr is the "host:port" address of the HTTP proxy,
+	// used by both HTTP and HTTPS. If empty, HTTP proxy is disabled.
+	HTTPAddr string
+
+	// SOCKS5Addr is the "host:port" address of the socks5 proxy.
+	// If empty, socks5 proxy is disabled.
+	SOCKS5Addr string
+}
+
+// Backup holds the proxy settings of the operating system before
+// they are changed. The keys and values are platform specific.
+type Backup map[string]string
+
+// Set changes the proxy settings of the operating system.
+// It returns the settings before the change, which are used by Restore.
+func Set(s Settings) (Backup, error) {
+	return set(s)
+}
+
+// Restore changes the proxy settings of the operating system back
+// to the backup returned by Set.
+func Restore(b Backup) error {
+	return restore(b)
+}
+
+// splitAddr returns the host and port of a "host:port" address.
+// It returns empty strings if the address is empty.
+func splitAddr(addr string) (host, port string, err error) {
+	if addr == "" {
+		return "", "", nil
+	}
+	host, port, err = net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return "", "", err
+	}
+	return host, port, nil
+}
@@ -0,0 +1,163 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build darwin && !ios
+
+package sysproxy
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// proxyKind is a kind of proxy of networksetup command.
+type proxyKind struct {
+	name  string
+	get   string
+	set   string
+	state string
+}
+
+var proxyKinds = []proxyKind{
+	{"web", "-getwebproxy", "-setwebproxy", "-setwebproxystate"},
+	{"secureweb", "-getsecurewebproxy", "-setsecurewebproxy", "-setsecurewebproxystate"},
+	{"socks", "-getsocksfirewallproxy", "-setsocksfirewallproxy", "-setsocksfirewallproxystate"},
+}
+
+func networksetup(args ...string) (string, error) {
+	out, err := exec.Command("networksetup", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("networksetup %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// networkServices returns the enabled network services.
+func networkServices() ([]string, error) {
+	out, err := networksetup("-listallnetworkservices")
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	for i, line := range strings.Split(out, "\n") {
+		// The first line is a note. Disabled services start with an asterisk.
+		line = strings.TrimSpace(line)
+		if i == 0 || line == "" || strings.HasPrefix(line, "*") {
+			continue
+		}
+		services = append(services, line)
+	}
+	return services, nil
+}
+
+// parseProxy returns the state, server and port printed by
+// networksetup -getwebproxy and similar commands.
+func parseProxy(out string) (state, server, port string) {
+	state = "off"
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Enabled":
+			if value == "Yes" {
+				state = "on"
+			}
+		case "Server":
+			server = value
+		case "Port":
+			port = value
+		}
+	}
+	return state, server, port
+}
+
+func set(s Settings) (Backup, error) {
+	addrs := map[string]string{
+		"web":       s.HTTPAddr,
+		"secureweb": s.HTTPAddr,
+		"socks":     s.SOCKS5Addr,
+	}
+	services, err := networkServices()
+	if err != nil {
+		return nil, err
+	}
+	backup := Backup{}
+	for _, service := range services {
+		for _, kind := range proxyKinds {
+			out, err := networksetup(kind.get, service)
+			if err != nil {
+				return nil, err
+			}
+			state, server, port := parseProxy(out)
+			backup[kind.name+"/"+service] = strings.Join([]string{state, server, port}, " ")
+		}
+	}
+	for _, service := range services {
+		for _, kind := range proxyKinds {
+			host, port, err := splitAddr(addrs[kind.name])
+			if err != nil {
+				return nil, err
+			}
+			if host == "" {
+				if _, err := networksetup(kind.state, service, "off"); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if _, err := networksetup(kind.set, service, host, port); err != nil {
+				return nil, err
+			}
+			if _, err := networksetup(kind.state, service, "on"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return backup, nil
+}
+
+func restore(b Backup) error {
+	for key, value := range b {
+		name, service, ok := strings.Cut(key, "/")
+		if !ok {
+			continue
+		}
+		var kind proxyKind
+		for _, k := range proxyKinds {
+			if k.name == name {
+				kind = k
+			}
+		}
+		if kind.name == "" {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 3 && fields[1] != "" && fields[2] != "0" {
+			if _, err := networksetup(kind.set, service, fields[1], fields[2]); err != nil {
+				return err
+			}
+		}
+		if _, err := networksetup(kind.state, service, fields[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build linux && !android
+
+package sysproxy
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// gsettingsKeys are the GNOME proxy settings changed by Set.
+var gsettingsKeys = [][2]string{
+	{"org.gnome.system.proxy", "mode"},
+	{"org.gnome.system.proxy.http", "host"},
+	{"org.gnome.system.proxy.http", "port"},
+	{"org.gnome.system.proxy.https", "host"},
+	{"org.gnome.system.proxy.https", "port"},
+	{"org.gnome.system.proxy.socks", "host"},
+	{"org.gnome.system.proxy.socks", "port"},
+}
+
+func gsettings(args ...string) (string, error) {
+	out, err := exec.Command("gsettings", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("gsettings %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// quote returns the GVariant text format of a string.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`) + "'"
+}
+
+func set(s Settings) (Backup, error) {
+	if _, err := exec.LookPath("gsettings"); err != nil {
+		return nil, ErrUnsupported
+	}
+	backup := Backup{}
+	for _, key := range gsettingsKeys {
+		// The values are printed in GVariant text format,
+		// which is accepted by gsettings set.
+		value, err := gsettings("get", key[0], key[1])
+		if err != nil {
+			return nil, err
+		}
+		backup[key[0]+" "+key[1]] = value
+	}
+
+	httpHost, httpPort, err := splitAddr(s.HTTPAddr)
+	if err != nil {
+		return nil, err
+	}
+	socksHost, socksPort, err := splitAddr(s.SOCKS5Addr)
+	if err != nil {
+		return nil, err
+	}
+	if httpPort == "" {
+		httpPort = "0"
+	}
+	if socksPort == "" {
+		socksPort = "0"
+	}
+	values := [][3]string{
+		{"org.gnome.system.proxy.http", "host", quote(httpHost)},
+		{"org.gnome.system.proxy.http", "port", httpPort},
+		{"org.gnome.system.proxy.https", "host", quote(httpHost)},
+		{"org.gnome.system.proxy.https", "port", httpPort},
+		{"org.gnome.system.proxy.socks", "host", quote(socksHost)},
+		{"org.gnome.system.proxy.socks", "port", socksPort},
+		{"org.gnome.system.proxy", "mode", quote("manual")},
+	}
+	for _, v := range values {
+		if _, err := gsettings("set", v[0], v[1], v[2]); err != nil {
+			return nil, err
+		}
+	}
+	return backup, nil
+}
+
+func restore(b Backup) error {
+	for _, key := range gsettingsKeys {
+		value, ok := b[key[0]+" "+key[1]]
+		if !ok {
+			continue
+		}
+		if _, err := gsettings("set", key[0], key[1], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sysproxy
+
+import "testing"
+
+func TestSplitAddr(t *testing.T) {
+	testCases := []struct {
+		addr     string
+		wantHost string
+		wantPort string
+		wantErr  bool
+	}{
+		{"", "", "", false},
+		{"127.0.0.1:1080", "127.0.0.1", "1080", false},
+		{"[::1]:8080", "::1", "8080", false},
+		{"127.0.0.1", "", "", true},
+		{"127.0.0.1:http", "", "", true},
+	}
+	for _, tc := range testCases {
+		host, port, err := splitAddr(tc.addr)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("splitAddr(%q) error = %v, want error %v", tc.addr, err, tc.wantErr)
+			continue
+		}
+		if host != tc.wantHost || port != tc.wantPort {
+			t.Errorf("splitAddr(%q) = %q, %q, want %q, %q", tc.addr, host, port, tc.wantHost, tc.wantPort)
+		}
+	}
+}
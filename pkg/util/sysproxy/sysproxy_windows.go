@@ -0,0 +1,108 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build windows
+
+package sysproxy
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	internetSettingsKey = `Software\Microsoft\Windows\CurrentVersion\Internet Settings`
+
+	internetOptionRefresh         = 37
+	internetOptionSettingsChanged = 39
+)
+
+var procInternetSetOptionW = windows.NewLazySystemDLL("wininet.dll").NewProc("InternetSetOptionW")
+
+// notifyChange lets the applications using WinINET read the new settings.
+func notifyChange() {
+	if procInternetSetOptionW.Find() != nil {
+		return
+	}
+	procInternetSetOptionW.Call(0, internetOptionSettingsChanged, 0, 0)
+	procInternetSetOptionW.Call(0, internetOptionRefresh, 0, 0)
+}
+
+func set(s Settings) (Backup, error) {
+	if s.HTTPAddr == "" {
+		return nil, ErrNoHTTPProxy
+	}
+	if _, _, err := splitAddr(s.HTTPAddr); err != nil {
+		return nil, err
+	}
+	key, err := registry.OpenKey(registry.CURRENT_USER, internetSettingsKey, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return nil, fmt.Errorf("open registry key failed: %w", err)
+	}
+	defer key.Close()
+
+	backup := Backup{}
+	if enable, _, err := key.GetIntegerValue("ProxyEnable"); err == nil {
+		backup["ProxyEnable"] = strconv.FormatUint(enable, 10)
+	}
+	for _, name := range []string{"ProxyServer", "ProxyOverride"} {
+		if value, _, err := key.GetStringValue(name); err == nil {
+			backup[name] = value
+		}
+	}
+
+	if err := key.SetStringValue("ProxyServer", "http="+s.HTTPAddr+";https="+s.HTTPAddr); err != nil {
+		return nil, fmt.Errorf("set ProxyServer failed: %w", err)
+	}
+	if err := key.SetStringValue("ProxyOverride", "<local>"); err != nil {
+		return nil, fmt.Errorf("set ProxyOverride failed: %w", err)
+	}
+	if err := key.SetDWordValue("ProxyEnable", 1); err != nil {
+		return nil, fmt.Errorf("set ProxyEnable failed: %w", err)
+	}
+	notifyChange()
+	return backup, nil
+}
+
+func restore(b Backup) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, internetSettingsKey, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("open registry key failed: %w", err)
+	}
+	defer key.Close()
+
+	// Values that didn't exist before are deleted.
+	for _, name := range []string{"ProxyServer", "ProxyOverride"} {
+		if value, ok := b[name]; ok {
+			if err := key.SetStringValue(name, value); err != nil {
+				return fmt.Errorf("set %s failed: %w", name, err)
+			}
+		} else {
+			key.DeleteValue(name)
+		}
+	}
+	enable := uint64(0)
+	if value, ok := b["ProxyEnable"]; ok {
+		enable, _ = strconv.ParseUint(value, 10, 32)
+	}
+	if err := key.SetDWordValue("ProxyEnable", uint32(enable)); err != nil {
+		return fmt.Errorf("set ProxyEnable failed: %w", err)
+	}
+	notifyChange()
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ToJSON converts a YAML document to JSON.
+func ToJSON(data []byte) ([]byte, error) {
+	v, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal() failed: %w", err)
+	}
+	return b, nil
+}
+
+// FromJSON converts a JSON document to YAML in block style.
+// The order of object members is preserved.
+func FromJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	var sb strings.Builder
+	switch t := v.(type) {
+	case orderedMap:
+		if len(t) > 0 {
+			writeMapping(&sb, t, 0, false)
+			return []byte(sb.String()), nil
+		}
+	case []any:
+		if len(t) > 0 {
+			writeSequence(&sb, t, 0)
+			return []byte(sb.String()), nil
+		}
+	}
+	writeScalar(&sb, v)
+	sb.WriteString("\n")
+	return []byte(sb.String()), nil
+}
+
+// orderedMap is a JSON object that keeps the order of members.
+type orderedMap []mapItem
+
+type mapItem struct {
+	key   string
+	value any
+}
+
+// decodeOrdered decodes the next JSON value. Objects are returned as
+// orderedMap, arrays as []any, and numbers as json.Number.
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := orderedMap{}
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON: %w", err)
+			}
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, mapItem{key: k.(string), value: v})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return m, nil
+	case json.Delim('['):
+		s := make([]any, 0)
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return s, nil
+	default:
+		return tok, nil
+	}
+}
+
+// writeMapping writes the members of a non-empty object at the indentation.
+// If inline is true, the first member continues the current line.
+func writeMapping(sb *strings.Builder, m orderedMap, indent int, inline bool) {
+	for i, item := range m {
+		if i > 0 || !inline {
+			sb.WriteString(strings.Repeat(" ", indent))
+		}
+		writeScalar(sb, item.key)
+		sb.WriteString(":")
+		writeValue(sb, item.value, indent)
+	}
+}
+
+// writeSequence writes the items of a non-empty array at the indentation.
+func writeSequence(sb *strings.Builder, s []any, indent int) {
+	for _, item := range s {
+		sb.WriteString(strings.Repeat(" ", indent))
+		sb.WriteString("-")
+		if m, ok := item.(orderedMap); ok && len(m) > 0 {
+			sb.WriteString(" ")
+			writeMapping(sb, m, indent+2, true)
+		} else {
+			writeValue(sb, item, indent)
+		}
+	}
+}
+
+// writeValue writes the value after a mapping key or sequence indicator,
+// and ends the line.
+func writeValue(sb *strings.Builder, v any, indent int) {
+	switch t := v.(type) {
+	case orderedMap:
+		if len(t) > 0 {
+			sb.WriteString("\n")
+			writeMapping(sb, t, indent+2, false)
+			return
+		}
+	case []any:
+		if len(t) > 0 {
+			sb.WriteString("\n")
+			writeSequence(sb, t, indent+2)
+			return
+		}
+	}
+	sb.WriteString(" ")
+	writeScalar(sb, v)
+	sb.WriteString("\n")
+}
+
+// writeScalar writes a scalar or an empty collection in a single line.
+func writeScalar(sb *strings.Builder, v any) {
+	switch t := v.(type) {
+	case nil:
+		sb.WriteString("null")
+	case bool:
+		sb.WriteString(strconv.FormatBool(t))
+	case json.Number:
+		sb.WriteString(t.String())
+	case string:
+		if isPlainSafe(t) {
+			sb.WriteString(t)
+		} else {
+			sb.WriteString(strconv.Quote(t))
+		}
+	case orderedMap:
+		sb.WriteString("{}")
+	case []any:
+		sb.WriteString("[]")
+	default:
+		sb.WriteString(strconv.Quote(fmt.Sprint(t)))
+	}
+}
+
+// isPlainSafe returns true if the string can be written as a plain scalar
+// and is read back as the same string.
+func isPlainSafe(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`", rune(s[0])) {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f || !strconv.IsPrint(r) && r != ' ' {
+			return false
+		}
+	}
+	if isLeadingZero(s) {
+		// Other YAML parsers may read it as an octal number.
+		return false
+	}
+	v, ok := resolvePlain(s).(string)
+	return ok && v == s
+}
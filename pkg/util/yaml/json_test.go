@@ -0,0 +1,102 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFromJSON(t *testing.T) {
+	input := `{
+    "profiles": [
+        {
+            "profileName": "default",
+            "user": {"name": "user1", "password": "a: b #c"},
+            "servers": [{"ipAddress": "1.1.1.1", "portBindings": [{"port": 4000, "protocol": "UDP"}]}],
+            "empty": {},
+            "none": []
+        }
+    ],
+    "activeProfile": "default",
+    "rpcPort": 1989,
+    "socks5ListenLAN": true,
+    "strings": ["true", "0123", "", " x", "-1", "[a]", "hello world", "line\nbreak"],
+    "nested": [[1, 2], null]
+}`
+	want := `profiles:
+  - profileName: default
+    user:
+      name: user1
+      password: "a: b #c"
+    servers:
+      - ipAddress: 1.1.1.1
+        portBindings:
+          - port: 4000
+            protocol: UDP
+    empty: {}
+    none: []
+activeProfile: default
+rpcPort: 1989
+socks5ListenLAN: true
+strings:
+  - "true"
+  - "0123"
+  - ""
+  - " x"
+  - "-1"
+  - "[a]"
+  - hello world
+  - "line\nbreak"
+nested:
+  -
+    - 1
+    - 2
+  - null
+`
+	got, err := FromJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("FromJSON() failed: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("FromJSON() = %s, want %s", got, want)
+	}
+
+	// Converting back must produce the same value.
+	b, err := ToJSON(got)
+	if err != nil {
+		t.Fatalf("ToJSON() failed: %v", err)
+	}
+	var gotValue, wantValue any
+	if err := json.Unmarshal(b, &gotValue); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	if err := json.Unmarshal([]byte(input), &wantValue); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("ToJSON(FromJSON()) = %s, want %s", b, input)
+	}
+}
+
+func TestFromJSONInvalid(t *testing.T) {
+	for _, input := range []string{"", "{", "[1,]", "{} {}"} {
+		if _, err := FromJSON([]byte(input)); err == nil {
+			t.Errorf("FromJSON(%q) returned no error", input)
+		}
+	}
+}
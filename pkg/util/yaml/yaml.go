@@ -0,0 +1,454 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package yaml parses a subset of YAML that is used by configuration files.
+//
+// Block mappings, block sequences, flow mappings, flow sequences, plain
+// scalars, quoted scalars and comments are supported. Anchors, aliases,
+// tags, multi-line scalars and multiple documents are not supported.
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal parses a YAML document. Mappings are returned as map[string]any,
+// sequences as []any, and scalars as string, int64, float64, bool or nil.
+func Unmarshal(data []byte) (any, error) {
+	lines, err := splitLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{lines: lines}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+	v, err := p.parseNode(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return v, nil
+}
+
+// line is a non-empty line of YAML document without comment.
+type line struct {
+	num    int // line number starting from 1
+	indent int // number of leading spaces
+	text   string
+}
+
+func splitLines(s string) ([]line, error) {
+	var lines []line
+	for i, raw := range strings.Split(s, "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		text := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(text)
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tab character is not allowed in indentation", i+1)
+		}
+		text = strings.TrimRight(stripComment(text), " \t")
+		if text == "" {
+			continue
+		}
+		if indent == 0 {
+			if text == "---" || strings.HasPrefix(text, "%") {
+				if len(lines) != 0 {
+					return nil, fmt.Errorf("line %d: multiple documents are not supported", i+1)
+				}
+				continue
+			}
+			if text == "..." {
+				break
+			}
+		}
+		lines = append(lines, line{num: i + 1, indent: indent, text: text})
+	}
+	return lines, nil
+}
+
+// stripComment removes the comment that starts with "#" outside of quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[{,:", rune(s[i-1])) {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '\t' {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	num := 0
+	if p.pos < len(p.lines) {
+		num = p.lines[p.pos].num
+	} else if len(p.lines) > 0 {
+		num = p.lines[len(p.lines)-1].num
+	}
+	return fmt.Errorf("line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+// parseNode parses the node that starts at the current line.
+// The indentation of the node must be at least minIndent.
+func (p *parser) parseNode(minIndent int) (any, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent < minIndent {
+		return nil, nil
+	}
+	l := p.lines[p.pos]
+	if isSequenceItem(l.text) {
+		return p.parseSequence(l.indent)
+	}
+	if _, _, ok, err := splitKey(l.text); err != nil {
+		return nil, p.errorf("%v", err)
+	} else if ok {
+		return p.parseMapping(l.indent)
+	}
+	p.pos++
+	v, err := parseScalar(l.text)
+	if err != nil {
+		p.pos--
+		return nil, p.errorf("%v", err)
+	}
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > l.indent {
+		return nil, p.errorf("multi-line scalar is not supported")
+	}
+	return v, nil
+}
+
+func (p *parser) parseSequence(indent int) (any, error) {
+	res := make([]any, 0)
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		l := p.lines[p.pos]
+		rest := strings.TrimLeft(l.text[1:], " ")
+		var item any
+		var err error
+		if rest == "" {
+			p.pos++
+			item, err = p.parseNode(indent + 1)
+		} else {
+			// Parse the rest of the line as a node indented after "- ".
+			p.lines[p.pos] = line{num: l.num, indent: indent + len(l.text) - len(rest), text: rest}
+			item, err = p.parseNode(indent + 1)
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, item)
+		if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+	}
+	return res, nil
+}
+
+func (p *parser) parseMapping(indent int) (any, error) {
+	res := make(map[string]any)
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		l := p.lines[p.pos]
+		key, value, ok, err := splitKey(l.text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if !ok {
+			return nil, p.errorf("expect a mapping key")
+		}
+		if _, found := res[key]; found {
+			return nil, p.errorf("duplicate mapping key %q", key)
+		}
+		p.pos++
+		if value != "" {
+			v, err := parseScalar(value)
+			if err != nil {
+				p.pos--
+				return nil, p.errorf("%v", err)
+			}
+			res[key] = v
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				return nil, p.errorf("multi-line scalar is not supported")
+			}
+			continue
+		}
+		if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+			v, err := p.parseNode(indent + 1)
+			if err != nil {
+				return nil, err
+			}
+			res[key] = v
+		} else if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+			// A sequence can have the same indentation as its key.
+			v, err := p.parseSequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			res[key] = v
+		} else {
+			res[key] = nil
+		}
+	}
+	return res, nil
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits a "key: value" line. It returns false if the line
+// is not a mapping entry.
+func splitKey(text string) (key, value string, ok bool, err error) {
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		return "", "", false, nil
+	}
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+		end := quotedEnd(text)
+		if end < 0 {
+			return "", "", false, fmt.Errorf("unterminated quoted string")
+		}
+		rest := text[end:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false, nil
+		}
+		k, err := parseScalar(text[:end])
+		if err != nil {
+			return "", "", false, err
+		}
+		return fmt.Sprint(k), strings.TrimSpace(rest[1:]), true, nil
+	}
+	idx := strings.Index(text, ": ")
+	if idx < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false, nil
+		}
+		idx = len(text) - 1
+	}
+	return strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx+1:]), true, nil
+}
+
+// quotedEnd returns the index after the closing quote of the quoted
+// string at the beginning of s, or -1 if it is not terminated.
+func quotedEnd(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// parseScalar parses a value in a single line, which is either a scalar
+// or a flow collection.
+func parseScalar(text string) (any, error) {
+	f := &flowParser{s: text}
+	v, err := f.parseValue("")
+	if err != nil {
+		return nil, err
+	}
+	f.skipSpaces()
+	if f.i < len(f.s) {
+		return nil, fmt.Errorf("unexpected character %q", f.s[f.i])
+	}
+	return v, nil
+}
+
+// flowParser parses flow collections and scalars.
+type flowParser struct {
+	s string
+	i int
+}
+
+func (f *flowParser) skipSpaces() {
+	for f.i < len(f.s) && (f.s[f.i] == ' ' || f.s[f.i] == '\t') {
+		f.i++
+	}
+}
+
+// parseValue parses a value that ends before any character in stops.
+func (f *flowParser) parseValue(stops string) (any, error) {
+	f.skipSpaces()
+	if f.i >= len(f.s) {
+		return nil, nil
+	}
+	switch c := f.s[f.i]; c {
+	case '{':
+		return f.parseMapping()
+	case '[':
+		return f.parseSequence()
+	case '"', '\'':
+		end := quotedEnd(f.s[f.i:])
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated quoted string")
+		}
+		quoted := f.s[f.i : f.i+end]
+		f.i += end
+		return unquote(quoted)
+	case '|', '>':
+		return nil, fmt.Errorf("block scalar is not supported")
+	case '&', '*', '!':
+		return nil, fmt.Errorf("anchor, alias and tag are not supported")
+	default:
+		start := f.i
+		for f.i < len(f.s) && !strings.ContainsRune(stops, rune(f.s[f.i])) {
+			f.i++
+		}
+		return resolvePlain(strings.TrimSpace(f.s[start:f.i])), nil
+	}
+}
+
+func (f *flowParser) parseMapping() (any, error) {
+	res := make(map[string]any)
+	f.i++ // skip "{"
+	for {
+		f.skipSpaces()
+		if f.i >= len(f.s) {
+			return nil, fmt.Errorf("unterminated flow mapping")
+		}
+		if f.s[f.i] == '}' {
+			f.i++
+			return res, nil
+		}
+		k, err := f.parseValue(":,}")
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprint(k)
+		if k == nil {
+			key = ""
+		}
+		f.skipSpaces()
+		var v any
+		if f.i < len(f.s) && f.s[f.i] == ':' {
+			f.i++
+			v, err = f.parseValue(",}")
+			if err != nil {
+				return nil, err
+			}
+		}
+		if _, found := res[key]; found {
+			return nil, fmt.Errorf("duplicate mapping key %q", key)
+		}
+		res[key] = v
+		f.skipSpaces()
+		if f.i < len(f.s) && f.s[f.i] == ',' {
+			f.i++
+		} else if f.i >= len(f.s) || f.s[f.i] != '}' {
+			return nil, fmt.Errorf("unterminated flow mapping")
+		}
+	}
+}
+
+func (f *flowParser) parseSequence() (any, error) {
+	res := make([]any, 0)
+	f.i++ // skip "["
+	for {
+		f.skipSpaces()
+		if f.i >= len(f.s) {
+			return nil, fmt.Errorf("unterminated flow sequence")
+		}
+		if f.s[f.i] == ']' {
+			f.i++
+			return res, nil
+		}
+		v, err := f.parseValue(",]")
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+		f.skipSpaces()
+		if f.i < len(f.s) && f.s[f.i] == ',' {
+			f.i++
+		} else if f.i >= len(f.s) || f.s[f.i] != ']' {
+			return nil, fmt.Errorf("unterminated flow sequence")
+		}
+	}
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid double quoted string %s", s)
+	}
+	return v, nil
+}
+
+// resolvePlain returns the value of a plain scalar.
+func resolvePlain(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	}
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil && !strings.HasPrefix(strings.TrimLeft(s, "+-"), "0b") && !isLeadingZero(s) {
+		return i
+	}
+	if strings.HasPrefix(s, "0o") {
+		if i, err := strconv.ParseInt(s[2:], 8, 64); err == nil {
+			return i
+		}
+	}
+	if strings.ContainsAny(s, ".eE") && !strings.ContainsAny(s, "_xX") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
+
+// isLeadingZero returns true if s is a decimal number with leading zero,
+// which is treated as a string.
+func isLeadingZero(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return len(s) > 1 && s[0] == '0' && s[1] >= '0' && s[1] <= '9'
+}
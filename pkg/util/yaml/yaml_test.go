@@ -0,0 +1,125 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	testcases := []struct {
+		name  string
+		input string
+		want  any
+	}{
+		{
+			name:  "empty",
+			input: "# nothing here\n",
+			want:  nil,
+		},
+		{
+			name:  "scalars",
+			input: "a: 1\nb: -2.5\nc: true\nd: ~\ne: hello world\nf: '0123'\ng: \"a\\tb\"\nh: 'it''s'\n",
+			want: map[string]any{
+				"a": int64(1),
+				"b": -2.5,
+				"c": true,
+				"d": nil,
+				"e": "hello world",
+				"f": "0123",
+				"g": "a\tb",
+				"h": "it's",
+			},
+		},
+		{
+			name: "clash proxies",
+			input: `---
+mixed-port: 7890 # comment
+proxies:
+  - name: "jp #1"
+    type: ss
+    server: 1.2.3.4
+    port: 8388
+    cipher: aes-256-gcm
+    password: p#ss
+  - {name: us, type: trojan, server: example.com, port: 443, alpn: [h2, http/1.1]}
+rules:
+- MATCH,DIRECT
+`,
+			want: map[string]any{
+				"mixed-port": int64(7890),
+				"proxies": []any{
+					map[string]any{
+						"name":     "jp #1",
+						"type":     "ss",
+						"server":   "1.2.3.4",
+						"port":     int64(8388),
+						"cipher":   "aes-256-gcm",
+						"password": "p#ss",
+					},
+					map[string]any{
+						"name":   "us",
+						"type":   "trojan",
+						"server": "example.com",
+						"port":   int64(443),
+						"alpn":   []any{"h2", "http/1.1"},
+					},
+				},
+				"rules": []any{"MATCH,DIRECT"},
+			},
+		},
+		{
+			name:  "nested sequence",
+			input: "- - a\n  - b\n-\n  c: d\n",
+			want: []any{
+				[]any{"a", "b"},
+				map[string]any{"c": "d"},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Unmarshal([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("Unmarshal() failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	testcases := []string{
+		"a: 1\na: 2\n",
+		"a: &anchor 1\n",
+		"a: |\n  text\n",
+		"a: b\n  c\n",
+		"a: [1, 2\n",
+		"a: \"unterminated\n",
+		"a:\n\tb: 1\n",
+		"a: 1\n---\nb: 2\n",
+	}
+	for _, input := range testcases {
+		if _, err := Unmarshal([]byte(input)); err == nil {
+			t.Errorf("Unmarshal(%q) returned no error", input)
+		}
+	}
+}
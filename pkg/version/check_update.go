@@ -20,13 +20,34 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"time"
 )
 
+const (
+	// DefaultLatestReleaseURL is the GitHub API to query the latest release.
+	DefaultLatestReleaseURL = "https://api.github.com/repos/enfein/mieru/releases/latest"
+
+	queryLatestVersionTimeout = 30 * time.Second
+)
+
+// CheckUpdateOptions controls how to query the latest release.
+type CheckUpdateOptions struct {
+	// LatestReleaseURL is the URL that returns the latest release in the
+	// same JSON format as GitHub API. It can point to a mirror.
+	// If empty, DefaultLatestReleaseURL is used.
+	LatestReleaseURL string
+
+	// ProxyURL is the proxy to send the HTTP request, for example,
+	// "socks5://127.0.0.1:1080". If empty, the request is sent directly.
+	ProxyURL string
+}
+
 // CheckUpdate fetches the latest mieru / mita version using GitHub API
-// and check if a new release is available.
-func CheckUpdate() (hasUpdate bool, msg string, err error) {
+// or a mirror, and check if a new release is available.
+func CheckUpdate(opts CheckUpdateOptions) (hasUpdate bool, msg string, err error) {
 	var remoteTag string
-	remoteTag, err = queryLatestVersion()
+	remoteTag, err = queryLatestVersion(opts)
 	if err != nil {
 		return false, "", fmt.Errorf("queryLatestVersion() failed: %w", err)
 	}
@@ -49,10 +70,30 @@ func CheckUpdate() (hasUpdate bool, msg string, err error) {
 	}
 }
 
-func queryLatestVersion() (string, error) {
-	resp, err := http.Get("https://api.github.com/repos/enfein/mieru/releases/latest")
+func queryLatestVersion(opts CheckUpdateOptions) (string, error) {
+	releaseURL := opts.LatestReleaseURL
+	if releaseURL == "" {
+		releaseURL = DefaultLatestReleaseURL
+	}
+	transport := &http.Transport{}
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return "", fmt.Errorf("url.Parse() failed: %v", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   queryLatestVersionTimeout,
+	}
+	defer client.CloseIdleConnections()
+	resp, err := client.Get(releaseURL)
 	if err != nil {
-		return "", fmt.Errorf("http.Get() failed: %v [GitHub may be blocked in your network]", err)
+		if opts.ProxyURL == "" && opts.LatestReleaseURL == "" {
+			return "", fmt.Errorf("http.Get() failed: %v [GitHub may be blocked in your network]", err)
+		}
+		return "", fmt.Errorf("http.Get() failed: %v", err)
 	}
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("HTTP returned expected status code %d", resp.StatusCode)
@@ -0,0 +1,43 @@
+// Copyright (C) 2023  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package version
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckUpdateFromMirror(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name": "v999.0.0"}`))
+	}))
+	defer server.Close()
+
+	hasUpdate, _, err := CheckUpdate(CheckUpdateOptions{LatestReleaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("CheckUpdate() failed: %v", err)
+	}
+	if !hasUpdate {
+		t.Errorf("CheckUpdate() didn't find the update")
+	}
+}
+
+func TestCheckUpdateInvalidProxy(t *testing.T) {
+	if _, _, err := CheckUpdate(CheckUpdateOptions{ProxyURL: "://"}); err == nil {
+		t.Errorf("CheckUpdate() with invalid proxy URL returned no error")
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package webui serves a web dashboard of mieru client on localhost.
+// The dashboard shows the status, metrics, connections and logs of the
+// client, which are read with the lifecycle RPC.
+package webui
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// TokenParam is the URL query parameter that carries the RPC token
+	// when the dashboard is opened for the first time.
+	TokenParam = "token"
+
+	// tokenCookie stores the RPC token in the browser after the
+	// dashboard is opened.
+	tokenCookie = "mieru_dashboard_token"
+
+	// rpcTimeout is the timeout of each RPC call.
+	rpcTimeout = 5 * time.Second
+
+	// historySeconds is the duration of metrics history shown in the graphs.
+	historySeconds = 3600
+
+	defaultLogLines = 200
+	maxLogLines     = 2000
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Handler serves the web dashboard.
+type Handler struct {
+	client pb.ClientLifecycleServiceClient
+	token  string
+	mux    *http.ServeMux
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// NewHandler returns the handler of the web dashboard. The data is read
+// from the client with the lifecycle RPC. Every request must carry the
+// RPC token, either in the URL query or in the cookie set by the
+// dashboard.
+func NewHandler(client pb.ClientLifecycleServiceClient, token string) *Handler {
+	h := &Handler{
+		client: client,
+		token:  token,
+		mux:    http.NewServeMux(),
+	}
+	h.mux.HandleFunc("/", h.serveIndex)
+	h.mux.HandleFunc("/api/status", h.serveStatus)
+	h.mux.HandleFunc("/api/metrics", h.serveMetrics)
+	h.mux.HandleFunc("/api/history", h.serveHistory)
+	h.mux.HandleFunc("/api/sessions", h.serveSessions)
+	h.mux.HandleFunc("/api/logs", h.serveLogs)
+	return h
+}
+
+// NewServer returns the HTTP server of the web dashboard.
+func NewServer(listenAddr string, handler *Handler) *http.Server {
+	return &http.Server{
+		Addr:              listenAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Reject requests to other host names, which can reach localhost
+	// from a web page with DNS rebinding.
+	if !isLocalHost(r.Host) {
+		http.Error(w, "host is not allowed", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method is not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if token := r.URL.Query().Get(TokenParam); token != "" {
+		if !h.validToken(token) {
+			http.Error(w, "token is invalid", http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     tokenCookie,
+			Value:    token,
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+		// Remove the token from the address bar and browser history.
+		http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+		return
+	}
+	cookie, err := r.Cookie(tokenCookie)
+	if err != nil || !h.validToken(cookie.Value) {
+		http.Error(w, "open the URL printed by \"mieru get dashboard-url\" to use the dashboard", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) validToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+func (h *Handler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+func (h *Handler) serveStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), rpcTimeout)
+	defer cancel()
+	status, err := h.client.GetStatus(ctx, &pb.Empty{})
+	writeProto(w, status, err)
+}
+
+func (h *Handler) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), rpcTimeout)
+	defer cancel()
+	metrics, err := h.client.GetMetrics(ctx, &pb.Empty{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// The metrics are already in JSON format.
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, metrics.GetJson())
+}
+
+func (h *Handler) serveHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), rpcTimeout)
+	defer cancel()
+	history, err := h.client.GetMetricsHistory(ctx, &pb.GetMetricsHistoryRequest{
+		DurationSeconds: proto.Int64(historySeconds),
+	})
+	writeProto(w, history, err)
+}
+
+func (h *Handler) serveSessions(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), rpcTimeout)
+	defer cancel()
+	info, err := h.client.GetSessionInfo(ctx, &pb.Empty{})
+	writeProto(w, info, err)
+}
+
+func (h *Handler) serveLogs(w http.ResponseWriter, r *http.Request) {
+	lines := defaultLogLines
+	if s := r.URL.Query().Get("lines"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxLogLines {
+			http.Error(w, fmt.Sprintf("lines must be between 1 and %d", maxLogLines), http.StatusBadRequest)
+			return
+		}
+		lines = n
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), rpcTimeout)
+	defer cancel()
+	stream, err := h.client.GetLogs(ctx, &pb.GetLogsRequest{Lines: proto.Int32(int32(lines))})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var b strings.Builder
+	b.WriteString(`{"lines":[`)
+	for i := 0; ; i++ {
+		line, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		j, err := protojson.Marshal(line)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("protojson.Marshal() failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.Write(j)
+	}
+	b.WriteString("]}")
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, b.String())
+}
+
+// writeProto writes the message in JSON format, or the error if it is not nil.
+func writeProto(w http.ResponseWriter, m proto.Message, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("protojson.Marshal() failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// writeError writes the error returned by the RPC call.
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusBadGateway)
+}
+
+// isLocalHost returns true if the host of the request is localhost
+// or a loopback IP address.
+func isLocalHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
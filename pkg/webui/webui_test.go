@@ -0,0 +1,158 @@
+// Copyright (C) 2024  mieru authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package webui
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/enfein/mieru/pkg/appctl/appctlpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const testToken = "0123456789abcdef"
+
+// fakeClient implements the RPC methods used by the dashboard.
+type fakeClient struct {
+	pb.ClientLifecycleServiceClient
+}
+
+func (c *fakeClient) GetStatus(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (*pb.AppStatusMsg, error) {
+	return &pb.AppStatusMsg{Status: pb.AppStatus_RUNNING.Enum()}, nil
+}
+
+func (c *fakeClient) GetMetrics(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (*pb.Metrics, error) {
+	return &pb.Metrics{Json: proto.String(`{"traffic": {"InBytes": 100}}`)}, nil
+}
+
+func (c *fakeClient) GetLogs(ctx context.Context, in *pb.GetLogsRequest, opts ...grpc.CallOption) (pb.ClientLifecycleService_GetLogsClient, error) {
+	return &fakeLogsClient{lines: []string{"first line", "second line"}}, nil
+}
+
+type fakeLogsClient struct {
+	grpc.ClientStream
+	lines []string
+}
+
+func (c *fakeLogsClient) Recv() (*pb.LogLine, error) {
+	if len(c.lines) == 0 {
+		return nil, io.EOF
+	}
+	line := &pb.LogLine{Text: proto.String(c.lines[0])}
+	c.lines = c.lines[1:]
+	return line, nil
+}
+
+func get(t *testing.T, h http.Handler, target string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandlerToken(t *testing.T) {
+	h := NewHandler(&fakeClient{}, testToken)
+
+	if w := get(t, h, "http://localhost/api/status", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("request without token got status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := get(t, h, "http://localhost/?token=wrong", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("request with wrong token got status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := get(t, h, "http://attacker.example:8080/?token="+testToken, nil); w.Code != http.StatusForbidden {
+		t.Errorf("request to other host got status %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w := get(t, h, "http://localhost/?token="+testToken, nil)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("request with token got status %d, want %d", w.Code, http.StatusSeeOther)
+	}
+	if location := w.Header().Get("Location"); location != "/" {
+		t.Errorf("redirect location is %q, want %q", location, "/")
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != testToken {
+		t.Fatalf("got cookies %v, want the token", cookies)
+	}
+
+	w = get(t, h, "http://127.0.0.1:8964/", cookies[0])
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "mieru dashboard") {
+		t.Errorf("index page got status %d", w.Code)
+	}
+}
+
+func TestHandlerAPI(t *testing.T) {
+	h := NewHandler(&fakeClient{}, testToken)
+	cookie := &http.Cookie{Name: tokenCookie, Value: testToken}
+
+	testCases := []struct {
+		path string
+		want string
+	}{
+		{"/api/status", `"RUNNING"`},
+		{"/api/metrics", `"InBytes": 100`},
+		{"/api/logs", `"second line"`},
+	}
+	for _, tc := range testCases {
+		w := get(t, h, "http://localhost"+tc.path, cookie)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s got status %d: %s", tc.path, w.Code, w.Body.String())
+			continue
+		}
+		if !strings.Contains(w.Body.String(), tc.want) {
+			t.Errorf("%s got %s, want %s", tc.path, w.Body.String(), tc.want)
+		}
+	}
+
+	if w := get(t, h, "http://localhost/api/logs?lines=0", cookie); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid lines got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/api/status", nil)
+	req.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestIsLocalHost(t *testing.T) {
+	testCases := []struct {
+		host string
+		want bool
+	}{
+		{"localhost", true},
+		{"LOCALHOST:8964", true},
+		{"127.0.0.1:8964", true},
+		{"[::1]:8964", true},
+		{"192.168.1.1:8964", false},
+		{"localhost.example.com", false},
+	}
+	for _, tc := range testCases {
+		if got := isLocalHost(tc.host); got != tc.want {
+			t.Errorf("isLocalHost(%q) = %v, want %v", tc.host, got, tc.want)
+		}
+	}
+}
@@ -40,6 +40,11 @@ echo "========== BEGIN OF UDP ASSOCIATE TEST =========="
 ./test_mix_udp_associate.sh
 echo "==========  END OF UDP ASSOCIATE TEST  =========="
 
+# Run UDP associate over TCP test.
+echo "========== BEGIN OF UDP ASSOCIATE OVER TCP TEST =========="
+./test_tcp_udp_associate.sh
+echo "==========  END OF UDP ASSOCIATE OVER TCP TEST  =========="
+
 # Run TCP test.
 echo "========== BEGIN OF TCP TEST =========="
 ./test_tcp.sh
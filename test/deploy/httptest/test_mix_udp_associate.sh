@@ -44,7 +44,7 @@ if [[ "$?" -ne 0 ]]; then
     exit 1
 fi
 ./mieru export config > client.url.txt
-rm ~/.config/mieru/client.conf.pb
+rm -rf ~/.config/mieru/client.conf.pb ~/.config/mieru/client.conf.pb.d
 echo "mieru client config before import:"
 ./mieru describe config
 sleep 1
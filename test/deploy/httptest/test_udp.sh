@@ -57,6 +57,22 @@ fi
 
 # Start testing.
 sleep 2
+echo ">>> mieru test - UDP <<<"
+./mieru test http://127.0.0.1:8080/
+if [ "$?" -ne "0" ]; then
+    echo "UDP - mieru test failed."
+    exit 1
+fi
+
+sleep 1
+echo ">>> mieru speedtest - UDP <<<"
+./mieru speedtest 4
+if [ "$?" -ne "0" ]; then
+    echo "UDP - mieru speedtest failed."
+    exit 1
+fi
+
+sleep 1
 echo ">>> socks5 - new connections - UDP <<<"
 ./sockshttpclient -dst_host=127.0.0.1 -dst_port=8080 \
   -local_proxy_host=127.0.0.1 -local_proxy_port=1080 \